@@ -0,0 +1,278 @@
+// Package alertmanager is a kenko Notifier that posts status changes as
+// alerts to a Prometheus Alertmanager, so they go through its routing,
+// inhibition, and silences.
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultAlertName = "KenkoTargetDown"
+	defaultSlowName  = "KenkoTargetSlow"
+	watchdogName     = "KenkoWatchdog"
+	defaultTTL       = 24 * time.Hour
+	defaultTimeout   = 10 * time.Second
+
+	// testTTL is how long a test alert fires before Alertmanager resolves it.
+	testTTL = 5 * time.Minute
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithAlertName sets the alertname label of the alerts (default
+// "KenkoTargetDown").
+func WithAlertName(name string) Option {
+	return func(n *Notifier) { n.alertName = name }
+}
+
+// WithSlowAlertName sets the alertname label of latency alerts (default
+// "KenkoTargetSlow"), which fire and resolve apart from the target's outage.
+func WithSlowAlertName(name string) Option {
+	return func(n *Notifier) { n.slowName = name }
+}
+
+// WithLabel adds a label to every alert, e.g. the team an Alertmanager route
+// matches on.
+func WithLabel(name, value string) Option {
+	return func(n *Notifier) { n.labels[labelName(name)] = value }
+}
+
+// WithTTL sets how long a firing alert lasts unless it is sent again
+// (default 24h). kenko resolves the alert when the target recovers, so this
+// only ends alerts whose recovery was never sent; set WithNotifyRepeat
+// shorter to keep long outages firing.
+func WithTTL(d time.Duration) Option {
+	return func(n *Notifier) { n.ttl = d }
+}
+
+// WithStatusPageURL sets the generator url alerts link back to.
+func WithStatusPageURL(url string) Option {
+	return func(n *Notifier) { n.statusPage = url }
+}
+
+// WithBasicAuth authenticates with a username and password, e.g. for an
+// Alertmanager behind a proxy.
+func WithBasicAuth(username, password string) Option {
+	return func(n *Notifier) { n.username, n.password = username, password }
+}
+
+// WithHTTPClient sets the client alerts are sent with (default a client with
+// a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// Notifier posts notifications as alerts to an Alertmanager.
+type Notifier struct {
+	url        string
+	alertName  string
+	slowName   string
+	labels     map[string]string
+	ttl        time.Duration
+	statusPage string
+	username   string
+	password   string
+	client     *http.Client
+}
+
+// New creates a Notifier posting to the Alertmanager at url, e.g.
+// "http://alertmanager:9093". for a cluster, create one per instance, as
+// Alertmanager expects every instance to be sent every alert.
+func New(url string, opts ...Option) *Notifier {
+	n := &Notifier{
+		url:       strings.TrimSuffix(url, "/") + "/api/v2/alerts",
+		alertName: defaultAlertName,
+		slowName:  defaultSlowName,
+		labels:    make(map[string]string),
+		ttl:       defaultTTL,
+		client:    &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Alert is an alert in the format of Alertmanager's v2 api.
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// alert builds the alert for n. its labels name the target, not the status,
+// so every change of one failure updates the same alert and the recovery
+// resolves it.
+func (a *Notifier) alert(n kenko.Notification) Alert {
+	at := n.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	name := a.alertName
+	if n.Slow != nil {
+		name = a.slowName
+	}
+	labels := map[string]string{"alertname": name, "target": n.Target}
+	if n.Result.Group != "" {
+		labels["group"] = n.Result.Group
+	}
+	if n.Result.Severity != "" {
+		labels["severity"] = string(n.Result.Severity)
+	}
+	for k, v := range n.Result.Labels {
+		labels[labelName(k)] = v
+	}
+	for k, v := range a.labels {
+		labels[k] = v
+	}
+	if n.Test {
+		labels["test"] = "true"
+	}
+
+	summary := fmt.Sprintf("%s is %s", n.Target, n.Status)
+	if n.Result.Error != "" {
+		summary += ": " + n.Result.Error
+	}
+	annotations := map[string]string{"summary": summary, "status": string(n.Status)}
+	if n.Result.URL != "" {
+		annotations["url"] = n.Result.URL
+	}
+
+	alert := Alert{Labels: labels, Annotations: annotations, StartsAt: at, EndsAt: at.Add(a.ttl), GeneratorURL: a.statusPage}
+	switch s := n.Slow; {
+	case s != nil:
+		alert.StartsAt = s.Since
+		annotations["summary"] = fmt.Sprintf("%s is slow: over %s for %d checks", n.Target, s.Threshold, s.Checks)
+		annotations["latency"] = n.Result.Latency.Round(time.Millisecond).String()
+		if s.Resolved {
+			alert.EndsAt = at
+			annotations["summary"] = fmt.Sprintf("%s is fast again after %s", n.Target, at.Sub(s.Since).Round(time.Second))
+		}
+	case n.Status == kenko.StatusHealthy:
+		alert.EndsAt = at
+		annotations["summary"] = n.Target + " recovered"
+		if o := n.Outage; o != nil {
+			alert.StartsAt = o.Start
+			annotations["summary"] = fmt.Sprintf("%s recovered after %s", n.Target, o.Duration.Round(time.Second))
+			if o.LastError != "" {
+				annotations["last_error"] = o.LastError
+			}
+		}
+	case n.Test:
+		alert.EndsAt = at.Add(testTTL)
+	}
+	return alert
+}
+
+// Notify posts the notification's alert. a healthy status resolves it.
+func (a *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	body, err := json.Marshal([]Alert{a.alert(n)})
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("alertmanager: marshal: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("alertmanager: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+	if a.username != "" {
+		req.SetBasicAuth(a.username, a.password)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alertmanager: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("alertmanager: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	if !kenko.RetryableStatus(resp.StatusCode) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the alert Notify would post for n.
+func (a *Notifier) Preview(n kenko.Notification) (string, error) {
+	body, err := json.MarshalIndent(a.alert(n), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("alertmanager: marshal: %w", err)
+	}
+	return string(body), nil
+}
+
+// Watchdog returns a heartbeat sender for kenko.WithHeartbeat that posts an
+// always-firing alert named KenkoWatchdog every interval, which expires
+// after three missed heartbeats. route it to a dead man's switch to be paged
+// when kenko or its host stops.
+func (a *Notifier) Watchdog(interval time.Duration) kenko.HeartbeatSender {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	header := http.Header{}
+	if a.username != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(a.username+":"+a.password)))
+	}
+	return kenko.HeartbeatSender{
+		URL:      a.url,
+		Interval: interval,
+		Header:   header,
+		Body: func(hb kenko.Heartbeat) ([]byte, error) {
+			return json.Marshal([]Alert{a.watchdog(hb, interval)})
+		},
+	}
+}
+
+func (a *Notifier) watchdog(hb kenko.Heartbeat, interval time.Duration) Alert {
+	labels := map[string]string{"alertname": watchdogName}
+	if hb.Instance != "" {
+		labels["instance"] = hb.Instance
+	}
+	if hb.Region != "" {
+		labels["region"] = hb.Region
+	}
+	for k, v := range a.labels {
+		labels[k] = v
+	}
+	return Alert{
+		Labels:       labels,
+		Annotations:  map[string]string{"summary": "kenko is running"},
+		StartsAt:     hb.At,
+		EndsAt:       hb.At.Add(3 * interval),
+		GeneratorURL: a.statusPage,
+	}
+}
+
+// labelName replaces the characters a Prometheus label name cannot hold with
+// underscores, e.g. in a kenko label such as "app.kubernetes.io/name".
+func labelName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,148 @@
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification(status kenko.Status) kenko.Notification {
+	n := notifytest.Notification(status)
+	n.Result.Labels["app.kubernetes.io/name"] = "api"
+	return n
+}
+
+func TestNotify_FiringAndResolved(t *testing.T) {
+	var alerts []Alert
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		var batch []Alert
+		json.NewDecoder(r.Body).Decode(&batch)
+		alerts = append(alerts, batch...)
+	}))
+	defer srv.Close()
+
+	a := New(srv.URL+"/", WithLabel("env", "prod"), WithTTL(time.Hour), WithStatusPageURL("https://status.example.com"))
+	firing := notification(kenko.StatusUnhealthy)
+	recovery := notification(kenko.StatusHealthy)
+	recovery.Previous = kenko.StatusUnhealthy
+	recovery.At = firing.At.Add(10 * time.Minute)
+	recovery.Outage = &kenko.Outage{Start: firing.At, Duration: 10 * time.Minute, LastError: "status 503"}
+	firing.Result.Severity, recovery.Result.Severity = kenko.SeverityCritical, kenko.SeverityCritical
+	for _, n := range []kenko.Notification{firing, recovery} {
+		if err := a.Notify(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if path != "/api/v2/alerts" || len(alerts) != 2 {
+		t.Fatalf("path %s, alerts %+v", path, alerts)
+	}
+
+	fired, resolved := alerts[0], alerts[1]
+	want := map[string]string{"alertname": "KenkoTargetDown", "target": "api", "group": "payments", "severity": "critical", "team": "payments", "app_kubernetes_io_name": "api", "env": "prod"}
+	for k, v := range want {
+		if fired.Labels[k] != v {
+			t.Errorf("label %s = %q, want %q", k, fired.Labels[k], v)
+		}
+	}
+	if len(fired.Labels) != len(want) {
+		t.Errorf("labels = %v, want %v", fired.Labels, want)
+	}
+	if !fired.EndsAt.Equal(firing.At.Add(time.Hour)) || fired.Annotations["summary"] != "api is unhealthy: status 503" || fired.GeneratorURL != "https://status.example.com" {
+		t.Errorf("firing alert = %+v", fired)
+	}
+	if len(resolved.Labels) != len(fired.Labels) || !resolved.StartsAt.Equal(firing.At) || !resolved.EndsAt.Equal(recovery.At) {
+		t.Errorf("resolved alert = %+v, want the firing alert ended at the recovery", resolved)
+	}
+	if resolved.Annotations["summary"] != "api recovered after 10m0s" {
+		t.Errorf("summary = %q", resolved.Annotations["summary"])
+	}
+}
+
+func TestAlert_Slow(t *testing.T) {
+	n := notification(kenko.StatusHealthy)
+	n.Result.Latency = 1500 * time.Millisecond
+	n.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3, Since: n.At.Add(-time.Minute)}
+	a := New("http://alertmanager:9093")
+	firing := a.alert(n)
+	if firing.Labels["alertname"] != "KenkoTargetSlow" || !firing.StartsAt.Equal(n.Slow.Since) || !firing.EndsAt.After(n.At) ||
+		firing.Annotations["summary"] != "api is slow: over 1s for 3 checks" || firing.Annotations["latency"] != "1.5s" {
+		t.Errorf("firing = %+v", firing)
+	}
+
+	n.Slow.Resolved = true
+	resolved := a.alert(n)
+	if resolved.Labels["alertname"] != "KenkoTargetSlow" || !resolved.EndsAt.Equal(n.At) ||
+		resolved.Annotations["summary"] != "api is fast again after 1m0s" {
+		t.Errorf("resolved = %+v", resolved)
+	}
+}
+
+func TestPreview_Test(t *testing.T) {
+	n := notification(kenko.StatusDegraded)
+	n.Test = true
+	a := New("http://alertmanager:9093")
+	alert := a.alert(n)
+	if alert.Labels["test"] != "true" || alert.EndsAt.Sub(alert.StartsAt) != testTTL {
+		t.Errorf("test alert = %+v, want a test label and a short ttl", alert)
+	}
+	text, err := a.Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `"status": "degraded"`) {
+		t.Errorf("preview = %s", text)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, "start time must be before end time\n", "start time must be before end time", func(url string) error {
+		return New(url).Notify(context.Background(), notification(kenko.StatusUnhealthy))
+	})
+}
+
+func TestWatchdog(t *testing.T) {
+	a := New("http://alertmanager:9093/", WithLabel("team", "sre"), WithBasicAuth("kenko", "secret"))
+	hb := a.Watchdog(30 * time.Second)
+	if hb.URL != "http://alertmanager:9093/api/v2/alerts" || hb.Interval != 30*time.Second || !strings.HasPrefix(hb.Header.Get("Authorization"), "Basic ") {
+		t.Errorf("sender = %+v", hb)
+	}
+
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	body, err := hb.Body(kenko.Heartbeat{At: at, Instance: "kenko-eu-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alerts []Alert
+	if err := json.Unmarshal(body, &alerts); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+	w := alerts[0]
+	if w.Labels["alertname"] != "KenkoWatchdog" || w.Labels["instance"] != "kenko-eu-1" || w.Labels["team"] != "sre" ||
+		!w.StartsAt.Equal(at) || !w.EndsAt.Equal(at.Add(90*time.Second)) {
+		t.Errorf("watchdog = %+v", w)
+	}
+}
+
+func TestLabelName(t *testing.T) {
+	for in, want := range map[string]string{
+		"team":                   "team",
+		"app.kubernetes.io/name": "app_kubernetes_io_name",
+		"2fa":                    "_fa",
+	} {
+		if got := labelName(in); got != want {
+			t.Errorf("labelName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// announcementsStateKey is the StateStore key holding status page announcements.
+const announcementsStateKey = "announcements"
+
+// resolvedAnnouncementAge is how long a resolved announcement stays on the
+// status page.
+const resolvedAnnouncementAge = 7 * 24 * time.Hour
+
+// ErrNoAnnouncement is returned when an announcement ID does not exist.
+var ErrNoAnnouncement = errors.New("kenko: no such announcement")
+
+// AnnouncementState is the progress of a manually posted incident.
+type AnnouncementState string
+
+// Possible AnnouncementState values, in their usual order.
+const (
+	AnnouncementInvestigating AnnouncementState = "investigating"
+	AnnouncementIdentified    AnnouncementState = "identified"
+	AnnouncementMonitoring    AnnouncementState = "monitoring"
+	AnnouncementResolved      AnnouncementState = "resolved"
+)
+
+func (s AnnouncementState) valid() bool {
+	switch s {
+	case AnnouncementInvestigating, AnnouncementIdentified, AnnouncementMonitoring, AnnouncementResolved:
+		return true
+	}
+	return false
+}
+
+// Announcement is an incident posted by an operator to the status page, with
+// the messages written as it progressed.
+type Announcement struct {
+	ID    string            `json:"id"`
+	Title string            `json:"title"`
+	State AnnouncementState `json:"state"`
+	// Components are the affected target names; empty means the whole service.
+	Components []string             `json:"components,omitempty"`
+	Updates    []AnnouncementUpdate `json:"updates"`
+	CreatedAt  time.Time            `json:"created_at"`
+	ResolvedAt *time.Time           `json:"resolved_at,omitempty"`
+}
+
+// AnnouncementUpdate is one message posted on an announcement.
+type AnnouncementUpdate struct {
+	State   AnnouncementState `json:"state"`
+	Message string            `json:"message"`
+	At      time.Time         `json:"at"`
+}
+
+// announcements holds the posted announcements, oldest first.
+type announcements struct {
+	mu   sync.Mutex
+	list []Announcement
+}
+
+// Announcements returns all posted announcements, newest first.
+func (c *Checker) Announcements() []Announcement {
+	c.announcements.mu.Lock()
+	defer c.announcements.mu.Unlock()
+
+	out := make([]Announcement, 0, len(c.announcements.list))
+	for i := len(c.announcements.list) - 1; i >= 0; i-- {
+		a := c.announcements.list[i]
+		a.Components = slices.Clone(a.Components)
+		a.Updates = slices.Clone(a.Updates)
+		out = append(out, a)
+	}
+	return out
+}
+
+// PostAnnouncement posts an incident to the status page with its first
+// message. an empty state means investigating. it is persisted when the store
+// implements StateStore, and only shown once it is; the same goes for
+// updates and deletions.
+func (c *Checker) PostAnnouncement(ctx context.Context, title, message string, state AnnouncementState, components []string) (Announcement, error) {
+	if title == "" || message == "" {
+		return Announcement{}, fmt.Errorf("kenko: announcement title and message must not be empty")
+	}
+	if state == "" {
+		state = AnnouncementInvestigating
+	}
+	if !state.valid() {
+		return Announcement{}, fmt.Errorf("kenko: invalid announcement state %q", state)
+	}
+
+	names := make([]string, 0, len(components))
+	for _, comp := range components {
+		name, err := c.normalizeName(comp)
+		if err != nil {
+			return Announcement{}, fmt.Errorf("kenko: component %q: %w", comp, err)
+		}
+		if !c.hasTarget(name) {
+			return Announcement{}, fmt.Errorf("kenko: unknown target %q", comp)
+		}
+		names = append(names, name)
+	}
+
+	now := time.Now()
+	a := Announcement{
+		ID:         strconv.FormatInt(now.UnixNano(), 36),
+		Title:      title,
+		State:      state,
+		Components: names,
+		Updates:    []AnnouncementUpdate{{State: state, Message: message, At: now}},
+		CreatedAt:  now,
+	}
+	if state == AnnouncementResolved {
+		a.ResolvedAt = &now
+	}
+
+	c.announcements.mu.Lock()
+	defer c.announcements.mu.Unlock()
+	list := append(slices.Clone(c.announcements.list), a)
+	if err := c.saveState(ctx, announcementsStateKey, list); err != nil {
+		return Announcement{}, &saveError{err: err}
+	}
+	c.announcements.list = list
+	return a, nil
+}
+
+// UpdateAnnouncement adds a message to an announcement, moving it to state.
+// an empty state keeps the current one.
+func (c *Checker) UpdateAnnouncement(ctx context.Context, id, message string, state AnnouncementState) (Announcement, error) {
+	if message == "" {
+		return Announcement{}, fmt.Errorf("kenko: announcement message must not be empty")
+	}
+	if state != "" && !state.valid() {
+		return Announcement{}, fmt.Errorf("kenko: invalid announcement state %q", state)
+	}
+
+	c.announcements.mu.Lock()
+	defer c.announcements.mu.Unlock()
+
+	i := slices.IndexFunc(c.announcements.list, func(a Announcement) bool { return a.ID == id })
+	if i < 0 {
+		return Announcement{}, ErrNoAnnouncement
+	}
+
+	list := slices.Clone(c.announcements.list)
+	a := &list[i]
+	if state == "" {
+		state = a.State
+	}
+	now := time.Now()
+	a.State = state
+	a.Updates = append(slices.Clone(a.Updates), AnnouncementUpdate{State: state, Message: message, At: now})
+	switch {
+	case state == AnnouncementResolved && a.ResolvedAt == nil:
+		a.ResolvedAt = &now
+	case state != AnnouncementResolved:
+		a.ResolvedAt = nil
+	}
+	if err := c.saveState(ctx, announcementsStateKey, list); err != nil {
+		return Announcement{}, &saveError{err: err}
+	}
+	c.announcements.list = list
+	return *a, nil
+}
+
+// DeleteAnnouncement removes an announcement, e.g. one posted by mistake.
+func (c *Checker) DeleteAnnouncement(ctx context.Context, id string) error {
+	c.announcements.mu.Lock()
+	defer c.announcements.mu.Unlock()
+
+	i := slices.IndexFunc(c.announcements.list, func(a Announcement) bool { return a.ID == id })
+	if i < 0 {
+		return ErrNoAnnouncement
+	}
+	list := slices.Delete(slices.Clone(c.announcements.list), i, i+1)
+	if err := c.saveState(ctx, announcementsStateKey, list); err != nil {
+		return err
+	}
+	c.announcements.list = list
+	return nil
+}
+
+// currentAnnouncements returns the announcements shown on the status page:
+// open ones and those resolved recently, newest first.
+func (c *Checker) currentAnnouncements(now time.Time) []Announcement {
+	var out []Announcement
+	for _, a := range c.Announcements() {
+		if a.ResolvedAt != nil && now.Sub(*a.ResolvedAt) > resolvedAnnouncementAge {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// loadAnnouncements restores persisted announcements unless some were posted
+// in memory already.
+func (c *Checker) loadAnnouncements(ctx context.Context) error {
+	var stored []Announcement
+	if ok, err := c.loadState(ctx, announcementsStateKey, &stored); !ok {
+		return err
+	}
+
+	c.announcements.mu.Lock()
+	defer c.announcements.mu.Unlock()
+	if len(c.announcements.list) == 0 {
+		c.announcements.list = stored
+	}
+	return nil
+}
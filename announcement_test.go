@@ -0,0 +1,111 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func announcementChecker(t *testing.T, store Store) *Checker {
+	t.Helper()
+	c, err := NewChecker(WithStore(store), WithNameNormalizer(SlugName), WithTarget("api", "https://example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestAnnouncements_Lifecycle(t *testing.T) {
+	c := announcementChecker(t, NewMemoryStore())
+	ctx := context.Background()
+
+	a, err := c.PostAnnouncement(ctx, "Elevated errors", "We are looking into it.", "", []string{"API"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.State != AnnouncementInvestigating || a.Components[0] != "api" {
+		t.Errorf("announcement = %+v, want investigating on api", a)
+	}
+
+	a, err = c.UpdateAnnouncement(ctx, a.ID, "Fixed by rolling back.", AnnouncementResolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ResolvedAt == nil || len(a.Updates) != 2 {
+		t.Errorf("announcement = %+v, want resolved with two updates", a)
+	}
+
+	if got := c.currentAnnouncements(time.Now()); len(got) != 1 {
+		t.Errorf("current = %d, want the recently resolved announcement", len(got))
+	}
+	if got := c.currentAnnouncements(time.Now().Add(8 * 24 * time.Hour)); len(got) != 0 {
+		t.Errorf("current = %d, want old resolved announcements hidden", len(got))
+	}
+
+	if err := c.DeleteAnnouncement(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteAnnouncement(ctx, a.ID); !errors.Is(err, ErrNoAnnouncement) {
+		t.Errorf("second delete = %v, want ErrNoAnnouncement", err)
+	}
+}
+
+func TestPostAnnouncement_Invalid(t *testing.T) {
+	c := announcementChecker(t, NewMemoryStore())
+	ctx := context.Background()
+
+	if _, err := c.PostAnnouncement(ctx, "", "msg", "", nil); err == nil {
+		t.Error("expected an error for a missing title")
+	}
+	if _, err := c.PostAnnouncement(ctx, "title", "msg", "fixed", nil); err == nil {
+		t.Error("expected an error for an unknown state")
+	}
+	if _, err := c.PostAnnouncement(ctx, "title", "msg", "", []string{"db"}); err == nil {
+		t.Error("expected an error for an unknown component")
+	}
+}
+
+func TestAnnouncements_Persisted(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	a, err := announcementChecker(t, store).PostAnnouncement(ctx, "Maintenance", "Upgrading the database.", AnnouncementMonitoring, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := announcementChecker(t, store)
+	if err := c.loadAnnouncements(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := c.Announcements()
+	if len(got) != 1 || got[0].ID != a.ID || got[0].State != AnnouncementMonitoring {
+		t.Errorf("announcements = %+v, want the persisted one", got)
+	}
+}
+
+func TestAnnouncements_SaveFails(t *testing.T) {
+	store := NewMemoryStore()
+	c := announcementChecker(t, store)
+	ctx := context.Background()
+	a, err := c.PostAnnouncement(ctx, "Maintenance", "Upgrading the database.", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.store = failingStateStore{store}
+	if _, err := c.PostAnnouncement(ctx, "Outage", "Looking into it.", "", nil); !errors.As(err, new(*saveError)) {
+		t.Errorf("post err = %v, want a save error", err)
+	}
+	if _, err := c.UpdateAnnouncement(ctx, a.ID, "Done.", AnnouncementResolved); !errors.As(err, new(*saveError)) {
+		t.Errorf("update err = %v, want a save error", err)
+	}
+	if err := c.DeleteAnnouncement(ctx, a.ID); err == nil {
+		t.Error("expected an error deleting when the state cannot be saved")
+	}
+	got := c.Announcements()
+	if len(got) != 1 || got[0].State != AnnouncementInvestigating || len(got[0].Updates) != 1 {
+		t.Errorf("announcements = %+v, want only the saved one, unchanged", got)
+	}
+}
@@ -0,0 +1,115 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultArchiveInterval is how often old results are archived and pruned.
+const defaultArchiveInterval = time.Hour
+
+// Archiver receives results that are about to be pruned from the store, e.g.
+// to upload them to object storage for long-term evidence.
+type Archiver interface {
+	// Archive stores the target's results, oldest first. the results are
+	// only pruned from the store once it returned nil.
+	Archive(ctx context.Context, name string, results []Result) error
+}
+
+// HistoryPruner is implemented by history stores that can drop old results.
+type HistoryPruner interface {
+	// PruneHistory removes the target's results checked before before and
+	// reports how many were removed.
+	PruneHistory(ctx context.Context, name string, before time.Time) (int, error)
+}
+
+// Archive configures moving old results out of the store.
+type Archive struct {
+	// Archiver receives the results.
+	Archiver Archiver
+	// After is the age past which results are archived and pruned.
+	After time.Duration
+	// Interval between archive runs (default 1h).
+	Interval time.Duration
+}
+
+// WithArchive periodically hands results older than a.After to a.Archiver and
+// then prunes them from the store, which must implement HistoryStore and
+// HistoryPruner.
+func WithArchive(a Archive) Option {
+	return func(o *options) { o.archive = &a }
+}
+
+func validateArchive(a *Archive, store Store) error {
+	if a == nil {
+		return nil
+	}
+	if a.Archiver == nil {
+		return fmt.Errorf("kenko: archive needs an archiver")
+	}
+	if a.After <= 0 {
+		return fmt.Errorf("kenko: archive age must be positive, got %s", a.After)
+	}
+	if a.Interval < 0 {
+		return fmt.Errorf("kenko: archive interval must not be negative, got %s", a.Interval)
+	}
+	_, keeps := store.(HistoryStore)
+	_, prunes := store.(HistoryPruner)
+	if !keeps || !prunes {
+		return fmt.Errorf("kenko: archive needs a store that keeps and prunes history, got %T", store)
+	}
+	return nil
+}
+
+func (c *Checker) runArchive(ctx context.Context) {
+	ticker := time.NewTicker(cmp.Or(c.archive.Interval, defaultArchiveInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.life.stop:
+			return
+		case <-ticker.C:
+		}
+		c.archiveOld(ctx, time.Now().Add(-c.archive.After))
+	}
+}
+
+// archiveOld archives and prunes every target's results checked before
+// cutoff. a target whose results fail to archive keeps them, and is retried
+// on the next run.
+func (c *Checker) archiveOld(ctx context.Context, cutoff time.Time) {
+	hs := c.store.(HistoryStore)
+	pruner := c.store.(HistoryPruner)
+
+	for _, t := range c.targetList() {
+		results, err := hs.History(ctx, t.Name, HistoryQuery{To: cutoff})
+		if err != nil {
+			c.logger.Warn("failed to read results to archive", "target", t.Name, "error", err)
+			continue
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		// history is newest first; archives read oldest first
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+		if err := c.archive.Archiver.Archive(ctx, t.Name, results); err != nil {
+			c.logger.Warn("failed to archive results", "target", t.Name, "results", len(results), "error", err)
+			continue
+		}
+
+		pruned, err := pruner.PruneHistory(ctx, t.Name, cutoff)
+		if err != nil {
+			c.logger.Warn("failed to prune archived results", "target", t.Name, "error", err)
+			continue
+		}
+		c.logger.Info("archived results", "target", t.Name, "archived", len(results), "pruned", pruned)
+	}
+}
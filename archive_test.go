@@ -0,0 +1,71 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type recordingArchiver struct {
+	archived map[string][]Result
+	err      error
+}
+
+func (a *recordingArchiver) Archive(_ context.Context, name string, results []Result) error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.archived == nil {
+		a.archived = make(map[string][]Result)
+	}
+	a.archived[name] = append(a.archived[name], results...)
+	return nil
+}
+
+func TestArchiveOld(t *testing.T) {
+	store := NewMemoryStore()
+	archiver := &recordingArchiver{}
+	c, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithStore(store),
+		WithArchive(Archive{Archiver: archiver, After: time.Hour}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for _, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Minute} {
+		store.Set(context.Background(), "api", Result{Target: "api", Status: StatusHealthy, CheckedAt: now.Add(-age)})
+	}
+
+	archiver.err = errors.New("bucket unavailable")
+	c.archiveOld(context.Background(), now.Add(-time.Hour))
+	if kept, _ := store.History(context.Background(), "api", HistoryQuery{}); len(kept) != 3 {
+		t.Fatalf("kept %d results after a failed archive, want all 3", len(kept))
+	}
+
+	archiver.err = nil
+	c.archiveOld(context.Background(), now.Add(-time.Hour))
+	archived := archiver.archived["api"]
+	if len(archived) != 2 || !archived[0].CheckedAt.Equal(now.Add(-3*time.Hour)) {
+		t.Errorf("archived = %+v, want the two old results, oldest first", archived)
+	}
+	if kept, _ := store.History(context.Background(), "api", HistoryQuery{}); len(kept) != 1 {
+		t.Errorf("kept %d results, want the recent one", len(kept))
+	}
+}
+
+func TestNew_InvalidArchive(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"no archiver": {WithArchive(Archive{After: time.Hour})},
+		"no age":      {WithArchive(Archive{Archiver: &recordingArchiver{}})},
+		"no history":  {WithArchive(Archive{Archiver: &recordingArchiver{}, After: time.Hour}), WithStore(struct{ Store }{NewMemoryStore()})},
+	} {
+		opts = append(opts, WithTarget("api", "https://api.example.com"))
+		if _, err := NewChecker(opts...); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
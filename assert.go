@@ -0,0 +1,51 @@
+package kenko
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+)
+
+// HeaderAssertion requires a response header to be present and, optionally,
+// to equal Value or match Pattern. with neither set only presence is checked.
+type HeaderAssertion struct {
+	Name    string
+	Value   string
+	Pattern *regexp.Regexp
+}
+
+func (a HeaderAssertion) check(h http.Header) error {
+	values := h.Values(a.Name)
+	if len(values) == 0 {
+		return fmt.Errorf("header %s missing", a.Name)
+	}
+
+	got := values[0]
+	switch {
+	case a.Pattern != nil && !a.Pattern.MatchString(got):
+		return fmt.Errorf("header %s = %q, want match for %q", a.Name, got, a.Pattern)
+	case a.Value != "" && got != a.Value:
+		return fmt.Errorf("header %s = %q, want %q", a.Name, got, a.Value)
+	}
+	return nil
+}
+
+// statusOK reports whether code counts as healthy for the target: one of its
+// expected codes, or any code below 400 when none are set.
+func statusOK(target Target, code int) bool {
+	if len(target.ExpectStatus) == 0 {
+		return code < 400
+	}
+	return slices.Contains(target.ExpectStatus, code)
+}
+
+// checkHeaders returns the first failing header assertion of the target, if any.
+func checkHeaders(target Target, h http.Header) error {
+	for _, a := range target.HeaderAssertions {
+		if err := a.check(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestHeaderAssertion(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Env", "prod")
+	h.Set("Strict-Transport-Security", "max-age=63072000")
+
+	tests := []struct {
+		name    string
+		a       HeaderAssertion
+		wantErr bool
+	}{
+		{"present", HeaderAssertion{Name: "Strict-Transport-Security"}, false},
+		{"missing", HeaderAssertion{Name: "X-Missing"}, true},
+		{"value match", HeaderAssertion{Name: "X-Env", Value: "prod"}, false},
+		{"value mismatch", HeaderAssertion{Name: "X-Env", Value: "staging"}, true},
+		{"pattern match", HeaderAssertion{Name: "strict-transport-security", Pattern: regexp.MustCompile(`max-age=\d+`)}, false},
+		{"pattern mismatch", HeaderAssertion{Name: "X-Env", Pattern: regexp.MustCompile(`^stag`)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.a.check(h)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheck_HeaderAssertionFails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Env", "staging")
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("test", ts.URL, WithHeaderAssertion(HeaderAssertion{Name: "X-Env", Value: "prod"})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+
+	if result.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q", result.Status, StatusUnhealthy)
+	}
+	if result.StatusCode != 200 {
+		t.Errorf("status_code = %d, want 200", result.StatusCode)
+	}
+	if result.Error == "" {
+		t.Error("expected assertion error")
+	}
+}
+
+func TestStatusOK(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect []int
+		code   int
+		want   bool
+	}{
+		{"default ok", nil, 302, true},
+		{"default fail", nil, 404, false},
+		{"expected", []int{200, 204}, 204, true},
+		{"unexpected", []int{204}, 200, false},
+		{"expected error code", []int{401}, 401, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusOK(Target{ExpectStatus: tt.expect}, tt.code); got != tt.want {
+				t.Errorf("statusOK(%v, %d) = %v, want %v", tt.expect, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_ExpectStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("test", ts.URL, WithExpectStatus(204)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+
+	if result.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q", result.Status, StatusUnhealthy)
+	}
+	if result.Error != "status 200, want one of [204]" {
+		t.Errorf("error = %q", result.Error)
+	}
+}
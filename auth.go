@@ -0,0 +1,30 @@
+package kenko
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// WithAPIToken enables the endpoints that change what kenko checks or sends:
+// the target, pause, silence, incident exclusion, announcement, and notifier
+// test endpoints. requests to them must carry token as a bearer token.
+// without it RegisterHandlers leaves them out, as anyone who can reach the
+// port could otherwise point checks at internal addresses.
+func WithAPIToken(token string) Option {
+	return func(o *options) { o.apiToken = token }
+}
+
+// requireToken wraps h so it only serves requests authenticated with token,
+// answering 401 otherwise.
+func requireToken(token string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kenko"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid api token"})
+			return
+		}
+		h(w, r)
+	}
+}
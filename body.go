@@ -0,0 +1,90 @@
+package kenko
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultFailureBodyBytes is how much of a failed check's response body is
+// kept when the target doesn't set FailureBodyBytes.
+const defaultFailureBodyBytes = 512
+
+// bodyReader caps how much of a response body is read and counts the bytes
+// that were.
+type bodyReader struct {
+	r io.Reader
+	n int64
+
+	// head holds the first keep bytes read, for failure snippets.
+	head []byte
+	keep int
+}
+
+// newBodyReader wraps body, stopping after limit bytes (or maxBodyBytes when
+// limit is not positive).
+func newBodyReader(body io.Reader, limit int64) *bodyReader {
+	if limit <= 0 || limit > maxBodyBytes {
+		limit = maxBodyBytes
+	}
+	return &bodyReader{r: io.LimitReader(body, limit)}
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	if room := b.keep - len(b.head); room > 0 {
+		b.head = append(b.head, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// drain discards whatever is left up to the limit, letting the connection be
+// reused when the whole body fits.
+func (b *bodyReader) drain() {
+	_, _ = io.Copy(io.Discard, b)
+}
+
+// failureBodyLimit returns how many body bytes to keep when the target fails,
+// or 0 when snippets are disabled.
+func failureBodyLimit(t Target) int {
+	switch {
+	case t.FailureBodyBytes < 0:
+		return 0
+	case t.FailureBodyBytes == 0:
+		return defaultFailureBodyBytes
+	default:
+		return t.FailureBodyBytes
+	}
+}
+
+// snippet returns up to n bytes of body as printable UTF-8. invalid bytes and
+// control characters other than newline and tab are replaced, and a rune cut
+// off by the limit is dropped.
+func snippet(body []byte, n int) string {
+	if len(body) > n {
+		body = body[:n]
+		for i := 0; i < utf8.UTFMax && len(body) > 0; i++ {
+			if r, size := utf8.DecodeLastRune(body); r != utf8.RuneError || size != 1 {
+				break
+			}
+			body = body[:len(body)-1]
+		}
+	}
+
+	var b strings.Builder
+	for len(body) > 0 {
+		r, size := utf8.DecodeRune(body)
+		body = body[size:]
+		switch {
+		case r == '\n' || r == '\t':
+			b.WriteRune(r)
+		case r == utf8.RuneError, unicode.IsControl(r):
+			b.WriteRune(utf8.RuneError)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
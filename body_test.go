@@ -0,0 +1,116 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheck_BodyBytes(t *testing.T) {
+	payload := strings.Repeat("x", 4096)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("full", ts.URL),
+		WithTarget("capped", ts.URL, WithMaxBodyBytes(100)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.BodyBytes != 4096 {
+		t.Errorf("full body_bytes = %d, want 4096", r.BodyBytes)
+	}
+	if r := c.check(context.Background(), c.targets[1]); r.BodyBytes != 100 {
+		t.Errorf("capped body_bytes = %d, want 100", r.BodyBytes)
+	}
+}
+
+func TestCheck_HeadMethod(t *testing.T) {
+	var method string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("head", ts.URL, WithMethod(http.MethodHead)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.check(context.Background(), c.targets[0])
+	if method != http.MethodHead {
+		t.Errorf("method = %q, want HEAD", method)
+	}
+	if r.BodyBytes != 0 {
+		t.Errorf("body_bytes = %d, want 0 for HEAD", r.BodyBytes)
+	}
+	if r.Status != StatusHealthy {
+		t.Errorf("status = %q, want %q", r.Status, StatusHealthy)
+	}
+}
+
+func TestCheck_FailureBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			_, _ = w.Write([]byte("fine"))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream\x00 timed out " + strings.Repeat("x", 1000)))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("down", ts.URL+"/down"),
+		WithTarget("short", ts.URL+"/down", WithFailureBody(8)),
+		WithTarget("off", ts.URL+"/down", WithFailureBody(-1)),
+		WithTarget("up", ts.URL+"/ok"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.check(context.Background(), c.targets[0])
+	if !strings.HasPrefix(r.Body, "upstream� timed out") || len(r.Body) > defaultFailureBodyBytes+2 {
+		t.Errorf("body = %.40q (len %d), want a sanitized %d byte snippet", r.Body, len(r.Body), defaultFailureBodyBytes)
+	}
+	if r.BodyBytes <= int64(defaultFailureBodyBytes) {
+		t.Errorf("body_bytes = %d, want the full body counted", r.BodyBytes)
+	}
+
+	if r := c.check(context.Background(), c.targets[1]); r.Body != "upstream" {
+		t.Errorf("short body = %q, want %q", r.Body, "upstream")
+	}
+	if r := c.check(context.Background(), c.targets[2]); r.Body != "" {
+		t.Errorf("disabled body = %q, want empty", r.Body)
+	}
+	if r := c.check(context.Background(), c.targets[3]); r.Body != "" {
+		t.Errorf("healthy body = %q, want empty", r.Body)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"hello\nworld\t!", 100, "hello\nworld\t!"},
+		{"bell\x07", 100, "bell�"},
+		{"héllo", 2, "h"},
+		{"\xffbad", 100, "�bad"},
+	}
+
+	for _, tt := range tests {
+		if got := snippet([]byte(tt.in), tt.n); got != tt.want {
+			t.Errorf("snippet(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+// Package boltstore is a kenko Store kept in a local bbolt file, for
+// deployments without an external store or cgo, such as edge appliances.
+// besides the latest result of each target it keeps their recent history, a
+// log of status changes, and a log of notification deliveries.
+package boltstore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	defaultHistoryLen = 1000
+
+	// openTimeout bounds waiting for another process's lock on the file.
+	openTimeout = 5 * time.Second
+)
+
+var (
+	resultsBucket = []byte("results")
+	stateBucket   = []byte("state")
+	historyBucket = []byte("history")
+	// countsBucket holds how many keys each capped log holds, so an insert
+	// trims the log without walking it.
+	countsBucket = []byte("counts")
+)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithHistory sets how many results are kept per target (default 1000). 0
+// keeps only the latest result.
+func WithHistory(maxLen int) Option {
+	return func(s *Store) { s.historyLen = maxLen }
+}
+
+// Store is a kenko Store backed by a bbolt database file.
+type Store struct {
+	path       string
+	historyLen int
+
+	mu sync.Mutex
+	db *bolt.DB
+}
+
+// New creates a Store kept in the file at path. the file is created and
+// opened when the store is first used, and stays locked against other
+// processes until Close.
+func New(path string, opts ...Option) *Store {
+	s := &Store{path: path, historyLen: defaultHistoryLen}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// open returns the database, opening it and creating its buckets on first
+// use. a failed open is retried by the next call.
+func (s *Store) open() (*bolt.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db != nil {
+		return s.db, nil
+	}
+
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: open %s: %w", s.path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{resultsBucket, stateBucket, historyBucket, eventsBucket, deliveriesBucket, rollupsBucket, countsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("boltstore: create buckets: %w", err)
+	}
+	s.db = db
+	return db, nil
+}
+
+// Set stores a result keyed by target name and appends it to its history.
+func (s *Store) Set(ctx context.Context, name string, result kenko.Result) error {
+	result.Target = name
+	return s.SetBatch(ctx, []kenko.Result{result})
+}
+
+// SetBatch stores several results in one transaction, keyed by their target
+// name.
+func (s *Store) SetBatch(_ context.Context, results []kenko.Result) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, r := range results {
+			if err := s.put(tx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) put(tx *bolt.Tx, r kenko.Result) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("boltstore: marshal: %w", err)
+	}
+	if err := tx.Bucket(resultsBucket).Put([]byte(r.Target), data); err != nil {
+		return fmt.Errorf("boltstore: put %q: %w", r.Target, err)
+	}
+	if s.historyLen <= 0 {
+		return nil
+	}
+
+	b, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(r.Target))
+	if err != nil {
+		return fmt.Errorf("boltstore: history %q: %w", r.Target, err)
+	}
+	seq, _ := b.NextSequence()
+	if err := b.Put(historyKey(checkedAt(r), seq), data); err != nil {
+		return fmt.Errorf("boltstore: history %q: %w", r.Target, err)
+	}
+	if err := trim(tx, b, historyCount(r.Target), s.historyLen); err != nil {
+		return fmt.Errorf("boltstore: trim history %q: %w", r.Target, err)
+	}
+	return nil
+}
+
+// historyCount is the counts key of a target's history.
+func historyCount(name string) string { return "history/" + name }
+
+// trim drops the oldest entries of b past limit after one was added to it,
+// deleting forward from the first. how many b holds is kept in the counts
+// bucket under name; a log written before counts were kept is counted once.
+func trim(tx *bolt.Tx, b *bolt.Bucket, name string, limit int) error {
+	counts := tx.Bucket(countsBucket)
+	c := b.Cursor()
+	var n int
+	if v := counts.Get([]byte(name)); len(v) == 8 {
+		n = int(binary.BigEndian.Uint64(v)) + 1
+	} else {
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			n++
+		}
+	}
+	for ; n > limit; n-- {
+		if k, _ := c.First(); k == nil {
+			n = 0
+			break
+		}
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return counts.Put([]byte(name), binary.BigEndian.AppendUint64(nil, uint64(n)))
+}
+
+// uncount lowers the count kept under name by the n entries a prune removed.
+func uncount(tx *bolt.Tx, name string, n int) error {
+	counts := tx.Bucket(countsBucket)
+	v := counts.Get([]byte(name))
+	if len(v) != 8 || n == 0 {
+		return nil
+	}
+	left := max(int(binary.BigEndian.Uint64(v))-n, 0)
+	return counts.Put([]byte(name), binary.BigEndian.AppendUint64(nil, uint64(left)))
+}
+
+// deleteKeys removes keys collected by a cursor, which must not delete while
+// it iterates.
+func deleteKeys(b *bolt.Bucket, keys [][]byte) error {
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAll returns the latest result of every target.
+func (s *Store) GetAll(_ context.Context) (map[string]kenko.Result, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string][]byte)
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(resultsBucket).ForEach(func(k, v []byte) error {
+			vals[string(k)] = append([]byte(nil), v...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: read results: %w", err)
+	}
+	return decodeResults(vals), nil
+}
+
+// Delete removes the result and history stored for name.
+func (s *Store) Delete(_ context.Context, name string) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(resultsBucket).Delete([]byte(name)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(historyBucket).DeleteBucket([]byte(name)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		return tx.Bucket(countsBucket).Delete([]byte(historyCount(name)))
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: delete %q: %w", name, err)
+	}
+	return nil
+}
+
+// History returns the target's kept results matching q, newest first.
+func (s *Store) History(_ context.Context, name string, q kenko.HistoryQuery) ([]kenko.Result, error) {
+	if s.historyLen <= 0 {
+		return nil, kenko.ErrNoHistory
+	}
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []kenko.Result
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket).Bucket([]byte(name))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		var k, v []byte
+		if q.To.IsZero() {
+			k, v = c.Last()
+		} else {
+			// seek past every entry checked at or before To, then step back
+			k, v = c.Seek(historyKey(q.To.Add(1), 0))
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if !q.From.IsZero() && keyTime(k).Before(q.From) {
+				break
+			}
+			var r kenko.Result
+			if err := json.Unmarshal(v, &r); err != nil {
+				r = kenko.Result{Target: name, Status: kenko.StatusUnknown, CheckedAt: keyTime(k), Error: fmt.Sprintf("boltstore: unmarshal: %v", err)}
+			}
+			out = append(out, r)
+			if q.Limit > 0 && len(out) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: history %q: %w", name, err)
+	}
+	return out, nil
+}
+
+// PruneHistory drops the target's results checked before before.
+func (s *Store) PruneHistory(_ context.Context, name string, before time.Time) (int, error) {
+	db, err := s.open()
+	if err != nil {
+		return 0, err
+	}
+
+	var pruned int
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket).Bucket([]byte(name))
+		if b == nil {
+			return nil
+		}
+		var old [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && keyTime(k).Before(before); k, _ = c.Next() {
+			old = append(old, append([]byte(nil), k...))
+		}
+		pruned = len(old)
+		if err := deleteKeys(b, old); err != nil {
+			return err
+		}
+		return uncount(tx, historyCount(name), pruned)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("boltstore: prune history %q: %w", name, err)
+	}
+	return pruned, nil
+}
+
+// SaveState stores an operator state blob under key.
+func (s *Store) SaveState(_ context.Context, key string, data []byte) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).Put([]byte(key), data)
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: save state %q: %w", key, err)
+	}
+	return nil
+}
+
+// LoadState returns the operator state blob stored under key, or nil if unset.
+func (s *Store) LoadState(_ context.Context, key string) ([]byte, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(stateBucket).Get([]byte(key)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: load state %q: %w", key, err)
+	}
+	return data, nil
+}
+
+// Ping opens the database if it is not open yet, reporting whether the file
+// can be used.
+func (s *Store) Ping(_ context.Context) error {
+	_, err := s.open()
+	return err
+}
+
+// Close closes the database file, releasing its lock.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// decodeResults unmarshals stored results. entries that fail to decode are
+// reported with StatusUnknown and the parse error rather than being dropped,
+// so the target stays visible.
+func decodeResults(vals map[string][]byte) map[string]kenko.Result {
+	out := make(map[string]kenko.Result, len(vals))
+	for name, data := range vals {
+		var r kenko.Result
+		if err := json.Unmarshal(data, &r); err != nil {
+			out[name] = kenko.Result{
+				Target: name,
+				Status: kenko.StatusUnknown,
+				Error:  fmt.Sprintf("boltstore: unmarshal: %v", err),
+			}
+			continue
+		}
+		out[name] = r
+	}
+	return out
+}
+
+// historyKey orders history entries by check time, then by insertion for
+// results checked at the same instant.
+func historyKey(at time.Time, seq uint64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(at.UnixNano()))
+	binary.BigEndian.PutUint64(k[8:], seq)
+	return k
+}
+
+func keyTime(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+}
+
+// checkedAt is when the result was checked, or now for results without a
+// check time.
+func checkedAt(r kenko.Result) time.Time {
+	if r.CheckedAt.IsZero() {
+		return time.Now()
+	}
+	return r.CheckedAt
+}
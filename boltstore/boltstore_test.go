@@ -0,0 +1,304 @@
+package boltstore
+
+import (
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newStore(t *testing.T, opts ...Option) *Store {
+	t.Helper()
+	s := New(filepath.Join(t.TempDir(), "kenko.db"), opts...)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore_Results(t *testing.T) {
+	s := newStore(t)
+	ctx := context.Background()
+
+	if err := s.Set(ctx, "api", kenko.Result{Status: kenko.StatusHealthy}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetBatch(ctx, []kenko.Result{{Target: "db", Status: kenko.StatusUnhealthy}}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := s.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all["api"].Target != "api" || all["db"].Status != kenko.StatusUnhealthy {
+		t.Errorf("results = %+v", all)
+	}
+
+	if err := s.Delete(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := s.GetAll(ctx); len(all) != 1 {
+		t.Errorf("after delete = %+v", all)
+	}
+	if h, _ := s.History(ctx, "api", kenko.HistoryQuery{}); len(h) != 0 {
+		t.Errorf("history after delete = %+v", h)
+	}
+}
+
+func TestStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kenko.db")
+	ctx := context.Background()
+
+	s := New(path)
+	s.Set(ctx, "api", kenko.Result{Status: kenko.StatusHealthy})
+	s.SaveState(ctx, "silences", []byte(`[]`))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = New(path)
+	defer s.Close()
+	if all, err := s.GetAll(ctx); err != nil || all["api"].Status != kenko.StatusHealthy {
+		t.Errorf("reopened results = %+v, %v", all, err)
+	}
+	if data, err := s.LoadState(ctx, "silences"); err != nil || string(data) != `[]` {
+		t.Errorf("reopened state = %q, %v", data, err)
+	}
+	if data, err := s.LoadState(ctx, "unset"); err != nil || data != nil {
+		t.Errorf("unset state = %q, %v", data, err)
+	}
+}
+
+func TestStore_History(t *testing.T) {
+	s := newStore(t, WithHistory(3))
+	ctx := context.Background()
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		s.Set(ctx, "api", kenko.Result{StatusCode: i, CheckedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	codes := func(q kenko.HistoryQuery) []int {
+		results, err := s.History(ctx, "api", q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []int
+		for _, r := range results {
+			out = append(out, r.StatusCode)
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		q    kenko.HistoryQuery
+		want []int
+	}{
+		{kenko.HistoryQuery{}, []int{4, 3, 2}},
+		{kenko.HistoryQuery{Limit: 1}, []int{4}},
+		{kenko.HistoryQuery{To: start.Add(3 * time.Minute)}, []int{3, 2}},
+		{kenko.HistoryQuery{From: start.Add(3 * time.Minute)}, []int{4, 3}},
+	} {
+		got := codes(tc.q)
+		if len(got) != len(tc.want) {
+			t.Errorf("History(%+v) = %v, want %v", tc.q, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("History(%+v) = %v, want %v", tc.q, got, tc.want)
+				break
+			}
+		}
+	}
+
+	if n, err := s.PruneHistory(ctx, "api", start.Add(4*time.Minute)); err != nil || n != 2 {
+		t.Errorf("pruned %d, %v, want 2", n, err)
+	}
+	if got := codes(kenko.HistoryQuery{}); len(got) != 1 || got[0] != 4 {
+		t.Errorf("after prune = %v, want [4]", got)
+	}
+}
+
+func TestStore_HistoryOff(t *testing.T) {
+	s := newStore(t, WithHistory(0))
+	if _, err := s.History(context.Background(), "api", kenko.HistoryQuery{}); err != kenko.ErrNoHistory {
+		t.Errorf("err = %v, want ErrNoHistory", err)
+	}
+}
+
+func TestStore_OpenError(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "missing", "kenko.db"))
+	if err := s.Ping(context.Background()); err == nil {
+		t.Error("opening a file in a missing directory succeeded")
+	}
+}
+
+func TestStore_Events(t *testing.T) {
+	s := newStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	for i, e := range []kenko.Event{
+		{Target: "api", From: kenko.StatusHealthy, To: kenko.StatusUnhealthy},
+		{Target: "db", From: kenko.StatusHealthy, To: kenko.StatusUnhealthy},
+		{Target: "api", From: kenko.StatusUnhealthy, To: kenko.StatusHealthy, Duration: 2 * time.Minute},
+	} {
+		e.At = at.Add(time.Duration(i) * time.Minute)
+		if err := s.AddEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err := s.Events(ctx, kenko.EventQuery{Target: "api", To: at.Add(2 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].To != kenko.StatusHealthy || events[0].Duration != 2*time.Minute {
+		t.Errorf("api events = %+v, want the recovery first", events)
+	}
+
+	if n, err := s.PruneTransitions(ctx, at.Add(time.Minute)); err != nil || n != 1 {
+		t.Errorf("pruned %d, %v, want 1", n, err)
+	}
+	if events, _ := s.Events(ctx, kenko.EventQuery{Limit: 5}); len(events) != 2 {
+		t.Errorf("after prune = %+v, want 2 events", events)
+	}
+}
+
+func TestStore_Deliveries(t *testing.T) {
+	s := newStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	for i, d := range []kenko.Delivery{
+		{Notifier: "pager", Target: "api", Outcome: kenko.NotificationFailed, Error: "status 503"},
+		{Notifier: "chat", Target: "api", Outcome: kenko.NotificationDelivered},
+		{Notifier: "pager", Target: "api", Outcome: kenko.NotificationDelivered, Attempts: 2},
+	} {
+		d.At = at.Add(time.Duration(i) * time.Minute)
+		if err := s.AddDelivery(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliveries, err := s.Deliveries(ctx, kenko.DeliveryQuery{Notifier: "pager", To: at.Add(2 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 || deliveries[0].Attempts != 2 || deliveries[1].Error != "status 503" {
+		t.Errorf("pager deliveries = %+v, want the retried delivery first", deliveries)
+	}
+	if deliveries, _ := s.Deliveries(ctx, kenko.DeliveryQuery{From: at.Add(time.Minute), Limit: 1}); len(deliveries) != 1 || !deliveries[0].At.Equal(at.Add(2*time.Minute)) {
+		t.Errorf("newest since 12:01 = %+v", deliveries)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	s := newStore(t)
+	db, err := s.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(eventsBucket)
+		// two entries from before counts were kept are counted once
+		for i := range 2 {
+			b.Put(historyKey(at.Add(time.Duration(i)*time.Minute), uint64(i)), []byte("{}"))
+		}
+		for i := 2; i < 6; i++ {
+			b.Put(historyKey(at.Add(time.Duration(i)*time.Minute), uint64(i)), []byte("{}"))
+			if err := trim(tx, b, "events", 3); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(eventsBucket)
+		if k, _ := b.Cursor().First(); b.Stats().KeyN != 3 || !keyTime(k).Equal(at.Add(3*time.Minute)) {
+			t.Errorf("kept %d entries from %s, want the newest 3", b.Stats().KeyN, keyTime(k))
+		}
+		if n := binary.BigEndian.Uint64(tx.Bucket(countsBucket).Get([]byte("events"))); n != 3 {
+			t.Errorf("count = %d, want 3", n)
+		}
+		return nil
+	})
+}
+
+func TestStore_CountsAfterPrune(t *testing.T) {
+	s := newStore(t, WithHistory(3))
+	ctx := context.Background()
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	set := func(i int) {
+		if err := s.Set(ctx, "api", kenko.Result{Target: "api", Status: kenko.StatusHealthy, CheckedAt: at.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range 3 {
+		set(i)
+	}
+	if n, err := s.PruneHistory(ctx, "api", at.Add(2*time.Minute)); err != nil || n != 2 {
+		t.Fatalf("pruned %d, %v, want 2", n, err)
+	}
+	set(3)
+	set(4)
+	if h, _ := s.History(ctx, "api", kenko.HistoryQuery{}); len(h) != 3 {
+		t.Errorf("history = %d results, want 3 after the prune freed room", len(h))
+	}
+
+	// a target deleted and added again starts its count afresh
+	if err := s.Delete(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	set(5)
+	set(6)
+	if h, _ := s.History(ctx, "api", kenko.HistoryQuery{}); len(h) != 2 {
+		t.Errorf("history = %d results, want 2 after the delete", len(h))
+	}
+}
+
+func TestStore_Rollups(t *testing.T) {
+	s := newStore(t)
+	ctx := context.Background()
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	rollups := []kenko.Rollup{
+		{Start: start, Checks: 1},
+		{Start: start.Add(time.Hour), Checks: 2, Down: 1, MaxLatency: time.Second},
+		{Start: start.Add(2 * time.Hour), Checks: 3},
+	}
+	if err := s.SaveRollups(ctx, "api", kenko.HourlyRollups, rollups); err != nil {
+		t.Fatal(err)
+	}
+	rollups[1].Checks = 5
+	s.SaveRollups(ctx, "api", kenko.HourlyRollups, rollups[1:2])
+	s.SaveRollups(ctx, "api", kenko.FiveMinuteRollups, rollups[:1])
+
+	got, err := s.Rollups(ctx, "api", kenko.HourlyRollups, start.Add(time.Minute), start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Checks != 5 || got[0].MaxLatency != time.Second {
+		t.Errorf("rollups = %+v, want the replaced second hour", got)
+	}
+	if got, _ := s.Rollups(ctx, "web", kenko.HourlyRollups, time.Time{}, time.Time{}); len(got) != 0 {
+		t.Errorf("rollups of an unknown target = %+v", got)
+	}
+
+	if n, err := s.PruneRollups(ctx, kenko.HourlyRollups, start.Add(2*time.Hour)); err != nil || n != 2 {
+		t.Errorf("pruned %d, %v, want 2", n, err)
+	}
+	if got, _ := s.Rollups(ctx, "api", kenko.FiveMinuteRollups, time.Time{}, time.Time{}); len(got) != 1 {
+		t.Errorf("5m rollups = %+v, want them kept", got)
+	}
+}
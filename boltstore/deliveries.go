@@ -0,0 +1,84 @@
+package boltstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aidantrabs/kenko"
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxDeliveries is how many notification deliveries are kept across
+// notifiers.
+const maxDeliveries = 10000
+
+var deliveriesBucket = []byte("deliveries")
+
+// AddDelivery appends a notification delivery to the log, dropping the oldest
+// beyond 10000.
+func (s *Store) AddDelivery(_ context.Context, d kenko.Delivery) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("boltstore: marshal delivery: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deliveriesBucket)
+		seq, _ := b.NextSequence()
+		if err := b.Put(historyKey(d.At, seq), data); err != nil {
+			return err
+		}
+		return trim(tx, b, string(deliveriesBucket), maxDeliveries)
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: add delivery: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the kept deliveries matching q, newest first.
+func (s *Store) Deliveries(_ context.Context, q kenko.DeliveryQuery) ([]kenko.Delivery, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []kenko.Delivery
+	err = db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(deliveriesBucket).Cursor()
+		var k, v []byte
+		if q.To.IsZero() {
+			k, v = c.Last()
+		} else {
+			k, v = c.Seek(historyKey(q.To.Add(1), 0))
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if !q.From.IsZero() && keyTime(k).Before(q.From) {
+				break
+			}
+			var d kenko.Delivery
+			if err := json.Unmarshal(v, &d); err != nil || q.Target != "" && d.Target != q.Target || q.Notifier != "" && d.Notifier != q.Notifier {
+				continue
+			}
+			out = append(out, d)
+			if q.Limit > 0 && len(out) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: deliveries: %w", err)
+	}
+	return out, nil
+}
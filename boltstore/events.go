@@ -0,0 +1,110 @@
+package boltstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxEvents is how many events are kept across targets.
+const maxEvents = 10000
+
+var eventsBucket = []byte("events")
+
+// AddEvent appends an event to the log, dropping the oldest beyond 10000.
+func (s *Store) AddEvent(_ context.Context, e kenko.Event) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("boltstore: marshal event: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(eventsBucket)
+		seq, _ := b.NextSequence()
+		if err := b.Put(historyKey(e.At, seq), data); err != nil {
+			return err
+		}
+		return trim(tx, b, string(eventsBucket), maxEvents)
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: add event: %w", err)
+	}
+	return nil
+}
+
+// Events returns the kept events matching q, newest first.
+func (s *Store) Events(_ context.Context, q kenko.EventQuery) ([]kenko.Event, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []kenko.Event
+	err = db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(eventsBucket).Cursor()
+		var k, v []byte
+		if q.To.IsZero() {
+			k, v = c.Last()
+		} else {
+			k, v = c.Seek(historyKey(q.To.Add(1), 0))
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if !q.From.IsZero() && keyTime(k).Before(q.From) {
+				break
+			}
+			var e kenko.Event
+			if err := json.Unmarshal(v, &e); err != nil || q.Target != "" && e.Target != q.Target {
+				continue
+			}
+			out = append(out, e)
+			if q.Limit > 0 && len(out) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: events: %w", err)
+	}
+	return out, nil
+}
+
+// PruneTransitions drops the events from before before.
+func (s *Store) PruneTransitions(_ context.Context, before time.Time) (int, error) {
+	db, err := s.open()
+	if err != nil {
+		return 0, err
+	}
+
+	var pruned int
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(eventsBucket)
+		var old [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && keyTime(k).Before(before); k, _ = c.Next() {
+			old = append(old, append([]byte(nil), k...))
+		}
+		pruned = len(old)
+		if err := deleteKeys(b, old); err != nil {
+			return err
+		}
+		return uncount(tx, string(eventsBucket), pruned)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("boltstore: prune events: %w", err)
+	}
+	return pruned, nil
+}
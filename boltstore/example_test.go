@@ -0,0 +1,17 @@
+package boltstore_test
+
+import (
+	"fmt"
+
+	"github.com/aidantrabs/kenko/boltstore"
+)
+
+func ExampleNew() {
+	store := boltstore.New("/var/lib/kenko/kenko.db",
+		boltstore.WithHistory(500),
+	)
+	defer store.Close()
+
+	fmt.Println(store != nil)
+	// Output: true
+}
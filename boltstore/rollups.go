@@ -0,0 +1,128 @@
+package boltstore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	bolt "go.etcd.io/bbolt"
+)
+
+// rollupsBucket holds a bucket per resolution, each holding a bucket per
+// target keyed by rollup start.
+var rollupsBucket = []byte("rollups")
+
+// SaveRollups stores the target's rollups of the resolution, replacing any
+// with the same start.
+func (s *Store) SaveRollups(_ context.Context, name string, resolution time.Duration, rollups []kenko.Rollup) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		res, err := tx.Bucket(rollupsBucket).CreateBucketIfNotExists([]byte(resolution.String()))
+		if err != nil {
+			return err
+		}
+		b, err := res.CreateBucketIfNotExists([]byte(name))
+		if err != nil {
+			return err
+		}
+		for _, r := range rollups {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(rollupKey(r.Start), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: save rollups %q: %w", name, err)
+	}
+	return nil
+}
+
+// Rollups returns the target's rollups of the resolution starting between
+// from and to, oldest first.
+func (s *Store) Rollups(_ context.Context, name string, resolution time.Duration, from, to time.Time) ([]kenko.Rollup, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []kenko.Rollup
+	err = db.View(func(tx *bolt.Tx) error {
+		res := tx.Bucket(rollupsBucket).Bucket([]byte(resolution.String()))
+		if res == nil {
+			return nil
+		}
+		b := res.Bucket([]byte(name))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		k, v := c.First()
+		if !from.IsZero() {
+			k, v = c.Seek(rollupKey(from))
+		}
+		for ; k != nil; k, v = c.Next() {
+			if !to.IsZero() && keyTime(k).After(to) {
+				break
+			}
+			var r kenko.Rollup
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			out = append(out, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: rollups %q: %w", name, err)
+	}
+	return out, nil
+}
+
+// PruneRollups drops the rollups of the resolution of every target starting
+// before before.
+func (s *Store) PruneRollups(_ context.Context, resolution time.Duration, before time.Time) (int, error) {
+	db, err := s.open()
+	if err != nil {
+		return 0, err
+	}
+
+	var pruned int
+	err = db.Update(func(tx *bolt.Tx) error {
+		res := tx.Bucket(rollupsBucket).Bucket([]byte(resolution.String()))
+		if res == nil {
+			return nil
+		}
+		return res.ForEachBucket(func(name []byte) error {
+			b := res.Bucket(name)
+			var old [][]byte
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil && keyTime(k).Before(before); k, _ = c.Next() {
+				old = append(old, append([]byte(nil), k...))
+			}
+			pruned += len(old)
+			return deleteKeys(b, old)
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("boltstore: prune rollups: %w", err)
+	}
+	return pruned, nil
+}
+
+func rollupKey(start time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(start.UnixNano()))
+	return k
+}
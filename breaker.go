@@ -0,0 +1,321 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailures = 3
+	defaultBreakerCooldown = 10 * time.Second
+	defaultBreakerTimeout  = 2 * time.Second
+	defaultBreakerBuffer   = 10000
+)
+
+// StoreBreaker configures the circuit breaker of WithStoreBreaker.
+type StoreBreaker struct {
+	// Failures is how many result reads or writes in a row must fail to
+	// open the breaker (default 3).
+	Failures int
+	// Cooldown is how long the breaker stays open before the store is
+	// probed again (default 10s).
+	Cooldown time.Duration
+	// Timeout bounds each result read or write while the breaker is closed
+	// (default 2s).
+	Timeout time.Duration
+	// Buffer caps how many results are kept for replay while the breaker is
+	// open. the oldest are dropped beyond it (default 10000).
+	Buffer int
+}
+
+// WithStoreBreaker puts a circuit breaker in front of the store's result
+// reads and writes, so an outage of a remote store such as Redis does not
+// stall every check and /status call on its timeouts. once Failures calls
+// in a row fail, results are buffered in memory and Results serves the last
+// results read, updated with those since, without calling the store. every
+// Cooldown while Run is running the store is probed, with Ping when it
+// implements HealthChecker, and once it answers the buffered results are
+// replayed in order and the breaker closes.
+func WithStoreBreaker(b StoreBreaker) Option {
+	return func(o *options) { o.breaker = &b }
+}
+
+// errBreakerOpen is returned for writes skipped while the breaker is open.
+var errBreakerOpen = errors.New("kenko: store unavailable, breaker open")
+
+// breakerStore wraps the result reads and writes of a store. the checker
+// keeps the bare store for its optional interfaces.
+type breakerStore struct {
+	store  Store
+	cfg    StoreBreaker
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	open     bool
+	failures int
+	openedAt time.Time
+	// latest mirrors the newest result of each target, served while open.
+	latest  map[string]Result
+	pending []Result
+	dropped int
+}
+
+func newBreakerStore(store Store, cfg StoreBreaker, logger *slog.Logger) *breakerStore {
+	if cfg.Failures <= 0 {
+		cfg.Failures = defaultBreakerFailures
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultBreakerCooldown
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultBreakerTimeout
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = defaultBreakerBuffer
+	}
+	return &breakerStore{store: store, cfg: cfg, logger: logger, latest: make(map[string]Result)}
+}
+
+func (b *breakerStore) Set(ctx context.Context, name string, result Result) error {
+	return b.SetBatch(ctx, []Result{result})
+}
+
+// SetBatch writes the results, or buffers them while the breaker is open.
+// the call that opens the breaker buffers its results too, so only errors of
+// a still closed breaker are returned.
+func (b *breakerStore) SetBatch(ctx context.Context, results []Result) error {
+	if b.isOpen() {
+		b.buffer(results)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+	if err := b.write(ctx, results); err != nil {
+		if b.failed(err) {
+			b.buffer(results)
+			return nil
+		}
+		return err
+	}
+
+	b.mu.Lock()
+	b.failures = 0
+	for _, r := range results {
+		b.latest[r.Target] = r
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+// GetAll reads the store's results, or serves the mirrored ones while the
+// breaker is open or the read fails after a successful one.
+func (b *breakerStore) GetAll(ctx context.Context) (map[string]Result, error) {
+	if b.isOpen() {
+		return b.mirrored(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+	results, err := b.store.GetAll(ctx)
+	if err != nil {
+		b.failed(err)
+		b.mu.Lock()
+		empty := len(b.latest) == 0
+		b.mu.Unlock()
+		if empty {
+			return nil, err
+		}
+		return b.mirrored(), nil
+	}
+
+	b.mu.Lock()
+	b.failures = 0
+	b.latest = copyResults(results)
+	b.mu.Unlock()
+	return results, nil
+}
+
+// AddDelivery writes a delivery record unless the breaker is open, as the
+// writes of results would time out on the same store. records are not
+// buffered for replay.
+func (b *breakerStore) AddDelivery(ctx context.Context, d Delivery) error {
+	ds, ok := b.store.(DeliveryStore)
+	if !ok {
+		return ErrNoDeliveries
+	}
+	if b.isOpen() {
+		return errBreakerOpen
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+	if err := ds.AddDelivery(ctx, d); err != nil {
+		b.failed(err)
+		return err
+	}
+	b.mu.Lock()
+	b.failures = 0
+	b.mu.Unlock()
+	return nil
+}
+
+// Deliveries reads the store's delivery records.
+func (b *breakerStore) Deliveries(ctx context.Context, q DeliveryQuery) ([]Delivery, error) {
+	ds, ok := b.store.(DeliveryStore)
+	if !ok {
+		return nil, ErrNoDeliveries
+	}
+	return ds.Deliveries(ctx, q)
+}
+
+func (b *breakerStore) write(ctx context.Context, results []Result) error {
+	if bs, ok := b.store.(BatchStore); ok && len(results) > 1 {
+		return bs.SetBatch(ctx, results)
+	}
+	for _, r := range results {
+		if err := b.store.Set(ctx, r.Target, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *breakerStore) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// failed counts a failed call, opening the breaker at the threshold, and
+// reports whether it is open.
+func (b *breakerStore) failed(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return true
+	}
+	b.failures++
+	if b.failures < b.cfg.Failures {
+		return false
+	}
+	b.open = true
+	b.openedAt = time.Now()
+	b.logger.Warn("store unavailable, buffering results", "failures", b.failures, "error", err)
+	return true
+}
+
+func (b *breakerStore) buffer(results []Result) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, results...)
+	for _, r := range results {
+		b.latest[r.Target] = r
+	}
+	b.trim()
+}
+
+// trim drops the oldest pending results beyond the buffer size. the caller
+// holds b.mu.
+func (b *breakerStore) trim() {
+	if over := len(b.pending) - b.cfg.Buffer; over > 0 {
+		b.pending = slices.Delete(b.pending, 0, over)
+		b.dropped += over
+	}
+}
+
+func (b *breakerStore) mirrored() map[string]Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return copyResults(b.latest)
+}
+
+// recover probes the open breaker's store and replays the buffered results,
+// closing the breaker once all are written.
+func (b *breakerStore) recover(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+
+	var err error
+	if hc, ok := b.store.(HealthChecker); ok {
+		err = hc.Ping(ctx)
+	} else {
+		_, err = b.store.GetAll(ctx)
+	}
+	if err != nil {
+		b.mu.Lock()
+		b.openedAt = time.Now()
+		b.mu.Unlock()
+		return
+	}
+
+	replayed := 0
+	for {
+		b.mu.Lock()
+		if len(b.pending) == 0 {
+			b.open = false
+			b.failures = 0
+			dropped := b.dropped
+			b.dropped = 0
+			b.mu.Unlock()
+			b.logger.Info("store recovered", "replayed", replayed, "dropped", dropped)
+			return
+		}
+		n := min(len(b.pending), defaultWriteBatch)
+		batch := slices.Clone(b.pending[:n])
+		b.pending = b.pending[n:]
+		b.mu.Unlock()
+
+		if err := b.write(ctx, batch); err != nil {
+			// put the batch back in front of what was buffered meanwhile
+			b.mu.Lock()
+			b.pending = append(batch, b.pending...)
+			b.trim()
+			b.openedAt = time.Now()
+			b.mu.Unlock()
+			b.logger.Warn("failed to replay buffered results", "error", err)
+			return
+		}
+		replayed += n
+	}
+}
+
+// due reports whether the breaker is open and its cooldown has passed.
+func (b *breakerStore) due(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open && now.Sub(b.openedAt) >= b.cfg.Cooldown
+}
+
+// resultStore returns the store results are read from and written to.
+func (c *Checker) resultStore() Store {
+	if c.breaker != nil {
+		return c.breaker
+	}
+	return c.store
+}
+
+func (c *Checker) runBreaker(ctx context.Context) {
+	ticker := time.NewTicker(c.breaker.cfg.Cooldown / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.life.stop:
+			return
+		case now := <-ticker.C:
+			if c.breaker.due(now) {
+				c.breaker.recover(ctx)
+				if c.cache != nil {
+					c.cache.invalidate()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyStore is a MemoryStore whose results can be made to fail.
+type flakyStore struct {
+	*MemoryStore
+
+	mu    sync.Mutex
+	down  bool
+	calls int
+}
+
+var errStoreDown = errors.New("connection refused")
+
+func (f *flakyStore) fail() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.down {
+		return errStoreDown
+	}
+	return nil
+}
+
+func (f *flakyStore) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *flakyStore) Set(ctx context.Context, name string, r Result) error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.MemoryStore.Set(ctx, name, r)
+}
+
+func (f *flakyStore) GetAll(ctx context.Context) (map[string]Result, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return f.MemoryStore.GetAll(ctx)
+}
+
+func (f *flakyStore) Ping(context.Context) error { return f.fail() }
+
+func TestBreakerStore_OpensAndReplays(t *testing.T) {
+	ctx := context.Background()
+	store := &flakyStore{MemoryStore: NewMemoryStore()}
+	b := newBreakerStore(store, StoreBreaker{Failures: 2}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := b.Set(ctx, "api", Result{Target: "api", Status: StatusHealthy}); err != nil {
+		t.Fatal(err)
+	}
+
+	store.setDown(true)
+	if err := b.Set(ctx, "api", Result{Target: "api", Status: StatusUnhealthy}); !errors.Is(err, errStoreDown) {
+		t.Errorf("first failure = %v, want it returned", err)
+	}
+	if err := b.Set(ctx, "api", Result{Target: "api", Status: StatusDegraded}); err != nil {
+		t.Errorf("failure opening the breaker = %v, want the result buffered", err)
+	}
+	if !b.isOpen() {
+		t.Fatal("breaker not open after 2 failures")
+	}
+
+	// while open the store is not called at all
+	calls := store.calls
+	b.Set(ctx, "web", Result{Target: "web", Status: StatusHealthy})
+	all, err := b.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all["api"].Status != StatusDegraded || all["web"].Status != StatusHealthy {
+		t.Errorf("results while open = %+v, want the buffered ones", all)
+	}
+	if store.calls != calls {
+		t.Errorf("store called %d times while open", store.calls-calls)
+	}
+
+	// a failed probe keeps it open
+	b.recover(ctx)
+	if !b.isOpen() {
+		t.Fatal("breaker closed while the store is down")
+	}
+
+	store.setDown(false)
+	b.recover(ctx)
+	if b.isOpen() {
+		t.Fatal("breaker still open after the store recovered")
+	}
+	stored, _ := store.MemoryStore.GetAll(ctx)
+	if stored["api"].Status != StatusDegraded || stored["web"].Status != StatusHealthy {
+		t.Errorf("stored = %+v, want the buffered results replayed", stored)
+	}
+	if history, _ := store.History(ctx, "api", HistoryQuery{}); len(history) != 2 || history[0].Status != StatusDegraded {
+		t.Errorf("api history = %+v, want the replayed result last", history)
+	}
+}
+
+func TestBreakerStore_Deliveries(t *testing.T) {
+	ctx := context.Background()
+	store := &flakyStore{MemoryStore: NewMemoryStore(), down: true}
+	b := newBreakerStore(store, StoreBreaker{Failures: 1}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Set(ctx, "api", Result{Target: "api"})
+	if !b.isOpen() {
+		t.Fatal("breaker not open")
+	}
+
+	if err := b.AddDelivery(ctx, Delivery{Notifier: "ops", Target: "api"}); !errors.Is(err, errBreakerOpen) {
+		t.Errorf("delivery while open: err = %v, want errBreakerOpen", err)
+	}
+	store.setDown(false)
+	b.recover(ctx)
+	if err := b.AddDelivery(ctx, Delivery{Notifier: "ops", Target: "api"}); err != nil {
+		t.Fatal(err)
+	}
+	if deliveries, _ := b.Deliveries(ctx, DeliveryQuery{}); len(deliveries) != 1 {
+		t.Errorf("deliveries = %+v, want only the one written after recovery", deliveries)
+	}
+}
+
+func TestBreakerStore_Buffer(t *testing.T) {
+	b := newBreakerStore(&flakyStore{MemoryStore: NewMemoryStore(), down: true}, StoreBreaker{Failures: 1, Buffer: 2}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, name := range []string{"a", "b", "c"} {
+		b.Set(context.Background(), name, Result{Target: name})
+	}
+	if len(b.pending) != 2 || b.pending[0].Target != "b" || b.dropped != 1 {
+		t.Errorf("pending = %+v, %d dropped, want the oldest dropped", b.pending, b.dropped)
+	}
+}
+
+func TestWithStoreBreaker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	store := &flakyStore{MemoryStore: NewMemoryStore(), down: true}
+	c, err := NewChecker(
+		WithTarget("api", srv.URL),
+		WithStore(store),
+		WithStoreBreaker(StoreBreaker{Failures: 1}),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.checkTarget(context.Background(), c.targetList()[0])
+	results, err := c.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["api"].Status != StatusHealthy {
+		t.Errorf("results = %+v, want the buffered check", results)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleHealth(c)(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if !strings.Contains(rec.Body.String(), `"redis":"down"`) {
+		t.Errorf("health = %s, want redis down", rec.Body)
+	}
+
+	store.setDown(false)
+	c.breaker.openedAt = time.Time{}
+	if !c.breaker.due(time.Now()) {
+		t.Fatal("breaker not due for a probe")
+	}
+	c.breaker.recover(context.Background())
+	if stored, _ := store.MemoryStore.GetAll(context.Background()); stored["api"].Status != StatusHealthy {
+		t.Errorf("stored = %+v, want the check replayed", stored)
+	}
+}
@@ -0,0 +1,63 @@
+package kenko
+
+import (
+	"sync"
+	"time"
+)
+
+// resultCache holds the most recent GetAll snapshot for a short TTL so that
+// frequent readers (e.g. status page polling) don't hit the store every time.
+// the checker invalidates it whenever it writes new results.
+type resultCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	results map[string]Result
+	expires time.Time
+	// gen counts invalidations, so a snapshot read from the store before
+	// one is not cached after it.
+	gen uint64
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{ttl: ttl}
+}
+
+// get returns a copy of the cached results, or false on a miss along with
+// the generation to put the store's results under.
+func (c *resultCache) get() (map[string]Result, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.results == nil || time.Now().After(c.expires) {
+		return nil, c.gen, false
+	}
+	return copyResults(c.results), c.gen, true
+}
+
+// put caches results read at generation gen, unless the cache was
+// invalidated since.
+func (c *resultCache) put(gen uint64, results map[string]Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	c.results = copyResults(results)
+	c.expires = time.Now().Add(c.ttl)
+}
+
+func (c *resultCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = nil
+	c.gen++
+}
+
+func copyResults(in map[string]Result) map[string]Result {
+	out := make(map[string]Result, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package kenko
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingStore struct {
+	*MemoryStore
+	gets atomic.Int32
+}
+
+func (s *countingStore) GetAll(ctx context.Context) (map[string]Result, error) {
+	s.gets.Add(1)
+	return s.MemoryStore.GetAll(ctx)
+}
+
+func TestResults_CacheHit(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore()}
+	c, err := NewChecker(
+		WithTarget("api", "http://example.com"),
+		WithStore(store),
+		WithResultCache(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = store.Set(context.Background(), "api", Result{Target: "api", Status: StatusHealthy})
+
+	for range 3 {
+		if _, err := c.Results(); err != nil {
+			t.Fatalf("Results: %v", err)
+		}
+	}
+
+	if n := store.gets.Load(); n != 1 {
+		t.Errorf("store GetAll calls = %d, want 1", n)
+	}
+}
+
+func TestResults_CacheInvalidatedOnRecord(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore()}
+	c, err := NewChecker(
+		WithTarget("api", "http://example.com"),
+		WithStore(store),
+		WithResultCache(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.record(context.Background(), Result{Target: "api", Status: StatusHealthy})
+	_, _ = c.Results()
+
+	c.record(context.Background(), Result{Target: "api", Status: StatusUnhealthy})
+	results, _ := c.Results()
+
+	if results["api"].Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q", results["api"].Status, StatusUnhealthy)
+	}
+	if n := store.gets.Load(); n != 2 {
+		t.Errorf("store GetAll calls = %d, want 2", n)
+	}
+}
+
+func TestResultCache_Expires(t *testing.T) {
+	c := newResultCache(time.Millisecond)
+	c.put(0, map[string]Result{"api": {Status: StatusHealthy}})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, _, ok := c.get(); ok {
+		t.Error("expected cache miss after ttl")
+	}
+}
+
+func TestResultCache_StalePut(t *testing.T) {
+	c := newResultCache(time.Minute)
+	_, gen, _ := c.get()
+	// a write lands while the reader is still at the store
+	c.invalidate()
+	c.put(gen, map[string]Result{"api": {Status: StatusHealthy}})
+
+	if _, _, ok := c.get(); ok {
+		t.Error("expected the snapshot read before invalidate not to be cached")
+	}
+
+	_, gen, _ = c.get()
+	c.put(gen, map[string]Result{"api": {Status: StatusUnhealthy}})
+	if results, _, ok := c.get(); !ok || results["api"].Status != StatusUnhealthy {
+		t.Errorf("results = %v, %v, want the fresh snapshot cached", results, ok)
+	}
+}
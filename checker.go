@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/http/httptrace"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,16 +17,79 @@ type MetricsReporter interface {
 	ReportCheck(target string, status Status, latencySeconds float64)
 }
 
+// ResultReporter is implemented by MetricsReporters that want the full Result
+// rather than just status and latency. when present it is used instead of ReportCheck.
+type ResultReporter interface {
+	ReportResult(result Result)
+}
+
+// TargetForgetter is an optional MetricsReporter extension told when a
+// target stops being checked, because it was paused or removed, so it can
+// drop the target's series rather than keep reporting its last status.
+type TargetForgetter interface {
+	ForgetTarget(target string)
+}
+
 // Checker performs periodic HTTP health checks against configured targets.
 type Checker struct {
-	client   *http.Client
-	store    Store
-	targets  []Target
-	interval time.Duration
-	logger   *slog.Logger
-	metrics  MetricsReporter
+	client        *http.Client
+	store         Store
+	targetsMu     sync.RWMutex
+	targets       []Target
+	interval      time.Duration
+	warmup        time.Duration
+	logger        *slog.Logger
+	metrics       MetricsReporter
+	header        http.Header
+	vars          map[string]string
+	region        string
+	writer        *resultWriter
+	breaker       *breakerStore
+	cache         *resultCache
+	content       contentTracker
+	cookies       cookieJars
+	transports    transports
+	sampler       sampler
+	thresholds    thresholds
+	slowness      slowness
+	timeline      timeline
+	outages       outages
+	aggregates    aggregates
+	recent        recentSamples
+	deploys       deploys
+	exclusions    exclusions
+	announcements announcements
+	silences      silences
+	pauses        pauses
+	managed       managedTargets
+	targetDecoder TargetDecoder
+	targetSaver   TargetSaver
+	apiToken      string
+	credentials   credentials
+	sourceAddr    string
+	sourceIf      string
+	revocation    RevocationChecker
+	names         NameNormalizer
+	ipEnricher    IPEnricher
+	decoders      map[string]ContentDecoder
+	statusPage    StatusPage
+	notifiers     map[string]Notifier
+	dispatcher    *dispatcher
+	quorums       map[string]int
+	maintenance   map[string][]MaintenanceWindow
+	heartbeat     *HeartbeatSender
+	heartbeats    heartbeats
+	archive       *Archive
+	retention     *Retention
+	rollups       *Rollups
+	snapshot      string
+	started       time.Time
+	life          *lifecycle
+	schedule      *schedule
 
 	ready atomic.Bool
+	// lastCheck is the unix nano time the latest check completed.
+	lastCheck atomic.Int64
 }
 
 // NewChecker creates a Checker configured with the given options.
@@ -34,28 +99,155 @@ func NewChecker(opts ...Option) (*Checker, error) {
 		opt(o)
 	}
 
-	if len(o.targets) == 0 {
-		return nil, fmt.Errorf("kenko: at least one target is required")
+	if err := validateTargets(o.targets, o.names, o.revocation); err != nil {
+		return nil, err
+	}
+
+	if err := validateQuorums(o.quorums); err != nil {
+		return nil, err
+	}
+
+	if err := validateGroupMaintenance(o.maintenance); err != nil {
+		return nil, err
+	}
+
+	if err := validateHeartbeat(o.heartbeat); err != nil {
+		return nil, err
+	}
+
+	if err := validateTransport(o.transport); err != nil {
+		return nil, err
+	}
+
+	if err := normalizeStatusPage(&o.statusPage, o.targets, o.names); err != nil {
+		return nil, err
 	}
 
 	if o.store == nil {
 		o.store = NewMemoryStore()
 	}
 
+	if err := validateArchive(o.archive, o.store); err != nil {
+		return nil, err
+	}
+	if err := validateRetention(o.retention, o.archive, o.store); err != nil {
+		return nil, err
+	}
+	if err := validateRollups(o.rollups, o.store); err != nil {
+		return nil, err
+	}
+	if err := validateRoutes(o.routes, o.notifiers); err != nil {
+		return nil, err
+	}
+	if err := validateThrottle(o.throttle, o.repeat); err != nil {
+		return nil, err
+	}
+	if err := validateGroupWindow(o.grouping); err != nil {
+		return nil, err
+	}
+
 	client := o.client
 	if client == nil {
 		client = &http.Client{}
 	}
 	client.Timeout = o.timeout
+	if o.transport != nil {
+		rt, err := o.transport.tuned(client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = rt
+	}
 
-	return &Checker{
+	var breaker *breakerStore
+	results := o.store
+	if o.breaker != nil {
+		breaker = newBreakerStore(o.store, *o.breaker, o.logger)
+		results = breaker
+	}
+
+	var cache *resultCache
+	if o.cacheTTL > 0 {
+		cache = newResultCache(o.cacheTTL)
+	}
+
+	decoders := builtinDecoders()
+	for enc, dec := range o.decoders {
+		decoders[enc] = dec
+	}
+
+	var writer *resultWriter
+	if o.writeQueue > 0 {
+		writer = newResultWriter(results, o.logger, o.writeQueue)
+		if o.writeBatch > 0 {
+			writer.batchSize = o.writeBatch
+		}
+		writer.delay = o.writeDelay
+		if cache != nil {
+			writer.afterWrite = cache.invalidate
+		}
+	}
+
+	c := &Checker{
 		client:   client,
 		store:    o.store,
 		targets:  o.targets,
 		interval: o.interval,
+		warmup:   o.warmup,
 		logger:   o.logger,
 		metrics:  o.metrics,
-	}, nil
+		header:   o.header,
+		vars:     o.vars,
+		region:   o.region,
+		writer:   writer,
+		breaker:  breaker,
+		cache:    cache,
+
+		sourceAddr:  o.sourceAddr,
+		sourceIf:    o.sourceIf,
+		revocation:  o.revocation,
+		names:       o.names,
+		ipEnricher:  o.ipEnricher,
+		decoders:    decoders,
+		statusPage:  o.statusPage,
+		notifiers:   o.notifiers,
+		quorums:     o.quorums,
+		maintenance: o.maintenance,
+		heartbeat:   o.heartbeat,
+		archive:     o.archive,
+		retention:   o.retention,
+		rollups:     o.rollups,
+		snapshot:    o.snapshotPath,
+		started:     time.Now(),
+		life:        newLifecycle(),
+		schedule:    newSchedule(),
+
+		managed:       managedTargets{base: o.targets},
+		targetDecoder: o.targetDecoder,
+		targetSaver:   o.targetSaver,
+		apiToken:      o.apiToken,
+	}
+	if len(o.notifiers) > 0 {
+		var retry NotifyRetry
+		if o.notifyRetry != nil {
+			retry = *o.notifyRetry
+		}
+		c.dispatcher = newDispatcher(o.notifiers, retry, o.logger, c.recordDelivery)
+		c.dispatcher.routes = o.routes
+		c.dispatcher.window = o.grouping
+		if t := o.throttle; t != nil && t.Limit > 0 && t.Per > 0 {
+			for _, q := range c.dispatcher.queues {
+				q.limiter = &rateLimiter{limit: t.Limit, per: t.Per}
+			}
+		}
+		if o.repeat > 0 {
+			c.dispatcher.remind = &reminders{interval: o.repeat}
+		}
+		if slices.ContainsFunc(o.routes, func(r Route) bool { return len(r.Escalation) > 0 }) {
+			c.dispatcher.escalate = &escalations{}
+		}
+	}
+	return c, nil
 }
 
 // Ready reports whether the checker has completed at least one check cycle.
@@ -64,87 +256,385 @@ func (c *Checker) Ready() bool { return c.ready.Load() }
 // Store returns the result store used by the checker.
 func (c *Checker) Store() Store { return c.store }
 
-// Results returns the latest check results for all targets.
+// hasTarget reports whether a target with the given name is configured.
+func (c *Checker) hasTarget(name string) bool {
+	for _, t := range c.targetList() {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Results returns the latest check results for all targets, served from the
+// in-process cache when one is configured.
 func (c *Checker) Results() (map[string]Result, error) {
-	return c.store.GetAll(context.Background())
+	var gen uint64
+	if c.cache != nil {
+		results, g, ok := c.cache.get()
+		if ok {
+			return c.withPaused(results), nil
+		}
+		gen = g
+	}
+
+	results, err := c.resultStore().GetAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.put(gen, results)
+	}
+	return c.withPaused(results), nil
 }
 
-// Run starts the check loop, blocking until ctx is cancelled.
+// Run starts the check loop, blocking until ctx is cancelled or Shutdown
+// completes. cancelling ctx aborts checks in flight; use Shutdown to let them
+// finish first.
 func (c *Checker) Run(ctx context.Context) {
-	c.logger.Info("checker starting", "targets", len(c.targets), "interval", c.interval)
+	defer c.life.finish()
+	select {
+	case <-c.life.stop:
+		return
+	default:
+	}
+
+	// checks get their own context so Shutdown can wait for them, or abort
+	// them once its deadline passes.
+	ctx, abort := context.WithCancel(ctx)
+	defer abort()
+	c.life.setAbort(abort)
+
+	c.logger.Info("checker starting", "targets", len(c.targetList()), "interval", c.interval, "warmup", c.warmup)
+
+	if c.snapshot != "" {
+		if err := c.restoreSnapshot(ctx); err != nil {
+			c.logger.Warn("failed to restore snapshot", "error", err)
+		}
+	}
+	if err := c.loadExclusions(ctx); err != nil {
+		c.logger.Warn("failed to load incident exclusions", "error", err)
+	}
+	if err := c.loadAnnouncements(ctx); err != nil {
+		c.logger.Warn("failed to load announcements", "error", err)
+	}
+	if err := c.loadSilences(ctx); err != nil {
+		c.logger.Warn("failed to load silences", "error", err)
+	}
+	if err := c.loadPauses(ctx); err != nil {
+		c.logger.Warn("failed to load pauses", "error", err)
+	}
+	if err := c.loadManagedTargets(ctx); err != nil {
+		c.logger.Warn("failed to load targets changed through the API", "error", err)
+	}
+	if err := c.loadAggregates(ctx); err != nil {
+		c.logger.Warn("failed to load uptime aggregates", "error", err)
+	}
+
+	// save what the last checks counted and stored, once they have finished
+	// and, deferred before the writer is stopped, once it has drained
+	defer func() {
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		c.saveAggregates(saveCtx)
+		if c.snapshot != "" {
+			if err := c.takeSnapshot(saveCtx); err != nil {
+				c.logger.Warn("failed to write snapshot", "error", err)
+			}
+		}
+	}()
+
+	if c.writer != nil {
+		writerDone := make(chan struct{})
+		go func() {
+			c.writer.run(ctx)
+			close(writerDone)
+		}()
+		defer func() {
+			abort()
+			<-writerDone
+		}()
+	}
+
+	if c.dispatcher != nil {
+		dispatcherDone := make(chan struct{})
+		go func() {
+			c.dispatcher.run(ctx)
+			close(dispatcherDone)
+		}()
+		defer func() {
+			abort()
+			<-dispatcherDone
+		}()
+	}
+
+	// checks started by the loop finish before the writer is stopped
+	defer c.schedule.wg.Wait()
+
+	if c.heartbeat != nil {
+		go c.sendHeartbeats(ctx)
+	}
+	if c.archive != nil {
+		go c.runArchive(ctx)
+	}
+	if c.retention != nil {
+		go c.runRetention(ctx)
+	}
+	if c.rollups != nil {
+		go c.runRollups(ctx)
+	}
+	go c.runAggregates(ctx)
+	if c.breaker != nil {
+		go c.runBreaker(ctx)
+	}
 
-	c.checkAll(ctx)
-	c.ready.Store(true)
+	if c.warmup > 0 {
+		c.stagger(c.activeTargets(), time.Now())
+	} else {
+		c.checkAll(ctx)
+		if c.writer != nil {
+			c.writer.wait(ctx)
+		}
+		c.ready.Store(true)
+	}
 
-	ticker := time.NewTicker(c.interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(c.untilNext(c.activeTargets(), time.Now()))
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			c.logger.Info("checker stopping")
 			return
-		case <-ticker.C:
-			c.checkAll(ctx)
+		case <-c.life.stop:
+			c.logger.Info("checker draining")
+			c.schedule.wg.Wait()
+			if c.writer != nil {
+				c.writer.wait(ctx)
+			}
+			c.logger.Info("checker stopped")
+			return
+		case <-c.schedule.wake:
+			timer.Stop()
+		case <-timer.C:
+			select {
+			case <-c.life.stop:
+				// a tick raced with Shutdown; drain on the next pass instead
+				continue
+			default:
+			}
 		}
+
+		targets := c.activeTargets()
+		c.start(ctx, c.due(targets, time.Now()))
+		timer.Reset(c.untilNext(targets, time.Now()))
 	}
 }
 
+// checkAll checks every target but the scheduled ones, marking them all as
+// just checked, and waits for the checks to complete.
 func (c *Checker) checkAll(ctx context.Context) {
-	var wg sync.WaitGroup
+	var targets []Target
+	now := time.Now()
+	c.schedule.mu.Lock()
+	for _, t := range c.activeTargets() {
+		c.schedule.next[t.Name] = c.nextCheck(t, now)
+		if t.cron == nil {
+			targets = append(targets, t)
+		}
+	}
+	c.schedule.mu.Unlock()
 
-	for _, target := range c.targets {
+	var wg sync.WaitGroup
+	for _, target := range targets {
 		wg.Add(1)
 		go func(t Target) {
 			defer wg.Done()
-			result := c.check(ctx, t)
+			c.checkTarget(ctx, t)
+		}(target)
+	}
+	wg.Wait()
+}
 
-			if err := c.store.Set(ctx, t.Name, result); err != nil {
-				c.logger.Warn("failed to store result", "target", t.Name, "error", err)
-			}
+// checkTarget checks one target and records and reports its result.
+func (c *Checker) checkTarget(ctx context.Context, t Target) {
+	result := c.checkWithRefresh(ctx, t)
+	result.Region = c.region
+	result.Group = t.Group
+	result.Severity = t.Severity
+	result.Labels = t.Labels
+	if c.inMaintenance(t, time.Now()) {
+		result.Status = StatusMaintenance
+	}
+	c.thresholds.apply(t, &result)
+	outage := c.outages.observe(result)
+	if alert := c.slowness.observe(t, result); alert != nil {
+		c.notifySlow(result, alert)
+	}
+	if prev, changed := c.timeline.observe(result); changed {
+		c.recordEvent(ctx, prev, result)
+		c.notify(prev, result, outage)
+	} else {
+		c.remind(result)
+	}
+	c.escalate(result)
+	c.aggregates.observe(result)
+	c.recent.observe(result)
+	if c.sampler.keep(t, result) {
+		c.record(ctx, result)
+	}
 
-			if c.metrics != nil {
-				c.metrics.ReportCheck(t.Name, result.Status, result.Latency.Seconds())
-			}
+	c.report(result)
+	c.reportGroup(t)
+	c.lastCheck.Store(time.Now().UnixNano())
 
-			c.logger.Info("check complete",
-				"target", t.Name,
-				"status", result.Status,
-				"latency", result.Latency,
-			)
-		}(target)
+	c.logger.Info("check complete",
+		"target", t.Name,
+		"status", result.Status,
+		"latency", result.Latency,
+	)
+}
+
+// report passes the result to the metrics reporter, preferring the richer
+// ResultReporter interface when it is implemented.
+func (c *Checker) report(result Result) {
+	if c.metrics == nil {
+		return
+	}
+	if rr, ok := c.metrics.(ResultReporter); ok {
+		rr.ReportResult(result)
+		return
+	}
+	c.metrics.ReportCheck(result.Target, result.Status, result.Latency.Seconds())
+}
+
+// forgetTarget tells the metrics reporter a target is no longer checked.
+func (c *Checker) forgetTarget(name string) {
+	if tf, ok := c.metrics.(TargetForgetter); ok {
+		tf.ForgetTarget(name)
 	}
+}
 
-	wg.Wait()
+// record persists a result, either directly or via the write-behind queue.
+func (c *Checker) record(ctx context.Context, result Result) {
+	if c.writer != nil {
+		c.writer.enqueue(result)
+		return
+	}
+
+	if err := c.resultStore().Set(ctx, result.Target, result); err != nil {
+		c.logger.Warn("failed to store result", "target", result.Target, "error", err)
+	}
+
+	if c.cache != nil {
+		c.cache.invalidate()
+	}
 }
 
-func (c *Checker) check(ctx context.Context, target Target) Result {
+// attempt checks the target once. the error is the transport error of a
+// request that failed outright, used to decide whether to retry it.
+func (c *Checker) attempt(ctx context.Context, target Target) (Result, error) {
+	if len(target.Steps) > 0 {
+		return c.checkSteps(ctx, target), nil
+	}
+
 	start := time.Now()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.URL, nil)
+	url, err := render(target.URL, c.templateVars(target))
 	if err != nil {
-		return errResult(target, start, fmt.Sprintf("bad request: %v", err))
+		return errResult(target, start, fmt.Sprintf("bad url template: %v", err)), nil
 	}
 
-	resp, err := c.client.Do(req)
+	method := target.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var trace phaseTrace
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()), method, url, nil)
 	if err != nil {
-		return errResult(target, start, fmt.Sprintf("request failed: %v", err))
+		return errResult(target, start, fmt.Sprintf("bad request: %v", err)), nil
 	}
-	defer resp.Body.Close()
+	c.applyHeaders(req, target)
+	requestEncoding(req, target)
 
-	status := StatusHealthy
-	if resp.StatusCode >= 400 {
-		status = StatusUnhealthy
+	resp, err := c.clientFor(target).Do(req)
+	if err != nil {
+		result := errResult(target, start, fmt.Sprintf("request failed: %v", err))
+		c.enrich(&result, trace.remoteIP())
+		return result, err
 	}
+	defer resp.Body.Close()
 
-	return Result{
+	result := Result{
 		Target:     target.Name,
 		URL:        target.URL,
-		Status:     status,
+		Status:     StatusHealthy,
 		StatusCode: resp.StatusCode,
 		Latency:    time.Since(start),
-		CheckedAt:  time.Now(),
+		TLS:        tlsInfo(resp.TLS),
+	}
+	if !statusOK(target, resp.StatusCode) {
+		result.Status = StatusUnhealthy
+		if len(target.ExpectStatus) > 0 {
+			result.Error = fmt.Sprintf("status %d, want one of %v", resp.StatusCode, target.ExpectStatus)
+		}
+	}
+
+	if err := checkHeaders(target, resp.Header); err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = fmt.Sprintf("assertion failed: %v", err)
+	}
+
+	if target.Revocation != RevocationOff && resp.TLS != nil {
+		c.checkRevocation(ctx, target, resp.TLS, &result)
+	}
+
+	body := newBodyReader(resp.Body, target.MaxBodyBytes)
+	content := body
+	if len(target.ExpectEncoding) > 0 {
+		checkEncoding(target, resp, &result)
+		dec, err := c.decodedBody(body, result.ContentEncoding)
+		switch {
+		case err != nil:
+			result.Status = StatusUnhealthy
+			result.Error = fmt.Sprintf("decoding %s body: %v", result.ContentEncoding, err)
+		case dec != nil:
+			content = dec
+		}
+	}
+
+	content.keep = failureBodyLimit(target)
+	if target.ContentHash != nil {
+		c.checkContent(target, content, &result)
+	}
+	content.drain()
+	if content != body {
+		body.drain()
+		result.DecodedBytes = content.n
+	}
+	result.BodyBytes = body.n
+	if result.Status == StatusUnhealthy {
+		result.Body = snippet(content.head, content.keep)
+	}
+
+	result.CheckedAt = time.Now()
+	result.Phases = trace.phases(result.CheckedAt)
+	c.enrich(&result, trace.remoteIP())
+	return result, nil
+}
+
+// applyHeaders sets the checker-wide default headers followed by the target's own,
+// so a target header always replaces a default of the same name.
+func (c *Checker) applyHeaders(req *http.Request, target Target) {
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	for k, v := range target.Header {
+		req.Header[k] = v
 	}
+	c.applyCredential(req, target)
 }
 
 func errResult(target Target, start time.Time, msg string) Result {
@@ -160,7 +650,9 @@ func errResult(target Target, start time.Time, msg string) Result {
 
 func newCheckerFromFields(store Store, logger *slog.Logger) *Checker {
 	return &Checker{
-		store:  store,
-		logger: logger,
+		store:    store,
+		logger:   logger,
+		life:     newLifecycle(),
+		schedule: newSchedule(),
 	}
 }
@@ -114,3 +114,52 @@ func TestNewChecker_DefaultStore(t *testing.T) {
 		t.Error("expected non-nil default store")
 	}
 }
+
+func TestCheck_DefaultUserAgent(t *testing.T) {
+	var gotUA string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("test", ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.check(context.Background(), c.targets[0])
+
+	if gotUA != DefaultUserAgent {
+		t.Errorf("user-agent = %q, want %q", gotUA, DefaultUserAgent)
+	}
+}
+
+func TestCheck_TargetHeaderOverridesDefault(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("test", ts.URL, WithHeader("Accept", "application/json")),
+		WithUserAgent("probe/1.0"),
+		WithDefaultHeader("Accept", "text/html"),
+		WithDefaultHeader("X-Env", "prod"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.check(context.Background(), c.targets[0])
+
+	if ua := got.Get("User-Agent"); ua != "probe/1.0" {
+		t.Errorf("user-agent = %q, want %q", ua, "probe/1.0")
+	}
+	if accept := got.Get("Accept"); accept != "application/json" {
+		t.Errorf("accept = %q, want %q", accept, "application/json")
+	}
+	if env := got.Get("X-Env"); env != "prod" {
+		t.Errorf("x-env = %q, want %q", env, "prod")
+	}
+}
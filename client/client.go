@@ -0,0 +1,530 @@
+// Package client is a typed Go client for the kenko HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests (default http.DefaultClient).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithHeader sets a header sent with every request, e.g. for an
+// authenticating proxy in front of kenko.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Set(key, value) }
+}
+
+// WithToken authenticates requests with the api token kenko was started
+// with, which the endpoints that change state require.
+func WithToken(token string) Option {
+	return func(c *Client) { c.header.Set("Authorization", "Bearer "+token) }
+}
+
+// Client calls a kenko instance over HTTP.
+type Client struct {
+	base   *url.URL
+	http   *http.Client
+	header http.Header
+}
+
+// New returns a Client for the kenko instance at baseURL, e.g.
+// "http://localhost:6969".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("client: base url scheme must be http or https, got %q", u.Scheme)
+	}
+
+	c := &Client{base: u, http: http.DefaultClient, header: make(http.Header)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// APIError is returned when kenko answers with an error status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("client: kenko returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("client: kenko returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Health is the response of /health.
+type Health struct {
+	Status string `json:"status"`
+	Redis  string `json:"redis,omitempty"`
+}
+
+// TargetStatus is the latest check result of one target, as reported by
+// /status and the search endpoint.
+type TargetStatus struct {
+	Name       string            `json:"name"`
+	URL        string            `json:"url"`
+	Status     kenko.Status      `json:"status"`
+	StatusCode int               `json:"status_code,omitempty"`
+	LatencyMS  int64             `json:"latency_ms"`
+	Error      string            `json:"error,omitempty"`
+	CheckedAt  time.Time         `json:"checked_at,omitempty"`
+	Region     string            `json:"region,omitempty"`
+	Group      string            `json:"group,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	TLS        *kenko.TLSInfo    `json:"tls,omitempty"`
+	RemoteIP   string            `json:"remote_ip,omitempty"`
+	IPInfo     *kenko.IPInfo     `json:"ip_info,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Endpoints  []Endpoint        `json:"endpoints,omitempty"`
+}
+
+// Endpoint is the status of one endpoint of a target checked per address
+// family, resolved address, or region.
+type Endpoint struct {
+	Name       string       `json:"name"`
+	Status     kenko.Status `json:"status"`
+	StatusCode int          `json:"status_code,omitempty"`
+	LatencyMS  int64        `json:"latency_ms"`
+	Error      string       `json:"error,omitempty"`
+	RemoteIP   string       `json:"remote_ip,omitempty"`
+	Region     string       `json:"region,omitempty"`
+}
+
+// Latency returns the check latency as a duration.
+func (ts TargetStatus) Latency() time.Duration {
+	return time.Duration(ts.LatencyMS) * time.Millisecond
+}
+
+// Segment is a period during which a target kept one status.
+type Segment struct {
+	Status          kenko.Status `json:"status"`
+	Start           time.Time    `json:"start"`
+	End             time.Time    `json:"end,omitempty"`
+	Duration        string       `json:"duration"`
+	DurationSeconds int64        `json:"duration_seconds"`
+}
+
+// Timeline is a target's sequence of status segments.
+type Timeline struct {
+	Target   string    `json:"target"`
+	Summary  string    `json:"summary"`
+	Segments []Segment `json:"segments"`
+}
+
+// StatusEvent is a recorded change of a target's status.
+type StatusEvent struct {
+	Target          string       `json:"target"`
+	From            kenko.Status `json:"from"`
+	To              kenko.Status `json:"to"`
+	At              time.Time    `json:"at"`
+	Duration        string       `json:"duration"`
+	DurationSeconds int64        `json:"duration_seconds"`
+	Error           string       `json:"error,omitempty"`
+	Region          string       `json:"region,omitempty"`
+}
+
+// NotificationDelivery is the recorded outcome of one notification sent to a
+// notifier.
+type NotificationDelivery struct {
+	Notifier  string       `json:"notifier"`
+	Target    string       `json:"target"`
+	Status    kenko.Status `json:"status"`
+	Repeat    int          `json:"repeat,omitempty"`
+	Outcome   string       `json:"outcome"`
+	At        time.Time    `json:"at"`
+	Attempts  int          `json:"attempts,omitempty"`
+	LatencyMS int64        `json:"latency_ms"`
+	Error     string       `json:"error,omitempty"`
+	Region    string       `json:"region,omitempty"`
+}
+
+// Incident is an unhealthy period of a target.
+type Incident struct {
+	ID        string           `json:"id"`
+	Start     time.Time        `json:"start"`
+	End       time.Time        `json:"end,omitempty"`
+	Duration  string           `json:"duration"`
+	Exclusion *kenko.Exclusion `json:"exclusion,omitempty"`
+}
+
+// Incidents lists a target's incidents and its uptime.
+type Incidents struct {
+	Target    string     `json:"target"`
+	Uptime    float64    `json:"uptime"`
+	Incidents []Incident `json:"incidents"`
+}
+
+// Uptime summarizes a target's availability and latency over a window.
+type Uptime struct {
+	Target          string    `json:"target"`
+	Window          string    `json:"window"`
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	Uptime          float64   `json:"uptime"`
+	UptimePercent   float64   `json:"uptime_percent"`
+	Checks          int       `json:"checks"`
+	Down            int       `json:"down"`
+	Downtime        string    `json:"downtime"`
+	DowntimeSeconds int64     `json:"downtime_seconds"`
+	Incidents       int       `json:"incidents"`
+	AvgLatencyMS    int64     `json:"avg_latency_ms"`
+	P50MS           int64     `json:"p50_ms"`
+	P95MS           int64     `json:"p95_ms"`
+	P99MS           int64     `json:"p99_ms"`
+}
+
+// Health returns the service health reported by /health.
+func (c *Client) Health(ctx context.Context) (Health, error) {
+	var h Health
+	err := c.do(ctx, http.MethodGet, "/health", nil, nil, &h)
+	return h, err
+}
+
+// Ready reports whether kenko has completed its first check cycle.
+func (c *Client) Ready(ctx context.Context) (bool, error) {
+	err := c.do(ctx, http.MethodGet, "/ready", nil, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Status returns the latest result of every target, or only of targets
+// matching every label selector, such as "team=payments" or "env".
+func (c *Client) Status(ctx context.Context, labels ...string) ([]TargetStatus, error) {
+	var resp struct {
+		Targets []TargetStatus `json:"targets"`
+	}
+	var q url.Values
+	if len(labels) > 0 {
+		q = url.Values{"label": labels}
+	}
+	err := c.do(ctx, http.MethodGet, "/status", q, nil, &resp)
+	return resp.Targets, err
+}
+
+// Group is the rolled-up status of the targets sharing a group.
+type Group struct {
+	Name      string       `json:"name"`
+	Status    kenko.Status `json:"status"`
+	Quorum    int          `json:"quorum"`
+	Members   int          `json:"members"`
+	Healthy   int          `json:"healthy"`
+	Unhealthy int          `json:"unhealthy"`
+	Degraded  int          `json:"degraded"`
+}
+
+// Groups returns the status of every target group.
+func (c *Client) Groups(ctx context.Context) ([]Group, error) {
+	var resp struct {
+		Groups []Group `json:"groups"`
+	}
+	err := c.do(ctx, http.MethodGet, "/status", nil, nil, &resp)
+	return resp.Groups, err
+}
+
+// Search returns the targets matching query and, when given, one of statuses.
+func (c *Client) Search(ctx context.Context, query string, statuses ...kenko.Status) ([]TargetStatus, error) {
+	q := url.Values{"q": {query}}
+	if len(statuses) > 0 {
+		s := make([]string, len(statuses))
+		for i, st := range statuses {
+			s[i] = string(st)
+		}
+		q.Set("status", strings.Join(s, ","))
+	}
+
+	var resp struct {
+		Targets []TargetStatus `json:"targets"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/v1/search", q, nil, &resp)
+	return resp.Targets, err
+}
+
+// Timeline returns the status segments of the named target.
+func (c *Client) Timeline(ctx context.Context, name string) (Timeline, error) {
+	var tl Timeline
+	err := c.do(ctx, http.MethodGet, targetPath(name, "timeline"), nil, nil, &tl)
+	return tl, err
+}
+
+// History returns up to limit of the named target's most recent results,
+// newest first. a limit of 0 uses the server's default.
+func (c *Client) History(ctx context.Context, name string, limit int) ([]TargetStatus, error) {
+	var q url.Values
+	if limit > 0 {
+		q = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+	var resp struct {
+		Results []TargetStatus `json:"results"`
+	}
+	err := c.do(ctx, http.MethodGet, targetPath(name, "history"), q, nil, &resp)
+	return resp.Results, err
+}
+
+// Events returns the recorded status changes, newest first, of the named
+// target or, when name is empty, of every target. a limit of 0 uses the
+// server default.
+func (c *Client) Events(ctx context.Context, name string, limit int) ([]StatusEvent, error) {
+	q := url.Values{}
+	if name != "" {
+		q.Set("target", name)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var resp struct {
+		Events []StatusEvent `json:"events"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/v1/events", q, nil, &resp)
+	return resp.Events, err
+}
+
+// Notifications returns the recorded notification deliveries, newest first,
+// about the named target or, when name is empty, about every target. a limit
+// of 0 uses the server default.
+func (c *Client) Notifications(ctx context.Context, name string, limit int) ([]NotificationDelivery, error) {
+	q := url.Values{}
+	if name != "" {
+		q.Set("target", name)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var resp struct {
+		Notifications []NotificationDelivery `json:"notifications"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/v1/notifications", q, nil, &resp)
+	return resp.Notifications, err
+}
+
+// Incidents returns the incidents and uptime of the named target.
+func (c *Client) Incidents(ctx context.Context, name string) (Incidents, error) {
+	var inc Incidents
+	err := c.do(ctx, http.MethodGet, targetPath(name, "incidents"), nil, nil, &inc)
+	return inc, err
+}
+
+// Uptime returns the named target's uptime over window: "24h", "7d", "30d",
+// or "90d". an empty window uses the server default of 30d.
+func (c *Client) Uptime(ctx context.Context, name, window string) (Uptime, error) {
+	var q url.Values
+	if window != "" {
+		q = url.Values{"window": {window}}
+	}
+	var up Uptime
+	err := c.do(ctx, http.MethodGet, targetPath(name, "uptime"), q, nil, &up)
+	return up, err
+}
+
+// ExcludeIncident excludes one of the target's incidents from uptime.
+func (c *Client) ExcludeIncident(ctx context.Context, name, id, reason, note string) (kenko.Exclusion, error) {
+	var e kenko.Exclusion
+	body := map[string]string{"reason": reason, "note": note}
+	err := c.do(ctx, http.MethodPut, targetPath(name, "incidents", id, "exclusion"), nil, body, &e)
+	return e, err
+}
+
+// ManagedTarget is a target added or replaced through the target API.
+type ManagedTarget struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// AddTarget adds a target. spec is encoded as JSON, e.g. a kenko.TargetSpec,
+// or for kenko serve any target of the config file.
+func (c *Client) AddTarget(ctx context.Context, spec any) (ManagedTarget, error) {
+	var t ManagedTarget
+	err := c.do(ctx, http.MethodPost, "/api/v1/targets", nil, spec, &t)
+	return t, err
+}
+
+// UpdateTarget replaces the named target with spec, which must keep its name.
+func (c *Client) UpdateTarget(ctx context.Context, name string, spec any) (ManagedTarget, error) {
+	var t ManagedTarget
+	err := c.do(ctx, http.MethodPut, targetPath(name), nil, spec, &t)
+	return t, err
+}
+
+// RemoveTarget stops checking the named target.
+func (c *Client) RemoveTarget(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, targetPath(name), nil, nil, nil)
+}
+
+// PauseTarget stops checking the named target until ResumeTarget.
+func (c *Client) PauseTarget(ctx context.Context, name, comment string) (kenko.Pause, error) {
+	var p kenko.Pause
+	body := map[string]string{"comment": comment}
+	err := c.do(ctx, http.MethodPut, targetPath(name, "pause"), nil, body, &p)
+	return p, err
+}
+
+// ResumeTarget resumes checking a target paused with PauseTarget.
+func (c *Client) ResumeTarget(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, targetPath(name, "pause"), nil, nil, nil)
+}
+
+// Deploy is a recorded deploy marker.
+type Deploy struct {
+	Target string    `json:"target"`
+	At     time.Time `json:"at"`
+	Window string    `json:"window"`
+}
+
+// MarkDeploy records a deploy of the named target. a zero window uses the
+// server's default.
+func (c *Client) MarkDeploy(ctx context.Context, name string, window time.Duration) (Deploy, error) {
+	var d Deploy
+	body := map[string]string{}
+	if window > 0 {
+		body["window"] = window.String()
+	}
+	err := c.do(ctx, http.MethodPost, targetPath(name, "deploys"), nil, body, &d)
+	return d, err
+}
+
+// NotifierTest is the outcome of a test notification.
+type NotifierTest struct {
+	Notifier string       `json:"notifier"`
+	Target   string       `json:"target"`
+	Previous kenko.Status `json:"previous"`
+	Status   kenko.Status `json:"status"`
+	Sent     bool         `json:"sent"`
+	Preview  string       `json:"preview,omitempty"`
+}
+
+// TestNotifier sends a test notification through the named notifier, using
+// the latest result of target or a sample failure when target is empty. a
+// non-empty status overrides the notified status. with preview set the
+// message is rendered and returned instead of sent.
+func (c *Client) TestNotifier(ctx context.Context, name, target string, status kenko.Status, preview bool) (NotifierTest, error) {
+	var nt NotifierTest
+	body := map[string]any{"target": target, "status": status, "preview": preview}
+	err := c.do(ctx, http.MethodPost, "/api/v1/notifiers/"+url.PathEscape(name)+"/test", nil, body, &nt)
+	return nt, err
+}
+
+// Heartbeat records a heartbeat for a heartbeat target on behalf of instance
+// and returns the time the server recorded it.
+func (c *Client) Heartbeat(ctx context.Context, name, instance string) (time.Time, error) {
+	var out struct {
+		At time.Time `json:"at"`
+	}
+	body := kenko.Heartbeat{Instance: instance}
+	err := c.do(ctx, http.MethodPost, targetPath(name, "heartbeat"), nil, body, &out)
+	return out.At, err
+}
+
+// Announcements returns every posted announcement, newest first.
+func (c *Client) Announcements(ctx context.Context) ([]kenko.Announcement, error) {
+	var as []kenko.Announcement
+	err := c.do(ctx, http.MethodGet, "/api/v1/announcements", nil, nil, &as)
+	return as, err
+}
+
+// PostAnnouncement posts an incident to the status page.
+func (c *Client) PostAnnouncement(ctx context.Context, title, message string, state kenko.AnnouncementState, components ...string) (kenko.Announcement, error) {
+	var a kenko.Announcement
+	body := map[string]any{"title": title, "message": message, "state": state, "components": components}
+	err := c.do(ctx, http.MethodPost, "/api/v1/announcements", nil, body, &a)
+	return a, err
+}
+
+// UpdateAnnouncement adds a message to an announcement, optionally moving it
+// to a new state.
+func (c *Client) UpdateAnnouncement(ctx context.Context, id, message string, state kenko.AnnouncementState) (kenko.Announcement, error) {
+	var a kenko.Announcement
+	body := map[string]any{"message": message, "state": state}
+	err := c.do(ctx, http.MethodPost, "/api/v1/announcements/"+url.PathEscape(id)+"/updates", nil, body, &a)
+	return a, err
+}
+
+// DeleteAnnouncement removes an announcement.
+func (c *Client) DeleteAnnouncement(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/announcements/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func targetPath(name string, parts ...string) string {
+	p := "/api/v1/targets/" + url.PathEscape(name)
+	for _, part := range parts {
+		p += "/" + url.PathEscape(part)
+	}
+	return p
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out, when out is non-nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := *c.base
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("client: marshal: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return fmt.Errorf("client: %w", err)
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("client: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+		return &APIError{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// newServer runs a kenko instance checking an upstream that answers with
+// *code, and returns a client for its API.
+func newServer(t *testing.T, interval time.Duration, code func() int) *Client {
+	t.Helper()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code())
+	}))
+	t.Cleanup(upstream.Close)
+
+	k, err := kenko.New(
+		kenko.WithTarget("api", upstream.URL),
+		kenko.WithTarget("web", upstream.URL, kenko.WithTags("frontend"), kenko.WithLabels(map[string]string{"team": "web"})),
+		kenko.WithInterval(interval),
+		kenko.WithNotifier("ops", kenko.NotifierFunc(func(context.Context, kenko.Notification) error { return nil })),
+		kenko.WithAPIToken("secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	k.RegisterHandlers(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		k.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !k.Checker().Ready() {
+		if time.Now().After(deadline) {
+			t.Fatal("checker never became ready")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c, err := New(srv.URL+"/", WithToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func ok() int { return http.StatusOK }
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, u := range []string{"localhost:6969", "ftp://example.com", "://"} {
+		if _, err := New(u); err == nil {
+			t.Errorf("New(%q) succeeded", u)
+		}
+	}
+}
+
+func TestClient_HealthReadyStatus(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+	ctx := context.Background()
+
+	h, err := c.Health(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Status != "healthy" {
+		t.Errorf("health = %q, want healthy", h.Status)
+	}
+
+	ready, err := c.Ready(ctx)
+	if err != nil || !ready {
+		t.Errorf("Ready() = %v, %v; want true", ready, err)
+	}
+
+	targets, err := c.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(targets))
+	}
+	for _, ts := range targets {
+		if ts.Status != kenko.StatusHealthy || ts.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %q code %d", ts.Name, ts.Status, ts.StatusCode)
+		}
+		if ts.CheckedAt.IsZero() {
+			t.Errorf("%s: checked_at not decoded", ts.Name)
+		}
+	}
+}
+
+func TestClient_StatusLabels(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+
+	targets, err := c.Status(context.Background(), "team=web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].Name != "web" || targets[0].Labels["team"] != "web" {
+		t.Errorf("targets = %+v, want web with its labels", targets)
+	}
+}
+
+func TestClient_Search(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+
+	targets, err := c.Search(context.Background(), "tag:frontend", kenko.StatusHealthy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].Name != "web" {
+		t.Fatalf("search = %+v, want web", targets)
+	}
+	if len(targets[0].Tags) != 1 || targets[0].Tags[0] != "frontend" {
+		t.Errorf("tags = %v", targets[0].Tags)
+	}
+}
+
+func TestClient_TargetEndpoints(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+	ctx := context.Background()
+
+	tl, err := c.Timeline(ctx, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.Target != "api" || len(tl.Segments) != 1 || tl.Segments[0].Status != kenko.StatusHealthy {
+		t.Errorf("timeline = %+v", tl)
+	}
+
+	history, err := c.History(ctx, "api", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Status != kenko.StatusHealthy {
+		t.Errorf("history = %+v", history)
+	}
+
+	events, err := c.Events(ctx, "api", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("events = %+v, want none while api stays healthy", events)
+	}
+
+	notifications, err := c.Notifications(ctx, "api", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 0 {
+		t.Errorf("notifications = %+v, want none while api stays healthy", notifications)
+	}
+
+	inc, err := c.Incidents(ctx, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inc.Uptime != 1 || len(inc.Incidents) != 0 {
+		t.Errorf("incidents = %+v", inc)
+	}
+
+	up, err := c.Uptime(ctx, "api", "7d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Window != "7d" || up.UptimePercent != 100 || up.Checks == 0 {
+		t.Errorf("uptime = %+v", up)
+	}
+
+	p, err := c.PauseTarget(ctx, "api", "maintenance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Target != "api" || p.Comment != "maintenance" {
+		t.Errorf("pause = %+v", p)
+	}
+	if err := c.ResumeTarget(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := c.AddTarget(ctx, kenko.TargetSpec{Name: "docs", URL: "https://docs.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Name != "docs" {
+		t.Errorf("added = %+v", added)
+	}
+	if _, err := c.UpdateTarget(ctx, "docs", kenko.TargetSpec{Name: "docs", URL: "https://docs.example.com/v2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveTarget(ctx, "docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := c.MarkDeploy(ctx, "api", 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Target != "api" || d.Window != "5m0s" || d.At.IsZero() {
+		t.Errorf("deploy = %+v", d)
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+
+	_, err := c.Timeline(context.Background(), "missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "unknown target" {
+		t.Errorf("err = %+v", apiErr)
+	}
+}
+
+func TestClient_Announcements(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+	ctx := context.Background()
+
+	a, err := c.PostAnnouncement(ctx, "API errors", "looking into it", "", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.State != kenko.AnnouncementInvestigating || len(a.Components) != 1 {
+		t.Errorf("posted = %+v", a)
+	}
+
+	a, err = c.UpdateAnnouncement(ctx, a.ID, "fixed", kenko.AnnouncementResolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.State != kenko.AnnouncementResolved || a.ResolvedAt == nil || len(a.Updates) != 2 {
+		t.Errorf("updated = %+v", a)
+	}
+
+	list, err := c.Announcements(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Announcements() = %v, %v", list, err)
+	}
+
+	if err := c.DeleteAnnouncement(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	var apiErr *APIError
+	if err := c.DeleteAnnouncement(ctx, a.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("second delete err = %v, want 404", err)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Api-Key")
+		w.Write([]byte(`{"status":"healthy"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithHeader("X-Api-Key", "secret"), WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret" {
+		t.Errorf("X-Api-Key = %q, want secret", got)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	c := newServer(t, time.Hour, ok)
+	ctx := context.Background()
+	if _, err := c.PauseTarget(ctx, "api", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	c.header.Del("Authorization")
+	_, err := c.PauseTarget(ctx, "api", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthenticated err = %v, want 401", err)
+	}
+}
+
+func TestClient_TestNotifier(t *testing.T) {
+	c := newServer(t, time.Hour, func() int { return http.StatusOK })
+
+	nt, err := c.TestNotifier(context.Background(), "ops", "", kenko.StatusUnhealthy, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !nt.Sent || nt.Status != kenko.StatusUnhealthy {
+		t.Errorf("test = %+v", nt)
+	}
+
+	_, err = c.TestNotifier(context.Background(), "ops", "", "", true)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotImplemented {
+		t.Errorf("preview err = %v, want 501", err)
+	}
+}
+
+func TestClient_Heartbeat(t *testing.T) {
+	k, err := kenko.New(
+		kenko.WithTarget("api", "https://api.example.com"),
+		kenko.WithTarget("edge", "", kenko.WithHeartbeatTimeout(time.Minute)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	k.RegisterHandlers(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at, err := c.Heartbeat(context.Background(), "edge", "peer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at.IsZero() {
+		t.Error("heartbeat time is zero")
+	}
+
+	_, err = c.Heartbeat(context.Background(), "api", "peer-1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("err = %v, want 409", err)
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// DefaultWatchInterval is how often Watch polls when no interval is given.
+const DefaultWatchInterval = 10 * time.Second
+
+// Event reports a change in a target's status, or a failed poll when Err is set.
+type Event struct {
+	Target string
+	// Previous is empty on the first event for a target.
+	Previous kenko.Status
+	Current  kenko.Status
+	// Result is the check result that produced Current.
+	Result TargetStatus
+	At     time.Time
+	Err    error
+}
+
+// Removed reports whether the event marks a target that is no longer configured.
+func (e Event) Removed() bool {
+	return e.Err == nil && e.Current == ""
+}
+
+// Watch polls /status every interval and sends an Event whenever a target's
+// status changes. the first poll sends the current status of every target.
+// failed polls are sent as events with Err set and do not stop the watch.
+// the channel is closed once ctx is done.
+func (c *Client) Watch(ctx context.Context, interval time.Duration) <-chan Event {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		last := make(map[string]kenko.Status)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, ev := range c.poll(ctx, last) {
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events
+}
+
+// poll fetches /status and returns the changes since last, updating it.
+func (c *Client) poll(ctx context.Context, last map[string]kenko.Status) []Event {
+	now := time.Now()
+	targets, err := c.Status(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return []Event{{At: now, Err: err}}
+	}
+
+	var events []Event
+	seen := make(map[string]bool, len(targets))
+	for _, ts := range targets {
+		seen[ts.Name] = true
+		prev, ok := last[ts.Name]
+		if ok && prev == ts.Status {
+			continue
+		}
+		last[ts.Name] = ts.Status
+		events = append(events, Event{Target: ts.Name, Previous: prev, Current: ts.Status, Result: ts, At: now})
+	}
+	for name, prev := range last {
+		if !seen[name] {
+			delete(last, name)
+			events = append(events, Event{Target: name, Previous: prev, At: now})
+		}
+	}
+	return events
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+func next(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("events channel closed")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestWatch_StatusChanges(t *testing.T) {
+	var code atomic.Int32
+	code.Store(http.StatusOK)
+	c := newServer(t, 20*time.Millisecond, func() int { return int(code.Load()) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := c.Watch(ctx, 10*time.Millisecond)
+
+	initial := map[string]bool{}
+	for range 2 {
+		ev := next(t, events)
+		if ev.Err != nil {
+			t.Fatal(ev.Err)
+		}
+		if ev.Previous != "" || ev.Current != kenko.StatusHealthy {
+			t.Errorf("initial event = %+v", ev)
+		}
+		initial[ev.Target] = true
+	}
+	if !initial["api"] || !initial["web"] {
+		t.Fatalf("initial events for %v, want api and web", initial)
+	}
+
+	code.Store(http.StatusInternalServerError)
+	for range 2 {
+		ev := next(t, events)
+		if ev.Previous != kenko.StatusHealthy || ev.Current != kenko.StatusUnhealthy {
+			t.Errorf("change event = %+v", ev)
+		}
+		if ev.Result.StatusCode != http.StatusInternalServerError {
+			t.Errorf("result status code = %d", ev.Result.StatusCode)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatch_PollError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"failed to read results"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ev := next(t, c.Watch(ctx, time.Hour))
+	if ev.Err == nil {
+		t.Fatalf("event = %+v, want error", ev)
+	}
+}
+
+func TestPoll_RemovedTarget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"targets":[{"name":"api","status":"healthy"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := map[string]kenko.Status{"api": kenko.StatusHealthy, "old": kenko.StatusUnhealthy}
+	events := c.poll(context.Background(), last)
+	if len(events) != 1 || events[0].Target != "old" || !events[0].Removed() {
+		t.Fatalf("events = %+v, want old removed", events)
+	}
+	if _, ok := last["old"]; ok {
+		t.Error("removed target still tracked")
+	}
+}
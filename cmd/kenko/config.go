@@ -1,52 +1,639 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/mail"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	kenko "github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/alertmanager"
+	"github.com/aidantrabs/kenko/boltstore"
+	"github.com/aidantrabs/kenko/command"
+	"github.com/aidantrabs/kenko/email"
+	"github.com/aidantrabs/kenko/geoip"
+	"github.com/aidantrabs/kenko/gotify"
+	"github.com/aidantrabs/kenko/influxdb"
+	"github.com/aidantrabs/kenko/ntfy"
+	"github.com/aidantrabs/kenko/pagerduty"
+	"github.com/aidantrabs/kenko/pgstore"
 	"github.com/aidantrabs/kenko/prommetrics"
+	"github.com/aidantrabs/kenko/pushover"
 	"github.com/aidantrabs/kenko/redisstore"
+	"github.com/aidantrabs/kenko/remotewrite"
+	"github.com/aidantrabs/kenko/revocation"
+	"github.com/aidantrabs/kenko/s3archive"
+	"github.com/aidantrabs/kenko/slack"
+	"github.com/aidantrabs/kenko/telegram"
+	"github.com/aidantrabs/kenko/twilio"
+	"github.com/aidantrabs/kenko/webhook"
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"gopkg.in/yaml.v3"
 )
 
 type target struct {
-	Name string `yaml:"name"`
-	URL  string `yaml:"url"`
+	Name          string            `yaml:"name" schema:"required"`
+	URL           string            `yaml:"url"`
+	Headers       map[string]string `yaml:"headers" schema:"secret"`
+	ExpectHeaders []headerAssertion `yaml:"expect_headers"`
+	ContentHash   *contentHash      `yaml:"content_hash"`
+	Vars          map[string]string `yaml:"vars" schema:"secret"`
+	Steps         []step            `yaml:"steps"`
+	KeepCookies   bool              `yaml:"keep_cookies"`
+	IPVersion     string            `yaml:"ip_version" schema:"enum=any|4|6"`
+	SourceAddr    string            `yaml:"source_addr"`
+	SourceIf      string            `yaml:"source_interface"`
+	SampleEvery   int               `yaml:"sample_every" schema:"min=0"`
+	Method        string            `yaml:"method"`
+	ExpectStatus  []int             `yaml:"expect_status"`
+	MaxBodyBytes  int64             `yaml:"max_body_bytes" schema:"min=0"`
+	Revocation    string            `yaml:"revocation" schema:"enum=off|ocsp|require_staple"`
+	Timeouts      phaseTimeouts     `yaml:"timeouts"`
+	FailureBody   int               `yaml:"failure_body_bytes"`
+	ExpectEnc     []string          `yaml:"expect_encoding"`
+	Auth          *auth             `yaml:"auth"`
+	Tags          []string          `yaml:"tags"`
+	Labels        map[string]string `yaml:"labels"`
+	Enabled       *bool             `yaml:"enabled"`
+	Maintenance   []maintenance     `yaml:"maintenance"`
+	Group         string            `yaml:"group"`
+	Severity      string            `yaml:"severity" schema:"enum=critical|warning|info"`
+	Heartbeat     time.Duration     `yaml:"heartbeat_timeout"`
+	Interval      time.Duration     `yaml:"interval"`
+	Schedule      string            `yaml:"schedule"`
+	Timeout       time.Duration     `yaml:"timeout"`
+	Retries       int               `yaml:"retries" schema:"min=0"`
+	RetryBackoff  time.Duration     `yaml:"retry_backoff"`
+	RetryOn       []string          `yaml:"retry_on"`
+	FailThreshold int               `yaml:"failure_threshold" schema:"min=0"`
+	PassThreshold int               `yaml:"success_threshold" schema:"min=0"`
+	SlowLatency   time.Duration     `yaml:"slow_latency"`
+	SlowChecks    int               `yaml:"slow_checks" schema:"min=0"`
+	Endpoints     string            `yaml:"endpoints" schema:"enum=single|per_family|per_ip"`
+}
+
+type auth struct {
+	Header         string            `yaml:"header"`
+	Prefix         string            `yaml:"prefix"`
+	RefreshCommand []string          `yaml:"refresh_command"`
+	RefreshURL     string            `yaml:"refresh_url"`
+	RefreshMethod  string            `yaml:"refresh_method"`
+	RefreshHeaders map[string]string `yaml:"refresh_headers" schema:"secret"`
+	TokenJSON      string            `yaml:"token_json"`
+}
+
+type step struct {
+	Name          string            `yaml:"name"`
+	Method        string            `yaml:"method"`
+	URL           string            `yaml:"url"`
+	Headers       map[string]string `yaml:"headers" schema:"secret"`
+	Body          string            `yaml:"body"`
+	ExpectStatus  int               `yaml:"expect_status"`
+	ExpectHeaders []headerAssertion `yaml:"expect_headers"`
+	Extract       []extraction      `yaml:"extract"`
+}
+
+type extraction struct {
+	Name   string `yaml:"name"`
+	Header string `yaml:"header"`
+	JSON   string `yaml:"json"`
+}
+
+type contentHash struct {
+	Baseline string `yaml:"baseline"`
+}
+
+type phaseTimeouts struct {
+	DNS            time.Duration `yaml:"dns"`
+	Connect        time.Duration `yaml:"connect"`
+	TLSHandshake   time.Duration `yaml:"tls_handshake"`
+	ResponseHeader time.Duration `yaml:"response_header"`
+}
+
+type transport struct {
+	MaxIdleConns        int           `yaml:"max_idle_conns" schema:"min=0"`
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host" schema:"min=0"`
+	MaxConnsPerHost     int           `yaml:"max_conns_per_host" schema:"min=0"`
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout"`
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout"`
+	DisableKeepAlives   bool          `yaml:"disable_keep_alives"`
+}
+
+type redisTLS struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+type headerAssertion struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+	Regex string `yaml:"regex"`
+}
+
+type httpDefaults struct {
+	UserAgent string            `yaml:"user_agent"`
+	Accept    string            `yaml:"accept"`
+	Headers   map[string]string `yaml:"headers" schema:"secret"`
+}
+
+type heartbeat struct {
+	URL      string            `yaml:"url" schema:"required,secret"`
+	Interval time.Duration     `yaml:"interval"`
+	Headers  map[string]string `yaml:"headers" schema:"secret"`
+	Instance string            `yaml:"instance"`
+	Format   string            `yaml:"format" schema:"enum=kenko|alertmanager"`
+}
+
+type archive struct {
+	Bucket   string        `yaml:"bucket" schema:"required"`
+	Prefix   string        `yaml:"prefix"`
+	Endpoint string        `yaml:"endpoint"`
+	After    time.Duration `yaml:"after" schema:"required"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+type retention struct {
+	Results     time.Duration `yaml:"results"`
+	Transitions time.Duration `yaml:"transitions"`
+	Deliveries  time.Duration `yaml:"deliveries"`
+	Interval    time.Duration `yaml:"interval"`
+}
+
+type rollups struct {
+	Interval   time.Duration `yaml:"interval"`
+	FiveMinute time.Duration `yaml:"five_minute"`
+	Hourly     time.Duration `yaml:"hourly"`
+}
+
+type remoteWrite struct {
+	URL         string            `yaml:"url" schema:"required,secret"`
+	Interval    time.Duration     `yaml:"interval"`
+	Username    string            `yaml:"username"`
+	Password    string            `yaml:"password" schema:"secret"`
+	BearerToken string            `yaml:"bearer_token" schema:"secret"`
+	Headers     map[string]string `yaml:"headers" schema:"secret"`
+	Labels      map[string]string `yaml:"labels"`
+}
+
+type influxDB struct {
+	URL           string            `yaml:"url" schema:"required,secret"`
+	Token         string            `yaml:"token" schema:"secret"`
+	Username      string            `yaml:"username"`
+	Password      string            `yaml:"password" schema:"secret"`
+	Measurement   string            `yaml:"measurement"`
+	Tags          map[string]string `yaml:"tags"`
+	FlushInterval time.Duration     `yaml:"flush_interval"`
+	BatchSize     int               `yaml:"batch_size" schema:"min=0"`
+}
+
+type notifier struct {
+	Name         string                `yaml:"name" schema:"required"`
+	Webhook      *webhookNotifier      `yaml:"webhook"`
+	Slack        *slackNotifier        `yaml:"slack"`
+	Telegram     *telegramNotifier     `yaml:"telegram"`
+	PagerDuty    *pagerDutyNotifier    `yaml:"pagerduty"`
+	Email        *emailNotifier        `yaml:"email"`
+	Ntfy         *ntfyNotifier         `yaml:"ntfy"`
+	Gotify       *gotifyNotifier       `yaml:"gotify"`
+	Pushover     *pushoverNotifier     `yaml:"pushover"`
+	Alertmanager *alertmanagerNotifier `yaml:"alertmanager"`
+	Twilio       *twilioNotifier       `yaml:"twilio"`
+	Command      *commandNotifier      `yaml:"command"`
+}
+
+type webhookNotifier struct {
+	URL     string            `yaml:"url" schema:"required,secret"`
+	Headers map[string]string `yaml:"headers" schema:"secret"`
+	Body    string            `yaml:"body"`
+	Secret  string            `yaml:"secret" schema:"secret"`
+}
+
+// slackNotifier posts through an incoming webhook_url, or as a bot with a
+// token to a channel.
+type slackNotifier struct {
+	WebhookURL    string `yaml:"webhook_url" schema:"secret"`
+	Token         string `yaml:"token" schema:"secret"`
+	Channel       string `yaml:"channel"`
+	StatusPageURL string `yaml:"status_page_url"`
+	Text          string `yaml:"text"`
+}
+
+// telegramNotifier sends as a bot to chat_id, or to the chat of the first of
+// routes the target matches.
+type telegramNotifier struct {
+	Token  string          `yaml:"token" schema:"required,secret"`
+	ChatID string          `yaml:"chat_id"`
+	Text   string          `yaml:"text"`
+	Routes []telegramRoute `yaml:"routes"`
+}
+
+type telegramRoute struct {
+	ChatID  string            `yaml:"chat_id" schema:"required"`
+	Targets []string          `yaml:"targets"`
+	Groups  []string          `yaml:"groups"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+type pagerDutyNotifier struct {
+	RoutingKey     string `yaml:"routing_key" schema:"required,secret"`
+	EventsURL      string `yaml:"events_url"`
+	DedupKeyPrefix string `yaml:"dedup_key_prefix"`
+	Summary        string `yaml:"summary"`
+}
+
+// emailNotifier mails through the SMTP server at addr, rendering subject and
+// body as text/template templates of the notification.
+type emailNotifier struct {
+	Addr     string       `yaml:"addr" schema:"required"`
+	From     string       `yaml:"from" schema:"required"`
+	To       []string     `yaml:"to"`
+	Username string       `yaml:"username"`
+	Password string       `yaml:"password" schema:"secret"`
+	TLS      string       `yaml:"tls" schema:"enum=starttls|tls|none"`
+	Subject  string       `yaml:"subject"`
+	Body     string       `yaml:"body"`
+	Routes   []emailRoute `yaml:"routes"`
+}
+
+// ntfyNotifier publishes to topic on server, authenticated with a token or a
+// username and password.
+type ntfyNotifier struct {
+	Topic         string `yaml:"topic" schema:"required"`
+	Server        string `yaml:"server"`
+	Token         string `yaml:"token" schema:"secret"`
+	Username      string `yaml:"username"`
+	Password      string `yaml:"password" schema:"secret"`
+	StatusPageURL string `yaml:"status_page_url"`
+}
+
+type gotifyNotifier struct {
+	URL   string `yaml:"url" schema:"required"`
+	Token string `yaml:"token" schema:"required,secret"`
+}
+
+type pushoverNotifier struct {
+	Token         string `yaml:"token" schema:"required,secret"`
+	User          string `yaml:"user" schema:"required,secret"`
+	Device        string `yaml:"device"`
+	StatusPageURL string `yaml:"status_page_url"`
+}
+
+// alertmanagerNotifier posts alerts to the Alertmanager at url.
+type alertmanagerNotifier struct {
+	URL           string            `yaml:"url" schema:"required"`
+	AlertName     string            `yaml:"alertname"`
+	Labels        map[string]string `yaml:"labels"`
+	TTL           time.Duration     `yaml:"ttl"`
+	StatusPageURL string            `yaml:"status_page_url"`
+	Username      string            `yaml:"username"`
+	Password      string            `yaml:"password" schema:"secret"`
+}
+
+// twilioNotifier texts, or with voice calls, the to numbers from the Twilio
+// number from.
+type twilioNotifier struct {
+	AccountSID string   `yaml:"account_sid" schema:"required"`
+	AuthToken  string   `yaml:"auth_token" schema:"required,secret"`
+	From       string   `yaml:"from" schema:"required"`
+	To         []string `yaml:"to" schema:"required"`
+	Voice      bool     `yaml:"voice"`
+}
+
+// commandNotifier runs the program and arguments in run, without a shell.
+type commandNotifier struct {
+	Run []string          `yaml:"run" schema:"required"`
+	Env map[string]string `yaml:"env" schema:"secret"`
+	Dir string            `yaml:"dir"`
+}
+
+// e164 matches a phone number in E.164 format.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+type emailRoute struct {
+	To      []string          `yaml:"to"`
+	Targets []string          `yaml:"targets"`
+	Groups  []string          `yaml:"groups"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+type notifyThrottle struct {
+	Limit int           `yaml:"limit" schema:"required,min=1"`
+	Per   time.Duration `yaml:"per" schema:"required"`
+}
+
+// route sends the status changes matching its criteria to notifiers.
+type route struct {
+	Targets    []string          `yaml:"targets"`
+	Groups     []string          `yaml:"groups"`
+	Severities []string          `yaml:"severities"`
+	Labels     map[string]string `yaml:"labels"`
+	Statuses   []string          `yaml:"statuses"`
+	Notifiers  []string          `yaml:"notifiers"`
+	Continue   bool              `yaml:"continue"`
+	Escalation []escalationStep  `yaml:"escalation"`
+}
+
+type escalationStep struct {
+	After     time.Duration `yaml:"after" schema:"required"`
+	Notifiers []string      `yaml:"notifiers" schema:"required"`
+}
+
+type notifyRetry struct {
+	Attempts int           `yaml:"attempts" schema:"min=0"`
+	Backoff  time.Duration `yaml:"backoff"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+type storeBreaker struct {
+	Failures int           `yaml:"failures" schema:"min=0"`
+	Cooldown time.Duration `yaml:"cooldown"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Buffer   int           `yaml:"buffer" schema:"min=0"`
+}
+
+type group struct {
+	Quorum      *int          `yaml:"quorum" schema:"min=1"`
+	Maintenance []maintenance `yaml:"maintenance"`
+}
+
+type maintenance struct {
+	Schedule string        `yaml:"schedule" schema:"required"`
+	Duration time.Duration `yaml:"duration" schema:"required"`
+}
+
+type statusPage struct {
+	Title       string                      `yaml:"title"`
+	Description string                      `yaml:"description"`
+	Components  []component                 `yaml:"components"`
+	Statuses    map[string]string           `yaml:"statuses"`
+	Locales     map[string]statusPageLocale `yaml:"locales"`
+}
+
+type component struct {
+	Target      string `yaml:"target" schema:"required"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+type statusPageLocale struct {
+	Title       string                   `yaml:"title"`
+	Description string                   `yaml:"description"`
+	Components  map[string]componentText `yaml:"components"`
+	Statuses    map[string]string        `yaml:"statuses"`
+}
+
+type componentText struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
 }
 
 type config struct {
-	Port          int           `yaml:"port"`
-	CheckInterval time.Duration `yaml:"check_interval"`
-	CheckTimeout  time.Duration `yaml:"check_timeout"`
-	RedisAddr     string        `yaml:"redis_addr"`
-	RedisPassword string        `yaml:"redis_password"`
-	Targets       []target      `yaml:"targets"`
+	Port          int               `yaml:"port" schema:"min=1,max=65535"`
+	Region        string            `yaml:"region"`
+	APIToken      string            `yaml:"api_token" schema:"secret"`
+	CheckInterval time.Duration     `yaml:"check_interval"`
+	CheckTimeout  time.Duration     `yaml:"check_timeout"`
+	Warmup        time.Duration     `yaml:"warmup"`
+	RedisAddr     string            `yaml:"redis_addr"`
+	RedisUsername string            `yaml:"redis_username"`
+	RedisPassword string            `yaml:"redis_password" schema:"secret"`
+	RedisDB       int               `yaml:"redis_db" schema:"min=0"`
+	RedisTLS      *redisTLS         `yaml:"redis_tls"`
+	RedisCodec    string            `yaml:"redis_codec" schema:"enum=json|msgpack"`
+	RedisPrefix   string            `yaml:"redis_key_prefix"`
+	RedisHistory  *int              `yaml:"redis_history_size" schema:"min=0"`
+	HistoryMaxAge time.Duration     `yaml:"redis_history_max_age"`
+	PostgresURL   string            `yaml:"postgres_url" schema:"secret"`
+	MemoryHistory *int              `yaml:"memory_history_size" schema:"min=0"`
+	BoltPath      string            `yaml:"bolt_path"`
+	BoltHistory   *int              `yaml:"bolt_history_size" schema:"min=0"`
+	WriteQueue    int               `yaml:"write_queue_size" schema:"min=0"`
+	WriteBatch    int               `yaml:"write_batch_size" schema:"min=0"`
+	WriteDelay    time.Duration     `yaml:"write_batch_delay"`
+	CacheTTL      time.Duration     `yaml:"result_cache_ttl"`
+	StoreBreaker  *storeBreaker     `yaml:"store_breaker"`
+	SnapshotPath  string            `yaml:"snapshot_path"`
+	HTTPDefaults  httpDefaults      `yaml:"http_defaults"`
+	Transport     *transport        `yaml:"transport"`
+	Vars          map[string]string `yaml:"vars" schema:"secret"`
+	TLSMetrics    bool              `yaml:"tls_metrics"`
+	MetricLabels  []string          `yaml:"metric_labels"`
+	SourceAddr    string            `yaml:"source_addr"`
+	SourceIf      string            `yaml:"source_interface"`
+	GeoIPDBs      []string          `yaml:"geoip_databases"`
+	StatusPage    *statusPage       `yaml:"status_page"`
+	Groups        map[string]group  `yaml:"groups"`
+	Heartbeat     *heartbeat        `yaml:"heartbeat"`
+	Archive       *archive          `yaml:"archive"`
+	Retention     *retention        `yaml:"retention"`
+	Rollups       *rollups          `yaml:"rollups"`
+	RemoteWrite   *remoteWrite      `yaml:"remote_write"`
+	InfluxDB      *influxDB         `yaml:"influxdb"`
+	Notifiers     []notifier        `yaml:"notifiers"`
+	NotifyRetry   *notifyRetry      `yaml:"notify_retry"`
+	NotifyRepeat  time.Duration     `yaml:"notify_repeat"`
+	Throttle      *notifyThrottle   `yaml:"notify_throttle"`
+	GroupWindow   time.Duration     `yaml:"notify_group_window"`
+	Routes        []route           `yaml:"routes"`
+	Vault         *vaultConfig      `yaml:"vault"`
+	SaveTargets   bool              `yaml:"persist_targets"`
+	SlugNames     bool              `yaml:"normalize_names"`
+	Targets       []target          `yaml:"targets"`
+
+	// checksum is the sha256 of the config source, so a periodic re-fetch
+	// only reloads when it changed.
+	checksum string
+	// lines locates fields in the config file for error messages.
+	lines positions
+}
+
+// defaultConfig returns the settings used when running without a config file.
+func defaultConfig() *config {
+	return &config{
+		Port:          6969,
+		CheckInterval: 30 * time.Second,
+		CheckTimeout:  5 * time.Second,
+	}
 }
 
 func loadConfig(path string) (*config, error) {
-	data, err := os.ReadFile(path)
+	return loadConfigAs(path, "")
+}
+
+// loadConfigAs loads a config file in the given format, or in the format
+// implied by its extension when format is empty.
+func loadConfigAs(path, format string) (*config, error) {
+	cfg, err := readConfig(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// readConfig decodes a config file without validating it, so overrides can
+// be applied first.
+func readConfig(path, format string) (*config, error) {
+	format, err := configFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fetchConfig(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
+	checksum := sha256.Sum256(data)
 
-	expanded := os.ExpandEnv(string(data))
+	expanded := expandEnv(string(data))
 
-	var cfg config
-	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
-		return nil, fmt.Errorf("parsing config: %w", err)
+	// json is yaml too, so errors in either can point at a line; toml is
+	// converted first and has no lines to report
+	var lines positions
+	if format != "toml" {
+		lines = nodePositions([]byte(expanded))
 	}
 
-	if err := cfg.validate(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+	data, err = toYAML([]byte(expanded), format)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s config: %w", format, err)
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s config: %w", format, err)
+	}
+	s := configSchema()
+	if err := resolveSecretFiles(s, "", doc); err != nil {
+		return nil, fmt.Errorf("reading secret: %w", err)
+	}
+	if err := resolveVaultRefs(s, doc); err != nil {
+		return nil, fmt.Errorf("reading secret: %w", err)
+	}
+	if err := s.validate("", doc); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", lines.locate(err))
+	}
+	applyDefaults(doc)
+
+	// decode the document with secrets filled in rather than the raw data
+	if data, err = yaml.Marshal(doc); err != nil {
+		return nil, fmt.Errorf("parsing %s config: %w", format, err)
+	}
+	var cfg config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s config: %w", format, err)
 	}
+	cfg.checksum = hex.EncodeToString(checksum[:])
+	cfg.lines = lines
 
 	return &cfg, nil
 }
 
+// configFormat returns format, or when it is empty the format implied by the
+// path's extension, or a remote url's path. files without a .json or .toml
+// extension are yaml.
+func configFormat(path, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		switch strings.ToLower(configExt(path)) {
+		case ".json":
+			return "json", nil
+		case ".toml":
+			return "toml", nil
+		}
+		return "yaml", nil
+	case "yaml", "yml":
+		return "yaml", nil
+	case "json":
+		return "json", nil
+	case "toml":
+		return "toml", nil
+	default:
+		return "", fmt.Errorf("config format must be yaml, json, or toml, got %q", format)
+	}
+}
+
+// toYAML converts json and toml config data to yaml so every format shares
+// the same field names, schema, and duration parsing.
+func toYAML(data []byte, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		// json is valid yaml, but checking it as json gives json error messages
+		var raw any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	case "toml":
+		var raw map[string]any
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(raw)
+	}
+	return data, nil
+}
+
+// expandEnv replaces $VAR and ${VAR} with environment variables, like
+// os.ExpandEnv. ${VAR:-fallback} uses fallback when VAR is unset or empty and
+// ${VAR-fallback} only when it is unset. $$ is a literal $.
+func expandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		if v, fallback, ok := strings.Cut(name, ":-"); ok {
+			if val := os.Getenv(v); val != "" {
+				return val
+			}
+			return fallback
+		}
+		if v, fallback, ok := strings.Cut(name, "-"); ok {
+			if val, set := os.LookupEnv(v); set {
+				return val
+			}
+			return fallback
+		}
+		return os.Getenv(name)
+	})
+}
+
+// validate checks the config after overrides are applied, reporting the line
+// of the offending field when it came from the config file.
 func (c *config) validate() error {
+	return c.lines.locate(c.validateFields())
+}
+
+func (c *config) validateFields() error {
 	if c.Port < 1 || c.Port > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535, got %d", c.Port)
 	}
@@ -59,53 +646,1668 @@ func (c *config) validate() error {
 		return fmt.Errorf("check_timeout must be positive, got %s", c.CheckTimeout)
 	}
 
+	if c.Warmup < 0 {
+		return fmt.Errorf("warmup must not be negative, got %s", c.Warmup)
+	}
+
+	if err := validateSource(c.SourceAddr, c.SourceIf); err != nil {
+		return err
+	}
+
+	if c.WriteQueue < 0 {
+		return fmt.Errorf("write_queue_size must not be negative, got %d", c.WriteQueue)
+	}
+	if c.WriteBatch < 0 {
+		return fmt.Errorf("write_batch_size must not be negative, got %d", c.WriteBatch)
+	}
+	if c.WriteDelay < 0 {
+		return fmt.Errorf("write_batch_delay must not be negative, got %s", c.WriteDelay)
+	}
+	if (c.WriteBatch > 0 || c.WriteDelay > 0) && c.WriteQueue == 0 {
+		return fmt.Errorf("write_batch_size and write_batch_delay need write_queue_size")
+	}
+
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("result_cache_ttl must not be negative, got %s", c.CacheTTL)
+	}
+
+	if strings.HasSuffix(c.RedisPrefix, ":") || strings.ContainsAny(c.RedisPrefix, " \t\n") {
+		return fmt.Errorf("redis_key_prefix must not end with a colon or contain spaces, got %q", c.RedisPrefix)
+	}
+
+	if _, ok := redisstore.Codecs()[c.RedisCodec]; c.RedisCodec != "" && !ok {
+		return fmt.Errorf("redis_codec must be json or msgpack, got %q", c.RedisCodec)
+	}
+
+	if c.RedisDB < 0 {
+		return fmt.Errorf("redis_db must not be negative, got %d", c.RedisDB)
+	}
+	if c.RedisTLS != nil {
+		if _, err := c.RedisTLS.config(); err != nil {
+			return fmt.Errorf("redis_tls: %w", err)
+		}
+	}
+
+	if c.RedisHistory != nil && *c.RedisHistory < 0 {
+		return fmt.Errorf("redis_history_size must not be negative, got %d", *c.RedisHistory)
+	}
+	if c.HistoryMaxAge < 0 {
+		return fmt.Errorf("redis_history_max_age must not be negative, got %s", c.HistoryMaxAge)
+	}
+
+	if c.BoltHistory != nil && *c.BoltHistory < 0 {
+		return fmt.Errorf("bolt_history_size must not be negative, got %d", *c.BoltHistory)
+	}
+	if c.MemoryHistory != nil && *c.MemoryHistory < 0 {
+		return fmt.Errorf("memory_history_size must not be negative, got %d", *c.MemoryHistory)
+	}
+
+	stores := 0
+	for _, set := range []string{c.RedisAddr, c.PostgresURL, c.BoltPath} {
+		if set != "" {
+			stores++
+		}
+	}
+	if stores > 1 {
+		return fmt.Errorf("set only one of redis_addr, postgres_url, and bolt_path")
+	}
+	if b := c.StoreBreaker; b != nil {
+		if stores == 0 {
+			return fmt.Errorf("store_breaker: needs redis_addr, postgres_url, or bolt_path")
+		}
+		if err := b.validate(); err != nil {
+			return fmt.Errorf("store_breaker: %w", err)
+		}
+	}
+	if c.PostgresURL != "" {
+		if err := pgstore.ValidateDSN(c.PostgresURL); err != nil {
+			return fmt.Errorf("postgres_url: %w", err)
+		}
+	}
+
 	if len(c.Targets) == 0 {
 		return fmt.Errorf("at least one target is required")
 	}
 
+	seen := make(map[string]int, len(c.Targets))
 	for i, t := range c.Targets {
+		path := fmt.Sprintf("targets[%d]", i)
 		if t.Name == "" {
-			return fmt.Errorf("target[%d]: name must not be empty", i)
+			return at(path, fmt.Errorf("target[%d]: name must not be empty", i))
 		}
-		if t.URL == "" {
-			return fmt.Errorf("target[%d] %q: url must not be empty", i, t.Name)
-		}
-		u, err := url.Parse(t.URL)
+		slug, err := c.targetName(t.Name)
 		if err != nil {
-			return fmt.Errorf("target[%d] %q: invalid url: %w", i, t.Name, err)
+			return at(path+".name", fmt.Errorf("target[%d]: %w", i, err))
+		}
+		if j, ok := seen[slug]; ok {
+			return at(path+".name", fmt.Errorf("target[%d] %q: name collides with target[%d] %q as %q", i, t.Name, j, c.Targets[j].Name, slug))
+		}
+		seen[slug] = i
+		if err := t.validate(); err != nil {
+			return at(path, fmt.Errorf("target[%d] %q: %w", i, t.Name, err))
+		}
+	}
+
+	for name, g := range c.Groups {
+		if g.Quorum != nil && *g.Quorum < 1 {
+			return at("groups."+name+".quorum", fmt.Errorf("groups.%s.quorum must be at least 1, got %d", name, *g.Quorum))
+		}
+		for j, m := range g.Maintenance {
+			path := fmt.Sprintf("groups.%s.maintenance[%d]", name, j)
+			if err := m.validate(); err != nil {
+				return at(path, fmt.Errorf("%s: %w", path, err))
+			}
+		}
+		if !slices.ContainsFunc(c.Targets, func(t target) bool { return t.Group == name }) {
+			return at("groups."+name, fmt.Errorf("groups.%s: no target is in the group", name))
+		}
+	}
+
+	if c.Heartbeat != nil {
+		if err := validateURL(c.Heartbeat.URL); err != nil {
+			return fmt.Errorf("heartbeat: %w", err)
+		}
+		if c.Heartbeat.Interval < 0 {
+			return fmt.Errorf("heartbeat: interval must not be negative, got %s", c.Heartbeat.Interval)
+		}
+	}
+
+	if c.Archive != nil {
+		if err := c.Archive.validate(); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		if c.RedisAddr != "" && c.RedisHistory != nil && *c.RedisHistory == 0 {
+			return fmt.Errorf("archive: needs redis history, but redis_history_size is 0")
+		}
+		if c.BoltPath != "" && c.BoltHistory != nil && *c.BoltHistory == 0 {
+			return fmt.Errorf("archive: needs bolt history, but bolt_history_size is 0")
+		}
+		if stores == 0 && c.MemoryHistory != nil && *c.MemoryHistory == 0 {
+			return fmt.Errorf("archive: needs history, but memory_history_size is 0")
+		}
+	}
+
+	if r := c.Retention; r != nil {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("retention: %w", err)
+		}
+		if c.Archive != nil && r.Results > 0 && r.Results < c.Archive.After {
+			return fmt.Errorf("retention: results must not be shorter than archive after %s, got %s", c.Archive.After, r.Results)
+		}
+	}
+
+	if r := c.Rollups; r != nil {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("rollups: %w", err)
+		}
+		if c.RedisAddr != "" && c.RedisHistory != nil && *c.RedisHistory == 0 {
+			return fmt.Errorf("rollups: needs redis history, but redis_history_size is 0")
+		}
+		if c.BoltPath != "" && c.BoltHistory != nil && *c.BoltHistory == 0 {
+			return fmt.Errorf("rollups: needs bolt history, but bolt_history_size is 0")
+		}
+		if stores == 0 && c.MemoryHistory != nil && *c.MemoryHistory == 0 {
+			return fmt.Errorf("rollups: needs history, but memory_history_size is 0")
+		}
+	}
+
+	if c.RemoteWrite != nil {
+		if err := c.RemoteWrite.validate(); err != nil {
+			return fmt.Errorf("remote_write: %w", err)
+		}
+	}
+
+	if c.InfluxDB != nil {
+		if err := c.InfluxDB.validate(); err != nil {
+			return fmt.Errorf("influxdb: %w", err)
+		}
+	}
+
+	names := make(map[string]int, len(c.Notifiers))
+	for i, n := range c.Notifiers {
+		path := fmt.Sprintf("notifiers[%d]", i)
+		if j, ok := names[n.Name]; ok {
+			return at(path+".name", fmt.Errorf("notifiers[%d]: name %q is already used by notifiers[%d]", i, n.Name, j))
+		}
+		names[n.Name] = i
+		if err := n.validate(); err != nil {
+			return at(path, fmt.Errorf("notifiers[%d] %q: %w", i, n.Name, err))
+		}
+	}
+	if r := c.NotifyRetry; r != nil {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("notify_retry: %w", err)
+		}
+	}
+	if c.NotifyRepeat < 0 {
+		return fmt.Errorf("notify_repeat must not be negative, got %s", c.NotifyRepeat)
+	}
+	if c.GroupWindow < 0 {
+		return fmt.Errorf("notify_group_window must not be negative, got %s", c.GroupWindow)
+	}
+	if t := c.Throttle; t != nil && t.Per <= 0 {
+		return at("notify_throttle.per", fmt.Errorf("notify_throttle.per must be positive, got %s", t.Per))
+	}
+	for i, r := range c.Routes {
+		path := fmt.Sprintf("routes[%d]", i)
+		if len(r.Notifiers) == 0 {
+			return at(path, fmt.Errorf("%s: notifiers: required", path))
+		}
+		for _, name := range r.Notifiers {
+			if _, ok := names[name]; !ok {
+				return at(path+".notifiers", fmt.Errorf("%s: unknown notifier %q", path, name))
+			}
 		}
-		if u.Scheme != "http" && u.Scheme != "https" {
-			return fmt.Errorf("target[%d] %q: url scheme must be http or https, got %q", i, t.Name, u.Scheme)
+		for _, status := range r.Statuses {
+			switch kenko.Status(status) {
+			case kenko.StatusDegraded, kenko.StatusUnhealthy, kenko.StatusUnknown:
+			default:
+				return at(path+".statuses", fmt.Errorf("%s: statuses must be degraded, unhealthy, or unknown, got %q", path, status))
+			}
 		}
-		if u.Host == "" {
-			return fmt.Errorf("target[%d] %q: url must have a host", i, t.Name)
+		for _, group := range r.Groups {
+			if !slices.ContainsFunc(c.Targets, func(t target) bool { return t.Group == group }) {
+				return at(path+".groups", fmt.Errorf("%s: no target is in group %q", path, group))
+			}
+		}
+		for _, severity := range r.Severities {
+			if !slices.Contains(kenko.Severities, kenko.Severity(severity)) {
+				return at(path+".severities", fmt.Errorf("%s: severities must be critical, warning, or info, got %q", path, severity))
+			}
+		}
+		for j, step := range r.Escalation {
+			stepPath := fmt.Sprintf("%s.escalation[%d]", path, j)
+			if step.After <= 0 {
+				return at(stepPath+".after", fmt.Errorf("%s.after must be positive, got %s", stepPath, step.After))
+			}
+			for _, name := range step.Notifiers {
+				if _, ok := names[name]; !ok {
+					return at(stepPath+".notifiers", fmt.Errorf("%s: unknown notifier %q", stepPath, name))
+				}
+			}
+		}
+	}
+
+	if c.Transport != nil {
+		if err := c.Transport.validate(); err != nil {
+			return fmt.Errorf("transport: %w", err)
+		}
+	}
+
+	if c.StatusPage != nil {
+		if err := c.StatusPage.validate(seen, c.targetName); err != nil {
+			return fmt.Errorf("status_page: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func configToOptions(cfg *config) []kenko.Option {
-	opts := make([]kenko.Option, 0, len(cfg.Targets)+4)
+// targetName returns the name a config target is checked under: its slug
+// with normalize_names, or the name itself.
+func (c *config) targetName(name string) (string, error) {
+	if !c.SlugNames {
+		return name, nil
+	}
+	return kenko.SlugName(name)
+}
 
-	for _, t := range cfg.Targets {
-		opts = append(opts, kenko.WithTarget(t.Name, t.URL))
+// validate checks the status page against the config's targets, given by
+// the names normalize returns for them.
+func (sp *statusPage) validate(targets map[string]int, normalize func(string) (string, error)) error {
+	known := func(name string) bool {
+		slug, err := normalize(name)
+		_, ok := targets[slug]
+		return err == nil && ok
 	}
 
-	opts = append(opts,
-		kenko.WithInterval(cfg.CheckInterval),
-		kenko.WithTimeout(cfg.CheckTimeout),
-	)
+	for i, comp := range sp.Components {
+		if !known(comp.Target) {
+			return fmt.Errorf("components[%d]: target %q is not configured", i, comp.Target)
+		}
+	}
+	if err := validateStatusLabels(sp.Statuses); err != nil {
+		return err
+	}
+
+	for tag, loc := range sp.Locales {
+		for name := range loc.Components {
+			if !known(name) {
+				return fmt.Errorf("locales.%s.components: target %q is not configured", tag, name)
+			}
+		}
+		if err := validateStatusLabels(loc.Statuses); err != nil {
+			return fmt.Errorf("locales.%s.%w", tag, err)
+		}
+	}
+	return nil
+}
 
-	if cfg.RedisAddr != "" {
-		var rsOpts []redisstore.Option
-		if cfg.RedisPassword != "" {
-			rsOpts = append(rsOpts, redisstore.WithPassword(cfg.RedisPassword))
+func validateStatusLabels(labels map[string]string) error {
+	for status := range labels {
+		if _, ok := kenko.DefaultStatusLabels[kenko.Status(status)]; !ok {
+			return fmt.Errorf("statuses: unknown status %q, want healthy, degraded, unhealthy, or unknown", status)
 		}
-		opts = append(opts, kenko.WithStore(redisstore.New(cfg.RedisAddr, rsOpts...)))
 	}
+	return nil
+}
 
-	opts = append(opts, kenko.WithMetrics(prommetrics.New()))
+func (t *target) validate() error {
+	switch {
+	case strings.Contains(t.URL, "{{"):
+		if err := kenko.ValidateTemplate(t.URL); err != nil {
+			return fmt.Errorf("invalid url template: %w", err)
+		}
+	case t.Heartbeat > 0 && t.URL == "":
+		// heartbeat targets are not requested, so the url is only shown
+	case len(t.Steps) == 0 || t.URL != "":
+		if err := validateURL(t.URL); err != nil {
+			return err
+		}
+	}
 
-	return opts
+	if _, ok := t.Labels[""]; ok {
+		return fmt.Errorf("labels must not have an empty key")
+	}
+
+	if t.Heartbeat < 0 {
+		return fmt.Errorf("heartbeat_timeout must not be negative, got %s", t.Heartbeat)
+	}
+	if t.Heartbeat > 0 && (len(t.Steps) > 0 || t.Endpoints != "" && t.Endpoints != "single") {
+		return fmt.Errorf("heartbeat_timeout cannot be combined with steps or endpoints")
+	}
+
+	if err := validateHeaderAssertions(t.ExpectHeaders); err != nil {
+		return fmt.Errorf("expect_headers%w", err)
+	}
+
+	if _, err := parseIPVersion(t.IPVersion); err != nil {
+		return err
+	}
+
+	if err := validateSource(t.SourceAddr, t.SourceIf); err != nil {
+		return err
+	}
+
+	for k, code := range t.ExpectStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("expect_status[%d] must be between 100 and 599, got %d", k, code)
+		}
+	}
+
+	if t.MaxBodyBytes < 0 {
+		return fmt.Errorf("max_body_bytes must not be negative, got %d", t.MaxBodyBytes)
+	}
+
+	if t.SampleEvery < 0 {
+		return fmt.Errorf("sample_every must not be negative, got %d", t.SampleEvery)
+	}
+
+	if _, err := parseRevocation(t.Revocation); err != nil {
+		return err
+	}
+
+	mode, err := parseEndpoints(t.Endpoints)
+	if err != nil {
+		return err
+	}
+	if mode == kenko.EndpointsPerFamily && t.IPVersion != "" && t.IPVersion != "any" {
+		return fmt.Errorf("endpoints: per_family cannot be combined with ip_version %s", t.IPVersion)
+	}
+	if mode == kenko.EndpointsPerIP && len(t.Steps) > 0 {
+		return fmt.Errorf("endpoints: per_ip cannot be used with steps")
+	}
+
+	if err := t.Timeouts.validate(); err != nil {
+		return err
+	}
+
+	if t.Interval < 0 {
+		return fmt.Errorf("interval must not be negative, got %s", t.Interval)
+	}
+	if t.Schedule != "" {
+		if err := kenko.ValidateCron(t.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+		if t.Interval > 0 {
+			return fmt.Errorf("schedule cannot be combined with interval")
+		}
+	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", t.Timeout)
+	}
+
+	for k, m := range t.Maintenance {
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("maintenance[%d]: %w", k, err)
+		}
+	}
+
+	if t.Retries < 0 {
+		return fmt.Errorf("retries must not be negative, got %d", t.Retries)
+	}
+	if t.RetryBackoff < 0 {
+		return fmt.Errorf("retry_backoff must not be negative, got %s", t.RetryBackoff)
+	}
+	for k, on := range t.RetryOn {
+		if !slices.Contains(kenko.RetryConditions, kenko.RetryCondition(on)) {
+			return fmt.Errorf("retry_on[%d] must be one of timeout, 5xx, or connection_refused, got %q", k, on)
+		}
+	}
+	if t.FailThreshold < 0 {
+		return fmt.Errorf("failure_threshold must not be negative, got %d", t.FailThreshold)
+	}
+	if t.PassThreshold < 0 {
+		return fmt.Errorf("success_threshold must not be negative, got %d", t.PassThreshold)
+	}
+	if t.SlowLatency < 0 {
+		return fmt.Errorf("slow_latency must not be negative, got %s", t.SlowLatency)
+	}
+	if t.SlowChecks < 0 {
+		return fmt.Errorf("slow_checks must not be negative, got %d", t.SlowChecks)
+	}
+
+	for k, enc := range t.ExpectEnc {
+		if strings.TrimSpace(enc) == "" {
+			return fmt.Errorf("expect_encoding[%d] must not be empty", k)
+		}
+	}
+
+	if t.Auth != nil {
+		if err := t.Auth.validate(); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	for j, st := range t.Steps {
+		if err := st.validate(); err != nil {
+			return fmt.Errorf("steps[%d]: %w", j, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *step) validate() error {
+	if s.URL == "" {
+		return fmt.Errorf("url must not be empty")
+	}
+	for field, text := range map[string]string{"url": s.URL, "body": s.Body} {
+		if err := kenko.ValidateTemplate(text); err != nil {
+			return fmt.Errorf("invalid %s template: %w", field, err)
+		}
+	}
+	if err := validateHeaderAssertions(s.ExpectHeaders); err != nil {
+		return fmt.Errorf("expect_headers%w", err)
+	}
+	for k, ex := range s.Extract {
+		if ex.Name == "" {
+			return fmt.Errorf("extract[%d]: name must not be empty", k)
+		}
+		if (ex.Header == "") == (ex.JSON == "") {
+			return fmt.Errorf("extract[%d] %q: exactly one of header or json must be set", k, ex.Name)
+		}
+	}
+	return nil
+}
+
+func (a *auth) validate() error {
+	if (len(a.RefreshCommand) == 0) == (a.RefreshURL == "") {
+		return fmt.Errorf("exactly one of refresh_command or refresh_url must be set")
+	}
+	if a.RefreshURL != "" {
+		if err := validateURL(a.RefreshURL); err != nil {
+			return fmt.Errorf("refresh_url: %w", err)
+		}
+	}
+	if a.TokenJSON != "" && a.RefreshURL == "" {
+		return fmt.Errorf("token_json requires refresh_url")
+	}
+	return nil
+}
+
+func (pt phaseTimeouts) validate() error {
+	for field, d := range map[string]time.Duration{
+		"dns":             pt.DNS,
+		"connect":         pt.Connect,
+		"tls_handshake":   pt.TLSHandshake,
+		"response_header": pt.ResponseHeader,
+	} {
+		if d < 0 {
+			return fmt.Errorf("timeouts.%s must not be negative, got %s", field, d)
+		}
+	}
+	return nil
+}
+
+func (m maintenance) validate() error {
+	if err := kenko.ValidateCron(m.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	if m.Duration <= 0 {
+		return fmt.Errorf("duration must be positive, got %s", m.Duration)
+	}
+	return nil
+}
+
+// config builds the tls config for connecting to redis, loading the ca and
+// client certificate files.
+func (rt *redisTLS) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         rt.ServerName,
+		InsecureSkipVerify: rt.InsecureSkipVerify,
+	}
+	if rt.CAFile != "" {
+		pem, err := os.ReadFile(rt.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ca_file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file: no certificates in %s", rt.CAFile)
+		}
+	}
+	if (rt.CertFile == "") != (rt.KeyFile == "") {
+		return nil, fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if rt.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(rt.CertFile, rt.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func (a *archive) validate() error {
+	if a.After <= 0 {
+		return fmt.Errorf("after must be positive, got %s", a.After)
+	}
+	if a.Interval < 0 {
+		return fmt.Errorf("interval must not be negative, got %s", a.Interval)
+	}
+	if a.Endpoint != "" {
+		if err := validateURL(a.Endpoint); err != nil {
+			return fmt.Errorf("endpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *retention) validate() error {
+	for field, d := range map[string]time.Duration{
+		"results":     r.Results,
+		"transitions": r.Transitions,
+		"deliveries":  r.Deliveries,
+		"interval":    r.Interval,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", field, d)
+		}
+	}
+	if r.Results == 0 && r.Transitions == 0 && r.Deliveries == 0 {
+		return fmt.Errorf("set results, transitions, or deliveries")
+	}
+	return nil
+}
+
+func (r *rollups) validate() error {
+	for field, d := range map[string]time.Duration{
+		"interval":    r.Interval,
+		"five_minute": r.FiveMinute,
+		"hourly":      r.Hourly,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", field, d)
+		}
+	}
+	if r.FiveMinute > 0 && r.Hourly > 0 && r.Hourly < r.FiveMinute {
+		return fmt.Errorf("hourly must not be shorter than five_minute %s, got %s", r.FiveMinute, r.Hourly)
+	}
+	return nil
+}
+
+func (rw *remoteWrite) validate() error {
+	if err := validateURL(rw.URL); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	if rw.Interval < 0 {
+		return fmt.Errorf("interval must not be negative, got %s", rw.Interval)
+	}
+	if rw.Password != "" && rw.Username == "" {
+		return fmt.Errorf("password needs a username")
+	}
+	if rw.BearerToken != "" && rw.Username != "" {
+		return fmt.Errorf("set username or bearer_token, not both")
+	}
+	return nil
+}
+
+func (n *notifier) validate() error {
+	kinds := 0
+	if n.Webhook != nil {
+		kinds++
+		if err := validateURL(n.Webhook.URL); err != nil {
+			return fmt.Errorf("webhook.url: %w", err)
+		}
+		if _, err := template.New("body").Parse(n.Webhook.Body); err != nil {
+			return fmt.Errorf("webhook.body: %w", err)
+		}
+	}
+	if n.Slack != nil {
+		kinds++
+		if err := n.Slack.validate(); err != nil {
+			return fmt.Errorf("slack.%w", err)
+		}
+	}
+	if n.Telegram != nil {
+		kinds++
+		if err := n.Telegram.validate(); err != nil {
+			return fmt.Errorf("telegram.%w", err)
+		}
+	}
+	if n.PagerDuty != nil {
+		kinds++
+		if n.PagerDuty.EventsURL != "" {
+			if err := validateURL(n.PagerDuty.EventsURL); err != nil {
+				return fmt.Errorf("pagerduty.events_url: %w", err)
+			}
+		}
+		if _, err := template.New("summary").Parse(n.PagerDuty.Summary); err != nil {
+			return fmt.Errorf("pagerduty.summary: %w", err)
+		}
+	}
+	if n.Email != nil {
+		kinds++
+		if err := n.Email.validate(); err != nil {
+			return fmt.Errorf("email.%w", err)
+		}
+	}
+	if n.Ntfy != nil {
+		kinds++
+		if err := n.Ntfy.validate(); err != nil {
+			return fmt.Errorf("ntfy.%w", err)
+		}
+	}
+	if n.Gotify != nil {
+		kinds++
+		if err := validateURL(n.Gotify.URL); err != nil {
+			return fmt.Errorf("gotify.url: %w", err)
+		}
+	}
+	if n.Pushover != nil {
+		kinds++
+		if n.Pushover.StatusPageURL != "" {
+			if err := validateURL(n.Pushover.StatusPageURL); err != nil {
+				return fmt.Errorf("pushover.status_page_url: %w", err)
+			}
+		}
+	}
+	if n.Alertmanager != nil {
+		kinds++
+		if err := n.Alertmanager.validate(); err != nil {
+			return fmt.Errorf("alertmanager.%w", err)
+		}
+	}
+	if n.Twilio != nil {
+		kinds++
+		for _, number := range append([]string{n.Twilio.From}, n.Twilio.To...) {
+			if !e164.MatchString(number) {
+				return fmt.Errorf("twilio: %q is not an E.164 phone number such as +15551234567", number)
+			}
+		}
+	}
+	if n.Command != nil {
+		kinds++
+		if len(n.Command.Run) == 0 || strings.TrimSpace(n.Command.Run[0]) == "" {
+			return fmt.Errorf("command.run[0]: must name a program")
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("set exactly one of webhook, slack, telegram, pagerduty, email, ntfy, gotify, pushover, alertmanager, twilio, command")
+	}
+	return nil
+}
+
+func (s *slackNotifier) validate() error {
+	switch {
+	case s.WebhookURL != "" && s.Token != "":
+		return fmt.Errorf("webhook_url: set webhook_url or token, not both")
+	case s.WebhookURL != "":
+		if err := validateURL(s.WebhookURL); err != nil {
+			return fmt.Errorf("webhook_url: %w", err)
+		}
+		if s.Channel != "" {
+			return fmt.Errorf("channel: an incoming webhook posts to its own channel, set token to pick one")
+		}
+	case s.Token != "":
+		if s.Channel == "" {
+			return fmt.Errorf("channel: required with token")
+		}
+	default:
+		return fmt.Errorf("webhook_url: set webhook_url or token")
+	}
+	if s.StatusPageURL != "" {
+		if err := validateURL(s.StatusPageURL); err != nil {
+			return fmt.Errorf("status_page_url: %w", err)
+		}
+	}
+	if _, err := template.New("text").Parse(s.Text); err != nil {
+		return fmt.Errorf("text: %w", err)
+	}
+	return nil
+}
+
+func (tg *telegramNotifier) validate() error {
+	if tg.ChatID == "" && len(tg.Routes) == 0 {
+		return fmt.Errorf("chat_id: set chat_id or routes")
+	}
+	for i, r := range tg.Routes {
+		if len(r.Targets) == 0 && len(r.Groups) == 0 && len(r.Labels) == 0 {
+			return fmt.Errorf("routes[%d]: matches every target, set targets, groups, or labels", i)
+		}
+	}
+	if _, err := htmltemplate.New("text").Parse(tg.Text); err != nil {
+		return fmt.Errorf("text: %w", err)
+	}
+	return nil
+}
+
+func (nt *ntfyNotifier) validate() error {
+	if strings.ContainsAny(nt.Topic, "/?#") {
+		return fmt.Errorf("topic: must be a bare topic name, got %q", nt.Topic)
+	}
+	for field, u := range map[string]string{"server": nt.Server, "status_page_url": nt.StatusPageURL} {
+		if u == "" {
+			continue
+		}
+		if err := validateURL(u); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	if nt.Token != "" && nt.Username != "" {
+		return fmt.Errorf("token: set token or username, not both")
+	}
+	if nt.Password != "" && nt.Username == "" {
+		return fmt.Errorf("password: needs a username")
+	}
+	return nil
+}
+
+func (am *alertmanagerNotifier) validate() error {
+	for field, u := range map[string]string{"url": am.URL, "status_page_url": am.StatusPageURL} {
+		if u == "" {
+			continue
+		}
+		if err := validateURL(u); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	if am.TTL < 0 {
+		return fmt.Errorf("ttl: must not be negative, got %s", am.TTL)
+	}
+	if am.Password != "" && am.Username == "" {
+		return fmt.Errorf("password: needs a username")
+	}
+	return nil
+}
+
+func (e *emailNotifier) validate() error {
+	if _, _, err := net.SplitHostPort(e.Addr); err != nil {
+		return fmt.Errorf("addr: must be host:port, got %q", e.Addr)
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	if len(e.To) == 0 && len(e.Routes) == 0 {
+		return fmt.Errorf("to: set to or routes")
+	}
+	if err := validateAddresses(e.To); err != nil {
+		return fmt.Errorf("to: %w", err)
+	}
+	for i, r := range e.Routes {
+		if len(r.To) == 0 {
+			return fmt.Errorf("routes[%d].to: required", i)
+		}
+		if err := validateAddresses(r.To); err != nil {
+			return fmt.Errorf("routes[%d].to: %w", i, err)
+		}
+		if len(r.Targets) == 0 && len(r.Groups) == 0 && len(r.Labels) == 0 {
+			return fmt.Errorf("routes[%d]: matches every target, set targets, groups, or labels", i)
+		}
+	}
+	if e.Password != "" && e.Username == "" {
+		return fmt.Errorf("password: needs a username")
+	}
+	for field, text := range map[string]string{"subject": e.Subject, "body": e.Body} {
+		if _, err := template.New(field).Parse(text); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+func validateAddresses(addrs []string) error {
+	for _, a := range addrs {
+		if _, err := mail.ParseAddress(a); err != nil {
+			return fmt.Errorf("%q: %w", a, err)
+		}
+	}
+	return nil
+}
+
+// build creates the notifier the block configures.
+func (n *notifier) build() kenko.Notifier {
+	switch {
+	case n.Webhook != nil:
+		var opts []webhook.Option
+		for k, v := range n.Webhook.Headers {
+			opts = append(opts, webhook.WithHeader(k, v))
+		}
+		// validate parsed the templates of every kind
+		if n.Webhook.Body != "" {
+			opts = append(opts, webhook.WithBody(template.Must(template.New("body").Parse(n.Webhook.Body))))
+		}
+		if n.Webhook.Secret != "" {
+			opts = append(opts, webhook.WithSecret(n.Webhook.Secret))
+		}
+		return webhook.New(n.Webhook.URL, opts...)
+	case n.Slack != nil:
+		var opts []slack.Option
+		if n.Slack.StatusPageURL != "" {
+			opts = append(opts, slack.WithStatusPageURL(n.Slack.StatusPageURL))
+		}
+		if n.Slack.Text != "" {
+			opts = append(opts, slack.WithText(template.Must(template.New("text").Parse(n.Slack.Text))))
+		}
+		if n.Slack.Token != "" {
+			return slack.NewBot(n.Slack.Token, n.Slack.Channel, opts...)
+		}
+		return slack.New(n.Slack.WebhookURL, opts...)
+	case n.Telegram != nil:
+		var opts []telegram.Option
+		for _, r := range n.Telegram.Routes {
+			opts = append(opts, telegram.WithRoute(telegram.Route(r)))
+		}
+		if n.Telegram.Text != "" {
+			opts = append(opts, telegram.WithText(htmltemplate.Must(htmltemplate.New("text").Parse(n.Telegram.Text))))
+		}
+		return telegram.New(n.Telegram.Token, n.Telegram.ChatID, opts...)
+	case n.PagerDuty != nil:
+		var opts []pagerduty.Option
+		if n.PagerDuty.EventsURL != "" {
+			opts = append(opts, pagerduty.WithEventsURL(n.PagerDuty.EventsURL))
+		}
+		if n.PagerDuty.DedupKeyPrefix != "" {
+			opts = append(opts, pagerduty.WithDedupKeyPrefix(n.PagerDuty.DedupKeyPrefix))
+		}
+		if n.PagerDuty.Summary != "" {
+			opts = append(opts, pagerduty.WithSummary(template.Must(template.New("summary").Parse(n.PagerDuty.Summary))))
+		}
+		return pagerduty.New(n.PagerDuty.RoutingKey, opts...)
+	case n.Email != nil:
+		e := n.Email
+		var opts []email.Option
+		if e.Username != "" {
+			opts = append(opts, email.WithAuth(e.Username, e.Password))
+		}
+		if e.TLS != "" {
+			opts = append(opts, email.WithTLS(email.TLSMode(e.TLS)))
+		}
+		if e.Subject != "" {
+			opts = append(opts, email.WithSubject(template.Must(template.New("subject").Parse(e.Subject))))
+		}
+		if e.Body != "" {
+			opts = append(opts, email.WithBody(template.Must(template.New("body").Parse(e.Body))))
+		}
+		for _, r := range e.Routes {
+			opts = append(opts, email.WithRoute(email.Route(r)))
+		}
+		return email.New(e.Addr, e.From, e.To, opts...)
+	case n.Ntfy != nil:
+		nt := n.Ntfy
+		var opts []ntfy.Option
+		if nt.Server != "" {
+			opts = append(opts, ntfy.WithServer(nt.Server))
+		}
+		if nt.Token != "" {
+			opts = append(opts, ntfy.WithToken(nt.Token))
+		}
+		if nt.Username != "" {
+			opts = append(opts, ntfy.WithBasicAuth(nt.Username, nt.Password))
+		}
+		if nt.StatusPageURL != "" {
+			opts = append(opts, ntfy.WithStatusPageURL(nt.StatusPageURL))
+		}
+		return ntfy.New(nt.Topic, opts...)
+	case n.Gotify != nil:
+		return gotify.New(n.Gotify.URL, n.Gotify.Token)
+	case n.Pushover != nil:
+		var opts []pushover.Option
+		if n.Pushover.Device != "" {
+			opts = append(opts, pushover.WithDevice(n.Pushover.Device))
+		}
+		if n.Pushover.StatusPageURL != "" {
+			opts = append(opts, pushover.WithStatusPageURL(n.Pushover.StatusPageURL))
+		}
+		return pushover.New(n.Pushover.Token, n.Pushover.User, opts...)
+	case n.Alertmanager != nil:
+		am := n.Alertmanager
+		var opts []alertmanager.Option
+		if am.AlertName != "" {
+			opts = append(opts, alertmanager.WithAlertName(am.AlertName))
+		}
+		for k, v := range am.Labels {
+			opts = append(opts, alertmanager.WithLabel(k, v))
+		}
+		if am.TTL > 0 {
+			opts = append(opts, alertmanager.WithTTL(am.TTL))
+		}
+		if am.StatusPageURL != "" {
+			opts = append(opts, alertmanager.WithStatusPageURL(am.StatusPageURL))
+		}
+		if am.Username != "" {
+			opts = append(opts, alertmanager.WithBasicAuth(am.Username, am.Password))
+		}
+		return alertmanager.New(am.URL, opts...)
+	case n.Twilio != nil:
+		tw := n.Twilio
+		var opts []twilio.Option
+		if tw.Voice {
+			opts = append(opts, twilio.WithVoice())
+		}
+		return twilio.New(tw.AccountSID, tw.AuthToken, tw.From, tw.To, opts...)
+	case n.Command != nil:
+		var opts []command.Option
+		for k, v := range n.Command.Env {
+			opts = append(opts, command.WithEnv(k, v))
+		}
+		if n.Command.Dir != "" {
+			opts = append(opts, command.WithDir(n.Command.Dir))
+		}
+		return command.New(n.Command.Run, opts...)
+	}
+	return nil
+}
+
+func (r route) toKenko() kenko.Route {
+	kr := kenko.Route{Targets: r.Targets, Groups: r.Groups, Notifiers: r.Notifiers, Continue: r.Continue}
+	for k, v := range r.Labels {
+		kr.Labels = append(kr.Labels, k+"="+v)
+	}
+	slices.Sort(kr.Labels)
+	for _, s := range r.Severities {
+		kr.Severities = append(kr.Severities, kenko.Severity(s))
+	}
+	for _, s := range r.Statuses {
+		kr.Statuses = append(kr.Statuses, kenko.Status(s))
+	}
+	for _, step := range r.Escalation {
+		kr.Escalation = append(kr.Escalation, kenko.EscalationStep{After: step.After, Notifiers: step.Notifiers})
+	}
+	return kr
+}
+
+func (r *notifyRetry) validate() error {
+	if r.Attempts < 0 {
+		return fmt.Errorf("attempts must not be negative, got %d", r.Attempts)
+	}
+	for field, d := range map[string]time.Duration{
+		"backoff": r.Backoff,
+		"timeout": r.Timeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", field, d)
+		}
+	}
+	return nil
+}
+
+func (b *storeBreaker) validate() error {
+	for field, n := range map[string]int{
+		"failures": b.Failures,
+		"buffer":   b.Buffer,
+	} {
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", field, n)
+		}
+	}
+	for field, d := range map[string]time.Duration{
+		"cooldown": b.Cooldown,
+		"timeout":  b.Timeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", field, d)
+		}
+	}
+	return nil
+}
+
+func (db *influxDB) validate() error {
+	if err := validateURL(db.URL); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	if db.FlushInterval < 0 {
+		return fmt.Errorf("flush_interval must not be negative, got %s", db.FlushInterval)
+	}
+	if db.BatchSize < 0 {
+		return fmt.Errorf("batch_size must not be negative, got %d", db.BatchSize)
+	}
+	if db.Password != "" && db.Username == "" {
+		return fmt.Errorf("password needs a username")
+	}
+	if db.Token != "" && db.Username != "" {
+		return fmt.Errorf("set username or token, not both")
+	}
+	return nil
+}
+
+func (tr *transport) validate() error {
+	for field, n := range map[string]int{
+		"max_idle_conns":          tr.MaxIdleConns,
+		"max_idle_conns_per_host": tr.MaxIdleConnsPerHost,
+		"max_conns_per_host":      tr.MaxConnsPerHost,
+	} {
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", field, n)
+		}
+	}
+	for field, d := range map[string]time.Duration{
+		"idle_conn_timeout":     tr.IdleConnTimeout,
+		"tls_handshake_timeout": tr.TLSHandshakeTimeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", field, d)
+		}
+	}
+	return nil
+}
+
+func parseIPVersion(s string) (kenko.IPVersion, error) {
+	switch s {
+	case "", "any":
+		return kenko.IPAny, nil
+	case "4":
+		return kenko.IPv4, nil
+	case "6":
+		return kenko.IPv6, nil
+	default:
+		return kenko.IPAny, fmt.Errorf("ip_version must be 4, 6, or any, got %q", s)
+	}
+}
+
+func parseEndpoints(s string) (kenko.EndpointMode, error) {
+	switch s {
+	case "", "single":
+		return kenko.EndpointsSingle, nil
+	case "per_family":
+		return kenko.EndpointsPerFamily, nil
+	case "per_ip":
+		return kenko.EndpointsPerIP, nil
+	default:
+		return kenko.EndpointsSingle, fmt.Errorf("endpoints must be single, per_family, or per_ip, got %q", s)
+	}
+}
+
+func parseRevocation(s string) (kenko.RevocationMode, error) {
+	switch s {
+	case "", "off":
+		return kenko.RevocationOff, nil
+	case "ocsp":
+		return kenko.RevocationCheck, nil
+	case "require_staple":
+		return kenko.RevocationRequireStaple, nil
+	default:
+		return kenko.RevocationOff, fmt.Errorf("revocation must be off, ocsp, or require_staple, got %q", s)
+	}
+}
+
+func validateSource(addr, iface string) error {
+	if addr != "" && iface != "" {
+		return fmt.Errorf("source_addr and source_interface are mutually exclusive")
+	}
+	if addr != "" && net.ParseIP(addr) == nil {
+		return fmt.Errorf("source_addr must be an ip address, got %q", addr)
+	}
+	return nil
+}
+
+func validateURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("url must not be empty")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url scheme must be http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url must have a host")
+	}
+	return nil
+}
+
+// validateHeaderAssertions returns an error prefixed with the offending index, e.g. "[2]: ...".
+func validateHeaderAssertions(as []headerAssertion) error {
+	for j, a := range as {
+		if a.Name == "" {
+			return fmt.Errorf("[%d]: name must not be empty", j)
+		}
+		if _, err := regexp.Compile(a.Regex); err != nil {
+			return fmt.Errorf("[%d]: invalid regex: %w", j, err)
+		}
+	}
+	return nil
+}
+
+// configToOptions converts the config into checker options. extra reporters,
+// such as an exporter the caller runs, receive every result alongside the
+// prometheus metrics.
+func configToOptions(cfg *config, extra ...kenko.MetricsReporter) []kenko.Option {
+	opts := make([]kenko.Option, 0, len(cfg.Targets)+4)
+
+	for _, t := range cfg.Targets {
+		opts = append(opts, kenko.WithTarget(t.Name, t.checkURL(), targetOptions(t)...))
+	}
+
+	// targets added through the api are written like config targets
+	opts = append(opts, kenko.WithTargetDecoder(decodeTarget))
+
+	// always set so a config reload can turn revocation checks on
+	opts = append(opts, kenko.WithRevocationChecker(revocation.New()))
+
+	opts = append(opts, kenko.WithContentDecoder("br", func(r io.Reader) (io.Reader, error) {
+		return brotli.NewReader(r), nil
+	}))
+
+	opts = append(opts,
+		kenko.WithInterval(cfg.CheckInterval),
+		kenko.WithTimeout(cfg.CheckTimeout),
+	)
+
+	if cfg.Warmup > 0 {
+		opts = append(opts, kenko.WithWarmup(cfg.Warmup))
+	}
+
+	if cfg.Region != "" {
+		opts = append(opts, kenko.WithRegion(cfg.Region))
+	}
+
+	if cfg.APIToken != "" {
+		opts = append(opts, kenko.WithAPIToken(cfg.APIToken))
+	}
+
+	if cfg.SlugNames {
+		opts = append(opts, kenko.WithNameNormalizer(kenko.SlugName))
+	}
+
+	if cfg.CacheTTL > 0 {
+		opts = append(opts, kenko.WithResultCache(cfg.CacheTTL))
+	}
+
+	if b := cfg.StoreBreaker; b != nil {
+		opts = append(opts, kenko.WithStoreBreaker(kenko.StoreBreaker(*b)))
+	}
+
+	if cfg.SnapshotPath != "" {
+		opts = append(opts, kenko.WithSnapshot(cfg.SnapshotPath))
+	}
+
+	if cfg.WriteQueue > 0 {
+		opts = append(opts, kenko.WithWriteBehind(cfg.WriteQueue))
+		if cfg.WriteBatch > 0 || cfg.WriteDelay > 0 {
+			opts = append(opts, kenko.WithWriteBatch(cfg.WriteBatch, cfg.WriteDelay))
+		}
+	}
+
+	if ua := cfg.HTTPDefaults.UserAgent; ua != "" {
+		opts = append(opts, kenko.WithUserAgent(ua))
+	}
+	if accept := cfg.HTTPDefaults.Accept; accept != "" {
+		opts = append(opts, kenko.WithDefaultHeader("Accept", accept))
+	}
+	for k, v := range cfg.HTTPDefaults.Headers {
+		opts = append(opts, kenko.WithDefaultHeader(k, v))
+	}
+
+	if cfg.Transport != nil {
+		opts = append(opts, kenko.WithTransport(kenko.TransportSettings(*cfg.Transport)))
+	}
+
+	for k, v := range cfg.Vars {
+		opts = append(opts, kenko.WithDefaultVar(k, v))
+	}
+
+	if cfg.SourceAddr != "" {
+		opts = append(opts, kenko.WithDefaultSourceAddr(cfg.SourceAddr))
+	}
+	if cfg.SourceIf != "" {
+		opts = append(opts, kenko.WithDefaultSourceInterface(cfg.SourceIf))
+	}
+
+	for name, g := range cfg.Groups {
+		if g.Quorum != nil {
+			opts = append(opts, kenko.WithGroupQuorum(name, *g.Quorum))
+		}
+		for _, m := range g.Maintenance {
+			opts = append(opts, kenko.WithGroupMaintenance(name, m.Schedule, m.Duration))
+		}
+	}
+
+	if hb := cfg.Heartbeat; hb != nil {
+		sender := kenko.HeartbeatSender{URL: hb.URL, Interval: hb.Interval, Header: make(http.Header, len(hb.Headers))}
+		if hb.Format == "alertmanager" {
+			// a watchdog alert expires, so it needs the interval it is sent at
+			interval := hb.Interval
+			if interval == 0 {
+				interval = cfg.CheckInterval
+			}
+			sender = alertmanager.New(hb.URL).Watchdog(interval)
+		}
+		for k, v := range hb.Headers {
+			sender.Header.Set(k, v)
+		}
+		sender.Instance = hb.Instance
+		opts = append(opts, kenko.WithHeartbeat(sender))
+	}
+
+	if r := cfg.Retention; r != nil {
+		opts = append(opts, kenko.WithRetention(kenko.Retention(*r)))
+	}
+
+	if r := cfg.Rollups; r != nil {
+		opts = append(opts, kenko.WithRollups(kenko.Rollups(*r)))
+	}
+
+	for _, n := range cfg.Notifiers {
+		opts = append(opts, kenko.WithNotifier(n.Name, n.build()))
+	}
+	if r := cfg.NotifyRetry; r != nil {
+		opts = append(opts, kenko.WithNotifyRetry(kenko.NotifyRetry(*r)))
+	}
+	for _, r := range cfg.Routes {
+		opts = append(opts, kenko.WithRoute(r.toKenko()))
+	}
+	if cfg.NotifyRepeat > 0 {
+		opts = append(opts, kenko.WithNotifyRepeat(cfg.NotifyRepeat))
+	}
+	if cfg.GroupWindow > 0 {
+		opts = append(opts, kenko.WithNotifyGroupWindow(cfg.GroupWindow))
+	}
+	if t := cfg.Throttle; t != nil {
+		opts = append(opts, kenko.WithNotifyThrottle(kenko.NotifyThrottle(*t)))
+	}
+
+	if cfg.StatusPage != nil {
+		opts = append(opts, kenko.WithStatusPage(cfg.StatusPage.toKenko()))
+	}
+
+	if cfg.MemoryHistory != nil {
+		// replaced by the external store, if any
+		opts = append(opts, kenko.WithStore(kenko.NewMemoryStore(kenko.WithHistorySize(*cfg.MemoryHistory))))
+	}
+
+	var pmOpts []prommetrics.Option
+	if cfg.TLSMetrics {
+		pmOpts = append(pmOpts, prommetrics.WithTLSMetrics())
+	}
+	if len(cfg.MetricLabels) > 0 {
+		pmOpts = append(pmOpts, prommetrics.WithTargetLabels(cfg.MetricLabels...))
+	}
+	var reporter kenko.MetricsReporter = prommetrics.New(pmOpts...)
+	if len(extra) > 0 {
+		reporter = kenko.MultiReporter(append([]kenko.MetricsReporter{reporter}, extra...)...)
+	}
+	opts = append(opts, kenko.WithMetrics(reporter))
+
+	return opts
+}
+
+// archiverFromConfig builds the s3 archiver of the archive block, or returns
+// nil when there is none. credentials and the region come from the usual aws
+// environment and shared config.
+func archiverFromConfig(ctx context.Context, cfg *config) (*s3archive.Archiver, error) {
+	a := cfg.Archive
+	if a == nil {
+		return nil, nil
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading aws config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if a.Endpoint != "" {
+			o.BaseEndpoint = aws.String(a.Endpoint)
+			// s3-compatible stores rarely support virtual-hosted buckets
+			o.UsePathStyle = true
+		}
+	})
+
+	var opts []s3archive.Option
+	if a.Prefix != "" {
+		opts = append(opts, s3archive.WithPrefix(a.Prefix))
+	}
+	return s3archive.New(client, a.Bucket, opts...), nil
+}
+
+// remoteWriterFromConfig builds the writer of the remote_write block, or
+// returns nil when there is none. it pushes the default registry the
+// prometheus reporter registers with.
+func remoteWriterFromConfig(cfg *config, logger *slog.Logger) *remotewrite.Writer {
+	rw := cfg.RemoteWrite
+	if rw == nil {
+		return nil
+	}
+	opts := []remotewrite.Option{remotewrite.WithLogger(logger)}
+	if rw.Interval > 0 {
+		opts = append(opts, remotewrite.WithInterval(rw.Interval))
+	}
+	switch {
+	case rw.BearerToken != "":
+		opts = append(opts, remotewrite.WithBearerToken(rw.BearerToken))
+	case rw.Username != "":
+		opts = append(opts, remotewrite.WithBasicAuth(rw.Username, rw.Password))
+	}
+	for k, v := range rw.Headers {
+		opts = append(opts, remotewrite.WithHeader(k, v))
+	}
+	if len(rw.Labels) > 0 {
+		opts = append(opts, remotewrite.WithLabels(rw.Labels))
+	}
+	return remotewrite.New(rw.URL, opts...)
+}
+
+// influxFromConfig builds the exporter of the influxdb block, or returns nil
+// when there is none. the metric_labels keys become tags.
+func influxFromConfig(cfg *config, logger *slog.Logger) *influxdb.Exporter {
+	db := cfg.InfluxDB
+	if db == nil {
+		return nil
+	}
+	opts := []influxdb.Option{influxdb.WithLogger(logger)}
+	switch {
+	case db.Token != "":
+		opts = append(opts, influxdb.WithToken(db.Token))
+	case db.Username != "":
+		opts = append(opts, influxdb.WithBasicAuth(db.Username, db.Password))
+	}
+	if db.Measurement != "" {
+		opts = append(opts, influxdb.WithMeasurement(db.Measurement))
+	}
+	if len(db.Tags) > 0 {
+		opts = append(opts, influxdb.WithTags(db.Tags))
+	}
+	if len(cfg.MetricLabels) > 0 {
+		opts = append(opts, influxdb.WithLabelTags(cfg.MetricLabels...))
+	}
+	if db.FlushInterval > 0 {
+		opts = append(opts, influxdb.WithFlushInterval(db.FlushInterval))
+	}
+	if db.BatchSize > 0 {
+		opts = append(opts, influxdb.WithBatchSize(db.BatchSize))
+	}
+	return influxdb.New(db.URL, opts...)
+}
+
+// enricherFromConfig opens the configured geoip databases, or returns nil when
+// none are set. the caller closes the reader.
+func enricherFromConfig(cfg *config) (*geoip.Reader, error) {
+	if len(cfg.GeoIPDBs) == 0 {
+		return nil, nil
+	}
+	return geoip.Open(cfg.GeoIPDBs...)
+}
+
+// storeFromConfig returns the configured external store, or nil to use the
+// default in-memory store.
+func storeFromConfig(cfg *config) (kenko.Store, error) {
+	if cfg.BoltPath != "" {
+		var bsOpts []boltstore.Option
+		if cfg.BoltHistory != nil {
+			bsOpts = append(bsOpts, boltstore.WithHistory(*cfg.BoltHistory))
+		}
+		return boltstore.New(cfg.BoltPath, bsOpts...), nil
+	}
+	if cfg.PostgresURL != "" {
+		store, err := pgstore.New(cfg.PostgresURL)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	if cfg.RedisAddr == "" {
+		return nil, nil
+	}
+
+	var rsOpts []redisstore.Option
+	if cfg.RedisUsername != "" {
+		rsOpts = append(rsOpts, redisstore.WithUsername(cfg.RedisUsername))
+	}
+	if cfg.RedisPassword != "" {
+		rsOpts = append(rsOpts, redisstore.WithPassword(cfg.RedisPassword))
+	}
+	if cfg.RedisDB != 0 {
+		rsOpts = append(rsOpts, redisstore.WithDB(cfg.RedisDB))
+	}
+	if cfg.RedisTLS != nil {
+		// the files may have changed since the config was validated, and
+		// falling back to plaintext would send the password in the clear
+		tc, err := cfg.RedisTLS.config()
+		if err != nil {
+			return nil, fmt.Errorf("redis_tls: %w", err)
+		}
+		rsOpts = append(rsOpts, redisstore.WithTLS(tc))
+	}
+	if cfg.RedisPrefix != "" {
+		rsOpts = append(rsOpts, redisstore.WithNamespace(cfg.RedisPrefix))
+	}
+	if c, ok := redisstore.Codecs()[cfg.RedisCodec]; ok {
+		rsOpts = append(rsOpts, redisstore.WithCodec(c))
+	}
+	if cfg.RedisHistory != nil || cfg.HistoryMaxAge > 0 {
+		size := redisstore.DefaultHistoryLen
+		if cfg.RedisHistory != nil {
+			size = *cfg.RedisHistory
+		}
+		rsOpts = append(rsOpts, redisstore.WithHistory(size, cfg.HistoryMaxAge))
+	}
+	return redisstore.New(cfg.RedisAddr, rsOpts...), nil
+}
+
+// configTargets builds the checker targets from the config, as used when
+// applying a reloaded config.
+func configTargets(cfg *config) []kenko.Target {
+	targets := make([]kenko.Target, 0, len(cfg.Targets))
+	for _, t := range cfg.Targets {
+		targets = append(targets, kenko.NewTarget(t.Name, t.checkURL(), targetOptions(t)...))
+	}
+	return targets
+}
+
+// checkURL is the target's url, or that of its first step when only steps are set.
+func (t target) checkURL() string {
+	if t.URL == "" && len(t.Steps) > 0 {
+		return t.Steps[0].URL
+	}
+	return t.URL
+}
+
+func targetOptions(t target) []kenko.TargetOption {
+	var opts []kenko.TargetOption
+
+	if t.Method != "" {
+		opts = append(opts, kenko.WithMethod(strings.ToUpper(t.Method)))
+	}
+	if len(t.ExpectStatus) > 0 {
+		opts = append(opts, kenko.WithExpectStatus(t.ExpectStatus...))
+	}
+	if t.MaxBodyBytes > 0 {
+		opts = append(opts, kenko.WithMaxBodyBytes(t.MaxBodyBytes))
+	}
+	if t.FailureBody != 0 {
+		opts = append(opts, kenko.WithFailureBody(t.FailureBody))
+	}
+	if len(t.ExpectEnc) > 0 {
+		opts = append(opts, kenko.WithExpectEncoding(t.ExpectEnc...))
+	}
+
+	for k, v := range t.Headers {
+		opts = append(opts, kenko.WithHeader(k, v))
+	}
+
+	for k, v := range t.Vars {
+		opts = append(opts, kenko.WithVar(k, v))
+	}
+
+	for _, a := range t.ExpectHeaders {
+		opts = append(opts, kenko.WithHeaderAssertion(a.toKenko()))
+	}
+
+	if t.ContentHash != nil {
+		opts = append(opts, kenko.WithContentHash(t.ContentHash.Baseline))
+	}
+
+	if t.KeepCookies {
+		opts = append(opts, kenko.WithKeepCookies())
+	}
+
+	if v, _ := parseIPVersion(t.IPVersion); v != kenko.IPAny {
+		opts = append(opts, kenko.WithIPVersion(v))
+	}
+
+	if t.SourceAddr != "" {
+		opts = append(opts, kenko.WithSourceAddr(t.SourceAddr))
+	}
+	if t.SourceIf != "" {
+		opts = append(opts, kenko.WithSourceInterface(t.SourceIf))
+	}
+
+	if t.SampleEvery > 1 {
+		opts = append(opts, kenko.WithSampling(t.SampleEvery))
+	}
+
+	if mode, _ := parseRevocation(t.Revocation); mode != kenko.RevocationOff {
+		opts = append(opts, kenko.WithRevocation(mode))
+	}
+
+	if mode, _ := parseEndpoints(t.Endpoints); mode != kenko.EndpointsSingle {
+		opts = append(opts, kenko.WithEndpoints(mode))
+	}
+
+	if t.Timeouts != (phaseTimeouts{}) {
+		opts = append(opts, kenko.WithPhaseTimeouts(kenko.PhaseTimeouts(t.Timeouts)))
+	}
+
+	if t.Interval > 0 {
+		opts = append(opts, kenko.WithCheckInterval(t.Interval))
+	}
+	if t.Schedule != "" {
+		opts = append(opts, kenko.WithSchedule(t.Schedule))
+	}
+	if t.Timeout > 0 {
+		opts = append(opts, kenko.WithCheckTimeout(t.Timeout))
+	}
+
+	if t.Retries > 0 {
+		on := make([]kenko.RetryCondition, 0, len(t.RetryOn))
+		for _, c := range t.RetryOn {
+			on = append(on, kenko.RetryCondition(c))
+		}
+		opts = append(opts, kenko.WithRetries(t.Retries, t.RetryBackoff, on...))
+	}
+	if t.FailThreshold > 1 || t.PassThreshold > 1 {
+		opts = append(opts, kenko.WithThresholds(t.FailThreshold, t.PassThreshold))
+	}
+	if t.SlowLatency > 0 {
+		opts = append(opts, kenko.WithSlowThreshold(t.SlowLatency, t.SlowChecks))
+	}
+
+	if t.Auth != nil {
+		opts = append(opts, kenko.WithCredentialRefresh(t.Auth.toKenko()))
+	}
+
+	if len(t.Tags) > 0 {
+		opts = append(opts, kenko.WithTags(t.Tags...))
+	}
+	if len(t.Labels) > 0 {
+		opts = append(opts, kenko.WithLabels(t.Labels))
+	}
+	if t.Enabled != nil && !*t.Enabled {
+		opts = append(opts, kenko.WithPaused())
+	}
+	for _, m := range t.Maintenance {
+		opts = append(opts, kenko.WithMaintenance(m.Schedule, m.Duration))
+	}
+	if t.Group != "" {
+		opts = append(opts, kenko.WithGroup(t.Group))
+	}
+	if t.Severity != "" {
+		opts = append(opts, kenko.WithSeverity(kenko.Severity(t.Severity)))
+	}
+	if t.Heartbeat > 0 {
+		opts = append(opts, kenko.WithHeartbeatTimeout(t.Heartbeat))
+	}
+
+	if len(t.Steps) > 0 {
+		steps := make([]kenko.Step, 0, len(t.Steps))
+		for _, st := range t.Steps {
+			steps = append(steps, st.toKenko())
+		}
+		opts = append(opts, kenko.WithSteps(steps...))
+	}
+
+	return opts
+}
+
+func (sp *statusPage) toKenko() kenko.StatusPage {
+	out := kenko.StatusPage{
+		Title:       sp.Title,
+		Description: sp.Description,
+		Statuses:    statusLabels(sp.Statuses),
+	}
+	for _, comp := range sp.Components {
+		out.Components = append(out.Components, kenko.Component(comp))
+	}
+	if len(sp.Locales) > 0 {
+		out.Locales = make(map[string]kenko.StatusPageLocale, len(sp.Locales))
+	}
+	for tag, loc := range sp.Locales {
+		kl := kenko.StatusPageLocale{
+			Title:       loc.Title,
+			Description: loc.Description,
+			Statuses:    statusLabels(loc.Statuses),
+		}
+		if len(loc.Components) > 0 {
+			kl.Components = make(map[string]kenko.ComponentText, len(loc.Components))
+			for name, text := range loc.Components {
+				kl.Components[name] = kenko.ComponentText(text)
+			}
+		}
+		out.Locales[tag] = kl
+	}
+	return out
+}
+
+func statusLabels(labels map[string]string) map[kenko.Status]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make(map[kenko.Status]string, len(labels))
+	for status, label := range labels {
+		out[kenko.Status(status)] = label
+	}
+	return out
+}
+
+func (a *auth) toKenko() kenko.Credential {
+	cred := kenko.Credential{Header: a.Header, Prefix: a.Prefix}
+	if len(a.RefreshCommand) > 0 {
+		cred.Refresh = kenko.CommandRefresher(a.RefreshCommand...)
+		return cred
+	}
+
+	method := strings.ToUpper(a.RefreshMethod)
+	if method == "" {
+		method = http.MethodPost
+	}
+	header := make(http.Header, len(a.RefreshHeaders))
+	for k, v := range a.RefreshHeaders {
+		header.Set(k, v)
+	}
+	cred.Refresh = kenko.HTTPRefresher(nil, method, a.RefreshURL, header, a.TokenJSON)
+	return cred
+}
+
+func (a headerAssertion) toKenko() kenko.HeaderAssertion {
+	ha := kenko.HeaderAssertion{Name: a.Name, Value: a.Value}
+	if a.Regex != "" {
+		ha.Pattern = regexp.MustCompile(a.Regex) // validated in loadConfig
+	}
+	return ha
+}
+
+func (s step) toKenko() kenko.Step {
+	ks := kenko.Step{
+		Name:         s.Name,
+		Method:       s.Method,
+		URL:          s.URL,
+		Body:         s.Body,
+		ExpectStatus: s.ExpectStatus,
+	}
+	if len(s.Headers) > 0 {
+		ks.Header = make(http.Header, len(s.Headers))
+		for k, v := range s.Headers {
+			ks.Header.Set(k, v)
+		}
+	}
+	for _, a := range s.ExpectHeaders {
+		ks.HeaderAssertions = append(ks.HeaderAssertions, a.toKenko())
+	}
+	for _, ex := range s.Extract {
+		ks.Extract = append(ks.Extract, kenko.Extraction{Name: ex.Name, Header: ex.Header, JSON: ex.JSON})
+	}
+	return ks
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"maps"
+	"reflect"
+)
+
+// defaultsKey names the config block whose fields every target inherits.
+const defaultsKey = "defaults"
+
+// defaultsSchema is the schema of the defaults block: any target field except
+// the ones that identify a target.
+func defaultsSchema() *schema {
+	s := schemaFor(reflect.TypeFor[target]())
+	delete(s.Properties, "name")
+	delete(s.Properties, "url")
+	s.Required = nil
+	s.Description = "fields inherited by every target unless the target sets them"
+	return s
+}
+
+// applyDefaults merges the defaults block of a decoded config document into
+// each target and removes it. a field the target sets wins, except that maps
+// such as headers, vars and timeouts are merged key by key, so a target can
+// add or override a single header. lists replace the default. the document
+// must already have passed schema validation.
+func applyDefaults(doc any) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return
+	}
+	defaults, ok := root[defaultsKey].(map[string]any)
+	delete(root, defaultsKey)
+	if !ok {
+		return
+	}
+
+	targets, _ := root["targets"].([]any)
+	for _, t := range targets {
+		if fields, ok := t.(map[string]any); ok {
+			inherit(fields, defaults)
+		}
+	}
+}
+
+// inherit sets each field of defaults that dst lacks, recursing into maps
+// both set. defaults is not modified.
+func inherit(dst, defaults map[string]any) {
+	for k, def := range defaults {
+		cur, set := dst[k]
+		if !set || cur == nil {
+			dst[k] = clone(def)
+			continue
+		}
+		if curMap, ok := cur.(map[string]any); ok {
+			if defMap, ok := def.(map[string]any); ok {
+				inherit(curMap, defMap)
+			}
+		}
+	}
+}
+
+// clone deep-copies maps and lists so targets never share them.
+func clone(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := maps.Clone(v)
+		for k, item := range out {
+			out[k] = clone(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = clone(item)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	path := writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+defaults:
+  method: head
+  expect_status: [200, 204]
+  timeout: 2s
+  headers:
+    Authorization: Bearer shared
+    X-Env: prod
+  timeouts:
+    dns: 1s
+targets:
+  - name: api
+    url: https://api.example.com
+  - name: web
+    url: https://web.example.com
+    method: get
+    expect_status: [301]
+    headers:
+      X-Env: staging
+    timeouts:
+      connect: 2s
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	api, web := cfg.Targets[0], cfg.Targets[1]
+	if api.Method != "head" || api.Timeout != 2*time.Second || len(api.ExpectStatus) != 2 {
+		t.Errorf("api = %+v, want defaults inherited", api)
+	}
+	if api.Headers["Authorization"] != "Bearer shared" || api.Timeouts.DNS != time.Second {
+		t.Errorf("api headers = %v, timeouts = %+v", api.Headers, api.Timeouts)
+	}
+
+	if web.Method != "get" || len(web.ExpectStatus) != 1 || web.ExpectStatus[0] != 301 {
+		t.Errorf("web = %+v, want its own method and expect_status", web)
+	}
+	if web.Headers["X-Env"] != "staging" || web.Headers["Authorization"] != "Bearer shared" {
+		t.Errorf("web headers = %v, want merged with target winning", web.Headers)
+	}
+	if web.Timeouts.DNS != time.Second || web.Timeouts.Connect != 2*time.Second {
+		t.Errorf("web timeouts = %+v, want merged", web.Timeouts)
+	}
+
+	// targets must not share the default maps
+	api.Headers["X-Env"] = "changed"
+	if web.Headers["Authorization"] != "Bearer shared" || cfg.Targets[0].Headers["X-Env"] != "changed" {
+		t.Error("unexpected header sharing")
+	}
+}
+
+func TestLoadConfig_DefaultsInvalid(t *testing.T) {
+	tests := map[string]struct {
+		defaults string
+		wantErr  string
+	}{
+		"name":          {"name: shared", "defaults.name: unknown field"},
+		"type":          {"method: [GET]", "defaults.method: must be string"},
+		"inherited bad": {"expect_status: [42]", "expect_status[0] must be between 100 and 599"},
+	}
+
+	for name, tt := range tests {
+		path := writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+defaults:
+  `+tt.defaults+`
+targets:
+  - name: api
+    url: https://api.example.com
+`)
+		if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	kenko "github.com/aidantrabs/kenko"
+)
+
+// doctorCheck is one line of the doctor report. a nil err passes and a
+// non-empty skip marks a check that could not be run.
+type doctorCheck struct {
+	name string
+	err  error
+	skip string
+}
+
+func doctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	configPath := fs.String("config", defaultConfigPath, "path to config file, or an http(s)://, s3://, or etcd:// location")
+	configFmt := fs.String("config-format", "", "config file format: yaml, json, or toml (default from the file extension)")
+	flags := registerOverrides(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if failed := runDoctor(ctx, *configPath, *configFmt, flags, os.Stdout); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// runDoctor verifies the runtime environment for the given config, with
+// overrides applied, and writes a pass/fail report to w, returning the number
+// of failed checks.
+func runDoctor(ctx context.Context, path, format string, flags overrides, w io.Writer) int {
+	cfg, err := resolveConfig(path, format, true, nil, flags)
+	checks := []doctorCheck{{name: "config " + redactLocation(path), err: err}}
+	if err == nil {
+		checks = append(checks, doctorStore(ctx, cfg))
+		checks = append(checks, doctorDNS(ctx, cfg)...)
+		checks = append(checks, doctorPort(cfg))
+	}
+
+	failed := 0
+	for _, c := range checks {
+		switch {
+		case c.skip != "":
+			fmt.Fprintf(w, "SKIP  %s: %s\n", c.name, c.skip)
+		case c.err != nil:
+			failed++
+			fmt.Fprintf(w, "FAIL  %s: %v\n", c.name, c.err)
+		default:
+			fmt.Fprintf(w, "PASS  %s\n", c.name)
+		}
+	}
+	fmt.Fprintf(w, "\n%d checks, %d failed\n", len(checks), failed)
+
+	return failed
+}
+
+func doctorStore(ctx context.Context, cfg *config) doctorCheck {
+	store, err := storeFromConfig(cfg)
+	if err != nil {
+		return doctorCheck{name: "store", err: err}
+	}
+	if store == nil {
+		return doctorCheck{name: "store", skip: "using in-memory store"}
+	}
+
+	hc, ok := store.(kenko.HealthChecker)
+	if !ok {
+		return doctorCheck{name: "store", skip: "store does not support health checks"}
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	name := "store " + cfg.RedisAddr
+	switch {
+	case cfg.PostgresURL != "":
+		name = "store postgres"
+	case cfg.BoltPath != "":
+		name = "store " + cfg.BoltPath
+	}
+	return doctorCheck{name: name, err: hc.Ping(pingCtx)}
+}
+
+// doctorDNS resolves the host of every target and step URL. templated URLs
+// are skipped since their host is only known at check time.
+func doctorDNS(ctx context.Context, cfg *config) []doctorCheck {
+	var checks []doctorCheck
+	seen := make(map[string]bool)
+
+	resolve := func(name, raw string) {
+		check := doctorCheck{name: "dns " + name}
+		if strings.Contains(raw, "{{") {
+			check.skip = "templated url"
+			checks = append(checks, check)
+			return
+		}
+
+		u, err := url.Parse(raw)
+		if err != nil {
+			check.err = err
+			checks = append(checks, check)
+			return
+		}
+		host := u.Hostname()
+		if seen[host] {
+			return
+		}
+		seen[host] = true
+
+		check.name += " (" + host + ")"
+		if net.ParseIP(host) == nil {
+			_, check.err = net.DefaultResolver.LookupHost(ctx, host)
+		}
+		checks = append(checks, check)
+	}
+
+	for _, t := range cfg.Targets {
+		if t.URL != "" {
+			resolve(t.Name, t.URL)
+		}
+		for i, st := range t.Steps {
+			resolve(fmt.Sprintf("%s step %d", t.Name, i+1), st.URL)
+		}
+	}
+	return checks
+}
+
+func doctorPort(cfg *config) doctorCheck {
+	addr := fmt.Sprintf(":%d", cfg.Port)
+	check := doctorCheck{name: "listen " + addr}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		check.err = err
+		return check
+	}
+	_ = ln.Close()
+	return check
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestRunDoctor_Pass(t *testing.T) {
+	path := writeConfig(t, `
+port: `+strconv.Itoa(freePort(t))+`
+check_interval: 10s
+check_timeout: 3s
+targets:
+  - name: local
+    url: http://127.0.0.1:9999
+  - name: templated
+    url: "http://{{.Host}}/health"
+`)
+
+	var out bytes.Buffer
+	failed := runDoctor(context.Background(), path, "", nil, &out)
+
+	if failed != 0 {
+		t.Fatalf("failed = %d, want 0\n%s", failed, out.String())
+	}
+	for _, want := range []string{"PASS  config", "SKIP  store", "PASS  dns local", "SKIP  dns templated"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunDoctor_InvalidConfig(t *testing.T) {
+	path := writeConfig(t, `port: 0`)
+
+	var out bytes.Buffer
+	if failed := runDoctor(context.Background(), path, "", nil, &out); failed != 1 {
+		t.Errorf("failed = %d, want 1\n%s", failed, out.String())
+	}
+}
+
+func TestRunDoctor_PortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	path := writeConfig(t, `
+port: `+strconv.Itoa(port)+`
+check_interval: 10s
+check_timeout: 3s
+targets:
+  - name: local
+    url: http://127.0.0.1:9999
+`)
+
+	var out bytes.Buffer
+	runDoctor(context.Background(), path, "", nil, &out)
+	if !strings.Contains(out.String(), "FAIL  listen") {
+		t.Errorf("expected listen failure:\n%s", out.String())
+	}
+}
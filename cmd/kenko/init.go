@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// starter holds the settings of a generated config.
+type starter struct {
+	Port      int
+	Interval  time.Duration
+	Timeout   time.Duration
+	RedisAddr string
+	Targets   []target
+}
+
+var starterTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote":    quoteYAML,
+	"duration": shortDuration,
+}).Parse(`# kenko config generated by kenko init. run kenko doctor -config <this file>
+# to check it before deploying, and kenko schema to list every setting.
+port: {{.Port}}
+check_interval: {{duration .Interval}}
+check_timeout: {{duration .Timeout}}
+# spread the first checks over this long after start-up
+warmup: 10s
+{{if .RedisAddr}}
+redis_addr: {{quote .RedisAddr}}
+redis_password: ${REDIS_PASSWORD}
+{{- else}}
+# results are kept in memory. set redis_addr to persist them and share them
+# between instances.
+# redis_addr: localhost:6379
+# redis_password: ${REDIS_PASSWORD}
+{{- end}}
+
+http_defaults:
+  user_agent: kenko
+
+# nothing is sent on a status change until a notifier is set. uncomment one,
+# and the routes if different targets go to different notifiers; kenko
+# schema lists the other notifier kinds.
+# notifiers:
+#   - name: oncall
+#     slack:
+#       webhook_url: ${SLACK_WEBHOOK_URL}
+#   - name: hooks
+#     webhook:
+#       url: https://hooks.example.com/kenko
+#       secret: ${WEBHOOK_SECRET}
+# routes:
+#   - severities: [critical]
+#     notifiers: [oncall]
+#     continue: true
+#   - notifiers: [hooks]
+
+targets:
+{{- range .Targets}}
+  - name: {{quote .Name}}
+    url: {{quote .URL}}
+{{- end}}
+  # a target with more of its settings:
+  # - name: payments
+  #   url: https://payments.example.com/health
+  #   expect_status: [200]
+  #   labels: {team: payments, env: prod}
+  #   retries: 2
+  #   retry_backoff: 500ms
+  #   maintenance:
+  #     - schedule: "0 2 * * *"
+  #       duration: 30m
+`))
+
+func initConfig(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: kenko init [flags] [path]\n\nwrites a starter config to path (default %s)\n\n", defaultConfigPath)
+		fs.PrintDefaults()
+	}
+	s := starter{}
+	fs.IntVar(&s.Port, "port", 6969, "port to serve the api and metrics on")
+	fs.DurationVar(&s.Interval, "interval", 30*time.Second, "how often to check each target")
+	fs.DurationVar(&s.Timeout, "timeout", 5*time.Second, "http timeout of each check")
+	fs.StringVar(&s.RedisAddr, "redis-addr", "", "redis address to store results in (default in memory)")
+	var targets targetFlags
+	fs.Var(&targets, "target", "target as name=<name>,url=<url> (repeatable)")
+	force := fs.Bool("force", false, "overwrite an existing file")
+	yes := fs.Bool("yes", false, "don't prompt, use the flags and defaults")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one path, got %d", fs.NArg())
+	}
+	path := defaultConfigPath
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+	s.Targets = targets
+
+	if !*yes && isTerminal(os.Stdin) {
+		if err := s.prompt(os.Stdin, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	if err := writeStarter(path, s, *force); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", path)
+	return nil
+}
+
+// writeStarter renders the starter config to path, refusing to replace an
+// existing file unless force is set. the result is loaded back before it is
+// written so a bad flag value never produces a config kenko rejects.
+func writeStarter(path string, s starter, force bool) error {
+	if len(s.Targets) == 0 {
+		s.Targets = []target{{Name: "example", URL: "https://example.com"}}
+	}
+
+	var buf strings.Builder
+	if err := starterTemplate.Execute(&buf, s); err != nil {
+		return err
+	}
+
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0o644); err != nil {
+		return err
+	}
+	if _, err := loadConfigAs(tmp, "yaml"); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("generated config is invalid: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// prompt asks for each setting on out, keeping the current value when the
+// answer is empty. targets are asked for one at a time until a blank name.
+func (s *starter) prompt(in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	ask := func(question, def string) string {
+		if def != "" {
+			fmt.Fprintf(out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(out, "%s: ", question)
+		}
+		if !sc.Scan() {
+			return def
+		}
+		if answer := strings.TrimSpace(sc.Text()); answer != "" {
+			return answer
+		}
+		return def
+	}
+
+	port, err := strconv.Atoi(ask("port", strconv.Itoa(s.Port)))
+	if err != nil {
+		return fmt.Errorf("port: %w", err)
+	}
+	s.Port = port
+	if s.Interval, err = time.ParseDuration(ask("check interval", shortDuration(s.Interval))); err != nil {
+		return fmt.Errorf("check interval: %w", err)
+	}
+	if s.Timeout, err = time.ParseDuration(ask("check timeout", shortDuration(s.Timeout))); err != nil {
+		return fmt.Errorf("check timeout: %w", err)
+	}
+	s.RedisAddr = ask("redis address (blank keeps results in memory)", s.RedisAddr)
+
+	if len(s.Targets) == 0 {
+		fmt.Fprintln(out, "targets to check, a blank name to finish:")
+		for {
+			name := ask("  name", "")
+			if name == "" {
+				break
+			}
+			s.Targets = append(s.Targets, target{Name: name, URL: ask("  url", "")})
+		}
+	}
+	return sc.Err()
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe
+// or file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// quoteYAML quotes s as a yaml double-quoted scalar, which json strings are.
+func quoteYAML(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// shortDuration formats d without trailing zero units, e.g. 1m rather than
+// 1m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
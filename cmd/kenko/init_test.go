@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteStarter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configs", "kenko.yaml")
+	s := starter{
+		Port:      8080,
+		Interval:  time.Minute,
+		Timeout:   3 * time.Second,
+		RedisAddr: "redis:6379",
+		Targets:   []target{{Name: "api", URL: "https://api.example.com/health?a=1,b=2"}},
+	}
+	if err := writeStarter(path, s, false); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("generated config does not load: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.CheckInterval != time.Minute || cfg.RedisAddr != "redis:6379" {
+		t.Errorf("config = %+v", cfg)
+	}
+	if len(cfg.Targets) != 1 || cfg.Targets[0].URL != "https://api.example.com/health?a=1,b=2" {
+		t.Errorf("targets = %+v", cfg.Targets)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "check_interval: 1m\n") {
+		t.Errorf("interval not written as 1m:\n%s", data)
+	}
+
+	if err := writeStarter(path, s, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("overwrite err = %v, want already exists", err)
+	}
+	if err := writeStarter(path, starter{Port: 6969, Interval: time.Second, Timeout: time.Second}, true); err != nil {
+		t.Fatalf("forced overwrite: %v", err)
+	}
+	if cfg, err := loadConfig(path); err != nil || cfg.Targets[0].Name != "example" || cfg.RedisAddr != "" {
+		t.Errorf("default starter = %+v, %v", cfg, err)
+	}
+}
+
+func TestWriteStarter_NotifierStubs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kenko.yaml")
+	if err := writeStarter(path, starter{Port: 6969, Interval: time.Second, Timeout: time.Second}, false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+
+	// uncomment the block from "# notifiers:" to the blank line after it.
+	lines := strings.Split(string(data), "\n")
+	stub := false
+	for i, line := range lines {
+		if line == "# notifiers:" {
+			stub = true
+		}
+		if stub && line == "" {
+			break
+		}
+		if stub {
+			lines[i] = strings.TrimPrefix(line, "# ")
+		}
+	}
+	if !stub {
+		t.Fatalf("no notifier stubs in:\n%s", data)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T0/B0/x")
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("uncommented stubs do not load: %v", err)
+	}
+	if len(cfg.Notifiers) != 2 || len(cfg.Routes) != 2 {
+		t.Errorf("notifiers = %+v, routes = %+v", cfg.Notifiers, cfg.Routes)
+	}
+}
+
+func TestWriteStarter_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kenko.yaml")
+	err := writeStarter(path, starter{Port: 0, Interval: time.Second, Timeout: time.Second}, false)
+	if err == nil || !strings.Contains(err.Error(), "generated config is invalid") {
+		t.Errorf("err = %v, want invalid config", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("invalid config was written")
+	}
+}
+
+func TestStarter_Prompt(t *testing.T) {
+	s := starter{Port: 6969, Interval: 30 * time.Second, Timeout: 5 * time.Second}
+	in := strings.NewReader("\n10s\n\nlocalhost:6379\napi\nhttps://api.example.com\n\n")
+	var out bytes.Buffer
+	if err := s.prompt(in, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Port != 6969 || s.Interval != 10*time.Second || s.Timeout != 5*time.Second || s.RedisAddr != "localhost:6379" {
+		t.Errorf("starter = %+v", s)
+	}
+	if len(s.Targets) != 1 || s.Targets[0].Name != "api" || s.Targets[0].URL != "https://api.example.com" {
+		t.Errorf("targets = %+v", s.Targets)
+	}
+	if !strings.Contains(out.String(), "port [6969]: ") {
+		t.Errorf("prompts = %q", out.String())
+	}
+}
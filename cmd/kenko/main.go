@@ -1,76 +1,46 @@
 package main
 
 import (
-	"context"
-	"flag"
 	"fmt"
-	"log/slog"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	kenko "github.com/aidantrabs/kenko"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strings"
 )
 
-func main() {
-	configPath := flag.String("config", "configs/config.yaml", "path to config file")
-	flag.Parse()
+const usage = `usage: kenko <command> [flags]
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+commands:
+  serve   run the monitor (default)
+  doctor  verify config, store, dns, and listener before deploying
+  init    write a starter config file
+  schema  print the json schema of the config file
+`
 
-	cfg, err := loadConfig(*configPath)
-	if err != nil {
-		logger.Error("failed to load config", "error", err)
-		os.Exit(1)
-	}
-
-	opts := configToOptions(cfg)
-	opts = append(opts, kenko.WithLogger(logger))
+func main() {
+	args := os.Args[1:]
 
-	k, err := kenko.New(opts...)
-	if err != nil {
-		logger.Error("failed to create kenko", "error", err)
-		os.Exit(1)
+	// a bare flag list (e.g. "kenko -config x") is shorthand for serve
+	cmd := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	go k.Run(ctx)
-
-	mux := http.NewServeMux()
-	k.RegisterHandlers(mux)
-	mux.Handle("/metrics", promhttp.Handler())
-
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  120 * time.Second,
+	var err error
+	switch cmd {
+	case "serve":
+		err = serve(args)
+	case "doctor":
+		err = doctor(args)
+	case "init":
+		err = initConfig(args)
+	case "schema":
+		err = writeSchema(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
 	}
 
-	go func() {
-		logger.Info("server starting", "addr", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("server error", "error", err)
-			os.Exit(1)
-		}
-	}()
-
-	<-ctx.Done()
-	logger.Info("shutdown signal received")
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		logger.Error("server shutdown error", "error", err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kenko %s: %v\n", cmd, err)
 		os.Exit(1)
 	}
-
-	logger.Info("server stopped gracefully")
 }
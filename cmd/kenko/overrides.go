@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix is prepended to a setting's upper-cased key to form its
+// environment variable, e.g. KENKO_REDIS_ADDR for redis_addr.
+const envPrefix = "KENKO_"
+
+// setting is a top-level config value that can be overridden from the
+// environment or a flag without editing the config file.
+type setting struct {
+	key   string
+	usage string
+	set   func(c *config, value string) error
+	// boolean settings may be given as a bare flag, e.g. --tls-metrics.
+	boolean bool
+	// secret settings may also be read from the file named by the
+	// environment variable with a _FILE suffix, e.g. KENKO_REDIS_PASSWORD_FILE.
+	secret bool
+}
+
+// env returns the setting's environment variable.
+func (s setting) env() string {
+	return envPrefix + strings.ToUpper(s.key)
+}
+
+// flag returns the setting's flag name, e.g. redis-addr.
+func (s setting) flag() string {
+	return strings.ReplaceAll(s.key, "_", "-")
+}
+
+func stringSetting(key, usage string, field func(*config) *string) setting {
+	return setting{key: key, usage: usage, set: func(c *config, v string) error {
+		*field(c) = v
+		return nil
+	}}
+}
+
+func secretSetting(key, usage string, field func(*config) *string) setting {
+	s := stringSetting(key, usage, field)
+	s.secret = true
+	return s
+}
+
+func intSetting(key, usage string, field func(*config) *int) setting {
+	return setting{key: key, usage: usage, set: func(c *config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", v)
+		}
+		*field(c) = n
+		return nil
+	}}
+}
+
+func durationSetting(key, usage string, field func(*config) *time.Duration) setting {
+	return setting{key: key, usage: usage, set: func(c *config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", v)
+		}
+		*field(c) = d
+		return nil
+	}}
+}
+
+func boolSetting(key, usage string, field func(*config) *bool) setting {
+	return setting{key: key, usage: usage, boolean: true, set: func(c *config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", v)
+		}
+		*field(c) = b
+		return nil
+	}}
+}
+
+// settings lists the values that can be overridden, in the order they are
+// documented.
+var settings = []setting{
+	intSetting("port", "http server port", func(c *config) *int { return &c.Port }),
+	stringSetting("region", "region or probe name added to results", func(c *config) *string { return &c.Region }),
+	secretSetting("api_token", "bearer token enabling the api endpoints that change state", func(c *config) *string { return &c.APIToken }),
+	durationSetting("check_interval", "time between check cycles", func(c *config) *time.Duration { return &c.CheckInterval }),
+	durationSetting("check_timeout", "timeout per http check", func(c *config) *time.Duration { return &c.CheckTimeout }),
+	durationSetting("warmup", "spread the first checks over this period", func(c *config) *time.Duration { return &c.Warmup }),
+	stringSetting("redis_addr", "redis address (host:port)", func(c *config) *string { return &c.RedisAddr }),
+	stringSetting("redis_codec", "encoding for results stored in redis (json or msgpack)", func(c *config) *string { return &c.RedisCodec }),
+	stringSetting("redis_key_prefix", "namespace of every redis key (default kenko)", func(c *config) *string { return &c.RedisPrefix }),
+	stringSetting("redis_username", "redis acl username", func(c *config) *string { return &c.RedisUsername }),
+	secretSetting("redis_password", "redis password", func(c *config) *string { return &c.RedisPassword }),
+	intSetting("redis_db", "redis database number", func(c *config) *int { return &c.RedisDB }),
+	secretSetting("postgres_url", "postgres connection url, used instead of redis", func(c *config) *string { return &c.PostgresURL }),
+	intSetting("write_queue_size", "write-behind queue size", func(c *config) *int { return &c.WriteQueue }),
+	durationSetting("result_cache_ttl", "result cache ttl", func(c *config) *time.Duration { return &c.CacheTTL }),
+	stringSetting("source_addr", "local ip to send checks from", func(c *config) *string { return &c.SourceAddr }),
+	stringSetting("source_interface", "network interface to send checks from", func(c *config) *string { return &c.SourceIf }),
+	boolSetting("tls_metrics", "export tls metrics", func(c *config) *bool { return &c.TLSMetrics }),
+}
+
+// overrides holds setting values given as flags, keyed by setting key.
+type overrides map[string]string
+
+// registerOverrides adds a flag for every setting to fs. values are checked
+// as they are parsed so a malformed flag fails before the config is loaded.
+func registerOverrides(fs *flag.FlagSet) overrides {
+	o := make(overrides)
+	for _, s := range settings {
+		usage := s.usage + " (overrides " + s.key + " and $" + s.env() + ")"
+		set := func(v string) error {
+			if err := s.set(&config{}, v); err != nil {
+				return err
+			}
+			o[s.key] = v
+			return nil
+		}
+		if s.boolean {
+			fs.BoolFunc(s.flag(), usage, set)
+		} else {
+			fs.Func(s.flag(), usage, set)
+		}
+	}
+	return o
+}
+
+// applyOverrides sets config values from KENKO_* environment variables and
+// then from flags, so the precedence is flag, environment, config file,
+// built-in default.
+func applyOverrides(cfg *config, flags overrides) error {
+	for _, s := range settings {
+		if path, ok := os.LookupEnv(s.env() + "_FILE"); ok && s.secret {
+			v, err := readSecretFile(path)
+			if err != nil {
+				return fmt.Errorf("%s_FILE: %w", s.env(), err)
+			}
+			if err := s.set(cfg, v); err != nil {
+				return fmt.Errorf("%s_FILE: %w", s.env(), err)
+			}
+		}
+		if v, ok := os.LookupEnv(s.env()); ok {
+			if err := s.set(cfg, v); err != nil {
+				return fmt.Errorf("%s: %w", s.env(), err)
+			}
+		}
+		if v, ok := flags[s.key]; ok {
+			if err := s.set(cfg, v); err != nil {
+				return fmt.Errorf("--%s: %w", s.flag(), err)
+			}
+		}
+	}
+	return nil
+}
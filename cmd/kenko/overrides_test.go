@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverrides_Precedence(t *testing.T) {
+	path := writeConfig(t, `
+port: 8080
+check_interval: 30s
+check_timeout: 5s
+redis_addr: file:6379
+targets:
+  - name: api
+    url: https://api.example.com
+`)
+	t.Setenv("KENKO_PORT", "9090")
+	t.Setenv("KENKO_REDIS_ADDR", "env:6379")
+	t.Setenv("KENKO_CHECK_INTERVAL", "1m")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerOverrides(fs)
+	if err := fs.Parse([]string{"--port", "7070", "--tls-metrics"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := resolveConfig(path, "", true, nil, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 7070 {
+		t.Errorf("port = %d, want the flag's 7070", cfg.Port)
+	}
+	if cfg.RedisAddr != "env:6379" {
+		t.Errorf("redis_addr = %q, want the environment's", cfg.RedisAddr)
+	}
+	if cfg.CheckInterval != time.Minute {
+		t.Errorf("check_interval = %s, want 1m", cfg.CheckInterval)
+	}
+	if cfg.CheckTimeout != 5*time.Second {
+		t.Errorf("check_timeout = %s, want the file's 5s", cfg.CheckTimeout)
+	}
+	if !cfg.TLSMetrics {
+		t.Error("tls_metrics flag not applied")
+	}
+}
+
+func TestOverrides_FixesFileBeforeValidation(t *testing.T) {
+	path := writeConfig(t, `
+check_interval: 30s
+check_timeout: 5s
+targets:
+  - name: api
+    url: https://api.example.com
+`)
+	t.Setenv("KENKO_PORT", "8080")
+
+	cfg, err := resolveConfig(path, "", true, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("port = %d, want 8080", cfg.Port)
+	}
+}
+
+func TestOverrides_InvalidEnv(t *testing.T) {
+	t.Setenv("KENKO_CHECK_TIMEOUT", "soon")
+
+	_, err := resolveConfig(defaultConfigPath, "", false, []target{{Name: "api", URL: "https://api.example.com"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "KENKO_CHECK_TIMEOUT") {
+		t.Errorf("err = %v, want it to name KENKO_CHECK_TIMEOUT", err)
+	}
+}
+
+func TestOverrides_InvalidFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	registerOverrides(fs)
+
+	if err := fs.Parse([]string{"--port", "eighty"}); err == nil {
+		t.Error("malformed --port accepted")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fieldError is a config error about the field at a schema path, such as
+// targets[1].url, so it can be located in the config file.
+type fieldError struct {
+	path string
+	err  error
+}
+
+func (e *fieldError) Error() string { return e.err.Error() }
+func (e *fieldError) Unwrap() error { return e.err }
+
+// positions maps schema paths to the line they start on in the config file.
+type positions map[string]int
+
+// nodePositions records the line of every key and list item in a yaml (or
+// json) document. it returns nil for data that does not parse, leaving the
+// error to the regular decoding.
+func nodePositions(data []byte) positions {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	p := make(positions)
+	p.walk("", root.Content[0])
+	return p
+}
+
+func (p positions) walk(path string, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := join(path, n.Content[i].Value)
+			p[key] = n.Content[i].Line
+			p.walk(key, n.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			key := path + "[" + strconv.Itoa(i) + "]"
+			p[key] = item.Line
+			p.walk(key, item)
+		}
+	case yaml.AliasNode:
+		if n.Alias != nil {
+			p.walk(path, n.Alias)
+		}
+	}
+}
+
+// locate prefixes err with the line of the field it is about or, for a
+// missing field, of the closest enclosing one.
+func (p positions) locate(err error) error {
+	var fe *fieldError
+	if !errors.As(err, &fe) {
+		return err
+	}
+	for path := fe.path; path != ""; path = parentPath(path) {
+		if line, ok := p[path]; ok {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return err
+}
+
+// parentPath returns the path enclosing a field or list item.
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i > 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// at marks err as being about the field at path.
+func at(path string, err error) error {
+	return &fieldError{path: path, err: err}
+}
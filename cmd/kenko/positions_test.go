@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_ErrorLines(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		wantErr string
+	}{
+		"typo": {
+			"port: 8080\ncheck_intervall: 10s\ncheck_timeout: 3s\ntargets:\n  - name: api\n    url: https://api.example.com\n",
+			"line 2: check_intervall: unknown field, did you mean check_interval?",
+		},
+		"nested typo": {
+			"port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\ntargets:\n  - name: api\n    url: https://api.example.com\n    methd: HEAD\n",
+			"line 7: targets[0].methd: unknown field, did you mean method?",
+		},
+		"unknown without suggestion": {
+			"port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\nfrobnicate: true\ntargets:\n  - name: api\n    url: https://api.example.com\n",
+			"line 4: frobnicate: unknown field",
+		},
+		"missing name": {
+			"port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\ntargets:\n  - name: api\n    url: https://api.example.com\n  - url: https://web.example.com\n",
+			"line 7: targets[1].name: required",
+		},
+		"duplicate name": {
+			"port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\ntargets:\n  - name: api\n    url: https://api.example.com\n  - name: api\n    url: https://web.example.com\n",
+			"line 7: target[1] \"api\": name collides",
+		},
+		"empty url": {
+			"port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\ntargets:\n  - name: api\n    url: https://api.example.com\n\n  - name: web\n    url: \"\"\n",
+			"line 8: target[1] \"web\"",
+		},
+		"bad type": {
+			"port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\ntargets:\n  - name: api\n    url: https://api.example.com\n    max_body_bytes: lots\n",
+			"line 7: targets[0].max_body_bytes: must be integer",
+		},
+	}
+	for name, tt := range tests {
+		_, err := loadConfig(writeConfig(t, tt.config))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadConfig_ErrorLinesJSON(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{
+  "port": 8080,
+  "check_interval": "10s",
+  "check_timeout": "3s",
+  "targets": [
+    {"name": "api", "url": "https://api.example.com", "timeot": "1s"}
+  ]
+}`)
+	_, err := loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "line 6: targets[0].timeot: unknown field, did you mean timeout?") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"port", "port", 0},
+		{"check_intervall", "check_interval", 1},
+		{"methd", "method", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
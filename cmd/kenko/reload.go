@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	kenko "github.com/aidantrabs/kenko"
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the burst of events an editor or config management
+// tool produces when saving a file into a single reload.
+const reloadDebounce = 250 * time.Millisecond
+
+// reloader re-reads the config and applies its targets to a running checker.
+// only targets are reloaded; other settings still need a restart.
+type reloader struct {
+	path    string
+	format  string
+	pathSet bool
+	inline  []target
+	flags   overrides
+	checker *kenko.Checker
+	logger  *slog.Logger
+
+	// refresh is how often a remote config is re-fetched; zero disables it.
+	refresh time.Duration
+
+	cfg *config
+}
+
+// watched reports whether the config comes from a source rather than inline
+// targets alone.
+func (r *reloader) watched() bool {
+	return len(r.inline) == 0 || r.pathSet
+}
+
+// remote reports whether the config is fetched from a remote source, which
+// is polled instead of watched.
+func (r *reloader) remote() bool {
+	return r.watched() && remoteConfig(r.path)
+}
+
+// reload re-reads the config and applies it. on error the running targets
+// are kept.
+func (r *reloader) reload(ctx context.Context) error {
+	cfg, err := resolveConfig(r.path, r.format, r.pathSet, r.inline, r.flags)
+	if err != nil {
+		return err
+	}
+	return r.apply(ctx, cfg)
+}
+
+// poll re-fetches a remote config and applies it only when its checksum
+// changed since the last load.
+func (r *reloader) poll(ctx context.Context) error {
+	cfg, err := resolveConfig(r.path, r.format, r.pathSet, r.inline, r.flags)
+	if err != nil {
+		return err
+	}
+	if r.cfg != nil && cfg.checksum == r.cfg.checksum {
+		return nil
+	}
+	r.logger.Info("remote config changed, reloading config", "location", redactLocation(r.path), "checksum", cfg.checksum)
+	return r.apply(ctx, cfg)
+}
+
+func (r *reloader) apply(ctx context.Context, cfg *config) error {
+	changes, err := r.checker.SetTargets(ctx, configTargets(cfg))
+	if err != nil {
+		return err
+	}
+
+	if r.cfg != nil && !sameSettings(r.cfg, cfg) {
+		r.logger.Warn("config settings other than targets changed and need a restart to apply")
+	}
+	r.cfg = cfg
+
+	if changes.Empty() {
+		r.logger.Info("config reloaded, targets unchanged")
+		return nil
+	}
+	r.logger.Info("config reloaded",
+		"added", changes.Added,
+		"removed", changes.Removed,
+		"changed", changes.Changed,
+	)
+	return nil
+}
+
+func (r *reloader) reloadOrLog(ctx context.Context) {
+	if err := r.reload(ctx); err != nil {
+		r.logger.Error("config reload failed, keeping current targets", "error", err)
+	}
+}
+
+// run reloads on every signal from hup and, when the config is a file, on
+// changes to it or, when it is remote, whenever a periodic re-fetch finds it
+// changed, until ctx is cancelled.
+func (r *reloader) run(ctx context.Context, hup <-chan os.Signal) {
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	var ticks <-chan time.Time
+	switch {
+	case r.remote():
+		if r.refresh > 0 {
+			ticker := time.NewTicker(r.refresh)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
+	case r.watched():
+		w, err := watchFile(r.path)
+		if err != nil {
+			r.logger.Warn("not watching config file, reload with SIGHUP", "path", r.path, "error", err)
+		} else {
+			defer w.Close()
+			events, errs = w.Events, w.Errors
+		}
+	}
+
+	target := filepath.Clean(r.path)
+	debounce := time.NewTimer(0)
+	if !debounce.Stop() {
+		<-debounce.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.logger.Info("SIGHUP received, reloading config")
+			r.reloadOrLog(ctx)
+		case ev := <-events:
+			if filepath.Clean(ev.Name) == target && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce.Reset(reloadDebounce)
+			}
+		case <-debounce.C:
+			r.logger.Info("config file changed, reloading config", "path", r.path)
+			r.reloadOrLog(ctx)
+		case <-ticks:
+			if err := r.poll(ctx); err != nil {
+				r.logger.Error("remote config refresh failed, keeping current targets", "error", err)
+			}
+		case err := <-errs:
+			r.logger.Warn("config watch error", "error", err)
+		}
+	}
+}
+
+// watchFile watches the directory holding path, since many editors replace
+// the file rather than writing it in place.
+func watchFile(path string) (*fsnotify.Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// sameSettings reports whether two configs differ only in their targets.
+func sameSettings(a, b *config) bool {
+	x, y := *a, *b
+	x.Targets, y.Targets = nil, nil
+	x.checksum, y.checksum = "", ""
+	x.lines, y.lines = nil, nil
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	kenko "github.com/aidantrabs/kenko"
+)
+
+const reloadHeader = "port: 6969\ncheck_interval: 30s\ncheck_timeout: 5s\n"
+
+const reloadConfigA = `
+targets:
+  - name: api
+    url: https://api.example.com
+  - name: web
+    url: https://example.com
+`
+
+const reloadConfigB = `
+targets:
+  - name: api
+    url: https://api.example.com
+  - name: docs
+    url: https://docs.example.com
+`
+
+func newReloader(t *testing.T, config string) (*reloader, string) {
+	t.Helper()
+	path := writeConfig(t, reloadHeader+config)
+
+	cfg, err := resolveConfig(path, "", true, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opts []kenko.Option
+	for _, t := range configTargets(cfg) {
+		opts = append(opts, kenko.WithTarget(t.Name, t.URL))
+	}
+	c, err := kenko.NewChecker(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &reloader{
+		path:    path,
+		pathSet: true,
+		checker: c,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cfg:     cfg,
+	}, path
+}
+
+func rewriteConfig(t *testing.T, path, config string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(reloadHeader+config), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func targetNames(c *kenko.Checker) []string {
+	var names []string
+	for _, t := range c.Targets() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestReloader_Reload(t *testing.T) {
+	r, path := newReloader(t, reloadConfigA)
+	rewriteConfig(t, path, reloadConfigB)
+
+	if err := r.reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := targetNames(r.checker); len(got) != 2 || got[0] != "api" || got[1] != "docs" {
+		t.Errorf("targets = %v, want [api docs]", got)
+	}
+}
+
+func TestReloader_InvalidKeepsTargets(t *testing.T) {
+	r, path := newReloader(t, reloadConfigA)
+	rewriteConfig(t, path, "targets:\n  - name: api\n    url: ftp://example.com\n")
+
+	if err := r.reload(context.Background()); err == nil {
+		t.Fatal("expected an invalid config to fail")
+	}
+	if got := targetNames(r.checker); len(got) != 2 || got[1] != "web" {
+		t.Errorf("targets = %v, want the original [api web]", got)
+	}
+}
+
+func TestReloader_Signal(t *testing.T) {
+	r, path := newReloader(t, reloadConfigA)
+	rewriteConfig(t, path, reloadConfigB) // before the watcher starts
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hup := make(chan os.Signal, 1)
+	go r.run(ctx, hup)
+	hup <- os.Interrupt
+
+	waitForTargets(t, r.checker, "docs")
+}
+
+func TestReloader_FileChange(t *testing.T) {
+	r, path := newReloader(t, reloadConfigA)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx, nil)
+	time.Sleep(50 * time.Millisecond) // let the watcher start
+
+	rewriteConfig(t, path, reloadConfigB)
+	waitForTargets(t, r.checker, "docs")
+}
+
+func waitForTargets(t *testing.T, c *kenko.Checker, want string) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, name := range targetNames(c) {
+			if name == want {
+				return
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("targets = %v, want them to include %q", targetNames(c), want)
+}
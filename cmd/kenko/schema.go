@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaDraft is the JSON Schema dialect of the generated schema.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations accepted by time.ParseDuration.
+const durationPattern = `^[-+]?(0|([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+
+var (
+	durationType   = reflect.TypeFor[time.Duration]()
+	durationRegexp = regexp.MustCompile(durationPattern)
+)
+
+// schema is the subset of JSON Schema kenko generates from its config structs
+// and validates config files against.
+type schema struct {
+	Schema     string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       typeList           `json:"type,omitempty"`
+	Properties map[string]*schema `json:"properties,omitempty"`
+	Values     *schema            `json:"-"`
+	Closed     bool               `json:"-"`
+	// Secret marks a string, or a map of strings, whose values may instead
+	// be read from files named by a _file key. see resolveSecretFiles.
+	Secret      bool     `json:"-"`
+	Items       *schema  `json:"items,omitempty"`
+	Required    []string `json:"required,omitempty"`
+	Enum        []any    `json:"enum,omitempty"`
+	Minimum     *float64 `json:"minimum,omitempty"`
+	Maximum     *float64 `json:"maximum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Description string   `json:"description,omitempty"`
+	// re is Pattern compiled, set along with it.
+	re *regexp.Regexp
+}
+
+// MarshalJSON emits additionalProperties as false for structs and as the
+// value schema for maps.
+func (s *schema) MarshalJSON() ([]byte, error) {
+	type plain schema
+	out := struct {
+		*plain
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+	switch {
+	case s.Values != nil:
+		out.AdditionalProperties = s.Values
+	case s.Closed:
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+// typeList is a schema type, marshalled as a string when there is only one.
+type typeList []string
+
+func (t typeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// configSchema returns the JSON Schema of the config file, generated from the
+// config structs so the two cannot drift apart.
+func configSchema() *schema {
+	s := schemaFor(reflect.TypeFor[config]())
+	s.Schema = schemaDraft
+	s.Title = "kenko config"
+	// lets json configs name the schema for editors
+	s.Properties["$schema"] = &schema{Type: typeList{"string"}}
+	s.Properties[defaultsKey] = defaultsSchema()
+	return s
+}
+
+// schemaFor builds the schema of a config type. fields are named by their
+// yaml tag and refined by a schema tag holding comma-separated directives:
+// required, secret, min=N, max=N, and enum=a|b|c.
+func schemaFor(t reflect.Type) *schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return &schema{Type: typeList{"string"}, Pattern: durationPattern, re: durationRegexp, Description: "a duration such as 30s or 5m"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: typeList{"string"}}
+	case reflect.Bool:
+		return &schema{Type: typeList{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &schema{Type: typeList{"integer"}}
+	case reflect.Slice:
+		return &schema{Type: typeList{"array"}, Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: typeList{"object"}, Values: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &schema{Type: typeList{"object"}, Properties: make(map[string]*schema), Closed: true}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			prop := schemaFor(f.Type)
+			if applyDirectives(prop, f.Tag.Get("schema")) {
+				s.Required = append(s.Required, name)
+			}
+			s.Properties[name] = prop
+			if prop.Secret && prop.Values == nil {
+				s.Properties[name+secretFileSuffix] = &schema{
+					Type:        typeList{"string"},
+					Description: "path of a file holding " + name,
+				}
+			}
+		}
+		return s
+	}
+	panic(fmt.Sprintf("schema: unsupported config type %s", t))
+}
+
+// applyDirectives refines s by a schema tag, reporting whether the field is
+// required.
+func applyDirectives(s *schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for _, d := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(d, "=")
+		switch key {
+		case "required":
+			required = true
+		case "secret":
+			s.Secret = true
+		case "min", "max":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				panic(fmt.Sprintf("schema: bad %s directive %q", key, d))
+			}
+			if key == "min" {
+				s.Minimum = &n
+			} else {
+				s.Maximum = &n
+			}
+		case "enum":
+			// yaml reads an unquoted 4 as a number, so numeric string
+			// values are also accepted as numbers.
+			s.Type = nil
+			for _, v := range strings.Split(val, "|") {
+				s.Enum = append(s.Enum, v)
+				if n, err := strconv.Atoi(v); err == nil {
+					s.Enum = append(s.Enum, n)
+				}
+			}
+		default:
+			panic(fmt.Sprintf("schema: unknown directive %q", d))
+		}
+	}
+	return required
+}
+
+// validate checks a decoded yaml document against s, returning the first
+// violation found. path locates v within the document, e.g. targets[0].url.
+func (s *schema) validate(path string, v any) (err error) {
+	// an empty yaml value leaves the field at its default
+	if v == nil {
+		return nil
+	}
+
+	// attribute the error to the innermost field that produced it
+	defer func() {
+		var fe *fieldError
+		if err != nil && !errors.As(err, &fe) {
+			err = at(path, err)
+		}
+	}()
+
+	if len(s.Enum) > 0 {
+		if !slices.Contains(s.Enum, v) {
+			return fmt.Errorf("%s: must be one of %s", fieldName(path), enumList(s.Enum))
+		}
+		return nil
+	}
+
+	if typ := jsonType(v); len(s.Type) > 0 && !slices.Contains(s.Type, typ) {
+		switch {
+		case slices.Contains(s.Type, "number") && typ == "integer":
+		// yaml decodes an unquoted number or boolean into a string field as
+		// written, e.g. a numeric chat id. patterned strings still need the
+		// string, as durations do.
+		case slices.Equal(s.Type, typeList{"string"}) && s.re == nil && (typ == "integer" || typ == "number" || typ == "boolean"):
+		default:
+			return fmt.Errorf("%s: must be %s, got %s", fieldName(path), strings.Join(s.Type, " or "), typ)
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		if s.re != nil && !s.re.MatchString(v) {
+			return fmt.Errorf("%s: %q does not match %s", fieldName(path), v, describe(s))
+		}
+	case int:
+		if s.Minimum != nil && float64(v) < *s.Minimum {
+			return fmt.Errorf("%s: must be at least %v, got %d", fieldName(path), *s.Minimum, v)
+		}
+		if s.Maximum != nil && float64(v) > *s.Maximum {
+			return fmt.Errorf("%s: must be at most %v, got %d", fieldName(path), *s.Maximum, v)
+		}
+	case []any:
+		for i, item := range v {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: required", join(path, name))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			prop := s.Properties[k]
+			switch {
+			case prop != nil:
+			case s.Values != nil:
+				prop = s.Values
+			case s.Closed:
+				if name := closest(k, s.Properties); name != "" {
+					return at(join(path, k), fmt.Errorf("%s: unknown field, did you mean %s?", join(path, k), name))
+				}
+				return at(join(path, k), fmt.Errorf("%s: unknown field", join(path, k)))
+			default:
+				continue
+			}
+			if err := prop.validate(join(path, k), v[k]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// closest returns the property name nearest to an unknown key, allowing for
+// a typo or two, or "" when none is close.
+func closest(key string, props map[string]*schema) string {
+	best, bestDist := "", 3
+	for name := range props {
+		if d := editDistance(key, name); d < bestDist || d == bestDist && name < best {
+			best, bestDist = name, d
+		}
+	}
+	if bestDist > 2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func describe(s *schema) string {
+	if s.Pattern == durationPattern {
+		return "a duration such as 30s"
+	}
+	return "pattern " + s.Pattern
+}
+
+// jsonType names the JSON Schema type of a decoded yaml value.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func enumList(values []any) string {
+	var parts []string
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// writeSchema writes the config schema to w as indented JSON.
+func writeSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(configSchema())
+}
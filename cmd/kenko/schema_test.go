@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema_CoversConfig(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSchema(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("schema is not json: %v", err)
+	}
+	if doc["$schema"] != schemaDraft || doc["additionalProperties"] != false {
+		t.Errorf("root = %v", doc)
+	}
+
+	props := doc["properties"].(map[string]any)
+	for _, key := range []string{"port", "check_interval", "warmup", "status_page", "targets"} {
+		if _, ok := props[key]; !ok {
+			t.Errorf("schema has no %q property", key)
+		}
+	}
+
+	items := props["targets"].(map[string]any)["items"].(map[string]any)
+	if req := items["required"].([]any); len(req) != 1 || req[0] != "name" {
+		t.Errorf("targets required = %v, want [name]", req)
+	}
+	headers := items["properties"].(map[string]any)["headers"].(map[string]any)
+	if headers["additionalProperties"].(map[string]any)["type"] != "string" {
+		t.Errorf("headers = %v, want string values", headers)
+	}
+}
+
+func TestConfigSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"valid", "port: 8080\ncheck_interval: 1m30s\ntargets:\n  - name: api\n    url: https://example.com\n    ip_version: 4\n", ""},
+		{"empty targets", "targets:\n", ""},
+		{"unknown field", "check_intervall: 30s\n", "check_intervall: unknown field"},
+		{"nested unknown", "targets:\n  - name: api\n    timeouts:\n      dns: 1s\n      tls: 1s\n", "targets[0].timeouts.tls: unknown field"},
+		{"wrong type", "port: eighty\n", "port: must be integer, got string"},
+		{"range", "port: 70000\n", "port: must be at most 65535"},
+		{"bad duration", "check_timeout: 5 seconds\n", "check_timeout"},
+		{"enum", "targets:\n  - name: api\n    ip_version: 5\n", "targets[0].ip_version: must be one of any, 4, 6"},
+		{"required", "targets:\n  - url: https://example.com\n", "targets[0].name: required"},
+		{"map values", "vars:\n  host: [1]\n", "vars.host: must be string, got array"},
+		{"scalar strings", "vars:\n  port: 1\n  debug: true\n  ratio: 0.5\ntargets:\n  - name: 42\n    url: https://example.com\n", ""},
+		{"scalar duration", "check_timeout: 5\n", "check_timeout: must be string, got integer"},
+	}
+
+	for _, tt := range tests {
+		err := validateYAML(t, tt.yaml)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func validateYAML(t *testing.T, data string) error {
+	t.Helper()
+	path := writeConfig(t, data)
+	_, err := readConfig(path, "")
+	return err
+}
+
+func TestLoadConfig_SchemaRejectsTypo(t *testing.T) {
+	path := writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+targets:
+  - name: api
+    url: https://example.com
+    mehtod: HEAD
+`)
+
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), "targets[0].mehtod: unknown field") {
+		t.Errorf("err = %v, want an unknown field error", err)
+	}
+}
+
+func TestHandleSchema(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleSchema(rec, httptest.NewRequest(http.MethodGet, "/api/v1/config/schema", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/schema+json" {
+		t.Fatalf("code = %d, content-type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Error("body is not json")
+	}
+}
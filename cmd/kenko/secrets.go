@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// secretFileSuffix marks a config key whose value is read from the file it
+// names, e.g. redis_password_file: /run/secrets/redis.
+const secretFileSuffix = "_file"
+
+// resolveSecretFiles replaces every <key>_file entry of a decoded config
+// document with <key> set to the named file's contents, for keys the schema
+// marks secret. this covers string fields such as redis_password and the
+// values of secret maps such as headers, so mounted kubernetes or docker
+// secrets never pass through the environment. files are read on every load,
+// so a reload picks up rotated secrets.
+func resolveSecretFiles(s *schema, path string, v any) error {
+	if s == nil {
+		return nil
+	}
+
+	switch v := v.(type) {
+	case []any:
+		for i, item := range v {
+			if err := resolveSecretFiles(s.Items, fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			base, ok := strings.CutSuffix(k, secretFileSuffix)
+			if !ok || !s.secretKey(base) {
+				continue
+			}
+			if _, both := v[base]; both {
+				return fmt.Errorf("%s and %s are mutually exclusive", join(path, base), join(path, k))
+			}
+			file, ok := v[k].(string)
+			if !ok {
+				return fmt.Errorf("%s: must be a file path", join(path, k))
+			}
+			secret, err := readSecretFile(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", join(path, k), err)
+			}
+			v[base] = secret
+			delete(v, k)
+		}
+
+		for k, item := range v {
+			prop := s.Properties[k]
+			if prop == nil {
+				prop = s.Values
+			}
+			if err := resolveSecretFiles(prop, join(path, k), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// secretKey reports whether key of an object with schema s may be read from
+// a file: a secret string property, or any entry of a secret map.
+func (s *schema) secretKey(key string) bool {
+	if s.Values != nil {
+		return s.Secret
+	}
+	prop := s.Properties[key]
+	return prop != nil && prop.Secret
+}
+
+// readSecretFile returns a secret file's contents without the trailing
+// newline most tools write.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_SecretFiles(t *testing.T) {
+	password := writeSecret(t, "hunter2\n")
+	token := writeSecret(t, "Bearer abc123\n")
+
+	path := writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+redis_password_file: `+password+`
+http_defaults:
+  headers:
+    X-Api-Key_file: `+password+`
+targets:
+  - name: api
+    url: https://api.example.com
+    headers:
+      Authorization_file: `+token+`
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RedisPassword != "hunter2" {
+		t.Errorf("redis_password = %q, want hunter2", cfg.RedisPassword)
+	}
+	if got := cfg.HTTPDefaults.Headers["X-Api-Key"]; got != "hunter2" {
+		t.Errorf("X-Api-Key = %q", got)
+	}
+	if got := cfg.Targets[0].Headers["Authorization"]; got != "Bearer abc123" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if _, ok := cfg.Targets[0].Headers["Authorization_file"]; ok {
+		t.Error("_file key left in headers")
+	}
+}
+
+func TestLoadConfig_SecretFileErrors(t *testing.T) {
+	tests := map[string]struct {
+		yaml    string
+		wantErr string
+	}{
+		"missing file": {"redis_password_file: /nonexistent/secret\n", "redis_password_file"},
+		"both":         {"redis_password: x\nredis_password_file: /nonexistent/secret\n", "mutually exclusive"},
+		"not secret":   {"region_file: /nonexistent/secret\n", "region_file: unknown field"},
+	}
+
+	for name, tt := range tests {
+		path := writeConfig(t, "port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\n"+tt.yaml)
+		if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOverrides_SecretFileEnv(t *testing.T) {
+	t.Setenv("KENKO_REDIS_PASSWORD_FILE", writeSecret(t, "from-file\n"))
+
+	cfg, err := resolveConfig(defaultConfigPath, "", false, []target{{Name: "api", URL: "https://api.example.com"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RedisPassword != "from-file" {
+		t.Errorf("redis_password = %q, want from-file", cfg.RedisPassword)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	kenko "github.com/aidantrabs/kenko"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const defaultConfigPath = "configs/config.yaml"
+
+func serve(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configPath := fs.String("config", defaultConfigPath, "path to config file, or an http(s)://, s3://, or etcd:// location")
+	configFmt := fs.String("config-format", "", "config file format: yaml, json, or toml (default from the file extension)")
+	configRefresh := fs.Duration("config-refresh", time.Minute, "how often to re-fetch a remote config, reloading when it changed (0 disables)")
+	var inline targetFlags
+	fs.Var(&inline, "target", "inline target as name=<name>,url=<url> (repeatable)")
+	flags := registerOverrides(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	configSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			configSet = true
+		}
+	})
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	cfg, err := resolveConfig(*configPath, *configFmt, configSet, inline, flags)
+	if err != nil {
+		logger.Error("failed to load config", "error", err)
+		return err
+	}
+
+	var extra []kenko.MetricsReporter
+	influx := influxFromConfig(cfg, logger)
+	if influx != nil {
+		extra = append(extra, influx)
+	}
+	opts := configToOptions(cfg, extra...)
+	opts = append(opts, kenko.WithLogger(logger))
+
+	if cfg.SaveTargets {
+		saver, err := newConfigSaver(*configPath, *configFmt, len(inline) == 0 || configSet)
+		if err != nil {
+			logger.Error("failed to persist targets", "error", err)
+			return err
+		}
+		saver.slug = cfg.SlugNames
+		opts = append(opts, kenko.WithTargetSaver(saver))
+	}
+
+	store, err := storeFromConfig(cfg)
+	if err != nil {
+		logger.Error("failed to open store", "error", err)
+		return err
+	}
+	if store != nil {
+		opts = append(opts, kenko.WithStore(store))
+	}
+
+	enricher, err := enricherFromConfig(cfg)
+	if err != nil {
+		logger.Error("failed to open geoip databases", "error", err)
+		return err
+	}
+	if enricher != nil {
+		defer enricher.Close()
+		opts = append(opts, kenko.WithIPEnricher(enricher))
+	}
+
+	archiver, err := archiverFromConfig(context.Background(), cfg)
+	if err != nil {
+		logger.Error("failed to set up the archive", "error", err)
+		return err
+	}
+	if archiver != nil {
+		opts = append(opts, kenko.WithArchive(kenko.Archive{
+			Archiver: archiver,
+			After:    cfg.Archive.After,
+			Interval: cfg.Archive.Interval,
+		}))
+	}
+
+	k, err := kenko.New(opts...)
+	if err != nil {
+		logger.Error("failed to create kenko", "error", err)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// the checker runs on its own context so a shutdown signal drains it
+	// through k.Shutdown rather than aborting checks mid-flight
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+	go k.Run(runCtx)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	r := &reloader{
+		path:    *configPath,
+		format:  *configFmt,
+		pathSet: configSet,
+		inline:  inline,
+		flags:   flags,
+		checker: k.Checker(),
+		logger:  logger,
+		refresh: *configRefresh,
+		cfg:     cfg,
+	}
+	go r.run(ctx, hup)
+	go renewVault(ctx, logger)
+
+	// the exporters outlive the checker so they write what it reports while
+	// draining, then write once more when stopped
+	exportCtx, stopExport := context.WithCancel(context.Background())
+	defer stopExport()
+	var exporters sync.WaitGroup
+	if rw := remoteWriterFromConfig(cfg, logger); rw != nil {
+		exporters.Add(1)
+		go func() {
+			defer exporters.Done()
+			rw.Run(exportCtx)
+		}()
+	}
+	if influx != nil {
+		exporters.Add(1)
+		go func() {
+			defer exporters.Done()
+			influx.Run(exportCtx)
+		}()
+	}
+
+	mux := http.NewServeMux()
+	k.RegisterHandlers(mux)
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("GET /api/v1/config/schema", handleSchema)
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Port),
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("server starting", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("server error", "error", err)
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutdown signal received")
+	case err := <-serverErr:
+		return err
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("server shutdown error", "error", err)
+		return err
+	}
+
+	if err := k.Shutdown(shutdownCtx); err != nil {
+		logger.Error("checker did not drain before the shutdown deadline", "error", err)
+		return err
+	}
+	stopExport()
+	exporters.Wait()
+
+	logger.Info("server stopped gracefully")
+	return nil
+}
+
+// handleSchema serves the config schema, so editors and ci can fetch it from
+// a running instance.
+func handleSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	if err := writeSchema(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// resolveConfig loads the config file, appending any inline targets and
+// applying environment and flag overrides. when only inline targets are given
+// and no config path was set explicitly, built-in defaults are used instead
+// of a file.
+func resolveConfig(path, format string, pathSet bool, inline []target, flags overrides) (*config, error) {
+	cfg := defaultConfig()
+	if len(inline) == 0 || pathSet {
+		var err error
+		if cfg, err = readConfig(path, format); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Targets = append(cfg.Targets, inline...)
+
+	if err := applyOverrides(cfg, flags); err != nil {
+		return nil, fmt.Errorf("invalid override: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// targetFlags collects repeated --target name=...,url=... flags.
+type targetFlags []target
+
+func (f *targetFlags) String() string {
+	parts := make([]string, 0, len(*f))
+	for _, t := range *f {
+		parts = append(parts, "name="+t.Name+",url="+t.URL)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f *targetFlags) Set(value string) error {
+	t, err := parseTargetFlag(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, t)
+	return nil
+}
+
+// parseTargetFlag parses "name=api,url=https://...". commas that are not
+// followed by a known key are kept as part of the previous value so URLs
+// containing commas still work.
+func parseTargetFlag(value string) (target, error) {
+	var t target
+	var cur *string
+
+	for _, seg := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(seg, "=")
+		switch key {
+		case "name":
+			cur = &t.Name
+		case "url":
+			cur = &t.URL
+		default:
+			if cur == nil {
+				return target{}, fmt.Errorf("invalid target %q: expected name=<name>,url=<url>", value)
+			}
+			*cur += "," + seg
+			continue
+		}
+		*cur = val
+	}
+
+	if t.Name == "" || t.URL == "" {
+		return target{}, fmt.Errorf("invalid target %q: both name and url are required", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestParseTargetFlag(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantName string
+		wantURL  string
+		wantErr  bool
+	}{
+		{"name=api,url=https://api.example.com/health", "api", "https://api.example.com/health", false},
+		{"url=https://example.com,name=web", "web", "https://example.com", false},
+		{"name=q,url=https://example.com/?ids=1,2,3", "q", "https://example.com/?ids=1,2,3", false},
+		{"name=api", "", "", true},
+		{"https://example.com", "", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTargetFlag(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTargetFlag(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got.Name != tt.wantName || got.URL != tt.wantURL {
+			t.Errorf("parseTargetFlag(%q) = %q, %q; want %q, %q", tt.in, got.Name, got.URL, tt.wantName, tt.wantURL)
+		}
+	}
+}
+
+func TestResolveConfig_InlineOnly(t *testing.T) {
+	cfg, err := resolveConfig(defaultConfigPath, "", false, []target{{Name: "api", URL: "https://api.example.com"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 6969 {
+		t.Errorf("port = %d, want 6969", cfg.Port)
+	}
+	if len(cfg.Targets) != 1 {
+		t.Errorf("targets = %d, want 1", len(cfg.Targets))
+	}
+}
+
+func TestResolveConfig_InlineAppendsToFile(t *testing.T) {
+	path := writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+targets:
+  - name: example
+    url: https://example.com
+`)
+
+	cfg, err := resolveConfig(path, "", true, []target{{Name: "api", URL: "https://api.example.com"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Targets) != 2 {
+		t.Errorf("targets = %d, want 2", len(cfg.Targets))
+	}
+}
+
+func TestResolveConfig_InvalidInline(t *testing.T) {
+	_, err := resolveConfig(defaultConfigPath, "", false, []target{{Name: "api", URL: "ftp://example.com"}}, nil)
+	if err == nil {
+		t.Fatal("expected error for invalid inline target")
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// configFetchTimeout bounds a single fetch of a remote config.
+	configFetchTimeout = 30 * time.Second
+	// maxConfigBytes caps the size of a remote config.
+	maxConfigBytes = 10 << 20
+)
+
+// remoteConfig reports whether location names a remote config source rather
+// than a local file.
+func remoteConfig(location string) bool {
+	scheme, _, ok := strings.Cut(location, "://")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "s3", "etcd", "etcd+https":
+		return true
+	}
+	return false
+}
+
+// configExt returns the extension of a config location, ignoring any query
+// string of a remote one.
+func configExt(location string) string {
+	if remoteConfig(location) {
+		if u, err := url.Parse(location); err == nil {
+			return path.Ext(u.Path)
+		}
+	}
+	return filepath.Ext(location)
+}
+
+// fetchConfig reads config data from a file path or a remote location: an
+// http(s) url, s3://bucket/key using the default aws credential chain, or
+// etcd://host:port/key through the etcd v3 json gateway (etcd+https for tls).
+func fetchConfig(location string) ([]byte, error) {
+	if !remoteConfig(location) {
+		return os.ReadFile(location)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configFetchTimeout)
+	defer cancel()
+
+	switch strings.ToLower(u.Scheme) {
+	case "s3":
+		return fetchS3(ctx, u)
+	case "etcd", "etcd+https":
+		return fetchEtcd(ctx, u)
+	default:
+		return fetchHTTP(ctx, u)
+	}
+}
+
+// fetchHTTP gets the config from an http(s) url. credentials in the url are
+// sent with basic auth.
+func fetchHTTP(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "kenko")
+	return doFetch(req)
+}
+
+// fetchEtcd reads a single key through the etcd v3 grpc-gateway, which takes
+// and returns base64 encoded keys and values.
+func fetchEtcd(ctx context.Context, u *url.URL) ([]byte, error) {
+	if u.Path == "" || u.Path == "/" {
+		return nil, fmt.Errorf("etcd config location needs a key, e.g. etcd://%s/kenko/config", u.Host)
+	}
+
+	scheme := "http"
+	if strings.EqualFold(u.Scheme, "etcd+https") {
+		scheme = "https"
+	}
+	body, err := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(u.Path))})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := url.URL{Scheme: scheme, Host: u.Host, Path: "/v3/kv/range"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	data, err := doFetch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		KVs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("decoding etcd response: %w", err)
+	}
+	if len(resp.KVs) == 0 {
+		return nil, fmt.Errorf("etcd key %q not found", u.Path)
+	}
+	return base64.StdEncoding.DecodeString(resp.KVs[0].Value)
+}
+
+func doFetch(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%s %s: status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	return readLimited(resp.Body)
+}
+
+// fetchS3 gets the config object from s3://bucket/key. the region,
+// credentials, and an AWS_ENDPOINT_URL for s3-compatible stores come from the
+// usual aws environment and shared config.
+func fetchS3(ctx context.Context, u *url.URL) ([]byte, error) {
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("s3 config location must be s3://bucket/key")
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading aws config: %w", err)
+	}
+	out, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.Host),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return readLimited(out.Body)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxConfigBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxConfigBytes {
+		return nil, fmt.Errorf("config is larger than %d bytes", maxConfigBytes)
+	}
+	return data, nil
+}
+
+// redactLocation hides any password in a remote config location for logs.
+func redactLocation(location string) string {
+	if !remoteConfig(location) {
+		return location
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	kenko "github.com/aidantrabs/kenko"
+)
+
+func TestLoadConfig_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "kenko" || pass != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/config.json":
+			io.WriteString(w, `{"port": 8080, "check_interval": "10s", "check_timeout": "3s", "targets": [{"name": "api", "url": "https://api.example.com"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	base := strings.Replace(srv.URL, "http://", "http://kenko:s3cret@", 1)
+	cfg, err := loadConfig(base + "/config.json?ref=main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Targets) != 1 || cfg.Targets[0].Name != "api" || cfg.checksum == "" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	_, err = loadConfig(base + "/missing.yaml")
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("missing err = %v, want status 404", err)
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error leaks the password: %v", err)
+	}
+}
+
+func TestLoadConfig_Etcd(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key string `json:"key"`
+		}
+		if r.URL.Path != "/v3/kv/range" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		key, _ := base64.StdEncoding.DecodeString(req.Key)
+		if string(key) != "/kenko/config" {
+			io.WriteString(w, `{"header": {}}`)
+			return
+		}
+		value := base64.StdEncoding.EncodeToString([]byte(reloadHeader + reloadConfigA))
+		io.WriteString(w, `{"kvs": [{"value": "`+value+`"}]}`)
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	cfg, err := loadConfig("etcd://" + host + "/kenko/config")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Targets) != 2 {
+		t.Errorf("targets = %+v", cfg.Targets)
+	}
+
+	if _, err := loadConfig("etcd://" + host + "/kenko/other"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing key err = %v", err)
+	}
+	if _, err := loadConfig("etcd://" + host); err == nil || !strings.Contains(err.Error(), "needs a key") {
+		t.Errorf("no key err = %v", err)
+	}
+}
+
+func TestFetchConfig_S3Location(t *testing.T) {
+	for _, loc := range []string{"s3://bucket", "s3://bucket/", "s3:///key"} {
+		if _, err := fetchConfig(loc); err == nil || !strings.Contains(err.Error(), "s3://bucket/key") {
+			t.Errorf("%s: err = %v", loc, err)
+		}
+	}
+}
+
+func TestConfigExt(t *testing.T) {
+	tests := map[string]string{
+		"configs/config.toml":                     ".toml",
+		"https://example.com/kenko.json?ref=main": ".json",
+		"s3://bucket/kenko/config.yaml":           ".yaml",
+		"etcd://localhost:2379/kenko/config":      "",
+	}
+	for loc, want := range tests {
+		if got := configExt(loc); got != want {
+			t.Errorf("configExt(%q) = %q, want %q", loc, got, want)
+		}
+	}
+}
+
+func TestReloader_PollRemote(t *testing.T) {
+	var config atomic.Value
+	config.Store(reloadConfigA)
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		io.WriteString(w, reloadHeader+config.Load().(string))
+	}))
+	defer srv.Close()
+
+	location := srv.URL + "/config.yaml"
+	cfg, err := resolveConfig(location, "", true, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := kenko.NewChecker(kenko.WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &reloader{
+		path:    location,
+		pathSet: true,
+		checker: c,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cfg:     cfg,
+	}
+	if !r.remote() {
+		t.Fatal("http config not treated as remote")
+	}
+
+	// unchanged content leaves the running targets alone
+	if err := r.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := targetNames(c); len(got) != 1 {
+		t.Errorf("targets = %v, want [api] until the config changes", got)
+	}
+
+	config.Store(reloadConfigB)
+	if err := r.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := targetNames(c); len(got) != 2 || got[1] != "docs" {
+		t.Errorf("targets = %v, want [api docs]", got)
+	}
+	if fetches.Load() != 3 {
+		t.Errorf("fetches = %d, want 3", fetches.Load())
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	kenko "github.com/aidantrabs/kenko"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeTarget decodes a target API spec written like a target of the config
+// file, in json or yaml. unlike the config file it does not inherit the
+// defaults block, nor read secrets from files or vault, as the spec comes
+// from a request. for the same reason it rejects the settings that run
+// commands or reach beyond the target, which only the config file may set.
+func decodeTarget(spec []byte) (kenko.Target, error) {
+	var doc any
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return kenko.Target{}, fmt.Errorf("parsing target: %w", err)
+	}
+	if _, ok := doc.(map[string]any); !ok {
+		return kenko.Target{}, errors.New("invalid target: must be an object")
+	}
+	if err := schemaFor(reflect.TypeFor[target]()).validate("", doc); err != nil {
+		return kenko.Target{}, fmt.Errorf("invalid target: %w", err)
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return kenko.Target{}, fmt.Errorf("parsing target: %w", err)
+	}
+	var t target
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil {
+		return kenko.Target{}, fmt.Errorf("parsing target: %w", err)
+	}
+	if err := t.validate(); err != nil {
+		return kenko.Target{}, fmt.Errorf("invalid target %q: %w", t.Name, err)
+	}
+	for _, f := range []struct {
+		field string
+		set   bool
+	}{
+		{"auth.refresh_command", t.Auth != nil && len(t.Auth.RefreshCommand) > 0},
+		{"auth.refresh_url", t.Auth != nil && t.Auth.RefreshURL != ""},
+		{"source_addr", t.SourceAddr != ""},
+		{"source_interface", t.SourceIf != ""},
+	} {
+		if f.set {
+			return kenko.Target{}, fmt.Errorf("invalid target %q: %s cannot be set through the API", t.Name, f.field)
+		}
+	}
+	return kenko.NewTarget(t.Name, t.checkURL(), targetOptions(t)...), nil
+}
+
+// configSaver writes target API changes back to the targets of a local yaml
+// config file, keeping its comments. the file is replaced whole, so the
+// watcher reloads it like any other edit.
+type configSaver struct {
+	path string
+	// slug is set with normalize_names, when the file's target names are
+	// matched by their slug.
+	slug bool
+	mu   sync.Mutex
+}
+
+// newConfigSaver returns a configSaver for the config at path, which must be
+// a local yaml file.
+func newConfigSaver(path, format string, fromFile bool) (*configSaver, error) {
+	format, err := configFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+	if !fromFile || remoteConfig(path) || format != "yaml" {
+		return nil, errors.New("persist_targets needs a local yaml config file")
+	}
+	return &configSaver{path: path}, nil
+}
+
+func (s *configSaver) SaveTarget(_ context.Context, name string, spec []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("parsing config: not a mapping")
+	}
+	root := doc.Content[0]
+
+	targets := mappingValue(root, "targets")
+	if targets == nil {
+		if spec == nil {
+			return nil
+		}
+		targets = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "targets"}, targets)
+	}
+	i := -1
+	for j, t := range targets.Content {
+		if n := mappingValue(t, "name"); n != nil && s.sameName(n.Value, name) {
+			i = j
+			break
+		}
+	}
+
+	switch {
+	case spec == nil && i < 0:
+		return nil
+	case spec == nil:
+		targets.Content = append(targets.Content[:i], targets.Content[i+1:]...)
+	default:
+		var entry yaml.Node
+		if err := yaml.Unmarshal(spec, &entry); err != nil {
+			return fmt.Errorf("parsing target: %w", err)
+		}
+		t := entry.Content[0]
+		blockStyle(t)
+		if i < 0 {
+			targets.Content = append(targets.Content, t)
+		} else {
+			targets.Content[i] = t
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return replaceFile(s.path, buf.Bytes())
+}
+
+// mappingValue returns the value of key in a yaml mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sameName reports whether a config target name is checked under name.
+func (s *configSaver) sameName(raw, name string) bool {
+	if !s.slug {
+		return raw == name
+	}
+	slug, err := kenko.SlugName(raw)
+	if err != nil {
+		return raw == name
+	}
+	return slug == name
+}
+
+// blockStyle clears the flow and quoting styles a json spec decodes with, so
+// it is written like the rest of the file. strings that need quotes keep
+// them.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// replaceFile writes data to a temporary file beside path and renames it
+// over path, keeping its permissions.
+func replaceFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".kenko-config-*")
+	if err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := f.Chmod(info.Mode().Perm()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
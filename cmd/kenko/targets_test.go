@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	kenko "github.com/aidantrabs/kenko"
+)
+
+func TestDecodeTarget(t *testing.T) {
+	tgt, err := decodeTarget([]byte(`{"name":"api","url":"https://api.example.com","interval":"10s",
+		"retries":2,"severity":"critical","headers":{"X-Key":"1"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tgt.Name != "api" || tgt.Interval != 10*time.Second || tgt.Retries != 2 ||
+		tgt.Severity != kenko.SeverityCritical || tgt.Header.Get("X-Key") != "1" {
+		t.Errorf("target = %+v", tgt)
+	}
+
+	for _, tt := range []struct {
+		spec string
+		want string
+	}{
+		{`{"url":"https://api.example.com"}`, "name: required"},
+		{`{"name":"api","url":"https://api.example.com","retrys":2}`, "did you mean retries?"},
+		{`{"name":"api","url":"https://api.example.com","severity":"urgent"}`, "must be one of"},
+		{`{"name":"api","url":"ftp://api.example.com"}`, `invalid target "api"`},
+		{`{"name":"api","headers_file":{"X-Key":"/etc/shadow"},"url":"https://api.example.com"}`, "unknown field"},
+		{`["api"]`, "must be an object"},
+		{`{"name":"api","url":"https://api.example.com","auth":{"refresh_command":["sh","-c","id"]}}`, "auth.refresh_command cannot be set"},
+		{`{"name":"api","url":"https://api.example.com","auth":{"refresh_url":"http://169.254.169.254/token"}}`, "auth.refresh_url cannot be set"},
+		{`{"name":"api","url":"https://api.example.com","source_addr":"10.0.0.1"}`, "source_addr cannot be set"},
+		{`{"name":"api","url":"https://api.example.com","source_interface":"eth0"}`, "source_interface cannot be set"},
+	} {
+		_, err := decodeTarget([]byte(tt.spec))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func TestNewConfigSaver(t *testing.T) {
+	if _, err := newConfigSaver("config.yaml", "", true); err != nil {
+		t.Errorf("local yaml: %v", err)
+	}
+	for _, tt := range []struct {
+		path, format string
+		fromFile     bool
+	}{
+		{"config.json", "", true},
+		{"config.yaml", "toml", true},
+		{"https://config.example.com/kenko.yaml", "", true},
+		{"config.yaml", "", false},
+	} {
+		if _, err := newConfigSaver(tt.path, tt.format, tt.fromFile); err == nil {
+			t.Errorf("%+v: expected an error", tt)
+		}
+	}
+}
+
+func TestConfigSaver(t *testing.T) {
+	path := writeConfig(t, `port: 6969
+check_interval: 30s
+check_timeout: 5s
+normalize_names: true
+# the services we check
+targets:
+  - name: Main API # the public one
+    url: https://api.example.com
+  - name: web
+    url: https://example.com
+`)
+	s := &configSaver{path: path, slug: true}
+	ctx := context.Background()
+
+	if err := s.SaveTarget(ctx, "docs", []byte(`{"name":"docs","url":"https://docs.example.com","interval":"1m","enabled":false}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveTarget(ctx, "main-api", []byte(`{"name":"Main API","url":"https://api.example.com/v2"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveTarget(ctx, "web", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveTarget(ctx, "missing", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# the services we check") {
+		t.Errorf("config lost its comments:\n%s", data)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if len(cfg.Targets) != 2 {
+		t.Fatalf("targets = %+v, want Main API and docs", cfg.Targets)
+	}
+	if api := cfg.Targets[0]; api.Name != "Main API" || api.URL != "https://api.example.com/v2" {
+		t.Errorf("api = %+v, want the edited url", api)
+	}
+	if docs := cfg.Targets[1]; docs.Name != "docs" || docs.Interval != time.Minute || docs.Enabled == nil || *docs.Enabled {
+		t.Errorf("docs = %+v", docs)
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// vaultPrefix marks a secret config value read from vault, e.g.
+	// vault:secret/data/kenko#api_token.
+	vaultPrefix = "vault:"
+
+	// vaultKey names the config block that configures the vault client.
+	vaultKey = "vault"
+
+	defaultVaultJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// vaultRenewInterval is how often tokens and leases are checked for
+	// renewal. each is renewed once half of its ttl has passed.
+	vaultRenewInterval = 10 * time.Second
+)
+
+type vaultConfig struct {
+	Address   string `yaml:"address"`
+	Namespace string `yaml:"namespace"`
+	Auth      string `yaml:"auth" schema:"enum=token|kubernetes"`
+	TokenFile string `yaml:"token_file"`
+	Role      string `yaml:"role"`
+	Mount     string `yaml:"mount"`
+	JWTFile   string `yaml:"jwt_file"`
+}
+
+// vaultClients caches a client per vault config, so reloads reuse its token
+// and renewVault keeps every token and lease alive.
+var vaultClients = struct {
+	sync.Mutex
+	m map[vaultConfig]*vaultClient
+}{m: make(map[vaultConfig]*vaultClient)}
+
+// resolveVaultRefs replaces every vault:<path>#<key> value of a secret field
+// in a decoded config document with the key read from vault. the vault block
+// of the document configures the client; VAULT_ADDR, VAULT_TOKEN and
+// VAULT_NAMESPACE fill in what it leaves out. each path is read once.
+func resolveVaultRefs(s *schema, doc any) error {
+	var client *vaultClient
+	secrets := make(map[string]map[string]any)
+
+	return walkSecrets(s, "", doc, func(path, value string) (string, error) {
+		ref, ok := strings.CutPrefix(value, vaultPrefix)
+		if !ok {
+			return value, nil
+		}
+		secretPath, key, ok := strings.Cut(ref, "#")
+		if !ok || secretPath == "" || key == "" {
+			return "", fmt.Errorf("%s: vault reference must be vault:<path>#<key>, got %q", path, value)
+		}
+
+		if client == nil {
+			var err error
+			if client, err = vaultFromDoc(doc); err != nil {
+				return "", fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		data, ok := secrets[secretPath]
+		if !ok {
+			var err error
+			if data, err = client.read(context.Background(), secretPath); err != nil {
+				return "", fmt.Errorf("%s: %w", path, err)
+			}
+			secrets[secretPath] = data
+		}
+
+		switch v := data[key].(type) {
+		case string:
+			return v, nil
+		case nil:
+			return "", fmt.Errorf("%s: vault secret %s has no key %q", path, secretPath, key)
+		default:
+			return fmt.Sprint(v), nil
+		}
+	})
+}
+
+// walkSecrets calls fn with the value of every secret string in a decoded
+// config document, replacing it with the result.
+func walkSecrets(s *schema, path string, v any, fn func(path, value string) (string, error)) error {
+	if s == nil {
+		return nil
+	}
+
+	switch v := v.(type) {
+	case []any:
+		for i, item := range v {
+			if err := walkSecrets(s.Items, fmt.Sprintf("%s[%d]", path, i), item, fn); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			if str, ok := v[k].(string); ok && s.secretKey(k) {
+				resolved, err := fn(join(path, k), str)
+				if err != nil {
+					return err
+				}
+				v[k] = resolved
+				continue
+			}
+			prop := s.Properties[k]
+			if prop == nil {
+				prop = s.Values
+			}
+			if err := walkSecrets(prop, join(path, k), v[k], fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// vaultFromDoc returns the cached client for the document's vault block,
+// logging in on first use.
+func vaultFromDoc(doc any) (*vaultClient, error) {
+	var cfg vaultConfig
+	if root, ok := doc.(map[string]any); ok && root[vaultKey] != nil {
+		data, err := yaml.Marshal(root[vaultKey])
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("vault: %w", err)
+		}
+	}
+	cfg.Address = strings.TrimRight(cmp.Or(cfg.Address, os.Getenv("VAULT_ADDR")), "/")
+	cfg.Namespace = cmp.Or(cfg.Namespace, os.Getenv("VAULT_NAMESPACE"))
+	cfg.Auth = cmp.Or(cfg.Auth, "token")
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("vault: address is not set; set vault.address or VAULT_ADDR")
+	}
+	if cfg.Auth != "token" && cfg.Auth != "kubernetes" {
+		return nil, fmt.Errorf("vault: auth must be token or kubernetes, got %q", cfg.Auth)
+	}
+	if cfg.Auth == "kubernetes" {
+		if cfg.Role == "" {
+			return nil, fmt.Errorf("vault: kubernetes auth needs a role")
+		}
+		cfg.Mount = cmp.Or(cfg.Mount, "kubernetes")
+		cfg.JWTFile = cmp.Or(cfg.JWTFile, defaultVaultJWTFile)
+	}
+
+	vaultClients.Lock()
+	defer vaultClients.Unlock()
+	if c, ok := vaultClients.m[cfg]; ok {
+		return c, nil
+	}
+	c := &vaultClient{cfg: cfg, http: &http.Client{Timeout: 10 * time.Second}, leases: make(map[string]vaultLease)}
+	if err := c.login(context.Background()); err != nil {
+		return nil, err
+	}
+	vaultClients.m[cfg] = c
+	return c, nil
+}
+
+// vaultClient reads secrets from vault over its http api, keeping its token
+// and the leases of the secrets it read renewed.
+type vaultClient struct {
+	cfg  vaultConfig
+	http *http.Client
+
+	mu     sync.Mutex
+	token  vaultLease
+	leases map[string]vaultLease
+}
+
+// vaultLease is a token or secret lease and when it is next due for renewal.
+type vaultLease struct {
+	id        string
+	renewable bool
+	renewAt   time.Time
+}
+
+func newLease(id string, ttlSeconds int, renewable bool, now time.Time) vaultLease {
+	ttl := time.Duration(ttlSeconds) * time.Second
+	return vaultLease{id: id, renewable: renewable && ttl > 0, renewAt: now.Add(ttl / 2)}
+}
+
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// login obtains a token: the configured one for token auth, looking up its
+// ttl, or one from logging in with the pod's service account for kubernetes
+// auth.
+func (c *vaultClient) login(ctx context.Context) error {
+	now := time.Now()
+	switch c.cfg.Auth {
+	case "kubernetes":
+		jwt, err := readSecretFile(c.cfg.JWTFile)
+		if err != nil {
+			return fmt.Errorf("vault: reading service account token: %w", err)
+		}
+		resp, err := c.do(ctx, http.MethodPost, "auth/"+c.cfg.Mount+"/login", "", map[string]string{"role": c.cfg.Role, "jwt": jwt})
+		if err != nil {
+			return fmt.Errorf("vault: kubernetes login: %w", err)
+		}
+		if resp.Auth == nil || resp.Auth.ClientToken == "" {
+			return fmt.Errorf("vault: kubernetes login returned no token")
+		}
+		c.setToken(newLease(resp.Auth.ClientToken, resp.Auth.LeaseDuration, resp.Auth.Renewable, now))
+		return nil
+	default:
+		token := os.Getenv("VAULT_TOKEN")
+		if c.cfg.TokenFile != "" {
+			var err error
+			if token, err = readSecretFile(c.cfg.TokenFile); err != nil {
+				return fmt.Errorf("vault: reading token: %w", err)
+			}
+		}
+		if token == "" {
+			return fmt.Errorf("vault: no token; set vault.token_file or VAULT_TOKEN")
+		}
+		resp, err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", token, nil)
+		if err != nil {
+			return fmt.Errorf("vault: token lookup: %w", err)
+		}
+		ttl, _ := resp.Data["ttl"].(float64)
+		renewable, _ := resp.Data["renewable"].(bool)
+		c.setToken(newLease(token, int(ttl), renewable, now))
+		return nil
+	}
+}
+
+func (c *vaultClient) setToken(l vaultLease) {
+	c.mu.Lock()
+	c.token = l
+	c.mu.Unlock()
+}
+
+func (c *vaultClient) currentToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token.id
+}
+
+// read returns the data of the secret at path, unwrapping kv version 2
+// responses, and tracks its lease when it has a renewable one.
+func (c *vaultClient) read(ctx context.Context, path string) (map[string]any, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, c.currentToken(), nil)
+	if err != nil && c.cfg.Auth == "kubernetes" {
+		// the token may have expired while kenko was idle; log in again once
+		if c.login(ctx) == nil {
+			resp, err = c.do(ctx, http.MethodGet, path, c.currentToken(), nil)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vault: reading %s: %w", path, err)
+	}
+	if resp.LeaseID != "" && resp.Renewable {
+		c.mu.Lock()
+		c.leases[resp.LeaseID] = newLease(resp.LeaseID, resp.LeaseDuration, true, time.Now())
+		c.mu.Unlock()
+	}
+
+	// kv version 2 nests the secret under data.data next to its metadata
+	if inner, ok := resp.Data["data"].(map[string]any); ok {
+		if _, ok := resp.Data["metadata"]; ok {
+			return inner, nil
+		}
+	}
+	return resp.Data, nil
+}
+
+// renew renews the token and secret leases that are due. a token that can no
+// longer be renewed is replaced by logging in again.
+func (c *vaultClient) renew(ctx context.Context, now time.Time) error {
+	c.mu.Lock()
+	token := c.token
+	var due []vaultLease
+	for _, l := range c.leases {
+		if !now.Before(l.renewAt) {
+			due = append(due, l)
+		}
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	if token.renewable && !now.Before(token.renewAt) {
+		resp, err := c.do(ctx, http.MethodPost, "auth/token/renew-self", token.id, map[string]string{})
+		switch {
+		case err == nil && resp.Auth != nil:
+			c.setToken(newLease(token.id, resp.Auth.LeaseDuration, resp.Auth.Renewable, now))
+		case c.cfg.Auth == "kubernetes":
+			if err := c.login(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			errs = append(errs, fmt.Errorf("vault: renewing token: %w", err))
+		}
+	}
+
+	for _, l := range due {
+		resp, err := c.do(ctx, http.MethodPut, "sys/leases/renew", c.currentToken(), map[string]string{"lease_id": l.id})
+		c.mu.Lock()
+		if err != nil || !resp.Renewable {
+			// an expired or revoked lease is picked up again by the next reload
+			delete(c.leases, l.id)
+		} else {
+			c.leases[l.id] = newLease(l.id, resp.LeaseDuration, true, now)
+		}
+		c.mu.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("vault: renewing lease %s: %w", l.id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *vaultClient) do(ctx context.Context, method, path, token string, body any) (*vaultResponse, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.Address+"/v1/"+strings.TrimPrefix(path, "/"), r)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out vaultResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("status %d: decoding response: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode >= 400 {
+		if len(out.Errors) > 0 {
+			return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(out.Errors, "; "))
+		}
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return &out, nil
+}
+
+// renewVault keeps the tokens and leases of every vault client renewed
+// until ctx is cancelled.
+func renewVault(ctx context.Context, logger *slog.Logger) {
+	ticker := time.NewTicker(vaultRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			vaultClients.Lock()
+			clients := make([]*vaultClient, 0, len(vaultClients.m))
+			for _, c := range vaultClients.m {
+				clients = append(clients, c)
+			}
+			vaultClients.Unlock()
+
+			for _, c := range clients {
+				if err := c.renew(ctx, now); err != nil {
+					logger.Warn("vault renewal failed", "address", c.cfg.Address, "error", err)
+				}
+			}
+		}
+	}
+}
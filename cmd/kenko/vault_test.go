@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault serves the slice of the vault api kenko uses.
+type fakeVault struct {
+	mu    sync.Mutex
+	calls map[string]int
+	token string
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	t.Helper()
+	v := &fakeVault{calls: make(map[string]int), token: "root"}
+	srv := httptest.NewServer(v)
+	t.Cleanup(srv.Close)
+	return v, srv
+}
+
+func (v *fakeVault) count(path string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.calls[path]
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	v.calls[r.URL.Path]++
+	token := v.token
+	v.mu.Unlock()
+
+	if r.URL.Path == "/v1/auth/kubernetes/login" {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role"] != "kenko" || body["jwt"] != "service-account-jwt" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": 60, "renewable": true}})
+		return
+	}
+	if r.Header.Get("X-Vault-Token") != token {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/auth/token/lookup-self":
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"ttl": 120, "renewable": true}})
+	case "/v1/auth/token/renew-self":
+		json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": 120, "renewable": true}})
+	case "/v1/secret/data/kenko":
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"data":     map[string]any{"redis": "redis-pass", "api_token": "Bearer abc", "port": 6380},
+			"metadata": map[string]any{"version": 3},
+		}})
+	case "/v1/database/creds/kenko":
+		json.NewEncoder(w).Encode(map[string]any{"lease_id": "database/creds/kenko/1", "lease_duration": 60, "renewable": true, "data": map[string]any{"password": "dynamic"}})
+	case "/v1/sys/leases/renew":
+		json.NewEncoder(w).Encode(map[string]any{"lease_id": "database/creds/kenko/1", "lease_duration": 60, "renewable": true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"errors": []string{}})
+	}
+}
+
+func TestLoadConfig_VaultToken(t *testing.T) {
+	v, srv := newFakeVault(t)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	cfg, err := loadConfig(writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+vault:
+  address: `+srv.URL+`
+redis_addr: localhost:6379
+redis_password: vault:secret/data/kenko#redis
+targets:
+  - name: api
+    url: https://api.example.com
+    headers:
+      Authorization: vault:secret/data/kenko#api_token
+      X-Plain: vault-is-not-a-reference
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RedisPassword != "redis-pass" || cfg.Targets[0].Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("redis_password = %q, headers = %v", cfg.RedisPassword, cfg.Targets[0].Headers)
+	}
+	if got := cfg.Targets[0].Headers["X-Plain"]; got != "vault-is-not-a-reference" {
+		t.Errorf("X-Plain = %q", got)
+	}
+	if n := v.count("/v1/secret/data/kenko"); n != 1 {
+		t.Errorf("secret read %d times, want once", n)
+	}
+}
+
+func TestLoadConfig_VaultKubernetes(t *testing.T) {
+	v, srv := newFakeVault(t)
+	v.token = "k8s-token"
+	jwt := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwt, []byte("service-account-jwt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(writeConfig(t, `
+port: 8080
+check_interval: 10s
+check_timeout: 3s
+vault:
+  address: `+srv.URL+`
+  auth: kubernetes
+  role: kenko
+  jwt_file: `+jwt+`
+redis_addr: localhost:6379
+redis_password: vault:database/creds/kenko#password
+targets:
+  - name: api
+    url: https://api.example.com
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RedisPassword != "dynamic" {
+		t.Errorf("redis_password = %q", cfg.RedisPassword)
+	}
+
+	client, err := vaultFromDoc(map[string]any{"vault": map[string]any{"address": srv.URL, "auth": "kubernetes", "role": "kenko", "jwt_file": jwt}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := v.count("/v1/auth/kubernetes/login"); n != 1 {
+		t.Errorf("logged in %d times, want the cached client reused", n)
+	}
+
+	// nothing is due yet
+	if err := client.renew(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if v.count("/v1/auth/token/renew-self") != 0 || v.count("/v1/sys/leases/renew") != 0 {
+		t.Error("renewed before half the ttl passed")
+	}
+
+	if err := client.renew(context.Background(), time.Now().Add(45*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if v.count("/v1/auth/token/renew-self") != 1 || v.count("/v1/sys/leases/renew") != 1 {
+		t.Errorf("calls = %v, want the token and lease renewed", v.calls)
+	}
+}
+
+func TestLoadConfig_VaultErrors(t *testing.T) {
+	_, srv := newFakeVault(t)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("VAULT_ADDR", "")
+
+	base := "port: 8080\ncheck_interval: 10s\ncheck_timeout: 3s\nredis_addr: localhost:6379\ntargets:\n  - name: api\n    url: https://api.example.com\n"
+	tests := map[string]struct {
+		config  string
+		wantErr string
+	}{
+		"no address":   {base + "redis_password: vault:secret/data/kenko#redis\n", "VAULT_ADDR"},
+		"no key":       {base + "vault:\n  address: " + srv.URL + "\nredis_password: vault:secret/data/kenko\n", "vault:<path>#<key>"},
+		"missing key":  {base + "vault:\n  address: " + srv.URL + "\nredis_password: vault:secret/data/kenko#nope\n", `no key "nope"`},
+		"missing path": {base + "vault:\n  address: " + srv.URL + "\nredis_password: vault:secret/data/other#x\n", "status 404"},
+		"no role":      {base + "vault:\n  address: " + srv.URL + "\n  auth: kubernetes\nredis_password: vault:secret/data/kenko#redis\n", "needs a role"},
+	}
+	for name, tt := range tests {
+		_, err := loadConfig(writeConfig(t, tt.config))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+
+	// a fresh server, as the client for srv is cached with its valid token
+	_, other := newFakeVault(t)
+	t.Setenv("VAULT_TOKEN", "wrong")
+	_, err := loadConfig(writeConfig(t, base+"vault:\n  address: "+other.URL+"/\nredis_password: vault:secret/data/kenko#redis\n"))
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("bad token err = %v", err)
+	}
+}
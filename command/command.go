@@ -0,0 +1,182 @@
+// Package command is a kenko Notifier that runs a command on each status
+// change, for automation with no native integration, such as restarting a
+// service or opening a ticket. the notification is passed in KENKO_*
+// environment variables and as JSON on standard input.
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// maxOutput caps the output of a failed command kept in its error.
+const maxOutput = 512
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithEnv adds a variable to the command's environment, which otherwise is
+// kenko's own and the KENKO_* variables.
+func WithEnv(key, value string) Option {
+	return func(n *Notifier) { n.env = append(n.env, key+"="+value) }
+}
+
+// WithDir sets the working directory of the command (default kenko's).
+func WithDir(dir string) Option {
+	return func(n *Notifier) { n.dir = dir }
+}
+
+// Notifier runs a command for every notification.
+type Notifier struct {
+	argv []string
+	env  []string
+	dir  string
+}
+
+// New creates a Notifier running argv, a program and its arguments, which is
+// not run through a shell. a command that runs longer than the notification
+// timeout is killed and retried.
+func New(argv []string, opts ...Option) *Notifier {
+	n := &Notifier{argv: argv}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Payload is the JSON written to the command's standard input.
+type Payload struct {
+	Target   string       `json:"target"`
+	Previous kenko.Status `json:"previous"`
+	Status   kenko.Status `json:"status"`
+	At       time.Time    `json:"at"`
+	// Outage is set when the target recovered.
+	Outage *kenko.Outage `json:"outage,omitempty"`
+	// Repeat counts the reminders sent while the target stays down.
+	Repeat int `json:"repeat,omitempty"`
+	// Slow is set for a latency alert rather than a status change.
+	Slow *kenko.SlowAlert `json:"slow,omitempty"`
+	// Test is set for notifications sent through the notifier test API.
+	Test   bool         `json:"test,omitempty"`
+	Uptime float64      `json:"uptime"`
+	Result kenko.Result `json:"result"`
+}
+
+func payload(n kenko.Notification) Payload {
+	return Payload{
+		Target:   n.Target,
+		Previous: n.Previous,
+		Status:   n.Status,
+		At:       n.At,
+		Outage:   n.Outage,
+		Repeat:   n.Repeat,
+		Slow:     n.Slow,
+		Test:     n.Test,
+		Uptime:   n.Uptime,
+		Result:   n.Result,
+	}
+}
+
+// environ returns the KENKO_* variables the command is run with for n.
+func environ(n kenko.Notification) []string {
+	r := n.Result
+	env := []string{
+		"KENKO_TARGET=" + n.Target,
+		"KENKO_STATUS=" + string(n.Status),
+		"KENKO_PREVIOUS=" + string(n.Previous),
+		"KENKO_AT=" + n.At.UTC().Format(time.RFC3339),
+		"KENKO_URL=" + r.URL,
+		"KENKO_ERROR=" + r.Error,
+		"KENKO_STATUS_CODE=" + strconv.Itoa(r.StatusCode),
+		"KENKO_LATENCY_MS=" + strconv.FormatInt(r.Latency.Milliseconds(), 10),
+		"KENKO_GROUP=" + r.Group,
+		"KENKO_REGION=" + r.Region,
+		"KENKO_REPEAT=" + strconv.Itoa(n.Repeat),
+		"KENKO_TEST=" + strconv.FormatBool(n.Test),
+	}
+	if o := n.Outage; o != nil {
+		env = append(env, "KENKO_OUTAGE_SECONDS="+strconv.FormatInt(int64(o.Duration.Seconds()), 10))
+	}
+	if n.Slow != nil {
+		env = append(env, "KENKO_SLOW="+strconv.FormatBool(!n.Slow.Resolved))
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.Labels)) {
+		env = append(env, "KENKO_LABEL_"+envName(k)+"="+r.Labels[k])
+	}
+	return env
+}
+
+// Notify runs the command for n and waits for it to exit. a non-zero exit
+// status is retried; a command that cannot be found is not.
+func (c *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	if len(c.argv) == 0 {
+		return kenko.Permanent(errors.New("command: no command"))
+	}
+	stdin, err := json.Marshal(payload(n))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("command: marshal: %w", err))
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.argv[0], c.argv[1:]...)
+	cmd.Dir = c.dir
+	cmd.Env = append(append(os.Environ(), environ(n)...), c.env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// a killed command's children may hold the output open
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	msg := strings.TrimSpace(out.String())
+	if len(msg) > maxOutput {
+		msg = msg[len(msg)-maxOutput:]
+	}
+	if msg != "" {
+		err = fmt.Errorf("command: %s: %w: %s", c.argv[0], err, msg)
+	} else {
+		err = fmt.Errorf("command: %s: %w", c.argv[0], err)
+	}
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the command line, variables, and input Notify would run
+// the command with for n.
+func (c *Notifier) Preview(n kenko.Notification) (string, error) {
+	stdin, err := json.MarshalIndent(payload(n), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("command: marshal: %w", err)
+	}
+	lines := append([]string{strings.Join(c.argv, " ")}, environ(n)...)
+	return strings.Join(lines, "\n") + "\n\n" + string(stdin), nil
+}
+
+// envName turns a label name into the part of a variable name after
+// KENKO_LABEL_, e.g. "app.kubernetes.io/name" into APP_KUBERNETES_IO_NAME.
+func envName(s string) string {
+	b := []byte(strings.ToUpper(s))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+func notification() kenko.Notification {
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	return kenko.Notification{
+		Target:   "api",
+		Previous: kenko.StatusHealthy,
+		Status:   kenko.StatusUnhealthy,
+		At:       at,
+		Result: kenko.Result{
+			Target: "api", URL: "https://api.example.com/health", Status: kenko.StatusUnhealthy, StatusCode: 503, Error: "status 503",
+			Latency: 250 * time.Millisecond, Labels: map[string]string{"app.kubernetes.io/name": "api"}, CheckedAt: at,
+		},
+	}
+}
+
+func TestNotify(t *testing.T) {
+	dir := t.TempDir()
+	script := `echo "$KENKO_TARGET $KENKO_STATUS $KENKO_PREVIOUS $KENKO_STATUS_CODE $KENKO_LATENCY_MS $KENKO_LABEL_APP_KUBERNETES_IO_NAME $RUNBOOK" > env; cat > stdin`
+	n := New([]string{"sh", "-c", script}, WithDir(dir), WithEnv("RUNBOOK", "restart-api"))
+	if err := n.Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(env)); got != "api unhealthy healthy 503 250 api restart-api" {
+		t.Errorf("env = %q", got)
+	}
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Payload
+	if err := json.Unmarshal(stdin, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Target != "api" || p.Status != kenko.StatusUnhealthy || p.Result.Error != "status 503" {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	err := New([]string{"sh", "-c", "echo ticket service down >&2; exit 3"}).Notify(context.Background(), notification())
+	if err == nil || kenko.IsPermanent(err) || !strings.Contains(err.Error(), "exit status 3: ticket service down") {
+		t.Errorf("err = %v, want a retried failure with the output", err)
+	}
+
+	err = New([]string{"kenko-no-such-command"}).Notify(context.Background(), notification())
+	if !kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent error for a missing command", err)
+	}
+	if err := New(nil).Notify(context.Background(), notification()); !kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent error for no command", err)
+	}
+}
+
+func TestNotify_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := New([]string{"sleep", "5"}).Notify(ctx, notification())
+	if err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("err = %v after %s, want the command killed", err, time.Since(start))
+	}
+}
+
+func TestPreview(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Outage = &kenko.Outage{Duration: 90 * time.Second}
+	text, err := New([]string{"/usr/local/bin/kenko-hook", "--ticket"}).Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/usr/local/bin/kenko-hook --ticket\n", "KENKO_STATUS=healthy\n", "KENKO_OUTAGE_SECONDS=90\n", `"target": "api"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("preview %q does not contain %q", text, want)
+		}
+	}
+}
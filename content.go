@@ -0,0 +1,63 @@
+package kenko
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxBodyBytes bounds how much of a response body is read for inspection.
+const maxBodyBytes = 10 << 20
+
+// ContentHash configures response body change detection for a target.
+type ContentHash struct {
+	// Baseline is the expected hex sha256 of the body. when empty the body is
+	// compared against the one seen on the previous check.
+	Baseline string
+}
+
+// contentTracker remembers the last body hash seen per target.
+type contentTracker struct {
+	mu   sync.Mutex
+	last map[string]string
+}
+
+// swap stores sum as the latest hash for name and returns the previous one.
+func (t *contentTracker) swap(name, sum string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.last == nil {
+		t.last = make(map[string]string)
+	}
+	prev, ok := t.last[name]
+	t.last[name] = sum
+	return prev, ok
+}
+
+// checkContent hashes the body and marks the result as changed when it
+// differs from the target's baseline or previous hash.
+func (c *Checker) checkContent(target Target, body io.Reader, result *Result) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.LimitReader(body, maxBodyBytes)); err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = fmt.Sprintf("reading body: %v", err)
+		return
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	result.BodyHash = sum
+
+	prev, seen := c.content.swap(target.Name, sum)
+	if baseline := target.ContentHash.Baseline; baseline != "" {
+		result.Changed = !strings.EqualFold(sum, baseline)
+	} else {
+		result.Changed = seen && prev != sum
+	}
+
+	if result.Changed {
+		c.logger.Warn("content changed", "target", target.Name, "hash", sum)
+	}
+}
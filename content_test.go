@@ -0,0 +1,70 @@
+package kenko
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheck_ContentChangedFromPrevious(t *testing.T) {
+	body := "v1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("test", ts.URL, WithContentHash("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := c.check(context.Background(), c.targets[0])
+	if first.Changed {
+		t.Error("first check should not be flagged as changed")
+	}
+	if first.BodyHash == "" {
+		t.Error("expected body hash")
+	}
+
+	same := c.check(context.Background(), c.targets[0])
+	if same.Changed {
+		t.Error("unchanged body flagged as changed")
+	}
+
+	body = "v2"
+	changed := c.check(context.Background(), c.targets[0])
+	if !changed.Changed {
+		t.Error("expected changed body to be flagged")
+	}
+	if changed.Status != StatusHealthy {
+		t.Errorf("status = %q, want %q", changed.Status, StatusHealthy)
+	}
+}
+
+func TestCheck_ContentBaseline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("expected"))
+	}))
+	defer ts.Close()
+
+	sum := sha256.Sum256([]byte("expected"))
+	baseline := hex.EncodeToString(sum[:])
+
+	c, err := NewChecker(
+		WithTarget("match", ts.URL, WithContentHash(baseline)),
+		WithTarget("mismatch", ts.URL, WithContentHash("deadbeef")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Changed {
+		t.Error("body matching baseline flagged as changed")
+	}
+	if r := c.check(context.Background(), c.targets[1]); !r.Changed {
+		t.Error("body not matching baseline should be flagged")
+	}
+}
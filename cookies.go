@@ -0,0 +1,29 @@
+package kenko
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+)
+
+// cookieJars holds the persistent cookie jar of each target that keeps
+// cookies between checks.
+type cookieJars struct {
+	mu   sync.Mutex
+	jars map[string]http.CookieJar
+}
+
+func (j *cookieJars) get(name string) http.CookieJar {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.jars == nil {
+		j.jars = make(map[string]http.CookieJar)
+	}
+	jar, ok := j.jars[name]
+	if !ok {
+		jar, _ = cookiejar.New(nil) // only errors on a bad public suffix list
+		j.jars[name] = jar
+	}
+	return jar
+}
@@ -0,0 +1,80 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func sessionServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "s1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCheckSteps_CookiesShared(t *testing.T) {
+	ts := sessionServer()
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("flow", ts.URL, WithSteps(
+		Step{Name: "login", URL: ts.URL + "/login"},
+		Step{Name: "me", URL: ts.URL + "/me"},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+	if result.Status != StatusHealthy {
+		t.Errorf("status = %q, want %q (error: %s)", result.Status, StatusHealthy, result.Error)
+	}
+}
+
+func TestCheckSteps_JarFreshPerRun(t *testing.T) {
+	ts := sessionServer()
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("login", ts.URL+"/login", WithSteps(Step{URL: ts.URL + "/login"})),
+		WithTarget("me", ts.URL+"/me", WithSteps(Step{URL: ts.URL + "/me"})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = c.check(context.Background(), c.targets[0])
+	result := c.check(context.Background(), c.targets[1])
+	if result.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q: cookies leaked between targets", result.Status, StatusUnhealthy)
+	}
+}
+
+func TestCheck_KeepCookies(t *testing.T) {
+	ts := sessionServer()
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("flow", ts.URL, WithKeepCookies(), WithSteps(
+		Step{Name: "login", URL: ts.URL + "/login"},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.check(context.Background(), c.targets[0])
+
+	// a later plain check of the same target reuses the persisted session
+	me := c.targets[0]
+	me.Steps = nil
+	me.URL = ts.URL + "/me"
+	result := c.check(context.Background(), me)
+	if result.Status != StatusHealthy {
+		t.Errorf("status = %q, want %q", result.Status, StatusHealthy)
+	}
+}
@@ -0,0 +1,165 @@
+package kenko
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshTimeout bounds a single credential refresh.
+const refreshTimeout = 10 * time.Second
+
+// CredentialRefresher fetches a fresh secret for a target, e.g. a new bearer
+// token from an auth service.
+type CredentialRefresher func(ctx context.Context) (string, error)
+
+// Credential refreshes the header a target authenticates with. when a check is
+// rejected with 401 or 403 the secret is refreshed and the check retried once
+// before the target is reported unhealthy.
+type Credential struct {
+	// Header is the request header carrying the secret (default Authorization).
+	Header string
+	// Prefix is prepended to the refreshed secret, e.g. "Bearer ".
+	Prefix  string
+	Refresh CredentialRefresher
+}
+
+func (cr *Credential) header() string {
+	if cr.Header == "" {
+		return "Authorization"
+	}
+	return cr.Header
+}
+
+// WithCredentialRefresh refreshes the target's credential with cred.Refresh
+// when a check is rejected with 401 or 403, then retries the check.
+func WithCredentialRefresh(cred Credential) TargetOption {
+	return func(t *Target) { t.Credential = &cred }
+}
+
+// CommandRefresher runs argv and uses its trimmed standard output as the secret.
+func CommandRefresher(argv ...string) CredentialRefresher {
+	return func(ctx context.Context) (string, error) {
+		if len(argv) == 0 {
+			return "", fmt.Errorf("no refresh command")
+		}
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%s: %w: %s", argv[0], err, msg)
+			}
+			return "", fmt.Errorf("%s: %w", argv[0], err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+}
+
+// HTTPRefresher requests url with method and header and uses the response
+// body as the secret, or the value at jsonPath (e.g. "access_token") when set.
+func HTTPRefresher(client *http.Client, method, url string, header http.Header, jsonPath string) CredentialRefresher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return "", err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode >= 400 {
+			return "", fmt.Errorf("refresh endpoint returned status %d", resp.StatusCode)
+		}
+
+		if jsonPath == "" {
+			return strings.TrimSpace(string(body)), nil
+		}
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return "", fmt.Errorf("decoding json: %w", err)
+		}
+		return lookupJSON(doc, jsonPath)
+	}
+}
+
+// credentials holds the refreshed header value per target.
+type credentials struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (cs *credentials) get(name string) (string, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	v, ok := cs.values[name]
+	return v, ok
+}
+
+func (cs *credentials) set(name, value string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.values == nil {
+		cs.values = make(map[string]string)
+	}
+	cs.values[name] = value
+}
+
+// applyCredential sets the target's refreshed credential, if it has one,
+// over any static header of the same name.
+func (c *Checker) applyCredential(req *http.Request, target Target) {
+	if target.Credential == nil {
+		return
+	}
+	if v, ok := c.credentials.get(target.Name); ok {
+		req.Header.Set(target.Credential.header(), v)
+	}
+}
+
+// rejected reports whether the target refused the check's credentials.
+func rejected(r Result) bool {
+	return r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden
+}
+
+// checkWithRefresh checks the target and, when it rejects the credential,
+// refreshes it and checks again.
+func (c *Checker) checkWithRefresh(ctx context.Context, target Target) Result {
+	result := c.probe(ctx, target)
+	if target.Credential == nil || target.Credential.Refresh == nil || !rejected(result) {
+		return result
+	}
+
+	refreshCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	secret, err := target.Credential.Refresh(refreshCtx)
+	cancel()
+	if err != nil {
+		c.logger.Warn("credential refresh failed", "target", target.Name, "error", err)
+		result.Error = fmt.Sprintf("status %d; credential refresh failed: %v", result.StatusCode, err)
+		return result
+	}
+
+	c.credentials.set(target.Name, target.Credential.Prefix+secret)
+	c.logger.Info("credential refreshed, retrying check", "target", target.Name, "status_code", result.StatusCode)
+	return c.probe(ctx, target)
+}
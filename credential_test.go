@@ -0,0 +1,128 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func tokenServer(t *testing.T, want string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckWithRefresh_RetriesWithNewToken(t *testing.T) {
+	srv := tokenServer(t, "Bearer fresh")
+
+	refreshes := 0
+	c, err := NewChecker(WithTarget("api", srv.URL,
+		WithHeader("Authorization", "Bearer stale"),
+		WithCredentialRefresh(Credential{
+			Prefix: "Bearer ",
+			Refresh: func(context.Context) (string, error) {
+				refreshes++
+				return "fresh", nil
+			},
+		}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := c.Targets()[0]
+
+	for range 2 {
+		if r := c.checkWithRefresh(context.Background(), target); r.Status != StatusHealthy {
+			t.Fatalf("status = %q (%d), want healthy after refresh", r.Status, r.StatusCode)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1 with the token reused afterwards", refreshes)
+	}
+}
+
+func TestCheckWithRefresh_RefreshFails(t *testing.T) {
+	srv := tokenServer(t, "Bearer fresh")
+
+	c, err := NewChecker(WithTarget("api", srv.URL, WithCredentialRefresh(Credential{
+		Refresh: func(context.Context) (string, error) { return "", errors.New("vault sealed") },
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.checkWithRefresh(context.Background(), c.Targets()[0])
+	if r.Status != StatusUnhealthy || !strings.Contains(r.Error, "vault sealed") {
+		t.Errorf("result = %q %q, want unhealthy with the refresh error", r.Status, r.Error)
+	}
+}
+
+func TestCheckWithRefresh_OnlyOnAuthFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c, err := NewChecker(WithTarget("api", srv.URL, WithCredentialRefresh(Credential{
+		Refresh: func(context.Context) (string, error) {
+			t.Error("refresh called for a 500")
+			return "", nil
+		},
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.checkWithRefresh(context.Background(), c.Targets()[0])
+}
+
+func TestCommandRefresher(t *testing.T) {
+	got, err := CommandRefresher("echo", " token123 ")(context.Background())
+	if err != nil || got != "token123" {
+		t.Errorf("CommandRefresher = %q, %v; want token123", got, err)
+	}
+
+	if _, err := CommandRefresher("false")(context.Background()); err == nil {
+		t.Error("expected an error from a failing command")
+	}
+}
+
+func TestHTTPRefresher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Client") != "kenko" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"data":{"access_token":"abc"}}`))
+	}))
+	defer srv.Close()
+
+	refresh := HTTPRefresher(srv.Client(), http.MethodPost, srv.URL, http.Header{"X-Client": {"kenko"}}, "data.access_token")
+	got, err := refresh(context.Background())
+	if err != nil || got != "abc" {
+		t.Errorf("HTTPRefresher = %q, %v; want abc", got, err)
+	}
+}
+
+func TestSameTarget_Credential(t *testing.T) {
+	refresh := func(context.Context) (string, error) { return "", nil }
+	a := NewTarget("api", "https://example.com", WithCredentialRefresh(Credential{Prefix: "Bearer ", Refresh: refresh}))
+	b := NewTarget("api", "https://example.com", WithCredentialRefresh(Credential{Prefix: "Bearer ", Refresh: refresh}))
+	if !sameTarget(a, b) {
+		t.Error("expected targets differing only in refresher identity to be the same")
+	}
+
+	b.Credential.Header = "X-Token"
+	if sameTarget(a, b) {
+		t.Error("expected a changed credential header to count as a change")
+	}
+}
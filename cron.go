@@ -0,0 +1,216 @@
+package kenko
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression: minute, hour, day of
+// month, month, and day of week. each field is a bitset of the values it
+// matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// when both day fields are restricted a day matching either one
+	// matches, as in vixie cron.
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseCron parses a cron expression such as "30 2 * * 1-5" or "@daily". a
+// leading "CRON_TZ=Europe/Berlin " (or "TZ=") interprets it in that zone
+// instead of the local one. months and weekdays may be given by their
+// three-letter names, and 7 is Sunday too.
+func parseCron(expr string) (*cronSchedule, error) {
+	s := &cronSchedule{loc: time.Local}
+
+	spec := strings.TrimSpace(expr)
+	if rest, ok := cutTZ(spec); ok {
+		zone, fields, _ := strings.Cut(rest, " ")
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		s.loc = loc
+		spec = strings.TrimSpace(fields)
+	}
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	var err error
+	parse := func(field string, lo, hi int, names []string) uint64 {
+		if err != nil {
+			return 0
+		}
+		var set uint64
+		set, err = parseCronField(field, lo, hi, names)
+		if err != nil {
+			err = fmt.Errorf("cron %q: %w", expr, err)
+		}
+		return set
+	}
+	s.minute = parse(fields[0], 0, 59, nil)
+	s.hour = parse(fields[1], 0, 23, nil)
+	s.dom = parse(fields[2], 1, 31, nil)
+	s.month = parse(fields[3], 1, 12, monthNames)
+	s.dow = parse(fields[4], 0, 7, dayNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domAny = fields[2] == "*" || fields[2] == "?"
+	s.dowAny = fields[4] == "*" || fields[4] == "?"
+
+	if s.next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron %q never matches", expr)
+	}
+	return s, nil
+}
+
+// ValidateCron reports whether expr parses as a cron schedule, as used by
+// target schedules and maintenance windows.
+func ValidateCron(expr string) error {
+	_, err := parseCron(expr)
+	return err
+}
+
+func cutTZ(spec string) (string, bool) {
+	if rest, ok := strings.CutPrefix(spec, "CRON_TZ="); ok {
+		return rest, true
+	}
+	return strings.CutPrefix(spec, "TZ=")
+}
+
+// parseCronField parses a comma-separated list of values, ranges (a-b), and
+// steps (*/n or a-b/n) within [lo, hi].
+func parseCronField(field string, lo, hi int, names []string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+
+		var from, to int
+		switch {
+		case rng == "*" || rng == "?":
+			from, to = lo, hi
+		default:
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = cronValue(a, lo, hi, names); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = cronValue(b, lo, hi, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = hi
+			}
+			if to < from {
+				return 0, fmt.Errorf("range %q is backwards", rng)
+			}
+		}
+
+		n := 1
+		if hasStep {
+			var err error
+			if n, err = strconv.Atoi(step); err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step %q", step)
+			}
+		}
+		for v := from; v <= to; v += n {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func cronValue(s string, lo, hi int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i + lo, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("value %q must be between %d and %d", s, lo, hi)
+	}
+	return v, nil
+}
+
+// next returns the first minute after t that the schedule matches, or the
+// zero time when it does not match within five years, e.g. for "0 0 30 2 *".
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = s.nextHour(t)
+		case s.minute&(1<<t.Minute()) == 0:
+			// skip straight to the next matching minute of this hour
+			if rest := s.minute >> (t.Minute() + 1); rest != 0 {
+				t = t.Add(time.Duration(bits.TrailingZeros64(rest)+1) * time.Minute)
+			} else {
+				t = s.nextHour(t)
+			}
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// nextHour returns the start of the hour after t. it is computed on the wall
+// clock because zones with half-hour offsets don't start hours on multiples
+// of an hour.
+func (s *cronSchedule) nextHour(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<t.Weekday()) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
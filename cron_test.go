@@ -0,0 +1,57 @@
+package kenko
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	from := time.Date(2026, 3, 13, 10, 17, 30, 0, time.UTC) // a friday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"CRON_TZ=UTC * * * * *", time.Date(2026, 3, 13, 10, 18, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC */15 * * * *", time.Date(2026, 3, 13, 10, 30, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 9-17 * * mon-fri", time.Date(2026, 3, 13, 11, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 30 2 * * 1-5", time.Date(2026, 3, 16, 2, 30, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 * feb 0", time.Date(2027, 2, 7, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC 0 0 13 * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=UTC @monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Europe/Berlin 0 2 * * *", time.Date(2026, 3, 14, 2, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		s, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if got := s.next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: next = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"0 0 30 2 *",
+		"CRON_TZ=Nowhere/Special * * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("%q parsed", expr)
+		}
+	}
+}
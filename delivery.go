@@ -0,0 +1,128 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoDeliveries is returned when the store does not keep a delivery log.
+var ErrNoDeliveries = errors.New("kenko: store does not keep deliveries")
+
+// Delivery records what became of one notification sent to one notifier, so
+// it can later be shown whether a page actually went out.
+type Delivery struct {
+	Notifier string `json:"notifier"`
+	Target   string `json:"target"`
+	// Status is the status the notification was about, and Repeat counts the
+	// reminders as in Notification.
+	Status Status `json:"status"`
+	Repeat int    `json:"repeat,omitempty"`
+	// Outcome is NotificationDelivered, NotificationFailed,
+	// NotificationDropped, NotificationThrottled or NotificationSilenced.
+	Outcome string `json:"outcome"`
+	// At is when the outcome was known.
+	At time.Time `json:"at"`
+	// Attempts is how many times the notification was tried; dropped,
+	// throttled and silenced ones are never tried.
+	Attempts int `json:"attempts,omitempty"`
+	// Latency is how long the attempts took, including the waits between
+	// them.
+	Latency time.Duration `json:"latency"`
+	// Error is the error of the last failed attempt, if any, with request
+	// urls cut down to their host as they may hold a notifier's secret.
+	Error  string `json:"error,omitempty"`
+	Region string `json:"region,omitempty"`
+}
+
+// DeliveryQuery selects deliveries. zero fields leave the query open on that
+// side.
+type DeliveryQuery struct {
+	// Target and Notifier limit the deliveries to one target or notifier.
+	Target   string
+	Notifier string
+	// From and To bound the deliveries by when their outcome was known,
+	// inclusive.
+	From, To time.Time
+	// Limit caps how many deliveries are returned.
+	Limit int
+}
+
+// DeliveryStore is implemented by stores that keep a log of notification
+// deliveries.
+type DeliveryStore interface {
+	AddDelivery(ctx context.Context, d Delivery) error
+	// Deliveries returns the deliveries matching q, newest first.
+	Deliveries(ctx context.Context, q DeliveryQuery) ([]Delivery, error)
+}
+
+// DeliveryPruner is implemented by stores whose delivery log can be pruned
+// by age, for those that otherwise keep every delivery.
+type DeliveryPruner interface {
+	// PruneDeliveries removes the deliveries whose outcome was known before
+	// before and reports how many were removed.
+	PruneDeliveries(ctx context.Context, before time.Time) (int, error)
+}
+
+// Deliveries returns the recorded notification deliveries matching q, newest
+// first. it returns ErrNoDeliveries when the store keeps none.
+func (c *Checker) Deliveries(ctx context.Context, q DeliveryQuery) ([]Delivery, error) {
+	ds, ok := c.store.(DeliveryStore)
+	if !ok {
+		return nil, ErrNoDeliveries
+	}
+	return ds.Deliveries(ctx, q)
+}
+
+// recordDelivery reports the delivery's outcome and logs it in the store,
+// through the store breaker when there is one. the dispatcher calls it from
+// its own goroutine, off the path of the checks and notifications.
+func (c *Checker) recordDelivery(d Delivery) {
+	if nr, ok := c.metrics.(NotificationReporter); ok {
+		nr.ReportNotification(d.Notifier, d.Outcome)
+	}
+	if _, ok := c.store.(DeliveryStore); !ok {
+		return
+	}
+	ds := c.resultStore().(DeliveryStore)
+
+	// deliveries are also recorded while draining after the checker stopped
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	d.Region = c.region
+	if err := ds.AddDelivery(ctx, d); err != nil {
+		c.logger.Warn("failed to record delivery", "notifier", d.Notifier, "target", d.Target, "error", err)
+	}
+}
+
+// matches reports whether d is selected by q.
+func (q DeliveryQuery) matches(d Delivery) bool {
+	return (q.Target == "" || d.Target == q.Target) &&
+		(q.Notifier == "" || d.Notifier == q.Notifier) &&
+		(q.From.IsZero() || !d.At.Before(q.From)) &&
+		(q.To.IsZero() || !d.At.After(q.To))
+}
+
+// deliveryError renders err for a Delivery. the path and query of request
+// urls are left out, as for webhook and chat notifiers the url is the
+// secret.
+func deliveryError(err error) string {
+	msg := err.Error()
+	for e := err; e != nil; {
+		var uerr *url.Error
+		if !errors.As(e, &uerr) {
+			break
+		}
+		redacted := "redacted"
+		if u, perr := url.Parse(uerr.URL); perr == nil && u.Host != "" {
+			redacted = u.Scheme + "://" + u.Host + "/..."
+		}
+		msg = strings.ReplaceAll(msg, strconv.Quote(uerr.URL), strconv.Quote(redacted))
+		msg = strings.ReplaceAll(msg, uerr.URL, redacted)
+		e = uerr.Err
+	}
+	return msg
+}
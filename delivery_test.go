@@ -0,0 +1,134 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecker_RecordsDeliveries(t *testing.T) {
+	flaky := &flakyNotifier{}
+	flaky.fails.Store(1)
+	rejecting := &recordingNotifier{err: Permanent(errors.New("status 400"))}
+	c, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithNotifier("ops", flaky),
+		WithNotifier("chat", rejecting),
+		WithNotifyRetry(NotifyRetry{Backoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.dispatcher.enqueue(Notification{Target: "api", Status: StatusUnhealthy})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.dispatcher.run(ctx)
+
+	deliveries, err := c.Deliveries(context.Background(), DeliveryQuery{Target: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byNotifier := make(map[string]Delivery)
+	for _, d := range deliveries {
+		byNotifier[d.Notifier] = d
+	}
+	if d := byNotifier["ops"]; d.Outcome != NotificationDelivered || d.Attempts != 2 || d.Error != "connection reset" || d.Status != StatusUnhealthy || d.Latency <= 0 {
+		t.Errorf("ops delivery = %+v, want delivered on the second attempt", d)
+	}
+	if d := byNotifier["chat"]; d.Outcome != NotificationFailed || d.Attempts != 1 || d.Error != "status 400" {
+		t.Errorf("chat delivery = %+v, want failed once", d)
+	}
+	if only, _ := c.Deliveries(context.Background(), DeliveryQuery{Notifier: "chat"}); len(only) != 1 {
+		t.Errorf("chat deliveries = %+v, want one", only)
+	}
+}
+
+func TestMemoryStore_Deliveries(t *testing.T) {
+	m := NewMemoryStore()
+	ctx := context.Background()
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i := range maxMemoryDeliveries + 10 {
+		m.AddDelivery(ctx, Delivery{Notifier: "ops", Target: "api", At: at.Add(time.Duration(i) * time.Second)})
+	}
+
+	deliveries, _ := m.Deliveries(ctx, DeliveryQuery{From: at.Add(time.Duration(maxMemoryDeliveries) * time.Second), Limit: 5})
+	if len(deliveries) != 5 || !deliveries[0].At.Equal(at.Add(time.Duration(maxMemoryDeliveries+9)*time.Second)) {
+		t.Errorf("deliveries = %+v, want the newest 5", deliveries)
+	}
+	if all, _ := m.Deliveries(ctx, DeliveryQuery{}); len(all) != maxMemoryDeliveries {
+		t.Errorf("kept %d deliveries, want %d", len(all), maxMemoryDeliveries)
+	}
+	if other, _ := m.Deliveries(ctx, DeliveryQuery{Notifier: "chat"}); len(other) != 0 {
+		t.Errorf("chat deliveries = %+v, want none", other)
+	}
+}
+
+func TestHandleNotifications(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithNotifier("pager", &recordingNotifier{}), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	store.AddDelivery(context.Background(), Delivery{Notifier: "pager", Target: "api", Status: StatusUnhealthy, Outcome: NotificationFailed, At: at, Attempts: 3, Latency: 1500 * time.Millisecond, Error: "status 503"})
+
+	for query, want := range map[string]int{
+		"":                           http.StatusOK,
+		"?target=api":                http.StatusOK,
+		"?notifier=pager":            http.StatusOK,
+		"?target=nope":               http.StatusNotFound,
+		"?notifier=chat":             http.StatusNotFound,
+		"?to=noon":                   http.StatusBadRequest,
+		"?limit=0":                   http.StatusBadRequest,
+		"?from=2026-03-13T11:00:00Z": http.StatusOK,
+	} {
+		rec := httptest.NewRecorder()
+		HandleNotifications(c)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications"+query, nil))
+		if rec.Code != want {
+			t.Errorf("%q: status = %d, want %d", query, rec.Code, want)
+			continue
+		}
+		if want != http.StatusOK {
+			continue
+		}
+
+		var resp notificationsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Notifications) != 1 || resp.Notifications[0].Outcome != "failed" || resp.Notifications[0].LatencyMS != 1500 || resp.Notifications[0].Error != "status 503" {
+			t.Errorf("%q: notifications = %+v, want the failed page", query, resp.Notifications)
+		}
+	}
+
+	noDeliveries, _ := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(struct{ Store }{NewMemoryStore()}))
+	rec := httptest.NewRecorder()
+	HandleNotifications(noDeliveries)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("without a delivery store: status = %d, want %d", rec.Code, http.StatusNotImplemented)
+	}
+}
+
+func TestDeliveryError(t *testing.T) {
+	secret := "https://hooks.slack.com/services/T000/B000/XXXX?token=abc"
+	err := fmt.Errorf("slack: send: %w", &url.Error{Op: "Post", URL: secret, Err: errors.New("connection refused")})
+
+	got := deliveryError(err)
+	if strings.Contains(got, "XXXX") || strings.Contains(got, "token") {
+		t.Errorf("error = %q, leaks the url", got)
+	}
+	if want := `slack: send: Post "https://hooks.slack.com/...": connection refused`; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+	if got := deliveryError(errors.New("status 500")); got != "status 500" {
+		t.Errorf("plain error = %q", got)
+	}
+}
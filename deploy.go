@@ -0,0 +1,200 @@
+package kenko
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRecentSamples bounds the observations kept per target for deploy
+	// comparisons.
+	maxRecentSamples = 2000
+
+	// DefaultDeployWindow is the comparison window used when MarkDeploy is
+	// called without one.
+	DefaultDeployWindow = 10 * time.Minute
+
+	// a deploy regresses when its failure rate rises by more than
+	// failureRateSlack or its p95 latency grows past latencyRegression times
+	// the baseline.
+	failureRateSlack  = 0.05
+	latencyRegression = 1.5
+)
+
+// sample is one observed check, kept only for deploy comparisons.
+type sample struct {
+	at      time.Time
+	status  Status
+	latency time.Duration
+}
+
+// recentSamples keeps a bounded window of observations per target in memory.
+type recentSamples struct {
+	mu      sync.Mutex
+	samples map[string][]sample
+}
+
+func (rs *recentSamples) observe(r Result) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.samples == nil {
+		rs.samples = make(map[string][]sample)
+	}
+
+	s := append(rs.samples[r.Target], sample{at: r.CheckedAt, status: r.Status, latency: r.Latency})
+	if len(s) > maxRecentSamples {
+		s = s[len(s)-maxRecentSamples:]
+	}
+	rs.samples[r.Target] = s
+}
+
+// between returns the target's samples checked in [from, to).
+func (rs *recentSamples) between(name string, from, to time.Time) []sample {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var out []sample
+	for _, s := range rs.samples[name] {
+		if !s.at.Before(from) && s.at.Before(to) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// DeployStats summarizes the checks of a target over one comparison window.
+type DeployStats struct {
+	Checks      int           `json:"checks"`
+	Failures    int           `json:"failures"`
+	FailureRate float64       `json:"failure_rate"`
+	P50         time.Duration `json:"p50"`
+	P95         time.Duration `json:"p95"`
+}
+
+func summarize(samples []sample) DeployStats {
+	st := DeployStats{Checks: len(samples)}
+	if st.Checks == 0 {
+		return st
+	}
+
+	latencies := make([]time.Duration, 0, len(samples))
+	for _, s := range samples {
+		if s.status != StatusHealthy {
+			st.Failures++
+		}
+		latencies = append(latencies, s.latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	st.FailureRate = float64(st.Failures) / float64(st.Checks)
+	st.P50 = percentile(latencies, 0.50)
+	st.P95 = percentile(latencies, 0.95)
+	return st
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// Deploy is a deploy marker for a target. Baseline summarizes the Window
+// before At, which is compared against the Window after it.
+type Deploy struct {
+	Target   string        `json:"target"`
+	At       time.Time     `json:"at"`
+	Window   time.Duration `json:"window"`
+	Baseline DeployStats   `json:"baseline"`
+}
+
+// DeployComparison compares the checks after a deploy against its baseline.
+type DeployComparison struct {
+	Deploy
+	After DeployStats `json:"after"`
+	// Complete reports that the whole window after the deploy has elapsed.
+	Complete    bool     `json:"complete"`
+	Regressions []string `json:"regressions,omitempty"`
+}
+
+// deploys holds the latest deploy marker per target.
+type deploys struct {
+	mu     sync.Mutex
+	latest map[string]Deploy
+}
+
+// MarkDeploy records a deploy of the named target at the current time,
+// snapshotting the window before it as the baseline. a zero window uses
+// DefaultDeployWindow.
+func (c *Checker) MarkDeploy(name string, window time.Duration) (Deploy, error) {
+	if !c.hasTarget(name) {
+		return Deploy{}, fmt.Errorf("kenko: unknown target %q", name)
+	}
+	if window <= 0 {
+		window = DefaultDeployWindow
+	}
+
+	now := time.Now()
+	d := Deploy{
+		Target:   name,
+		At:       now,
+		Window:   window,
+		Baseline: summarize(c.recent.between(name, now.Add(-window), now)),
+	}
+
+	c.deploys.mu.Lock()
+	defer c.deploys.mu.Unlock()
+	if c.deploys.latest == nil {
+		c.deploys.latest = make(map[string]Deploy)
+	}
+	c.deploys.latest[name] = d
+	return d, nil
+}
+
+// CompareDeploy compares the checks since the target's latest deploy marker
+// against its baseline. ok is false when no deploy has been marked.
+func (c *Checker) CompareDeploy(name string) (DeployComparison, bool) {
+	c.deploys.mu.Lock()
+	d, ok := c.deploys.latest[name]
+	c.deploys.mu.Unlock()
+	if !ok {
+		return DeployComparison{}, false
+	}
+
+	now := time.Now()
+	end := d.At.Add(d.Window)
+	if now.Before(end) {
+		end = now
+	}
+
+	cmp := DeployComparison{
+		Deploy:   d,
+		After:    summarize(c.recent.between(name, d.At, end)),
+		Complete: !now.Before(d.At.Add(d.Window)),
+	}
+	cmp.Regressions = regressions(d.Baseline, cmp.After)
+	return cmp, true
+}
+
+func regressions(before, after DeployStats) []string {
+	if before.Checks == 0 || after.Checks == 0 {
+		return nil
+	}
+
+	var out []string
+	if after.FailureRate-before.FailureRate > failureRateSlack {
+		out = append(out, fmt.Sprintf("failure rate %.1f%% → %.1f%%", before.FailureRate*100, after.FailureRate*100))
+	}
+	if before.P95 > 0 && float64(after.P95) > latencyRegression*float64(before.P95) {
+		out = append(out, fmt.Sprintf("p95 latency %s → %s", before.P95, after.P95))
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package kenko
+
+import (
+	"testing"
+	"time"
+)
+
+func observeN(rs *recentSamples, target string, at time.Time, n int, status Status, latency time.Duration) {
+	for i := range n {
+		rs.observe(Result{Target: target, Status: status, Latency: latency, CheckedAt: at.Add(time.Duration(i) * time.Second)})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	st := summarize([]sample{
+		{status: StatusHealthy, latency: 10 * time.Millisecond},
+		{status: StatusHealthy, latency: 20 * time.Millisecond},
+		{status: StatusUnhealthy, latency: 30 * time.Millisecond},
+		{status: StatusHealthy, latency: 40 * time.Millisecond},
+	})
+
+	if st.Checks != 4 || st.Failures != 1 || st.FailureRate != 0.25 {
+		t.Errorf("stats = %+v, want 4 checks with 1 failure", st)
+	}
+	if st.P50 != 20*time.Millisecond || st.P95 != 40*time.Millisecond {
+		t.Errorf("p50 = %v, p95 = %v, want 20ms and 40ms", st.P50, st.P95)
+	}
+}
+
+func TestMarkDeploy_UnknownTarget(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://example.com"))
+	if _, err := c.MarkDeploy("nope", 0); err == nil {
+		t.Error("expected error for an unknown target")
+	}
+	if _, ok := c.CompareDeploy("api"); ok {
+		t.Error("expected no comparison before a deploy is marked")
+	}
+}
+
+func TestCompareDeploy_Regression(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://example.com"))
+	now := time.Now()
+	observeN(&c.recent, "api", now.Add(-5*time.Minute), 20, StatusHealthy, 50*time.Millisecond)
+
+	d, err := c.MarkDeploy("api", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Window != 10*time.Minute || d.Baseline.Checks != 20 {
+		t.Fatalf("deploy = %+v, want a 10m window over 20 baseline checks", d)
+	}
+
+	observeN(&c.recent, "api", d.At.Add(time.Millisecond), 5, StatusUnhealthy, 200*time.Millisecond)
+	// let the samples fall strictly before "now" in CompareDeploy
+	time.Sleep(10 * time.Millisecond)
+
+	cmp, ok := c.CompareDeploy("api")
+	if !ok {
+		t.Fatal("expected a comparison")
+	}
+	if cmp.Complete {
+		t.Error("expected the comparison window to still be open")
+	}
+	if cmp.After.Checks == 0 || len(cmp.Regressions) != 2 {
+		t.Errorf("after = %+v, regressions = %v, want failure rate and latency regressions", cmp.After, cmp.Regressions)
+	}
+}
+
+func TestRegressions_None(t *testing.T) {
+	before := DeployStats{Checks: 10, P95: 100 * time.Millisecond}
+	after := DeployStats{Checks: 10, P95: 120 * time.Millisecond}
+	if r := regressions(before, after); r != nil {
+		t.Errorf("regressions = %v, want none", r)
+	}
+}
@@ -0,0 +1,66 @@
+package kenko
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DigestNotifier is implemented by notifiers that can send several
+// notifications as one message, for WithNotifyGroupWindow.
+type DigestNotifier interface {
+	NotifyDigest(ctx context.Context, ns []Notification) error
+}
+
+// WithNotifyGroupWindow holds each notifier's notifications for window after
+// the first of them arrives and sends all that arrived meanwhile together,
+// so an outage taking down many targets at once is one message rather than a
+// page per target. notifiers that do not implement DigestNotifier are still
+// sent them one by one.
+func WithNotifyGroupWindow(window time.Duration) Option {
+	return func(o *options) { o.grouping = window }
+}
+
+func validateGroupWindow(window time.Duration) error {
+	if window < 0 {
+		return fmt.Errorf("kenko: notification group window must not be negative, got %s", window)
+	}
+	return nil
+}
+
+// collect gathers the notifications queued for q within the group window
+// after first, or until ctx is done.
+func (d *dispatcher) collect(ctx context.Context, q *notifyQueue, first Notification) []Notification {
+	batch := []Notification{first}
+	timer := time.NewTimer(d.window)
+	defer timer.Stop()
+	for {
+		select {
+		case n := <-q.queue:
+			batch = append(batch, n)
+		case <-timer.C:
+			return batch
+		case <-ctx.Done():
+			return batch
+		}
+	}
+}
+
+// deliverGroup sends batch as one digest when q's notifier can, and one
+// notification at a time otherwise. a digest counts once against q's
+// throttle.
+func (d *dispatcher) deliverGroup(ctx context.Context, q *notifyQueue, batch []Notification) {
+	dn, ok := q.notifier.(DigestNotifier)
+	if !ok || len(batch) == 1 {
+		for _, n := range batch {
+			d.deliver(ctx, q, n)
+		}
+		return
+	}
+	if batch = d.throttle(q, batch); len(batch) == 0 {
+		return
+	}
+	d.try(ctx, q, batch, func(ctx context.Context) error {
+		return dn.NotifyDigest(ctx, batch)
+	})
+}
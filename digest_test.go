@@ -0,0 +1,66 @@
+package kenko
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// digestNotifier records the digests it is sent.
+type digestNotifier struct {
+	recordingNotifier
+	mu      sync.Mutex
+	digests [][]Notification
+}
+
+func (d *digestNotifier) NotifyDigest(_ context.Context, ns []Notification) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.digests = append(d.digests, ns)
+	return nil
+}
+
+func TestDispatcher_GroupWindow(t *testing.T) {
+	digest := &digestNotifier{}
+	plain := &recordingNotifier{}
+	outcomes := &outcomeRecorder{}
+	d := newDispatcher(map[string]Notifier{"chat": digest, "hook": plain}, NotifyRetry{}, slog.New(slog.NewTextHandler(io.Discard, nil)), outcomes.report)
+	d.window = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx)
+		close(done)
+	}()
+	for _, target := range []string{"api", "web", "db"} {
+		d.enqueue(Notification{Target: target, Status: StatusUnhealthy})
+	}
+	time.Sleep(150 * time.Millisecond)
+	d.enqueue(Notification{Target: "api", Status: StatusHealthy})
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	if len(digest.digests) != 1 || len(digest.digests[0]) != 3 {
+		t.Fatalf("digests = %+v, want the three failures together", digest.digests)
+	}
+	if len(digest.sent) != 1 || digest.sent[0].Status != StatusHealthy {
+		t.Errorf("sent = %+v, want the lone recovery sent on its own", digest.sent)
+	}
+	if len(plain.sent) != 4 {
+		t.Errorf("hook sent %d notifications, want each of the 4", len(plain.sent))
+	}
+	if outcomes.outcomes["chat/delivered"] != 4 || outcomes.outcomes["hook/delivered"] != 4 {
+		t.Errorf("outcomes = %v", outcomes.outcomes)
+	}
+}
+
+func TestNewChecker_GroupWindowValidation(t *testing.T) {
+	if _, err := NewChecker(WithTarget("api", "https://api.example.com"), WithNotifyGroupWindow(-time.Second)); err == nil {
+		t.Error("want an error for a negative group window")
+	}
+}
@@ -0,0 +1,434 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	defaultNotifyAttempts = 3
+	defaultNotifyBackoff  = time.Second
+	defaultNotifyTimeout  = 10 * time.Second
+
+	// notifyQueueSize caps the notifications waiting for each notifier.
+	notifyQueueSize = 100
+
+	// deliveryLogSize caps the delivery records waiting to be reported and
+	// written to the store.
+	deliveryLogSize = 1000
+)
+
+// outcomes of a notification, as passed to NotificationReporter.
+const (
+	NotificationDelivered = "delivered"
+	NotificationFailed    = "failed"
+	NotificationDropped   = "dropped"
+	NotificationThrottled = "throttled"
+	NotificationSilenced  = "silenced"
+)
+
+// NotificationReporter is an optional MetricsReporter extension that receives
+// the outcome of every notification sent on a status change: delivered,
+// failed after its retries, dropped because the notifier's queue was full,
+// throttled by WithNotifyThrottle, or silenced by an active silence.
+type NotificationReporter interface {
+	ReportNotification(notifier, outcome string)
+}
+
+// NotifyRetry configures how notifications sent on status changes are
+// retried.
+type NotifyRetry struct {
+	// Attempts is how many times a notification is tried before it is given
+	// up on (default 3).
+	Attempts int
+	// Backoff is the wait before the first retry, doubling for each next one
+	// (default 1s).
+	Backoff time.Duration
+	// Timeout bounds each attempt (default 10s).
+	Timeout time.Duration
+}
+
+// WithNotifyRetry sets how notifications sent on status changes are retried.
+func WithNotifyRetry(r NotifyRetry) Option {
+	return func(o *options) { o.notifyRetry = &r }
+}
+
+// permanentError marks a notification failure retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps a notifier's error to stop the notification from being
+// retried, e.g. when the receiver rejected the request as malformed.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked by
+// Permanent.
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// RetryableStatus reports whether a request a receiver answered with the
+// HTTP status code may succeed when retried. 4xx responses mean the request
+// itself was rejected, except 408 and 429, so notifiers wrap the error of any
+// status it reports false for in Permanent.
+func RetryableStatus(code int) bool {
+	if code < 400 || code >= 500 {
+		return true
+	}
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// notifyQueue holds the notifications waiting for one notifier.
+type notifyQueue struct {
+	name     string
+	notifier Notifier
+	queue    chan Notification
+	limiter  *rateLimiter
+}
+
+// dispatcher delivers notifications from a goroutine per notifier, so a slow
+// or failing receiver neither blocks the checks nor delays the others.
+type dispatcher struct {
+	queues   []*notifyQueue
+	routes   []Route
+	retry    NotifyRetry
+	remind   *reminders
+	escalate *escalations
+	failures routedFailures
+	window   time.Duration
+	logger   *slog.Logger
+	// report is called with every delivery record from run's goroutine,
+	// so slow store writes do not hold up the notifications.
+	report func(Delivery)
+	log    chan Delivery
+}
+
+func newDispatcher(notifiers map[string]Notifier, retry NotifyRetry, logger *slog.Logger, report func(Delivery)) *dispatcher {
+	if retry.Attempts <= 0 {
+		retry.Attempts = defaultNotifyAttempts
+	}
+	if retry.Backoff <= 0 {
+		retry.Backoff = defaultNotifyBackoff
+	}
+	if retry.Timeout <= 0 {
+		retry.Timeout = defaultNotifyTimeout
+	}
+
+	d := &dispatcher{retry: retry, logger: logger, report: report, log: make(chan Delivery, deliveryLogSize)}
+	for name, n := range notifiers {
+		d.queues = append(d.queues, &notifyQueue{name: name, notifier: n, queue: make(chan Notification, notifyQueueSize)})
+	}
+	slices.SortFunc(d.queues, func(a, b *notifyQueue) int { return cmp.Compare(a.name, b.name) })
+	return d
+}
+
+// enqueue queues n for every notifier it is routed to or its target's
+// failure was routed or escalated to without blocking.
+func (d *dispatcher) enqueue(n Notification) {
+	names := d.recipients(n)
+	for _, q := range d.queues {
+		if names == nil || names[q.name] {
+			d.offer(q, n)
+		}
+	}
+}
+
+// silence records n as silenced for every notifier enqueue would have
+// queued it for.
+func (d *dispatcher) silence(n Notification) {
+	names := d.recipients(n)
+	for _, q := range d.queues {
+		if names == nil || names[q.name] {
+			d.record(delivery(q, n, NotificationSilenced))
+		}
+	}
+}
+
+// recipients returns the names of the notifiers n is for, or nil for all of
+// them when there are no routes.
+func (d *dispatcher) recipients(n Notification) map[string]bool {
+	if len(d.routes) == 0 {
+		return nil
+	}
+	names := route(d.routes, n)
+	d.failures.track(n, names)
+	if d.escalate != nil {
+		for name := range d.escalate.notified(n.Target) {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// send queues n for the named notifiers without blocking.
+func (d *dispatcher) send(n Notification, names []string) {
+	for _, q := range d.queues {
+		if slices.Contains(names, q.name) {
+			d.offer(q, n)
+		}
+	}
+}
+
+// offer queues n for q, dropping it when q is full.
+func (d *dispatcher) offer(q *notifyQueue, n Notification) {
+	select {
+	case q.queue <- n:
+	default:
+		d.logger.Warn("notification queue full, dropping notification", "notifier", q.name, "target", n.Target)
+		d.record(delivery(q, n, NotificationDropped))
+	}
+}
+
+// record queues dl to be reported without blocking, dropping it when too
+// many are waiting.
+func (d *dispatcher) record(dl Delivery) {
+	select {
+	case d.log <- dl:
+	default:
+		d.logger.Warn("delivery log full, dropping delivery record", "notifier", dl.Notifier, "target", dl.Target, "outcome", dl.Outcome)
+	}
+}
+
+// delivery returns the record of n's outcome for q.
+func delivery(q *notifyQueue, n Notification, outcome string) Delivery {
+	return Delivery{
+		Notifier: q.name,
+		Target:   n.Target,
+		Status:   n.Status,
+		Repeat:   n.Repeat,
+		Outcome:  outcome,
+		At:       time.Now(),
+	}
+}
+
+// run delivers queued notifications until ctx is done, then makes one last
+// attempt at those still queued. it returns once their outcomes are
+// reported.
+func (d *dispatcher) run(ctx context.Context) {
+	stop := make(chan struct{})
+	logged := make(chan struct{})
+	go func() {
+		d.writeLog(stop)
+		close(logged)
+	}()
+
+	done := make(chan struct{}, len(d.queues))
+	for _, q := range d.queues {
+		go func() {
+			d.work(ctx, q)
+			done <- struct{}{}
+		}()
+	}
+	for range d.queues {
+		<-done
+	}
+	close(stop)
+	<-logged
+}
+
+// writeLog reports the queued delivery records until stop is closed, then
+// reports those still queued.
+func (d *dispatcher) writeLog(stop <-chan struct{}) {
+	for {
+		select {
+		case dl := <-d.log:
+			d.report(dl)
+		case <-stop:
+			for {
+				select {
+				case dl := <-d.log:
+					d.report(dl)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *dispatcher) work(ctx context.Context, q *notifyQueue) {
+	for {
+		select {
+		case <-ctx.Done():
+			d.drain(q)
+			return
+		case n := <-q.queue:
+			if ctx.Err() != nil {
+				d.drain(q, n)
+				return
+			}
+			if d.window > 0 {
+				batch := d.collect(ctx, q, n)
+				if ctx.Err() != nil {
+					d.drain(q, batch...)
+					return
+				}
+				d.deliverGroup(ctx, q, batch)
+				continue
+			}
+			d.deliver(ctx, q, n)
+		}
+	}
+}
+
+// drain delivers first and whatever is still queued once run's context is
+// done, so the changes seen by the last checks are not lost.
+func (d *dispatcher) drain(q *notifyQueue, first ...Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, n := range first {
+		d.deliver(ctx, q, n)
+	}
+	for {
+		select {
+		case n := <-q.queue:
+			d.deliver(ctx, q, n)
+		default:
+			return
+		}
+	}
+}
+
+// deliver sends n unless q is throttled, retrying failures with backoff
+// until the attempts run out, the error is permanent, or ctx is done.
+func (d *dispatcher) deliver(ctx context.Context, q *notifyQueue, n Notification) {
+	if d.throttle(q, []Notification{n}) == nil {
+		return
+	}
+	d.try(ctx, q, []Notification{n}, func(ctx context.Context) error {
+		return q.notifier.Notify(ctx, n)
+	})
+}
+
+// throttle counts sending batch as one message against q's limit. when the
+// limit is reached it reports the notifications in batch as throttled and
+// returns the rest, the recoveries, which are always sent so a failure that
+// was sent is not left open on the receiving end.
+func (d *dispatcher) throttle(q *notifyQueue, batch []Notification) []Notification {
+	if q.limiter == nil || !slices.ContainsFunc(batch, func(n Notification) bool { return !resolves(n) }) {
+		return batch
+	}
+	if q.limiter.allow(time.Now()) {
+		return batch
+	}
+	var kept []Notification
+	for _, n := range batch {
+		if resolves(n) {
+			kept = append(kept, n)
+			continue
+		}
+		d.logger.Warn("notification throttled", "notifier", q.name, "target", n.Target)
+		d.record(delivery(q, n, NotificationThrottled))
+	}
+	return kept
+}
+
+// try calls send until it succeeds, the attempts run out, the error is
+// permanent, or ctx is done, reporting the outcome for each of the
+// notifications in batch it sends.
+func (d *dispatcher) try(ctx context.Context, q *notifyQueue, batch []Notification, send func(context.Context) error) {
+	attr := slog.String("target", batch[0].Target)
+	if len(batch) > 1 {
+		targets := make([]string, len(batch))
+		for i, n := range batch {
+			targets[i] = n.Target
+		}
+		attr = slog.Any("targets", targets)
+	}
+
+	start := time.Now()
+	report := func(outcome string, attempts int, err error) {
+		for _, n := range batch {
+			dl := delivery(q, n, outcome)
+			dl.Attempts = attempts
+			dl.Latency = dl.At.Sub(start)
+			if err != nil {
+				dl.Error = deliveryError(err)
+			}
+			d.record(dl)
+		}
+	}
+	backoff := d.retry.Backoff
+	var last error
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, d.retry.Timeout)
+		err := send(attemptCtx)
+		cancel()
+		if err == nil {
+			report(NotificationDelivered, attempt, last)
+			return
+		}
+		last = err
+
+		if IsPermanent(err) || attempt >= d.retry.Attempts || ctx.Err() != nil {
+			d.logger.Warn("failed to send notification", "notifier", q.name, attr, "attempts", attempt, "error", err)
+			report(NotificationFailed, attempt, err)
+			return
+		}
+		d.logger.Debug("retrying notification", "notifier", q.name, attr, "attempt", attempt+1, "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// notify queues a notification of the change from the closed segment to the
+// result's status, with the outage a recovery ended. changes into
+// maintenance or a pause are expected, so they notify no one, and a change
+// out of one is compared against the status before it: a failure that ended
+// meanwhile is sent its recovery, and one that began meanwhile is sent as
+// usual. changes an active silence matches notify no one either.
+func (c *Checker) notify(prev Segment, r Result, outage *Outage) {
+	if c.dispatcher == nil || quiet(r.Status) {
+		return
+	}
+	if quiet(prev.Status) {
+		before, ok := c.timeline.beforeQuiet(r.Target)
+		if !ok || before.Status == r.Status {
+			return
+		}
+		prev = before
+	}
+	if r.Status == StatusHealthy && outage == nil {
+		// the outage began before a restart, so only its last status is known
+		outage = &Outage{Start: prev.Start, Duration: prev.Duration(r.CheckedAt)}
+	}
+	n := Notification{
+		Target:   r.Target,
+		Previous: prev.Status,
+		Status:   r.Status,
+		Result:   r,
+		At:       r.CheckedAt,
+		Outage:   outage,
+		Uptime:   c.Uptime(r.Target),
+	}
+	if c.silenced(r, time.Now()) {
+		// still tracked, so reminders pick up once the silence ends
+		c.logger.Debug("notification silenced", "target", r.Target, "status", r.Status)
+		c.dispatcher.silence(n)
+	} else {
+		c.dispatcher.enqueue(n)
+	}
+	if c.dispatcher.remind != nil {
+		c.dispatcher.remind.sent(n)
+	}
+}
@@ -0,0 +1,166 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyNotifier fails its first fails notifications.
+type flakyNotifier struct {
+	recordingNotifier
+	fails atomic.Int32
+}
+
+func (f *flakyNotifier) Notify(ctx context.Context, n Notification) error {
+	f.recordingNotifier.Notify(ctx, n)
+	if f.fails.Add(-1) >= 0 {
+		return errors.New("connection reset")
+	}
+	return nil
+}
+
+// outcomeRecorder counts notification outcomes by notifier.
+type outcomeRecorder struct {
+	mu       sync.Mutex
+	outcomes map[string]int
+}
+
+func (o *outcomeRecorder) report(d Delivery) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.outcomes == nil {
+		o.outcomes = make(map[string]int)
+	}
+	o.outcomes[d.Notifier+"/"+d.Outcome]++
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	flaky := &flakyNotifier{}
+	flaky.fails.Store(2)
+	rejecting := &recordingNotifier{err: Permanent(errors.New("status 400"))}
+	outcomes := &outcomeRecorder{}
+	d := newDispatcher(map[string]Notifier{"ops": flaky, "chat": rejecting}, NotifyRetry{Backoff: time.Millisecond}, slog.New(slog.NewTextHandler(io.Discard, nil)), outcomes.report)
+
+	d.enqueue(Notification{Target: "api", Status: StatusUnhealthy})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.run(ctx)
+
+	if len(flaky.sent) != 3 {
+		t.Errorf("ops tried %d times, want 3", len(flaky.sent))
+	}
+	if len(rejecting.sent) != 1 {
+		t.Errorf("chat tried %d times, want a permanent error not retried", len(rejecting.sent))
+	}
+	if outcomes.outcomes["ops/delivered"] != 1 || outcomes.outcomes["chat/failed"] != 1 {
+		t.Errorf("outcomes = %v", outcomes.outcomes)
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	for code, want := range map[int]bool{
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+		http.StatusRequestTimeout:      true,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	} {
+		if got := RetryableStatus(code); got != want {
+			t.Errorf("RetryableStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestDispatcher_QueueFull(t *testing.T) {
+	outcomes := &outcomeRecorder{}
+	d := newDispatcher(map[string]Notifier{"ops": &recordingNotifier{}}, NotifyRetry{}, slog.New(slog.NewTextHandler(io.Discard, nil)), outcomes.report)
+	for range notifyQueueSize + 1 {
+		d.enqueue(Notification{Target: "api"})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.run(ctx)
+	if outcomes.outcomes["ops/dropped"] != 1 {
+		t.Errorf("outcomes = %v, want one dropped", outcomes.outcomes)
+	}
+}
+
+func TestNotify_AcrossMaintenance(t *testing.T) {
+	rec := &recordingNotifier{}
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithNotifier("ops", rec), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i, s := range []Status{
+		StatusHealthy, StatusMaintenance, StatusUnhealthy, // fails once maintenance ends
+		StatusPaused, StatusUnhealthy, // still failing after a pause
+		StatusMaintenance, StatusHealthy, // recovered during maintenance
+		StatusPaused, StatusHealthy,
+	} {
+		r := Result{Target: "api", Status: s, CheckedAt: start.Add(time.Duration(i) * time.Minute)}
+		outage := c.outages.observe(r)
+		if prev, changed := c.timeline.observe(r); changed {
+			c.notify(prev, r, outage)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.dispatcher.run(runCtx)
+
+	if len(rec.sent) != 2 {
+		t.Fatalf("sent = %+v, want the failure and its recovery", rec.sent)
+	}
+	if n := rec.sent[0]; n.Previous != StatusHealthy || n.Status != StatusUnhealthy {
+		t.Errorf("failure = %+v", n)
+	}
+	if n := rec.sent[1]; n.Previous != StatusUnhealthy || n.Status != StatusHealthy || n.Outage == nil || !n.Outage.Start.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("recovery = %+v, want the outage from the failure", n)
+	}
+}
+
+func TestCheckTarget_Notifies(t *testing.T) {
+	var code atomic.Int32
+	code.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(code.Load()))
+	}))
+	defer srv.Close()
+
+	rec := &recordingNotifier{}
+	c, err := NewChecker(WithTarget("api", srv.URL), WithNotifier("ops", rec), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	target := c.targetList()[0]
+
+	c.checkTarget(ctx, target)
+	code.Store(http.StatusServiceUnavailable)
+	c.checkTarget(ctx, target)
+	c.checkTarget(ctx, target)
+
+	// changes into maintenance notify no one
+	c.notify(Segment{Status: StatusUnhealthy}, Result{Target: "api", Status: StatusMaintenance}, nil)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	c.dispatcher.run(runCtx)
+
+	if len(rec.sent) != 1 {
+		t.Fatalf("sent = %+v, want one notification", rec.sent)
+	}
+	if n := rec.sent[0]; n.Target != "api" || n.Previous != StatusHealthy || n.Status != StatusUnhealthy || n.Result.StatusCode != http.StatusServiceUnavailable || n.Test {
+		t.Errorf("notification = %+v", n)
+	}
+}
@@ -0,0 +1,406 @@
+// Package email is a kenko Notifier that mails status changes through an
+// SMTP server, to a default list of recipients or to lists picked by routes.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// TLSMode is how the connection to the server is secured.
+type TLSMode string
+
+const (
+	// StartTLS upgrades the connection with STARTTLS, failing when the
+	// server does not offer it. it is the default, usually on port 587.
+	StartTLS TLSMode = "starttls"
+	// ImplicitTLS connects over TLS from the start, usually on port 465.
+	ImplicitTLS TLSMode = "tls"
+	// NoTLS sends in the clear, for a relay on the local host or network.
+	NoTLS TLSMode = "none"
+)
+
+// maxSubjectTargets caps the targets a digest's subject names.
+const maxSubjectTargets = 5
+
+const (
+	defaultSubject = `[kenko] {{.Target}} {{if .Slow}}{{if .Slow.Resolved}}is fast again{{else}}is slow{{end}}{{else if .Outage}}recovered{{else if .Repeat}}is still {{.Status}}{{else}}is {{.Status}}{{end}}`
+	defaultBody    = `{{with .Slow}}{{$.Target}} {{if .Resolved}}is fast again{{else}}took over {{.Threshold}} for {{.Checks}} checks{{end}}, slow since {{.Since.Format "2006-01-02 15:04:05 MST"}}.
+{{else}}{{.Target}} is {{.Status}} (was {{.Previous}}) as of {{.At.Format "2006-01-02 15:04:05 MST"}}.
+{{end}}
+{{- with .Outage}}
+Down for {{.Duration}} since {{.Start.Format "2006-01-02 15:04:05 MST"}}.
+{{- if .FirstError}}
+First error: {{.FirstError}}{{end}}
+{{- if and .LastError (ne .LastError .FirstError)}}
+Last error: {{.LastError}}{{end}}
+{{end}}
+{{- with .Result}}
+URL: {{.URL}}
+{{- if .StatusCode}}
+Status code: {{.StatusCode}}{{end}}
+Latency: {{.Latency}}
+{{- if .Error}}
+Error: {{.Error}}{{end}}
+{{- if .Region}}
+Region: {{.Region}}{{end}}
+{{end}}`
+)
+
+var (
+	// DefaultSubject renders the subject unless WithSubject is given.
+	DefaultSubject = template.Must(template.New("subject").Parse(defaultSubject))
+	// DefaultBody renders the body unless WithBody is given.
+	DefaultBody = template.Must(template.New("body").Parse(defaultBody))
+)
+
+// Route sends the notifications of matching targets to To instead of the
+// notifier's default recipients. a target matches when it is one of Targets
+// or in one of Groups, and has every one of Labels; empty criteria match any
+// target.
+type Route struct {
+	To      []string
+	Targets []string
+	Groups  []string
+	Labels  map[string]string
+}
+
+func (r Route) matches(n kenko.Notification) bool {
+	if len(r.Targets) > 0 && !slices.Contains(r.Targets, n.Target) {
+		return false
+	}
+	if len(r.Groups) > 0 && !slices.Contains(r.Groups, n.Result.Group) {
+		return false
+	}
+	for k, v := range r.Labels {
+		if got, ok := n.Result.Labels[k]; !ok || !strings.EqualFold(got, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithAuth authenticates with PLAIN auth, which net/smtp only sends over TLS
+// or to the local host.
+func WithAuth(username, password string) Option {
+	return func(n *Notifier) { n.username, n.password = username, password }
+}
+
+// WithTLS sets how the connection is secured (default StartTLS).
+func WithTLS(mode TLSMode) Option {
+	return func(n *Notifier) { n.tlsMode = mode }
+}
+
+// WithTLSConfig sets the TLS configuration, e.g. a private CA. its
+// ServerName defaults to the server's host.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(n *Notifier) { n.tlsConfig = c }
+}
+
+// WithSubject sets the template the subject is rendered from, executed with
+// the kenko.Notification.
+func WithSubject(t *template.Template) Option {
+	return func(n *Notifier) { n.subject = t }
+}
+
+// WithBody sets the template the plain text body is rendered from, executed
+// with the kenko.Notification.
+func WithBody(t *template.Template) Option {
+	return func(n *Notifier) { n.body = t }
+}
+
+// WithRoute adds a route. routes are tried in the order they were added and
+// the first match picks the recipients.
+func WithRoute(r Route) Option {
+	return func(n *Notifier) { n.routes = append(n.routes, r) }
+}
+
+// WithTimeout bounds connecting to the server and each command when the
+// context has no earlier deadline (default 30s).
+func WithTimeout(d time.Duration) Option {
+	return func(n *Notifier) { n.timeout = d }
+}
+
+// Notifier mails notifications through an SMTP server.
+type Notifier struct {
+	addr     string
+	host     string
+	from     string
+	to       []string
+	routes   []Route
+	username string
+	password string
+
+	tlsMode   TLSMode
+	tlsConfig *tls.Config
+	subject   *template.Template
+	body      *template.Template
+	timeout   time.Duration
+}
+
+// New creates a Notifier sending from the from address through the server at
+// addr, a host:port, to the to addresses unless a route picks others. with no
+// to addresses, notifications no route matches are not sent.
+func New(addr, from string, to []string, opts ...Option) *Notifier {
+	host, _, _ := net.SplitHostPort(addr)
+	n := &Notifier{
+		addr:    addr,
+		host:    host,
+		from:    from,
+		to:      to,
+		tlsMode: StartTLS,
+		subject: DefaultSubject,
+		body:    DefaultBody,
+		timeout: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// recipients returns the addresses n is mailed to.
+func (e *Notifier) recipients(n kenko.Notification) []string {
+	for _, r := range e.routes {
+		if r.matches(n) {
+			return r.To
+		}
+	}
+	return e.to
+}
+
+// render executes the subject and body templates for n.
+func (e *Notifier) render(n kenko.Notification) (subject, body string, err error) {
+	var b strings.Builder
+	if err := e.subject.Execute(&b, n); err != nil {
+		return "", "", fmt.Errorf("email: subject: %w", err)
+	}
+	// a subject must stay on one header line
+	subject = strings.Join(strings.Fields(b.String()), " ")
+	if n.Test {
+		subject = "[test] " + subject
+	}
+	b.Reset()
+	if err := e.body.Execute(&b, n); err != nil {
+		return "", "", fmt.Errorf("email: body: %w", err)
+	}
+	return subject, b.String(), nil
+}
+
+// message builds the RFC 5322 message mailed to to.
+func (e *Notifier) message(subject, body string, to []string, at time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", e.from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", at.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	header("X-Mailer", kenko.DefaultUserAgent)
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Notify mails the notification to its recipients. rejections with a 5xx
+// reply are not retried.
+func (e *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	to := e.recipients(n)
+	if len(to) == 0 {
+		return nil
+	}
+	subject, body, err := e.render(n)
+	if err != nil {
+		return kenko.Permanent(err)
+	}
+	return e.mail(ctx, to, subject, body)
+}
+
+// NotifyDigest mails one message for several notifications to each set of
+// recipients, with the subject and body rendered for each of the
+// notifications mailed to them.
+func (e *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	type digest struct {
+		to       []string
+		targets  []string
+		subjects []string
+		bodies   []string
+	}
+	var digests []*digest
+	byTo := make(map[string]*digest)
+	for _, n := range ns {
+		to := e.recipients(n)
+		if len(to) == 0 {
+			continue
+		}
+		subject, body, err := e.render(n)
+		if err != nil {
+			return kenko.Permanent(err)
+		}
+		key := strings.Join(to, ",")
+		d, ok := byTo[key]
+		if !ok {
+			d = &digest{to: to}
+			byTo[key] = d
+			digests = append(digests, d)
+		}
+		if !slices.Contains(d.targets, n.Target) {
+			d.targets = append(d.targets, n.Target)
+		}
+		d.subjects = append(d.subjects, subject)
+		d.bodies = append(d.bodies, body)
+	}
+
+	var errs []error
+	permanent := true
+	for _, d := range digests {
+		subject, body := d.subjects[0], d.bodies[0]
+		if len(d.bodies) > 1 {
+			subject, body = digestSubject(len(d.bodies), d.targets), ""
+			for i := range d.bodies {
+				body += d.subjects[i] + "\n\n" + d.bodies[i] + "\n"
+			}
+		}
+		if err := e.mail(ctx, d.to, subject, body); err != nil {
+			errs = append(errs, err)
+			permanent = permanent && kenko.IsPermanent(err)
+		}
+	}
+	err := errors.Join(errs...)
+	if err == nil || permanent {
+		return err
+	}
+	// keep a permanent error for one list from stopping the retries
+	return errors.New(err.Error())
+}
+
+// digestSubject counts the notifications of a digest and names their
+// targets, up to maxSubjectTargets of them.
+func digestSubject(count int, targets []string) string {
+	if len(targets) <= maxSubjectTargets {
+		return fmt.Sprintf("[kenko] %d notifications: %s", count, strings.Join(targets, ", "))
+	}
+	return fmt.Sprintf("[kenko] %d notifications: %s and %d more", count, strings.Join(targets[:maxSubjectTargets], ", "), len(targets)-maxSubjectTargets)
+}
+
+// mail sends a message with subject and body to to.
+func (e *Notifier) mail(ctx context.Context, to []string, subject, body string) error {
+	msg, err := e.message(subject, body, to, time.Now())
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("email: encode: %w", err))
+	}
+	if err := e.send(ctx, to, msg); err != nil {
+		var perr *textproto.Error
+		if errors.As(err, &perr) && perr.Code >= 500 {
+			return kenko.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// send delivers msg in one SMTP session.
+func (e *Notifier) send(ctx context.Context, to []string, msg []byte) error {
+	deadline := time.Now().Add(e.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	conn.SetDeadline(deadline)
+	// unblock the session when ctx is canceled before the deadline
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if e.tlsMode == ImplicitTLS {
+		conn = tls.Client(conn, e.clientTLS())
+	}
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("email: %w", err)
+	}
+	defer c.Close()
+
+	if e.tlsMode == StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return kenko.Permanent(fmt.Errorf("email: %s does not offer STARTTLS", e.addr))
+		}
+		if err := c.StartTLS(e.clientTLS()); err != nil {
+			return fmt.Errorf("email: starttls: %w", err)
+		}
+	}
+	if e.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return fmt.Errorf("email: auth: %w", err)
+		}
+	}
+	if err := c.Mail(e.from); err != nil {
+		return fmt.Errorf("email: mail from %s: %w", e.from, err)
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return fmt.Errorf("email: rcpt to %s: %w", addr, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("email: data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("email: data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("email: data: %w", err)
+	}
+	return c.Quit()
+}
+
+func (e *Notifier) clientTLS() *tls.Config {
+	c := &tls.Config{}
+	if e.tlsConfig != nil {
+		c = e.tlsConfig.Clone()
+	}
+	if c.ServerName == "" {
+		c.ServerName = e.host
+	}
+	return c
+}
+
+// Preview renders the subject and body Notify would mail for n.
+func (e *Notifier) Preview(n kenko.Notification) (string, error) {
+	subject, body, err := e.render(n)
+	if err != nil {
+		return "", err
+	}
+	return "Subject: " + subject + "\n\n" + body, nil
+}
@@ -0,0 +1,277 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// smtpServer is a minimal SMTP server without TLS or auth that records the
+// messages it accepts.
+type smtpServer struct {
+	ln   net.Listener
+	rcpt string // reply to RCPT, default 250
+
+	mu   sync.Mutex
+	to   [][]string
+	data []string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{ln: ln, rcpt: "250 ok"}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ready")
+	var to []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			reply("250 ok")
+		case "RCPT":
+			to = append(to, strings.Trim(strings.TrimPrefix(strings.TrimSpace(line), "RCPT TO:"), "<>"))
+			reply(s.rcpt)
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.to = append(s.to, to)
+			s.data = append(s.data, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func notification() kenko.Notification {
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	return kenko.Notification{
+		Target:   "api",
+		Previous: kenko.StatusHealthy,
+		Status:   kenko.StatusUnhealthy,
+		At:       at,
+		Result: kenko.Result{
+			Target: "api", URL: "https://api.example.com", Status: kenko.StatusUnhealthy, StatusCode: 503, Error: "status 503",
+			Latency: 120 * time.Millisecond, Group: "payments", CheckedAt: at,
+		},
+	}
+}
+
+func TestNotify(t *testing.T) {
+	srv := newSMTPServer(t)
+	n := New(srv.ln.Addr().String(), "kenko@example.com", []string{"ops@example.com"}, WithTLS(NoTLS))
+	if err := n.Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(srv.data) != 1 || len(srv.to[0]) != 1 || srv.to[0][0] != "ops@example.com" {
+		t.Fatalf("sent %v to %v", srv.data, srv.to)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Subject"); got != "[kenko] api is unhealthy" {
+		t.Errorf("subject = %q", got)
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	for _, want := range []string{"api is unhealthy (was healthy) as of 2026-03-13 12:00:00 UTC", "Status code: 503", "Error: status 503", "Latency: 120ms"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+}
+
+func TestNotify_Routes(t *testing.T) {
+	srv := newSMTPServer(t)
+	n := New(srv.ln.Addr().String(), "kenko@example.com", nil, WithTLS(NoTLS),
+		WithRoute(Route{To: []string{"payments@example.com", "cfo@example.com"}, Groups: []string{"payments"}}))
+
+	other := notification()
+	other.Target, other.Result.Group = "docs", ""
+	for _, notif := range []kenko.Notification{notification(), other} {
+		if err := n.Notify(context.Background(), notif); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// docs matches no route and there are no default recipients
+	if len(srv.to) != 1 || strings.Join(srv.to[0], ",") != "payments@example.com,cfo@example.com" {
+		t.Errorf("sent to %v", srv.to)
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+	srv := newSMTPServer(t)
+	n := New(srv.ln.Addr().String(), "kenko@example.com", []string{"ops@example.com"}, WithTLS(NoTLS),
+		WithRoute(Route{To: []string{"payments@example.com"}, Groups: []string{"payments"}}))
+
+	web := notification()
+	web.Target, web.Result.Group = "web", ""
+	db := notification()
+	db.Target, db.Result.Group = "db", ""
+	if err := n.NotifyDigest(context.Background(), []kenko.Notification{web, notification(), db}); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.data) != 2 {
+		t.Fatalf("sent %d messages to %v, want one per recipient list", len(srv.data), srv.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Subject"); srv.to[0][0] != "ops@example.com" || got != "[kenko] 2 notifications: web, db" {
+		t.Errorf("subject = %q to %v", got, srv.to[0])
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	for _, want := range []string{"[kenko] web is unhealthy\r\n\r\nweb is unhealthy (was healthy)", "[kenko] db is unhealthy\r\n\r\ndb is unhealthy"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+
+	// the only notification for payments is mailed as it is
+	msg, err = mail.ReadMessage(strings.NewReader(srv.data[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Subject"); got != "[kenko] api is unhealthy" {
+		t.Errorf("subject = %q", got)
+	}
+}
+
+func TestDigestSubject(t *testing.T) {
+	got := digestSubject(7, []string{"a", "b", "c", "d", "e", "f", "g"})
+	if got != "[kenko] 7 notifications: a, b, c, d, e and 2 more" {
+		t.Errorf("subject = %q", got)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	srv := newSMTPServer(t)
+	addr := srv.ln.Addr().String()
+
+	srv.rcpt = "550 no such user"
+	err := New(addr, "kenko@example.com", []string{"nobody@example.com"}, WithTLS(NoTLS)).Notify(context.Background(), notification())
+	if err == nil || !kenko.IsPermanent(err) || !strings.Contains(err.Error(), "no such user") {
+		t.Errorf("err = %v, want a permanent rejection", err)
+	}
+	srv.rcpt = "451 try again later"
+	err = New(addr, "kenko@example.com", []string{"ops@example.com"}, WithTLS(NoTLS)).Notify(context.Background(), notification())
+	if err == nil || kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a temporary failure", err)
+	}
+
+	err = New(addr, "kenko@example.com", []string{"ops@example.com"}).Notify(context.Background(), notification())
+	if err == nil || !strings.Contains(err.Error(), "does not offer STARTTLS") {
+		t.Errorf("err = %v, want starttls required", err)
+	}
+}
+
+func TestRender_Recovery(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Result.Error, n.Result.StatusCode = "", 200
+	n.Outage = &kenko.Outage{Start: n.At.Add(-time.Hour), Duration: time.Hour, FirstError: "timeout", LastError: "status 503"}
+
+	subject, body, err := New("localhost:25", "kenko@example.com", nil).render(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "[kenko] api recovered" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{"Down for 1h0m0s since 2026-03-13 11:00:00 UTC.", "First error: timeout", "Last error: status 503"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+}
+
+func TestRender_Slow(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusHealthy, kenko.StatusHealthy
+	n.Result.Error = ""
+	n.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3, Since: n.At.Add(-time.Minute)}
+
+	subject, body, err := New("localhost:25", "kenko@example.com", nil).render(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "[kenko] api is slow" {
+		t.Errorf("subject = %q", subject)
+	}
+	if want := "api took over 1s for 3 checks, slow since 2026-03-13 11:59:00 UTC."; !strings.Contains(body, want) {
+		t.Errorf("body %q does not contain %q", body, want)
+	}
+
+	n.Slow.Resolved = true
+	if subject, _, _ = New("localhost:25", "kenko@example.com", nil).render(n); subject != "[kenko] api is fast again" {
+		t.Errorf("subject = %q", subject)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	n := New("localhost:25", "kenko@example.com", nil,
+		WithSubject(template.Must(template.New("").Parse("{{.Target}}\n{{.Status}} ({{.Result.Group}})"))),
+		WithBody(template.Must(template.New("").Parse("{{.Result.Error}}"))))
+	notif := notification()
+	notif.Test = true
+	text, err := n.Preview(notif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Subject: [test] api unhealthy (payments)\n\nstatus 503" {
+		t.Errorf("preview = %q", text)
+	}
+}
@@ -0,0 +1,66 @@
+package kenko
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ContentDecoder decompresses a response body served with one Content-Encoding.
+type ContentDecoder func(r io.Reader) (io.Reader, error)
+
+// builtinDecoders are the encodings the standard library can decode. others,
+// such as br, can be added with WithContentDecoder.
+func builtinDecoders() map[string]ContentDecoder {
+	return map[string]ContentDecoder{
+		"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+	}
+}
+
+// requestEncoding advertises the target's expected encodings unless the
+// request already sets Accept-Encoding. setting it ourselves also stops the
+// transport from transparently decompressing gzip, so the served encoding and
+// wire size stay visible.
+func requestEncoding(req *http.Request, target Target) {
+	if len(target.ExpectEncoding) == 0 || req.Header.Get("Accept-Encoding") != "" {
+		return
+	}
+	req.Header.Set("Accept-Encoding", strings.Join(target.ExpectEncoding, ", "))
+}
+
+// checkEncoding degrades the result when the response was not served with
+// one of the expected encodings.
+func checkEncoding(target Target, resp *http.Response, result *Result) {
+	got := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	result.ContentEncoding = got
+	if slices.Contains(target.ExpectEncoding, got) {
+		return
+	}
+
+	if got == "" {
+		got = "identity"
+	}
+	if result.Status == StatusHealthy {
+		result.Status = StatusDegraded
+		result.Error = fmt.Sprintf("content-encoding %s, want %s", got, strings.Join(target.ExpectEncoding, " or "))
+	}
+}
+
+// decodedBody wraps the wire body in a decoder for the response's encoding,
+// returning nil when the encoding is identity or unknown.
+func (c *Checker) decodedBody(body io.Reader, encoding string) (*bodyReader, error) {
+	dec, ok := c.decoders[encoding]
+	if !ok {
+		return nil, nil
+	}
+	r, err := dec(body)
+	if err != nil {
+		return nil, err
+	}
+	return &bodyReader{r: io.LimitReader(r, maxBodyBytes)}, nil
+}
@@ -0,0 +1,104 @@
+package kenko
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheck_ExpectEncoding(t *testing.T) {
+	payload := strings.Repeat("kenko ", 1000)
+	compressed := gzipped(t, payload)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gzip" && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(compressed)
+			return
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("gzip", ts.URL+"/gzip", WithExpectEncoding("br", "GZIP")),
+		WithTarget("plain", ts.URL+"/plain", WithExpectEncoding("gzip")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.check(context.Background(), c.targets[0])
+	if r.Status != StatusHealthy || r.ContentEncoding != "gzip" {
+		t.Fatalf("status = %q, encoding = %q (error: %s), want healthy gzip", r.Status, r.ContentEncoding, r.Error)
+	}
+	if r.BodyBytes != int64(len(compressed)) || r.DecodedBytes != int64(len(payload)) {
+		t.Errorf("body_bytes = %d, decoded_bytes = %d, want %d and %d", r.BodyBytes, r.DecodedBytes, len(compressed), len(payload))
+	}
+
+	r = c.check(context.Background(), c.targets[1])
+	if r.Status != StatusDegraded || !strings.Contains(r.Error, "identity") {
+		t.Errorf("status = %q, error = %q, want degraded for an uncompressed response", r.Status, r.Error)
+	}
+}
+
+func TestCheck_ExpectEncodingCustomDecoder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write([]byte("abc"))
+	}))
+	defer ts.Close()
+
+	double := func(r io.Reader) (io.Reader, error) {
+		b, err := io.ReadAll(r)
+		return bytes.NewReader(bytes.Repeat(b, 2)), err
+	}
+
+	c, err := NewChecker(
+		WithContentDecoder("br", double),
+		WithTarget("br", ts.URL, WithExpectEncoding("br")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.check(context.Background(), c.targets[0])
+	if r.Status != StatusHealthy || r.BodyBytes != 3 || r.DecodedBytes != 6 {
+		t.Errorf("result = %+v, want healthy with 3 wire and 6 decoded bytes", r)
+	}
+}
+
+func TestCheck_ExpectEncodingCorruptBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write([]byte("not gzip"))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("bad", ts.URL, WithExpectEncoding("gzip")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q", r.Status, StatusUnhealthy)
+	}
+}
@@ -0,0 +1,210 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointMode selects how a target is split into separately checked endpoints.
+type EndpointMode int
+
+// Possible EndpointMode values.
+const (
+	// EndpointsSingle checks the target once, over whichever address the
+	// transport picks.
+	EndpointsSingle EndpointMode = iota
+	// EndpointsPerFamily checks the target over IPv4 and IPv6 separately, so
+	// a broken family shows even when the other one works.
+	EndpointsPerFamily
+	// EndpointsPerIP resolves the target's host and checks every address it
+	// returns, e.g. each node behind round-robin DNS.
+	EndpointsPerIP
+)
+
+// Endpoint is the outcome of checking one endpoint of a target: an address
+// family, a resolved address, or a region.
+type Endpoint struct {
+	Name       string        `json:"name"`
+	Status     Status        `json:"status"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Latency    time.Duration `json:"latency"`
+	Error      string        `json:"error,omitempty"`
+	RemoteIP   string        `json:"remote_ip,omitempty"`
+	Region     string        `json:"region,omitempty"`
+}
+
+// WithEndpoints splits the target into endpoints that are checked separately
+// and reported alongside an aggregate status.
+func WithEndpoints(mode EndpointMode) TargetOption {
+	return func(t *Target) { t.Endpoints = mode }
+}
+
+// AggregateStatus combines endpoint statuses: healthy when all are healthy,
+// unhealthy when none are healthy, and degraded when only some are, i.e. the
+// target is partially available.
+func AggregateStatus(statuses ...Status) Status {
+	if len(statuses) == 0 {
+		return StatusUnknown
+	}
+	healthy := 0
+	for _, s := range statuses {
+		if s == StatusHealthy {
+			healthy++
+		}
+	}
+	switch {
+	case healthy == len(statuses):
+		return StatusHealthy
+	case healthy == 0 && !slices.Contains(statuses, StatusDegraded):
+		return StatusUnhealthy
+	default:
+		return StatusDegraded
+	}
+}
+
+// probe checks the target, split into endpoints when it has an EndpointMode,
+// or looks up the latest heartbeat of a heartbeat target.
+func (c *Checker) probe(ctx context.Context, target Target) Result {
+	if target.HeartbeatTimeout > 0 {
+		return c.checkHeartbeat(ctx, target)
+	}
+	if target.Endpoints == EndpointsSingle {
+		return c.check(ctx, target)
+	}
+	return c.checkEndpoints(ctx, target)
+}
+
+// checkEndpoints checks each of the target's endpoints concurrently and
+// aggregates their results.
+func (c *Checker) checkEndpoints(ctx context.Context, target Target) Result {
+	start := time.Now()
+
+	names, targets, err := c.splitEndpoints(ctx, target)
+	if err != nil {
+		return errResult(target, start, err.Error())
+	}
+
+	results := make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.check(ctx, t)
+		}()
+	}
+	wg.Wait()
+
+	endpoints := make([]Endpoint, len(results))
+	for i, r := range results {
+		endpoints[i] = Endpoint{
+			Name:       names[i],
+			Status:     r.Status,
+			StatusCode: r.StatusCode,
+			Latency:    r.Latency,
+			Error:      r.Error,
+			RemoteIP:   r.RemoteIP,
+		}
+	}
+	return aggregate(results, endpoints)
+}
+
+// splitEndpoints returns the endpoint names and the per-endpoint copies of
+// the target to check.
+func (c *Checker) splitEndpoints(ctx context.Context, target Target) ([]string, []Target, error) {
+	switch target.Endpoints {
+	case EndpointsPerFamily:
+		v4, v6 := target, target
+		v4.IPVersion, v6.IPVersion = IPv4, IPv6
+		return []string{"ipv4", "ipv6"}, []Target{v4, v6}, nil
+
+	case EndpointsPerIP:
+		raw, err := render(target.URL, c.templateVars(target))
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad url template: %v", err)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad request: %v", err)
+		}
+
+		ips, err := c.lookupIPs(ctx, target, u.Hostname())
+		if err != nil {
+			return nil, nil, fmt.Errorf("dns lookup %s: %v", u.Hostname(), err)
+		}
+
+		names := make([]string, len(ips))
+		targets := make([]Target, len(ips))
+		for i, ip := range ips {
+			names[i] = ip.String()
+			targets[i] = target
+			targets[i].dialIP, targets[i].dialHost = ip.String(), u.Hostname()
+		}
+		return names, targets, nil
+	}
+	return []string{target.Name}, []Target{target}, nil
+}
+
+// lookupIPs resolves host within the DNS phase timeout, restricted to the
+// target's address family. results are sorted so endpoints keep their order
+// between checks.
+func (c *Checker) lookupIPs(ctx context.Context, target Target, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	network := "ip"
+	switch target.IPVersion {
+	case IPv4:
+		network = "ip4"
+	case IPv6:
+		network = "ip6"
+	}
+
+	if d := target.Timeouts.DNS; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(ips, func(a, b net.IP) int { return strings.Compare(a.String(), b.String()) })
+	return ips, nil
+}
+
+// aggregate builds a target's result from its endpoint results. details such
+// as TLS and timing come from the first healthy endpoint, or the first one
+// when none are healthy.
+func aggregate(results []Result, endpoints []Endpoint) Result {
+	statuses := make([]Status, len(endpoints))
+	for i, ep := range endpoints {
+		statuses[i] = ep.Status
+	}
+
+	i := slices.IndexFunc(results, func(r Result) bool { return r.Status == StatusHealthy })
+	out := results[max(i, 0)]
+	out.Status = AggregateStatus(statuses...)
+	out.Endpoints = endpoints
+	out.Error = ""
+
+	var failed []string
+	for _, ep := range endpoints {
+		if ep.Status != StatusHealthy {
+			failed = append(failed, fmt.Sprintf("%s: %s", ep.Name, cmp.Or(ep.Error, string(ep.Status))))
+		}
+	}
+	if len(failed) > 0 {
+		out.Error = fmt.Sprintf("%d of %d endpoints failing: %s", len(failed), len(endpoints), strings.Join(failed, "; "))
+	}
+	return out
+}
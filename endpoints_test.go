@@ -0,0 +1,137 @@
+package kenko
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAggregateStatus(t *testing.T) {
+	tests := []struct {
+		in   []Status
+		want Status
+	}{
+		{nil, StatusUnknown},
+		{[]Status{StatusHealthy, StatusHealthy}, StatusHealthy},
+		{[]Status{StatusUnhealthy, StatusUnhealthy}, StatusUnhealthy},
+		{[]Status{StatusHealthy, StatusUnhealthy}, StatusDegraded},
+		{[]Status{StatusDegraded, StatusUnhealthy}, StatusDegraded},
+	}
+	for _, tt := range tests {
+		if got := AggregateStatus(tt.in...); got != tt.want {
+			t.Errorf("AggregateStatus(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckEndpoints_PerIP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("api", ts.URL, WithEndpoints(EndpointsPerIP)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.probe(context.Background(), c.targets[0])
+	if result.Status != StatusHealthy || result.Error != "" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(result.Endpoints) != 1 || result.Endpoints[0].Name != "127.0.0.1" || result.Endpoints[0].RemoteIP != "127.0.0.1" {
+		t.Errorf("endpoints = %+v", result.Endpoints)
+	}
+}
+
+func TestCheckEndpoints_PerIPPinsAddress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	c, err := NewChecker(WithTarget("api", "http://kenko.invalid:"+port))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an endpoint dials its own address rather than resolving the host
+	target := c.targets[0]
+	target.dialIP, target.dialHost = "127.0.0.1", "kenko.invalid"
+	if result := c.check(context.Background(), target); result.Status != StatusHealthy {
+		t.Errorf("pinned check = %+v", result)
+	}
+
+	// and every address shares one transport
+	target.dialIP = "127.0.0.2"
+	c.check(context.Background(), target)
+	if n := len(c.transports.byKey); n != 1 {
+		t.Errorf("transports = %d, want 1 shared by the addresses", n)
+	}
+}
+
+func TestCheckEndpoints_PerIPRedirect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("no second loopback address: %v", err)
+	}
+	other := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	other.Listener.Close()
+	other.Listener = l
+	other.Start()
+	defer other.Close()
+	ts := httptest.NewServer(http.RedirectHandler(other.URL, http.StatusFound))
+	defer ts.Close()
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	c, err := NewChecker(WithTarget("api", "http://kenko.invalid:"+port))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the redirect to another host dials that host, not the pinned address
+	target := c.targets[0]
+	target.dialIP, target.dialHost = "127.0.0.1", "kenko.invalid"
+	if result := c.check(context.Background(), target); result.Status != StatusHealthy {
+		t.Errorf("redirected check = %+v", result)
+	}
+}
+
+func TestCheckEndpoints_PartialFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	// localhost resolves to 127.0.0.1 and usually ::1, but the server only
+	// listens on ipv4
+	c, err := NewChecker(WithTarget("api", "http://localhost:"+port, WithEndpoints(EndpointsPerFamily)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.probe(context.Background(), c.targets[0])
+	if len(result.Endpoints) != 2 || result.Endpoints[0].Name != "ipv4" || result.Endpoints[1].Name != "ipv6" {
+		t.Fatalf("endpoints = %+v", result.Endpoints)
+	}
+	if result.Endpoints[0].Status != StatusHealthy {
+		t.Errorf("ipv4 = %+v", result.Endpoints[0])
+	}
+	if result.Endpoints[1].Status != StatusUnhealthy {
+		t.Fatalf("ipv6 = %+v, want unhealthy", result.Endpoints[1])
+	}
+	if result.Status != StatusDegraded {
+		t.Errorf("status = %q, want degraded", result.Status)
+	}
+	if result.StatusCode != http.StatusOK || !strings.HasPrefix(result.Error, "1 of 2 endpoints failing: ipv6:") {
+		t.Errorf("aggregate = code %d, error %q", result.StatusCode, result.Error)
+	}
+}
+
+func TestNewChecker_EndpointConflicts(t *testing.T) {
+	if _, err := NewChecker(WithTarget("api", "https://example.com", WithEndpoints(EndpointsPerFamily), WithIPVersion(IPv6))); err == nil {
+		t.Error("per-family endpoints with an ip version accepted")
+	}
+	if _, err := NewChecker(WithTarget("api", "https://example.com", WithEndpoints(EndpointsPerIP), WithSteps(Step{Name: "a", URL: "https://example.com"}))); err == nil {
+		t.Error("per-ip endpoints with steps accepted")
+	}
+}
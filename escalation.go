@@ -0,0 +1,129 @@
+package kenko
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// EscalationStep sends a failure to more notifiers once it has lasted After,
+// e.g. a pager ten minutes in and a phone call after thirty.
+type EscalationStep struct {
+	After     time.Duration
+	Notifiers []string
+}
+
+func validateEscalation(i int, steps []EscalationStep, notifiers map[string]Notifier) error {
+	for j, step := range steps {
+		if step.After <= 0 {
+			return fmt.Errorf("kenko: route %d: escalation %d: after must be positive, got %s", i, j, step.After)
+		}
+		if len(step.Notifiers) == 0 {
+			return fmt.Errorf("kenko: route %d: escalation %d: no notifiers", i, j)
+		}
+		for _, name := range step.Notifiers {
+			if _, ok := notifiers[name]; !ok {
+				return fmt.Errorf("kenko: route %d: escalation %d: unknown notifier %q", i, j, name)
+			}
+		}
+	}
+	return nil
+}
+
+// escalations tracks the notifiers each failing target was escalated to, so
+// the steps fire once per failure and the failure's later changes, its
+// recovery included, reach them too.
+type escalations struct {
+	mu   sync.Mutex
+	open map[string]map[string]bool
+}
+
+// notified returns the notifiers target's failure was escalated to.
+func (e *escalations) notified(target string) map[string]bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return maps.Clone(e.open[target])
+}
+
+// due returns the notifiers of steps whose wait has passed for a failure
+// that has lasted d and marks them as notified.
+func (e *escalations) due(routes []Route, n Notification, d time.Duration) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sent := e.open[n.Target]
+	var names []string
+	for _, r := range routes {
+		if !r.matches(n) {
+			continue
+		}
+		for _, step := range r.Escalation {
+			if d < step.After {
+				continue
+			}
+			for _, name := range step.Notifiers {
+				if sent[name] {
+					continue
+				}
+				if sent == nil {
+					sent = make(map[string]bool)
+					if e.open == nil {
+						e.open = make(map[string]map[string]bool)
+					}
+					e.open[n.Target] = sent
+				}
+				sent[name] = true
+				names = append(names, name)
+			}
+		}
+		if !r.Continue {
+			break
+		}
+	}
+	return names
+}
+
+func (e *escalations) end(target string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.open, target)
+}
+
+// escalate sends a target's failure to the notifiers of the escalation steps
+// now due, measuring how long it has been failing from its timeline. a
+// recovery ends the escalation; maintenance and pauses hold it, so the
+// notifiers escalated to are still sent the recovery after them.
+func (c *Checker) escalate(r Result) {
+	if c.dispatcher == nil || c.dispatcher.escalate == nil {
+		return
+	}
+	switch r.Status {
+	case StatusHealthy:
+		c.dispatcher.escalate.end(r.Target)
+		return
+	case StatusMaintenance, StatusPaused:
+		return
+	}
+	if c.silenced(r, time.Now()) {
+		// left due, so it fires once the silence ends
+		return
+	}
+
+	since, prev, ok := c.timeline.failingSince(r.Target)
+	if !ok {
+		return
+	}
+	n := Notification{
+		Target:   r.Target,
+		Previous: prev,
+		Status:   r.Status,
+		Result:   r,
+		At:       r.CheckedAt,
+	}
+	if names := c.dispatcher.escalate.due(c.dispatcher.routes, n, r.CheckedAt.Sub(since)); len(names) > 0 {
+		n.Uptime = c.Uptime(r.Target)
+		c.logger.Info("escalating failure", "target", r.Target, "notifiers", names)
+		c.dispatcher.send(n, names)
+	}
+}
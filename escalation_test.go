@@ -0,0 +1,103 @@
+package kenko
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscalation(t *testing.T) {
+	chat, pager, phone := &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}
+	c, err := NewChecker(
+		WithTarget("api", "https://example.com"),
+		WithNotifier("chat", chat), WithNotifier("pager", pager), WithNotifier("phone", phone),
+		WithRoute(Route{Notifiers: []string{"chat"}, Escalation: []EscalationStep{
+			{After: 10 * time.Minute, Notifiers: []string{"pager"}},
+			{After: 30 * time.Minute, Notifiers: []string{"phone"}},
+		}}),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	observe := func(after time.Duration, status Status) {
+		r := Result{Target: "api", Status: status, CheckedAt: start.Add(after)}
+		if prev, changed := c.timeline.observe(r); changed {
+			c.notify(prev, r, nil)
+		}
+		c.escalate(r)
+	}
+	observe(0, StatusHealthy)
+	observe(time.Minute, StatusUnhealthy)
+	observe(5*time.Minute, StatusUnhealthy)
+	observe(11*time.Minute, StatusUnhealthy)
+	observe(12*time.Minute, StatusUnhealthy)
+	observe(20*time.Minute, StatusDegraded)
+	observe(31*time.Minute, StatusDegraded)
+	// maintenance holds the escalation, so the recovery still reaches it
+	observe(35*time.Minute, StatusMaintenance)
+	observe(40*time.Minute, StatusHealthy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.dispatcher.run(ctx)
+
+	for name, tt := range map[string]struct {
+		rec  *recordingNotifier
+		want string
+	}{
+		"chat":  {chat, "unhealthy degraded healthy"},
+		"pager": {pager, "unhealthy degraded healthy"},
+		"phone": {phone, "degraded healthy"},
+	} {
+		var got []string
+		for _, n := range tt.rec.sent {
+			got = append(got, string(n.Status))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s was sent %v, want %s", name, got, tt.want)
+		}
+	}
+	if n := pager.sent[0]; n.Previous != StatusHealthy || !n.At.Equal(start.Add(11*time.Minute)) {
+		t.Errorf("escalation = %+v, want the failure from healthy at +11m", n)
+	}
+	if len(c.dispatcher.escalate.notified("api")) != 0 {
+		t.Error("escalation not ended by the recovery")
+	}
+}
+
+func TestTimeline_FailingSince(t *testing.T) {
+	var tl timeline
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	for i, s := range []Status{StatusMaintenance, StatusDegraded, StatusUnhealthy} {
+		tl.observe(Result{Target: "api", Status: s, CheckedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	since, prev, ok := tl.failingSince("api")
+	if !ok || !since.Equal(start.Add(time.Minute)) || prev != StatusMaintenance {
+		t.Errorf("failingSince = %s, %s, %v, want +1m from maintenance", since, prev, ok)
+	}
+
+	tl.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: start.Add(5 * time.Minute)})
+	if _, _, ok := tl.failingSince("api"); ok {
+		t.Error("healthy target reported failing")
+	}
+}
+
+func TestValidateRoutes_Escalation(t *testing.T) {
+	notifiers := map[string]Notifier{"chat": &recordingNotifier{}}
+	for _, step := range []EscalationStep{
+		{Notifiers: []string{"chat"}},
+		{After: time.Minute},
+		{After: time.Minute, Notifiers: []string{"phone"}},
+	} {
+		routes := []Route{{Notifiers: []string{"chat"}, Escalation: []EscalationStep{step}}}
+		if err := validateRoutes(routes, notifiers); err == nil {
+			t.Errorf("step %+v: expected an error", step)
+		}
+	}
+}
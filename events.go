@@ -0,0 +1,79 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoEvents is returned when the store does not keep an event log.
+var ErrNoEvents = errors.New("kenko: store does not keep events")
+
+// Event records a change of a target's status.
+type Event struct {
+	Target string    `json:"target"`
+	From   Status    `json:"from"`
+	To     Status    `json:"to"`
+	At     time.Time `json:"at"`
+	// Duration is how long the target had been in From.
+	Duration time.Duration `json:"duration"`
+	// Error is the error of the check that caused the change, if any.
+	Error  string `json:"error,omitempty"`
+	Region string `json:"region,omitempty"`
+}
+
+// EventQuery selects events. zero fields leave the query open on that side.
+type EventQuery struct {
+	// Target limits the events to one target.
+	Target string
+	// From and To bound the events by when they happened, inclusive.
+	From, To time.Time
+	// Limit caps how many events are returned.
+	Limit int
+}
+
+// EventStore is implemented by stores that keep a log of status changes.
+type EventStore interface {
+	AddEvent(ctx context.Context, e Event) error
+	// Events returns the events matching q, newest first.
+	Events(ctx context.Context, q EventQuery) ([]Event, error)
+}
+
+// Events returns the recorded status changes matching q, newest first. it
+// returns ErrNoEvents when the store keeps none.
+func (c *Checker) Events(ctx context.Context, q EventQuery) ([]Event, error) {
+	es, ok := c.store.(EventStore)
+	if !ok {
+		return nil, ErrNoEvents
+	}
+	return es.Events(ctx, q)
+}
+
+// recordEvent logs the change from the segment that just closed to the
+// result's status.
+func (c *Checker) recordEvent(ctx context.Context, prev Segment, r Result) {
+	es, ok := c.store.(EventStore)
+	if !ok {
+		return
+	}
+
+	e := Event{
+		Target:   r.Target,
+		From:     prev.Status,
+		To:       r.Status,
+		At:       r.CheckedAt,
+		Duration: prev.Duration(r.CheckedAt),
+		Error:    r.Error,
+		Region:   r.Region,
+	}
+	if err := es.AddEvent(ctx, e); err != nil {
+		c.logger.Warn("failed to record event", "target", r.Target, "error", err)
+	}
+}
+
+// matches reports whether e is selected by q.
+func (q EventQuery) matches(e Event) bool {
+	return (q.Target == "" || e.Target == q.Target) &&
+		(q.From.IsZero() || !e.At.Before(q.From)) &&
+		(q.To.IsZero() || !e.At.After(q.To))
+}
@@ -0,0 +1,116 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckTarget_RecordsEvents(t *testing.T) {
+	var failing atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("api", ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := c.targetList()[0]
+	ctx := context.Background()
+
+	c.checkTarget(ctx, target)
+	c.checkTarget(ctx, target)
+	failing.Store(true)
+	c.checkTarget(ctx, target)
+	failing.Store(false)
+	c.checkTarget(ctx, target)
+
+	events, err := c.Events(ctx, EventQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want the failure and the recovery", events)
+	}
+	recovery, failure := events[0], events[1]
+	if failure.From != StatusHealthy || failure.To != StatusUnhealthy || failure.Duration <= 0 {
+		t.Errorf("failure = %+v", failure)
+	}
+	if recovery.From != StatusUnhealthy || recovery.To != StatusHealthy || recovery.Duration != recovery.At.Sub(failure.At) {
+		t.Errorf("recovery = %+v, want the failure's duration", recovery)
+	}
+}
+
+func TestMemoryStore_Events(t *testing.T) {
+	m := NewMemoryStore()
+	ctx := context.Background()
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i := range maxMemoryEvents + 10 {
+		m.AddEvent(ctx, Event{Target: "api", At: at.Add(time.Duration(i) * time.Second)})
+	}
+
+	events, _ := m.Events(ctx, EventQuery{From: at.Add(time.Duration(maxMemoryEvents) * time.Second), Limit: 5})
+	if len(events) != 5 || !events[0].At.Equal(at.Add(time.Duration(maxMemoryEvents+9)*time.Second)) {
+		t.Errorf("events = %+v, want the newest 5", events)
+	}
+	if all, _ := m.Events(ctx, EventQuery{}); len(all) != maxMemoryEvents {
+		t.Errorf("kept %d events, want %d", len(all), maxMemoryEvents)
+	}
+	if n, _ := m.PruneTransitions(ctx, at.Add(20*time.Second)); n != 10 {
+		t.Errorf("pruned %d, want 10", n)
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithTarget("web", "https://web.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	store.AddEvent(context.Background(), Event{Target: "api", From: StatusHealthy, To: StatusUnhealthy, At: at, Duration: 90 * time.Minute, Error: "connection refused"})
+	store.AddEvent(context.Background(), Event{Target: "web", From: StatusHealthy, To: StatusDegraded, At: at.Add(time.Minute)})
+
+	for query, want := range map[string]int{
+		"":                                 http.StatusOK,
+		"?target=api":                      http.StatusOK,
+		"?target=nope":                     http.StatusNotFound,
+		"?from=yesterday":                  http.StatusBadRequest,
+		"?limit=0":                         http.StatusBadRequest,
+		"?to=2026-03-13T12:00:30Z&limit=1": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events"+query, nil)
+		rec := httptest.NewRecorder()
+		HandleEvents(c)(rec, req)
+		if rec.Code != want {
+			t.Errorf("%q: status = %d, want %d", query, rec.Code, want)
+			continue
+		}
+		if want != http.StatusOK || query == "" {
+			continue
+		}
+
+		var resp eventsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Events) != 1 || resp.Events[0].Target != "api" || resp.Events[0].DurationSeconds != 5400 || resp.Events[0].Error != "connection refused" {
+			t.Errorf("%q: events = %+v, want the api failure", query, resp.Events)
+		}
+	}
+
+	noEvents, _ := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(struct{ Store }{NewMemoryStore()}))
+	rec := httptest.NewRecorder()
+	HandleEvents(noEvents)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("without an event store: status = %d, want %d", rec.Code, http.StatusNotImplemented)
+	}
+}
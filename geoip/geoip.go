@@ -0,0 +1,92 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record holds the fields read from GeoLite2/GeoIP2 ASN, Country and City
+// databases. each database only fills its own fields.
+type record struct {
+	ASN uint   `maxminddb:"autonomous_system_number"`
+	Org string `maxminddb:"autonomous_system_organization"`
+
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Reader implements kenko.IPEnricher over one or more local MaxMind
+// databases, e.g. GeoLite2-ASN.mmdb together with GeoLite2-City.mmdb.
+type Reader struct {
+	dbs []*maxminddb.Reader
+}
+
+// Open opens the MaxMind databases at paths.
+func Open(paths ...string) (*Reader, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("geoip: at least one database is required")
+	}
+
+	r := &Reader{}
+	for _, path := range paths {
+		db, err := maxminddb.Open(path)
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("geoip: open %s: %w", path, err)
+		}
+		r.dbs = append(r.dbs, db)
+	}
+	return r, nil
+}
+
+// Enrich returns the ASN and location of ip, merged across the databases in
+// the order they were opened. it returns nil if no database knows the address.
+func (r *Reader) Enrich(ip net.IP) (*kenko.IPInfo, error) {
+	if ip == nil {
+		return nil, errors.New("geoip: invalid ip")
+	}
+
+	var info kenko.IPInfo
+	for _, db := range r.dbs {
+		var rec record
+		if err := db.Lookup(ip, &rec); err != nil {
+			return nil, fmt.Errorf("geoip: lookup %s: %w", ip, err)
+		}
+
+		if info.ASN == 0 {
+			info.ASN = rec.ASN
+		}
+		if info.Org == "" {
+			info.Org = rec.Org
+		}
+		if info.Country == "" {
+			info.Country = rec.Country.ISOCode
+		}
+		if info.City == "" {
+			info.City = rec.City.Names["en"]
+		}
+	}
+
+	if info == (kenko.IPInfo{}) {
+		return nil, nil
+	}
+	return &info, nil
+}
+
+// Close releases the underlying databases.
+func (r *Reader) Close() error {
+	var errs []error
+	for _, db := range r.dbs {
+		errs = append(errs, db.Close())
+	}
+	return errors.Join(errs...)
+}
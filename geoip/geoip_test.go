@@ -0,0 +1,118 @@
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// mmdb encoding helpers for building tiny test databases. see
+// https://maxmind.github.io/MaxMind-DB/ for the format.
+func ctrl(typ, size int) []byte {
+	var ext []byte
+	if size >= 29 {
+		size, ext = 29, []byte{byte(size - 29)}
+	}
+	if typ <= 7 {
+		return append([]byte{byte(typ<<5 | size)}, ext...)
+	}
+	return append([]byte{byte(size), byte(typ - 7)}, ext...)
+}
+
+func str(s string) []byte { return append(ctrl(2, len(s)), s...) }
+
+func uintOf(typ int, v uint64) []byte {
+	var b []byte
+	for ; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return append(ctrl(typ, len(b)), b...)
+}
+
+func mapOf(kv ...[]byte) []byte {
+	out := ctrl(7, len(kv)/2)
+	for _, b := range kv {
+		out = append(out, b...)
+	}
+	return out
+}
+
+// writeTestDB writes an IPv4 database whose only network, 0.0.0.0/1, maps to data.
+func writeTestDB(t *testing.T, dbType string, data []byte) string {
+	t.Helper()
+
+	// one node: left (first bit 0) points at data offset 0, which is encoded
+	// as node_count + 16; right equals node_count, meaning no data
+	db := []byte{0, 0, 17, 0, 0, 1}
+	db = append(db, make([]byte, 16)...)
+	db = append(db, data...)
+	db = append(db, "\xab\xcd\xefMaxMind.com"...)
+	db = append(db, mapOf(
+		str("node_count"), uintOf(6, 1),
+		str("record_size"), uintOf(5, 24),
+		str("ip_version"), uintOf(5, 4),
+		str("database_type"), str(dbType),
+		str("binary_format_major_version"), uintOf(5, 2),
+		str("binary_format_minor_version"), uintOf(5, 0),
+	)...)
+
+	path := filepath.Join(t.TempDir(), dbType+".mmdb")
+	if err := os.WriteFile(path, db, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnrich_MergesDatabases(t *testing.T) {
+	asn := writeTestDB(t, "GeoLite2-ASN", mapOf(
+		str("autonomous_system_number"), uintOf(6, 13335),
+		str("autonomous_system_organization"), str("Cloudflare"),
+	))
+	city := writeTestDB(t, "GeoLite2-City", mapOf(
+		str("country"), mapOf(str("iso_code"), str("DE")),
+		str("city"), mapOf(str("names"), mapOf(str("en"), str("Frankfurt"))),
+	))
+
+	r, err := Open(asn, city)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	info, err := r.Enrich(net.ParseIP("1.2.3.4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := kenko.IPInfo{ASN: 13335, Org: "Cloudflare", Country: "DE", City: "Frankfurt"}
+	if info == nil || *info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+}
+
+func TestEnrich_UnknownAddress(t *testing.T) {
+	r, err := Open(writeTestDB(t, "GeoLite2-ASN", mapOf(str("autonomous_system_number"), uintOf(6, 1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	info, err := r.Enrich(net.ParseIP("200.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info != nil {
+		t.Errorf("info = %+v, want nil", info)
+	}
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.mmdb")); err == nil {
+		t.Error("expected error for a missing database")
+	}
+	if _, err := Open(); err == nil {
+		t.Error("expected error without databases")
+	}
+}
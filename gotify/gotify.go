@@ -0,0 +1,189 @@
+// Package gotify is a kenko Notifier that sends status changes as messages
+// of a Gotify application.
+package gotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithHTTPClient sets the client messages are sent with (default a client
+// with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// Notifier sends notifications to a Gotify server.
+type Notifier struct {
+	server string
+	token  string
+	client *http.Client
+}
+
+// New creates a Notifier sending to the Gotify server at serverURL as the
+// application whose token is given.
+func New(serverURL, token string, opts ...Option) *Notifier {
+	n := &Notifier{
+		server: strings.TrimSuffix(serverURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Message is the body of a request to Gotify's message api.
+type Message struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+// priorities by status. the Android app raises a notification from 4 and
+// makes it sound from 8.
+var priorities = map[kenko.Status]int{
+	kenko.StatusHealthy:   2,
+	kenko.StatusDegraded:  5,
+	kenko.StatusUnhealthy: 8,
+}
+
+func message(n kenko.Notification) Message {
+	var title string
+	switch {
+	case n.Slow != nil && n.Slow.Resolved:
+		title = fmt.Sprintf("%s is fast again after %s", n.Target, n.At.Sub(n.Slow.Since).Round(time.Second))
+	case n.Slow != nil:
+		title = fmt.Sprintf("%s is slow: over %s for %d checks", n.Target, n.Slow.Threshold, n.Slow.Checks)
+	case n.Outage != nil:
+		title = fmt.Sprintf("%s recovered after %s", n.Target, n.Outage.Duration.Round(time.Second))
+	case n.Repeat > 0:
+		title = fmt.Sprintf("%s is still %s", n.Target, n.Status)
+	default:
+		title = fmt.Sprintf("%s is %s (was %s)", n.Target, n.Status, n.Previous)
+	}
+	if n.Test {
+		title = "[test] " + title
+	}
+
+	var lines []string
+	if o := n.Outage; o != nil {
+		if o.FirstError != "" {
+			lines = append(lines, "First error: "+o.FirstError)
+		}
+		if o.LastError != "" && o.LastError != o.FirstError {
+			lines = append(lines, "Last error: "+o.LastError)
+		}
+	}
+	r := n.Result
+	if r.URL != "" {
+		lines = append(lines, "URL: "+r.URL)
+	}
+	if r.Error != "" {
+		lines = append(lines, "Error: "+r.Error)
+	}
+	if r.StatusCode != 0 {
+		lines = append(lines, fmt.Sprintf("Status code: %d", r.StatusCode))
+	}
+	lines = append(lines, "Latency: "+r.Latency.Round(time.Millisecond).String())
+	if r.Region != "" {
+		lines = append(lines, "Region: "+r.Region)
+	}
+
+	status := n.Status
+	if n.Slow != nil {
+		// a latency alert is as urgent as a degraded target
+		status = kenko.StatusDegraded
+		if n.Slow.Resolved {
+			status = kenko.StatusHealthy
+		}
+	}
+	p, ok := priorities[status]
+	if !ok {
+		p = 5
+	}
+	return Message{Title: title, Message: strings.Join(lines, "\n"), Priority: p}
+}
+
+// Notify sends the notification's message.
+func (g *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	return g.send(ctx, message(n))
+}
+
+// NotifyDigest sends one message for several notifications, with the title
+// of each as a line, at the priority of the most urgent of them.
+func (g *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	return g.send(ctx, digest(ns))
+}
+
+func digest(ns []kenko.Notification) Message {
+	d := Message{Title: fmt.Sprintf("kenko: %d notifications", len(ns))}
+	lines := make([]string, len(ns))
+	for i, n := range ns {
+		m := message(n)
+		lines[i] = m.Title
+		if e := n.Result.Error; e != "" && n.Status != kenko.StatusHealthy && n.Slow == nil {
+			lines[i] += ": " + e
+		}
+		d.Priority = max(d.Priority, m.Priority)
+	}
+	d.Message = strings.Join(lines, "\n")
+	return d
+}
+
+func (g *Notifier) send(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("gotify: marshal: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.server+"/message", bytes.NewReader(body))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("gotify: %w", err))
+	}
+	// a header keeps the token out of urls in errors and server logs
+	req.Header.Set("X-Gotify-Key", g.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	// errors are json such as {"error":"Unauthorized","errorCode":401,"errorDescription":"..."}
+	var result struct {
+		Description string `json:"errorDescription"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&result)
+	code := resp.StatusCode
+	err = fmt.Errorf("gotify: status %d: %s", code, result.Description)
+	if !kenko.RetryableStatus(code) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the title and message Notify would send for n.
+func (g *Notifier) Preview(n kenko.Notification) (string, error) {
+	m := message(n)
+	return m.Title + "\n" + m.Message, nil
+}
@@ -0,0 +1,92 @@
+package gotify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification { return notifytest.Notification(kenko.StatusUnhealthy) }
+
+func TestNotify(t *testing.T) {
+	var got Message
+	var path, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"id":1}`)
+	}))
+	defer srv.Close()
+
+	if err := New(srv.URL+"/", "Aapp.token").Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/message" || key != "Aapp.token" {
+		t.Errorf("path %s, key %q", path, key)
+	}
+	want := Message{
+		Title:    "api is unhealthy (was healthy)",
+		Message:  "URL: https://api.example.com/health\nError: status 503\nStatus code: 503\nLatency: 250ms",
+		Priority: 8,
+	}
+	if got != want {
+		t.Errorf("message = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreview_Recovery(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Result = kenko.Result{Target: "api", Status: kenko.StatusHealthy, StatusCode: 200, Latency: 20 * time.Millisecond}
+	n.Outage = &kenko.Outage{Duration: time.Hour, FirstError: "timeout", LastError: "timeout"}
+	if m := message(n); m.Priority != 2 {
+		t.Errorf("priority = %d, want 2", m.Priority)
+	}
+	text, err := New("https://gotify.example.com", "token").Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "api recovered after 1h0m0s\nFirst error: timeout\nStatus code: 200\nLatency: 20ms"; text != want {
+		t.Errorf("preview = %q, want %q", text, want)
+	}
+}
+
+func TestMessage_Slow(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusHealthy, kenko.StatusHealthy
+	n.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3, Since: n.At.Add(-time.Minute)}
+	if m := message(n); m.Title != "api is slow: over 1s for 3 checks" || m.Priority != 5 {
+		t.Errorf("message = %+v", m)
+	}
+	n.Slow.Resolved = true
+	if m := message(n); m.Title != "api is fast again after 1m0s" || m.Priority != 2 {
+		t.Errorf("message = %+v", m)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	degraded := notification()
+	degraded.Target, degraded.Status, degraded.Result.Error = "web", kenko.StatusDegraded, "slow upstream"
+	got := digest([]kenko.Notification{degraded, notification()})
+	want := Message{
+		Title:    "kenko: 2 notifications",
+		Message:  "web is degraded (was healthy): slow upstream\napi is unhealthy (was healthy): status 503",
+		Priority: 8,
+	}
+	if got != want {
+		t.Errorf("digest = %+v, want %+v", got, want)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, `{"error":"Unauthorized","errorCode":401,"errorDescription":"you need to provide a valid access token"}`, "valid access token", func(url string) error {
+		return New(url, "token").Notify(context.Background(), notification())
+	})
+}
@@ -0,0 +1,133 @@
+package kenko
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// GroupStatus is the rolled-up status of the targets sharing a Group.
+type GroupStatus struct {
+	Name string
+	// Status is healthy when every checked member is, unhealthy when at
+	// least Quorum members are unhealthy, degraded in between, and unknown
+	// before any member has been checked.
+	Status Status
+	// Quorum is how many unhealthy members make the group unhealthy.
+	Quorum    int
+	Members   int
+	Healthy   int
+	Unhealthy int
+	// Degraded counts degraded and unknown members. members not yet checked
+	// are counted in Members only.
+	Degraded int
+}
+
+// GroupReporter is an optional MetricsReporter extension that receives the
+// status of a target's group after each of its checks.
+type GroupReporter interface {
+	ReportGroup(region string, group GroupStatus)
+}
+
+// WithGroupQuorum makes group unhealthy only once n of its members are
+// unhealthy, instead of any one. fewer failures mark it degraded.
+func WithGroupQuorum(group string, n int) Option {
+	return func(o *options) {
+		if o.quorums == nil {
+			o.quorums = make(map[string]int)
+		}
+		o.quorums[group] = n
+	}
+}
+
+// WithGroup puts the target in a group, such as a service made of several
+// endpoints, whose status rolls up its members'.
+func WithGroup(name string) TargetOption {
+	return func(t *Target) { t.Group = name }
+}
+
+func validateQuorums(quorums map[string]int) error {
+	for group, n := range quorums {
+		if n < 1 {
+			return fmt.Errorf("kenko: group %q quorum must be at least 1, got %d", group, n)
+		}
+	}
+	return nil
+}
+
+// Groups returns the status of every group with at least one target, sorted
+// by name.
+func (c *Checker) Groups() ([]GroupStatus, error) {
+	results, err := c.Results()
+	if err != nil {
+		return nil, err
+	}
+	return c.resultGroups(results), nil
+}
+
+// resultGroups rolls up every group from stored results.
+func (c *Checker) resultGroups(results map[string]Result) []GroupStatus {
+	return c.groups("", func(name string) (Status, bool) {
+		r, ok := results[name]
+		return r.Status, ok
+	})
+}
+
+// groups rolls up the current targets by group, or only those in group when
+// it is set, looking up each member's status with status.
+func (c *Checker) groups(group string, status func(target string) (Status, bool)) []GroupStatus {
+	byName := make(map[string]*GroupStatus)
+	for _, t := range c.targetList() {
+		if t.Group == "" || group != "" && t.Group != group {
+			continue
+		}
+		g := byName[t.Group]
+		if g == nil {
+			g = &GroupStatus{Name: t.Group, Quorum: max(c.quorums[t.Group], 1)}
+			byName[t.Group] = g
+		}
+		g.Members++
+		switch s, ok := status(t.Name); {
+		case !ok, c.paused(t), s == StatusMaintenance:
+		case s == StatusHealthy:
+			g.Healthy++
+		case s == StatusUnhealthy:
+			g.Unhealthy++
+		default:
+			g.Degraded++
+		}
+	}
+
+	out := make([]GroupStatus, 0, len(byName))
+	for _, g := range byName {
+		g.Status = g.rollup()
+		out = append(out, *g)
+	}
+	slices.SortFunc(out, func(a, b GroupStatus) int { return cmp.Compare(a.Name, b.Name) })
+	return out
+}
+
+func (g *GroupStatus) rollup() Status {
+	switch {
+	case g.Unhealthy >= g.Quorum:
+		return StatusUnhealthy
+	case g.Unhealthy > 0 || g.Degraded > 0:
+		return StatusDegraded
+	case g.Healthy > 0:
+		return StatusHealthy
+	default:
+		return StatusUnknown
+	}
+}
+
+// reportGroup passes the status of the target's group, as observed by this
+// instance, to a GroupReporter.
+func (c *Checker) reportGroup(t Target) {
+	gr, ok := c.metrics.(GroupReporter)
+	if !ok || t.Group == "" {
+		return
+	}
+	for _, g := range c.groups(t.Group, c.timeline.current) {
+		gr.ReportGroup(c.region, g)
+	}
+}
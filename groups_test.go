@@ -0,0 +1,114 @@
+package kenko
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGroups(t *testing.T) {
+	c, err := NewChecker(
+		WithTarget("api-1", "https://a.example.com", WithGroup("payments")),
+		WithTarget("api-2", "https://b.example.com", WithGroup("payments")),
+		WithTarget("api-3", "https://c.example.com", WithGroup("payments")),
+		WithTarget("web", "https://web.example.com", WithGroup("frontend")),
+		WithTarget("blog", "https://blog.example.com"),
+		WithTarget("new", "https://new.example.com", WithGroup("pending")),
+		WithGroupQuorum("payments", 2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := func(name string, s Status) {
+		_ = c.store.Set(context.Background(), name, Result{Target: name, Status: s})
+	}
+	set("api-1", StatusHealthy)
+	set("api-2", StatusUnhealthy)
+	set("api-3", StatusHealthy)
+	set("web", StatusUnhealthy)
+
+	groups, err := c.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("groups = %+v, want frontend, payments, pending", groups)
+	}
+
+	want := map[string]GroupStatus{
+		"frontend": {Name: "frontend", Status: StatusUnhealthy, Quorum: 1, Members: 1, Unhealthy: 1},
+		"payments": {Name: "payments", Status: StatusDegraded, Quorum: 2, Members: 3, Healthy: 2, Unhealthy: 1},
+		"pending":  {Name: "pending", Status: StatusUnknown, Quorum: 1, Members: 1},
+	}
+	for _, g := range groups {
+		if g != want[g.Name] {
+			t.Errorf("%s = %+v, want %+v", g.Name, g, want[g.Name])
+		}
+	}
+
+	// reaching the quorum takes the group down
+	set("api-3", StatusUnhealthy)
+	groups, _ = c.Groups()
+	if groups[1].Status != StatusUnhealthy {
+		t.Errorf("payments = %+v, want unhealthy", groups[1])
+	}
+}
+
+func TestGroupStatus_Rollup(t *testing.T) {
+	tests := []struct {
+		name string
+		g    GroupStatus
+		want Status
+	}{
+		{"all healthy", GroupStatus{Quorum: 1, Members: 2, Healthy: 2}, StatusHealthy},
+		{"degraded member", GroupStatus{Quorum: 1, Members: 2, Healthy: 1, Degraded: 1}, StatusDegraded},
+		{"below quorum", GroupStatus{Quorum: 2, Members: 3, Healthy: 2, Unhealthy: 1}, StatusDegraded},
+		{"quorum", GroupStatus{Quorum: 2, Members: 3, Healthy: 1, Unhealthy: 2}, StatusUnhealthy},
+		{"unchecked", GroupStatus{Quorum: 1, Members: 2}, StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.g.rollup(); got != tt.want {
+				t.Errorf("rollup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type groupRecorder struct {
+	groups []GroupStatus
+}
+
+func (g *groupRecorder) ReportCheck(string, Status, float64) {}
+
+func (g *groupRecorder) ReportGroup(_ string, gs GroupStatus) { g.groups = append(g.groups, gs) }
+
+func TestCheckTarget_ReportsGroup(t *testing.T) {
+	rec := &groupRecorder{}
+	c, _ := NewChecker(
+		WithTarget("api", "http://127.0.0.1:1", WithGroup("payments")),
+		WithTarget("blog", "http://127.0.0.1:1"),
+		WithMetrics(rec),
+	)
+
+	for _, target := range c.targetList() {
+		c.checkTarget(context.Background(), target)
+	}
+
+	if len(rec.groups) != 1 || rec.groups[0].Name != "payments" || rec.groups[0].Unhealthy != 1 {
+		t.Errorf("reported groups = %+v", rec.groups)
+	}
+	results, _ := c.Results()
+	if results["api"].Group != "payments" {
+		t.Errorf("result group = %q, want payments", results["api"].Group)
+	}
+}
+
+func TestNewChecker_BadQuorum(t *testing.T) {
+	_, err := NewChecker(WithTarget("api", "https://api.example.com"), WithGroupQuorum("payments", 0))
+	if err == nil || !strings.Contains(err.Error(), "quorum") {
+		t.Errorf("err = %v, want quorum error", err)
+	}
+}
@@ -1,9 +1,18 @@
 package kenko
 
 import (
+	"cmp"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,17 +22,76 @@ type healthResponse struct {
 }
 
 type statusResponse struct {
-	Targets []targetResult `json:"targets"`
+	Targets  []targetResult `json:"targets"`
+	Groups   []groupResult  `json:"groups,omitempty"`
+	Silences []Silence      `json:"silences,omitempty"`
+}
+
+type groupResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Quorum    int    `json:"quorum"`
+	Members   int    `json:"members"`
+	Healthy   int    `json:"healthy"`
+	Unhealthy int    `json:"unhealthy"`
+	Degraded  int    `json:"degraded"`
 }
 
 type targetResult struct {
 	Name       string `json:"name"`
 	URL        string `json:"url"`
 	Status     string `json:"status"`
+	Observed   string `json:"observed,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMS  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+	CheckedAt  string `json:"checked_at,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Group      string `json:"group,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+	BodyBytes  int64  `json:"body_bytes,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
+	Encoding   string `json:"content_encoding,omitempty"`
+	Decoded    int64  `json:"decoded_bytes,omitempty"`
+	BodyHash   string `json:"body_hash,omitempty"`
+	Changed    bool   `json:"changed,omitempty"`
+	Body       string `json:"body,omitempty"`
+	Silenced   bool   `json:"silenced,omitempty"`
+
+	TLS      *TLSInfo     `json:"tls,omitempty"`
+	Phases   *phasesMS    `json:"phases_ms,omitempty"`
+	RemoteIP string       `json:"remote_ip,omitempty"`
+	IPInfo   *IPInfo      `json:"ip_info,omitempty"`
+	Steps    []stepResult `json:"steps,omitempty"`
+
+	Endpoints []endpointResult  `json:"endpoints,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type phasesMS struct {
+	DNS      int64 `json:"dns"`
+	Connect  int64 `json:"connect"`
+	TLS      int64 `json:"tls"`
+	TTFB     int64 `json:"ttfb"`
+	Transfer int64 `json:"transfer"`
+}
+
+type endpointResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMS  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+	RemoteIP   string `json:"remote_ip,omitempty"`
+	Region     string `json:"region,omitempty"`
+}
+
+type stepResult struct {
+	Name       string `json:"name"`
 	StatusCode int    `json:"status_code,omitempty"`
 	LatencyMS  int64  `json:"latency_ms"`
 	Error      string `json:"error,omitempty"`
-	CheckedAt  string `json:"checked_at"`
+	Body       string `json:"body,omitempty"`
 }
 
 func writeJSON(w http.ResponseWriter, code int, v any) {
@@ -37,7 +105,11 @@ func HandleHealth(checker *Checker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		resp := healthResponse{Status: "healthy"}
 
-		if hc, ok := checker.store.(HealthChecker); ok {
+		if checker.breaker != nil && checker.breaker.isOpen() {
+			// the breaker probes the store itself, so don't wait on it here
+			resp.Status = "degraded"
+			resp.Redis = "down"
+		} else if hc, ok := checker.store.(HealthChecker); ok {
 			ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 			defer cancel()
 			if err := hc.Ping(ctx); err != nil {
@@ -64,7 +136,12 @@ func HandleReady(checker *Checker) http.HandlerFunc {
 	}
 }
 
-// HandleStatus returns an HTTP handler that reports per-target check results.
+// HandleStatus returns an HTTP handler that reports per-target check results
+// and the active silences.
+// each label query parameter narrows the results to targets with a matching
+// label, e.g. /status?label=team=payments&label=env=prod, each severity
+// parameter to targets with one of the given severities, e.g.
+// /status?severity=critical, and groups to those with a matching member.
 func HandleStatus(checker *Checker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		results, err := checker.Results()
@@ -73,22 +150,1477 @@ func HandleStatus(checker *Checker) http.HandlerFunc {
 			return
 		}
 
+		selectors := r.URL.Query()["label"]
+		severities := r.URL.Query()["severity"]
+		filtered := len(selectors) > 0 || len(severities) > 0
 		resp := statusResponse{
-			Targets: make([]targetResult, 0, len(results)),
+			Targets:  make([]targetResult, 0, len(results)),
+			Silences: checker.Silences(),
 		}
 
+		now := time.Now()
+		matched := make(map[string]bool)
 		for _, r := range results {
-			resp.Targets = append(resp.Targets, targetResult{
-				Name:       r.Target,
-				URL:        r.URL,
-				Status:     string(r.Status),
-				StatusCode: r.StatusCode,
-				LatencyMS:  r.Latency.Milliseconds(),
-				Error:      r.Error,
-				CheckedAt:  r.CheckedAt.Format(time.RFC3339),
+			if !matchLabels(r.Labels, selectors) {
+				continue
+			}
+			if len(severities) > 0 && !slices.Contains(severities, string(r.Severity)) {
+				continue
+			}
+			tr := toTargetResult(r)
+			if r.CheckedAt.IsZero() {
+				tr.CheckedAt = ""
+			}
+			tr.Silenced = checker.silenced(r, now)
+			resp.Targets = append(resp.Targets, tr)
+			matched[r.Group] = true
+		}
+
+		for _, g := range checker.resultGroups(results) {
+			if filtered && !matched[g.Name] {
+				continue
+			}
+			resp.Groups = append(resp.Groups, groupResult{
+				Name:      g.Name,
+				Status:    string(g.Status),
+				Quorum:    g.Quorum,
+				Members:   g.Members,
+				Healthy:   g.Healthy,
+				Unhealthy: g.Unhealthy,
+				Degraded:  g.Degraded,
+			})
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func toTargetResult(r Result) targetResult {
+	var steps []stepResult
+	for _, st := range r.Steps {
+		steps = append(steps, stepResult{
+			Name:       st.Name,
+			StatusCode: st.StatusCode,
+			LatencyMS:  st.Latency.Milliseconds(),
+			Error:      st.Error,
+			Body:       st.Body,
+		})
+	}
+
+	var endpoints []endpointResult
+	for _, ep := range r.Endpoints {
+		endpoints = append(endpoints, endpointResult{
+			Name:       ep.Name,
+			Status:     string(ep.Status),
+			StatusCode: ep.StatusCode,
+			LatencyMS:  ep.Latency.Milliseconds(),
+			Error:      ep.Error,
+			RemoteIP:   ep.RemoteIP,
+			Region:     ep.Region,
+		})
+	}
+
+	var phases *phasesMS
+	if p := r.Phases; p != nil {
+		phases = &phasesMS{
+			DNS:      p.DNS.Milliseconds(),
+			Connect:  p.Connect.Milliseconds(),
+			TLS:      p.TLS.Milliseconds(),
+			TTFB:     p.TTFB.Milliseconds(),
+			Transfer: p.Transfer.Milliseconds(),
+		}
+	}
+
+	return targetResult{
+		Name:       r.Target,
+		URL:        r.URL,
+		Status:     string(r.Status),
+		Observed:   string(r.Observed),
+		StatusCode: r.StatusCode,
+		LatencyMS:  r.Latency.Milliseconds(),
+		Error:      r.Error,
+		CheckedAt:  r.CheckedAt.Format(time.RFC3339),
+		Region:     r.Region,
+		Group:      r.Group,
+		Severity:   string(r.Severity),
+		Labels:     r.Labels,
+		BodyBytes:  r.BodyBytes,
+		Attempts:   r.Attempts,
+		Encoding:   r.ContentEncoding,
+		Decoded:    r.DecodedBytes,
+		BodyHash:   r.BodyHash,
+		Changed:    r.Changed,
+		Body:       r.Body,
+		TLS:        r.TLS,
+		Phases:     phases,
+		RemoteIP:   r.RemoteIP,
+		IPInfo:     r.IPInfo,
+		Steps:      steps,
+		Endpoints:  endpoints,
+	}
+}
+
+type searchResponse struct {
+	Query   string         `json:"query"`
+	Count   int            `json:"count"`
+	Targets []searchResult `json:"targets"`
+}
+
+type searchResult struct {
+	targetResult
+	Tags []string `json:"tags,omitempty"`
+}
+
+// HandleSearch returns an HTTP handler that finds targets by the q query
+// parameter and optionally by status, given as a comma-separated list, e.g.
+// /api/v1/search?q=payments&status=unhealthy,degraded.
+func HandleSearch(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+
+		var statuses []Status
+		if raw := r.URL.Query().Get("status"); raw != "" {
+			for _, s := range strings.Split(raw, ",") {
+				statuses = append(statuses, Status(strings.TrimSpace(s)))
+			}
+		}
+
+		found, err := checker.Search(q, statuses...)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve results"})
+			return
+		}
+
+		resp := searchResponse{Query: q, Count: len(found), Targets: make([]searchResult, 0, len(found))}
+		for _, f := range found {
+			tr := toTargetResult(f.Result)
+			if f.Result.CheckedAt.IsZero() {
+				tr.CheckedAt = ""
+			}
+			resp.Targets = append(resp.Targets, searchResult{targetResult: tr, Tags: f.Target.Tags})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type timelineResponse struct {
+	Target   string            `json:"target"`
+	Summary  string            `json:"summary"`
+	Segments []timelineSegment `json:"segments"`
+}
+
+type timelineSegment struct {
+	Status          string `json:"status"`
+	Start           string `json:"start"`
+	End             string `json:"end,omitempty"`
+	Duration        string `json:"duration"`
+	DurationSeconds int64  `json:"duration_seconds"`
+}
+
+// HandleTimeline returns an HTTP handler that reports the sequence of status
+// segments of the target named by the {name} path value.
+func HandleTimeline(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		segs := checker.Timeline(name)
+		resp := timelineResponse{
+			Target:   name,
+			Segments: make([]timelineSegment, 0, len(segs)),
+		}
+
+		parts := make([]string, 0, len(segs))
+		for _, s := range segs {
+			d := s.Duration(now)
+			ts := timelineSegment{
+				Status:          string(s.Status),
+				Start:           s.Start.Format(time.RFC3339),
+				Duration:        formatDuration(d),
+				DurationSeconds: int64(d.Seconds()),
+			}
+			if !s.End.IsZero() {
+				ts.End = s.End.Format(time.RFC3339)
+			}
+			resp.Segments = append(resp.Segments, ts)
+			parts = append(parts, string(s.Status)+" "+ts.Duration)
+		}
+		resp.Summary = strings.Join(parts, " → ")
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type historyResponse struct {
+	Target  string         `json:"target"`
+	Results []targetResult `json:"results"`
+	// Transitions are the target's status changes in the same range, left
+	// out when the store keeps no events.
+	Transitions []event `json:"transitions,omitempty"`
+	// Next is the before cursor of the following, older page, set when the
+	// limit cut the results short.
+	Next string `json:"next,omitempty"`
+}
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// parseTimeRange parses the from and to query parameters (RFC 3339) of r.
+// either is zero when not set.
+func parseTimeRange(r *http.Request) (from, to time.Time, err error) {
+	query := r.URL.Query()
+	for _, p := range []struct {
+		param string
+		at    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := query.Get(p.param)
+		if raw == "" {
+			continue
+		}
+		if *p.at, err = time.Parse(time.RFC3339, raw); err != nil {
+			return time.Time{}, time.Time{}, errors.New(p.param + " must be an RFC 3339 time")
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// parseLimit parses the limit query parameter of r, defaulting to 100 and at
+// most 1000.
+func parseLimit(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return defaultHistoryLimit, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxHistoryLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxHistoryLimit)
+	}
+	return n, nil
+}
+
+// HandleHistory returns an HTTP handler that reports the stored results of
+// the target named by the {name} path value and its status changes, newest
+// first. from and to bound both by time (RFC 3339), e.g. to plot the last
+// day, and the limit query parameter sets how many of each (default 100, at
+// most 1000). a range with more results is paged: the response's next
+// cursor, passed back as before, returns the results older than the page.
+func HandleHistory(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		var q HistoryQuery
+		var err error
+		if q.From, q.To, err = parseTimeRange(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if q.Limit, err = parseLimit(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if raw := r.URL.Query().Get("before"); raw != "" {
+			before, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "before must be an RFC 3339 time"})
+				return
+			}
+			// To is inclusive, before is not.
+			if before = before.Add(-time.Nanosecond); q.To.IsZero() || before.Before(q.To) {
+				q.To = before
+			}
+		}
+
+		results, err := checker.History(r.Context(), name, q)
+		switch {
+		case errors.Is(err, ErrNoHistory):
+			writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve history"})
+			return
+		}
+
+		resp := historyResponse{Target: name, Results: make([]targetResult, 0, len(results))}
+		for _, res := range results {
+			resp.Results = append(resp.Results, toTargetResult(res))
+		}
+		if len(results) == q.Limit {
+			resp.Next = results[len(results)-1].CheckedAt.Format(time.RFC3339Nano)
+		}
+
+		events, err := checker.Events(r.Context(), EventQuery{Target: name, From: q.From, To: q.To, Limit: q.Limit})
+		switch {
+		case errors.Is(err, ErrNoEvents):
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve events"})
+			return
+		default:
+			resp.Transitions = make([]event, 0, len(events))
+			for _, e := range events {
+				resp.Transitions = append(resp.Transitions, toEvent(e))
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type rollupsResponse struct {
+	Target     string   `json:"target"`
+	Resolution string   `json:"resolution"`
+	Rollups    []rollup `json:"rollups"`
+}
+
+type rollup struct {
+	Start        string  `json:"start"`
+	Checks       int     `json:"checks"`
+	Down         int     `json:"down"`
+	Uptime       float64 `json:"uptime"`
+	MinLatencyMS int64   `json:"min_latency_ms"`
+	AvgLatencyMS int64   `json:"avg_latency_ms"`
+	MaxLatencyMS int64   `json:"max_latency_ms"`
+}
+
+// rollupResolutions maps the resolution query parameter to a rollup
+// resolution and the range served when from is not set.
+var rollupResolutions = map[string]struct {
+	resolution time.Duration
+	span       time.Duration
+}{
+	"5m": {FiveMinuteRollups, 24 * time.Hour},
+	"1h": {HourlyRollups, 90 * 24 * time.Hour},
+}
+
+// HandleRollups returns an HTTP handler that reports the rollups of the
+// target named by the {name} path value, oldest first. resolution is 5m or
+// 1h (default), and from and to bound the buckets by their start (RFC 3339,
+// default the last day of 5m or 90 days of 1h rollups).
+func HandleRollups(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		query := r.URL.Query()
+		res := cmp.Or(query.Get("resolution"), "1h")
+		spec, ok := rollupResolutions[res]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "resolution must be 5m or 1h"})
+			return
+		}
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if from.IsZero() {
+			end := to
+			if end.IsZero() {
+				end = time.Now()
+			}
+			from = end.Add(-spec.span)
+		}
+
+		rollups, err := checker.Rollups(r.Context(), name, spec.resolution, from, to)
+		switch {
+		case errors.Is(err, ErrNoRollups):
+			writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve rollups"})
+			return
+		}
+
+		resp := rollupsResponse{Target: name, Resolution: res, Rollups: make([]rollup, 0, len(rollups))}
+		for _, ru := range rollups {
+			resp.Rollups = append(resp.Rollups, rollup{
+				Start:        ru.Start.Format(time.RFC3339),
+				Checks:       ru.Checks,
+				Down:         ru.Down,
+				Uptime:       ru.Uptime(),
+				MinLatencyMS: ru.MinLatency.Milliseconds(),
+				AvgLatencyMS: ru.AvgLatency().Milliseconds(),
+				MaxLatencyMS: ru.MaxLatency.Milliseconds(),
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type eventsResponse struct {
+	Events []event `json:"events"`
+}
+
+type event struct {
+	Target          string `json:"target"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	At              string `json:"at"`
+	Duration        string `json:"duration"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Error           string `json:"error,omitempty"`
+	Region          string `json:"region,omitempty"`
+}
+
+// HandleEvents returns an HTTP handler that lists recorded status changes,
+// newest first. target limits them to one target, from and to bound them by
+// time (RFC 3339), and limit caps how many are returned.
+func HandleEvents(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var q EventQuery
+
+		if raw := query.Get("target"); raw != "" {
+			name, err := checker.normalizeName(raw)
+			if err != nil || !checker.hasTarget(name) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target " + raw})
+				return
+			}
+			q.Target = name
+		}
+		var err error
+		if q.From, q.To, err = parseTimeRange(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if q.Limit, err = parseLimit(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		events, err := checker.Events(r.Context(), q)
+		switch {
+		case errors.Is(err, ErrNoEvents):
+			writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve events"})
+			return
+		}
+
+		resp := eventsResponse{Events: make([]event, 0, len(events))}
+		for _, e := range events {
+			resp.Events = append(resp.Events, toEvent(e))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func toEvent(e Event) event {
+	return event{
+		Target:          e.Target,
+		From:            string(e.From),
+		To:              string(e.To),
+		At:              e.At.Format(time.RFC3339),
+		Duration:        formatDuration(e.Duration),
+		DurationSeconds: int64(e.Duration.Seconds()),
+		Error:           e.Error,
+		Region:          e.Region,
+	}
+}
+
+type notificationsResponse struct {
+	Notifications []notificationDelivery `json:"notifications"`
+}
+
+type notificationDelivery struct {
+	Notifier  string `json:"notifier"`
+	Target    string `json:"target"`
+	Status    string `json:"status"`
+	Repeat    int    `json:"repeat,omitempty"`
+	Outcome   string `json:"outcome"`
+	At        string `json:"at"`
+	Attempts  int    `json:"attempts,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Region    string `json:"region,omitempty"`
+}
+
+// HandleNotifications returns an HTTP handler that lists recorded
+// notification deliveries, newest first. target and notifier limit them to
+// one target or notifier, from and to bound them by when their outcome was
+// known (RFC 3339), and limit caps how many are returned.
+func HandleNotifications(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var q DeliveryQuery
+
+		if raw := query.Get("target"); raw != "" {
+			name, err := checker.normalizeName(raw)
+			if err != nil || !checker.hasTarget(name) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target " + raw})
+				return
+			}
+			q.Target = name
+		}
+		if name := query.Get("notifier"); name != "" {
+			if _, ok := checker.notifiers[name]; !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown notifier " + name})
+				return
+			}
+			q.Notifier = name
+		}
+		var err error
+		if q.From, q.To, err = parseTimeRange(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if q.Limit, err = parseLimit(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		deliveries, err := checker.Deliveries(r.Context(), q)
+		switch {
+		case errors.Is(err, ErrNoDeliveries):
+			writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve notifications"})
+			return
+		}
+
+		resp := notificationsResponse{Notifications: make([]notificationDelivery, 0, len(deliveries))}
+		for _, d := range deliveries {
+			resp.Notifications = append(resp.Notifications, notificationDelivery{
+				Notifier:  d.Notifier,
+				Target:    d.Target,
+				Status:    string(d.Status),
+				Repeat:    d.Repeat,
+				Outcome:   d.Outcome,
+				At:        d.At.Format(time.RFC3339),
+				Attempts:  d.Attempts,
+				LatencyMS: d.Latency.Milliseconds(),
+				Error:     d.Error,
+				Region:    d.Region,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// exportColumns are the csv columns of a history export.
+var exportColumns = []string{"target", "checked_at", "status", "status_code", "latency_ms", "error", "region", "attempts"}
+
+// HandleExportHistory returns an HTTP handler that streams the stored results
+// of the targets named by target query parameters, or of every target, oldest
+// first. from and to bound the export by check time (RFC 3339), and format is
+// csv or json (the default), a JSON array of results.
+func HandleExportHistory(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		format := cmp.Or(query.Get("format"), "json")
+		if format != "json" && format != "csv" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be csv or json"})
+			return
+		}
+
+		var q HistoryQuery
+		var err error
+		if q.From, q.To, err = parseTimeRange(r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		var names []string
+		for _, raw := range query["target"] {
+			name, err := checker.normalizeName(raw)
+			if err != nil || !checker.hasTarget(name) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target " + raw})
+				return
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			for _, t := range checker.targetList() {
+				names = append(names, t.Name)
+			}
+		}
+
+		// read the first target before writing anything, so an unsupported
+		// store still gets an error status
+		var first []Result
+		if len(names) > 0 {
+			first, err = checker.History(r.Context(), names[0], q)
+		}
+		switch {
+		case errors.Is(err, ErrNoHistory):
+			writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve history"})
+			return
+		}
+
+		ew := newExportWriter(w, format)
+		for i, name := range names {
+			results := first
+			if i > 0 {
+				if results, err = checker.History(r.Context(), name, q); err != nil {
+					// the status is already sent; a truncated export is all
+					// that can be signalled
+					checker.logger.Warn("history export failed", "target", name, "error", err)
+					return
+				}
+			}
+			for _, res := range slices.Backward(results) {
+				ew.write(res)
+			}
+			ew.flush()
+		}
+		ew.close()
+	}
+}
+
+// exportWriter writes a history export as csv or a json array.
+type exportWriter struct {
+	w     http.ResponseWriter
+	csv   *csv.Writer
+	json  *json.Encoder
+	count int
+}
+
+func newExportWriter(w http.ResponseWriter, format string) *exportWriter {
+	ew := &exportWriter{w: w}
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		ew.csv = csv.NewWriter(w)
+		ew.csv.Write(exportColumns)
+		return ew
+	}
+	w.Header().Set("Content-Type", "application/json")
+	ew.json = json.NewEncoder(w)
+	io.WriteString(w, "[")
+	return ew
+}
+
+func (ew *exportWriter) write(r Result) {
+	if ew.csv != nil {
+		ew.csv.Write([]string{
+			r.Target,
+			r.CheckedAt.Format(time.RFC3339Nano),
+			string(r.Status),
+			strconv.Itoa(r.StatusCode),
+			strconv.FormatInt(r.Latency.Milliseconds(), 10),
+			r.Error,
+			r.Region,
+			strconv.Itoa(r.Attempts),
+		})
+		return
+	}
+	if ew.count > 0 {
+		io.WriteString(ew.w, ",")
+	}
+	ew.json.Encode(toTargetResult(r))
+	ew.count++
+}
+
+// flush sends what has been written so far, so large exports stream.
+func (ew *exportWriter) flush() {
+	if ew.csv != nil {
+		ew.csv.Flush()
+	}
+	if f, ok := ew.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (ew *exportWriter) close() {
+	if ew.json != nil {
+		io.WriteString(ew.w, "]\n")
+	}
+	ew.flush()
+}
+
+// deployStats is the JSON form of DeployStats, with latencies in
+// milliseconds.
+type deployStats struct {
+	Checks      int     `json:"checks"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failure_rate"`
+	P50MS       int64   `json:"p50_ms"`
+	P95MS       int64   `json:"p95_ms"`
+}
+
+type deployResponse struct {
+	Target   string      `json:"target"`
+	At       string      `json:"at"`
+	Window   string      `json:"window"`
+	Baseline deployStats `json:"baseline"`
+
+	After       *deployStats `json:"after,omitempty"`
+	Complete    bool         `json:"complete,omitempty"`
+	Regressions []string     `json:"regressions,omitempty"`
+}
+
+type deployRequest struct {
+	Window string `json:"window"`
+}
+
+func toDeployStats(st DeployStats) deployStats {
+	return deployStats{
+		Checks:      st.Checks,
+		Failures:    st.Failures,
+		FailureRate: st.FailureRate,
+		P50MS:       st.P50.Milliseconds(),
+		P95MS:       st.P95.Milliseconds(),
+	}
+}
+
+func toDeployResponse(d Deploy) deployResponse {
+	return deployResponse{
+		Target:   d.Target,
+		At:       d.At.Format(time.RFC3339),
+		Window:   d.Window.String(),
+		Baseline: toDeployStats(d.Baseline),
+	}
+}
+
+// targetName resolves the {name} path value to a configured target, writing
+// an error response and returning false when it is invalid or unknown.
+func targetName(checker *Checker, w http.ResponseWriter, r *http.Request) (string, bool) {
+	name, err := checker.normalizeName(r.PathValue("name"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid target name: " + err.Error()})
+		return "", false
+	}
+	if !checker.hasTarget(name) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target"})
+		return "", false
+	}
+	return name, true
+}
+
+// HandleMarkDeploy returns an HTTP handler that records a deploy marker for a
+// target. the optional JSON body sets the comparison window, e.g. {"window": "15m"}.
+func HandleMarkDeploy(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		var req deployRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+				return
+			}
+		}
+
+		var window time.Duration
+		if req.Window != "" {
+			d, err := time.ParseDuration(req.Window)
+			if err != nil || d <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "window must be a positive duration"})
+				return
+			}
+			window = d
+		}
+
+		d, err := checker.MarkDeploy(name, window)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusCreated, toDeployResponse(d))
+	}
+}
+
+// HandleDeployComparison returns an HTTP handler that compares a target's
+// checks since its latest deploy marker against the baseline before it.
+func HandleDeployComparison(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		cmp, ok := checker.CompareDeploy(name)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no deploy marked"})
+			return
+		}
+
+		resp := toDeployResponse(cmp.Deploy)
+		after := toDeployStats(cmp.After)
+		resp.After = &after
+		resp.Complete = cmp.Complete
+		resp.Regressions = cmp.Regressions
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type uptimeResponse struct {
+	Target          string  `json:"target"`
+	Window          string  `json:"window"`
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Uptime          float64 `json:"uptime"`
+	UptimePercent   float64 `json:"uptime_percent"`
+	Checks          int     `json:"checks"`
+	Down            int     `json:"down"`
+	Downtime        string  `json:"downtime"`
+	DowntimeSeconds int64   `json:"downtime_seconds"`
+	Incidents       int     `json:"incidents"`
+	AvgLatencyMS    int64   `json:"avg_latency_ms"`
+	P50MS           int64   `json:"p50_ms"`
+	P95MS           int64   `json:"p95_ms"`
+	P99MS           int64   `json:"p99_ms"`
+	// the percentiles cover the samples checked in this range
+	Samples     int    `json:"samples"`
+	SamplesFrom string `json:"samples_from,omitempty"`
+	SamplesTo   string `json:"samples_to,omitempty"`
+}
+
+// HandleUptime returns an HTTP handler that reports the uptime, downtime,
+// incident count and latency of the target named by the {name} path value
+// over the window query parameter: 24h, 7d, 30d (the default), or 90d.
+func HandleUptime(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		window := cmp.Or(r.URL.Query().Get("window"), "30d")
+		d, ok := ReportWindows[window]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "window must be 24h, 7d, 30d, or 90d"})
+			return
+		}
+
+		rep, err := checker.UptimeReport(r.Context(), name, d)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to compute uptime"})
+			return
+		}
+		resp := uptimeResponse{
+			Target:          name,
+			Window:          window,
+			From:            rep.From.Format(time.RFC3339),
+			To:              rep.To.Format(time.RFC3339),
+			Uptime:          rep.Uptime,
+			UptimePercent:   math.Round(rep.Uptime*1e5) / 1e3,
+			Checks:          rep.Checks,
+			Down:            rep.Down,
+			Downtime:        formatDuration(rep.Downtime),
+			DowntimeSeconds: int64(rep.Downtime.Seconds()),
+			Incidents:       rep.Incidents,
+			AvgLatencyMS:    rep.AvgLatency.Milliseconds(),
+			P50MS:           rep.P50.Milliseconds(),
+			P95MS:           rep.P95.Milliseconds(),
+			P99MS:           rep.P99.Milliseconds(),
+			Samples:         rep.Samples,
+		}
+		if rep.Samples > 0 {
+			resp.SamplesFrom = rep.SamplesFrom.Format(time.RFC3339)
+			resp.SamplesTo = rep.SamplesTo.Format(time.RFC3339)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type incidentsResponse struct {
+	Target    string     `json:"target"`
+	Uptime    float64    `json:"uptime"`
+	Incidents []incident `json:"incidents"`
+}
+
+type incident struct {
+	ID        string     `json:"id"`
+	Start     string     `json:"start"`
+	End       string     `json:"end,omitempty"`
+	Duration  string     `json:"duration"`
+	Exclusion *Exclusion `json:"exclusion,omitempty"`
+}
+
+type exclusionRequest struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
+
+// HandleIncidents returns an HTTP handler that lists a target's incidents and
+// its uptime with excluded incidents left out.
+func HandleIncidents(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		incs := checker.Incidents(name)
+		resp := incidentsResponse{
+			Target:    name,
+			Uptime:    checker.Uptime(name),
+			Incidents: make([]incident, 0, len(incs)),
+		}
+		for _, inc := range incs {
+			out := incident{
+				ID:        inc.ID,
+				Start:     inc.Start.Format(time.RFC3339),
+				Duration:  formatDuration(Segment{Start: inc.Start, End: inc.End}.Duration(now)),
+				Exclusion: inc.Exclusion,
+			}
+			if !inc.End.IsZero() {
+				out.End = inc.End.Format(time.RFC3339)
+			}
+			resp.Incidents = append(resp.Incidents, out)
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// HandleExcludeIncident returns an HTTP handler that excludes an incident from
+// uptime. the JSON body gives a reason and an optional note.
+func HandleExcludeIncident(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		var req exclusionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Reason == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "a reason is required"})
+			return
+		}
+
+		e, err := checker.ExcludeIncident(r.Context(), name, r.PathValue("id"), req.Reason, req.Note)
+		switch {
+		case errors.Is(err, ErrNoIncident):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown incident"})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save exclusion"})
+		default:
+			writeJSON(w, http.StatusOK, e)
+		}
+	}
+}
+
+// HandleIncludeIncident returns an HTTP handler that removes an incident's
+// exclusion so it counts against uptime again.
+func HandleIncludeIncident(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		err := checker.IncludeIncident(r.Context(), name, r.PathValue("id"))
+		switch {
+		case errors.Is(err, ErrNoIncident):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "incident is not excluded"})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save exclusion"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// maxTargetSpecBytes caps the body of a target API request.
+const maxTargetSpecBytes = 1 << 20
+
+type targetResponse struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// HandleAddTarget returns an HTTP handler that adds a target from the JSON
+// spec in the body, decoded by the checker's TargetDecoder.
+func HandleAddTarget(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		spec, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTargetSpecBytes))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		t, err := checker.AddTarget(r.Context(), spec)
+		switch {
+		case errors.Is(err, ErrTargetExists):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "target already exists"})
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save targets"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusCreated, targetResponse{Name: t.Name, URL: t.URL})
+		}
+	}
+}
+
+// HandleUpdateTarget returns an HTTP handler that replaces the target named
+// by the {name} path value with the JSON spec in the body.
+func HandleUpdateTarget(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+		spec, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTargetSpecBytes))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		t, err := checker.UpdateTarget(r.Context(), name, spec)
+		switch {
+		case errors.Is(err, ErrNoTarget):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target"})
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save targets"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, targetResponse{Name: t.Name, URL: t.URL})
+		}
+	}
+}
+
+// HandleRemoveTarget returns an HTTP handler that stops checking the target
+// named by the {name} path value.
+func HandleRemoveTarget(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		err := checker.RemoveTarget(r.Context(), name)
+		switch {
+		case errors.Is(err, ErrNoTarget):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target"})
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save targets"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+type pauseRequest struct {
+	Comment string `json:"comment"`
+}
+
+// HandlePauses returns an HTTP handler that lists the targets paused through
+// the API.
+func HandlePauses(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, checker.Pauses())
+	}
+}
+
+// HandlePauseTarget returns an HTTP handler that stops checking a target
+// until it is resumed. the optional JSON body gives a comment, e.g.
+// {"comment": "db migration"}.
+func HandlePauseTarget(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		var req pauseRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+				return
+			}
+		}
+
+		p, err := checker.PauseTarget(r.Context(), name, req.Comment)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save pauses"})
+			return
+		}
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+// HandleResumeTarget returns an HTTP handler that resumes checking a target
+// paused through the API.
+func HandleResumeTarget(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		err := checker.ResumeTarget(r.Context(), name)
+		switch {
+		case errors.Is(err, ErrNotPaused):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "target is not paused"})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save pauses"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+type statusPageResponse struct {
+	Title         string                `json:"title,omitempty"`
+	Description   string                `json:"description,omitempty"`
+	Lang          string                `json:"lang,omitempty"`
+	Status        string                `json:"status"`
+	StatusLabel   string                `json:"status_label"`
+	Components    []statusPageComponent `json:"components"`
+	Announcements []Announcement        `json:"announcements"`
+}
+
+type statusPageComponent struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+	StatusLabel string `json:"status_label"`
+}
+
+// HandleStatusPage returns an HTTP handler serving the public status page:
+// the configured components with their current status, and open or recently
+// resolved announcements. it leaves out URLs, errors and other check details.
+// the language is taken from the lang query parameter or Accept-Language.
+func HandleStatusPage(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		results, err := checker.Results()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve results"})
+			return
+		}
+
+		sp := &checker.statusPage
+		var tags []string
+		if lang := r.URL.Query().Get("lang"); lang != "" {
+			tags = append(tags, lang)
+		}
+		lang, loc := sp.locale(append(tags, acceptLanguages(r.Header.Get("Accept-Language"))...))
+
+		resp := statusPageResponse{
+			Title:         cmp.Or(loc.Title, sp.Title),
+			Description:   cmp.Or(loc.Description, sp.Description),
+			Lang:          lang,
+			Components:    []statusPageComponent{},
+			Announcements: checker.currentAnnouncements(time.Now()),
+		}
+		if resp.Announcements == nil {
+			resp.Announcements = []Announcement{}
+		}
+
+		overall := StatusHealthy
+		for _, comp := range checker.components() {
+			status := StatusUnknown
+			if res, ok := results[comp.Target]; ok {
+				status = res.Status
+			}
+			if statusSeverity[status] > statusSeverity[overall] {
+				overall = status
+			}
+
+			text := loc.Components[comp.Target]
+			resp.Components = append(resp.Components, statusPageComponent{
+				ID:          comp.Target,
+				Name:        cmp.Or(text.Name, comp.Name, comp.Target),
+				Description: cmp.Or(text.Description, comp.Description),
+				Status:      string(status),
+				StatusLabel: sp.statusLabel(loc, status),
 			})
 		}
+		resp.Status = string(overall)
+		resp.StatusLabel = sp.statusLabel(loc, overall)
 
 		writeJSON(w, http.StatusOK, resp)
 	}
 }
+
+type announcementRequest struct {
+	Title      string            `json:"title"`
+	Message    string            `json:"message"`
+	State      AnnouncementState `json:"state"`
+	Components []string          `json:"components"`
+}
+
+// HandleAnnouncements returns an HTTP handler that lists every posted
+// announcement, newest first.
+func HandleAnnouncements(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, checker.Announcements())
+	}
+}
+
+// HandlePostAnnouncement returns an HTTP handler that posts an incident to the
+// status page. the JSON body gives a title, message, optional state, and the
+// affected components.
+func HandlePostAnnouncement(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req announcementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid announcement"})
+			return
+		}
+
+		a, err := checker.PostAnnouncement(r.Context(), req.Title, req.Message, req.State, req.Components)
+		switch {
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save announcements"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusCreated, a)
+		}
+	}
+}
+
+// HandleUpdateAnnouncement returns an HTTP handler that adds a message to the
+// announcement named by the {id} path value, optionally changing its state.
+func HandleUpdateAnnouncement(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req announcementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid update"})
+			return
+		}
+
+		a, err := checker.UpdateAnnouncement(r.Context(), r.PathValue("id"), req.Message, req.State)
+		switch {
+		case errors.Is(err, ErrNoAnnouncement):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown announcement"})
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save announcements"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, a)
+		}
+	}
+}
+
+type silenceRequest struct {
+	Targets  []string `json:"targets"`
+	Groups   []string `json:"groups"`
+	Labels   []string `json:"labels"`
+	Duration string   `json:"duration"`
+	Comment  string   `json:"comment"`
+}
+
+// HandleSilences returns an HTTP handler that lists the active silences,
+// newest first.
+func HandleSilences(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, checker.Silences())
+	}
+}
+
+// HandlePostSilence returns an HTTP handler that mutes the notifications of
+// matching targets. the JSON body gives the targets, groups, and label
+// selectors to match, a duration such as "2h", and a comment.
+func HandlePostSilence(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req silenceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid silence"})
+			return
+		}
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "duration must be a positive duration"})
+			return
+		}
+
+		s := Silence{Targets: req.Targets, Groups: req.Groups, Labels: req.Labels, Comment: req.Comment}
+		s, err = checker.AddSilence(r.Context(), s, d)
+		switch {
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save silences"})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusCreated, s)
+		}
+	}
+}
+
+// HandleDeleteSilence returns an HTTP handler that ends the silence named by
+// the {id} path value early.
+func HandleDeleteSilence(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := checker.DeleteSilence(r.Context(), r.PathValue("id"))
+		switch {
+		case errors.Is(err, ErrNoSilence):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown silence"})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save silences"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// HandleDeleteAnnouncement returns an HTTP handler that removes an announcement.
+func HandleDeleteAnnouncement(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := checker.DeleteAnnouncement(r.Context(), r.PathValue("id"))
+		switch {
+		case errors.Is(err, ErrNoAnnouncement):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown announcement"})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save announcements"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// formatDuration renders d compactly with its two most significant units, e.g. "3d 4h" or "12m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int64(d / (24 * time.Hour))
+	hours := int64(d/time.Hour) % 24
+	mins := int64(d/time.Minute) % 60
+	secs := int64(d/time.Second) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, mins)
+	case mins > 0:
+		return fmt.Sprintf("%dm", mins)
+	default:
+		return fmt.Sprintf("%ds", secs)
+	}
+}
+
+type notifierTestRequest struct {
+	Target  string `json:"target"`
+	Status  Status `json:"status"`
+	Preview bool   `json:"preview"`
+}
+
+type notifierTestResponse struct {
+	Notifier string `json:"notifier"`
+	Target   string `json:"target"`
+	Previous Status `json:"previous"`
+	Status   Status `json:"status"`
+	Sent     bool   `json:"sent"`
+	Preview  string `json:"preview,omitempty"`
+}
+
+// HandleTestNotifier returns an HTTP handler that sends a test notification
+// through a notifier. the optional JSON body picks a target whose latest
+// result is used, overrides the notified status, and with "preview": true
+// renders the message without sending it, e.g.
+// {"target": "api", "status": "healthy", "preview": true}.
+func HandleTestNotifier(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if _, ok := checker.notifiers[name]; !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown notifier"})
+			return
+		}
+
+		var req notifierTestRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+				return
+			}
+		}
+		if _, ok := DefaultStatusLabels[req.Status]; req.Status != "" && !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be healthy, degraded, unhealthy, or unknown"})
+			return
+		}
+		if req.Target != "" {
+			target, err := checker.normalizeName(req.Target)
+			if err != nil || !checker.hasTarget(target) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target"})
+				return
+			}
+			req.Target = target
+		}
+
+		n := checker.TestNotification(req.Target, req.Status)
+		resp := notifierTestResponse{Notifier: name, Target: n.Target, Previous: n.Previous, Status: n.Status}
+
+		if req.Preview {
+			text, err := checker.PreviewNotifier(name, n)
+			switch {
+			case errors.Is(err, ErrNoPreview):
+				writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+				return
+			case err != nil:
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "rendering preview: " + err.Error()})
+				return
+			}
+			resp.Preview = text
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		if err := checker.TestNotifier(ctx, name, n); err != nil {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": "delivery failed: " + deliveryError(err)})
+			return
+		}
+		resp.Sent = true
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type heartbeatResponse struct {
+	Target string    `json:"target"`
+	At     time.Time `json:"at"`
+}
+
+// HandleHeartbeat returns an HTTP handler that records a heartbeat for a
+// heartbeat target. the optional JSON body names the sender, e.g.
+// {"instance": "kenko-eu-1", "region": "eu"}; the receive time is used rather
+// than the sender's clock.
+func HandleHeartbeat(checker *Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := targetName(checker, w, r)
+		if !ok {
+			return
+		}
+
+		var hb Heartbeat
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&hb); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+				return
+			}
+		}
+		hb.At = time.Now()
+
+		err := checker.RecordHeartbeat(r.Context(), name, hb)
+		switch {
+		case errors.Is(err, ErrNotHeartbeat):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		case errors.As(err, new(*saveError)):
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save heartbeat"})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, heartbeatResponse{Target: name, At: hb.At})
+	}
+}
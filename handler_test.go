@@ -3,10 +3,12 @@ package kenko
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -88,6 +90,7 @@ func TestHandleStatus_JSON(t *testing.T) {
 		Status:    StatusHealthy,
 		Latency:   42 * time.Millisecond,
 		CheckedAt: time.Now(),
+		Phases:    &Phases{DNS: 3 * time.Millisecond, TTFB: 30 * time.Millisecond},
 	})
 
 	req := httptest.NewRequest(http.MethodGet, "/status", nil)
@@ -109,6 +112,34 @@ func TestHandleStatus_JSON(t *testing.T) {
 	if resp.Targets[0].Name != "api" {
 		t.Errorf("name = %q, want %q", resp.Targets[0].Name, "api")
 	}
+	if p := resp.Targets[0].Phases; p == nil || p.DNS != 3 || p.TTFB != 30 {
+		t.Errorf("phases_ms = %+v, want dns=3 ttfb=30", p)
+	}
+}
+
+func TestHandleStatus_Endpoints(t *testing.T) {
+	c := testChecker()
+
+	_ = c.store.Set(context.Background(), "api", Result{
+		Target: "api",
+		Status: StatusDegraded,
+		Endpoints: []Endpoint{
+			{Name: "ipv4", Status: StatusHealthy, StatusCode: 200, Latency: 12 * time.Millisecond},
+			{Name: "ipv6", Status: StatusUnhealthy, Error: "request failed"},
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	HandleStatus(c)(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var resp statusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	eps := resp.Targets[0].Endpoints
+	if len(eps) != 2 || eps[0].LatencyMS != 12 || eps[1].Status != "unhealthy" || eps[1].Error != "request failed" {
+		t.Errorf("endpoints = %+v", eps)
+	}
 }
 
 type mockHealthStore struct {
@@ -119,3 +150,483 @@ type mockHealthStore struct {
 func (m *mockHealthStore) Ping(_ context.Context) error {
 	return m.pingErr
 }
+
+func TestHandleTimeline(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	c.timeline.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: now.Add(-time.Hour)})
+	c.timeline.observe(Result{Target: "api", Status: StatusUnhealthy, CheckedAt: now.Add(-12 * time.Minute)})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/timeline", nil)
+	req.SetPathValue("name", "api")
+	rec := httptest.NewRecorder()
+	HandleTimeline(c)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp timelineResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(resp.Segments) != 2 {
+		t.Fatalf("segments = %d, want 2", len(resp.Segments))
+	}
+	if resp.Summary != "healthy 48m → unhealthy 12m" {
+		t.Errorf("summary = %q, want %q", resp.Summary, "healthy 48m → unhealthy 12m")
+	}
+}
+
+func TestHandleTimeline_UnknownTarget(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/nope/timeline", nil)
+	req.SetPathValue("name", "nope")
+	rec := httptest.NewRecorder()
+	HandleTimeline(c)(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleTimeline_NormalizesName(t *testing.T) {
+	c, _ := NewChecker(WithNameNormalizer(SlugName), WithTarget("API Server", "https://api.example.com"))
+
+	for name, want := range map[string]int{"API_Server": http.StatusOK, "api/../x": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/x/timeline", nil)
+		req.SetPathValue("name", name)
+		rec := httptest.NewRecorder()
+		HandleTimeline(c)(rec, req)
+
+		if rec.Code != want {
+			t.Errorf("%q: status = %d, want %d", name, rec.Code, want)
+		}
+	}
+}
+
+func TestHandleMarkDeploy(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/targets/api/deploys", strings.NewReader(`{"window":"15m"}`))
+	req.SetPathValue("name", "api")
+	rec := httptest.NewRecorder()
+	HandleMarkDeploy(c)(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	var resp deployResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Window != "15m0s" {
+		t.Errorf("window = %q, want %q", resp.Window, "15m0s")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/deploys/latest", nil)
+	req.SetPathValue("name", "api")
+	rec = httptest.NewRecorder()
+	HandleDeployComparison(c)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("comparison status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHandleMarkDeploy_BadWindow(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/targets/api/deploys", strings.NewReader(`{"window":"soon"}`))
+	req.SetPathValue("name", "api")
+	rec := httptest.NewRecorder()
+	HandleMarkDeploy(c)(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleIncidents_Exclusion(t *testing.T) {
+	c, _ := incidentChecker(t, NewMemoryStore())
+	id := c.Incidents("api")[0].ID
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/targets/api/incidents/"+id+"/exclusion",
+		strings.NewReader(`{"reason":"planned maintenance"}`))
+	req.SetPathValue("name", "api")
+	req.SetPathValue("id", id)
+	rec := httptest.NewRecorder()
+	HandleExcludeIncident(c)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("exclude status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/incidents", nil)
+	req.SetPathValue("name", "api")
+	rec = httptest.NewRecorder()
+	HandleIncidents(c)(rec, req)
+
+	var resp incidentsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Uptime != 1 || len(resp.Incidents) != 1 || resp.Incidents[0].Exclusion == nil {
+		t.Errorf("resp = %+v, want one excluded incident and full uptime", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/targets/api/incidents/"+id+"/exclusion", nil)
+	req.SetPathValue("name", "api")
+	req.SetPathValue("id", id)
+	rec = httptest.NewRecorder()
+	HandleIncludeIncident(c)(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("include status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
+
+func TestHandleExcludeIncident_MissingReason(t *testing.T) {
+	c, _ := incidentChecker(t, NewMemoryStore())
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/targets/api/incidents/1/exclusion", strings.NewReader(`{}`))
+	req.SetPathValue("name", "api")
+	req.SetPathValue("id", "1")
+	rec := httptest.NewRecorder()
+	HandleExcludeIncident(c)(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleStatusPage(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(
+		WithStore(store),
+		WithTarget("api", "https://api.internal"),
+		WithTarget("web", "https://web.internal"),
+		WithTarget("db", "https://db.internal"),
+		WithStatusPage(StatusPage{
+			Title:      "Example status",
+			Components: []Component{{Target: "web", Name: "Website"}, {Target: "api", Name: "API", Description: "REST API"}},
+			Locales: map[string]StatusPageLocale{
+				"fr": {
+					Title:      "État d'Example",
+					Components: map[string]ComponentText{"web": {Name: "Site web"}},
+					Statuses:   map[Status]string{StatusDegraded: "performances dégradées"},
+				},
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	_ = store.Set(ctx, "web", Result{Target: "web", Status: StatusHealthy})
+	_ = store.Set(ctx, "api", Result{Target: "api", Status: StatusDegraded, Error: "secret detail"})
+	if _, err := c.PostAnnouncement(ctx, "Slow API", "Investigating latency.", "", []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status-page", nil)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+	rec := httptest.NewRecorder()
+	HandleStatusPage(c)(rec, req)
+
+	if strings.Contains(rec.Body.String(), "secret detail") || strings.Contains(rec.Body.String(), ".internal") {
+		t.Errorf("status page leaked check details: %s", rec.Body.String())
+	}
+
+	var resp statusPageResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Lang != "fr" || resp.Title != "État d'Example" {
+		t.Errorf("lang, title = %q, %q; want the fr locale", resp.Lang, resp.Title)
+	}
+	if resp.Status != "degraded" || resp.StatusLabel != "performances dégradées" {
+		t.Errorf("overall = %q %q, want degraded in french", resp.Status, resp.StatusLabel)
+	}
+	if len(resp.Components) != 2 || resp.Components[0].Name != "Site web" || resp.Components[1].Description != "REST API" {
+		t.Errorf("components = %+v, want web then api, translated where available", resp.Components)
+	}
+	if len(resp.Announcements) != 1 || resp.Announcements[0].Title != "Slow API" {
+		t.Errorf("announcements = %+v", resp.Announcements)
+	}
+}
+
+func TestHandleAnnouncements_PostAndUpdate(t *testing.T) {
+	c := announcementChecker(t, NewMemoryStore())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/announcements",
+		strings.NewReader(`{"title":"Outage","message":"Looking into it","components":["api"]}`))
+	rec := httptest.NewRecorder()
+	HandlePostAnnouncement(c)(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("post status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var a Announcement
+	_ = json.NewDecoder(rec.Body).Decode(&a)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/announcements/"+a.ID+"/updates",
+		strings.NewReader(`{"message":"Resolved","state":"resolved"}`))
+	req.SetPathValue("id", a.ID)
+	rec = httptest.NewRecorder()
+	HandleUpdateAnnouncement(c)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/announcements/nope/updates", strings.NewReader(`{"message":"x"}`))
+	req.SetPathValue("id", "nope")
+	rec = httptest.NewRecorder()
+	HandleUpdateAnnouncement(c)(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown id status = %d, want 404", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/announcements", strings.NewReader(`{"title":"x"}`))
+	rec = httptest.NewRecorder()
+	HandlePostAnnouncement(c)(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing message status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleSearch(t *testing.T) {
+	c := searchChecker(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=payments&status=unhealthy,degraded", nil)
+	rec := httptest.NewRecorder()
+	HandleSearch(c)(rec, req)
+
+	var resp searchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 1 || resp.Targets[0].Name != "payments-api" || resp.Targets[0].Status != "unhealthy" {
+		t.Fatalf("resp = %+v, want only payments-api", resp)
+	}
+	if len(resp.Targets[0].Tags) != 2 {
+		t.Errorf("tags = %v, want the target's tags", resp.Targets[0].Tags)
+	}
+}
+
+func TestHandleTestNotifier(t *testing.T) {
+	rec := &previewNotifier{}
+	c, _ := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithNotifier("ops", rec),
+		WithNotifier("failing", &recordingNotifier{err: errors.New("connection refused")}),
+	)
+
+	post := func(name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notifiers/"+name+"/test", strings.NewReader(body))
+		req.SetPathValue("name", name)
+		w := httptest.NewRecorder()
+		HandleTestNotifier(c)(w, req)
+		return w
+	}
+
+	w := post("ops", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var resp notifierTestResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Sent || resp.Target != "example" || len(rec.sent) != 1 {
+		t.Errorf("resp = %+v, sent = %d", resp, len(rec.sent))
+	}
+
+	w = post("ops", `{"target":"api","status":"healthy","preview":true}`)
+	resp = notifierTestResponse{}
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.Sent || resp.Preview != "api is healthy: sample failure sent to test notifications" {
+		t.Errorf("preview: status = %d, resp = %+v", w.Code, resp)
+	}
+	if len(rec.sent) != 1 {
+		t.Error("preview sent a notification")
+	}
+
+	tests := map[string]struct {
+		name, body string
+		want       int
+	}{
+		"unknown notifier": {"pager", "", http.StatusNotFound},
+		"unknown target":   {"ops", `{"target":"db"}`, http.StatusNotFound},
+		"bad status":       {"ops", `{"status":"down"}`, http.StatusBadRequest},
+		"bad body":         {"ops", `{`, http.StatusBadRequest},
+		"no preview":       {"failing", `{"preview":true}`, http.StatusNotImplemented},
+		"delivery failure": {"failing", "", http.StatusBadGateway},
+	}
+	for name, tt := range tests {
+		if w := post(tt.name, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestHandleStatus_Groups(t *testing.T) {
+	c, _ := NewChecker(
+		WithTarget("api", "https://api.example.com", WithGroup("payments")),
+		WithTarget("db", "https://db.example.com", WithGroup("payments")),
+	)
+	_ = c.store.Set(context.Background(), "api", Result{Target: "api", Group: "payments", Status: StatusHealthy})
+	_ = c.store.Set(context.Background(), "db", Result{Target: "db", Group: "payments", Status: StatusUnhealthy})
+
+	rec := httptest.NewRecorder()
+	HandleStatus(c)(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var resp statusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Groups) != 1 {
+		t.Fatalf("groups = %+v, want payments", resp.Groups)
+	}
+	g := resp.Groups[0]
+	if g.Name != "payments" || g.Status != "unhealthy" || g.Members != 2 || g.Healthy != 1 {
+		t.Errorf("group = %+v", g)
+	}
+	for _, tr := range resp.Targets {
+		if tr.Group != "payments" {
+			t.Errorf("target %s group = %q", tr.Name, tr.Group)
+		}
+	}
+}
+
+func TestHandleStatus_LabelFilter(t *testing.T) {
+	c, _ := NewChecker(
+		WithTarget("api", "https://api.example.com", WithGroup("payments"), WithLabels(map[string]string{"team": "payments", "env": "prod"})),
+		WithTarget("db", "https://db.example.com", WithGroup("storage"), WithLabels(map[string]string{"team": "storage", "env": "prod"})),
+	)
+	_ = c.store.Set(context.Background(), "api", Result{Target: "api", Group: "payments", Labels: map[string]string{"team": "payments", "env": "prod"}, Status: StatusHealthy})
+	_ = c.store.Set(context.Background(), "db", Result{Target: "db", Group: "storage", Labels: map[string]string{"team": "storage", "env": "prod"}, Status: StatusHealthy})
+
+	status := func(query string) statusResponse {
+		rec := httptest.NewRecorder()
+		HandleStatus(c)(rec, httptest.NewRequest(http.MethodGet, "/status"+query, nil))
+		var resp statusResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := status("?label=team=payments&label=env")
+	if len(resp.Targets) != 1 || resp.Targets[0].Name != "api" || resp.Targets[0].Labels["team"] != "payments" {
+		t.Errorf("targets = %+v, want api with its labels", resp.Targets)
+	}
+	if len(resp.Groups) != 1 || resp.Groups[0].Name != "payments" {
+		t.Errorf("groups = %+v, want payments", resp.Groups)
+	}
+
+	if resp := status("?label=env=staging"); len(resp.Targets) != 0 || len(resp.Groups) != 0 {
+		t.Errorf("staging = %+v, want nothing", resp)
+	}
+	if resp := status(""); len(resp.Targets) != 2 || len(resp.Groups) != 2 {
+		t.Errorf("unfiltered = %+v, want both", resp)
+	}
+}
+
+func TestHandleStatus_SeverityFilter(t *testing.T) {
+	c, _ := NewChecker(
+		WithTarget("api", "https://api.example.com", WithGroup("payments"), WithSeverity(SeverityCritical)),
+		WithTarget("docs", "https://docs.example.com", WithGroup("web"), WithSeverity(SeverityInfo)),
+		WithTarget("blog", "https://blog.example.com", WithGroup("web")),
+	)
+	_ = c.store.Set(context.Background(), "api", Result{Target: "api", Group: "payments", Severity: SeverityCritical, Status: StatusHealthy})
+	_ = c.store.Set(context.Background(), "docs", Result{Target: "docs", Group: "web", Severity: SeverityInfo, Status: StatusHealthy})
+	_ = c.store.Set(context.Background(), "blog", Result{Target: "blog", Group: "web", Status: StatusHealthy})
+
+	status := func(query string) statusResponse {
+		rec := httptest.NewRecorder()
+		HandleStatus(c)(rec, httptest.NewRequest(http.MethodGet, "/status"+query, nil))
+		var resp statusResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := status("?severity=critical")
+	if len(resp.Targets) != 1 || resp.Targets[0].Name != "api" || resp.Targets[0].Severity != "critical" {
+		t.Errorf("targets = %+v, want api with its severity", resp.Targets)
+	}
+	if len(resp.Groups) != 1 || resp.Groups[0].Name != "payments" {
+		t.Errorf("groups = %+v, want payments", resp.Groups)
+	}
+	if resp := status("?severity=critical&severity=info"); len(resp.Targets) != 2 {
+		t.Errorf("critical or info = %+v, want api and docs", resp.Targets)
+	}
+}
+
+func TestHandleHeartbeat(t *testing.T) {
+	c, _ := NewChecker(
+		WithTarget("peer", "", WithHeartbeatTimeout(time.Minute)),
+		WithTarget("api", "https://api.example.com"),
+	)
+
+	post := func(name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/targets/"+name+"/heartbeat", strings.NewReader(body))
+		req.SetPathValue("name", name)
+		rec := httptest.NewRecorder()
+		HandleHeartbeat(c)(rec, req)
+		return rec
+	}
+
+	if rec := post("peer", `{"instance":"kenko-eu-1","at":"2000-01-01T00:00:00Z"}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	hb, ok := c.heartbeats.get("peer")
+	if !ok || hb.Instance != "kenko-eu-1" || time.Since(hb.At) > time.Minute {
+		t.Errorf("heartbeat = %+v, want receive time kept", hb)
+	}
+
+	if rec := post("api", ""); rec.Code != http.StatusConflict {
+		t.Errorf("non-heartbeat target: status = %d, want 409", rec.Code)
+	}
+	if rec := post("db", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown target: status = %d, want 404", rec.Code)
+	}
+
+	c.store = failingStateStore{NewMemoryStore()}
+	if rec := post("peer", ""); rec.Code != http.StatusInternalServerError {
+		t.Errorf("unsaved heartbeat: status = %d, want 500", rec.Code)
+	}
+	if got, _ := c.heartbeats.get("peer"); !got.At.Equal(hb.At) {
+		t.Errorf("heartbeat = %+v, want the unsaved one not recorded", got)
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?from=2026-03-12T12:00:00Z", nil)
+	from, to, err := parseTimeRange(req)
+	if err != nil || !from.Equal(time.Date(2026, 3, 12, 12, 0, 0, 0, time.UTC)) || !to.IsZero() {
+		t.Errorf("from, to = %v, %v, err %v", from, to, err)
+	}
+
+	for query, want := range map[string]string{
+		"?to=yesterday": "to must be an RFC 3339 time",
+		"?from=2026-03-12T12:00:00Z&to=2026-03-11T12:00:00Z": "from must not be after to",
+	} {
+		if _, _, err := parseTimeRange(httptest.NewRequest(http.MethodGet, "/"+query, nil)); err == nil || err.Error() != want {
+			t.Errorf("%s: err = %v, want %q", query, err, want)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	for query, want := range map[string]int{"": defaultHistoryLimit, "?limit=5": 5, "?limit=0": 0, "?limit=1001": 0} {
+		n, err := parseLimit(httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		if n != want || (err == nil) != (want != 0) {
+			t.Errorf("%q: limit = %d, err %v, want %d", query, n, err, want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package kenko
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// heartbeatStatePrefix prefixes the state key holding a target's last
+// heartbeat, so instances sharing a store see heartbeats sent to any of them.
+const heartbeatStatePrefix = "heartbeat:"
+
+// ErrNotHeartbeat is returned when recording a heartbeat for a target that
+// does not expect heartbeats.
+var ErrNotHeartbeat = errors.New("kenko: target does not expect heartbeats")
+
+// Heartbeat is a liveness signal pushed to a heartbeat target, e.g. by another
+// kenko instance monitoring this one.
+type Heartbeat struct {
+	// At is when the heartbeat was received.
+	At       time.Time `json:"at"`
+	Instance string    `json:"instance,omitempty"`
+	Region   string    `json:"region,omitempty"`
+}
+
+// WithHeartbeatTimeout turns the target into a passive heartbeat target: it
+// is not requested, but stays healthy while a heartbeat has arrived within d
+// and turns unhealthy once one is overdue. heartbeats are recorded with
+// RecordHeartbeat or POST /api/v1/targets/{name}/heartbeat.
+func WithHeartbeatTimeout(d time.Duration) TargetOption {
+	return func(t *Target) { t.HeartbeatTimeout = d }
+}
+
+// HeartbeatSender configures heartbeats this instance sends to a peer kenko
+// instance or an external dead-man's switch, so its own failure is noticed.
+type HeartbeatSender struct {
+	// URL receives a POST with a JSON Heartbeat, e.g. a peer's
+	// /api/v1/targets/{name}/heartbeat or a healthchecks.io ping url.
+	URL string
+	// Interval between heartbeats (default the check interval).
+	Interval time.Duration
+	// Header holds extra request headers, e.g. for authentication.
+	Header http.Header
+	// Instance identifies this instance in the heartbeat (default the
+	// hostname).
+	Instance string
+	// Body encodes the request body for a heartbeat (default the Heartbeat
+	// as JSON), e.g. as the always-firing watchdog alert of an Alertmanager.
+	Body func(Heartbeat) ([]byte, error)
+}
+
+// WithHeartbeat sends periodic heartbeats while Run is running. heartbeats are
+// skipped while no check has completed for twice the longest check interval,
+// so a stuck check loop is reported as dead too.
+func WithHeartbeat(hb HeartbeatSender) Option {
+	return func(o *options) { o.heartbeat = &hb }
+}
+
+type heartbeats struct {
+	mu   sync.Mutex
+	last map[string]Heartbeat
+}
+
+func (h *heartbeats) set(name string, hb Heartbeat) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.last == nil {
+		h.last = make(map[string]Heartbeat)
+	}
+	h.last[name] = hb
+}
+
+func (h *heartbeats) get(name string) (Heartbeat, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hb, ok := h.last[name]
+	return hb, ok
+}
+
+// RecordHeartbeat records a heartbeat for a heartbeat target. a zero At is
+// set to now. a heartbeat that cannot be saved is not recorded.
+func (c *Checker) RecordHeartbeat(ctx context.Context, name string, hb Heartbeat) error {
+	var target *Target
+	for _, t := range c.targetList() {
+		if t.Name == name {
+			target = &t
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("kenko: unknown target %q", name)
+	}
+	if target.HeartbeatTimeout <= 0 {
+		return ErrNotHeartbeat
+	}
+
+	if hb.At.IsZero() {
+		hb.At = time.Now()
+	}
+	if err := c.saveState(ctx, heartbeatStatePrefix+name, hb); err != nil {
+		return &saveError{err: err}
+	}
+	c.heartbeats.set(name, hb)
+	return nil
+}
+
+// lastHeartbeat returns the latest heartbeat of the target seen by this
+// instance or, through the store, by any other.
+func (c *Checker) lastHeartbeat(ctx context.Context, name string) (Heartbeat, bool) {
+	hb, ok := c.heartbeats.get(name)
+
+	var stored Heartbeat
+	found, err := c.loadState(ctx, heartbeatStatePrefix+name, &stored)
+	if err != nil {
+		c.logger.Warn("failed to load heartbeat", "target", name, "error", err)
+	}
+	if found && stored.At.After(hb.At) {
+		return stored, true
+	}
+	return hb, ok
+}
+
+// checkHeartbeat reports whether the heartbeat target's latest heartbeat is
+// within its timeout. before the first heartbeat the target is unknown until a
+// full timeout has passed since the checker started.
+func (c *Checker) checkHeartbeat(ctx context.Context, target Target) Result {
+	now := time.Now()
+	result := Result{
+		Target:    target.Name,
+		URL:       target.URL,
+		Status:    StatusHealthy,
+		CheckedAt: now,
+	}
+
+	hb, ok := c.lastHeartbeat(ctx, target.Name)
+	switch {
+	case !ok && now.Sub(c.started) < target.HeartbeatTimeout:
+		result.Status = StatusUnknown
+		result.Error = "waiting for the first heartbeat"
+	case !ok:
+		result.Status = StatusUnhealthy
+		result.Error = "no heartbeat received"
+	case now.Sub(hb.At) > target.HeartbeatTimeout:
+		result.Status = StatusUnhealthy
+		result.Error = fmt.Sprintf("no heartbeat for %s", now.Sub(hb.At).Round(time.Second))
+	}
+	return result
+}
+
+// sendHeartbeats posts a heartbeat every interval until ctx is done or
+// Shutdown is called.
+func (c *Checker) sendHeartbeats(ctx context.Context) {
+	hb := c.heartbeat
+	ticker := time.NewTicker(cmp.Or(hb.Interval, c.interval))
+	defer ticker.Stop()
+
+	instance := hb.Instance
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.life.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if c.stalled(time.Now()) {
+			c.logger.Warn("no recent check completed, skipping heartbeat")
+			continue
+		}
+
+		if err := c.sendHeartbeat(ctx, Heartbeat{At: time.Now(), Instance: instance, Region: c.region}); err != nil {
+			c.logger.Warn("heartbeat failed", "url", hb.URL, "error", err)
+		}
+	}
+}
+
+// stalled reports whether no check has completed for twice the longest wait
+// between checks, or since start-up. a scheduled target waits from the last
+// check until its schedule next matches.
+func (c *Checker) stalled(now time.Time) bool {
+	last := c.started
+	if n := c.lastCheck.Load(); n != 0 {
+		last = time.Unix(0, n)
+	}
+
+	longest := c.interval
+	for _, t := range c.targetList() {
+		longest = max(longest, c.nextCheck(t, last).Sub(last))
+	}
+	return now.Sub(last) > 2*longest
+}
+
+func validateHeartbeat(hb *HeartbeatSender) error {
+	if hb == nil {
+		return nil
+	}
+	u, err := url.Parse(hb.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("kenko: heartbeat url must be an http(s) url, got %q", hb.URL)
+	}
+	if hb.Interval < 0 {
+		return fmt.Errorf("kenko: heartbeat interval must not be negative, got %s", hb.Interval)
+	}
+	return nil
+}
+
+func (c *Checker) sendHeartbeat(ctx context.Context, hb Heartbeat) error {
+	encode := c.heartbeat.Body
+	if encode == nil {
+		encode = func(hb Heartbeat) ([]byte, error) { return json.Marshal(hb) }
+	}
+	body, err := encode(hb)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.heartbeat.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.header.Get("User-Agent"))
+	for k, v := range c.heartbeat.Header {
+		req.Header[k] = v
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckHeartbeat(t *testing.T) {
+	c, err := NewChecker(WithTarget("peer", "", WithHeartbeatTimeout(time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	peer := c.targetList()[0]
+
+	if r := c.probe(ctx, peer); r.Status != StatusUnknown {
+		t.Errorf("before first heartbeat: %s %q, want unknown", r.Status, r.Error)
+	}
+
+	c.started = time.Now().Add(-2 * time.Minute)
+	if r := c.probe(ctx, peer); r.Status != StatusUnhealthy || r.Error != "no heartbeat received" {
+		t.Errorf("never received: %s %q, want unhealthy", r.Status, r.Error)
+	}
+
+	if err := c.RecordHeartbeat(ctx, "peer", Heartbeat{Instance: "kenko-eu"}); err != nil {
+		t.Fatal(err)
+	}
+	if r := c.probe(ctx, peer); r.Status != StatusHealthy {
+		t.Errorf("after heartbeat: %s %q, want healthy", r.Status, r.Error)
+	}
+
+	if err := c.RecordHeartbeat(ctx, "peer", Heartbeat{At: time.Now().Add(-90 * time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if r := c.probe(ctx, peer); r.Status != StatusUnhealthy || !strings.HasPrefix(r.Error, "no heartbeat for 1m30s") {
+		t.Errorf("overdue: %s %q, want unhealthy", r.Status, r.Error)
+	}
+}
+
+func TestRecordHeartbeat_Errors(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"))
+
+	if err := c.RecordHeartbeat(context.Background(), "api", Heartbeat{}); !errors.Is(err, ErrNotHeartbeat) {
+		t.Errorf("err = %v, want ErrNotHeartbeat", err)
+	}
+	if err := c.RecordHeartbeat(context.Background(), "db", Heartbeat{}); err == nil {
+		t.Error("heartbeat for unknown target accepted")
+	}
+}
+
+func TestRecordHeartbeat_SharedThroughStore(t *testing.T) {
+	store := NewMemoryStore()
+	a, _ := NewChecker(WithStore(store), WithTarget("peer", "", WithHeartbeatTimeout(time.Minute)))
+	b, _ := NewChecker(WithStore(store), WithTarget("peer", "", WithHeartbeatTimeout(time.Minute)))
+	b.started = time.Now().Add(-time.Hour)
+
+	if err := a.RecordHeartbeat(context.Background(), "peer", Heartbeat{}); err != nil {
+		t.Fatal(err)
+	}
+	if r := b.probe(context.Background(), b.targetList()[0]); r.Status != StatusHealthy {
+		t.Errorf("other instance: %s %q, want healthy", r.Status, r.Error)
+	}
+}
+
+func TestNewChecker_HeartbeatValidation(t *testing.T) {
+	tests := map[string]Option{
+		"bad url": WithHeartbeat(HeartbeatSender{URL: "peer:6969"}),
+		"steps": WithTarget("peer", "", WithHeartbeatTimeout(time.Minute),
+			WithSteps(Step{URL: "https://example.com"})),
+	}
+	for name, opt := range tests {
+		if _, err := NewChecker(WithTarget("api", "https://api.example.com"), opt); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestSendHeartbeats(t *testing.T) {
+	var got atomic.Value
+	var count atomic.Int32
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hb Heartbeat
+		_ = json.NewDecoder(r.Body).Decode(&hb)
+		got.Store(hb)
+		if r.Header.Get("Authorization") != "Bearer peer" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		count.Add(1)
+	}))
+	defer peer.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+
+	c, err := NewChecker(
+		WithTarget("api", upstream.URL),
+		WithInterval(20*time.Millisecond),
+		WithRegion("eu"),
+		WithHeartbeat(HeartbeatSender{
+			URL:      peer.URL,
+			Interval: 10 * time.Millisecond,
+			Header:   http.Header{"Authorization": {"Bearer peer"}},
+			Instance: "kenko-eu-1",
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for count.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if count.Load() < 2 {
+		t.Fatalf("heartbeats = %d, want at least 2", count.Load())
+	}
+	hb := got.Load().(Heartbeat)
+	if hb.Instance != "kenko-eu-1" || hb.Region != "eu" || hb.At.IsZero() {
+		t.Errorf("heartbeat = %+v", hb)
+	}
+}
+
+func TestSendHeartbeat_Body(t *testing.T) {
+	var body string
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer peer.Close()
+
+	c, err := NewChecker(
+		WithTarget("api", peer.URL),
+		WithHeartbeat(HeartbeatSender{
+			URL:  peer.URL,
+			Body: func(hb Heartbeat) ([]byte, error) { return []byte("alive: " + hb.Instance), nil },
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sendHeartbeat(context.Background(), Heartbeat{Instance: "kenko-eu-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if body != "alive: kenko-eu-1" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestStalled(t *testing.T) {
+	c, _ := NewChecker(
+		WithTarget("fast", "https://a.example.com"),
+		WithTarget("slow", "https://b.example.com", WithCheckInterval(time.Minute)),
+		WithInterval(10*time.Second),
+	)
+	now := time.Now()
+	c.started = now.Add(-time.Hour)
+
+	if !c.stalled(now) {
+		t.Error("no check since an hour ago is not stalled")
+	}
+	c.lastCheck.Store(now.Add(-90 * time.Second).UnixNano())
+	if c.stalled(now) {
+		t.Error("check within twice the longest interval is stalled")
+	}
+}
+
+func TestStalled_Schedule(t *testing.T) {
+	c, err := NewChecker(
+		WithTarget("fast", "https://a.example.com"),
+		WithTarget("nightly", "https://b.example.com", WithSchedule("@daily")),
+		WithInterval(10*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	c.lastCheck.Store(now.Add(-time.Hour).UnixNano())
+
+	if c.stalled(now) {
+		t.Error("check an hour ago is stalled although a target only runs daily")
+	}
+	c.lastCheck.Store(now.Add(-49 * time.Hour).UnixNano())
+	if !c.stalled(now) {
+		t.Error("no check for two days is not stalled")
+	}
+}
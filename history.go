@@ -0,0 +1,37 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoHistory is returned when reading the history of a target from a store
+// that only keeps the latest result of each target.
+var ErrNoHistory = errors.New("kenko: store does not keep history")
+
+// HistoryStore is implemented by stores that keep past results of each
+// target, not just the latest one, so recent history can be served.
+type HistoryStore interface {
+	// History returns the target's results matching q, newest first.
+	History(ctx context.Context, name string, q HistoryQuery) ([]Result, error)
+}
+
+// HistoryQuery selects stored results by when they were checked.
+type HistoryQuery struct {
+	// From and To bound the results, inclusively. a zero value leaves that
+	// end open.
+	From, To time.Time
+	// Limit caps how many results are returned, 0 for no cap.
+	Limit int
+}
+
+// History returns the stored results of a target matching q, newest first,
+// or ErrNoHistory when the store does not keep history.
+func (c *Checker) History(ctx context.Context, name string, q HistoryQuery) ([]Result, error) {
+	hs, ok := c.store.(HistoryStore)
+	if !ok {
+		return nil, ErrNoHistory
+	}
+	return hs.History(ctx, name, q)
+}
@@ -0,0 +1,231 @@
+package kenko
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// historyStore is a MemoryStore that keeps every result, newest last.
+type historyStore struct {
+	*MemoryStore
+	results []Result
+}
+
+func (h *historyStore) Set(ctx context.Context, name string, r Result) error {
+	h.results = append(h.results, r)
+	return h.MemoryStore.Set(ctx, name, r)
+}
+
+func (h *historyStore) History(_ context.Context, name string, q HistoryQuery) ([]Result, error) {
+	var out []Result
+	for i := len(h.results) - 1; i >= 0 && (q.Limit == 0 || len(out) < q.Limit); i-- {
+		if h.results[i].Target == name {
+			out = append(out, h.results[i])
+		}
+	}
+	return out, nil
+}
+
+func TestChecker_History(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(NewMemoryStore(WithHistorySize(0))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.History(context.Background(), "api", HistoryQuery{}); !errors.Is(err, ErrNoHistory) {
+		t.Errorf("history err = %v, want ErrNoHistory with history off", err)
+	}
+}
+
+func TestHandleHistory(t *testing.T) {
+	store := &historyStore{MemoryStore: NewMemoryStore()}
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []Status{StatusHealthy, StatusUnhealthy, StatusHealthy} {
+		store.Set(context.Background(), "api", Result{Target: "api", Status: s})
+	}
+
+	for query, want := range map[string]int{"": http.StatusOK, "?limit=2": http.StatusOK, "?limit=0": http.StatusBadRequest, "?limit=x": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history"+query, nil)
+		req.SetPathValue("name", "api")
+		rec := httptest.NewRecorder()
+		HandleHistory(c)(rec, req)
+		if rec.Code != want {
+			t.Errorf("%q: status = %d, want %d", query, rec.Code, want)
+		}
+		if query != "?limit=2" {
+			continue
+		}
+
+		var resp historyResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Results) != 2 || resp.Results[0].Status != string(StatusHealthy) || resp.Results[1].Status != string(StatusUnhealthy) {
+			t.Errorf("results = %+v, want the last two, newest first", resp.Results)
+		}
+	}
+}
+
+func TestHandleHistory_Range(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i, s := range []Status{StatusHealthy, StatusUnhealthy, StatusHealthy, StatusHealthy} {
+		store.Set(ctx, "api", Result{Target: "api", Status: s, CheckedAt: start.Add(time.Duration(i) * time.Hour)})
+	}
+	store.AddEvent(ctx, Event{Target: "api", From: StatusHealthy, To: StatusUnhealthy, At: start.Add(time.Hour)})
+	store.AddEvent(ctx, Event{Target: "api", From: StatusUnhealthy, To: StatusHealthy, At: start.Add(2 * time.Hour), Duration: time.Hour})
+
+	history := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history"+query, nil)
+		req.SetPathValue("name", "api")
+		rec := httptest.NewRecorder()
+		HandleHistory(c)(rec, req)
+		return rec
+	}
+
+	rec := history("?from=2026-03-13T13:00:00Z&to=2026-03-13T14:00:00Z")
+	var resp historyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Status != string(StatusHealthy) || resp.Results[1].Status != string(StatusUnhealthy) {
+		t.Errorf("results = %+v, want the two in range, newest first", resp.Results)
+	}
+	if len(resp.Transitions) != 2 || resp.Transitions[0].To != string(StatusHealthy) || resp.Transitions[0].DurationSeconds != 3600 {
+		t.Errorf("transitions = %+v, want the failure and recovery, newest first", resp.Transitions)
+	}
+
+	for query, want := range map[string]int{
+		"?from=noon": http.StatusBadRequest,
+		"?from=2026-03-13T14:00:00Z&to=2026-03-13T13:00:00Z": http.StatusBadRequest,
+		"?to=2026-03-13T12:30:00Z&limit=1":                   http.StatusOK,
+	} {
+		if rec := history(query); rec.Code != want {
+			t.Errorf("%q: status = %d, want %d", query, rec.Code, want)
+		}
+	}
+}
+
+func TestHandleHistory_Before(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		store.Set(context.Background(), "api", Result{Target: "api", Status: StatusHealthy, CheckedAt: start.Add(time.Duration(i) * 30 * time.Second)})
+	}
+
+	var pages [][]string
+	query := "?limit=2"
+	for range 4 {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history"+query, nil)
+		req.SetPathValue("name", "api")
+		rec := httptest.NewRecorder()
+		HandleHistory(c)(rec, req)
+		var resp historyResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		var page []string
+		for _, r := range resp.Results {
+			page = append(page, r.CheckedAt)
+		}
+		pages = append(pages, page)
+		if resp.Next == "" {
+			break
+		}
+		query = "?limit=2&before=" + resp.Next
+	}
+
+	if len(pages) != 3 || len(pages[0]) != 2 || len(pages[1]) != 2 || len(pages[2]) != 1 ||
+		pages[0][0] != "2026-03-13T12:02:00Z" || pages[1][0] != "2026-03-13T12:01:00Z" || pages[2][0] != "2026-03-13T12:00:00Z" {
+		t.Errorf("pages = %v, want all five results across three pages, newest first", pages)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history?before=noon", nil)
+	req.SetPathValue("name", "api")
+	rec := httptest.NewRecorder()
+	HandleHistory(c)(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleHistory_NoHistory(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(NewMemoryStore(WithHistorySize(0))))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history", nil)
+	req.SetPathValue("name", "api")
+	rec := httptest.NewRecorder()
+	HandleHistory(c)(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotImplemented)
+	}
+}
+
+func TestHandleExportHistory(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithTarget("web", "https://web.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i, s := range []Status{StatusHealthy, StatusUnhealthy, StatusHealthy} {
+		at := start.Add(time.Duration(i) * time.Minute)
+		store.Set(context.Background(), "api", Result{Target: "api", Status: s, StatusCode: 200, CheckedAt: at})
+		store.Set(context.Background(), "web", Result{Target: "web", Status: StatusHealthy, CheckedAt: at})
+	}
+
+	export := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/history/export"+query, nil)
+		rec := httptest.NewRecorder()
+		HandleExportHistory(c)(rec, req)
+		return rec
+	}
+
+	rec := export("?target=api&from=2026-03-13T12:01:00Z&format=csv")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "target" || rows[1][2] != "unhealthy" || rows[2][2] != "healthy" {
+		t.Errorf("csv = %v, want a header and two rows, oldest first", rows)
+	}
+
+	rec = export("")
+	var results []targetResult
+	if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(results) != 6 || results[0].Name != "api" || results[5].Name != "web" {
+		t.Errorf("json export = %+v, want every result of both targets", results)
+	}
+
+	for query, want := range map[string]int{
+		"?format=xml":  http.StatusBadRequest,
+		"?from=today":  http.StatusBadRequest,
+		"?target=nope": http.StatusNotFound,
+	} {
+		if rec := export(query); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// exclusionsStateKey is the StateStore key holding incident exclusions.
+const exclusionsStateKey = "exclusions"
+
+// ErrNoIncident is returned when an exclusion names an incident that does not exist.
+var ErrNoIncident = errors.New("kenko: no such incident")
+
+// Incident is a period during which a target was unhealthy, taken from its
+// timeline. End is zero while the incident is ongoing.
+type Incident struct {
+	ID     string    `json:"id"`
+	Target string    `json:"target"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	// Exclusion is set when an operator excluded the incident from uptime.
+	Exclusion *Exclusion `json:"exclusion,omitempty"`
+}
+
+// Exclusion annotates an incident as not counting against a target's SLA,
+// e.g. planned maintenance or a third-party fault. excluded incidents stay in
+// history but are left out of uptime.
+type Exclusion struct {
+	Reason    string    `json:"reason"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// incidentID identifies an incident by the start of its timeline segment.
+func incidentID(start time.Time) string {
+	return strconv.FormatInt(start.UnixMilli(), 10)
+}
+
+// exclusions holds incident exclusions per target, keyed by incident ID.
+type exclusions struct {
+	mu       sync.Mutex
+	byTarget map[string]map[string]Exclusion
+}
+
+func (ex *exclusions) get(name, id string) (Exclusion, bool) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	e, ok := ex.byTarget[name][id]
+	return e, ok
+}
+
+// Incidents returns the target's incidents, oldest first.
+func (c *Checker) Incidents(name string) []Incident {
+	var out []Incident
+	for _, s := range c.timeline.get(name) {
+		if s.Status != StatusUnhealthy {
+			continue
+		}
+		inc := Incident{ID: incidentID(s.Start), Target: name, Start: s.Start, End: s.End}
+		if e, ok := c.exclusions.get(name, inc.ID); ok {
+			inc.Exclusion = &e
+		}
+		out = append(out, inc)
+	}
+	return out
+}
+
+// ExcludeIncident excludes one of the target's incidents from uptime and
+// persists the exclusion when the store implements StateStore. it only
+// takes effect once it is persisted.
+func (c *Checker) ExcludeIncident(ctx context.Context, name, id, reason, note string) (Exclusion, error) {
+	if reason == "" {
+		return Exclusion{}, fmt.Errorf("kenko: exclusion reason must not be empty")
+	}
+	if !c.hasIncident(name, id) {
+		return Exclusion{}, ErrNoIncident
+	}
+
+	e := Exclusion{Reason: reason, Note: note, CreatedAt: time.Now()}
+
+	c.exclusions.mu.Lock()
+	defer c.exclusions.mu.Unlock()
+	byTarget := maps.Clone(c.exclusions.byTarget)
+	if byTarget == nil {
+		byTarget = make(map[string]map[string]Exclusion)
+	}
+	byID := maps.Clone(byTarget[name])
+	if byID == nil {
+		byID = make(map[string]Exclusion)
+	}
+	byID[id] = e
+	byTarget[name] = byID
+	if err := c.saveState(ctx, exclusionsStateKey, byTarget); err != nil {
+		return Exclusion{}, err
+	}
+	c.exclusions.byTarget = byTarget
+	return e, nil
+}
+
+// IncludeIncident removes an exclusion, counting the incident against uptime
+// again once the change is persisted.
+func (c *Checker) IncludeIncident(ctx context.Context, name, id string) error {
+	c.exclusions.mu.Lock()
+	defer c.exclusions.mu.Unlock()
+
+	if _, ok := c.exclusions.byTarget[name][id]; !ok {
+		return ErrNoIncident
+	}
+	byTarget := maps.Clone(c.exclusions.byTarget)
+	byID := maps.Clone(byTarget[name])
+	delete(byID, id)
+	byTarget[name] = byID
+	if err := c.saveState(ctx, exclusionsStateKey, byTarget); err != nil {
+		return err
+	}
+	c.exclusions.byTarget = byTarget
+	return nil
+}
+
+func (c *Checker) hasIncident(name, id string) bool {
+	for _, s := range c.timeline.get(name) {
+		if s.Status == StatusUnhealthy && incidentID(s.Start) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// loadExclusions restores persisted exclusions, keeping any made in memory.
+func (c *Checker) loadExclusions(ctx context.Context) error {
+	var stored map[string]map[string]Exclusion
+	if ok, err := c.loadState(ctx, exclusionsStateKey, &stored); !ok {
+		return err
+	}
+
+	c.exclusions.mu.Lock()
+	defer c.exclusions.mu.Unlock()
+	if c.exclusions.byTarget == nil {
+		c.exclusions.byTarget = make(map[string]map[string]Exclusion)
+	}
+	for name, byID := range stored {
+		if c.exclusions.byTarget[name] == nil {
+			c.exclusions.byTarget[name] = make(map[string]Exclusion)
+		}
+		for id, e := range byID {
+			if _, ok := c.exclusions.byTarget[name][id]; !ok {
+				c.exclusions.byTarget[name][id] = e
+			}
+		}
+	}
+	return nil
+}
+
+// Uptime returns the fraction of the target's observed timeline it was not
+// unhealthy. excluded incidents are left out of both the downtime and the
+// total. it returns 1 when nothing has been observed.
+func (c *Checker) Uptime(name string) float64 {
+	now := time.Now()
+
+	var total, down time.Duration
+	for _, s := range c.timeline.get(name) {
+		d := s.Duration(now)
+		if s.Status == StatusUnhealthy {
+			if _, ok := c.exclusions.get(name, incidentID(s.Start)); ok {
+				continue
+			}
+			down += d
+		}
+		total += d
+	}
+
+	if total <= 0 {
+		return 1
+	}
+	return 1 - float64(down)/float64(total)
+}
@@ -0,0 +1,116 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func incidentChecker(t *testing.T, store Store) (*Checker, time.Time) {
+	t.Helper()
+	c, err := NewChecker(WithStore(store), WithTarget("api", "https://example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 30m healthy, 10m unhealthy, 20m healthy
+	start := time.Now().Add(-time.Hour)
+	c.timeline.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: start})
+	c.timeline.observe(Result{Target: "api", Status: StatusUnhealthy, CheckedAt: start.Add(30 * time.Minute)})
+	c.timeline.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: start.Add(40 * time.Minute)})
+	return c, start.Add(30 * time.Minute)
+}
+
+func TestIncidents_ExcludedFromUptime(t *testing.T) {
+	c, incStart := incidentChecker(t, NewMemoryStore())
+
+	incs := c.Incidents("api")
+	if len(incs) != 1 || !incs[0].Start.Equal(incStart) {
+		t.Fatalf("incidents = %+v, want one starting at %v", incs, incStart)
+	}
+
+	if up := c.Uptime("api"); math.Abs(up-50.0/60.0) > 0.001 {
+		t.Errorf("uptime = %v, want %v", up, 50.0/60.0)
+	}
+
+	if _, err := c.ExcludeIncident(context.Background(), "api", incs[0].ID, "planned maintenance", ""); err != nil {
+		t.Fatal(err)
+	}
+	if up := c.Uptime("api"); up != 1 {
+		t.Errorf("uptime with exclusion = %v, want 1", up)
+	}
+	if incs := c.Incidents("api"); incs[0].Exclusion == nil || incs[0].Exclusion.Reason != "planned maintenance" {
+		t.Errorf("incident = %+v, want it to keep the exclusion", incs[0])
+	}
+
+	if err := c.IncludeIncident(context.Background(), "api", incs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if up := c.Uptime("api"); up == 1 {
+		t.Error("expected the incident to count again after removing the exclusion")
+	}
+}
+
+func TestExcludeIncident_Unknown(t *testing.T) {
+	c, _ := incidentChecker(t, NewMemoryStore())
+
+	if _, err := c.ExcludeIncident(context.Background(), "api", "123", "third-party", ""); !errors.Is(err, ErrNoIncident) {
+		t.Errorf("err = %v, want ErrNoIncident", err)
+	}
+	if _, err := c.ExcludeIncident(context.Background(), "api", c.Incidents("api")[0].ID, "", ""); err == nil {
+		t.Error("expected error without a reason")
+	}
+}
+
+func TestExclusions_PersistedInStateStore(t *testing.T) {
+	store := NewMemoryStore()
+	c, _ := incidentChecker(t, store)
+	id := c.Incidents("api")[0].ID
+	if _, err := c.ExcludeIncident(context.Background(), "api", id, "third-party", "upstream dns outage"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, _ := incidentChecker(t, store)
+	if err := restarted.loadExclusions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := restarted.exclusions.get("api", id); !ok || e.Note != "upstream dns outage" {
+		t.Errorf("exclusion = %+v, %v, want it restored from the store", e, ok)
+	}
+}
+
+func TestExclusions_SaveFails(t *testing.T) {
+	store := NewMemoryStore()
+	c, _ := incidentChecker(t, store)
+	ctx := context.Background()
+	id := c.Incidents("api")[0].ID
+
+	c.store = failingStateStore{store}
+	if _, err := c.ExcludeIncident(ctx, "api", id, "third-party", ""); err == nil {
+		t.Fatal("expected an error when the state cannot be saved")
+	}
+	if _, ok := c.exclusions.get("api", id); ok {
+		t.Error("exclusion applied although it was not saved")
+	}
+
+	c.store = store
+	if _, err := c.ExcludeIncident(ctx, "api", id, "third-party", ""); err != nil {
+		t.Fatal(err)
+	}
+	c.store = failingStateStore{store}
+	if err := c.IncludeIncident(ctx, "api", id); err == nil {
+		t.Fatal("expected an error when the state cannot be saved")
+	}
+	if _, ok := c.exclusions.get("api", id); !ok {
+		t.Error("exclusion removed although the removal was not saved")
+	}
+}
+
+func TestUptime_NoHistory(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://example.com"))
+	if up := c.Uptime("api"); up != 1 {
+		t.Errorf("uptime = %v, want 1", up)
+	}
+}
@@ -0,0 +1,270 @@
+// Package influxdb is a kenko MetricsReporter that writes every check result
+// as a point in InfluxDB line protocol, to InfluxDB 1.x or 2.x, or to any
+// store accepting line protocol such as VictoriaMetrics. unlike scraped
+// metrics, each check keeps its own timestamp, latency, and status code.
+//
+// results are buffered and written in batches by Run, so a slow or
+// unreachable database never holds up the checks.
+package influxdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultMeasurement   = "kenko_check"
+	defaultFlushInterval = 10 * time.Second
+	defaultBatchSize     = 1000
+	defaultMaxBuffered   = 10000
+	defaultTimeout       = 10 * time.Second
+	// finalFlushTimeout bounds the write made when Run returns.
+	finalFlushTimeout = 5 * time.Second
+)
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithMeasurement sets the measurement name (default "kenko_check").
+func WithMeasurement(name string) Option {
+	return func(e *Exporter) { e.measurement = name }
+}
+
+// WithToken authenticates writes with an InfluxDB 2.x api token.
+func WithToken(token string) Option {
+	return func(e *Exporter) { e.token = token }
+}
+
+// WithBasicAuth authenticates writes with http basic auth, e.g. an InfluxDB
+// 1.x user.
+func WithBasicAuth(username, password string) Option {
+	return func(e *Exporter) { e.username, e.password = username, password }
+}
+
+// WithTags adds fixed tags to every point, e.g. the instance writing them.
+func WithTags(tags map[string]string) Option {
+	return func(e *Exporter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// WithLabelTags adds the given target label keys as tags, e.g.
+// WithLabelTags("team", "env"). the built-in tags take precedence over a
+// label of the same name.
+func WithLabelTags(keys ...string) Option {
+	return func(e *Exporter) { e.labelTags = append(e.labelTags, keys...) }
+}
+
+// WithFlushInterval sets how often buffered points are written (default 10s).
+func WithFlushInterval(d time.Duration) Option {
+	return func(e *Exporter) { e.interval = d }
+}
+
+// WithBatchSize writes as soon as n points are buffered rather than waiting
+// for the flush interval (default 1000).
+func WithBatchSize(n int) Option {
+	return func(e *Exporter) { e.batchSize = n }
+}
+
+// WithMaxBuffered caps the points kept while the database is unreachable;
+// the oldest are dropped beyond it (default 10000).
+func WithMaxBuffered(n int) Option {
+	return func(e *Exporter) { e.maxBuffered = n }
+}
+
+// WithHTTPClient sets the client writes are sent with (default a client with
+// a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Exporter) { e.client = c }
+}
+
+// WithLogger sets the logger failed writes are reported to (default
+// slog.Default()).
+func WithLogger(l *slog.Logger) Option {
+	return func(e *Exporter) { e.logger = l }
+}
+
+// Exporter buffers check results and writes them to a line protocol write
+// endpoint.
+type Exporter struct {
+	url         string
+	measurement string
+	token       string
+	username    string
+	password    string
+	tags        map[string]string
+	labelTags   []string
+	interval    time.Duration
+	batchSize   int
+	maxBuffered int
+	client      *http.Client
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	lines   [][]byte
+	dropped int
+	full    chan struct{}
+}
+
+// New creates an Exporter writing to url, the full write endpoint including
+// its query, with nanosecond precision, e.g.
+//
+//	http://localhost:8086/api/v2/write?org=acme&bucket=kenko&precision=ns
+//	http://localhost:8086/write?db=kenko&precision=ns
+//	http://localhost:8428/write
+func New(url string, opts ...Option) *Exporter {
+	e := &Exporter{
+		url:         url,
+		measurement: defaultMeasurement,
+		tags:        make(map[string]string),
+		interval:    defaultFlushInterval,
+		batchSize:   defaultBatchSize,
+		maxBuffered: defaultMaxBuffered,
+		client:      &http.Client{Timeout: defaultTimeout},
+		logger:      slog.Default(),
+		full:        make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.maxBuffered = max(e.maxBuffered, e.batchSize)
+	return e
+}
+
+// ReportCheck buffers a point for a check reported without its full result.
+func (e *Exporter) ReportCheck(target string, status kenko.Status, latencySeconds float64) {
+	e.ReportResult(kenko.Result{
+		Target:    target,
+		Status:    status,
+		Latency:   time.Duration(latencySeconds * float64(time.Second)),
+		CheckedAt: time.Now(),
+	})
+}
+
+// ReportResult buffers a point for the result.
+func (e *Exporter) ReportResult(result kenko.Result) {
+	line := e.appendLine(nil, result)
+
+	e.mu.Lock()
+	e.lines = append(e.lines, line)
+	if over := len(e.lines) - e.maxBuffered; over > 0 {
+		e.lines = e.lines[over:]
+		e.dropped += over
+	}
+	n := len(e.lines)
+	e.mu.Unlock()
+
+	if n >= e.batchSize {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run writes the buffered points every flush interval, or once a batch is
+// full, until ctx is done, then writes what is left. failed writes are
+// logged and their points kept for the next attempt.
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			defer cancel()
+			if err := e.Flush(final); err != nil {
+				e.logger.Warn("influxdb write failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+		case <-e.full:
+		}
+		if err := e.Flush(ctx); err != nil {
+			e.logger.Warn("influxdb write failed", "error", err)
+		}
+	}
+}
+
+// Flush writes the buffered points, a batch per request. points of a request
+// that failed to arrive or was refused with 429 or 5xx are put back in front
+// of any buffered since; a batch the database rejects as invalid is dropped.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	lines := e.lines
+	e.lines = nil
+	if e.dropped > 0 {
+		e.logger.Warn("influxdb buffer full, dropped points", "dropped", e.dropped)
+		e.dropped = 0
+	}
+	e.mu.Unlock()
+
+	for len(lines) > 0 {
+		n := min(len(lines), e.batchSize)
+		retry, err := e.write(ctx, lines[:n])
+		if err != nil && retry {
+			e.requeue(lines)
+			return err
+		}
+		lines = lines[n:]
+		if err != nil {
+			e.requeue(lines)
+			return err
+		}
+	}
+	return nil
+}
+
+// requeue puts unwritten lines back in front of the buffer, keeping the
+// newest within the cap.
+func (e *Exporter) requeue(lines [][]byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lines = append(lines, e.lines...)
+	if over := len(e.lines) - e.maxBuffered; over > 0 {
+		e.lines = e.lines[over:]
+		e.dropped += over
+	}
+}
+
+// write sends one batch, reporting whether a failed one is worth retrying.
+func (e *Exporter) write(ctx context.Context, lines [][]byte) (bool, error) {
+	body := bytes.Join(lines, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("influxdb: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "kenko")
+	switch {
+	case e.token != "":
+		req.Header.Set("Authorization", "Token "+e.token)
+	case e.username != "":
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("influxdb: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("influxdb: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return false, nil
+}
@@ -0,0 +1,151 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+type database struct {
+	mu      sync.Mutex
+	status  int
+	auth    []string
+	batches []string
+}
+
+func newDatabase(t *testing.T, status int) (*database, *httptest.Server) {
+	db := &database{status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		db.auth = append(db.auth, r.Header.Get("Authorization"))
+		if db.status != http.StatusNoContent {
+			w.WriteHeader(db.status)
+			io.WriteString(w, `{"message":"unable to parse points"}`)
+			return
+		}
+		db.batches = append(db.batches, string(body))
+		w.WriteHeader(db.status)
+	}))
+	t.Cleanup(srv.Close)
+	return db, srv
+}
+
+func (db *database) setStatus(status int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.status = status
+}
+
+func (db *database) written() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.batches...)
+}
+
+func result(target string) kenko.Result {
+	return kenko.Result{Target: target, Status: kenko.StatusHealthy, CheckedAt: time.Now()}
+}
+
+func TestFlush_Batches(t *testing.T) {
+	db, srv := newDatabase(t, http.StatusNoContent)
+	e := New(srv.URL, WithBatchSize(2), WithToken("secret"))
+	for _, name := range []string{"a", "b", "c"} {
+		e.ReportResult(result(name))
+	}
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	batches := db.written()
+	if len(batches) != 2 || strings.Count(batches[0], "\n") != 2 || !strings.Contains(batches[1], "target=c") {
+		t.Errorf("batches = %q, want a and b, then c", batches)
+	}
+	if db.auth[0] != "Token secret" {
+		t.Errorf("Authorization = %q", db.auth[0])
+	}
+}
+
+func TestFlush_RetriesUnavailable(t *testing.T) {
+	db, srv := newDatabase(t, http.StatusServiceUnavailable)
+	e := New(srv.URL)
+	e.ReportResult(result("a"))
+	if err := e.Flush(context.Background()); err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Fatalf("err = %v, want status 503", err)
+	}
+
+	db.setStatus(http.StatusNoContent)
+	e.ReportResult(result("b"))
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	batches := db.written()
+	if len(batches) != 1 || strings.Index(batches[0], "target=a") > strings.Index(batches[0], "target=b") {
+		t.Errorf("batches = %q, want a kept and written before b", batches)
+	}
+}
+
+func TestFlush_DropsRejected(t *testing.T) {
+	db, srv := newDatabase(t, http.StatusBadRequest)
+	e := New(srv.URL)
+	e.ReportResult(result("a"))
+	if err := e.Flush(context.Background()); err == nil || !strings.Contains(err.Error(), "unable to parse points") {
+		t.Fatalf("err = %v, want the response message", err)
+	}
+
+	db.setStatus(http.StatusNoContent)
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if batches := db.written(); len(batches) != 0 {
+		t.Errorf("batches = %q, want the rejected point dropped", batches)
+	}
+}
+
+func TestReportResult_MaxBuffered(t *testing.T) {
+	e := New("http://localhost", WithBatchSize(1), WithMaxBuffered(2))
+	for _, name := range []string{"a", "b", "c"} {
+		e.ReportResult(result(name))
+	}
+	if len(e.lines) != 2 || e.dropped != 1 || !strings.Contains(string(e.lines[0]), "target=b") {
+		t.Errorf("%d lines, %d dropped, want the oldest of 3 dropped", len(e.lines), e.dropped)
+	}
+}
+
+func TestRun_FlushesFullBatch(t *testing.T) {
+	db, srv := newDatabase(t, http.StatusNoContent)
+	e := New(srv.URL, WithBatchSize(2), WithFlushInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	e.ReportResult(result("a"))
+	e.ReportResult(result("b"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(db.written()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(db.written()) != 1 {
+		t.Fatal("full batch not written before the flush interval")
+	}
+
+	// what is left is written on shutdown
+	e.ReportResult(result("c"))
+	cancel()
+	<-done
+	if batches := db.written(); len(batches) != 2 || !strings.Contains(batches[1], "target=c") {
+		t.Errorf("batches = %q, want c written on shutdown", batches)
+	}
+}
@@ -0,0 +1,82 @@
+package influxdb
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aidantrabs/kenko"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// appendLine appends the result as one line of line protocol:
+//
+//	kenko_check,group=payments,status=healthy,target=api latency_seconds=0.12,up=1i,status_code=200i 1700000000000000000
+//
+// target, region, group, severity, status, and the configured label keys are
+// tags, sorted by key; empty ones are left out.
+func (e *Exporter) appendLine(b []byte, r kenko.Result) []byte {
+	tags := make(map[string]string, len(e.tags)+5+len(e.labelTags))
+	maps.Copy(tags, e.tags)
+	for _, k := range e.labelTags {
+		tags[k] = r.Labels[k]
+	}
+	tags["target"] = r.Target
+	tags["region"] = r.Region
+	tags["group"] = r.Group
+	tags["severity"] = string(r.Severity)
+	tags["status"] = string(r.Status)
+
+	b = append(b, measurementEscaper.Replace(e.measurement)...)
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		if tags[k] == "" {
+			continue
+		}
+		b = append(b, ',')
+		b = append(b, tagEscaper.Replace(k)...)
+		b = append(b, '=')
+		b = append(b, tagEscaper.Replace(tags[k])...)
+	}
+
+	up := 0
+	if r.Status == kenko.StatusHealthy {
+		up = 1
+	}
+	b = append(b, " latency_seconds="...)
+	b = strconv.AppendFloat(b, r.Latency.Seconds(), 'g', -1, 64)
+	b = append(b, ",up="...)
+	b = strconv.AppendInt(b, int64(up), 10)
+	b = append(b, 'i')
+	if r.StatusCode != 0 {
+		b = append(b, ",status_code="...)
+		b = strconv.AppendInt(b, int64(r.StatusCode), 10)
+		b = append(b, 'i')
+	}
+	if r.BodyBytes != 0 {
+		b = append(b, ",body_bytes="...)
+		b = strconv.AppendInt(b, r.BodyBytes, 10)
+		b = append(b, 'i')
+	}
+	if r.Attempts != 0 {
+		b = append(b, ",attempts="...)
+		b = strconv.AppendInt(b, int64(r.Attempts), 10)
+		b = append(b, 'i')
+	}
+	if r.Error != "" {
+		b = append(b, `,error="`...)
+		b = append(b, stringEscaper.Replace(r.Error)...)
+		b = append(b, '"')
+	}
+
+	if !r.CheckedAt.IsZero() {
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, r.CheckedAt.UnixNano(), 10)
+	}
+	return append(b, '\n')
+}
@@ -0,0 +1,55 @@
+package influxdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+func TestAppendLine(t *testing.T) {
+	at := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name   string
+		opts   []Option
+		result kenko.Result
+		want   string
+	}{
+		{
+			name: "healthy",
+			result: kenko.Result{
+				Target: "api", Group: "payments", Severity: kenko.SeverityCritical, Status: kenko.StatusHealthy, StatusCode: 200,
+				Latency: 120 * time.Millisecond, BodyBytes: 512, CheckedAt: at,
+			},
+			want: "kenko_check,group=payments,severity=critical,status=healthy,target=api latency_seconds=0.12,up=1i,status_code=200i,body_bytes=512i 1700000000000000000\n",
+		},
+		{
+			name: "error escaped",
+			result: kenko.Result{
+				Target: "edge api", Region: "eu,west", Status: kenko.StatusUnhealthy,
+				Error: `dial "tcp": refused`, Attempts: 3, CheckedAt: at,
+			},
+			want: `kenko_check,region=eu\,west,status=unhealthy,target=edge\ api latency_seconds=0,up=0i,attempts=3i,error="dial \"tcp\": refused" 1700000000000000000` + "\n",
+		},
+		{
+			name: "tags and labels",
+			opts: []Option{
+				WithMeasurement("checks"),
+				WithTags(map[string]string{"instance": "kenko-a"}),
+				WithLabelTags("team", "env", "status"),
+			},
+			result: kenko.Result{
+				Target: "api", Status: kenko.StatusDegraded, CheckedAt: at,
+				Labels: map[string]string{"team": "pay=ments", "status": "overridden"},
+			},
+			want: "checks,instance=kenko-a,status=degraded,target=api,team=pay\\=ments latency_seconds=0,up=0i 1700000000000000000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(New("http://localhost", tt.opts...).appendLine(nil, tt.result)); got != tt.want {
+				t.Errorf("line =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
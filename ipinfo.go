@@ -0,0 +1,34 @@
+package kenko
+
+import "net"
+
+// IPInfo describes the network and location a resolved address belongs to.
+type IPInfo struct {
+	ASN     uint   `json:"asn,omitempty"`
+	Org     string `json:"org,omitempty"`
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+// IPEnricher looks up network details for the address a check connected to.
+// it returns nil when the address is unknown. the geoip sub-package provides
+// an implementation backed by MaxMind databases.
+type IPEnricher interface {
+	Enrich(ip net.IP) (*IPInfo, error)
+}
+
+// enrich records the connected address on the result and, when an enricher
+// is configured, its network details.
+func (c *Checker) enrich(result *Result, remoteIP string) {
+	result.RemoteIP = remoteIP
+	if c.ipEnricher == nil || remoteIP == "" {
+		return
+	}
+
+	info, err := c.ipEnricher.Enrich(net.ParseIP(remoteIP))
+	if err != nil {
+		c.logger.Warn("ip enrichment failed", "target", result.Target, "ip", remoteIP, "error", err)
+		return
+	}
+	result.IPInfo = info
+}
@@ -0,0 +1,60 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeEnricher struct {
+	got net.IP
+	err error
+}
+
+func (f *fakeEnricher) Enrich(ip net.IP) (*IPInfo, error) {
+	f.got = ip
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &IPInfo{ASN: 64512, Country: "NL"}, nil
+}
+
+func TestCheck_RemoteIPAndEnrichment(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	e := &fakeEnricher{}
+	c := &Checker{client: ts.Client(), ipEnricher: e}
+
+	r := c.check(context.Background(), Target{Name: "api", URL: ts.URL})
+	if r.RemoteIP != "127.0.0.1" {
+		t.Errorf("remote ip = %q, want %q", r.RemoteIP, "127.0.0.1")
+	}
+	if !e.got.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("enriched ip = %v, want 127.0.0.1", e.got)
+	}
+	if r.IPInfo == nil || r.IPInfo.ASN != 64512 {
+		t.Errorf("ip info = %+v, want asn 64512", r.IPInfo)
+	}
+}
+
+func TestCheck_EnrichmentErrorKeepsResult(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := &Checker{
+		client:     ts.Client(),
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		ipEnricher: &fakeEnricher{err: errors.New("corrupt database")},
+	}
+
+	r := c.check(context.Background(), Target{Name: "api", URL: ts.URL})
+	if r.Status != StatusHealthy || r.RemoteIP == "" || r.IPInfo != nil {
+		t.Errorf("result = %+v, want healthy with a remote ip and no ip info", r)
+	}
+}
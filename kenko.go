@@ -22,11 +22,51 @@ func New(opts ...Option) (*Kenko, error) {
 	return &Kenko{checker: c}, nil
 }
 
-// RegisterHandlers registers the /health, /ready, and /status HTTP handlers on the given mux.
+// RegisterHandlers registers the /health, /ready, and /status HTTP handlers and
+// the /api/v1 endpoints on the given mux. the endpoints that change state
+// are only registered with WithAPIToken, and require the token.
 func (k *Kenko) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("/health", HandleHealth(k.checker))
 	mux.HandleFunc("/ready", HandleReady(k.checker))
 	mux.HandleFunc("/status", HandleStatus(k.checker))
+	mux.HandleFunc("GET /api/v1/search", HandleSearch(k.checker))
+	mux.HandleFunc("GET /api/v1/targets/{name}/timeline", HandleTimeline(k.checker))
+	mux.HandleFunc("GET /api/v1/targets/{name}/history", HandleHistory(k.checker))
+	mux.HandleFunc("GET /api/v1/targets/{name}/history/rollups", HandleRollups(k.checker))
+	mux.HandleFunc("GET /api/v1/history/export", HandleExportHistory(k.checker))
+	mux.HandleFunc("GET /api/v1/events", HandleEvents(k.checker))
+	mux.HandleFunc("GET /api/v1/notifications", HandleNotifications(k.checker))
+	mux.HandleFunc("POST /api/v1/targets/{name}/deploys", HandleMarkDeploy(k.checker))
+	mux.HandleFunc("POST /api/v1/targets/{name}/heartbeat", HandleHeartbeat(k.checker))
+	mux.HandleFunc("GET /api/v1/targets/{name}/deploys/latest", HandleDeployComparison(k.checker))
+	mux.HandleFunc("GET /api/v1/targets/{name}/incidents", HandleIncidents(k.checker))
+	mux.HandleFunc("GET /api/v1/targets/{name}/uptime", HandleUptime(k.checker))
+	mux.HandleFunc("GET /api/v1/pauses", HandlePauses(k.checker))
+	mux.HandleFunc("GET /api/v1/status-page", HandleStatusPage(k.checker))
+	mux.HandleFunc("GET /api/v1/announcements", HandleAnnouncements(k.checker))
+	mux.HandleFunc("GET /api/v1/silences", HandleSilences(k.checker))
+
+	token := k.checker.apiToken
+	if token == "" {
+		return
+	}
+	for pattern, h := range map[string]http.HandlerFunc{
+		"POST /api/v1/targets":                                   HandleAddTarget(k.checker),
+		"PUT /api/v1/targets/{name}":                             HandleUpdateTarget(k.checker),
+		"DELETE /api/v1/targets/{name}":                          HandleRemoveTarget(k.checker),
+		"PUT /api/v1/targets/{name}/incidents/{id}/exclusion":    HandleExcludeIncident(k.checker),
+		"DELETE /api/v1/targets/{name}/incidents/{id}/exclusion": HandleIncludeIncident(k.checker),
+		"PUT /api/v1/targets/{name}/pause":                       HandlePauseTarget(k.checker),
+		"DELETE /api/v1/targets/{name}/pause":                    HandleResumeTarget(k.checker),
+		"POST /api/v1/announcements":                             HandlePostAnnouncement(k.checker),
+		"POST /api/v1/announcements/{id}/updates":                HandleUpdateAnnouncement(k.checker),
+		"DELETE /api/v1/announcements/{id}":                      HandleDeleteAnnouncement(k.checker),
+		"POST /api/v1/silences":                                  HandlePostSilence(k.checker),
+		"DELETE /api/v1/silences/{id}":                           HandleDeleteSilence(k.checker),
+		"POST /api/v1/notifiers/{name}/test":                     HandleTestNotifier(k.checker),
+	} {
+		mux.HandleFunc(pattern, requireToken(token, h))
+	}
 }
 
 // Run starts the periodic health check loop, blocking until ctx is cancelled.
@@ -34,6 +74,12 @@ func (k *Kenko) Run(ctx context.Context) {
 	k.checker.Run(ctx)
 }
 
+// Shutdown stops scheduling checks and waits, until ctx expires, for those in
+// flight and their result writes to finish. see Checker.Shutdown.
+func (k *Kenko) Shutdown(ctx context.Context) error {
+	return k.checker.Shutdown(ctx)
+}
+
 // Checker returns the underlying Checker for direct access.
 func (k *Kenko) Checker() *Checker {
 	return k.checker
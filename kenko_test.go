@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -35,6 +36,44 @@ func TestNew_RegistersHandlers(t *testing.T) {
 	}
 }
 
+func TestRegisterHandlers_APIToken(t *testing.T) {
+	do := func(k *Kenko, auth string) int {
+		mux := http.NewServeMux()
+		k.RegisterHandlers(mux)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/targets", strings.NewReader(`{"name":"web","url":"https://web.example.com"}`))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// without a token the endpoints that change state are not registered
+	open, err := New(WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := do(open, ""); code != http.StatusNotFound {
+		t.Errorf("without a token status = %d, want 404", code)
+	}
+
+	k, err := New(WithTarget("api", "https://api.example.com"), WithAPIToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for auth, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusCreated,
+	} {
+		if code := do(k, auth); code != want {
+			t.Errorf("%q: status = %d, want %d", auth, code, want)
+		}
+	}
+}
+
 func TestNew_RunAndReady(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,26 @@
+package kenko
+
+import "strings"
+
+// matchLabel reports whether labels satisfy a selector: "key=value" requires
+// the key to have the value, compared case-insensitively, and a bare "key"
+// only requires the key to be set.
+func matchLabel(labels map[string]string, selector string) bool {
+	key, value, hasValue := strings.Cut(selector, "=")
+	for k, v := range labels {
+		if strings.EqualFold(k, key) {
+			return !hasValue || strings.EqualFold(v, value)
+		}
+	}
+	return false
+}
+
+// matchLabels reports whether labels satisfy every selector.
+func matchLabels(labels map[string]string, selectors []string) bool {
+	for _, sel := range selectors {
+		if !matchLabel(labels, sel) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package kenko
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"team": "Payments", "env": "prod"}
+
+	tests := []struct {
+		selectors []string
+		want      bool
+	}{
+		{nil, true},
+		{[]string{"team=payments"}, true},
+		{[]string{"TEAM=Payments"}, true},
+		{[]string{"team"}, true},
+		{[]string{"team=payments", "env=prod"}, true},
+		{[]string{"team=payments", "env=staging"}, false},
+		{[]string{"tier"}, false},
+		{[]string{"team="}, false},
+	}
+	for _, tt := range tests {
+		if got := matchLabels(labels, tt.selectors); got != tt.want {
+			t.Errorf("matchLabels(%v) = %v, want %v", tt.selectors, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTarget_Labels(t *testing.T) {
+	c, _ := NewChecker(
+		WithTarget("api", "", WithHeartbeatTimeout(time.Minute), WithLabels(map[string]string{"team": "payments"}), WithLabels(map[string]string{"env": "prod"})),
+	)
+	c.checkTarget(context.Background(), c.targets[0])
+
+	results, err := c.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results["api"]; r.Labels["team"] != "payments" || r.Labels["env"] != "prod" {
+		t.Errorf("labels = %v", r.Labels)
+	}
+}
+
+func TestNewChecker_EmptyLabelKey(t *testing.T) {
+	_, err := NewChecker(WithTarget("api", "https://api.example.com", WithLabels(map[string]string{"": "x"})))
+	if err == nil {
+		t.Fatal("expected an error for an empty label key")
+	}
+}
@@ -0,0 +1,100 @@
+package kenko
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MaintenanceWindow is a recurring period, such as a nightly deploy, during
+// which a target is still checked but reported with StatusMaintenance, so it
+// is not notified about or counted against its group.
+type MaintenanceWindow struct {
+	// Schedule is a cron expression for when each window starts, e.g.
+	// "0 2 * * *" for 02:00 every day. a "CRON_TZ=Europe/Berlin " prefix
+	// sets its timezone, which defaults to the local one.
+	Schedule string
+	// Duration is how long each window lasts.
+	Duration time.Duration
+
+	cron *cronSchedule
+}
+
+// WithMaintenance adds a recurring maintenance window to the target starting
+// at every time matching the cron schedule and lasting d.
+func WithMaintenance(schedule string, d time.Duration) TargetOption {
+	return func(t *Target) {
+		t.Maintenance = append(t.Maintenance, MaintenanceWindow{Schedule: schedule, Duration: d})
+	}
+}
+
+// WithGroupMaintenance adds a recurring maintenance window to every target in
+// group, as WithMaintenance does for one target.
+func WithGroupMaintenance(group, schedule string, d time.Duration) Option {
+	return func(o *options) {
+		if o.maintenance == nil {
+			o.maintenance = make(map[string][]MaintenanceWindow)
+		}
+		o.maintenance[group] = append(o.maintenance[group], MaintenanceWindow{Schedule: schedule, Duration: d})
+	}
+}
+
+// parseMaintenance parses the schedules of windows in place.
+func parseMaintenance(windows []MaintenanceWindow) error {
+	for i := range windows {
+		w := &windows[i]
+		if w.Duration <= 0 {
+			return fmt.Errorf("maintenance window %q needs a positive duration", w.Schedule)
+		}
+		cron, err := parseCron(w.Schedule)
+		if err != nil {
+			return err
+		}
+		w.cron = cron
+	}
+	return nil
+}
+
+// parseTargetMaintenance parses the maintenance windows of targets. each
+// target gets its own copy of its windows, so the caller's are not modified.
+func parseTargetMaintenance(targets []Target) error {
+	for i := range targets {
+		t := &targets[i]
+		if len(t.Maintenance) == 0 {
+			continue
+		}
+		t.Maintenance = slices.Clone(t.Maintenance)
+		if err := parseMaintenance(t.Maintenance); err != nil {
+			return fmt.Errorf("kenko: target %q: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateGroupMaintenance(windows map[string][]MaintenanceWindow) error {
+	for group, ws := range windows {
+		if err := parseMaintenance(ws); err != nil {
+			return fmt.Errorf("kenko: group %q: %w", group, err)
+		}
+	}
+	return nil
+}
+
+// active reports whether at falls within one of the window's occurrences.
+func (w MaintenanceWindow) active(at time.Time) bool {
+	if w.cron == nil {
+		return false
+	}
+	// a window is open at at when it started after at-Duration, so it is
+	// enough to look at the first start after that
+	start := w.cron.next(at.Add(-w.Duration))
+	return !start.IsZero() && !start.After(at)
+}
+
+// inMaintenance reports whether the target, or its group, is in a
+// maintenance window at at.
+func (c *Checker) inMaintenance(t Target, at time.Time) bool {
+	active := func(w MaintenanceWindow) bool { return w.active(at) }
+	return slices.ContainsFunc(t.Maintenance, active) ||
+		t.Group != "" && slices.ContainsFunc(c.maintenance[t.Group], active)
+}
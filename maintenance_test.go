@@ -0,0 +1,87 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow_Active(t *testing.T) {
+	w := []MaintenanceWindow{{Schedule: "CRON_TZ=UTC 0 2 * * *", Duration: 30 * time.Minute}}
+	if err := parseMaintenance(w); err != nil {
+		t.Fatal(err)
+	}
+
+	for at, want := range map[time.Time]bool{
+		time.Date(2026, 3, 13, 1, 59, 0, 0, time.UTC):  false,
+		time.Date(2026, 3, 13, 2, 0, 0, 0, time.UTC):   true,
+		time.Date(2026, 3, 13, 2, 29, 59, 0, time.UTC): true,
+		time.Date(2026, 3, 13, 2, 30, 0, 0, time.UTC):  false,
+		time.Date(2026, 3, 13, 14, 0, 0, 0, time.UTC):  false,
+	} {
+		if got := w[0].active(at); got != want {
+			t.Errorf("active(%s) = %v, want %v", at.Format(time.TimeOnly), got, want)
+		}
+	}
+}
+
+func TestCheckTarget_Maintenance(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("deploying", ts.URL, WithMaintenance("* * * * *", time.Hour)),
+		WithTarget("member", ts.URL, WithGroup("batch")),
+		WithTarget("other", ts.URL),
+		WithGroupMaintenance("batch", "* * * * *", time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range c.targets {
+		c.checkTarget(context.Background(), target)
+	}
+
+	results, err := c.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]Status{"deploying": StatusMaintenance, "member": StatusMaintenance, "other": StatusUnhealthy} {
+		if got := results[name].Status; got != want {
+			t.Errorf("%s: status %q, want %q", name, got, want)
+		}
+	}
+	if results["deploying"].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("check during maintenance not recorded: %+v", results["deploying"])
+	}
+
+	groups, err := c.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Unhealthy != 0 {
+		t.Errorf("groups = %+v, want no unhealthy members", groups)
+	}
+}
+
+func TestNew_InvalidMaintenance(t *testing.T) {
+	for _, opt := range []TargetOption{
+		WithMaintenance("0 2 * * *", 0),
+		WithMaintenance("0 25 * * *", time.Hour),
+	} {
+		if _, err := NewChecker(WithTarget("api", "https://api.example.com", opt)); err == nil {
+			t.Errorf("%+v accepted", NewTarget("api", "https://api.example.com", opt).Maintenance)
+		}
+	}
+
+	if _, err := NewChecker(
+		WithTarget("api", "https://api.example.com", WithGroup("batch")),
+		WithGroupMaintenance("batch", "@nightly", time.Hour),
+	); err == nil {
+		t.Error("bad group schedule accepted")
+	}
+}
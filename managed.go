@@ -0,0 +1,312 @@
+package kenko
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// targetsStateKey is the StateStore key holding the targets added, edited
+// and removed through AddTarget, UpdateTarget and RemoveTarget.
+const targetsStateKey = "targets"
+
+var (
+	// ErrNoTarget is returned when editing or removing a target that does
+	// not exist.
+	ErrNoTarget = errors.New("kenko: no such target")
+	// ErrTargetExists is returned when adding a target whose name is taken.
+	ErrTargetExists = errors.New("kenko: target already exists")
+)
+
+// TargetDecoder builds a target from its JSON spec, as sent to the target
+// API. it is also used to rebuild the persisted specs when Run starts, so it
+// must accept every spec it accepted before.
+type TargetDecoder func(spec []byte) (Target, error)
+
+// TargetSaver persists target API changes somewhere other than the store,
+// e.g. back to a config file. spec is nil when the target was removed.
+type TargetSaver interface {
+	SaveTarget(ctx context.Context, name string, spec []byte) error
+}
+
+// WithTargetDecoder sets how the specs given to AddTarget and UpdateTarget
+// are decoded (default DecodeTarget).
+func WithTargetDecoder(d TargetDecoder) Option {
+	return func(o *options) { o.targetDecoder = d }
+}
+
+// WithTargetSaver sets a TargetSaver told about every target API change,
+// before it is applied. a change it fails to save is not applied. what it
+// saves to becomes the source of truth: changes are not kept in the store,
+// and the targets next given to SetTargets replace them, so later edits of
+// the saved targets are not overridden.
+func WithTargetSaver(s TargetSaver) Option {
+	return func(o *options) { o.targetSaver = s }
+}
+
+// TargetSpec is the JSON spec DecodeTarget accepts. Interval and Timeout are
+// durations such as "30s".
+type TargetSpec struct {
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	Method       string            `json:"method,omitempty"`
+	ExpectStatus []int             `json:"expect_status,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Group        string            `json:"group,omitempty"`
+	Severity     Severity          `json:"severity,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Interval     string            `json:"interval,omitempty"`
+	Timeout      string            `json:"timeout,omitempty"`
+}
+
+// DecodeTarget is the default TargetDecoder. it decodes a TargetSpec,
+// rejecting unknown fields.
+func DecodeTarget(spec []byte) (Target, error) {
+	var s TargetSpec
+	dec := json.NewDecoder(bytes.NewReader(spec))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return Target{}, fmt.Errorf("kenko: decode target: %w", err)
+	}
+	if s.URL == "" {
+		return Target{}, fmt.Errorf("kenko: target %q has no url", s.Name)
+	}
+
+	var opts []TargetOption
+	if s.Method != "" {
+		opts = append(opts, WithMethod(s.Method))
+	}
+	if len(s.ExpectStatus) > 0 {
+		opts = append(opts, WithExpectStatus(s.ExpectStatus...))
+	}
+	for k, v := range s.Headers {
+		opts = append(opts, WithHeader(k, v))
+	}
+	if s.Group != "" {
+		opts = append(opts, WithGroup(s.Group))
+	}
+	if s.Severity != "" {
+		opts = append(opts, WithSeverity(s.Severity))
+	}
+	if len(s.Tags) > 0 {
+		opts = append(opts, WithTags(s.Tags...))
+	}
+	if len(s.Labels) > 0 {
+		opts = append(opts, WithLabels(s.Labels))
+	}
+	for _, d := range []struct {
+		field, value string
+		opt          func(time.Duration) TargetOption
+	}{
+		{"interval", s.Interval, WithCheckInterval},
+		{"timeout", s.Timeout, WithCheckTimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return Target{}, fmt.Errorf("kenko: target %q %s must be a positive duration, got %q", s.Name, d.field, d.value)
+		}
+		opts = append(opts, d.opt(v))
+	}
+	return NewTarget(s.Name, s.URL, opts...), nil
+}
+
+// managedTarget is a target API change: a target added or edited with Spec,
+// or a configured target Removed.
+type managedTarget struct {
+	Name    string          `json:"name"`
+	Spec    json.RawMessage `json:"spec,omitempty"`
+	Removed bool            `json:"removed,omitempty"`
+
+	target Target
+}
+
+// managedTargets holds the targets given to NewChecker or SetTargets and the
+// target API changes applied over them, in the order they were first made.
+type managedTargets struct {
+	mu      sync.Mutex
+	base    []Target
+	changes []managedTarget
+}
+
+// AddTarget decodes spec and starts checking the target from the next
+// cycle. the change is persisted when the store implements StateStore and
+// passed to the TargetSaver, if any.
+func (c *Checker) AddTarget(ctx context.Context, spec []byte) (Target, error) {
+	t, err := c.decodeTarget(spec)
+	if err != nil {
+		return Target{}, err
+	}
+
+	c.managed.mu.Lock()
+	defer c.managed.mu.Unlock()
+	if c.hasTarget(t.Name) {
+		return Target{}, ErrTargetExists
+	}
+	return t, c.manage(ctx, managedTarget{Name: t.Name, Spec: spec, target: t})
+}
+
+// UpdateTarget replaces the named target with the one decoded from spec,
+// which must keep its name. its results and operator state are kept.
+func (c *Checker) UpdateTarget(ctx context.Context, name string, spec []byte) (Target, error) {
+	t, err := c.decodeTarget(spec)
+	if err != nil {
+		return Target{}, err
+	}
+	if t.Name != name {
+		return Target{}, fmt.Errorf("kenko: target %q cannot be renamed to %q", name, t.Name)
+	}
+
+	c.managed.mu.Lock()
+	defer c.managed.mu.Unlock()
+	if !c.hasTarget(name) {
+		return Target{}, ErrNoTarget
+	}
+	return t, c.manage(ctx, managedTarget{Name: name, Spec: spec, target: t})
+}
+
+// RemoveTarget stops checking the named target, as if it was removed from
+// the config.
+func (c *Checker) RemoveTarget(ctx context.Context, name string) error {
+	c.managed.mu.Lock()
+	defer c.managed.mu.Unlock()
+	if !c.hasTarget(name) {
+		return ErrNoTarget
+	}
+	return c.manage(ctx, managedTarget{Name: name, Removed: true})
+}
+
+// decodeTarget decodes spec and normalizes the target's name.
+func (c *Checker) decodeTarget(spec []byte) (Target, error) {
+	t, err := c.targetDecoder(spec)
+	if err != nil {
+		return Target{}, err
+	}
+	name, err := c.normalizeName(t.Name)
+	if err != nil {
+		return Target{}, fmt.Errorf("kenko: target %q: %w", t.Name, err)
+	}
+	t.Name = name
+	return t, nil
+}
+
+// saveError marks an API change, to the targets, silences, announcements or
+// heartbeats, that was valid but could not be persisted, so it was not
+// applied.
+type saveError struct{ err error }
+
+func (e *saveError) Error() string { return e.err.Error() }
+
+func (e *saveError) Unwrap() error { return e.err }
+
+// manage persists the change m over the current ones, then applies them. a
+// change that fails to persist is not applied, so the checks never drift
+// from what a restart would restore. the caller holds managed.mu.
+func (c *Checker) manage(ctx context.Context, m managedTarget) error {
+	changes := slices.Clone(c.managed.changes)
+	i := slices.IndexFunc(changes, func(x managedTarget) bool { return x.Name == m.Name })
+	inBase := slices.ContainsFunc(c.managed.base, func(t Target) bool { return t.Name == m.Name })
+	switch {
+	case m.Removed && !inBase:
+		// a target that only the API added leaves nothing behind
+		changes = slices.Delete(changes, i, i+1)
+	case i >= 0:
+		changes[i] = m
+	default:
+		changes = append(changes, m)
+	}
+
+	targets := applyManaged(c.managed.base, changes)
+	if err := validateTargets(targets, c.names, c.revocation); err != nil {
+		return err
+	}
+
+	if c.targetSaver != nil {
+		if err := c.targetSaver.SaveTarget(ctx, m.Name, m.Spec); err != nil {
+			return &saveError{err: err}
+		}
+	} else if err := c.saveState(ctx, targetsStateKey, changes); err != nil {
+		return &saveError{err: err}
+	}
+
+	if _, err := c.swapTargets(ctx, targets); err != nil {
+		return err
+	}
+	c.managed.changes = changes
+	return nil
+}
+
+// applyManaged returns base with changes applied: edited targets replaced
+// in place, removed ones dropped, and added ones appended.
+func applyManaged(base []Target, changes []managedTarget) []Target {
+	if len(changes) == 0 {
+		return base
+	}
+	byName := make(map[string]managedTarget, len(changes))
+	for _, m := range changes {
+		byName[m.Name] = m
+	}
+
+	out := make([]Target, 0, len(base)+len(changes))
+	for _, t := range base {
+		m, ok := byName[t.Name]
+		switch {
+		case !ok:
+			out = append(out, t)
+		case !m.Removed:
+			out = append(out, m.target)
+		}
+		delete(byName, t.Name)
+	}
+	for _, m := range changes {
+		if _, ok := byName[m.Name]; ok && !m.Removed {
+			out = append(out, m.target)
+		}
+	}
+	return out
+}
+
+// loadManagedTargets restores persisted target API changes unless some were
+// made in memory already, or a TargetSaver keeps them instead. specs that no
+// longer decode are skipped.
+func (c *Checker) loadManagedTargets(ctx context.Context) error {
+	if c.targetSaver != nil {
+		return nil
+	}
+	var stored []managedTarget
+	if ok, err := c.loadState(ctx, targetsStateKey, &stored); !ok {
+		return err
+	}
+
+	c.managed.mu.Lock()
+	defer c.managed.mu.Unlock()
+	if len(c.managed.changes) > 0 {
+		return nil
+	}
+
+	changes := make([]managedTarget, 0, len(stored))
+	for _, m := range stored {
+		if !m.Removed {
+			t, err := c.decodeTarget(m.Spec)
+			if err != nil {
+				c.logger.Warn("skipping stored target", "target", m.Name, "error", err)
+				continue
+			}
+			m.target = t
+		}
+		changes = append(changes, m)
+	}
+	if _, err := c.swapTargets(ctx, applyManaged(c.managed.base, changes)); err != nil {
+		return err
+	}
+	c.managed.changes = changes
+	return nil
+}
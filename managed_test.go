@@ -0,0 +1,325 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type savedTarget struct {
+	name string
+	spec string
+}
+
+type recordingSaver struct{ saved []savedTarget }
+
+func (s *recordingSaver) SaveTarget(_ context.Context, name string, spec []byte) error {
+	s.saved = append(s.saved, savedTarget{name, string(spec)})
+	return nil
+}
+
+type failingSaver struct{}
+
+func (failingSaver) SaveTarget(context.Context, string, []byte) error {
+	return errors.New("disk full")
+}
+
+func targetNames(c *Checker) []string {
+	var names []string
+	for _, t := range c.Targets() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestDecodeTarget(t *testing.T) {
+	tgt, err := DecodeTarget([]byte(`{"name":"api","url":"https://api.example.com","method":"HEAD",
+		"expect_status":[200],"headers":{"X-Key":"1"},"group":"core","severity":"critical",
+		"tags":["edge"],"labels":{"team":"payments"},"interval":"10s","timeout":"2s"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tgt.Name != "api" || tgt.Method != http.MethodHead || !slices.Equal(tgt.ExpectStatus, []int{200}) ||
+		tgt.Header.Get("X-Key") != "1" || tgt.Group != "core" || tgt.Severity != SeverityCritical ||
+		!slices.Equal(tgt.Tags, []string{"edge"}) || tgt.Labels["team"] != "payments" ||
+		tgt.Interval != 10*time.Second || tgt.Timeout != 2*time.Second {
+		t.Errorf("target = %+v", tgt)
+	}
+
+	for _, spec := range []string{
+		`{"name":"api"}`,
+		`{"name":"api","url":"https://api.example.com","interval":"often"}`,
+		`{"name":"api","url":"https://api.example.com","timeout":"-1s"}`,
+		`{"name":"api","url":"https://api.example.com","retries":3}`,
+		`[]`,
+	} {
+		if _, err := DecodeTarget([]byte(spec)); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestManagedTargets(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(
+		WithStore(store),
+		WithNameNormalizer(SlugName),
+		WithTarget("api", "https://api.example.com"),
+		WithTarget("web", "https://web.example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	added, err := c.AddTarget(ctx, []byte(`{"name":"Billing API","url":"https://billing.example.com"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Name != "billing-api" {
+		t.Errorf("added = %+v, want its name normalized", added)
+	}
+	if _, err := c.AddTarget(ctx, []byte(`{"name":"api","url":"https://other.example.com"}`)); !errors.Is(err, ErrTargetExists) {
+		t.Errorf("duplicate add err = %v, want ErrTargetExists", err)
+	}
+
+	if _, err := c.UpdateTarget(ctx, "web", []byte(`{"name":"web","url":"https://www.example.com"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateTarget(ctx, "web", []byte(`{"name":"site","url":"https://www.example.com"}`)); err == nil {
+		t.Error("expected an error renaming a target")
+	}
+	if _, err := c.UpdateTarget(ctx, "docs", []byte(`{"name":"docs","url":"https://docs.example.com"}`)); !errors.Is(err, ErrNoTarget) {
+		t.Errorf("update of a missing target err = %v, want ErrNoTarget", err)
+	}
+	if err := c.RemoveTarget(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveTarget(ctx, "api"); !errors.Is(err, ErrNoTarget) {
+		t.Errorf("second remove err = %v, want ErrNoTarget", err)
+	}
+
+	if names := targetNames(c); !slices.Equal(names, []string{"web", "billing-api"}) {
+		t.Errorf("targets = %v, want [web billing-api]", names)
+	}
+	if tgt := c.Targets()[0]; tgt.URL != "https://www.example.com" {
+		t.Errorf("web = %+v, want the edited url", tgt)
+	}
+
+	// a config reload keeps the API changes over the new targets
+	if _, err := c.SetTargets(ctx, []Target{
+		NewTarget("api", "https://api.example.com"),
+		NewTarget("web", "https://web.example.com"),
+		NewTarget("db", "https://db.example.com"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if names := targetNames(c); !slices.Equal(names, []string{"web", "db", "billing-api"}) {
+		t.Errorf("targets after reload = %v, want [web db billing-api]", names)
+	}
+
+	// a removal ends once the config drops the target too
+	if _, err := c.SetTargets(ctx, []Target{NewTarget("web", "https://web.example.com")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SetTargets(ctx, []Target{
+		NewTarget("api", "https://api.example.com"),
+		NewTarget("web", "https://web.example.com"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if names := targetNames(c); !slices.Equal(names, []string{"api", "web", "billing-api"}) {
+		t.Errorf("targets after re-adding api = %v, want [api web billing-api]", names)
+	}
+	if err := c.RemoveTarget(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	// and a restart restores the changes from the store
+	restarted, err := NewChecker(
+		WithStore(store),
+		WithNameNormalizer(SlugName),
+		WithTarget("api", "https://api.example.com"),
+		WithTarget("web", "https://web.example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restarted.loadManagedTargets(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if names := targetNames(restarted); !slices.Equal(names, []string{"web", "billing-api"}) {
+		t.Errorf("restored targets = %v, want [web billing-api]", names)
+	}
+
+	// removing a target only the API added leaves no change behind
+	if err := c.RemoveTarget(ctx, "billing-api"); err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(c.managed.changes, func(m managedTarget) bool { return m.Name == "billing-api" }) {
+		t.Errorf("changes = %+v, want billing-api gone", c.managed.changes)
+	}
+}
+
+func TestManagedTargets_Saver(t *testing.T) {
+	store := NewMemoryStore()
+	saver := &recordingSaver{}
+	c, err := NewChecker(
+		WithStore(store),
+		WithTargetSaver(saver),
+		WithTarget("api", "https://api.example.com"),
+		WithTarget("web", "https://web.example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.AddTarget(ctx, []byte(`{"name":"docs","url":"https://docs.example.com"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateTarget(ctx, "web", []byte(`{"name":"web","url":"https://www.example.com"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveTarget(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if len(saver.saved) != 3 || saver.saved[2] != (savedTarget{name: "api"}) {
+		t.Errorf("saved = %+v, want the add, edit and removal", saver.saved)
+	}
+	if names := targetNames(c); !slices.Equal(names, []string{"web", "docs"}) {
+		t.Errorf("targets = %v, want [web docs] until the saved targets are reloaded", names)
+	}
+
+	// the saver keeps the changes, not the store
+	var stored []managedTarget
+	if ok, _ := c.loadState(ctx, targetsStateKey, &stored); ok {
+		t.Errorf("stored = %+v, want nothing", stored)
+	}
+
+	// so the reloaded targets are taken as they are, hand edits included
+	if _, err := c.SetTargets(ctx, []Target{
+		NewTarget("web", "https://web.example.com/v2"),
+		NewTarget("docs", "https://docs.example.com"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.managed.changes) != 0 {
+		t.Errorf("changes = %+v, want none", c.managed.changes)
+	}
+	if tgt := c.Targets()[0]; tgt.URL != "https://web.example.com/v2" {
+		t.Errorf("web = %+v, want the reloaded url", tgt)
+	}
+}
+
+func TestManagedTargets_Invalid(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.AddTarget(ctx, []byte(`{"name":"web","url":"https://web.example.com","severity":"urgent"}`)); err == nil {
+		t.Error("expected an error adding a target with an unknown severity")
+	}
+	if err := c.RemoveTarget(ctx, "api"); err == nil {
+		t.Error("expected an error removing the last target")
+	}
+	if names := targetNames(c); !slices.Equal(names, []string{"api"}) {
+		t.Errorf("targets = %v, want them unchanged", names)
+	}
+	if len(c.managed.changes) != 0 {
+		t.Errorf("changes = %+v, want none", c.managed.changes)
+	}
+}
+
+func TestManagedTargets_SaveFails(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(
+		WithStore(store),
+		WithTargetSaver(failingSaver{}),
+		WithTarget("api", "https://api.example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.AddTarget(ctx, []byte(`{"name":"web","url":"https://web.example.com"}`)); err == nil {
+		t.Fatal("expected an error when the saver fails")
+	}
+	if names := targetNames(c); !slices.Equal(names, []string{"api"}) {
+		t.Errorf("targets = %v, want the change not applied", names)
+	}
+	if len(c.managed.changes) != 0 {
+		t.Errorf("changes = %+v, want none", c.managed.changes)
+	}
+
+	// nor left in the store for a restart to apply
+	var stored []managedTarget
+	if _, err := c.loadState(ctx, targetsStateKey, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 0 {
+		t.Errorf("stored = %+v, want none", stored)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleAddTarget(c)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/targets",
+		strings.NewReader(`{"name":"web","url":"https://web.example.com"}`)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+}
+
+func TestHandleTargets(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(h http.HandlerFunc, method, name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/targets/"+name, strings.NewReader(body))
+		req.SetPathValue("name", name)
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec
+	}
+
+	rec := do(HandleAddTarget(c), http.MethodPost, "", `{"name":"web","url":"https://web.example.com"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("add status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var resp targetResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Name != "web" || resp.URL != "https://web.example.com" {
+		t.Errorf("add response = %+v", resp)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		h      http.HandlerFunc
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"duplicate", HandleAddTarget(c), http.MethodPost, "", `{"name":"web","url":"https://web.example.com"}`, http.StatusConflict},
+		{"invalid spec", HandleAddTarget(c), http.MethodPost, "", `{"name":"docs"}`, http.StatusBadRequest},
+		{"update", HandleUpdateTarget(c), http.MethodPut, "web", `{"name":"web","url":"https://www.example.com"}`, http.StatusOK},
+		{"update unknown", HandleUpdateTarget(c), http.MethodPut, "docs", `{"name":"docs","url":"https://docs.example.com"}`, http.StatusNotFound},
+		{"rename", HandleUpdateTarget(c), http.MethodPut, "web", `{"name":"site","url":"https://www.example.com"}`, http.StatusBadRequest},
+		{"remove", HandleRemoveTarget(c), http.MethodDelete, "web", "", http.StatusNoContent},
+		{"remove again", HandleRemoveTarget(c), http.MethodDelete, "web", "", http.StatusNotFound},
+	} {
+		if rec := do(tt.h, tt.method, tt.target, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.name, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package kenko
+
+// multiReporter passes every report to each of its reporters that supports
+// it.
+type multiReporter []MetricsReporter
+
+// MultiReporter combines reporters into one, e.g. prometheus metrics and an
+// exporter writing every result to a time series database. each receives the
+// results, group changes, and prune counts its optional interfaces ask for.
+func MultiReporter(reporters ...MetricsReporter) MetricsReporter {
+	return multiReporter(reporters)
+}
+
+func (m multiReporter) ReportCheck(target string, status Status, latencySeconds float64) {
+	for _, r := range m {
+		r.ReportCheck(target, status, latencySeconds)
+	}
+}
+
+func (m multiReporter) ReportResult(result Result) {
+	for _, r := range m {
+		if rr, ok := r.(ResultReporter); ok {
+			rr.ReportResult(result)
+			continue
+		}
+		r.ReportCheck(result.Target, result.Status, result.Latency.Seconds())
+	}
+}
+
+func (m multiReporter) ReportGroup(region string, group GroupStatus) {
+	for _, r := range m {
+		if gr, ok := r.(GroupReporter); ok {
+			gr.ReportGroup(region, group)
+		}
+	}
+}
+
+func (m multiReporter) ReportPruned(kind string, n int) {
+	for _, r := range m {
+		if pr, ok := r.(PruneReporter); ok {
+			pr.ReportPruned(kind, n)
+		}
+	}
+}
+
+func (m multiReporter) ForgetTarget(target string) {
+	for _, r := range m {
+		if tf, ok := r.(TargetForgetter); ok {
+			tf.ForgetTarget(target)
+		}
+	}
+}
+
+func (m multiReporter) ReportNotification(notifier, outcome string) {
+	for _, r := range m {
+		if nr, ok := r.(NotificationReporter); ok {
+			nr.ReportNotification(notifier, outcome)
+		}
+	}
+}
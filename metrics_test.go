@@ -0,0 +1,56 @@
+package kenko
+
+import (
+	"testing"
+	"time"
+)
+
+type checkRecorder struct {
+	checks []string
+}
+
+func (c *checkRecorder) ReportCheck(target string, _ Status, _ float64) {
+	c.checks = append(c.checks, target)
+}
+
+type resultRecorder struct {
+	checkRecorder
+	results []Result
+}
+
+func (r *resultRecorder) ReportResult(result Result) { r.results = append(r.results, result) }
+
+type forgetRecorder struct {
+	checkRecorder
+	forgotten []string
+}
+
+func (f *forgetRecorder) ForgetTarget(target string) { f.forgotten = append(f.forgotten, target) }
+
+func TestMultiReporter(t *testing.T) {
+	checks, results := &checkRecorder{}, &resultRecorder{}
+	groups, pruned := &groupRecorder{}, &pruneRecorder{pruned: make(map[string]int)}
+	forgets := &forgetRecorder{}
+	m := MultiReporter(checks, results, groups, pruned, forgets)
+
+	m.(ResultReporter).ReportResult(Result{Target: "api", Status: StatusHealthy, Latency: time.Second})
+	m.(GroupReporter).ReportGroup("", GroupStatus{Name: "payments"})
+	m.(PruneReporter).ReportPruned("results", 3)
+	m.(TargetForgetter).ForgetTarget("web")
+
+	if len(checks.checks) != 1 || checks.checks[0] != "api" {
+		t.Errorf("plain reporter got %v, want the check", checks.checks)
+	}
+	if len(results.results) != 1 || len(results.checks) != 0 {
+		t.Errorf("result reporter got %d results and %d checks, want only the result", len(results.results), len(results.checks))
+	}
+	if len(groups.groups) != 1 || groups.groups[0].Name != "payments" {
+		t.Errorf("groups = %+v", groups.groups)
+	}
+	if pruned.pruned["results"] != 3 {
+		t.Errorf("pruned = %v", pruned.pruned)
+	}
+	if len(forgets.forgotten) != 1 || forgets.forgotten[0] != "web" {
+		t.Errorf("forgotten = %v", forgets.forgotten)
+	}
+}
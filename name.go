@@ -0,0 +1,78 @@
+package kenko
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxNameLength keeps names usable as DNS-style labels and metric values.
+const maxNameLength = 63
+
+// NameNormalizer returns the canonical form of a target name, or an error if
+// the name is not acceptable. names end up as store keys, metric labels and
+// URL path segments, so two names that normalize alike are rejected.
+type NameNormalizer func(name string) (string, error)
+
+// SlugName is a NameNormalizer, enabled with WithNameNormalizer. it trims and lowercases the name and
+// collapses runs of spaces, underscores and dots into a single '-'. the result
+// must be 1-63 characters of [a-z0-9-] that start and end alphanumeric.
+func SlugName(name string) (string, error) {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case r == '-', r == '_', r == '.', r == ' ':
+			dash = true
+		default:
+			return "", fmt.Errorf("name %q contains invalid character %q", name, r)
+		}
+	}
+
+	slug := b.String()
+	switch {
+	case slug == "":
+		return "", fmt.Errorf("name %q is empty after normalization", name)
+	case len(slug) > maxNameLength:
+		return "", fmt.Errorf("name %q is longer than %d characters", name, maxNameLength)
+	}
+	return slug, nil
+}
+
+// normalizeTargets rewrites target names through fn, or keeps them verbatim
+// when fn is nil, and rejects empty or colliding names.
+func normalizeTargets(targets []Target, fn NameNormalizer) error {
+	seen := make(map[string]string, len(targets))
+	for i := range targets {
+		orig := targets[i].Name
+		name := orig
+		if fn != nil {
+			var err error
+			if name, err = fn(orig); err != nil {
+				return fmt.Errorf("kenko: target %q: %w", orig, err)
+			}
+		}
+		if name == "" {
+			return fmt.Errorf("kenko: target name must not be empty")
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("kenko: targets %q and %q both use the name %q", prev, orig, name)
+		}
+		seen[name] = orig
+		targets[i].Name = name
+	}
+	return nil
+}
+
+// normalizeName maps a name from a request to the canonical target name.
+func (c *Checker) normalizeName(name string) (string, error) {
+	if c.names == nil {
+		return name, nil
+	}
+	return c.names(name)
+}
@@ -0,0 +1,68 @@
+package kenko
+
+import "testing"
+
+func TestSlugName(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "api", want: "api"},
+		{in: "  API Server ", want: "api-server"},
+		{in: "db_primary.eu", want: "db-primary-eu"},
+		{in: "--edge--", want: "edge"},
+		{in: "a/b", wantErr: true},
+		{in: "über", wantErr: true},
+		{in: "___", wantErr: true},
+		{in: string(make([]byte, 64)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := SlugName(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SlugName(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SlugName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewChecker_NormalizesNames(t *testing.T) {
+	c, err := NewChecker(WithNameNormalizer(SlugName), WithTarget("Web App", "https://example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.targets[0].Name != "web-app" {
+		t.Errorf("name = %q, want %q", c.targets[0].Name, "web-app")
+	}
+}
+
+func TestNewChecker_DuplicateNormalizedNames(t *testing.T) {
+	_, err := NewChecker(
+		WithNameNormalizer(SlugName),
+		WithTarget("web app", "https://a.example.com"),
+		WithTarget("Web_App", "https://b.example.com"),
+	)
+	if err == nil {
+		t.Fatal("expected error for names that normalize alike")
+	}
+}
+
+func TestNewChecker_VerbatimNames(t *testing.T) {
+	c, err := NewChecker(WithTarget("Web App", "https://example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.targets[0].Name != "Web App" {
+		t.Errorf("name = %q, want it kept verbatim by default", c.targets[0].Name)
+	}
+
+	if _, err := NewChecker(WithTarget("", "https://example.com")); err == nil {
+		t.Error("expected error for an empty name")
+	}
+	if _, err := NewChecker(WithTarget("api", "https://a.example.com"), WithTarget("api", "https://b.example.com")); err == nil {
+		t.Error("expected error for a duplicate name")
+	}
+}
@@ -0,0 +1,165 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrNoNotifier is returned for a notifier name that is not registered.
+var ErrNoNotifier = errors.New("kenko: no such notifier")
+
+// ErrNoPreview is returned when previewing a notifier that does not
+// implement NotificationPreviewer.
+var ErrNoPreview = errors.New("kenko: notifier cannot preview messages")
+
+// Notification describes a change of a target's status.
+type Notification struct {
+	Target   string
+	Previous Status
+	Status   Status
+	Result   Result
+	At       time.Time
+
+	// Outage is set when the target recovered, describing the failure it
+	// recovered from.
+	Outage *Outage
+
+	// Repeat counts the reminders sent while the target stays down, set by
+	// WithNotifyRepeat. it is 0 for the change itself.
+	Repeat int
+
+	// Uptime is the fraction of the target's observed timeline it was not
+	// unhealthy, as reported by Checker.Uptime.
+	Uptime float64
+
+	// Slow is set for a latency alert rather than a status change: the
+	// target stayed up but answered slower than its SlowLatency, or, when
+	// Slow.Resolved is set, is fast again. Previous and Status are then both
+	// the target's current status.
+	Slow *SlowAlert
+
+	// Test marks a notification sent to check a notifier's delivery and
+	// formatting rather than because the target changed.
+	Test bool
+}
+
+// Outage describes how long a target was failing and the errors its checks
+// reported meanwhile.
+type Outage struct {
+	Start      time.Time     `json:"start"`
+	Duration   time.Duration `json:"duration"`
+	FirstError string        `json:"first_error,omitempty"`
+	LastError  string        `json:"last_error,omitempty"`
+}
+
+// Notifier delivers notifications to a channel, such as a webhook or chat.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc adapts a function to a Notifier.
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+// Notify calls f.
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error { return f(ctx, n) }
+
+// NotificationPreviewer is implemented by notifiers that can render the
+// message they would send without sending it.
+type NotificationPreviewer interface {
+	Preview(n Notification) (string, error)
+}
+
+// Notifiers returns the names of the registered notifiers, sorted.
+func (c *Checker) Notifiers() []string {
+	names := make([]string, 0, len(c.notifiers))
+	for name := range c.notifiers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// TestNotification builds a test notification for target from its latest
+// result, or a sample failure when target is empty or not yet checked.
+// status, if set, replaces the result's status so a recovery can be tried
+// too. the previous status is the opposite of the notified one.
+func (c *Checker) TestNotification(target string, status Status) Notification {
+	var result Result
+	if target != "" {
+		if results, err := c.Results(); err == nil {
+			result = results[target]
+		}
+	}
+	if result.Target == "" {
+		result = c.sampleResult(target)
+	}
+	if status != "" {
+		result.Status = status
+	}
+
+	n := Notification{
+		Target:   result.Target,
+		Previous: StatusHealthy,
+		Status:   result.Status,
+		Result:   result,
+		At:       time.Now(),
+		Uptime:   c.Uptime(result.Target),
+		Test:     true,
+	}
+	if result.Status == StatusHealthy {
+		n.Previous = StatusUnhealthy
+		n.Outage = &Outage{
+			Start:      n.At.Add(-5 * time.Minute),
+			Duration:   5 * time.Minute,
+			FirstError: "sample failure sent to test notifications",
+			LastError:  "sample failure sent to test notifications",
+		}
+	}
+	return n
+}
+
+// sampleResult is a plausible failed check of target, or of a placeholder
+// target when it is empty or unknown.
+func (c *Checker) sampleResult(target string) Result {
+	r := Result{
+		Target:     "example",
+		URL:        "https://example.com/health",
+		Status:     StatusUnhealthy,
+		StatusCode: 503,
+		Latency:    250 * time.Millisecond,
+		Error:      "sample failure sent to test notifications",
+		CheckedAt:  time.Now(),
+		Region:     c.region,
+	}
+	for _, t := range c.targetList() {
+		if t.Name == target {
+			r.Target, r.URL = t.Name, t.URL
+		}
+	}
+	return r
+}
+
+// TestNotifier sends n through the notifier registered as name.
+func (c *Checker) TestNotifier(ctx context.Context, name string, n Notification) error {
+	notifier, ok := c.notifiers[name]
+	if !ok {
+		return ErrNoNotifier
+	}
+	return notifier.Notify(ctx, n)
+}
+
+// PreviewNotifier renders the message the notifier registered as name would
+// send for n, without sending it.
+func (c *Checker) PreviewNotifier(name string, n Notification) (string, error) {
+	notifier, ok := c.notifiers[name]
+	if !ok {
+		return "", ErrNoNotifier
+	}
+	p, ok := notifier.(NotificationPreviewer)
+	if !ok {
+		return "", ErrNoPreview
+	}
+	return p.Preview(n)
+}
@@ -0,0 +1,93 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier keeps the notifications it is sent.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	err  error
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+// previewNotifier renders a one-line message.
+type previewNotifier struct{ recordingNotifier }
+
+func (p *previewNotifier) Preview(n Notification) (string, error) {
+	return fmt.Sprintf("%s is %s: %s", n.Target, n.Status, n.Result.Error), nil
+}
+
+func TestTestNotification_Sample(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"), WithRegion("eu"))
+
+	n := c.TestNotification("", "")
+	if !n.Test || n.Target != "example" || n.Status != StatusUnhealthy || n.Previous != StatusHealthy {
+		t.Errorf("notification = %+v", n)
+	}
+	if n.Result.Region != "eu" || n.Result.Error == "" {
+		t.Errorf("sample result = %+v", n.Result)
+	}
+
+	// a target not yet checked gets a sample with its own name and url
+	n = c.TestNotification("api", StatusHealthy)
+	if n.Target != "api" || n.Result.URL != "https://api.example.com" {
+		t.Errorf("notification = %+v", n)
+	}
+	if n.Status != StatusHealthy || n.Previous != StatusUnhealthy {
+		t.Errorf("recovery = %s -> %s", n.Previous, n.Status)
+	}
+}
+
+func TestTestNotification_RealResult(t *testing.T) {
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"))
+	_ = c.store.Set(context.Background(), "api", Result{Target: "api", Status: StatusDegraded, Error: "slow", CheckedAt: time.Now()})
+
+	n := c.TestNotification("api", "")
+	if n.Status != StatusDegraded || n.Result.Error != "slow" {
+		t.Errorf("notification = %+v", n)
+	}
+}
+
+func TestTestNotifier(t *testing.T) {
+	rec := &recordingNotifier{}
+	c, _ := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithNotifier("slack", rec),
+		WithNotifier("ops", &previewNotifier{}),
+	)
+
+	if got := c.Notifiers(); len(got) != 2 || got[0] != "ops" || got[1] != "slack" {
+		t.Errorf("Notifiers() = %v", got)
+	}
+
+	if err := c.TestNotifier(context.Background(), "slack", c.TestNotification("", "")); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.sent) != 1 || !rec.sent[0].Test {
+		t.Errorf("sent = %+v", rec.sent)
+	}
+
+	if err := c.TestNotifier(context.Background(), "pager", Notification{}); !errors.Is(err, ErrNoNotifier) {
+		t.Errorf("err = %v, want ErrNoNotifier", err)
+	}
+	if _, err := c.PreviewNotifier("slack", Notification{}); !errors.Is(err, ErrNoPreview) {
+		t.Errorf("err = %v, want ErrNoPreview", err)
+	}
+	text, err := c.PreviewNotifier("ops", Notification{Target: "api", Status: StatusUnhealthy, Result: Result{Error: "down"}})
+	if err != nil || text != "api is unhealthy: down" {
+		t.Errorf("preview = %q, %v", text, err)
+	}
+}
@@ -0,0 +1,71 @@
+// Package notifytest holds what the tests of kenko's notifier packages
+// share: the notification they send and the check that HTTP error statuses
+// are retried or not as kenko.RetryableStatus says.
+package notifytest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+// At is when Notification's change happened.
+var At = time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+// Notification returns the change of target api from healthy to status, with
+// the result of a check that got a 503.
+func Notification(status kenko.Status) kenko.Notification {
+	return kenko.Notification{
+		Target:   "api",
+		Previous: kenko.StatusHealthy,
+		Status:   status,
+		At:       At,
+		Result: kenko.Result{
+			Target: "api", URL: "https://api.example.com/health", Status: status, StatusCode: 503, Error: "status 503",
+			Latency: 250 * time.Millisecond, Group: "payments", Labels: map[string]string{"team": "payments"}, CheckedAt: At,
+		},
+	}
+}
+
+// statuses are the error statuses StatusErrors answers with, and whether
+// each must fail permanently.
+var statuses = []struct {
+	code      int
+	permanent bool
+}{
+	{http.StatusBadRequest, true},
+	{http.StatusUnauthorized, true},
+	{http.StatusForbidden, true},
+	{http.StatusNotFound, true},
+	{http.StatusRequestTimeout, false},
+	{http.StatusTooManyRequests, false},
+	{http.StatusInternalServerError, false},
+	{http.StatusServiceUnavailable, false},
+}
+
+// StatusErrors calls notify with the URL of a server that answers with one
+// error status after another and body. each error must contain want, from
+// the body, and be kenko.Permanent for 4xx statuses other than 408 and 429.
+func StatusErrors(t *testing.T, body, want string, notify func(url string) error) {
+	t.Helper()
+	for _, s := range statuses {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(s.code)
+			io.WriteString(w, body)
+		}))
+		err := notify(srv.URL)
+		srv.Close()
+
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("status %d: err = %v, want %q from the response", s.code, err, want)
+		}
+		if got := kenko.IsPermanent(err); got != s.permanent {
+			t.Errorf("status %d: permanent = %v, want %v", s.code, got, s.permanent)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package ntfy is a kenko Notifier that publishes status changes to a topic
+// on ntfy.sh or a self-hosted ntfy server.
+package ntfy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultServer  = "https://ntfy.sh"
+	defaultTimeout = 10 * time.Second
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithServer sets the base url of the ntfy server (default "https://ntfy.sh").
+func WithServer(url string) Option {
+	return func(n *Notifier) { n.server = strings.TrimSuffix(url, "/") }
+}
+
+// WithToken authenticates with an access token, for topics that are not
+// public.
+func WithToken(token string) Option {
+	return func(n *Notifier) { n.token = token }
+}
+
+// WithBasicAuth authenticates with a username and password.
+func WithBasicAuth(username, password string) Option {
+	return func(n *Notifier) { n.username, n.password = username, password }
+}
+
+// WithStatusPageURL opens the status page at url when a notification is
+// tapped.
+func WithStatusPageURL(url string) Option {
+	return func(n *Notifier) { n.statusPage = url }
+}
+
+// WithHTTPClient sets the client messages are published with (default a
+// client with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// Notifier publishes notifications to an ntfy topic.
+type Notifier struct {
+	topic      string
+	server     string
+	token      string
+	username   string
+	password   string
+	statusPage string
+	client     *http.Client
+}
+
+// New creates a Notifier publishing to topic.
+func New(topic string, opts ...Option) *Notifier {
+	n := &Notifier{
+		topic:  topic,
+		server: defaultServer,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// priorities and tags, which ntfy shows as emoji, by status. failures use
+// a priority high enough to vibrate the phone.
+var (
+	priorities = map[kenko.Status]string{
+		kenko.StatusHealthy:   "low",
+		kenko.StatusDegraded:  "default",
+		kenko.StatusUnhealthy: "high",
+	}
+	tags = map[kenko.Status]string{
+		kenko.StatusHealthy:   "white_check_mark",
+		kenko.StatusDegraded:  "warning",
+		kenko.StatusUnhealthy: "rotating_light",
+	}
+)
+
+// Notify publishes the notification's message to the topic.
+func (t *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	return t.publish(ctx, title(n), message(n), priority(n), tag(n))
+}
+
+// NotifyDigest publishes one message for several notifications, a line for
+// each, at the priority of the most urgent of them.
+func (t *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	lines := make([]string, len(ns))
+	worst := ns[0]
+	for i, n := range ns {
+		lines[i] = title(n)
+		if e := n.Result.Error; e != "" && n.Status != kenko.StatusHealthy && n.Slow == nil {
+			lines[i] += ": " + e
+		}
+		if slices.Index(urgency, priority(n)) > slices.Index(urgency, priority(worst)) {
+			worst = n
+		}
+	}
+	return t.publish(ctx, fmt.Sprintf("kenko: %d notifications", len(ns)), strings.Join(lines, "\n"), priority(worst), tag(worst))
+}
+
+// urgency orders the priorities used, from least to most urgent.
+var urgency = []string{"low", "default", "high"}
+
+func (t *Notifier) publish(ctx context.Context, title, message, priority, tag string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.server+"/"+t.topic, strings.NewReader(message))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("ntfy: %w", err))
+	}
+	req.Header.Set("Title", title)
+	req.Header.Set("Priority", priority)
+	if tag != "" {
+		req.Header.Set("Tags", tag)
+	}
+	if t.statusPage != "" {
+		req.Header.Set("Click", t.statusPage)
+	}
+	switch {
+	case t.token != "":
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	case t.username != "":
+		req.SetBasicAuth(t.username, t.password)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	// errors are json such as {"code":40301,"error":"forbidden"}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	code := resp.StatusCode
+	err = fmt.Errorf("ntfy: status %d: %s", code, strings.TrimSpace(string(body)))
+	if !kenko.RetryableStatus(code) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the title and message Notify would publish for n.
+func (t *Notifier) Preview(n kenko.Notification) (string, error) {
+	return title(n) + "\n" + message(n), nil
+}
+
+// priority and tag treat a latency alert as degraded and its recovery as
+// healthy.
+func priority(n kenko.Notification) string {
+	if p, ok := priorities[slowStatus(n)]; ok {
+		return p
+	}
+	return "default"
+}
+
+func tag(n kenko.Notification) string {
+	if n.Slow != nil && !n.Slow.Resolved {
+		return "snail"
+	}
+	return tags[slowStatus(n)]
+}
+
+func slowStatus(n kenko.Notification) kenko.Status {
+	switch {
+	case n.Slow == nil:
+		return n.Status
+	case n.Slow.Resolved:
+		return kenko.StatusHealthy
+	default:
+		return kenko.StatusDegraded
+	}
+}
+
+// title names the target and its new status.
+func title(n kenko.Notification) string {
+	var text string
+	switch {
+	case n.Slow != nil && n.Slow.Resolved:
+		text = fmt.Sprintf("%s is fast again after %s", n.Target, n.At.Sub(n.Slow.Since).Round(time.Second))
+	case n.Slow != nil:
+		text = fmt.Sprintf("%s is slow: over %s for %d checks", n.Target, n.Slow.Threshold, n.Slow.Checks)
+	case n.Outage != nil:
+		text = fmt.Sprintf("%s recovered after %s", n.Target, n.Outage.Duration.Round(time.Second))
+	case n.Repeat > 0:
+		text = fmt.Sprintf("%s is still %s", n.Target, n.Status)
+	default:
+		text = fmt.Sprintf("%s is %s (was %s)", n.Target, n.Status, n.Previous)
+	}
+	if n.Test {
+		text = "[test] " + text
+	}
+	return text
+}
+
+// message lists the details of the result, or of the outage a recovery
+// ended.
+func message(n kenko.Notification) string {
+	var lines []string
+	if o := n.Outage; o != nil {
+		if o.FirstError != "" {
+			lines = append(lines, "First error: "+o.FirstError)
+		}
+		if o.LastError != "" && o.LastError != o.FirstError {
+			lines = append(lines, "Last error: "+o.LastError)
+		}
+	}
+	r := n.Result
+	if r.Error != "" {
+		lines = append(lines, "Error: "+r.Error)
+	}
+	if r.StatusCode != 0 {
+		lines = append(lines, fmt.Sprintf("Status code: %d", r.StatusCode))
+	}
+	lines = append(lines, "Latency: "+r.Latency.Round(time.Millisecond).String())
+	if r.Region != "" {
+		lines = append(lines, "Region: "+r.Region)
+	}
+	return strings.Join(lines, "\n")
+}
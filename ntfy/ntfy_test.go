@@ -0,0 +1,116 @@
+package ntfy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification { return notifytest.Notification(kenko.StatusUnhealthy) }
+
+func TestNotify(t *testing.T) {
+	var path, body string
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, header = r.URL.Path, r.Header
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		io.WriteString(w, `{"id":"x"}`)
+	}))
+	defer srv.Close()
+
+	n := New("homelab", WithServer(srv.URL+"/"), WithToken("tk_secret"), WithStatusPageURL("https://status.example.com"))
+	if err := n.Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/homelab" {
+		t.Errorf("path = %s", path)
+	}
+	if header.Get("Title") != "api is unhealthy (was healthy)" || header.Get("Priority") != "high" || header.Get("Tags") != "rotating_light" ||
+		header.Get("Click") != "https://status.example.com" || header.Get("Authorization") != "Bearer tk_secret" {
+		t.Errorf("headers = %v", header)
+	}
+	if want := "Error: status 503\nStatus code: 503\nLatency: 250ms"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestNotify_BasicAuth(t *testing.T) {
+	var user, pass string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ = r.BasicAuth()
+	}))
+	defer srv.Close()
+
+	if err := New("homelab", WithServer(srv.URL), WithBasicAuth("kenko", "secret")).Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	if user != "kenko" || pass != "secret" {
+		t.Errorf("basic auth = %q:%q", user, pass)
+	}
+}
+
+func TestPreview_Recovery(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Result.Error, n.Result.StatusCode = "", 200
+	n.Outage = &kenko.Outage{Duration: 90 * time.Second, FirstError: "timeout", LastError: "status 503"}
+	n.Test = true
+	text, err := New("homelab").Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[test] api recovered after 1m30s\nFirst error: timeout\nLast error: status 503\nStatus code: 200\nLatency: 250ms"
+	if text != want {
+		t.Errorf("preview = %q, want %q", text, want)
+	}
+}
+
+func TestTitle_Slow(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusHealthy, kenko.StatusHealthy
+	n.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3, Since: n.At.Add(-time.Minute)}
+	if got := title(n); got != "api is slow: over 1s for 3 checks" || priority(n) != "default" || tag(n) != "snail" {
+		t.Errorf("title %q, priority %s, tag %s", got, priority(n), tag(n))
+	}
+	n.Slow.Resolved = true
+	if got := title(n); got != "api is fast again after 1m0s" || priority(n) != "low" || tag(n) != "white_check_mark" {
+		t.Errorf("title %q, priority %s, tag %s", got, priority(n), tag(n))
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+	var body string
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	recovery := notification()
+	recovery.Target, recovery.Previous, recovery.Status = "web", kenko.StatusUnhealthy, kenko.StatusHealthy
+	recovery.Outage = &kenko.Outage{Duration: time.Minute}
+	if err := New("homelab", WithServer(srv.URL)).NotifyDigest(context.Background(), []kenko.Notification{recovery, notification()}); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Title") != "kenko: 2 notifications" || header.Get("Priority") != "high" || header.Get("Tags") != "rotating_light" {
+		t.Errorf("headers = %v", header)
+	}
+	if want := "web recovered after 1m0s\napi is unhealthy (was healthy): status 503"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, `{"code":40301,"error":"forbidden"}`, "forbidden", func(url string) error {
+		return New("homelab", WithServer(url)).Notify(context.Background(), notification())
+	})
+}
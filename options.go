@@ -3,6 +3,7 @@ package kenko
 import (
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -12,11 +13,50 @@ type Option func(*options)
 type options struct {
 	targets  []Target
 	interval time.Duration
+	warmup   time.Duration
 	timeout  time.Duration
 	store    Store
 	metrics  MetricsReporter
 	logger   *slog.Logger
 	client   *http.Client
+	header   http.Header
+	vars     map[string]string
+	region   string
+
+	sourceAddr string
+	sourceIf   string
+
+	writeQueue int
+	writeBatch int
+	writeDelay time.Duration
+	cacheTTL   time.Duration
+
+	snapshotPath string
+
+	revocation RevocationChecker
+	names      NameNormalizer
+	ipEnricher IPEnricher
+	decoders   map[string]ContentDecoder
+	statusPage StatusPage
+	notifiers  map[string]Notifier
+	routes     []Route
+	throttle   *NotifyThrottle
+	repeat     time.Duration
+	grouping   time.Duration
+	quorums    map[string]int
+	heartbeat  *HeartbeatSender
+	transport  *TransportSettings
+	archive    *Archive
+	retention  *Retention
+	breaker    *StoreBreaker
+	rollups    *Rollups
+
+	maintenance map[string][]MaintenanceWindow
+	notifyRetry *NotifyRetry
+
+	targetDecoder TargetDecoder
+	targetSaver   TargetSaver
+	apiToken      string
 }
 
 func defaults() *options {
@@ -24,13 +64,16 @@ func defaults() *options {
 		interval: 30 * time.Second,
 		timeout:  5 * time.Second,
 		logger:   slog.Default(),
+		header:   http.Header{"User-Agent": {DefaultUserAgent}},
+
+		targetDecoder: DecodeTarget,
 	}
 }
 
 // WithTarget adds a named URL to the list of endpoints to check.
-func WithTarget(name, url string) Option {
+func WithTarget(name, url string, opts ...TargetOption) Option {
 	return func(o *options) {
-		o.targets = append(o.targets, Target{Name: name, URL: url})
+		o.targets = append(o.targets, NewTarget(name, url, opts...))
 	}
 }
 
@@ -39,6 +82,13 @@ func WithInterval(d time.Duration) Option {
 	return func(o *options) { o.interval = d }
 }
 
+// WithWarmup spreads the first check of each target evenly over d instead of
+// checking every target at once on start. the checker becomes ready once all
+// of them have been checked.
+func WithWarmup(d time.Duration) Option {
+	return func(o *options) { o.warmup = d }
+}
+
 // WithTimeout sets the HTTP request timeout per check (default 5s).
 func WithTimeout(d time.Duration) Option {
 	return func(o *options) { o.timeout = d }
@@ -63,3 +113,118 @@ func WithLogger(l *slog.Logger) Option {
 func WithHTTPClient(c *http.Client) Option {
 	return func(o *options) { o.client = c }
 }
+
+// WithUserAgent sets the User-Agent sent with every check (default "kenko").
+func WithUserAgent(ua string) Option {
+	return func(o *options) { o.header.Set("User-Agent", ua) }
+}
+
+// WithDefaultHeader sets a request header sent with every check unless a target overrides it.
+func WithDefaultHeader(key, value string) Option {
+	return func(o *options) { o.header.Set(key, value) }
+}
+
+// WithWriteBehind stores results asynchronously through a bounded queue of the
+// given size, so slow store writes never delay the check loop. results are
+// dropped with a warning when the queue is full.
+func WithWriteBehind(queueSize int) Option {
+	return func(o *options) { o.writeQueue = queueSize }
+}
+
+// WithWriteBatch sets how the write-behind queue groups results: up to size
+// results per store write (default 100), waiting up to delay after the first
+// one for more to arrive. with a BatchStore a delay covering the spread of a
+// cycle's checks writes the whole cycle in a few round trips. it has no effect
+// without WithWriteBehind.
+func WithWriteBatch(size int, delay time.Duration) Option {
+	return func(o *options) {
+		o.writeBatch = size
+		o.writeDelay = delay
+	}
+}
+
+// WithResultCache serves Results from an in-process snapshot for up to ttl,
+// only calling the store on a miss. the snapshot is dropped whenever new
+// results are written.
+func WithResultCache(ttl time.Duration) Option {
+	return func(o *options) { o.cacheTTL = ttl }
+}
+
+// WithDefaultVar sets a template variable available to every target URL, e.g.
+// {{.Host}}. targets can override it with WithVar.
+func WithDefaultVar(key, value string) Option {
+	return func(o *options) {
+		if o.vars == nil {
+			o.vars = make(map[string]string)
+		}
+		o.vars[key] = value
+	}
+}
+
+// WithRegion sets the region or probe name recorded on every result, so results
+// and metrics from several vantage points can be told apart.
+func WithRegion(region string) Option {
+	return func(o *options) { o.region = region }
+}
+
+// WithDefaultSourceAddr binds outbound checks to a local IP address unless a
+// target sets its own source.
+func WithDefaultSourceAddr(ip string) Option {
+	return func(o *options) { o.sourceAddr = ip }
+}
+
+// WithDefaultSourceInterface binds outbound checks to the address of the named
+// network interface unless a target sets its own source.
+func WithDefaultSourceInterface(name string) Option {
+	return func(o *options) { o.sourceIf = name }
+}
+
+// WithRevocationChecker sets the RevocationChecker used by targets with
+// revocation checking enabled.
+func WithRevocationChecker(rc RevocationChecker) Option {
+	return func(o *options) { o.revocation = rc }
+}
+
+// WithNameNormalizer sets how target names are validated and normalized,
+// e.g. SlugName. by default, or with nil, names are kept verbatim and only
+// empty and duplicate ones are rejected, so existing targets keep the names
+// their history, state and metrics are stored under.
+func WithNameNormalizer(fn NameNormalizer) Option {
+	return func(o *options) { o.names = fn }
+}
+
+// WithIPEnricher sets an IPEnricher used to attach ASN and location details to
+// the address each check connected to.
+func WithIPEnricher(e IPEnricher) Option {
+	return func(o *options) { o.ipEnricher = e }
+}
+
+// WithContentDecoder adds a decoder for a Content-Encoding such as br, used to
+// measure decompressed sizes of targets with an expected encoding. gzip and
+// deflate are built in.
+func WithContentDecoder(encoding string, dec ContentDecoder) Option {
+	return func(o *options) {
+		if o.decoders == nil {
+			o.decoders = make(map[string]ContentDecoder)
+		}
+		o.decoders[strings.ToLower(encoding)] = dec
+	}
+}
+
+// WithNotifier registers a notifier under name. it is sent a notification
+// whenever a target's status changes, or the changes routed to it when
+// WithRoute is used, and the notifier test API addresses it by name.
+func WithNotifier(name string, n Notifier) Option {
+	return func(o *options) {
+		if o.notifiers == nil {
+			o.notifiers = make(map[string]Notifier)
+		}
+		o.notifiers[name] = n
+	}
+}
+
+// WithStatusPage configures the names, descriptions and wording of the public
+// status page, including translations. components must name configured targets.
+func WithStatusPage(sp StatusPage) Option {
+	return func(o *options) { o.statusPage = sp }
+}
@@ -0,0 +1,58 @@
+package kenko
+
+import (
+	"fmt"
+	"sync"
+)
+
+// outages tracks each failing target's outage, from the check that took it
+// out of healthy to the one that brought it back.
+type outages struct {
+	mu   sync.Mutex
+	open map[string]*Outage
+}
+
+// observe tracks r and returns the outage its recovery ended, if any.
+// maintenance and pauses leave an outage open, so the recovery after them
+// still reports it.
+func (o *outages) observe(r Result) *Outage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	out := o.open[r.Target]
+	switch r.Status {
+	case StatusHealthy:
+		if out == nil {
+			return nil
+		}
+		delete(o.open, r.Target)
+		out.Duration = r.CheckedAt.Sub(out.Start)
+		return out
+	case StatusMaintenance, StatusPaused:
+		return nil
+	}
+
+	if out == nil {
+		if o.open == nil {
+			o.open = make(map[string]*Outage)
+		}
+		out = &Outage{Start: r.CheckedAt}
+		o.open[r.Target] = out
+	}
+	if msg := failure(r); msg != "" {
+		if out.FirstError == "" {
+			out.FirstError = msg
+		}
+		out.LastError = msg
+	}
+	return nil
+}
+
+// failure describes why r failed: its error, or else a status code outside
+// 2xx.
+func failure(r Result) string {
+	if r.Error == "" && r.StatusCode != 0 && (r.StatusCode < 200 || r.StatusCode > 299) {
+		return fmt.Sprintf("status %d", r.StatusCode)
+	}
+	return r.Error
+}
@@ -0,0 +1,76 @@
+package kenko
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOutages(t *testing.T) {
+	var o outages
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i, r := range []Result{
+		{Status: StatusHealthy},
+		{Status: StatusDegraded, Error: "slow"},
+		{Status: StatusUnhealthy},
+		{Status: StatusUnhealthy, Error: "status 503"},
+	} {
+		r.Target, r.CheckedAt = "api", start.Add(time.Duration(i)*time.Minute)
+		if out := o.observe(r); out != nil {
+			t.Fatalf("check %d ended outage %+v", i, out)
+		}
+	}
+
+	out := o.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: start.Add(10 * time.Minute)})
+	if out == nil || !out.Start.Equal(start.Add(time.Minute)) || out.Duration != 9*time.Minute || out.FirstError != "slow" || out.LastError != "status 503" {
+		t.Fatalf("outage = %+v", out)
+	}
+	if out := o.observe(Result{Target: "api", Status: StatusHealthy}); out != nil {
+		t.Errorf("second recovery = %+v", out)
+	}
+
+	// maintenance holds the outage until the recovery after it
+	o.observe(Result{Target: "api", Status: StatusUnhealthy, CheckedAt: start.Add(20 * time.Minute)})
+	o.observe(Result{Target: "api", Status: StatusMaintenance, CheckedAt: start.Add(21 * time.Minute)})
+	if out := o.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: start.Add(30 * time.Minute)}); out == nil || out.Duration != 10*time.Minute {
+		t.Errorf("outage after maintenance = %+v, want 10m", out)
+	}
+}
+
+func TestCheckTarget_NotifiesRecovery(t *testing.T) {
+	var code atomic.Int32
+	code.Store(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(code.Load()))
+	}))
+	defer srv.Close()
+
+	rec := &recordingNotifier{}
+	c, err := NewChecker(WithTarget("api", srv.URL), WithNotifier("ops", rec), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	target := c.targetList()[0]
+
+	c.checkTarget(ctx, target)
+	code.Store(http.StatusOK)
+	c.checkTarget(ctx, target)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	c.dispatcher.run(runCtx)
+
+	if len(rec.sent) != 1 {
+		t.Fatalf("sent = %+v, want the recovery", rec.sent)
+	}
+	n := rec.sent[0]
+	if n.Status != StatusHealthy || n.Outage == nil || n.Outage.Duration <= 0 || n.Outage.FirstError != "status 503" || n.Outage.LastError != "status 503" {
+		t.Errorf("notification = %+v, outage %+v", n, n.Outage)
+	}
+}
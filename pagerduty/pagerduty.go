@@ -0,0 +1,233 @@
+// Package pagerduty is a kenko Notifier that opens and resolves PagerDuty
+// incidents through the Events API v2. every target has its own dedup key,
+// so a recovery resolves the incident its failure opened.
+package pagerduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	defaultTimeout   = 10 * time.Second
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithEventsURL sets the url events are sent to (default
+// "https://events.pagerduty.com/v2/enqueue"), e.g.
+// "https://events.eu.pagerduty.com/v2/enqueue" for an EU account.
+func WithEventsURL(url string) Option {
+	return func(n *Notifier) { n.eventsURL = url }
+}
+
+// WithDedupKeyPrefix sets the prefix of the dedup keys, followed by the
+// target name (default "kenko/"). instances monitoring the same target names
+// for one service need distinct prefixes.
+func WithDedupKeyPrefix(prefix string) Option {
+	return func(n *Notifier) { n.prefix = prefix }
+}
+
+// WithSummary sets the template the summary of trigger events is rendered
+// from, executed with the kenko.Notification, e.g. to name the owning team
+// from the target's labels.
+func WithSummary(t *template.Template) Option {
+	return func(n *Notifier) { n.summary = t }
+}
+
+// WithHTTPClient sets the client events are sent with (default a client with
+// a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// Event is the body of an Events API v2 request.
+type Event struct {
+	RoutingKey  string   `json:"routing_key"`
+	EventAction string   `json:"event_action"`
+	DedupKey    string   `json:"dedup_key"`
+	Payload     *Payload `json:"payload,omitempty"`
+}
+
+// Payload describes the incident a trigger event opens or updates.
+type Payload struct {
+	Summary       string       `json:"summary"`
+	Source        string       `json:"source"`
+	Severity      string       `json:"severity"`
+	Timestamp     string       `json:"timestamp,omitempty"`
+	Component     string       `json:"component,omitempty"`
+	Group         string       `json:"group,omitempty"`
+	CustomDetails kenko.Result `json:"custom_details"`
+}
+
+// Notifier sends notifications to a PagerDuty service.
+type Notifier struct {
+	routingKey string
+	eventsURL  string
+	prefix     string
+	summary    *template.Template
+	client     *http.Client
+}
+
+// New creates a Notifier sending to the service whose integration (routing)
+// key is given.
+func New(routingKey string, opts ...Option) *Notifier {
+	n := &Notifier{
+		routingKey: routingKey,
+		eventsURL:  defaultEventsURL,
+		prefix:     "kenko/",
+		client:     &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// event builds the event for n: a resolve when the target turned healthy,
+// otherwise a trigger, which PagerDuty folds into the open incident of the
+// same dedup key. a slow target has an incident of its own, so it neither
+// resolves nor is resolved by the target's outage.
+func (p *Notifier) event(n kenko.Notification) (Event, error) {
+	key := p.prefix + n.Target
+	if n.Slow != nil {
+		key += "/slow"
+	}
+	if n.Test {
+		// a test must neither resolve nor update a real incident
+		key = p.prefix + "test/" + n.Target
+	}
+	e := Event{RoutingKey: p.routingKey, DedupKey: key}
+	if n.Slow != nil && n.Slow.Resolved || n.Slow == nil && n.Status == kenko.StatusHealthy {
+		e.EventAction = "resolve"
+		return e, nil
+	}
+
+	summary, err := p.renderSummary(n)
+	if err != nil {
+		return Event{}, err
+	}
+	if n.Test {
+		summary = "[test] " + summary
+	}
+	// summaries longer than 1024 characters are rejected
+	if len(summary) > 1024 {
+		summary = summary[:1021] + "..."
+	}
+	source := n.Result.URL
+	if source == "" {
+		source = n.Target
+	}
+	e.EventAction = "trigger"
+	e.Payload = &Payload{
+		Summary:       summary,
+		Source:        source,
+		Severity:      severity(n),
+		Component:     n.Target,
+		Group:         n.Result.Group,
+		CustomDetails: n.Result,
+	}
+	if !n.At.IsZero() {
+		e.Payload.Timestamp = n.At.Format(time.RFC3339)
+	}
+	return e, nil
+}
+
+func (p *Notifier) renderSummary(n kenko.Notification) (string, error) {
+	if p.summary == nil {
+		if n.Slow != nil {
+			return fmt.Sprintf("%s is slow: over %s for %d checks", n.Target, n.Slow.Threshold, n.Slow.Checks), nil
+		}
+		summary := fmt.Sprintf("%s is %s", n.Target, n.Status)
+		if n.Result.Error != "" {
+			summary += ": " + n.Result.Error
+		}
+		return summary, nil
+	}
+	var b strings.Builder
+	if err := p.summary.Execute(&b, n); err != nil {
+		return "", fmt.Errorf("pagerduty: summary: %w", err)
+	}
+	return b.String(), nil
+}
+
+// severity maps a notification to an event severity; a slow target is a
+// warning, and a failing target with a severity of its own is sent that one.
+func severity(n kenko.Notification) string {
+	if n.Slow != nil {
+		return "warning"
+	}
+	if n.Result.Severity != "" {
+		return string(n.Result.Severity)
+	}
+	switch n.Status {
+	case kenko.StatusUnhealthy:
+		return "critical"
+	case kenko.StatusDegraded:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Notify sends the notification's event. PagerDuty answers a valid event
+// with 202 and rejects malformed ones with 400, which is not retried.
+func (p *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	e, err := p.event(n)
+	if err != nil {
+		return kenko.Permanent(err)
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("pagerduty: marshal: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.eventsURL, bytes.NewReader(body))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("pagerduty: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("pagerduty: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	if !kenko.RetryableStatus(resp.StatusCode) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the event Notify would send for n, without its routing
+// key.
+func (p *Notifier) Preview(n kenko.Notification) (string, error) {
+	e, err := p.event(n)
+	if err != nil {
+		return "", err
+	}
+	e.RoutingKey = ""
+	body, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("pagerduty: marshal: %w", err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,129 @@
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification(status kenko.Status) kenko.Notification { return notifytest.Notification(status) }
+
+func TestNotify_TriggerAndResolve(t *testing.T) {
+	var events []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		json.NewDecoder(r.Body).Decode(&e)
+		events = append(events, e)
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"status":"success","dedup_key":"`+e.DedupKey+`"}`)
+	}))
+	defer srv.Close()
+
+	p := New("routing-key", WithEventsURL(srv.URL))
+	for _, status := range []kenko.Status{kenko.StatusUnhealthy, kenko.StatusHealthy} {
+		if err := p.Notify(context.Background(), notification(status)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("events = %+v", events)
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "routing-key" || trigger.Payload == nil {
+		t.Fatalf("trigger = %+v", trigger)
+	}
+	if pl := trigger.Payload; pl.Summary != "api is unhealthy: status 503" || pl.Source != "https://api.example.com/health" ||
+		pl.Severity != "critical" || pl.Group != "payments" || pl.Timestamp != "2026-03-13T12:00:00Z" {
+		t.Errorf("payload = %+v", pl)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey || resolve.Payload != nil {
+		t.Errorf("resolve = %+v, want the trigger's dedup key %q", resolve, trigger.DedupKey)
+	}
+}
+
+func TestEvent_Severity(t *testing.T) {
+	p := New("key")
+	if e, _ := p.event(notification(kenko.StatusUnhealthy)); e.Payload.Severity != "critical" {
+		t.Errorf("unhealthy severity = %q, want critical", e.Payload.Severity)
+	}
+	n := notification(kenko.StatusUnhealthy)
+	n.Result.Severity = kenko.SeverityInfo
+	if e, _ := p.event(n); e.Payload.Severity != "info" {
+		t.Errorf("info target severity = %q, want info", e.Payload.Severity)
+	}
+}
+
+func TestEvent_DedupKeys(t *testing.T) {
+	p := New("key", WithDedupKeyPrefix("eu/"))
+	if e, _ := p.event(notification(kenko.StatusDegraded)); e.DedupKey != "eu/api" {
+		t.Errorf("dedup key = %q", e.DedupKey)
+	}
+	test := notification(kenko.StatusHealthy)
+	test.Test = true
+	if e, _ := p.event(test); e.DedupKey != "eu/test/api" {
+		t.Errorf("test dedup key = %q, want one apart from the target's", e.DedupKey)
+	}
+}
+
+func TestEvent_Slow(t *testing.T) {
+	p := New("key")
+	n := notification(kenko.StatusHealthy)
+	n.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3, Since: n.At}
+	e, err := p.event(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.EventAction != "trigger" || e.DedupKey != "kenko/api/slow" || e.Payload.Severity != "warning" ||
+		e.Payload.Summary != "api is slow: over 1s for 3 checks" {
+		t.Errorf("event = %+v, payload = %+v", e, e.Payload)
+	}
+
+	n.Slow.Resolved = true
+	if e, _ := p.event(n); e.EventAction != "resolve" || e.DedupKey != "kenko/api/slow" {
+		t.Errorf("resolve = %+v", e)
+	}
+}
+
+func TestEvent_Summary(t *testing.T) {
+	tmpl := template.Must(template.New("summary").Parse(`{{.Target}} ({{.Result.Labels.team}}) is {{.Status}}`))
+	n := notification(kenko.StatusUnhealthy)
+	n.Result.Labels = map[string]string{"team": "payments"}
+	e, err := New("key", WithSummary(tmpl)).event(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Payload.Summary != "api (payments) is unhealthy" {
+		t.Errorf("summary = %q", e.Payload.Summary)
+	}
+
+	broken := template.Must(template.New("summary").Parse(`{{.Missing}}`))
+	if err := New("key", WithSummary(broken)).Notify(context.Background(), n); !kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent template error", err)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, `{"status":"invalid event"}`, "invalid event", func(url string) error {
+		return New("key", WithEventsURL(url)).Notify(context.Background(), notification(kenko.StatusUnhealthy))
+	})
+}
+
+func TestPreview(t *testing.T) {
+	text, err := New("secret-key").Preview(notification(kenko.StatusUnhealthy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(text, "secret-key") || !strings.Contains(text, `"event_action": "trigger"`) {
+		t.Errorf("preview = %s", text)
+	}
+}
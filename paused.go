@@ -0,0 +1,197 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pausesStateKey is the StateStore key holding the pauses set through
+// PauseTarget.
+const pausesStateKey = "pauses"
+
+// ErrNotPaused is returned when resuming a target that was not paused with
+// PauseTarget.
+var ErrNotPaused = errors.New("kenko: target is not paused")
+
+// WithPaused keeps the target listed without checking it, e.g. while its
+// service is being migrated. its latest result is reported with StatusPaused,
+// so its history and dashboards survive, and no metrics or notifications are
+// produced for it until it is resumed.
+func WithPaused() TargetOption {
+	return func(t *Target) { t.Paused = true }
+}
+
+// Pause records that a target was paused at runtime rather than in its
+// config, e.g. during planned maintenance.
+type Pause struct {
+	Target   string    `json:"target"`
+	Comment  string    `json:"comment,omitempty"`
+	PausedAt time.Time `json:"paused_at"`
+}
+
+// pauses holds the runtime pauses by target name.
+type pauses struct {
+	mu       sync.Mutex
+	byTarget map[string]Pause
+}
+
+func (ps *pauses) has(name string) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	_, ok := ps.byTarget[name]
+	return ok
+}
+
+// Pauses returns the runtime pauses, ordered by target name. targets paused
+// with WithPaused are not listed.
+func (c *Checker) Pauses() []Pause {
+	c.pauses.mu.Lock()
+	defer c.pauses.mu.Unlock()
+
+	out := make([]Pause, 0, len(c.pauses.byTarget))
+	for _, p := range c.pauses.byTarget {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b Pause) int { return strings.Compare(a.Target, b.Target) })
+	return out
+}
+
+// PauseTarget stops checking the named target until ResumeTarget, as
+// WithPaused does, without a config change. pausing a paused target keeps
+// its original time and replaces the comment. the target's timeline moves
+// to StatusPaused, so the time it is paused counts as neither up nor down,
+// and an open latency alert is resolved.
+// the pause survives SetTargets and is persisted when the store implements
+// StateStore.
+func (c *Checker) PauseTarget(ctx context.Context, name, comment string) (Pause, error) {
+	if !c.hasTarget(name) {
+		return Pause{}, fmt.Errorf("kenko: unknown target %q", name)
+	}
+
+	c.pauses.mu.Lock()
+	defer c.pauses.mu.Unlock()
+	p, ok := c.pauses.byTarget[name]
+	if !ok {
+		p = Pause{Target: name, PausedAt: time.Now()}
+	}
+	p.Comment = comment
+
+	// the pause only takes effect once it is persisted
+	byTarget := maps.Clone(c.pauses.byTarget)
+	if byTarget == nil {
+		byTarget = make(map[string]Pause)
+	}
+	byTarget[name] = p
+	if err := c.saveState(ctx, pausesStateKey, byTarget); err != nil {
+		return Pause{}, err
+	}
+	c.pauses.byTarget = byTarget
+	if !ok {
+		r := Result{Target: name, Status: StatusPaused, CheckedAt: p.PausedAt, Region: c.region}
+		for _, t := range c.targetList() {
+			if t.Name != name {
+				continue
+			}
+			if alert := c.slowness.observe(t, r); alert != nil {
+				c.notifySlow(r, alert)
+			}
+		}
+		if prev, changed := c.timeline.observe(r); changed {
+			c.recordEvent(ctx, prev, r)
+		}
+		c.forgetTarget(name)
+	}
+	return p, nil
+}
+
+// ResumeTarget lifts a pause set with PauseTarget, so the target is checked
+// again from the next cycle. a target paused in its config stays paused.
+func (c *Checker) ResumeTarget(ctx context.Context, name string) error {
+	c.pauses.mu.Lock()
+	defer c.pauses.mu.Unlock()
+
+	if _, ok := c.pauses.byTarget[name]; !ok {
+		return ErrNotPaused
+	}
+	byTarget := maps.Clone(c.pauses.byTarget)
+	delete(byTarget, name)
+	if err := c.saveState(ctx, pausesStateKey, byTarget); err != nil {
+		return err
+	}
+	c.pauses.byTarget = byTarget
+	c.schedule.poke()
+	return nil
+}
+
+// loadPauses restores persisted pauses, keeping any set in memory already.
+func (c *Checker) loadPauses(ctx context.Context) error {
+	var stored map[string]Pause
+	if ok, err := c.loadState(ctx, pausesStateKey, &stored); !ok {
+		return err
+	}
+
+	c.pauses.mu.Lock()
+	defer c.pauses.mu.Unlock()
+	if c.pauses.byTarget == nil {
+		c.pauses.byTarget = make(map[string]Pause)
+	}
+	for name, p := range stored {
+		if _, ok := c.pauses.byTarget[name]; !ok {
+			c.pauses.byTarget[name] = p
+		}
+	}
+	return nil
+}
+
+// paused reports whether t is paused in its config or at runtime.
+func (c *Checker) paused(t Target) bool {
+	return t.Paused || c.pauses.has(t.Name)
+}
+
+// activeTargets returns the current targets that are not paused.
+func (c *Checker) activeTargets() []Target {
+	targets := c.targetList()
+	var out []Target
+	for _, t := range targets {
+		if !c.paused(t) {
+			out = append(out, t)
+		}
+	}
+	if len(out) == len(targets) {
+		return targets
+	}
+	return out
+}
+
+// withPaused returns results with every paused target's latest result marked
+// StatusPaused, or a placeholder when it was never checked. results itself is
+// left untouched as it may be cached.
+func (c *Checker) withPaused(results map[string]Result) map[string]Result {
+	var out map[string]Result
+	for _, t := range c.targetList() {
+		if !c.paused(t) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]Result, len(results))
+			maps.Copy(out, results)
+		}
+		r, ok := out[t.Name]
+		if !ok {
+			r = Result{Target: t.Name, URL: t.URL, Region: c.region, Group: t.Group, Severity: t.Severity, Labels: t.Labels}
+		}
+		r.Status = StatusPaused
+		r.Error = ""
+		out[t.Name] = r
+	}
+	if out == nil {
+		return results
+	}
+	return out
+}
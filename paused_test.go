@@ -0,0 +1,307 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_PausedTargetNotChecked(t *testing.T) {
+	var active, paused atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/paused" {
+			paused.Add(1)
+		} else {
+			active.Add(1)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("active", ts.URL+"/active"),
+		WithTarget("paused", ts.URL+"/paused", WithPaused()),
+		WithInterval(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for active.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("active target checked %d times", active.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if n := paused.Load(); n != 0 {
+		t.Errorf("paused target checked %d times", n)
+	}
+	if !c.Ready() {
+		t.Error("checker not ready")
+	}
+}
+
+func TestResults_Paused(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(
+		WithStore(store),
+		WithTarget("api", "https://api.example.com", WithPaused()),
+		WithTarget("web", "https://web.example.com", WithPaused(), WithGroup("shop")),
+		WithTarget("db", "https://db.example.com", WithGroup("shop")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Set(context.Background(), "api", Result{Target: "api", Status: StatusUnhealthy, StatusCode: 503, Error: "status 503"})
+	_ = store.Set(context.Background(), "db", Result{Target: "db", Status: StatusHealthy})
+
+	results, err := c.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results["api"]; r.Status != StatusPaused || r.StatusCode != 503 || r.Error != "" {
+		t.Errorf("api = %+v, want its last result marked paused", r)
+	}
+	if r := results["web"]; r.Status != StatusPaused || r.URL != "https://web.example.com" || r.Group != "shop" {
+		t.Errorf("web = %+v, want a paused placeholder", r)
+	}
+	if r := results["db"]; r.Status != StatusHealthy {
+		t.Errorf("db = %+v", r)
+	}
+
+	stored, _ := store.GetAll(context.Background())
+	if stored["api"].Status != StatusUnhealthy {
+		t.Error("stored result was modified")
+	}
+
+	groups, err := c.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Status != StatusHealthy || groups[0].Degraded != 0 {
+		t.Errorf("groups = %+v, want shop healthy with web not counted", groups)
+	}
+}
+
+func TestPauseTarget(t *testing.T) {
+	store := NewMemoryStore()
+	metrics := &forgetRecorder{}
+	c, err := NewChecker(
+		WithStore(store),
+		WithMetrics(metrics),
+		WithTarget("api", "https://api.example.com"),
+		WithTarget("web", "https://web.example.com", WithPaused()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_ = store.Set(ctx, "api", Result{Target: "api", Status: StatusHealthy})
+
+	if _, err := c.PauseTarget(ctx, "missing", ""); err == nil {
+		t.Error("expected an error pausing an unknown target")
+	}
+	p, err := c.PauseTarget(ctx, "api", "db migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Target != "api" || p.Comment != "db migration" || p.PausedAt.IsZero() {
+		t.Errorf("pause = %+v", p)
+	}
+	if len(metrics.forgotten) != 1 || metrics.forgotten[0] != "api" {
+		t.Errorf("forgotten = %v, want the paused target's metrics dropped", metrics.forgotten)
+	}
+	again, err := c.PauseTarget(ctx, "api", "longer migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.PausedAt.Equal(p.PausedAt) || again.Comment != "longer migration" {
+		t.Errorf("second pause = %+v, want the original time and the new comment", again)
+	}
+
+	if active := c.activeTargets(); len(active) != 0 {
+		t.Errorf("active targets = %+v, want none", active)
+	}
+	results, _ := c.Results()
+	if results["api"].Status != StatusPaused {
+		t.Errorf("api = %+v, want paused", results["api"])
+	}
+
+	// a restarted checker picks the pause up from the store
+	restarted, err := NewChecker(WithStore(store), WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restarted.loadPauses(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.Pauses(); len(got) != 1 || got[0].Comment != "longer migration" {
+		t.Errorf("restored pauses = %+v", got)
+	}
+
+	if err := c.ResumeTarget(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ResumeTarget(ctx, "api"); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("second resume err = %v, want ErrNotPaused", err)
+	}
+	if err := c.ResumeTarget(ctx, "web"); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("resuming a config pause err = %v, want ErrNotPaused", err)
+	}
+	if active := c.activeTargets(); len(active) != 1 || active[0].Name != "api" {
+		t.Errorf("active targets = %+v, want api", active)
+	}
+}
+
+func TestPauseTarget_ClosesSegment(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithStore(store), WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	down := time.Now().Add(-time.Hour)
+	c.timeline.observe(Result{Target: "api", Status: StatusUnhealthy, CheckedAt: down})
+
+	p, err := c.PauseTarget(ctx, "api", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	segs := c.timeline.get("api")
+	if len(segs) != 2 || segs[0].Status != StatusUnhealthy || !segs[0].End.Equal(p.PausedAt) || segs[1].Status != StatusPaused {
+		t.Errorf("segments = %+v, want the failure closed at the pause", segs)
+	}
+	events, _ := store.Events(ctx, EventQuery{Target: "api"})
+	if len(events) != 1 || events[0].From != StatusUnhealthy || events[0].To != StatusPaused {
+		t.Errorf("events = %+v, want the change to paused", events)
+	}
+}
+
+func TestPauseTarget_ResolvesSlowAlert(t *testing.T) {
+	chat := &recordingNotifier{}
+	c, err := NewChecker(
+		WithTarget("api", "https://api.example.com", WithSlowThreshold(time.Second, 0)),
+		WithNotifier("chat", chat),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.slowness.observe(c.targetList()[0], Result{Target: "api", Status: StatusHealthy, Latency: 2 * time.Second, CheckedAt: time.Now()})
+
+	if _, err := c.PauseTarget(context.Background(), "api", ""); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.dispatcher.run(ctx)
+
+	if len(chat.sent) != 1 || chat.sent[0].Slow == nil || !chat.sent[0].Slow.Resolved {
+		t.Errorf("sent %+v, want the latency alert resolved", chat.sent)
+	}
+}
+
+// failingStateStore is a MemoryStore whose state can no longer be saved.
+type failingStateStore struct{ *MemoryStore }
+
+func (failingStateStore) SaveState(context.Context, string, []byte) error {
+	return errors.New("disk full")
+}
+
+func TestPauseTarget_SaveFails(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithStore(store), WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := c.PauseTarget(ctx, "api", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// a resume that cannot be saved leaves the target paused
+	c.store = failingStateStore{store}
+	if err := c.ResumeTarget(ctx, "api"); err == nil {
+		t.Fatal("expected an error when the state cannot be saved")
+	}
+	if len(c.Pauses()) != 1 {
+		t.Errorf("pauses = %+v, want api still paused", c.Pauses())
+	}
+
+	// nor does a pause that cannot be saved take effect
+	c.store = store
+	if err := c.ResumeTarget(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	c.store = failingStateStore{store}
+	if _, err := c.PauseTarget(ctx, "api", ""); err == nil {
+		t.Fatal("expected an error when the state cannot be saved")
+	}
+	if len(c.Pauses()) != 0 {
+		t.Errorf("pauses = %+v, want none", c.Pauses())
+	}
+}
+
+func TestHandlePauseTarget(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(h http.HandlerFunc, method, name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/targets/"+name+"/pause", strings.NewReader(body))
+		req.SetPathValue("name", name)
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec
+	}
+
+	rec := do(HandlePauseTarget(c), http.MethodPut, "api", `{"comment":"maintenance"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("pause status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var p Pause
+	_ = json.NewDecoder(rec.Body).Decode(&p)
+	if p.Target != "api" || p.Comment != "maintenance" {
+		t.Errorf("pause = %+v", p)
+	}
+
+	rec = httptest.NewRecorder()
+	HandlePauses(c)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/pauses", nil))
+	var list []Pause
+	_ = json.NewDecoder(rec.Body).Decode(&list)
+	if len(list) != 1 || list[0].Target != "api" {
+		t.Errorf("pauses = %+v", list)
+	}
+
+	if rec := do(HandlePauseTarget(c), http.MethodPut, "missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown target status = %d, want 404", rec.Code)
+	}
+	if rec := do(HandlePauseTarget(c), http.MethodPut, "api", "{"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad body status = %d, want 400", rec.Code)
+	}
+	if rec := do(HandleResumeTarget(c), http.MethodDelete, "api", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("resume status = %d, want 204", rec.Code)
+	}
+	if rec := do(HandleResumeTarget(c), http.MethodDelete, "api", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second resume status = %d, want 404", rec.Code)
+	}
+}
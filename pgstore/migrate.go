@@ -0,0 +1,167 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// migrations are applied in order, each once, and never edited after
+// release: a schema change is a new migration appended to the list. {p} is
+// replaced by the table prefix.
+var migrations = []string{
+	// 1: results, latest results, transitions, and operator state
+	`CREATE TABLE {p}results (
+		id          bigserial PRIMARY KEY,
+		target      text NOT NULL,
+		status      text NOT NULL,
+		status_code integer NOT NULL,
+		latency_ms  double precision NOT NULL,
+		error       text NOT NULL,
+		region      text NOT NULL,
+		checked_at  timestamptz NOT NULL,
+		result      jsonb NOT NULL
+	);
+	CREATE INDEX {p}results_target_checked_at_idx ON {p}results (target, checked_at DESC);
+	CREATE INDEX {p}results_checked_at_idx ON {p}results (checked_at);
+
+	CREATE TABLE {p}latest (
+		target     text PRIMARY KEY,
+		status     text NOT NULL,
+		checked_at timestamptz NOT NULL,
+		result     jsonb NOT NULL
+	);
+
+	CREATE TABLE {p}transitions (
+		id       bigserial PRIMARY KEY,
+		target   text NOT NULL,
+		previous text NOT NULL,
+		status   text NOT NULL,
+		error    text NOT NULL,
+		at       timestamptz NOT NULL
+	);
+	CREATE INDEX {p}transitions_target_at_idx ON {p}transitions (target, at DESC);
+
+	CREATE TABLE {p}state (
+		key        text PRIMARY KEY,
+		data       bytea NOT NULL,
+		updated_at timestamptz NOT NULL
+	);`,
+
+	// 2: status change events
+	`CREATE TABLE {p}events (
+		id          bigserial PRIMARY KEY,
+		target      text NOT NULL,
+		from_status text NOT NULL,
+		to_status   text NOT NULL,
+		at          timestamptz NOT NULL,
+		duration_ms double precision NOT NULL,
+		error       text NOT NULL,
+		region      text NOT NULL
+	);
+	CREATE INDEX {p}events_at_idx ON {p}events (at DESC);
+	CREATE INDEX {p}events_target_at_idx ON {p}events (target, at DESC);`,
+
+	// 3: history rollups
+	`CREATE TABLE {p}rollups (
+		target           text NOT NULL,
+		resolution_s     integer NOT NULL,
+		start            timestamptz NOT NULL,
+		checks           integer NOT NULL,
+		down             integer NOT NULL,
+		min_latency_ms   double precision NOT NULL,
+		max_latency_ms   double precision NOT NULL,
+		total_latency_ms double precision NOT NULL,
+		PRIMARY KEY (target, resolution_s, start)
+	);
+	CREATE INDEX {p}rollups_resolution_start_idx ON {p}rollups (resolution_s, start);`,
+
+	// 4: notification deliveries
+	`CREATE TABLE {p}deliveries (
+		id         bigserial PRIMARY KEY,
+		notifier   text NOT NULL,
+		target     text NOT NULL,
+		status     text NOT NULL,
+		repeat     integer NOT NULL,
+		outcome    text NOT NULL,
+		at         timestamptz NOT NULL,
+		attempts   integer NOT NULL,
+		latency_ms double precision NOT NULL,
+		error      text NOT NULL,
+		region     text NOT NULL
+	);
+	CREATE INDEX {p}deliveries_at_idx ON {p}deliveries (at DESC);
+	CREATE INDEX {p}deliveries_target_at_idx ON {p}deliveries (target, at DESC);`,
+
+	// 5: status changes recorded only as events. the transitions from before
+	// a target's first event are carried over, timed from the one before.
+	`INSERT INTO {p}events (target, from_status, to_status, at, duration_ms, error, region)
+	SELECT target, previous, status, at, duration_ms, error, ''
+	FROM (
+		SELECT t.*, coalesce(extract(epoch FROM t.at - lag(t.at) OVER w) * 1000, 0) AS duration_ms
+		FROM {p}transitions t
+		WINDOW w AS (PARTITION BY t.target ORDER BY t.at, t.id)
+	) t
+	WHERE previous <> ''
+	AND NOT EXISTS (SELECT 1 FROM {p}events e WHERE e.target = t.target AND e.at <= t.at);
+	DROP TABLE {p}transitions;`,
+}
+
+// migrate brings the schema up to date. replicas starting together are
+// serialized by an advisory lock, so each migration runs exactly once.
+func (s *Store) migrate(ctx context.Context) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("pgstore: migrate: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, s.table("migrations")); err != nil {
+		return fmt.Errorf("pgstore: migrate: lock: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+s.table("migrations")+` (
+		version    integer PRIMARY KEY,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("pgstore: migrate: %w", err)
+	}
+
+	var current int
+	if err := tx.QueryRow(ctx, `SELECT coalesce(max(version), 0) FROM `+s.table("migrations")).Scan(&current); err != nil {
+		return fmt.Errorf("pgstore: migrate: %w", err)
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("pgstore: schema version %d is newer than this kenko's %d", current, len(migrations))
+	}
+
+	for i, m := range migrations[current:] {
+		version := current + i + 1
+		if _, err := tx.Exec(ctx, expand(m, s.prefix)); err != nil {
+			return fmt.Errorf("pgstore: migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO `+s.table("migrations")+` (version) VALUES ($1)`, version); err != nil {
+			return fmt.Errorf("pgstore: migration %d: %w", version, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// expand substitutes the table prefix into a migration.
+func expand(migration, prefix string) string {
+	return strings.ReplaceAll(migration, "{p}", prefix)
+}
+
+// validPrefix reports whether prefix can be spliced into table names
+// unquoted: lowercase letters, digits, and underscores, not starting with a
+// digit.
+func validPrefix(prefix string) bool {
+	for i, c := range prefix {
+		switch {
+		case c >= 'a' && c <= 'z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
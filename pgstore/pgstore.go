@@ -0,0 +1,564 @@
+// Package pgstore is a kenko Store backed by PostgreSQL. besides the latest
+// result of each target it keeps every stored result, every status
+// transition, and every notification delivery until kenko's retention prunes
+// them, so history can be queried with SQL and backed up with the usual
+// Postgres tooling.
+package pgstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const defaultTablePrefix = "kenko_"
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithTablePrefix sets the prefix of the tables the store creates and uses
+// (default "kenko_"), e.g. to keep several deployments in one database.
+func WithTablePrefix(prefix string) Option {
+	return func(s *Store) { s.prefix = prefix }
+}
+
+// Store is a kenko Store backed by PostgreSQL.
+type Store struct {
+	pool   *pgxpool.Pool
+	prefix string
+
+	mu       sync.Mutex
+	migrated bool
+}
+
+// New creates a Store for the database at dsn, a postgres:// URL or
+// key=value connection string. it connects lazily, and the schema is
+// migrated to the current version before the store is first used.
+func New(dsn string, opts ...Option) (*Store, error) {
+	s := &Store{prefix: defaultTablePrefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !validPrefix(s.prefix) {
+		return nil, fmt.Errorf("pgstore: invalid table prefix %q", s.prefix)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: %w", err)
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: %w", err)
+	}
+	s.pool = pool
+	return s, nil
+}
+
+// ValidateDSN reports whether dsn is a valid connection string for New.
+func ValidateDSN(dsn string) error {
+	_, err := pgxpool.ParseConfig(dsn)
+	return err
+}
+
+// Migrate brings the schema up to date. it is called before the store is
+// first used, so calling it is only needed to migrate ahead of time.
+func (s *Store) Migrate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.migrated {
+		return nil
+	}
+	if err := s.migrate(ctx); err != nil {
+		return err
+	}
+	s.migrated = true
+	return nil
+}
+
+// table returns the name of one of the store's tables.
+func (s *Store) table(name string) string {
+	return s.prefix + name
+}
+
+// Set records the result in the target's history, replaces its latest result,
+// and records a transition when its status changed.
+func (s *Store) Set(ctx context.Context, name string, result kenko.Result) error {
+	return s.SetBatch(ctx, []kenko.Result{withTarget(result, name)})
+}
+
+// SetBatch stores several results in one transaction, keyed by their target
+// name.
+func (s *Store) SetBatch(ctx context.Context, results []kenko.Result) error {
+	if err := s.Migrate(ctx); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("pgstore: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, r := range results {
+		if err := s.insert(ctx, tx, r); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("pgstore: commit: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) insert(ctx context.Context, tx pgx.Tx, r kenko.Result) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("pgstore: marshal: %w", err)
+	}
+
+	checkedAt := checkedAt(r)
+	if _, err := tx.Exec(ctx, `INSERT INTO `+s.table("results")+` (target, status, status_code, latency_ms, error, region, checked_at, result)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		r.Target, string(r.Status), r.StatusCode, float64(r.Latency)/float64(time.Millisecond), r.Error, r.Region, checkedAt, data,
+	); err != nil {
+		return fmt.Errorf("pgstore: insert %q: %w", r.Target, err)
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO `+s.table("latest")+` (target, status, checked_at, result)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (target) DO UPDATE SET status = excluded.status, checked_at = excluded.checked_at, result = excluded.result`,
+		r.Target, string(r.Status), checkedAt, data,
+	); err != nil {
+		return fmt.Errorf("pgstore: update %q: %w", r.Target, err)
+	}
+	return nil
+}
+
+// GetAll returns the latest result of every target.
+func (s *Store) GetAll(ctx context.Context) (map[string]kenko.Result, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT target, result FROM `+s.table("latest"))
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: query: %w", err)
+	}
+	defer rows.Close()
+
+	vals := make(map[string][]byte)
+	for rows.Next() {
+		var name string
+		var data []byte
+		if err := rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("pgstore: scan: %w", err)
+		}
+		vals[name] = data
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: query: %w", err)
+	}
+	return decodeResults(vals), nil
+}
+
+// History returns the target's stored results matching q, newest first.
+func (s *Store) History(ctx context.Context, name string, q kenko.HistoryQuery) ([]kenko.Result, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	// nil bounds and limit leave the query open on that side
+	var from, to, limit any
+	if !q.From.IsZero() {
+		from = q.From
+	}
+	if !q.To.IsZero() {
+		to = q.To
+	}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.pool.Query(ctx, `SELECT result FROM `+s.table("results")+`
+		WHERE target = $1
+		AND ($2::timestamptz IS NULL OR checked_at >= $2)
+		AND ($3::timestamptz IS NULL OR checked_at <= $3)
+		ORDER BY checked_at DESC, id DESC
+		LIMIT $4`, name, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: history %q: %w", name, err)
+	}
+	defer rows.Close()
+
+	var out []kenko.Result
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("pgstore: scan: %w", err)
+		}
+		var r kenko.Result
+		if err := json.Unmarshal(data, &r); err != nil {
+			r = kenko.Result{Target: name, Status: kenko.StatusUnknown, Error: fmt.Sprintf("pgstore: unmarshal: %v", err)}
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: history %q: %w", name, err)
+	}
+	return out, nil
+}
+
+// PruneHistory deletes the target's results checked before before. its
+// events are kept.
+func (s *Store) PruneHistory(ctx context.Context, name string, before time.Time) (int, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return 0, err
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM `+s.table("results")+` WHERE target = $1 AND checked_at < $2`, name, before)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: prune history %q: %w", name, err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// PruneTransitions deletes the events of every target from before before.
+func (s *Store) PruneTransitions(ctx context.Context, before time.Time) (int, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return 0, err
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM `+s.table("events")+` WHERE at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: prune events: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// AddEvent records a status change event.
+func (s *Store) AddEvent(ctx context.Context, e kenko.Event) error {
+	if err := s.Migrate(ctx); err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx, `INSERT INTO `+s.table("events")+` (target, from_status, to_status, at, duration_ms, error, region)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		e.Target, string(e.From), string(e.To), e.At, float64(e.Duration)/float64(time.Millisecond), e.Error, e.Region,
+	); err != nil {
+		return fmt.Errorf("pgstore: add event %q: %w", e.Target, err)
+	}
+	return nil
+}
+
+// Events returns the events matching q, newest first.
+func (s *Store) Events(ctx context.Context, q kenko.EventQuery) ([]kenko.Event, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	// nil filters and limit leave the query open on that side
+	var target, from, to, limit any
+	if q.Target != "" {
+		target = q.Target
+	}
+	if !q.From.IsZero() {
+		from = q.From
+	}
+	if !q.To.IsZero() {
+		to = q.To
+	}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.pool.Query(ctx, `SELECT target, from_status, to_status, at, duration_ms, error, region FROM `+s.table("events")+`
+		WHERE ($1::text IS NULL OR target = $1)
+		AND ($2::timestamptz IS NULL OR at >= $2)
+		AND ($3::timestamptz IS NULL OR at <= $3)
+		ORDER BY at DESC, id DESC
+		LIMIT $4`, target, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []kenko.Event
+	for rows.Next() {
+		var e kenko.Event
+		var fromStatus, toStatus string
+		var durationMS float64
+		if err := rows.Scan(&e.Target, &fromStatus, &toStatus, &e.At, &durationMS, &e.Error, &e.Region); err != nil {
+			return nil, fmt.Errorf("pgstore: scan: %w", err)
+		}
+		e.From, e.To = kenko.Status(fromStatus), kenko.Status(toStatus)
+		e.Duration = time.Duration(durationMS * float64(time.Millisecond))
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: events: %w", err)
+	}
+	return out, nil
+}
+
+// AddDelivery records a notification delivery.
+func (s *Store) AddDelivery(ctx context.Context, d kenko.Delivery) error {
+	if err := s.Migrate(ctx); err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx, `INSERT INTO `+s.table("deliveries")+` (notifier, target, status, repeat, outcome, at, attempts, latency_ms, error, region)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		d.Notifier, d.Target, string(d.Status), d.Repeat, d.Outcome, d.At, d.Attempts, float64(d.Latency)/float64(time.Millisecond), d.Error, d.Region,
+	); err != nil {
+		return fmt.Errorf("pgstore: add delivery %q: %w", d.Target, err)
+	}
+	return nil
+}
+
+// PruneDeliveries deletes the deliveries from before before.
+func (s *Store) PruneDeliveries(ctx context.Context, before time.Time) (int, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return 0, err
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM `+s.table("deliveries")+` WHERE at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: prune deliveries: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Deliveries returns the deliveries matching q, newest first.
+func (s *Store) Deliveries(ctx context.Context, q kenko.DeliveryQuery) ([]kenko.Delivery, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	// nil filters and limit leave the query open on that side
+	var target, notifier, from, to, limit any
+	if q.Target != "" {
+		target = q.Target
+	}
+	if q.Notifier != "" {
+		notifier = q.Notifier
+	}
+	if !q.From.IsZero() {
+		from = q.From
+	}
+	if !q.To.IsZero() {
+		to = q.To
+	}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.pool.Query(ctx, `SELECT notifier, target, status, repeat, outcome, at, attempts, latency_ms, error, region FROM `+s.table("deliveries")+`
+		WHERE ($1::text IS NULL OR target = $1)
+		AND ($2::text IS NULL OR notifier = $2)
+		AND ($3::timestamptz IS NULL OR at >= $3)
+		AND ($4::timestamptz IS NULL OR at <= $4)
+		ORDER BY at DESC, id DESC
+		LIMIT $5`, target, notifier, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var out []kenko.Delivery
+	for rows.Next() {
+		var d kenko.Delivery
+		var status string
+		var latencyMS float64
+		if err := rows.Scan(&d.Notifier, &d.Target, &status, &d.Repeat, &d.Outcome, &d.At, &d.Attempts, &latencyMS, &d.Error, &d.Region); err != nil {
+			return nil, fmt.Errorf("pgstore: scan: %w", err)
+		}
+		d.Status = kenko.Status(status)
+		d.Latency = time.Duration(latencyMS * float64(time.Millisecond))
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: deliveries: %w", err)
+	}
+	return out, nil
+}
+
+// SaveRollups stores the target's rollups of the resolution, replacing any
+// with the same start.
+func (s *Store) SaveRollups(ctx context.Context, name string, resolution time.Duration, rollups []kenko.Rollup) error {
+	if err := s.Migrate(ctx); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("pgstore: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, r := range rollups {
+		if _, err := tx.Exec(ctx, `INSERT INTO `+s.table("rollups")+` (target, resolution_s, start, checks, down, min_latency_ms, max_latency_ms, total_latency_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (target, resolution_s, start) DO UPDATE SET checks = excluded.checks, down = excluded.down,
+				min_latency_ms = excluded.min_latency_ms, max_latency_ms = excluded.max_latency_ms, total_latency_ms = excluded.total_latency_ms`,
+			name, int(resolution/time.Second), r.Start, r.Checks, r.Down, ms(r.MinLatency), ms(r.MaxLatency), ms(r.TotalLatency),
+		); err != nil {
+			return fmt.Errorf("pgstore: save rollups %q: %w", name, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("pgstore: commit: %w", err)
+	}
+	return nil
+}
+
+// Rollups returns the target's rollups of the resolution starting between
+// from and to, oldest first.
+func (s *Store) Rollups(ctx context.Context, name string, resolution time.Duration, from, to time.Time) ([]kenko.Rollup, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	// nil bounds leave the query open on that side
+	var fromArg, toArg any
+	if !from.IsZero() {
+		fromArg = from
+	}
+	if !to.IsZero() {
+		toArg = to
+	}
+	rows, err := s.pool.Query(ctx, `SELECT start, checks, down, min_latency_ms, max_latency_ms, total_latency_ms FROM `+s.table("rollups")+`
+		WHERE target = $1 AND resolution_s = $2
+		AND ($3::timestamptz IS NULL OR start >= $3)
+		AND ($4::timestamptz IS NULL OR start <= $4)
+		ORDER BY start`, name, int(resolution/time.Second), fromArg, toArg)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: rollups %q: %w", name, err)
+	}
+	defer rows.Close()
+
+	var out []kenko.Rollup
+	for rows.Next() {
+		var r kenko.Rollup
+		var minMS, maxMS, totalMS float64
+		if err := rows.Scan(&r.Start, &r.Checks, &r.Down, &minMS, &maxMS, &totalMS); err != nil {
+			return nil, fmt.Errorf("pgstore: scan: %w", err)
+		}
+		r.MinLatency, r.MaxLatency, r.TotalLatency = fromMS(minMS), fromMS(maxMS), fromMS(totalMS)
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: rollups %q: %w", name, err)
+	}
+	return out, nil
+}
+
+// PruneRollups deletes the rollups of the resolution of every target starting
+// before before.
+func (s *Store) PruneRollups(ctx context.Context, resolution time.Duration, before time.Time) (int, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return 0, err
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM `+s.table("rollups")+` WHERE resolution_s = $1 AND start < $2`, int(resolution/time.Second), before)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: prune rollups: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Delete removes the latest result stored for name. its history is kept.
+func (s *Store) Delete(ctx context.Context, name string) error {
+	if err := s.Migrate(ctx); err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx, `DELETE FROM `+s.table("latest")+` WHERE target = $1`, name); err != nil {
+		return fmt.Errorf("pgstore: delete %q: %w", name, err)
+	}
+	return nil
+}
+
+// SaveState stores an operator state blob under key.
+func (s *Store) SaveState(ctx context.Context, key string, data []byte) error {
+	if err := s.Migrate(ctx); err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx, `INSERT INTO `+s.table("state")+` (key, data, updated_at) VALUES ($1, $2, now())
+		ON CONFLICT (key) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`, key, data); err != nil {
+		return fmt.Errorf("pgstore: save state %q: %w", key, err)
+	}
+	return nil
+}
+
+// LoadState returns the operator state blob stored under key, or nil if unset.
+func (s *Store) LoadState(ctx context.Context, key string) ([]byte, error) {
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err := s.pool.QueryRow(ctx, `SELECT data FROM `+s.table("state")+` WHERE key = $1`, key).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: load state %q: %w", key, err)
+	}
+	return data, nil
+}
+
+// Ping checks connectivity to the database.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// Close closes the store's connections.
+func (s *Store) Close() {
+	s.pool.Close()
+}
+
+// Pool returns the underlying connection pool.
+func (s *Store) Pool() *pgxpool.Pool {
+	return s.pool
+}
+
+// decodeResults unmarshals stored results. rows that fail to decode are
+// reported with StatusUnknown and the parse error rather than being dropped,
+// so the target stays visible.
+func decodeResults(vals map[string][]byte) map[string]kenko.Result {
+	out := make(map[string]kenko.Result, len(vals))
+	for name, data := range vals {
+		var r kenko.Result
+		if err := json.Unmarshal(data, &r); err != nil {
+			out[name] = kenko.Result{
+				Target: name,
+				Status: kenko.StatusUnknown,
+				Error:  fmt.Sprintf("pgstore: unmarshal: %v", err),
+			}
+			continue
+		}
+		out[name] = r
+	}
+	return out
+}
+
+func withTarget(r kenko.Result, name string) kenko.Result {
+	r.Target = name
+	return r
+}
+
+// ms converts a duration to the milliseconds stored in latency columns.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func fromMS(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// checkedAt is when the result was checked, or now for results without a
+// check time.
+func checkedAt(r kenko.Result) time.Time {
+	if r.CheckedAt.IsZero() {
+		return time.Now()
+	}
+	return r.CheckedAt
+}
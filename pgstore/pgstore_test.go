@@ -0,0 +1,219 @@
+package pgstore
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+func TestValidPrefix(t *testing.T) {
+	for prefix, want := range map[string]bool{
+		"kenko_":      true,
+		"":            true,
+		"prod2_":      true,
+		"2prod_":      false,
+		"Kenko_":      false,
+		"kenko; drop": false,
+	} {
+		if got := validPrefix(prefix); got != want {
+			t.Errorf("validPrefix(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New("postgres://localhost/kenko", WithTablePrefix("bad-prefix"))
+	if err == nil || !strings.Contains(err.Error(), "invalid table prefix") {
+		t.Errorf("err = %v, want invalid table prefix", err)
+	}
+	if _, err := New("postgres://localhost:notaport/kenko"); err == nil {
+		t.Error("bad dsn accepted")
+	}
+}
+
+func TestNew_DefaultTablePrefix(t *testing.T) {
+	s, err := New("postgres://localhost/kenko")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.table("results") != "kenko_results" {
+		t.Errorf("results table = %q, want kenko_results", s.table("results"))
+	}
+}
+
+func TestMigrations_Prefixed(t *testing.T) {
+	for i, m := range migrations {
+		sql := expand(m, "prod_")
+		if strings.Contains(sql, "{p}") {
+			t.Errorf("migration %d has an unexpanded prefix", i+1)
+		}
+		for _, stmt := range strings.Split(sql, ";") {
+			fields := strings.Fields(stmt)
+			if len(fields) >= 3 && fields[0] == "CREATE" && fields[1] == "TABLE" && !strings.HasPrefix(fields[2], "prod_") {
+				t.Errorf("migration %d creates unprefixed table %s", i+1, fields[2])
+			}
+		}
+	}
+}
+
+func TestDecodeResults_PartialFailure(t *testing.T) {
+	out := decodeResults(map[string][]byte{
+		"api": []byte(`{"target":"api","status":"healthy"}`),
+		"bad": []byte(`{not json`),
+	})
+
+	if out["api"].Status != kenko.StatusHealthy {
+		t.Errorf("api status = %q, want %q", out["api"].Status, kenko.StatusHealthy)
+	}
+	if bad := out["bad"]; bad.Status != kenko.StatusUnknown || bad.Target != "bad" || bad.Error == "" {
+		t.Errorf("bad result = %+v, want target and error set", bad)
+	}
+}
+
+// TestStore runs against the database in KENKO_TEST_POSTGRES_DSN, e.g.
+// postgres://postgres@localhost/kenko_test, and is skipped without it.
+func TestStore(t *testing.T) {
+	dsn := os.Getenv("KENKO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("KENKO_TEST_POSTGRES_DSN not set")
+	}
+	ctx := context.Background()
+	prefix := "kenko_test_" + strings.ReplaceAll(time.Now().Format("150405.000000"), ".", "_") + "_"
+
+	s, err := New(dsn, WithTablePrefix(prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	t.Cleanup(func() {
+		for _, table := range []string{"results", "latest", "transitions", "state", "events", "deliveries", "rollups", "migrations"} {
+			s.pool.Exec(ctx, `DROP TABLE IF EXISTS `+s.table(table))
+		}
+	})
+
+	// a second store must find the schema migrated
+	again, err := New(dsn, WithTablePrefix(prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := again.Migrate(ctx); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	again.Close()
+
+	at := time.Now().Truncate(time.Second)
+	for i, status := range []kenko.Status{kenko.StatusHealthy, kenko.StatusHealthy, kenko.StatusUnhealthy} {
+		r := kenko.Result{Target: "api", Status: status, CheckedAt: at.Add(time.Duration(i) * time.Second)}
+		if err := s.Set(ctx, "api", r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := s.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all["api"].Status != kenko.StatusUnhealthy {
+		t.Errorf("latest = %+v, want unhealthy", all["api"])
+	}
+
+	history, err := s.History(ctx, "api", kenko.HistoryQuery{From: at.Add(time.Second), Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Status != kenko.StatusUnhealthy {
+		t.Errorf("history = %+v, want the last two results, newest first", history)
+	}
+
+	if n, err := s.PruneHistory(ctx, "api", at.Add(time.Second)); err != nil || n != 1 {
+		t.Errorf("pruned %d, %v, want 1", n, err)
+	}
+
+	for i, to := range []kenko.Status{kenko.StatusUnhealthy, kenko.StatusHealthy} {
+		e := kenko.Event{Target: "api", To: to, At: at.Add(time.Duration(i) * 2 * time.Second), Duration: time.Minute}
+		if err := s.AddEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := s.Events(ctx, kenko.EventQuery{Target: "api", Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].To != kenko.StatusHealthy || events[0].Duration != time.Minute {
+		t.Errorf("events = %+v, want the recovery first", events)
+	}
+
+	d := kenko.Delivery{Notifier: "pager", Target: "api", Status: kenko.StatusUnhealthy, Outcome: kenko.NotificationFailed, At: at, Attempts: 3, Latency: 1500 * time.Millisecond, Error: "status 503"}
+	if err := s.AddDelivery(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, err := s.Deliveries(ctx, kenko.DeliveryQuery{Notifier: "pager", Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Outcome != kenko.NotificationFailed || deliveries[0].Latency != 1500*time.Millisecond || deliveries[0].Error != "status 503" {
+		t.Errorf("deliveries = %+v, want the failed page", deliveries)
+	}
+	if n, err := s.PruneDeliveries(ctx, at.Add(time.Second)); err != nil || n != 1 {
+		t.Errorf("pruned %d deliveries, %v, want 1", n, err)
+	}
+
+	// one event is older
+	if n, err := s.PruneTransitions(ctx, at.Add(time.Second)); err != nil || n != 1 {
+		t.Errorf("pruned %d events, %v, want 1", n, err)
+	}
+
+	var results int
+	s.pool.QueryRow(ctx, `SELECT count(*) FROM `+s.table("results")).Scan(&results)
+	if results != 2 {
+		t.Errorf("%d results, want 2", results)
+	}
+
+	if err := s.Delete(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := s.GetAll(ctx); len(all) != 0 {
+		t.Errorf("after delete = %v", all)
+	}
+
+	hour := at.Truncate(time.Hour)
+	rollups := []kenko.Rollup{
+		{Start: hour.Add(-time.Hour), Checks: 1},
+		{Start: hour, Checks: 2, Down: 1, MinLatency: 10 * time.Millisecond, MaxLatency: 30 * time.Millisecond, TotalLatency: 40 * time.Millisecond},
+	}
+	if err := s.SaveRollups(ctx, "api", kenko.HourlyRollups, rollups); err != nil {
+		t.Fatal(err)
+	}
+	rollups[1].Checks = 3
+	if err := s.SaveRollups(ctx, "api", kenko.HourlyRollups, rollups[1:]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Rollups(ctx, "api", kenko.HourlyRollups, hour.Add(-time.Hour), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Checks != 3 || got[1].MaxLatency != 30*time.Millisecond {
+		t.Errorf("rollups = %+v, want the second replaced", got)
+	}
+	if n, err := s.PruneRollups(ctx, kenko.HourlyRollups, hour); err != nil || n != 1 {
+		t.Errorf("pruned %d rollups, %v, want 1", n, err)
+	}
+
+	if data, err := s.LoadState(ctx, "silences"); err != nil || data != nil {
+		t.Errorf("unset state = %q, %v", data, err)
+	}
+	if err := s.SaveState(ctx, "silences", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.LoadState(ctx, "silences"); err != nil || string(data) != `[]` {
+		t.Errorf("state = %q, %v", data, err)
+	}
+}
@@ -1,6 +1,10 @@
 package prommetrics
 
 import (
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/aidantrabs/kenko"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -18,14 +22,43 @@ func WithNamespace(ns string) Option {
 	return func(r *Reporter) { r.namespace = ns }
 }
 
+// WithTLSMetrics enables the kenko_tls_info and kenko_tls_cert_expiry_timestamp_seconds
+// gauges for HTTPS targets.
+func WithTLSMetrics() Option {
+	return func(r *Reporter) { r.tlsMetrics = true }
+}
+
+// WithTargetLabels adds the given target label keys as Prometheus labels on
+// every per-target metric, e.g. WithTargetLabels("team", "env"). targets
+// without a key get an empty value. keys are sanitized into valid label names
+// and prefixed with "label_" when they would clash with a built-in label.
+func WithTargetLabels(keys ...string) Option {
+	return func(r *Reporter) { r.targetLabels = append(r.targetLabels, keys...) }
+}
+
 // Reporter implements MetricsReporter using Prometheus counters, gauges, and histograms.
 type Reporter struct {
 	registerer prometheus.Registerer
 	namespace  string
+	tlsMetrics bool
+
+	targetLabels []string
+	labelKeys    []string
 
 	checkDuration *prometheus.HistogramVec
+	phaseDuration *prometheus.HistogramVec
 	checkTotal    *prometheus.CounterVec
 	targetUp      *prometheus.GaugeVec
+	endpointUp    *prometheus.GaugeVec
+	contentChange *prometheus.CounterVec
+	bodyBytes     *prometheus.CounterVec
+	decodedBytes  *prometheus.CounterVec
+	tlsInfo       *prometheus.GaugeVec
+	certExpiry    *prometheus.GaugeVec
+	groupUp       *prometheus.GaugeVec
+	groupMembers  *prometheus.GaugeVec
+	pruned        *prometheus.CounterVec
+	notifications *prometheus.CounterVec
 }
 
 // New creates a Reporter and registers its metrics with Prometheus.
@@ -36,39 +69,255 @@ func New(opts ...Option) *Reporter {
 	for _, opt := range opts {
 		opt(r)
 	}
+	slices.Sort(r.targetLabels)
+	r.targetLabels = slices.Compact(r.targetLabels)
+	r.labelKeys = labelKeys(r.targetLabels)
 
 	r.checkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: r.namespace,
 		Name:      "kenko_check_duration_seconds",
 		Help:      "duration of health checks",
 		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
-	}, []string{"target"})
+	}, r.labelNames())
+
+	r.phaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_check_phase_duration_seconds",
+		Help:      "duration of each phase of a health check (dns, connect, tls, ttfb, transfer)",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, r.labelNames("phase"))
 
 	r.checkTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: r.namespace,
 		Name:      "kenko_check_total",
 		Help:      "total number of health checks",
-	}, []string{"target", "status"})
+	}, r.labelNames("status"))
 
 	r.targetUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: r.namespace,
 		Name:      "kenko_target_up",
 		Help:      "whether a target is healthy (1) or not (0)",
-	}, []string{"target"})
+	}, r.labelNames())
+
+	r.endpointUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_endpoint_up",
+		Help:      "whether one endpoint (address family, resolved ip, or region) of a target is healthy (1) or not (0)",
+	}, r.labelNames("endpoint"))
+
+	r.groupUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_group_up",
+		Help:      "whether a target group is healthy (1), degraded (0.5), or unhealthy (0)",
+	}, []string{"group", "region"})
+
+	r.groupMembers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_group_members",
+		Help:      "number of targets in a group by status, with unchecked members as unknown",
+	}, []string{"group", "region", "status"})
+
+	r.pruned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_pruned_total",
+		Help:      "number of stored results and transitions removed by retention",
+	}, []string{"kind"})
 
-	r.registerer.MustRegister(r.checkDuration, r.checkTotal, r.targetUp)
+	r.notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_notifications_total",
+		Help:      "number of status change notifications by notifier and outcome: delivered, failed, or dropped",
+	}, []string{"notifier", "outcome"})
+
+	r.contentChange = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_content_changed_total",
+		Help:      "number of checks whose response body hash changed",
+	}, r.labelNames())
+
+	r.bodyBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_check_body_bytes_total",
+		Help:      "response body bytes read by health checks",
+	}, r.labelNames())
+
+	r.decodedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      "kenko_check_decoded_body_bytes_total",
+		Help:      "decompressed response body bytes of checks with an expected content encoding",
+	}, r.labelNames("encoding"))
+
+	r.registerer.MustRegister(r.checkDuration, r.phaseDuration, r.checkTotal, r.targetUp, r.endpointUp, r.contentChange, r.bodyBytes, r.decodedBytes, r.groupUp, r.groupMembers, r.pruned, r.notifications)
+
+	if r.tlsMetrics {
+		r.tlsInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: r.namespace,
+			Name:      "kenko_tls_info",
+			Help:      "negotiated tls version and cipher suite of a target (always 1)",
+		}, r.labelNames("version", "cipher_suite"))
+
+		r.certExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: r.namespace,
+			Name:      "kenko_tls_cert_expiry_timestamp_seconds",
+			Help:      "unix time at which the target's leaf certificate expires",
+		}, r.labelNames())
+
+		r.registerer.MustRegister(r.tlsInfo, r.certExpiry)
+	}
 
 	return r
 }
 
 // ReportCheck records the result of a health check as Prometheus metrics.
 func (r *Reporter) ReportCheck(target string, status kenko.Status, latencySeconds float64) {
-	r.checkDuration.WithLabelValues(target).Observe(latencySeconds)
-	r.checkTotal.WithLabelValues(target, string(status)).Inc()
+	r.ReportResult(kenko.Result{
+		Target:  target,
+		Status:  status,
+		Latency: time.Duration(latencySeconds * float64(time.Second)),
+	})
+}
+
+// ReportResult records a full check result, including metrics derived from
+// optional Result fields such as content changes.
+func (r *Reporter) ReportResult(result kenko.Result) {
+	lv := r.labelValues(result)
 
-	if status == kenko.StatusHealthy {
-		r.targetUp.WithLabelValues(target).Set(1)
+	r.checkDuration.WithLabelValues(lv...).Observe(result.Latency.Seconds())
+	r.checkTotal.WithLabelValues(append(lv, string(result.Status))...).Inc()
+
+	if p := result.Phases; p != nil {
+		for phase, d := range map[string]time.Duration{
+			"dns":      p.DNS,
+			"connect":  p.Connect,
+			"tls":      p.TLS,
+			"ttfb":     p.TTFB,
+			"transfer": p.Transfer,
+		} {
+			// a reused connection skips dns, connect and tls entirely
+			if d > 0 || phase == "ttfb" || phase == "transfer" {
+				r.phaseDuration.WithLabelValues(append(lv, phase)...).Observe(d.Seconds())
+			}
+		}
+	}
+
+	// drop the series of a previous group or severity after the target moved
+	r.targetUp.DeletePartialMatch(prometheus.Labels{"target": result.Target, "region": result.Region})
+	if result.Status == kenko.StatusHealthy {
+		r.targetUp.WithLabelValues(lv...).Set(1)
 	} else {
-		r.targetUp.WithLabelValues(target).Set(0)
+		r.targetUp.WithLabelValues(lv...).Set(0)
+	}
+
+	// drop endpoints that went away, e.g. an address no longer resolved
+	r.endpointUp.DeletePartialMatch(prometheus.Labels{"target": result.Target, "region": result.Region})
+	for _, ep := range result.Endpoints {
+		up := 0.0
+		if ep.Status == kenko.StatusHealthy {
+			up = 1
+		}
+		r.endpointUp.WithLabelValues(append(lv, ep.Name)...).Set(up)
+	}
+
+	if result.Changed {
+		r.contentChange.WithLabelValues(lv...).Inc()
+	}
+
+	r.bodyBytes.WithLabelValues(lv...).Add(float64(result.BodyBytes))
+	if result.DecodedBytes > 0 {
+		r.decodedBytes.WithLabelValues(append(lv, result.ContentEncoding)...).Add(float64(result.DecodedBytes))
+	}
+
+	if r.tlsMetrics && result.TLS != nil {
+		// drop the previous series so a renegotiated version doesn't linger
+		r.tlsInfo.DeletePartialMatch(prometheus.Labels{"target": result.Target, "region": result.Region})
+		r.tlsInfo.WithLabelValues(append(lv, result.TLS.Version, result.TLS.CipherSuite)...).Set(1)
+		if len(result.TLS.Chain) > 0 {
+			r.certExpiry.WithLabelValues(lv...).Set(float64(result.TLS.Chain[0].NotAfter.Unix()))
+		}
+	}
+}
+
+// ForgetTarget deletes every series of a target that is no longer checked,
+// so a paused or removed target does not keep reporting its last status.
+func (r *Reporter) ForgetTarget(target string) {
+	match := prometheus.Labels{"target": target}
+	for _, vec := range []interface {
+		DeletePartialMatch(prometheus.Labels) int
+	}{r.checkDuration, r.phaseDuration, r.checkTotal, r.targetUp, r.endpointUp, r.contentChange, r.bodyBytes, r.decodedBytes} {
+		vec.DeletePartialMatch(match)
+	}
+	if r.tlsMetrics {
+		r.tlsInfo.DeletePartialMatch(match)
+		r.certExpiry.DeletePartialMatch(match)
+	}
+}
+
+// ReportGroup records the rolled-up status of a target group.
+func (r *Reporter) ReportGroup(region string, g kenko.GroupStatus) {
+	up := 0.0
+	switch g.Status {
+	case kenko.StatusHealthy:
+		up = 1
+	case kenko.StatusDegraded:
+		up = 0.5
+	}
+	r.groupUp.WithLabelValues(g.Name, region).Set(up)
+
+	for status, n := range map[kenko.Status]int{
+		kenko.StatusHealthy:   g.Healthy,
+		kenko.StatusUnhealthy: g.Unhealthy,
+		kenko.StatusDegraded:  g.Degraded,
+		kenko.StatusUnknown:   g.Members - g.Healthy - g.Unhealthy - g.Degraded,
+	} {
+		r.groupMembers.WithLabelValues(g.Name, region, string(status)).Set(float64(n))
+	}
+}
+
+// ReportPruned records how many results or transitions a retention run
+// removed.
+func (r *Reporter) ReportPruned(kind string, n int) {
+	r.pruned.WithLabelValues(kind).Add(float64(n))
+}
+
+// ReportNotification counts the outcome of a status change notification.
+func (r *Reporter) ReportNotification(notifier, outcome string) {
+	r.notifications.WithLabelValues(notifier, outcome).Inc()
+}
+
+// builtinLabels are the label names of kenko's own metrics, which target
+// labels must not reuse.
+var builtinLabels = []string{"target", "region", "group", "severity", "phase", "status", "endpoint", "encoding", "version", "cipher_suite"}
+
+// labelKeys returns the Prometheus label name for each target label key.
+func labelKeys(keys []string) []string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		name := strings.Map(func(c rune) rune {
+			if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+				return c
+			}
+			return '_'
+		}, k)
+		if name == "" || name[0] >= '0' && name[0] <= '9' || strings.HasPrefix(name, "__") || slices.Contains(builtinLabels, name) {
+			name = "label_" + name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// labelNames returns the per-target label dimensions shared by every metric,
+// including any target labels, followed by any metric-specific extras.
+func (r *Reporter) labelNames(extra ...string) []string {
+	names := append([]string{"target", "region", "group", "severity"}, r.labelKeys...)
+	return append(names, extra...)
+}
+
+// labelValues returns the values for labelNames in the same order.
+func (r *Reporter) labelValues(result kenko.Result) []string {
+	values := []string{result.Target, result.Region, result.Group, string(result.Severity)}
+	for _, k := range r.targetLabels {
+		values = append(values, result.Labels[k])
 	}
+	return values
 }
@@ -2,6 +2,7 @@ package prommetrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/aidantrabs/kenko"
 	"github.com/prometheus/client_golang/prometheus"
@@ -88,3 +89,367 @@ func TestNew_WithNamespace(t *testing.T) {
 		t.Errorf("myapp_kenko_target_up not found, got: %v", names)
 	}
 }
+
+func TestReportResult_ContentChanged(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusHealthy, Changed: true})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() == "kenko_content_changed_total" {
+			if v := f.GetMetric()[0].GetCounter().GetValue(); v != 1 {
+				t.Errorf("content_changed_total = %v, want 1", v)
+			}
+			return
+		}
+	}
+	t.Error("kenko_content_changed_total metric not found")
+}
+
+func TestReportResult_TLSMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	r := New(WithRegistry(reg), WithTLSMetrics())
+
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusHealthy, TLS: &kenko.TLSInfo{Version: "TLS 1.2", CipherSuite: "A"}})
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusHealthy, TLS: &kenko.TLSInfo{Version: "TLS 1.3", CipherSuite: "B"}})
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "kenko_tls_info" {
+			continue
+		}
+		if n := len(f.GetMetric()); n != 1 {
+			t.Fatalf("tls_info series = %d, want 1", n)
+		}
+		for _, l := range f.GetMetric()[0].GetLabel() {
+			if l.GetName() == "version" && l.GetValue() != "TLS 1.3" {
+				t.Errorf("version = %q, want %q", l.GetValue(), "TLS 1.3")
+			}
+		}
+		return
+	}
+	t.Error("kenko_tls_info metric not found")
+}
+
+func TestReportResult_RegionLabel(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{Target: "api", Region: "eu-west", Status: kenko.StatusHealthy})
+	r.ReportResult(kenko.Result{Target: "api", Region: "us-east", Status: kenko.StatusUnhealthy})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "kenko_target_up" {
+			continue
+		}
+		byRegion := make(map[string]float64)
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "region" {
+					byRegion[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+		if byRegion["eu-west"] != 1 || byRegion["us-east"] != 0 || len(byRegion) != 2 {
+			t.Errorf("target_up by region = %v, want eu-west=1 us-east=0", byRegion)
+		}
+		return
+	}
+	t.Error("kenko_target_up metric not found")
+}
+
+func TestReportResult_PhaseDurations(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{
+		Target: "api",
+		Status: kenko.StatusHealthy,
+		Phases: &kenko.Phases{DNS: 10 * time.Millisecond, TTFB: 50 * time.Millisecond},
+	})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "kenko_check_phase_duration_seconds" {
+			continue
+		}
+		phases := make(map[string]bool)
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "phase" {
+					phases[l.GetValue()] = true
+				}
+			}
+		}
+		// connect and tls were zero, as for a reused connection
+		if !phases["dns"] || !phases["ttfb"] || !phases["transfer"] || phases["connect"] || phases["tls"] {
+			t.Errorf("observed phases = %v, want dns, ttfb and transfer", phases)
+		}
+		return
+	}
+	t.Error("kenko_check_phase_duration_seconds metric not found")
+}
+
+func TestReportResult_DecodedBytes(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusHealthy, BodyBytes: 100, ContentEncoding: "gzip", DecodedBytes: 400})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() == "kenko_check_decoded_body_bytes_total" {
+			if v := f.GetMetric()[0].GetCounter().GetValue(); v != 400 {
+				t.Errorf("decoded_body_bytes_total = %v, want 400", v)
+			}
+			return
+		}
+	}
+	t.Error("kenko_check_decoded_body_bytes_total metric not found")
+}
+
+func TestReportResult_EndpointUp(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusDegraded, Endpoints: []kenko.Endpoint{
+		{Name: "ipv4", Status: kenko.StatusHealthy},
+		{Name: "ipv6", Status: kenko.StatusUnhealthy},
+	}})
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusHealthy, Endpoints: []kenko.Endpoint{
+		{Name: "ipv4", Status: kenko.StatusHealthy},
+	}})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "kenko_endpoint_up" {
+			continue
+		}
+		byEndpoint := make(map[string]float64)
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "endpoint" {
+					byEndpoint[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+		if len(byEndpoint) != 1 || byEndpoint["ipv4"] != 1 {
+			t.Errorf("endpoint_up = %v, want only ipv4=1 after ipv6 went away", byEndpoint)
+		}
+		return
+	}
+	t.Error("kenko_endpoint_up metric not found")
+}
+
+func TestReportResult_GroupLabel(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{Target: "api", Group: "checkout", Status: kenko.StatusHealthy})
+	r.ReportResult(kenko.Result{Target: "api", Group: "payments", Status: kenko.StatusHealthy})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "kenko_target_up" {
+			continue
+		}
+		var groups []string
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "group" {
+					groups = append(groups, l.GetValue())
+				}
+			}
+		}
+		if len(groups) != 1 || groups[0] != "payments" {
+			t.Errorf("target_up groups = %v, want only payments after the move", groups)
+		}
+		return
+	}
+	t.Error("kenko_target_up metric not found")
+}
+
+func TestReportGroup(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportGroup("eu", kenko.GroupStatus{Name: "payments", Status: kenko.StatusDegraded, Quorum: 2, Members: 4, Healthy: 2, Unhealthy: 1})
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			key := f.GetName()
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "status" {
+					key += "/" + l.GetValue()
+				}
+			}
+			values[key] = m.GetGauge().GetValue()
+		}
+	}
+
+	want := map[string]float64{
+		"kenko_group_up":                0.5,
+		"kenko_group_members/healthy":   2,
+		"kenko_group_members/unhealthy": 1,
+		"kenko_group_members/unknown":   1,
+		"kenko_group_members/degraded":  0,
+	}
+	for k, v := range want {
+		if got, ok := values[k]; !ok || got != v {
+			t.Errorf("%s = %v, want %v", k, got, v)
+		}
+	}
+}
+
+func TestReportResult_TargetLabels(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r := New(WithRegistry(reg), WithTargetLabels("team", "env", "team", "status", "cost-center"))
+	r.ReportResult(kenko.Result{
+		Target:   "api",
+		Status:   kenko.StatusHealthy,
+		Severity: kenko.SeverityWarning,
+		Labels:   map[string]string{"team": "payments", "status": "ga", "cost-center": "42", "tier": "1"},
+	})
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "kenko_target_up" {
+			continue
+		}
+		labels := make(map[string]string)
+		for _, l := range f.GetMetric()[0].GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		want := map[string]string{
+			"target":       "api",
+			"region":       "",
+			"group":        "",
+			"severity":     "warning",
+			"team":         "payments",
+			"env":          "",
+			"label_status": "ga",
+			"cost_center":  "42",
+		}
+		for k, v := range want {
+			if got, ok := labels[k]; !ok || got != v {
+				t.Errorf("label %s = %q (set %v), want %q", k, got, ok, v)
+			}
+		}
+		if _, ok := labels["tier"]; ok {
+			t.Error("tier was exported without being opted in")
+		}
+		return
+	}
+	t.Error("kenko_target_up metric not found")
+}
+
+func TestForgetTarget(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportResult(kenko.Result{Target: "api", Status: kenko.StatusHealthy, Endpoints: []kenko.Endpoint{{Name: "ipv4", Status: kenko.StatusHealthy}}})
+	r.ReportResult(kenko.Result{Target: "web", Status: kenko.StatusHealthy})
+	r.ForgetTarget("api")
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "target" && l.GetValue() == "api" {
+					t.Errorf("%s still has a series for the forgotten target", f.GetName())
+				}
+			}
+		}
+	}
+}
+
+func TestReportPruned(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportPruned("results", 3)
+	r.ReportPruned("results", 2)
+	r.ReportPruned("transitions", 0)
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "kenko_pruned_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			values[m.GetLabel()[0].GetValue()] = m.GetCounter().GetValue()
+		}
+	}
+	if values["results"] != 5 {
+		t.Errorf("pruned results = %v, want 5", values["results"])
+	}
+	if v, ok := values["transitions"]; !ok || v != 0 {
+		t.Errorf("pruned transitions = %v, %v, want a zero series", v, ok)
+	}
+}
+
+func TestReportNotification(t *testing.T) {
+	r := newTestReporter(t)
+	r.ReportNotification("ops", kenko.NotificationDelivered)
+	r.ReportNotification("ops", kenko.NotificationDelivered)
+	r.ReportNotification("ops", kenko.NotificationFailed)
+
+	reg := r.registerer.(*prometheus.Registry)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "kenko_notifications_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			values[m.GetLabel()[0].GetValue()+"/"+m.GetLabel()[1].GetValue()] = m.GetCounter().GetValue()
+		}
+	}
+	if values["ops/delivered"] != 2 || values["ops/failed"] != 1 {
+		t.Errorf("notifications = %v, want 2 delivered and 1 failed", values)
+	}
+}
@@ -0,0 +1,238 @@
+// Package pushover is a kenko Notifier that sends status changes through
+// Pushover to a user's or group's devices.
+package pushover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultAPIURL  = "https://api.pushover.net/1/messages.json"
+	defaultTimeout = 10 * time.Second
+
+	// the message api rejects longer titles and messages
+	maxTitle   = 250
+	maxMessage = 1024
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithDevice sends to the named devices of the user only, comma separated.
+func WithDevice(device string) Option {
+	return func(n *Notifier) { n.device = device }
+}
+
+// WithStatusPageURL links every message to the status page at url.
+func WithStatusPageURL(url string) Option {
+	return func(n *Notifier) { n.statusPage = url }
+}
+
+// WithAPIURL sets the url messages are posted to (default
+// "https://api.pushover.net/1/messages.json").
+func WithAPIURL(url string) Option {
+	return func(n *Notifier) { n.apiURL = url }
+}
+
+// WithHTTPClient sets the client messages are sent with (default a client
+// with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// Notifier sends notifications through Pushover.
+type Notifier struct {
+	token      string
+	user       string
+	device     string
+	statusPage string
+	apiURL     string
+	client     *http.Client
+}
+
+// New creates a Notifier sending as the application whose api token is
+// given to the user or group key.
+func New(token, user string, opts ...Option) *Notifier {
+	n := &Notifier{
+		token:  token,
+		user:   user,
+		apiURL: defaultAPIURL,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// priorities by status: high bypasses quiet hours, low sends no sound.
+// emergency priority is left out, it repeats until acknowledged.
+var priorities = map[kenko.Status]int{
+	kenko.StatusHealthy:   -1,
+	kenko.StatusDegraded:  0,
+	kenko.StatusUnhealthy: 1,
+}
+
+// priority treats a latency alert as degraded and its recovery as healthy.
+func priority(n kenko.Notification) int {
+	switch {
+	case n.Slow == nil:
+		return priorities[n.Status]
+	case n.Slow.Resolved:
+		return priorities[kenko.StatusHealthy]
+	default:
+		return priorities[kenko.StatusDegraded]
+	}
+}
+
+// form builds the message for n, without the token.
+func (p *Notifier) form(n kenko.Notification) url.Values {
+	v := url.Values{}
+	v.Set("user", p.user)
+	v.Set("title", truncate(title(n), maxTitle))
+	v.Set("message", truncate(message(n), maxMessage))
+	v.Set("priority", strconv.Itoa(priority(n)))
+	if !n.At.IsZero() {
+		v.Set("timestamp", strconv.FormatInt(n.At.Unix(), 10))
+	}
+	if p.device != "" {
+		v.Set("device", p.device)
+	}
+	if p.statusPage != "" {
+		v.Set("url", p.statusPage)
+		v.Set("url_title", "Status page")
+	}
+	return v
+}
+
+// Notify sends the notification's message.
+func (p *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	return p.send(ctx, p.form(n))
+}
+
+// NotifyDigest sends one message for several notifications, with the title
+// of each as a line, at the priority of the most urgent of them.
+func (p *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	return p.send(ctx, p.digest(ns))
+}
+
+// digest builds the message for several notifications.
+func (p *Notifier) digest(ns []kenko.Notification) url.Values {
+	v := p.form(ns[0])
+	lines := make([]string, len(ns))
+	urgent := priority(ns[0])
+	for i, n := range ns {
+		lines[i] = title(n)
+		if e := n.Result.Error; e != "" && n.Status != kenko.StatusHealthy && n.Slow == nil {
+			lines[i] += ": " + e
+		}
+		urgent = max(urgent, priority(n))
+	}
+	v.Set("title", fmt.Sprintf("kenko: %d notifications", len(ns)))
+	v.Set("message", truncate(strings.Join(lines, "\n"), maxMessage))
+	v.Set("priority", strconv.Itoa(urgent))
+	return v
+}
+
+func (p *Notifier) send(ctx context.Context, form url.Values) error {
+	form.Set("token", p.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("pushover: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushover: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	// a 4xx response lists what was wrong, e.g. "user identifier is invalid"
+	var result struct {
+		Errors []string `json:"errors"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&result)
+	code := resp.StatusCode
+	err = fmt.Errorf("pushover: status %d: %s", code, strings.Join(result.Errors, "; "))
+	if !kenko.RetryableStatus(code) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the title and message Notify would send for n.
+func (p *Notifier) Preview(n kenko.Notification) (string, error) {
+	form := p.form(n)
+	return form.Get("title") + "\n" + form.Get("message"), nil
+}
+
+// title names the target and its new status.
+func title(n kenko.Notification) string {
+	var text string
+	switch {
+	case n.Slow != nil && n.Slow.Resolved:
+		text = fmt.Sprintf("%s is fast again after %s", n.Target, n.At.Sub(n.Slow.Since).Round(time.Second))
+	case n.Slow != nil:
+		text = fmt.Sprintf("%s is slow: over %s for %d checks", n.Target, n.Slow.Threshold, n.Slow.Checks)
+	case n.Outage != nil:
+		text = fmt.Sprintf("%s recovered after %s", n.Target, n.Outage.Duration.Round(time.Second))
+	case n.Repeat > 0:
+		text = fmt.Sprintf("%s is still %s", n.Target, n.Status)
+	default:
+		text = fmt.Sprintf("%s is %s (was %s)", n.Target, n.Status, n.Previous)
+	}
+	if n.Test {
+		text = "[test] " + text
+	}
+	return text
+}
+
+// message lists the details of the result, or of the outage a recovery
+// ended.
+func message(n kenko.Notification) string {
+	var lines []string
+	if o := n.Outage; o != nil {
+		if o.FirstError != "" {
+			lines = append(lines, "First error: "+o.FirstError)
+		}
+		if o.LastError != "" && o.LastError != o.FirstError {
+			lines = append(lines, "Last error: "+o.LastError)
+		}
+	}
+	r := n.Result
+	if r.Error != "" {
+		lines = append(lines, "Error: "+r.Error)
+	}
+	if r.StatusCode != 0 {
+		lines = append(lines, fmt.Sprintf("Status code: %d", r.StatusCode))
+	}
+	lines = append(lines, "Latency: "+r.Latency.Round(time.Millisecond).String())
+	if r.Region != "" {
+		lines = append(lines, "Region: "+r.Region)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate cuts s to at most max characters.
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package pushover
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification { return notifytest.Notification(kenko.StatusUnhealthy) }
+
+func TestNotify(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.PostForm
+		io.WriteString(w, `{"status":1,"request":"x"}`)
+	}))
+	defer srv.Close()
+
+	n := New("app-token", "user-key", WithAPIURL(srv.URL), WithDevice("phone"), WithStatusPageURL("https://status.example.com"))
+	if err := n.Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	for field, want := range map[string]string{
+		"token":     "app-token",
+		"user":      "user-key",
+		"device":    "phone",
+		"title":     "api is unhealthy (was healthy)",
+		"message":   "Error: status 503\nStatus code: 503\nLatency: 250ms",
+		"priority":  "1",
+		"timestamp": "1773403200",
+		"url":       "https://status.example.com",
+	} {
+		if got.Get(field) != want {
+			t.Errorf("%s = %q, want %q", field, got.Get(field), want)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Result = kenko.Result{Target: "api", Status: kenko.StatusHealthy, StatusCode: 200, Latency: 20 * time.Millisecond}
+	n.Outage = &kenko.Outage{Duration: 90 * time.Second, FirstError: strings.Repeat("x", 2000)}
+	p := New("app-token", "user-key")
+	if got := p.form(n).Get("priority"); got != "-1" {
+		t.Errorf("priority = %s, want -1", got)
+	}
+	text, err := p.Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, "api recovered after 1m30s\nFirst error: xxx") || !strings.HasSuffix(text, "...") || strings.Contains(text, "app-token") {
+		t.Errorf("preview = %q", text)
+	}
+}
+
+func TestForm_Slow(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusHealthy, kenko.StatusHealthy
+	n.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3, Since: n.At.Add(-time.Minute)}
+	p := New("app-token", "user-key")
+	if form := p.form(n); form.Get("title") != "api is slow: over 1s for 3 checks" || form.Get("priority") != "0" {
+		t.Errorf("form = %v", form)
+	}
+	n.Slow.Resolved = true
+	if form := p.form(n); form.Get("title") != "api is fast again after 1m0s" || form.Get("priority") != "-1" {
+		t.Errorf("form = %v", form)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	recovery := notification()
+	recovery.Target, recovery.Previous, recovery.Status = "web", kenko.StatusUnhealthy, kenko.StatusHealthy
+	recovery.Outage = &kenko.Outage{Duration: time.Minute}
+	form := New("app-token", "user-key").digest([]kenko.Notification{recovery, notification()})
+	if form.Get("title") != "kenko: 2 notifications" || form.Get("priority") != "1" ||
+		form.Get("message") != "web recovered after 1m0s\napi is unhealthy (was healthy): status 503" {
+		t.Errorf("form = %v", form)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, `{"user":"invalid","errors":["user identifier is invalid"],"status":0}`, "user identifier is invalid", func(url string) error {
+		return New("app-token", "user-key", WithAPIURL(url)).Notify(context.Background(), notification())
+	})
+}
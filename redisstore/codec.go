@@ -0,0 +1,114 @@
+package redisstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes results for storage. JSON is the default. other codecs trade
+// readability for size, which matters for fleets with thousands of targets.
+type Codec interface {
+	// Name identifies the codec in stored entries and config, e.g. "msgpack".
+	Name() string
+	Marshal(r kenko.Result) ([]byte, error)
+	Unmarshal(data []byte, r *kenko.Result) error
+}
+
+// Built-in codecs.
+var (
+	// JSON stores results as plain JSON objects, readable with redis-cli.
+	JSON Codec = jsonCodec{}
+	// MsgPack stores results as MessagePack maps keyed by the JSON field
+	// names, typically about half the size of JSON.
+	MsgPack Codec = msgpackCodec{}
+)
+
+// Codecs returns the built-in codecs by name.
+func Codecs() map[string]Codec {
+	return map[string]Codec{JSON.Name(): JSON, MsgPack.Name(): MsgPack}
+}
+
+// entries written by a codec other than JSON start with codecMagic and the
+// length-prefixed codec name, so a store can read entries written before its
+// codec was changed. 0xc1 is never used by MessagePack and cannot start JSON.
+const codecMagic = 0xc1
+
+// encode marshals r with c, framing it unless c is JSON.
+func encode(c Codec, r kenko.Result) ([]byte, error) {
+	data, err := c.Marshal(r)
+	if err != nil || c.Name() == JSON.Name() {
+		return data, err
+	}
+
+	name := c.Name()
+	out := make([]byte, 0, 2+len(name)+len(data))
+	out = append(out, codecMagic, byte(len(name)))
+	out = append(out, name...)
+	return append(out, data...), nil
+}
+
+// decode unmarshals an entry written by any of codecs. unframed entries are
+// JSON.
+func decode(codecs map[string]Codec, data []byte, r *kenko.Result) error {
+	if len(data) == 0 || data[0] != codecMagic {
+		return JSON.Unmarshal(data, r)
+	}
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return errors.New("truncated codec header")
+	}
+
+	name := string(data[2 : 2+data[1]])
+	c, ok := codecs[name]
+	if !ok {
+		return fmt.Errorf("unknown codec %q", name)
+	}
+	return c.Unmarshal(data[2+data[1]:], r)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) Marshal(r kenko.Result) ([]byte, error) { return json.Marshal(r) }
+
+func (jsonCodec) Unmarshal(data []byte, r *kenko.Result) error { return json.Unmarshal(data, r) }
+
+// msgpackVersion is the schema version written ahead of each MessagePack
+// payload. bump it, and keep decoding the old one, when a change to Result
+// would decode wrongly from existing entries rather than just leave new
+// fields zero.
+const msgpackVersion = 1
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(r kenko.Result) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(msgpackVersion)
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+	enc.UseCompactInts(true)
+	if err := enc.Encode(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, r *kenko.Result) error {
+	if len(data) == 0 {
+		return errors.New("empty msgpack entry")
+	}
+	if v := data[0]; v != msgpackVersion {
+		return fmt.Errorf("unsupported msgpack schema version %d", v)
+	}
+	dec := msgpack.NewDecoder(bytes.NewReader(data[1:]))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(r)
+}
@@ -0,0 +1,110 @@
+package redisstore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+func sampleResult() kenko.Result {
+	return kenko.Result{
+		Target:     "api",
+		URL:        "https://api.example.com/health",
+		Status:     kenko.StatusDegraded,
+		StatusCode: 200,
+		Latency:    42 * time.Millisecond,
+		Error:      "1 of 2 endpoints failing: ipv6: request failed",
+		CheckedAt:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Region:     "us-east",
+		TLS: &kenko.TLSInfo{
+			Version:     "TLS 1.3",
+			CipherSuite: "TLS_AES_128_GCM_SHA256",
+			Chain:       []kenko.CertInfo{{Subject: "CN=api", Issuer: "CN=ca", NotAfter: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+		Phases:   &kenko.Phases{DNS: time.Millisecond, Connect: 2 * time.Millisecond, TTFB: 30 * time.Millisecond},
+		RemoteIP: "203.0.113.7",
+		IPInfo:   &kenko.IPInfo{ASN: 64500, Org: "Example"},
+		Endpoints: []kenko.Endpoint{
+			{Name: "ipv4", Status: kenko.StatusHealthy, StatusCode: 200, Latency: 40 * time.Millisecond},
+			{Name: "ipv6", Status: kenko.StatusUnhealthy, Error: "request failed"},
+		},
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	want := sampleResult()
+	for name, c := range Codecs() {
+		data, err := encode(c, want)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", name, err)
+		}
+		var got kenko.Result
+		if err := decode(Codecs(), data, &got); err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		// msgpack keeps the instant but not the zone.
+		if !got.CheckedAt.Equal(want.CheckedAt) || !got.TLS.Chain[0].NotAfter.Equal(want.TLS.Chain[0].NotAfter) {
+			t.Errorf("%s: times = %v, %v", name, got.CheckedAt, got.TLS.Chain[0].NotAfter)
+		}
+		got.CheckedAt, got.TLS.Chain[0].NotAfter = want.CheckedAt, want.TLS.Chain[0].NotAfter
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestMsgPack_Smaller(t *testing.T) {
+	r := sampleResult()
+	js, _ := encode(JSON, r)
+	mp, _ := encode(MsgPack, r)
+	if len(mp) >= len(js) {
+		t.Errorf("msgpack %d bytes, json %d bytes", len(mp), len(js))
+	}
+}
+
+func TestDecodeResults_MixedCodecs(t *testing.T) {
+	mp, _ := encode(MsgPack, kenko.Result{Target: "new", Status: kenko.StatusHealthy})
+	out := decodeResults(Codecs(), map[string]string{
+		"old": `{"target":"old","status":"unhealthy"}`,
+		"new": string(mp),
+	})
+
+	if out["old"].Status != kenko.StatusUnhealthy || out["new"].Status != kenko.StatusHealthy {
+		t.Errorf("results = %+v", out)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	mp, _ := encode(MsgPack, kenko.Result{Target: "api"})
+	future := append([]byte(nil), mp...)
+	future[2+len("msgpack")] = msgpackVersion + 1
+
+	tests := map[string]struct {
+		data    []byte
+		wantErr string
+	}{
+		"unknown codec": {[]byte{codecMagic, 3, 'p', 'b', '2', 0}, `unknown codec "pb2"`},
+		"truncated":     {[]byte{codecMagic, 9, 'm'}, "truncated"},
+		"version":       {future, "schema version 2"},
+	}
+
+	for name, tt := range tests {
+		var r kenko.Result
+		if err := decode(Codecs(), tt.data, &r); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNew_WithCodec(t *testing.T) {
+	s := New("localhost:6379", WithCodec(MsgPack))
+	if s.codec != MsgPack {
+		t.Errorf("codec = %v, want MsgPack", s.codec)
+	}
+	if s.codecs["json"] == nil {
+		t.Error("json codec not readable")
+	}
+}
@@ -0,0 +1,87 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultDeliveriesKey = "kenko:deliveries"
+
+	// maxDeliveries is roughly how many notification deliveries the stream
+	// keeps across notifiers.
+	maxDeliveries = 10000
+)
+
+// AddDelivery appends a notification delivery to the deliveries stream, which
+// keeps about the latest 10000.
+func (s *RedisStore) AddDelivery(ctx context.Context, d kenko.Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("redisstore: marshal delivery: %w", err)
+	}
+	err = s.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.deliveriesKey,
+		MaxLen: maxDeliveries,
+		Approx: true,
+		Values: map[string]any{"delivery": data},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("redisstore: add delivery: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the deliveries matching q, newest first. like Events,
+// the time bounds apply to when deliveries were stored.
+func (s *RedisStore) Deliveries(ctx context.Context, q kenko.DeliveryQuery) ([]kenko.Delivery, error) {
+	start, stop := "+", "-"
+	if !q.To.IsZero() {
+		start = strconv.FormatInt(q.To.UnixMilli(), 10)
+	}
+	if !q.From.IsZero() {
+		stop = strconv.FormatInt(q.From.UnixMilli(), 10)
+	}
+
+	var out []kenko.Delivery
+	for {
+		msgs, err := s.rdb.XRevRangeN(ctx, s.deliveriesKey, start, stop, eventsPage).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redisstore: deliveries: %w", err)
+		}
+		for _, d := range decodeDeliveries(msgs) {
+			if q.Target != "" && d.Target != q.Target || q.Notifier != "" && d.Notifier != q.Notifier {
+				continue
+			}
+			out = append(out, d)
+			if q.Limit > 0 && len(out) == q.Limit {
+				return out, nil
+			}
+		}
+		if len(msgs) < eventsPage {
+			return out, nil
+		}
+		// continue below the oldest entry read
+		start = "(" + msgs[len(msgs)-1].ID
+	}
+}
+
+// decodeDeliveries unmarshals stream entries into deliveries, skipping any
+// that fail to decode.
+func decodeDeliveries(msgs []redis.XMessage) []kenko.Delivery {
+	out := make([]kenko.Delivery, 0, len(msgs))
+	for _, m := range msgs {
+		data, _ := m.Values["delivery"].(string)
+		var d kenko.Delivery
+		if err := json.Unmarshal([]byte(data), &d); err != nil {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestDeliveries_FiltersByNotifier(t *testing.T) {
+	fake := &streamFake{}
+	for i := eventsPage + 10; i > 0; i-- {
+		notifier := "chat"
+		if i%50 == 0 {
+			notifier = "pager"
+		}
+		fake.msgs = append(fake.msgs, redis.XMessage{
+			ID:     fmt.Sprintf("%013d-0", 1700000000000+i),
+			Values: map[string]any{"delivery": fmt.Sprintf(`{"notifier":%q,"target":"api","outcome":"delivered","attempts":%d}`, notifier, i)},
+		})
+	}
+	s := New("localhost:6379")
+	s.rdb.AddHook(fake)
+
+	deliveries, err := s.Deliveries(context.Background(), kenko.DeliveryQuery{Notifier: "pager", Target: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != (eventsPage+10)/50 || deliveries[0].Attempts != eventsPage {
+		t.Errorf("deliveries = %+v, want every pager delivery, newest first", deliveries)
+	}
+	if len(fake.calls) != 2 {
+		t.Errorf("read %d pages, want 2", len(fake.calls))
+	}
+}
+
+func TestDecodeDeliveries(t *testing.T) {
+	out := decodeDeliveries([]redis.XMessage{
+		{ID: "1700000000000-0", Values: map[string]any{"delivery": `{"notifier":"pager","target":"api","outcome":"failed"}`}},
+		{ID: "1690000000000-0", Values: map[string]any{"delivery": `{not json`}},
+	})
+	if len(out) != 1 || out[0].Notifier != "pager" || out[0].Outcome != kenko.NotificationFailed {
+		t.Errorf("deliveries = %+v, want the valid one", out)
+	}
+}
@@ -0,0 +1,100 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultEventsKey = "kenko:events"
+
+	// maxEvents is roughly how many events the stream keeps across targets.
+	maxEvents = 10000
+
+	// eventsPage is how many entries Events and Deliveries read per round
+	// trip while filtering.
+	eventsPage = 500
+)
+
+// AddEvent appends an event to the events stream, which keeps about the
+// latest 10000.
+func (s *RedisStore) AddEvent(ctx context.Context, e kenko.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("redisstore: marshal event: %w", err)
+	}
+	err = s.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.eventsKey,
+		MaxLen: maxEvents,
+		Approx: true,
+		Values: map[string]any{"event": data},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("redisstore: add event: %w", err)
+	}
+	return nil
+}
+
+// Events returns the events matching q, newest first. like History, the time
+// bounds apply to when events were stored.
+func (s *RedisStore) Events(ctx context.Context, q kenko.EventQuery) ([]kenko.Event, error) {
+	start, stop := "+", "-"
+	if !q.To.IsZero() {
+		start = strconv.FormatInt(q.To.UnixMilli(), 10)
+	}
+	if !q.From.IsZero() {
+		stop = strconv.FormatInt(q.From.UnixMilli(), 10)
+	}
+
+	var out []kenko.Event
+	for {
+		msgs, err := s.rdb.XRevRangeN(ctx, s.eventsKey, start, stop, eventsPage).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redisstore: events: %w", err)
+		}
+		for _, e := range decodeEvents(msgs) {
+			if q.Target != "" && e.Target != q.Target {
+				continue
+			}
+			out = append(out, e)
+			if q.Limit > 0 && len(out) == q.Limit {
+				return out, nil
+			}
+		}
+		if len(msgs) < eventsPage {
+			return out, nil
+		}
+		// continue below the oldest entry read
+		start = "(" + msgs[len(msgs)-1].ID
+	}
+}
+
+// PruneTransitions drops the events stored before before.
+func (s *RedisStore) PruneTransitions(ctx context.Context, before time.Time) (int, error) {
+	n, err := s.rdb.XTrimMinID(ctx, s.eventsKey, strconv.FormatInt(before.UnixMilli(), 10)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redisstore: prune events: %w", err)
+	}
+	return int(n), nil
+}
+
+// decodeEvents unmarshals stream entries into events, skipping any that fail
+// to decode.
+func decodeEvents(msgs []redis.XMessage) []kenko.Event {
+	out := make([]kenko.Event, 0, len(msgs))
+	for _, m := range msgs {
+		data, _ := m.Values["event"].(string)
+		var e kenko.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
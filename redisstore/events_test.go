@@ -0,0 +1,81 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+// streamFake answers XREVRANGE from msgs, newest first, so paging can be
+// checked without a server.
+type streamFake struct {
+	msgs  []redis.XMessage
+	calls []string
+}
+
+func (f *streamFake) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (f *streamFake) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+		start, count := fmt.Sprint(args[2]), int(args[5].(int64))
+		f.calls = append(f.calls, start)
+
+		var page []redis.XMessage
+		for _, m := range f.msgs {
+			if start != "+" && m.ID >= start[1:] {
+				continue
+			}
+			if page = append(page, m); len(page) == count {
+				break
+			}
+		}
+		cmd.(*redis.XMessageSliceCmd).SetVal(page)
+		return nil
+	}
+}
+
+func (f *streamFake) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestEvents_PagesWhileFiltering(t *testing.T) {
+	fake := &streamFake{}
+	for i := 2 * eventsPage; i > 0; i-- {
+		target := "db"
+		if i%100 == 0 {
+			target = "api"
+		}
+		fake.msgs = append(fake.msgs, redis.XMessage{
+			ID:     fmt.Sprintf("%013d-0", 1700000000000+i),
+			Values: map[string]any{"event": `{"target":"` + target + `","from":"healthy","to":"unhealthy","duration":` + strconv.Itoa(i) + `}`},
+		})
+	}
+	s := New("localhost:6379")
+	s.rdb.AddHook(fake)
+
+	events, err := s.Events(context.Background(), kenko.EventQuery{Target: "api", Limit: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2*eventsPage/100 || events[0].Duration != 2*eventsPage {
+		t.Errorf("events = %+v, want every api event, newest first", events)
+	}
+	if len(fake.calls) != 3 {
+		t.Errorf("read %d pages, want 3", len(fake.calls))
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	out := decodeEvents([]redis.XMessage{
+		{ID: "1700000000000-0", Values: map[string]any{"event": `{"target":"api","from":"healthy","to":"unhealthy"}`}},
+		{ID: "1690000000000-0", Values: map[string]any{"event": `{not json`}},
+	})
+	if len(out) != 1 || out[0].Target != "api" || out[0].To != kenko.StatusUnhealthy {
+		t.Errorf("events = %+v, want the valid one", out)
+	}
+}
@@ -0,0 +1,113 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultHistoryPrefix = "kenko:history"
+
+// DefaultHistoryLen is how many results are kept per target by default.
+const DefaultHistoryLen = 1000
+
+// WithHistory sets how much history is kept in each target's stream: at most
+// maxLen results (default 1000), and when maxAge is set, only those from the
+// last maxAge. a stream not written to for maxAge expires with its target. a
+// maxLen of 0 turns history off.
+func WithHistory(maxLen int, maxAge time.Duration) Option {
+	return func(s *RedisStore) {
+		s.historyLen = maxLen
+		s.historyAge = maxAge
+	}
+}
+
+// historyKey is the stream holding the results of target name, e.g.
+// kenko:history:api.
+func (s *RedisStore) historyKey(name string) string {
+	return s.historyPrefix + ":" + name
+}
+
+// appendHistory queues adding the encoded result to the target's stream and
+// trimming it to the retention limits.
+func (s *RedisStore) appendHistory(ctx context.Context, pipe redis.Pipeliner, name string, data []byte) {
+	key := s.historyKey(name)
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: int64(s.historyLen),
+		Approx: true,
+		Values: map[string]any{"result": data},
+	})
+	if s.historyAge > 0 {
+		minID := strconv.FormatInt(time.Now().Add(-s.historyAge).UnixMilli(), 10)
+		pipe.XTrimMinIDApprox(ctx, key, minID, 0)
+		pipe.PExpire(ctx, key, s.historyAge)
+	}
+}
+
+// History returns the target's results matching q, newest first. results are
+// ordered and filtered by when they were stored, which trails CheckedAt by
+// the write latency.
+func (s *RedisStore) History(ctx context.Context, name string, q kenko.HistoryQuery) ([]kenko.Result, error) {
+	if s.historyLen == 0 {
+		return nil, kenko.ErrNoHistory
+	}
+
+	start, stop := "+", "-"
+	if !q.To.IsZero() {
+		start = strconv.FormatInt(q.To.UnixMilli(), 10)
+	}
+	if !q.From.IsZero() {
+		stop = strconv.FormatInt(q.From.UnixMilli(), 10)
+	}
+
+	var msgs []redis.XMessage
+	var err error
+	if q.Limit > 0 {
+		msgs, err = s.rdb.XRevRangeN(ctx, s.historyKey(name), start, stop, int64(q.Limit)).Result()
+	} else {
+		msgs, err = s.rdb.XRevRange(ctx, s.historyKey(name), start, stop).Result()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: history %q: %w", name, err)
+	}
+	return decodeHistory(s.codecs, name, msgs), nil
+}
+
+// PruneHistory drops the target's history stored before before.
+func (s *RedisStore) PruneHistory(ctx context.Context, name string, before time.Time) (int, error) {
+	n, err := s.rdb.XTrimMinID(ctx, s.historyKey(name), strconv.FormatInt(before.UnixMilli(), 10)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redisstore: prune history %q: %w", name, err)
+	}
+	return int(n), nil
+}
+
+// decodeHistory unmarshals stream entries into results. like decodeResults,
+// entries that fail to decode are kept as StatusUnknown results.
+func decodeHistory(codecs map[string]Codec, name string, msgs []redis.XMessage) []kenko.Result {
+	out := make([]kenko.Result, 0, len(msgs))
+	for _, msg := range msgs {
+		data, _ := msg.Values["result"].(string)
+		var r kenko.Result
+		if err := decode(codecs, []byte(data), &r); err != nil {
+			r = kenko.Result{
+				Target: name,
+				Status: kenko.StatusUnknown,
+				Error:  fmt.Sprintf("redisstore: unmarshal: %v", err),
+			}
+			if ms, _, ok := strings.Cut(msg.ID, "-"); ok {
+				if n, err := strconv.ParseInt(ms, 10, 64); err == nil {
+					r.CheckedAt = time.UnixMilli(n)
+				}
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
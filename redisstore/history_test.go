@@ -0,0 +1,39 @@
+package redisstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNew_WithHistory(t *testing.T) {
+	s := New("localhost:6379")
+	if s.historyLen != DefaultHistoryLen || s.historyAge != 0 {
+		t.Errorf("default history = %d, %s", s.historyLen, s.historyAge)
+	}
+	if got := s.historyKey("api"); got != "kenko:history:api" {
+		t.Errorf("historyKey = %q", got)
+	}
+
+	s = New("localhost:6379", WithHistory(50, time.Hour))
+	if s.historyLen != 50 || s.historyAge != time.Hour {
+		t.Errorf("history = %d, %s, want 50, 1h", s.historyLen, s.historyAge)
+	}
+}
+
+func TestDecodeHistory(t *testing.T) {
+	out := decodeHistory(Codecs(), "api", []redis.XMessage{
+		{ID: "1700000000000-0", Values: map[string]any{"result": `{"target":"api","status":"healthy"}`}},
+		{ID: "1690000000000-0", Values: map[string]any{"result": `{not json`}},
+	})
+
+	if len(out) != 2 || out[0].Status != kenko.StatusHealthy {
+		t.Fatalf("history = %+v", out)
+	}
+	bad := out[1]
+	if bad.Status != kenko.StatusUnknown || bad.Error == "" || !bad.CheckedAt.Equal(time.UnixMilli(1690000000000)) {
+		t.Errorf("bad entry = %+v, want unknown with the stream time", bad)
+	}
+}
@@ -2,14 +2,19 @@ package redisstore
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aidantrabs/kenko"
 	"github.com/redis/go-redis/v9"
 )
 
-const defaultKeyPrefix = "kenko:results"
+const (
+	defaultKeyPrefix = "kenko:results"
+	defaultStateKey  = "kenko:state"
+)
 
 // Option configures a RedisStore.
 type Option func(*RedisStore)
@@ -19,38 +24,148 @@ func WithPassword(password string) Option {
 	return func(s *RedisStore) { s.password = password }
 }
 
+// WithUsername sets the Redis ACL username, for servers with users other than
+// default.
+func WithUsername(username string) Option {
+	return func(s *RedisStore) { s.username = username }
+}
+
+// WithDB selects the Redis logical database (default 0).
+func WithDB(db int) Option {
+	return func(s *RedisStore) { s.db = db }
+}
+
+// WithTLS connects to Redis over TLS with the given config, as managed
+// services such as ElastiCache and Upstash require.
+func WithTLS(cfg *tls.Config) Option {
+	return func(s *RedisStore) { s.tls = cfg }
+}
+
+// WithNamespace prefixes every key the store uses with ns instead of "kenko":
+// results go in ns:results, operator state in ns:state, history in
+// ns:history:<name>, status change events in ns:events, notification
+// deliveries in ns:deliveries, and rollups in ns:rollups:<seconds>:<name>.
+// instances with their own namespace, e.g. prod and staging, can share one
+// Redis database.
+func WithNamespace(ns string) Option {
+	return func(s *RedisStore) {
+		s.keyPrefix = ns + ":results"
+		s.stateKey = ns + ":state"
+		s.historyPrefix = ns + ":history"
+		s.eventsKey = ns + ":events"
+		s.deliveriesKey = ns + ":deliveries"
+		s.rollupsPrefix = ns + ":rollups"
+	}
+}
+
 // WithKeyPrefix sets the Redis hash key used to store results (default "kenko:results").
 func WithKeyPrefix(prefix string) Option {
 	return func(s *RedisStore) { s.keyPrefix = prefix }
 }
 
+// WithCodec sets the codec results are written with (default JSON). entries
+// written by any built-in codec can still be read, so the codec can be changed
+// without flushing existing results.
+func WithCodec(c Codec) Option {
+	return func(s *RedisStore) { s.codec = c }
+}
+
 // RedisStore is a Store backed by a Redis hash.
 type RedisStore struct {
 	rdb       *redis.Client
 	keyPrefix string
+	stateKey  string
+	username  string
 	password  string
+	db        int
+	tls       *tls.Config
+	codec     Codec
+	codecs    map[string]Codec
+
+	historyPrefix string
+	historyLen    int
+	historyAge    time.Duration
+	eventsKey     string
+	deliveriesKey string
+	rollupsPrefix string
 }
 
 // New creates a RedisStore connected to the given address.
 func New(addr string, opts ...Option) *RedisStore {
-	s := &RedisStore{keyPrefix: defaultKeyPrefix}
+	s := &RedisStore{
+		keyPrefix:     defaultKeyPrefix,
+		stateKey:      defaultStateKey,
+		codec:         JSON,
+		historyPrefix: defaultHistoryPrefix,
+		historyLen:    DefaultHistoryLen,
+		eventsKey:     defaultEventsKey,
+		deliveriesKey: defaultDeliveriesKey,
+		rollupsPrefix: defaultRollupsPrefix,
+	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.codecs = Codecs()
+	s.codecs[s.codec.Name()] = s.codec
 	s.rdb = redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: s.password,
+		Addr:      addr,
+		Username:  s.username,
+		Password:  s.password,
+		DB:        s.db,
+		TLSConfig: s.tls,
 	})
 	return s
 }
 
-// Set stores a result in Redis keyed by target name.
+// Set stores a result in Redis keyed by target name, and appends it to the
+// target's history stream unless history is off.
 func (s *RedisStore) Set(ctx context.Context, name string, result kenko.Result) error {
-	data, err := json.Marshal(result)
+	data, err := encode(s.codec, result)
 	if err != nil {
 		return fmt.Errorf("redisstore: marshal: %w", err)
 	}
-	return s.rdb.HSet(ctx, s.keyPrefix, name, data).Err()
+	if s.historyLen == 0 {
+		return s.rdb.HSet(ctx, s.keyPrefix, name, data).Err()
+	}
+
+	_, err = s.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.keyPrefix, name, data)
+		s.appendHistory(ctx, pipe, name, data)
+		return nil
+	})
+	return err
+}
+
+// SetBatch stores several results, keyed by their target name, in one round
+// trip: a single HSET of every result and, unless history is off, an append
+// to each target's history stream.
+func (s *RedisStore) SetBatch(ctx context.Context, results []kenko.Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	fields := make([]any, 0, 2*len(results))
+	for _, r := range results {
+		data, err := encode(s.codec, r)
+		if err != nil {
+			return fmt.Errorf("redisstore: marshal %q: %w", r.Target, err)
+		}
+		fields = append(fields, r.Target, data)
+	}
+
+	_, err := s.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.keyPrefix, fields...)
+		if s.historyLen > 0 {
+			for i := 0; i < len(fields); i += 2 {
+				s.appendHistory(ctx, pipe, fields[i].(string), fields[i+1].([]byte))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redisstore: set batch: %w", err)
+	}
+	return nil
 }
 
 // GetAll retrieves all stored results from Redis.
@@ -60,15 +175,60 @@ func (s *RedisStore) GetAll(ctx context.Context) (map[string]kenko.Result, error
 		return nil, fmt.Errorf("redisstore: hgetall: %w", err)
 	}
 
+	return decodeResults(s.codecs, vals), nil
+}
+
+// Delete removes the result and history stored for name.
+func (s *RedisStore) Delete(ctx context.Context, name string) error {
+	_, err := s.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, s.keyPrefix, name)
+		pipe.Del(ctx, s.historyKey(name))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redisstore: delete %q: %w", name, err)
+	}
+	return nil
+}
+
+// decodeResults unmarshals hash entries written by any of codecs into results. entries that fail to
+// decode are reported with StatusUnknown and the parse error rather than
+// being dropped, so the target stays visible.
+func decodeResults(codecs map[string]Codec, vals map[string]string) map[string]kenko.Result {
 	out := make(map[string]kenko.Result, len(vals))
 	for name, data := range vals {
 		var r kenko.Result
-		if err := json.Unmarshal([]byte(data), &r); err != nil {
-			return nil, fmt.Errorf("redisstore: unmarshal %q: %w", name, err)
+		if err := decode(codecs, []byte(data), &r); err != nil {
+			out[name] = kenko.Result{
+				Target: name,
+				Status: kenko.StatusUnknown,
+				Error:  fmt.Sprintf("redisstore: unmarshal: %v", err),
+			}
+			continue
 		}
 		out[name] = r
 	}
-	return out, nil
+	return out
+}
+
+// SaveState stores an operator state blob in the state hash under key.
+func (s *RedisStore) SaveState(ctx context.Context, key string, data []byte) error {
+	if err := s.rdb.HSet(ctx, s.stateKey, key, data).Err(); err != nil {
+		return fmt.Errorf("redisstore: save state %q: %w", key, err)
+	}
+	return nil
+}
+
+// LoadState returns the operator state blob stored under key, or nil if unset.
+func (s *RedisStore) LoadState(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.rdb.HGet(ctx, s.stateKey, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: load state %q: %w", key, err)
+	}
+	return data, nil
 }
 
 // Ping checks connectivity to the Redis server.
@@ -1,9 +1,32 @@
 package redisstore
 
 import (
+	"context"
+	"crypto/tls"
 	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
 )
 
+// pipelineRecorder captures pipelines instead of sending them, so writes can
+// be checked without a server.
+type pipelineRecorder struct {
+	pipelines [][]redis.Cmder
+}
+
+func (p *pipelineRecorder) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (p *pipelineRecorder) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (p *pipelineRecorder) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		p.pipelines = append(p.pipelines, cmds)
+		return nil
+	}
+}
+
 func TestNew_DefaultKeyPrefix(t *testing.T) {
 	s := New("localhost:6379")
 	if s.keyPrefix != defaultKeyPrefix {
@@ -18,9 +41,109 @@ func TestNew_WithPassword(t *testing.T) {
 	}
 }
 
+func TestNew_Connection(t *testing.T) {
+	cfg := &tls.Config{ServerName: "cache.example.com"}
+	s := New("cache.example.com:6380", WithUsername("kenko"), WithPassword("secret"), WithDB(2), WithTLS(cfg))
+
+	opts := s.rdb.Options()
+	if opts.Username != "kenko" || opts.Password != "secret" || opts.DB != 2 || opts.TLSConfig != cfg {
+		t.Errorf("options = %+v", opts)
+	}
+}
+
 func TestNew_WithKeyPrefix(t *testing.T) {
 	s := New("localhost:6379", WithKeyPrefix("myapp:health"))
 	if s.keyPrefix != "myapp:health" {
 		t.Errorf("keyPrefix = %q, want %q", s.keyPrefix, "myapp:health")
 	}
 }
+
+func TestNew_WithNamespace(t *testing.T) {
+	s := New("localhost:6379", WithNamespace("staging"))
+	if s.keyPrefix != "staging:results" || s.stateKey != "staging:state" || s.historyKey("api") != "staging:history:api" || s.eventsKey != "staging:events" || s.deliveriesKey != "staging:deliveries" {
+		t.Errorf("keys = %q, %q, %q, want all under staging:", s.keyPrefix, s.stateKey, s.historyKey("api"))
+	}
+}
+
+func TestDecodeResults_PartialFailure(t *testing.T) {
+	out := decodeResults(Codecs(), map[string]string{
+		"api": `{"target":"api","status":"healthy"}`,
+		"bad": `{not json`,
+	})
+
+	if len(out) != 2 {
+		t.Fatalf("len = %d, want 2", len(out))
+	}
+	if out["api"].Status != kenko.StatusHealthy {
+		t.Errorf("api status = %q, want %q", out["api"].Status, kenko.StatusHealthy)
+	}
+	bad := out["bad"]
+	if bad.Status != kenko.StatusUnknown {
+		t.Errorf("bad status = %q, want %q", bad.Status, kenko.StatusUnknown)
+	}
+	if bad.Target != "bad" || bad.Error == "" {
+		t.Errorf("bad result = %+v, want target and error set", bad)
+	}
+}
+
+func TestDecodeResults_Endpoints(t *testing.T) {
+	out := decodeResults(Codecs(), map[string]string{
+		"api": `{"target":"api","status":"degraded","endpoints":[{"name":"ipv4","status":"healthy"},{"name":"ipv6","status":"unhealthy","error":"request failed"}]}`,
+	})
+
+	eps := out["api"].Endpoints
+	if len(eps) != 2 || eps[1].Name != "ipv6" || eps[1].Status != kenko.StatusUnhealthy {
+		t.Errorf("endpoints = %+v", eps)
+	}
+}
+
+func TestSetBatch_OnePipeline(t *testing.T) {
+	s := New("localhost:6379")
+	rec := &pipelineRecorder{}
+	s.rdb.AddHook(rec)
+
+	err := s.SetBatch(context.Background(), []kenko.Result{
+		{Target: "api", Status: kenko.StatusHealthy},
+		{Target: "web", Status: kenko.StatusUnhealthy},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.pipelines) != 1 {
+		t.Fatalf("pipelines = %d, want 1", len(rec.pipelines))
+	}
+	var names []string
+	for _, cmd := range rec.pipelines[0] {
+		names = append(names, cmd.Name())
+	}
+	if len(names) != 3 || names[0] != "hset" || names[1] != "xadd" || names[2] != "xadd" {
+		t.Errorf("commands = %v, want one hset and an xadd per result", names)
+	}
+	if args := rec.pipelines[0][0].Args(); len(args) != 6 || args[2] != "api" || args[4] != "web" {
+		t.Errorf("hset args = %v", args)
+	}
+}
+
+func TestSaveRollups_ReplacesByStart(t *testing.T) {
+	s := New("localhost:6379", WithNamespace("staging"))
+	rec := &pipelineRecorder{}
+	s.rdb.AddHook(rec)
+
+	start := time.Unix(1700000000, 0)
+	err := s.SaveRollups(context.Background(), "api", kenko.HourlyRollups, []kenko.Rollup{{Start: start, Checks: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, cmd := range rec.pipelines[0] {
+		names = append(names, cmd.Name())
+	}
+	if len(names) != 4 || names[1] != "zremrangebyscore" || names[2] != "zadd" {
+		t.Fatalf("commands = %v, want the old rollup removed and the new one added in a transaction", names)
+	}
+	if args := rec.pipelines[0][1].Args(); args[1] != "staging:rollups:3600:api" || args[2] != "1700000000" || args[3] != "1700000000" {
+		t.Errorf("zremrangebyscore args = %v", args)
+	}
+}
@@ -0,0 +1,92 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRollupsPrefix = "kenko:rollups"
+
+// rollupsKey is the sorted set holding the target's rollups of the
+// resolution, scored by start, e.g. kenko:rollups:3600:api.
+func (s *RedisStore) rollupsKey(resolution time.Duration, name string) string {
+	return s.rollupsPrefix + ":" + strconv.Itoa(int(resolution/time.Second)) + ":" + name
+}
+
+// SaveRollups stores the target's rollups of the resolution, replacing any
+// with the same start, in one transaction.
+func (s *RedisStore) SaveRollups(ctx context.Context, name string, resolution time.Duration, rollups []kenko.Rollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	key := s.rollupsKey(resolution, name)
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, r := range rollups {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			score := strconv.FormatInt(r.Start.Unix(), 10)
+			pipe.ZRemRangeByScore(ctx, key, score, score)
+			pipe.ZAdd(ctx, key, redis.Z{Score: float64(r.Start.Unix()), Member: data})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redisstore: save rollups %q: %w", name, err)
+	}
+	return nil
+}
+
+// Rollups returns the target's rollups of the resolution starting between
+// from and to, oldest first. rollups that fail to decode are skipped.
+func (s *RedisStore) Rollups(ctx context.Context, name string, resolution time.Duration, from, to time.Time) ([]kenko.Rollup, error) {
+	lower, upper := "-inf", "+inf"
+	if !from.IsZero() {
+		lower = strconv.FormatInt(from.Unix(), 10)
+	}
+	if !to.IsZero() {
+		upper = strconv.FormatInt(to.Unix(), 10)
+	}
+	vals, err := s.rdb.ZRangeByScore(ctx, s.rollupsKey(resolution, name), &redis.ZRangeBy{Min: lower, Max: upper}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: rollups %q: %w", name, err)
+	}
+
+	out := make([]kenko.Rollup, 0, len(vals))
+	for _, v := range vals {
+		var r kenko.Rollup
+		if err := json.Unmarshal([]byte(v), &r); err != nil {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// PruneRollups drops the rollups of the resolution of every target starting
+// before before, finding the targets' sets with SCAN.
+func (s *RedisStore) PruneRollups(ctx context.Context, resolution time.Duration, before time.Time) (int, error) {
+	upper := "(" + strconv.FormatInt(before.Unix(), 10)
+	pattern := s.rollupsPrefix + ":" + strconv.Itoa(int(resolution/time.Second)) + ":*"
+
+	pruned := 0
+	iter := s.rdb.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		n, err := s.rdb.ZRemRangeByScore(ctx, iter.Val(), "-inf", upper).Result()
+		if err != nil {
+			return pruned, fmt.Errorf("redisstore: prune rollups: %w", err)
+		}
+		pruned += int(n)
+	}
+	if err := iter.Err(); err != nil {
+		return pruned, fmt.Errorf("redisstore: prune rollups: %w", err)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,208 @@
+package kenko
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// TargetChanges lists the target names added, removed and changed by SetTargets.
+type TargetChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Empty reports whether SetTargets left the targets as they were.
+func (tc TargetChanges) Empty() bool {
+	return len(tc.Added) == 0 && len(tc.Removed) == 0 && len(tc.Changed) == 0
+}
+
+// validateTargets normalizes target names in place and checks that the
+// targets can be run by a checker with the given revocation checker.
+func validateTargets(targets []Target, names NameNormalizer, rc RevocationChecker) error {
+	if len(targets) == 0 {
+		return fmt.Errorf("kenko: at least one target is required")
+	}
+
+	if err := normalizeTargets(targets, names); err != nil {
+		return err
+	}
+
+	if err := parseTargetMaintenance(targets); err != nil {
+		return err
+	}
+
+	if err := parseSchedules(targets); err != nil {
+		return err
+	}
+
+	for _, t := range targets {
+		if t.Revocation != RevocationOff && rc == nil {
+			return fmt.Errorf("kenko: target %q checks revocation but no RevocationChecker is set", t.Name)
+		}
+		if t.Endpoints == EndpointsPerFamily && t.IPVersion != IPAny {
+			return fmt.Errorf("kenko: target %q checks each address family but is restricted to one", t.Name)
+		}
+		if t.Endpoints == EndpointsPerIP && len(t.Steps) > 0 {
+			return fmt.Errorf("kenko: target %q cannot check each resolved address of a multi-step check", t.Name)
+		}
+		if t.HeartbeatTimeout > 0 && (len(t.Steps) > 0 || t.Endpoints != EndpointsSingle) {
+			return fmt.Errorf("kenko: heartbeat target %q cannot have steps or endpoints", t.Name)
+		}
+		if _, ok := t.Labels[""]; ok {
+			return fmt.Errorf("kenko: target %q has a label with an empty key", t.Name)
+		}
+		if t.Retries < 0 || t.RetryBackoff < 0 {
+			return fmt.Errorf("kenko: target %q has a negative retry count or backoff", t.Name)
+		}
+		if t.FailureThreshold < 0 || t.SuccessThreshold < 0 {
+			return fmt.Errorf("kenko: target %q has a negative failure or success threshold", t.Name)
+		}
+		if t.SlowLatency < 0 || t.SlowChecks < 0 {
+			return fmt.Errorf("kenko: target %q has a negative slow latency or check count", t.Name)
+		}
+		if t.Severity != "" && !slices.Contains(Severities, t.Severity) {
+			return fmt.Errorf("kenko: target %q has unknown severity %q", t.Name, t.Severity)
+		}
+		for _, on := range t.RetryOn {
+			if !slices.Contains(RetryConditions, on) {
+				return fmt.Errorf("kenko: target %q retries on unknown condition %q", t.Name, on)
+			}
+		}
+	}
+	return nil
+}
+
+// targetList returns the current targets. the slice is replaced, never
+// modified, by SetTargets so callers may range over it without the lock.
+func (c *Checker) targetList() []Target {
+	c.targetsMu.RLock()
+	defer c.targetsMu.RUnlock()
+	return c.targets
+}
+
+// Targets returns a copy of the targets currently being checked.
+func (c *Checker) Targets() []Target {
+	return slices.Clone(c.targetList())
+}
+
+// SetTargets replaces the checker's targets while it is running, e.g. after a
+// config reload. results, timelines and operator state of targets that are
+// kept survive the swap; the results of removed targets are deleted when the
+// store implements Deleter. the new targets are checked from the next cycle.
+// targets added or edited through AddTarget and UpdateTarget stay so over
+// the new targets, as do those removed with RemoveTarget while the new
+// targets still have them, unless a TargetSaver saved the changes to where
+// the new targets come from.
+func (c *Checker) SetTargets(ctx context.Context, targets []Target) (TargetChanges, error) {
+	targets = slices.Clone(targets)
+	if err := validateTargets(targets, c.names, c.revocation); err != nil {
+		return TargetChanges{}, err
+	}
+
+	c.managed.mu.Lock()
+	defer c.managed.mu.Unlock()
+
+	// a removal is done with once the new targets no longer have the target,
+	// so adding it back to the config checks it again. with a TargetSaver the
+	// new targets already hold every change
+	managed := slices.DeleteFunc(slices.Clone(c.managed.changes), func(m managedTarget) bool {
+		return c.targetSaver != nil || m.Removed && !slices.ContainsFunc(targets, func(t Target) bool { return t.Name == m.Name })
+	})
+	changes, err := c.swapTargets(ctx, applyManaged(targets, managed))
+	if err != nil {
+		return TargetChanges{}, err
+	}
+	c.managed.base = targets
+	pruned := len(managed) < len(c.managed.changes)
+	c.managed.changes = managed
+	if pruned && c.targetSaver == nil {
+		if err := c.saveState(ctx, targetsStateKey, managed); err != nil {
+			c.logger.Warn("failed to save targets changed through the API", "error", err)
+		}
+	}
+	return changes, nil
+}
+
+// swapTargets validates targets and makes them the current ones. the caller
+// holds managed.mu.
+func (c *Checker) swapTargets(ctx context.Context, targets []Target) (TargetChanges, error) {
+	if err := validateTargets(targets, c.names, c.revocation); err != nil {
+		return TargetChanges{}, err
+	}
+
+	c.targetsMu.Lock()
+	old := c.targets
+	c.targets = targets
+	c.targetsMu.Unlock()
+
+	changes := diffTargets(old, targets)
+	c.schedule.poke()
+
+	if d, ok := c.store.(Deleter); ok {
+		for _, name := range changes.Removed {
+			if err := d.Delete(ctx, name); err != nil {
+				c.logger.Warn("failed to delete result", "target", name, "error", err)
+			}
+		}
+	}
+	if c.cache != nil && len(changes.Removed) > 0 {
+		c.cache.invalidate()
+	}
+	for _, name := range changes.Removed {
+		c.forgetTarget(name)
+	}
+
+	return changes, nil
+}
+
+func diffTargets(old, cur []Target) TargetChanges {
+	before := make(map[string]Target, len(old))
+	for _, t := range old {
+		before[t.Name] = t
+	}
+
+	var tc TargetChanges
+	for _, t := range cur {
+		prev, ok := before[t.Name]
+		switch {
+		case !ok:
+			tc.Added = append(tc.Added, t.Name)
+		case !sameTarget(prev, t):
+			tc.Changed = append(tc.Changed, t.Name)
+		}
+		delete(before, t.Name)
+	}
+	for _, t := range old {
+		if _, ok := before[t.Name]; ok {
+			tc.Removed = append(tc.Removed, t.Name)
+		}
+	}
+	return tc
+}
+
+// sameTarget compares two target definitions. credential refreshers are
+// functions, which never compare equal, so only their settings are compared,
+// and schedules and maintenance windows are compared by their settings rather
+// than their parsed cron expressions.
+func sameTarget(a, b Target) bool {
+	if !slices.EqualFunc(a.Maintenance, b.Maintenance, func(x, y MaintenanceWindow) bool {
+		return x.Schedule == y.Schedule && x.Duration == y.Duration
+	}) {
+		return false
+	}
+	a.Maintenance, b.Maintenance = nil, nil
+	a.cron, b.cron = nil, nil
+
+	ac, bc := a.Credential, b.Credential
+	a.Credential, b.Credential = nil, nil
+	if (ac == nil) != (bc == nil) {
+		return false
+	}
+	if ac != nil && (ac.Header != bc.Header || ac.Prefix != bc.Prefix) {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
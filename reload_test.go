@@ -0,0 +1,141 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetTargets_Diff(t *testing.T) {
+	store := NewMemoryStore()
+	metrics := &forgetRecorder{}
+	c, err := NewChecker(
+		WithStore(store),
+		WithMetrics(metrics),
+		WithNameNormalizer(SlugName),
+		WithTarget("api", "https://api.example.com"),
+		WithTarget("web", "https://example.com"),
+		WithTarget("db", "https://db.example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, name := range []string{"api", "web", "db"} {
+		_ = store.Set(ctx, name, Result{Target: name, Status: StatusHealthy})
+	}
+	c.timeline.observe(Result{Target: "api", Status: StatusHealthy})
+
+	changes, err := c.SetTargets(ctx, []Target{
+		NewTarget("api", "https://api.example.com"),
+		NewTarget("web", "https://example.com", WithMethod(http.MethodHead)),
+		NewTarget("Docs", "https://docs.example.com"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(changes.Added, []string{"docs"}) ||
+		!slices.Equal(changes.Removed, []string{"db"}) ||
+		!slices.Equal(changes.Changed, []string{"web"}) {
+		t.Errorf("changes = %+v, want added [docs], removed [db], changed [web]", changes)
+	}
+
+	results, _ := c.Results()
+	if _, ok := results["db"]; ok {
+		t.Error("expected the removed target's result to be deleted")
+	}
+	if _, ok := results["api"]; !ok {
+		t.Error("expected the kept target's result to survive")
+	}
+	if len(c.timeline.get("api")) == 0 {
+		t.Error("expected the kept target's timeline to survive")
+	}
+	if !c.hasTarget("docs") || c.hasTarget("db") {
+		t.Error("expected hasTarget to follow the new targets")
+	}
+	if !slices.Equal(metrics.forgotten, []string{"db"}) {
+		t.Errorf("forgotten = %v, want the removed target's metrics dropped", metrics.forgotten)
+	}
+}
+
+func TestSetTargets_Unchanged(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com", WithHeader("X-Key", "1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := c.SetTargets(context.Background(), []Target{
+		NewTarget("api", "https://api.example.com", WithHeader("X-Key", "1")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.Empty() {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
+
+func TestSetTargets_Invalid(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, targets := range map[string][]Target{
+		"empty":      nil,
+		"duplicate":  {NewTarget("api", "https://a.example.com"), NewTarget("api", "https://b.example.com")},
+		"revocation": {NewTarget("api", "https://api.example.com", WithRevocation(RevocationCheck))},
+	} {
+		if _, err := c.SetTargets(context.Background(), targets); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if !c.hasTarget("api") {
+		t.Error("expected a rejected update to keep the old targets")
+	}
+}
+
+func TestSetTargets_CheckedNextCycle(t *testing.T) {
+	var hits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer srv.Close()
+
+	c, err := NewChecker(WithTarget("a", srv.URL+"/a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	c.checkAll(ctx)
+
+	if _, err := c.SetTargets(ctx, []Target{NewTarget("b", srv.URL+"/b")}); err != nil {
+		t.Fatal(err)
+	}
+	c.checkAll(ctx)
+
+	if !slices.Equal(hits, []string{"a", "b"}) {
+		t.Errorf("hits = %v, want [a b]", hits)
+	}
+}
+
+func TestSetTargets_MaintenanceUnchanged(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com", WithMaintenance("CRON_TZ=Europe/Berlin 0 2 * * *", time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := c.SetTargets(context.Background(), []Target{NewTarget("api", "https://api.example.com", WithMaintenance("CRON_TZ=Europe/Berlin 0 2 * * *", time.Hour))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.Empty() {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
@@ -0,0 +1,182 @@
+// Package remotewrite pushes kenko's Prometheus metrics to a remote_write
+// endpoint such as Grafana Cloud, Mimir, Cortex, or a Prometheus with the
+// remote write receiver enabled, for deployments nothing scrapes.
+//
+// it gathers the registry the prommetrics Reporter registers with on every
+// interval and sends the samples as a snappy-compressed protobuf
+// WriteRequest, version 1.0 of the protocol.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	defaultTimeout  = 10 * time.Second
+	// finalPushTimeout bounds the push made when Run returns.
+	finalPushTimeout = 5 * time.Second
+)
+
+// Option configures a Writer.
+type Option func(*Writer)
+
+// WithInterval sets how often metrics are pushed (default 30s).
+func WithInterval(d time.Duration) Option {
+	return func(w *Writer) { w.interval = d }
+}
+
+// WithGatherer sets the registry gathered on every push (default
+// prometheus.DefaultGatherer).
+func WithGatherer(g prometheus.Gatherer) Option {
+	return func(w *Writer) { w.gatherer = g }
+}
+
+// WithBasicAuth authenticates pushes with http basic auth, e.g. a Grafana
+// Cloud instance id and api token.
+func WithBasicAuth(username, password string) Option {
+	return func(w *Writer) { w.username, w.password = username, password }
+}
+
+// WithBearerToken authenticates pushes with an Authorization: Bearer header.
+func WithBearerToken(token string) Option {
+	return func(w *Writer) { w.token = token }
+}
+
+// WithHeader adds a request header to every push, e.g. X-Scope-OrgID for a
+// multi-tenant Mimir.
+func WithHeader(key, value string) Option {
+	return func(w *Writer) { w.header.Add(key, value) }
+}
+
+// WithLabels adds labels to every series, e.g. job and instance, which a
+// scraping Prometheus would otherwise have attached. labels already on a
+// metric take precedence.
+func WithLabels(labels map[string]string) Option {
+	return func(w *Writer) {
+		for k, v := range labels {
+			w.labels[k] = v
+		}
+	}
+}
+
+// WithHTTPClient sets the client pushes are sent with (default a client with
+// a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(w *Writer) { w.client = c }
+}
+
+// WithLogger sets the logger failed pushes are reported to (default
+// slog.Default()).
+func WithLogger(l *slog.Logger) Option {
+	return func(w *Writer) { w.logger = l }
+}
+
+// Writer periodically pushes gathered metrics to a remote_write url.
+type Writer struct {
+	url      string
+	interval time.Duration
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	logger   *slog.Logger
+
+	username string
+	password string
+	token    string
+	header   http.Header
+	labels   map[string]string
+}
+
+// New creates a Writer pushing to url.
+func New(url string, opts ...Option) *Writer {
+	w := &Writer{
+		url:      url,
+		interval: defaultInterval,
+		gatherer: prometheus.DefaultGatherer,
+		client:   &http.Client{Timeout: defaultTimeout},
+		logger:   slog.Default(),
+		header:   make(http.Header),
+		labels:   make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run pushes every interval until ctx is done, then pushes once more so the
+// last checks before a shutdown are not lost. failed pushes are logged and
+// retried with fresh samples on the next interval.
+func (w *Writer) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalPushTimeout)
+			defer cancel()
+			if err := w.Push(final); err != nil {
+				w.logger.Warn("remote write failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := w.Push(ctx); err != nil {
+				w.logger.Warn("remote write failed", "error", err)
+			}
+		}
+	}
+}
+
+// Push gathers the metrics and sends them in one request.
+func (w *Writer) Push(ctx context.Context) error {
+	families, err := w.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("remotewrite: gather: %w", err)
+	}
+	series := toSeries(families, w.labels, time.Now())
+	if len(series) == 0 {
+		return nil
+	}
+
+	body := snappy.Encode(nil, encodeWriteRequest(series))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("remotewrite: %w", err)
+	}
+	for k, v := range w.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "kenko")
+	switch {
+	case w.token != "":
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	case w.username != "":
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remotewrite: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remotewrite: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,136 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type receiver struct {
+	requests chan *http.Request
+	bodies   chan []byte
+	status   int
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	rcv := &receiver{requests: make(chan *http.Request, 4), bodies: make(chan []byte, 4), status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.requests <- r
+		rcv.bodies <- body
+		w.WriteHeader(rcv.status)
+		if rcv.status != http.StatusNoContent {
+			io.WriteString(w, "out of order sample\n")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return rcv, srv
+}
+
+func testRegistry() *prometheus.Registry {
+	reg := prometheus.NewPedanticRegistry()
+	checks := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "kenko_checks_total", Help: "checks"}, []string{"target"})
+	reg.MustRegister(checks)
+	checks.WithLabelValues("api").Add(3)
+	return reg
+}
+
+func TestPush(t *testing.T) {
+	rcv, srv := newReceiver(t, http.StatusNoContent)
+	w := New(srv.URL,
+		WithGatherer(testRegistry()),
+		WithBasicAuth("12345", "token"),
+		WithHeader("X-Scope-OrgID", "tenant"),
+		WithLabels(map[string]string{"instance": "eu-1"}),
+	)
+	if err := w.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-rcv.requests
+	for header, want := range map[string]string{
+		"Content-Type":                      "application/x-protobuf",
+		"Content-Encoding":                  "snappy",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+		"X-Scope-OrgID":                     "tenant",
+	} {
+		if got := r.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if user, pass, ok := r.BasicAuth(); !ok || user != "12345" || pass != "token" {
+		t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+	}
+
+	data, err := snappy.Decode(nil, <-rcv.bodies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodeWriteRequest(t, data)
+	s, ok := got[`{__name__="kenko_checks_total",instance="eu-1",target="api"}`]
+	if len(got) != 1 || !ok || s.value != 3 {
+		t.Errorf("series = %v, want the counter at 3", got)
+	}
+}
+
+func TestPush_BearerToken(t *testing.T) {
+	rcv, srv := newReceiver(t, http.StatusNoContent)
+	w := New(srv.URL, WithGatherer(testRegistry()), WithBearerToken("secret"))
+	if err := w.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := (<-rcv.requests).Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestPush_ErrorStatus(t *testing.T) {
+	_, srv := newReceiver(t, http.StatusBadRequest)
+	err := New(srv.URL, WithGatherer(testRegistry())).Push(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "status 400: out of order sample") {
+		t.Errorf("err = %v, want the status and response body", err)
+	}
+}
+
+func TestPush_NothingGathered(t *testing.T) {
+	rcv, srv := newReceiver(t, http.StatusNoContent)
+	if err := New(srv.URL, WithGatherer(prometheus.NewRegistry())).Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-rcv.requests:
+		t.Error("pushed without series")
+	default:
+	}
+}
+
+func TestRun_PushesOnShutdown(t *testing.T) {
+	rcv, srv := newReceiver(t, http.StatusNoContent)
+	w := New(srv.URL, WithGatherer(testRegistry()), WithInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+	select {
+	case <-rcv.requests:
+	default:
+		t.Error("no final push on shutdown")
+	}
+}
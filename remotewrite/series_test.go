@@ -0,0 +1,137 @@
+package remotewrite
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest is the inverse of encodeWriteRequest, keyed by the
+// series' labels in text form, e.g. {__name__="up",target="api"}.
+func decodeWriteRequest(t *testing.T, data []byte) map[string]series {
+	t.Helper()
+	out := make(map[string]series)
+	fields(t, data, func(_ protowire.Number, ts []byte) {
+		var s series
+		fields(t, ts, func(num protowire.Number, msg []byte) {
+			switch num {
+			case 1:
+				var l label
+				fields(t, msg, func(num protowire.Number, v []byte) {
+					if num == 1 {
+						l.name = string(v)
+					} else {
+						l.value = string(v)
+					}
+				})
+				s.labels = append(s.labels, l)
+			case 2:
+				for len(msg) > 0 {
+					num, typ, n := protowire.ConsumeTag(msg)
+					msg = msg[n:]
+					switch {
+					case num == 1 && typ == protowire.Fixed64Type:
+						v, n := protowire.ConsumeFixed64(msg)
+						s.value = math.Float64frombits(v)
+						msg = msg[n:]
+					case num == 2 && typ == protowire.VarintType:
+						v, n := protowire.ConsumeVarint(msg)
+						s.timestamp = int64(v)
+						msg = msg[n:]
+					default:
+						t.Fatalf("unexpected sample field %d", num)
+					}
+				}
+			}
+		})
+		out[seriesKey(s.labels)] = s
+	})
+	return out
+}
+
+// fields calls fn with every length-delimited field of msg.
+func fields(t *testing.T, msg []byte, fn func(protowire.Number, []byte)) {
+	t.Helper()
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 || typ != protowire.BytesType {
+			t.Fatalf("unexpected field %d of type %d", num, typ)
+		}
+		msg = msg[n:]
+		v, n := protowire.ConsumeBytes(msg)
+		if n < 0 {
+			t.Fatal("truncated message")
+		}
+		fn(num, v)
+		msg = msg[n:]
+	}
+}
+
+func seriesKey(labels []label) string {
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = l.name + "=" + `"` + l.value + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func TestToSeries(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	up := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kenko_target_up", Help: "up"}, []string{"target"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kenko_check_duration_seconds",
+		Help:    "duration",
+		Buckets: []float64{0.1, 1},
+	}, []string{"target"})
+	reg.MustRegister(up, duration)
+	up.WithLabelValues("api").Set(1)
+	duration.WithLabelValues("api").Observe(0.5)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.UnixMilli(1_700_000_000_000)
+	got := decodeWriteRequest(t, encodeWriteRequest(toSeries(families, map[string]string{"job": "kenko", "target": "ignored"}, now)))
+
+	want := map[string]float64{
+		`{__name__="kenko_target_up",job="kenko",target="api"}`:                               1,
+		`{__name__="kenko_check_duration_seconds_bucket",job="kenko",le="0.1",target="api"}`:  0,
+		`{__name__="kenko_check_duration_seconds_bucket",job="kenko",le="1",target="api"}`:    1,
+		`{__name__="kenko_check_duration_seconds_bucket",job="kenko",le="+Inf",target="api"}`: 1,
+		`{__name__="kenko_check_duration_seconds_sum",job="kenko",target="api"}`:              0.5,
+		`{__name__="kenko_check_duration_seconds_count",job="kenko",target="api"}`:            1,
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d series, want %d: %v", len(got), len(want), slices.Sorted(maps.Keys(got)))
+	}
+	for key, v := range want {
+		s, ok := got[key]
+		if !ok {
+			t.Errorf("missing series %s", key)
+			continue
+		}
+		if s.value != v || s.timestamp != now.UnixMilli() {
+			t.Errorf("%s = %v at %d, want %v at %d", key, s.value, s.timestamp, v, now.UnixMilli())
+		}
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	for f, want := range map[float64]string{
+		0.25:        "0.25",
+		1:           "1",
+		math.Inf(1): "+Inf",
+		1e-7:        "1e-07",
+	} {
+		if got := formatFloat(f); got != want {
+			t.Errorf("formatFloat(%v) = %q, want %q", f, got, want)
+		}
+	}
+}
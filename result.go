@@ -2,12 +2,6 @@ package kenko
 
 import "time"
 
-// Target represents an endpoint to be health-checked.
-type Target struct {
-	Name string
-	URL  string
-}
-
 // Status represents the outcome of a health check.
 type Status string
 
@@ -15,6 +9,18 @@ type Status string
 const (
 	StatusHealthy   Status = "healthy"
 	StatusUnhealthy Status = "unhealthy"
+	// StatusUnknown marks a result that could not be determined, e.g. a
+	// stored entry that failed to decode.
+	StatusUnknown Status = "unknown"
+	// StatusDegraded marks a target that responds but fails a non-fatal
+	// check, e.g. a revoked certificate.
+	StatusDegraded Status = "degraded"
+	// StatusPaused marks a target that is listed but not checked. see
+	// WithPaused.
+	StatusPaused Status = "paused"
+	// StatusMaintenance marks a target checked during one of its maintenance
+	// windows. see WithMaintenance.
+	StatusMaintenance Status = "maintenance"
 )
 
 // Result holds the outcome of a single health check against a target.
@@ -26,4 +32,57 @@ type Result struct {
 	Latency    time.Duration `json:"latency"`
 	Error      string        `json:"error,omitempty"`
 	CheckedAt  time.Time     `json:"checked_at"`
+	Region     string        `json:"region,omitempty"`
+	Group      string        `json:"group,omitempty"`
+	Severity   Severity      `json:"severity,omitempty"`
+	BodyBytes  int64         `json:"body_bytes,omitempty"`
+
+	// Attempts is the number of requests the check took when it was retried.
+	Attempts int `json:"attempts,omitempty"`
+
+	// Observed is the status the check found while the target's failure or
+	// success threshold holds Status at the previous one. see WithThresholds.
+	Observed Status `json:"observed,omitempty"`
+
+	// Labels are the target's labels at the time of the check.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ContentEncoding and DecodedBytes are set for targets with an expected
+	// encoding. BodyBytes then counts the compressed bytes on the wire and
+	// DecodedBytes the decompressed size, when the encoding can be decoded.
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	DecodedBytes    int64  `json:"decoded_bytes,omitempty"`
+
+	// BodyHash is the hex sha256 of the response body, set when content
+	// hashing is enabled for the target.
+	BodyHash string `json:"body_hash,omitempty"`
+	// Changed reports that BodyHash differs from the configured baseline or,
+	// without a baseline, from the previous check.
+	Changed bool `json:"changed,omitempty"`
+
+	// Body holds the start of the response body of a failed check, with
+	// control characters replaced, so the failure can be seen without
+	// reproducing it.
+	Body string `json:"body,omitempty"`
+
+	// TLS describes the negotiated connection for HTTPS targets.
+	TLS *TLSInfo `json:"tls,omitempty"`
+
+	// Phases breaks Latency down by DNS, connect, TLS, time to first byte and
+	// body transfer. it is nil for multi-step checks.
+	Phases *Phases `json:"phases,omitempty"`
+
+	// RemoteIP is the address the check connected to, which shows DNS
+	// failovers and CDN edge changes. IPInfo enriches it when an IPEnricher
+	// is configured.
+	RemoteIP string  `json:"remote_ip,omitempty"`
+	IPInfo   *IPInfo `json:"ip_info,omitempty"`
+
+	// Steps holds per-step outcomes for multi-step synthetic checks.
+	Steps []StepResult `json:"steps,omitempty"`
+
+	// Endpoints holds the outcome of each endpoint of a target checked per
+	// address family, resolved address, or region. Status then aggregates
+	// them and the other fields describe a representative endpoint.
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
 }
@@ -0,0 +1,139 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultRetentionInterval is how often stored data is pruned.
+const defaultRetentionInterval = time.Hour
+
+// Retention bounds how long stored data is kept. a zero age keeps that kind
+// of data until the store's own limits drop it.
+type Retention struct {
+	// Results is how long raw check results are kept in the store's history.
+	Results time.Duration
+	// Transitions is how long status changes are kept, in the store and in
+	// each target's timeline.
+	Transitions time.Duration
+	// Deliveries is how long notification delivery records are kept in the
+	// store's delivery log.
+	Deliveries time.Duration
+	// Interval between pruning runs (default 1h).
+	Interval time.Duration
+}
+
+// TransitionPruner is implemented by stores that record status transitions.
+type TransitionPruner interface {
+	// PruneTransitions removes transitions of every target from before
+	// before and reports how many were removed.
+	PruneTransitions(ctx context.Context, before time.Time) (int, error)
+}
+
+// PruneReporter is an optional MetricsReporter extension that receives how
+// many results ("results"), transitions ("transitions") or delivery records
+// ("deliveries") each pruning run removed.
+type PruneReporter interface {
+	ReportPruned(kind string, n int)
+}
+
+// WithRetention periodically prunes results and transitions older than the
+// ages in r from the store and the in-memory timelines. pruning results
+// needs a store that implements HistoryPruner, and pruning deliveries one
+// that implements DeliveryPruner.
+func WithRetention(r Retention) Option {
+	return func(o *options) { o.retention = &r }
+}
+
+func validateRetention(r *Retention, a *Archive, store Store) error {
+	if r == nil {
+		return nil
+	}
+	if r.Results < 0 || r.Transitions < 0 || r.Deliveries < 0 || r.Interval < 0 {
+		return fmt.Errorf("kenko: retention ages and interval must not be negative")
+	}
+	if r.Results == 0 && r.Transitions == 0 && r.Deliveries == 0 {
+		return fmt.Errorf("kenko: retention needs a results, transitions or deliveries age")
+	}
+	if _, ok := store.(DeliveryPruner); r.Deliveries > 0 && !ok {
+		return fmt.Errorf("kenko: deliveries retention needs a store that prunes deliveries, got %T", store)
+	}
+	if r.Results > 0 {
+		if _, ok := store.(HistoryPruner); !ok {
+			return fmt.Errorf("kenko: results retention needs a store that prunes history, got %T", store)
+		}
+		if a != nil && r.Results < a.After {
+			return fmt.Errorf("kenko: results retention %s is shorter than the archive age %s, so results would be pruned before they are archived", r.Results, a.After)
+		}
+	}
+	return nil
+}
+
+func (c *Checker) runRetention(ctx context.Context) {
+	ticker := time.NewTicker(cmp.Or(c.retention.Interval, defaultRetentionInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.life.stop:
+			return
+		case <-ticker.C:
+		}
+		c.prune(ctx, time.Now())
+	}
+}
+
+// prune drops what is older than the retention ages at now and reports the
+// counts.
+func (c *Checker) prune(ctx context.Context, now time.Time) {
+	r := c.retention
+
+	if r.Results > 0 {
+		pruner := c.store.(HistoryPruner)
+		before := now.Add(-r.Results)
+		total := 0
+		for _, t := range c.targetList() {
+			n, err := pruner.PruneHistory(ctx, t.Name, before)
+			if err != nil {
+				c.logger.Warn("failed to prune results", "target", t.Name, "error", err)
+				continue
+			}
+			total += n
+		}
+		c.reportPruned("results", total)
+	}
+
+	if r.Transitions > 0 {
+		before := now.Add(-r.Transitions)
+		total := c.timeline.prune(before)
+		if tp, ok := c.store.(TransitionPruner); ok {
+			n, err := tp.PruneTransitions(ctx, before)
+			if err != nil {
+				c.logger.Warn("failed to prune transitions", "error", err)
+			}
+			total += n
+		}
+		c.reportPruned("transitions", total)
+	}
+
+	if r.Deliveries > 0 {
+		n, err := c.store.(DeliveryPruner).PruneDeliveries(ctx, now.Add(-r.Deliveries))
+		if err != nil {
+			c.logger.Warn("failed to prune deliveries", "error", err)
+		}
+		c.reportPruned("deliveries", n)
+	}
+}
+
+func (c *Checker) reportPruned(kind string, n int) {
+	if n > 0 {
+		c.logger.Info("pruned old data", "kind", kind, "count", n)
+	}
+	if pr, ok := c.metrics.(PruneReporter); ok {
+		pr.ReportPruned(kind, n)
+	}
+}
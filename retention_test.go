@@ -0,0 +1,88 @@
+package kenko
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type pruneRecorder struct {
+	pruned map[string]int
+}
+
+func (p *pruneRecorder) ReportCheck(string, Status, float64) {}
+
+func (p *pruneRecorder) ReportPruned(kind string, n int) { p.pruned[kind] += n }
+
+func TestPrune(t *testing.T) {
+	store := NewMemoryStore()
+	rec := &pruneRecorder{pruned: make(map[string]int)}
+	c, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithStore(store),
+		WithMetrics(rec),
+		WithRetention(Retention{Results: time.Hour, Transitions: 2 * time.Hour, Deliveries: time.Hour}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i, st := range []Status{StatusHealthy, StatusUnhealthy, StatusHealthy, StatusUnhealthy} {
+		r := Result{Target: "api", Status: st, CheckedAt: now.Add(time.Duration(i-4) * time.Hour)}
+		store.Set(context.Background(), "api", r)
+		c.timeline.observe(r)
+		store.AddDelivery(context.Background(), Delivery{Notifier: "ops", Target: "api", At: r.CheckedAt})
+	}
+
+	c.prune(context.Background(), now)
+
+	if kept, _ := store.History(context.Background(), "api", HistoryQuery{}); len(kept) != 1 {
+		t.Errorf("kept %d results, want the one from the last hour", len(kept))
+	}
+	// only the segment that ended 3h ago is older than 2h
+	if segs := c.timeline.get("api"); len(segs) != 3 {
+		t.Errorf("kept %d segments, want 3", len(segs))
+	}
+	if rec.pruned["results"] != 3 || rec.pruned["transitions"] != 1 || rec.pruned["deliveries"] != 3 {
+		t.Errorf("pruned = %v, want 3 results, 1 transition and 3 deliveries", rec.pruned)
+	}
+}
+
+func TestTimeline_Prune(t *testing.T) {
+	var tl timeline
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tl.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: t0})
+
+	// the current segment is kept however old it is
+	if n := tl.prune(t0.Add(time.Hour)); n != 0 || len(tl.get("api")) != 1 {
+		t.Errorf("pruned %d, kept %d segments, want the open one kept", n, len(tl.get("api")))
+	}
+}
+
+func TestNew_InvalidRetention(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"empty":              {WithRetention(Retention{})},
+		"negative":           {WithRetention(Retention{Results: -time.Hour})},
+		"no pruner":          {WithRetention(Retention{Results: time.Hour}), WithStore(struct{ Store }{NewMemoryStore()})},
+		"no delivery pruner": {WithRetention(Retention{Deliveries: time.Hour}), WithStore(struct{ Store }{NewMemoryStore()})},
+		"before archive": {
+			WithRetention(Retention{Results: time.Hour}),
+			WithArchive(Archive{Archiver: &recordingArchiver{}, After: 24 * time.Hour}),
+		},
+	} {
+		opts = append(opts, WithTarget("api", "https://api.example.com"))
+		if _, err := NewChecker(opts...); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	// transitions alone need no pruning store
+	if _, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithStore(struct{ Store }{NewMemoryStore()}),
+		WithRetention(Retention{Transitions: time.Hour}),
+	); err != nil {
+		t.Errorf("transitions retention: %v", err)
+	}
+}
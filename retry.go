@@ -0,0 +1,85 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// RetryCondition is a kind of failed check that is retried.
+type RetryCondition string
+
+const (
+	// RetryOnTimeout retries checks whose request timed out.
+	RetryOnTimeout RetryCondition = "timeout"
+	// RetryOn5xx retries checks answered with a 5xx status code.
+	RetryOn5xx RetryCondition = "5xx"
+	// RetryOnConnRefused retries checks whose connection was refused.
+	RetryOnConnRefused RetryCondition = "connection_refused"
+)
+
+// RetryConditions lists every RetryCondition, the default when a target sets
+// none.
+var RetryConditions = []RetryCondition{RetryOnTimeout, RetryOn5xx, RetryOnConnRefused}
+
+// WithRetries retries a failed check up to n times before its result is
+// recorded, so a single transient blip doesn't flip the target to unhealthy.
+// backoff is the wait before the first retry and doubles for each next one.
+// when no conditions are given timeouts, 5xx responses and refused
+// connections are all retried.
+func WithRetries(n int, backoff time.Duration, on ...RetryCondition) TargetOption {
+	return func(t *Target) {
+		t.Retries = n
+		t.RetryBackoff = backoff
+		t.RetryOn = on
+	}
+}
+
+// check checks the target, retrying failures as configured for it. the
+// result of the last attempt is returned.
+func (c *Checker) check(ctx context.Context, target Target) Result {
+	result, err := c.attempt(ctx, target)
+
+	backoff := target.RetryBackoff
+	for n := 1; n <= target.Retries && retryable(ctx, target, result, err); n++ {
+		c.logger.Debug("retrying check", "target", target.Name, "attempt", n+1, "error", result.Error)
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return result
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+		result, err = c.attempt(ctx, target)
+		result.Attempts = n + 1
+	}
+	return result
+}
+
+// retryable reports whether a failed attempt matches one of the target's
+// retry conditions. nothing is retried once ctx is done.
+func retryable(ctx context.Context, target Target, result Result, err error) bool {
+	if result.Status != StatusUnhealthy || ctx.Err() != nil {
+		return false
+	}
+
+	on := target.RetryOn
+	if len(on) == 0 {
+		on = RetryConditions
+	}
+	if err == nil {
+		return result.StatusCode >= 500 && slices.Contains(on, RetryOn5xx)
+	}
+
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &ne) && ne.Timeout() {
+		return slices.Contains(on, RetryOnTimeout)
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) && slices.Contains(on, RetryOnConnRefused)
+}
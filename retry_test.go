@@ -0,0 +1,88 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheck_RetriesTransientFailure(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("api", ts.URL, WithRetries(2, time.Millisecond)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.check(context.Background(), c.targets[0])
+	if r.Status != StatusHealthy || r.Attempts != 2 {
+		t.Errorf("status %q after %d attempts, want healthy after 2", r.Status, r.Attempts)
+	}
+}
+
+func TestCheck_RetryConditions(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("timeouts", ts.URL, WithRetries(3, 0, RetryOnTimeout)),
+		WithTarget("5xx", ts.URL, WithRetries(3, 0, RetryOn5xx)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Attempts != 0 || calls.Load() != 1 {
+		t.Errorf("503 retried on timeout only: %d calls", calls.Load())
+	}
+	calls.Store(0)
+	if r := c.check(context.Background(), c.targets[1]); r.Status != StatusUnhealthy || r.Attempts != 4 || calls.Load() != 4 {
+		t.Errorf("status %q after %d attempts and %d calls, want unhealthy after 4", r.Status, r.Attempts, calls.Load())
+	}
+}
+
+func TestCheck_RetriesRefusedConnection(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("refused", url, WithRetries(1, 0)),
+		WithTarget("404", url, WithRetries(1, 0, RetryOn5xx)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Attempts != 2 {
+		t.Errorf("refused connection attempts = %d, want 2", r.Attempts)
+	}
+	if r := c.check(context.Background(), c.targets[1]); r.Attempts != 0 {
+		t.Errorf("refused connection retried on 5xx only: %d attempts", r.Attempts)
+	}
+}
+
+func TestNew_InvalidRetries(t *testing.T) {
+	for _, opt := range []TargetOption{
+		WithRetries(-1, 0),
+		WithRetries(1, -time.Second),
+		WithRetries(1, 0, "4xx"),
+	} {
+		if _, err := NewChecker(WithTarget("api", "https://api.example.com", opt)); err == nil {
+			t.Errorf("%+v accepted", NewTarget("api", "https://api.example.com", opt))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package kenko
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+// RevocationMode selects how a target's certificate revocation status is verified.
+type RevocationMode int
+
+// Possible RevocationMode values.
+const (
+	RevocationOff RevocationMode = iota
+	// RevocationCheck uses a stapled OCSP response when the server sends one
+	// and otherwise queries the certificate's OCSP responder or CRL.
+	RevocationCheck
+	// RevocationRequireStaple also treats a missing OCSP staple as degraded.
+	RevocationRequireStaple
+)
+
+// Errors a RevocationChecker wraps to mark a target degraded. any other error
+// leaves the target's status untouched and records the revocation as unknown.
+var (
+	ErrCertRevoked = errors.New("certificate revoked")
+	ErrNoStaple    = errors.New("no stapled ocsp response")
+)
+
+// RevocationChecker verifies that the leaf certificate of a TLS connection has
+// not been revoked. the revocation sub-package provides an OCSP and CRL based
+// implementation.
+type RevocationChecker interface {
+	CheckRevocation(ctx context.Context, cs *tls.ConnectionState, requireStaple bool) error
+}
+
+// Revocation values recorded in TLSInfo.
+const (
+	revocationGood     = "good"
+	revocationRevoked  = "revoked"
+	revocationNoStaple = "no_staple"
+	revocationUnknown  = "unknown"
+)
+
+// checkRevocation records the revocation status of the target's certificate
+// and degrades an otherwise healthy result when it is revoked or unstapled.
+func (c *Checker) checkRevocation(ctx context.Context, target Target, cs *tls.ConnectionState, result *Result) {
+	err := c.revocation.CheckRevocation(ctx, cs, target.Revocation == RevocationRequireStaple)
+
+	var degraded bool
+	switch {
+	case err == nil:
+		result.TLS.Revocation = revocationGood
+	case errors.Is(err, ErrCertRevoked):
+		result.TLS.Revocation = revocationRevoked
+		degraded = true
+	case errors.Is(err, ErrNoStaple):
+		result.TLS.Revocation = revocationNoStaple
+		degraded = true
+	default:
+		result.TLS.Revocation = revocationUnknown
+		c.logger.Warn("revocation check failed", "target", target.Name, "error", err)
+	}
+
+	if degraded && result.Status == StatusHealthy {
+		result.Status = StatusDegraded
+		result.Error = fmt.Sprintf("revocation: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"golang.org/x/crypto/ocsp"
+)
+
+// maxResponseBytes caps OCSP responses and CRLs read from the network.
+const maxResponseBytes = 10 << 20
+
+// Option configures a Checker.
+type Option func(*Checker)
+
+// WithHTTPClient sets the client used for OCSP queries and CRL downloads
+// (default a client with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(ch *Checker) { ch.client = c }
+}
+
+// Checker implements kenko.RevocationChecker using stapled OCSP responses,
+// OCSP responders and CRL distribution points, in that order.
+type Checker struct {
+	client *http.Client
+
+	mu   sync.Mutex
+	crls map[string]*x509.RevocationList
+}
+
+// New creates a Checker.
+func New(opts ...Option) *Checker {
+	c := &Checker{
+		client: &http.Client{Timeout: 10 * time.Second},
+		crls:   make(map[string]*x509.RevocationList),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CheckRevocation reports whether the leaf certificate of cs has been revoked,
+// returning an error wrapping kenko.ErrCertRevoked if so. with requireStaple a
+// connection without a stapled OCSP response fails with kenko.ErrNoStaple.
+func (c *Checker) CheckRevocation(ctx context.Context, cs *tls.ConnectionState, requireStaple bool) error {
+	leaf, issuer, err := chain(cs)
+	if err != nil {
+		return err
+	}
+
+	if len(cs.OCSPResponse) > 0 {
+		return ocspStatus(cs.OCSPResponse, leaf, issuer)
+	}
+	if requireStaple {
+		return kenko.ErrNoStaple
+	}
+
+	switch {
+	case len(leaf.OCSPServer) > 0:
+		return c.queryOCSP(ctx, leaf.OCSPServer[0], leaf, issuer)
+	case len(leaf.CRLDistributionPoints) > 0:
+		return c.checkCRL(ctx, leaf.CRLDistributionPoints[0], leaf, issuer)
+	default:
+		return errors.New("revocation: certificate has no ocsp responder or crl distribution point")
+	}
+}
+
+// chain returns the leaf certificate and its issuer, preferring the verified
+// chain over the certificates sent by the peer.
+func chain(cs *tls.ConnectionState) (leaf, issuer *x509.Certificate, err error) {
+	certs := cs.PeerCertificates
+	if len(cs.VerifiedChains) > 0 {
+		certs = cs.VerifiedChains[0]
+	}
+	if len(certs) < 2 {
+		return nil, nil, errors.New("revocation: issuer certificate not in chain")
+	}
+	return certs[0], certs[1], nil
+}
+
+func ocspStatus(der []byte, leaf, issuer *x509.Certificate) error {
+	resp, err := ocsp.ParseResponseForCert(der, leaf, issuer)
+	if err != nil {
+		return fmt.Errorf("revocation: parse ocsp response: %w", err)
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("%w at %s", kenko.ErrCertRevoked, resp.RevokedAt.Format(time.RFC3339))
+	default:
+		return errors.New("revocation: ocsp status unknown")
+	}
+}
+
+func (c *Checker) queryOCSP(ctx context.Context, server string, leaf, issuer *x509.Certificate) error {
+	body, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return fmt.Errorf("revocation: create ocsp request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("revocation: ocsp request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	der, err := c.fetch(req)
+	if err != nil {
+		return err
+	}
+	return ocspStatus(der, leaf, issuer)
+}
+
+func (c *Checker) checkCRL(ctx context.Context, url string, leaf, issuer *x509.Certificate) error {
+	rl, err := c.crl(ctx, url, issuer)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range rl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+			return fmt.Errorf("%w at %s", kenko.ErrCertRevoked, entry.RevocationTime.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// crl returns the revocation list at url, reusing a cached copy until its
+// NextUpdate passes.
+func (c *Checker) crl(ctx context.Context, url string, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	c.mu.Lock()
+	rl, ok := c.crls[url]
+	c.mu.Unlock()
+	if ok && time.Now().Before(rl.NextUpdate) {
+		return rl, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("revocation: crl request: %w", err)
+	}
+
+	der, err := c.fetch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rl, err = x509.ParseRevocationList(der)
+	if err != nil {
+		return nil, fmt.Errorf("revocation: parse crl: %w", err)
+	}
+	if err := rl.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("revocation: crl signature: %w", err)
+	}
+
+	c.mu.Lock()
+	c.crls[url] = rl
+	c.mu.Unlock()
+	return rl, nil
+}
+
+func (c *Checker) fetch(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("revocation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("revocation: %s returned %d", req.URL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("revocation: read %s: %w", req.URL, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,199 @@
+package revocation
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"golang.org/x/crypto/ocsp"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kenko test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) leaf(t *testing.T, serial int64, ocspURL, crlURL string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if ocspURL != "" {
+		tmpl.OCSPServer = []string{ocspURL}
+	}
+	if crlURL != "" {
+		tmpl.CRLDistributionPoints = []string{crlURL}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func (ca *testCA) ocspResponse(t *testing.T, leaf *x509.Certificate, status int) []byte {
+	t.Helper()
+	der, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+		Status:       status,
+		SerialNumber: leaf.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+		RevokedAt:    time.Now().Add(-time.Minute),
+	}, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func state(leaf, issuer *x509.Certificate, staple []byte) *tls.ConnectionState {
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leaf, issuer},
+		OCSPResponse:     staple,
+	}
+}
+
+func TestCheckRevocation_Staple(t *testing.T) {
+	ca := newTestCA(t)
+	leaf := ca.leaf(t, 2, "", "")
+	c := New()
+
+	if err := c.CheckRevocation(context.Background(), state(leaf, ca.cert, ca.ocspResponse(t, leaf, ocsp.Good)), true); err != nil {
+		t.Errorf("good staple: err = %v, want nil", err)
+	}
+
+	err := c.CheckRevocation(context.Background(), state(leaf, ca.cert, ca.ocspResponse(t, leaf, ocsp.Revoked)), false)
+	if !errors.Is(err, kenko.ErrCertRevoked) {
+		t.Errorf("revoked staple: err = %v, want ErrCertRevoked", err)
+	}
+}
+
+func TestCheckRevocation_RequireStaple(t *testing.T) {
+	ca := newTestCA(t)
+	leaf := ca.leaf(t, 2, "http://ocsp.invalid", "")
+
+	err := New().CheckRevocation(context.Background(), state(leaf, ca.cert, nil), true)
+	if !errors.Is(err, kenko.ErrNoStaple) {
+		t.Errorf("err = %v, want ErrNoStaple", err)
+	}
+}
+
+func TestCheckRevocation_OCSPQuery(t *testing.T) {
+	ca := newTestCA(t)
+	var leaf *x509.Certificate
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil || req.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(ca.ocspResponse(t, leaf, ocsp.Revoked))
+	}))
+	defer srv.Close()
+
+	leaf = ca.leaf(t, 2, srv.URL, "")
+	err := New().CheckRevocation(context.Background(), state(leaf, ca.cert, nil), false)
+	if !errors.Is(err, kenko.ErrCertRevoked) {
+		t.Errorf("err = %v, want ErrCertRevoked", err)
+	}
+}
+
+func TestCheckRevocation_CRL(t *testing.T) {
+	ca := newTestCA(t)
+
+	var fetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:     big.NewInt(1),
+			ThisUpdate: time.Now().Add(-time.Minute),
+			NextUpdate: time.Now().Add(time.Hour),
+			RevokedCertificateEntries: []x509.RevocationListEntry{
+				{SerialNumber: big.NewInt(3), RevocationTime: time.Now().Add(-time.Minute)},
+			},
+		}, ca.cert, ca.key)
+		if err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write(der)
+	}))
+	defer srv.Close()
+
+	c := New()
+	good := ca.leaf(t, 2, "", srv.URL)
+	if err := c.CheckRevocation(context.Background(), state(good, ca.cert, nil), false); err != nil {
+		t.Errorf("good cert: err = %v, want nil", err)
+	}
+
+	revoked := ca.leaf(t, 3, "", srv.URL)
+	if err := c.CheckRevocation(context.Background(), state(revoked, ca.cert, nil), false); !errors.Is(err, kenko.ErrCertRevoked) {
+		t.Errorf("revoked cert: err = %v, want ErrCertRevoked", err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("crl fetched %d times, want 1", fetches)
+	}
+}
+
+func TestCheckRevocation_NoIssuer(t *testing.T) {
+	ca := newTestCA(t)
+	leaf := ca.leaf(t, 2, "", "")
+
+	cs := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}
+	err := New().CheckRevocation(context.Background(), cs, false)
+	if err == nil || errors.Is(err, kenko.ErrCertRevoked) {
+		t.Errorf("err = %v, want a non-revocation error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package kenko
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeRevocation struct {
+	err           error
+	requireStaple bool
+}
+
+func (f *fakeRevocation) CheckRevocation(_ context.Context, _ *tls.ConnectionState, requireStaple bool) error {
+	f.requireStaple = requireStaple
+	return f.err
+}
+
+func TestNewChecker_RevocationRequiresChecker(t *testing.T) {
+	_, err := NewChecker(WithTarget("api", "https://example.com", WithRevocation(RevocationCheck)))
+	if err == nil {
+		t.Fatal("expected error without a RevocationChecker")
+	}
+}
+
+func TestCheck_Revocation(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		mode       RevocationMode
+		err        error
+		wantStatus Status
+		wantRev    string
+	}{
+		{"good", RevocationCheck, nil, StatusHealthy, "good"},
+		{"revoked", RevocationCheck, fmt.Errorf("%w at now", ErrCertRevoked), StatusDegraded, "revoked"},
+		{"no staple", RevocationRequireStaple, ErrNoStaple, StatusDegraded, "no_staple"},
+		{"responder down", RevocationCheck, errors.New("timeout"), StatusHealthy, "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &fakeRevocation{err: tt.err}
+			c := &Checker{
+				client:     srv.Client(),
+				logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+				revocation: rc,
+			}
+
+			result := c.check(context.Background(), Target{Name: "api", URL: srv.URL, Revocation: tt.mode})
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.TLS == nil || result.TLS.Revocation != tt.wantRev {
+				t.Errorf("tls = %+v, want revocation %q", result.TLS, tt.wantRev)
+			}
+			if rc.requireStaple != (tt.mode == RevocationRequireStaple) {
+				t.Errorf("requireStaple = %v for mode %d", rc.requireStaple, tt.mode)
+			}
+		})
+	}
+}
+
+func TestCheck_RevocationSkippedForPlainHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := &Checker{
+		client:     srv.Client(),
+		logger:     slog.Default(),
+		revocation: &fakeRevocation{err: ErrCertRevoked},
+	}
+
+	result := c.check(context.Background(), Target{Name: "api", URL: srv.URL, Revocation: RevocationCheck})
+	if result.Status != StatusHealthy {
+		t.Errorf("status = %q, want %q", result.Status, StatusHealthy)
+	}
+}
@@ -0,0 +1,266 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrNoRollups is returned when the store does not keep rollups.
+var ErrNoRollups = errors.New("kenko: store does not keep rollups")
+
+// resolutions of the rollups WithRollups keeps.
+const (
+	FiveMinuteRollups = 5 * time.Minute
+	HourlyRollups     = time.Hour
+)
+
+const (
+	defaultRollupInterval = 5 * time.Minute
+	defaultFiveMinuteKeep = 14 * 24 * time.Hour
+	defaultHourlyKeep     = 400 * 24 * time.Hour
+
+	// rollupDelay holds back the newest bucket so results still on their
+	// way to the store, e.g. in the write-behind queue, are counted.
+	rollupDelay = time.Minute
+	// rollupChunk is how much raw history is read per query.
+	rollupChunk = 24 * time.Hour
+
+	// rollupStatePrefix prefixes the state key holding how far a target's
+	// history has been compacted.
+	rollupStatePrefix = "rollup:"
+)
+
+// Rollup summarizes a target's checks in one bucket of a rollup resolution.
+// checks during maintenance are left out.
+type Rollup struct {
+	Start  time.Time `json:"start"`
+	Checks int       `json:"checks"`
+	// Down is how many of the checks were unhealthy.
+	Down       int           `json:"down"`
+	MinLatency time.Duration `json:"min_latency"`
+	MaxLatency time.Duration `json:"max_latency"`
+	// TotalLatency is the sum of the checks' latencies.
+	TotalLatency time.Duration `json:"total_latency"`
+}
+
+// Uptime returns the fraction of the checks that were not unhealthy, or 1
+// without checks.
+func (r Rollup) Uptime() float64 {
+	if r.Checks == 0 {
+		return 1
+	}
+	return 1 - float64(r.Down)/float64(r.Checks)
+}
+
+// AvgLatency returns the mean latency of the checks.
+func (r Rollup) AvgLatency() time.Duration {
+	if r.Checks == 0 {
+		return 0
+	}
+	return r.TotalLatency / time.Duration(r.Checks)
+}
+
+// merge adds the checks counted in o.
+func (r *Rollup) merge(o Rollup) {
+	if o.Checks == 0 {
+		return
+	}
+	if r.Checks == 0 || o.MinLatency < r.MinLatency {
+		r.MinLatency = o.MinLatency
+	}
+	r.MaxLatency = max(r.MaxLatency, o.MaxLatency)
+	r.Checks += o.Checks
+	r.Down += o.Down
+	r.TotalLatency += o.TotalLatency
+}
+
+func (r *Rollup) add(res Result) {
+	down := 0
+	if res.Status == StatusUnhealthy {
+		down = 1
+	}
+	r.merge(Rollup{Checks: 1, Down: down, MinLatency: res.Latency, MaxLatency: res.Latency, TotalLatency: res.Latency})
+}
+
+// RollupStore is implemented by stores that keep rollups of each target's
+// history.
+type RollupStore interface {
+	// SaveRollups stores rollups of the resolution, replacing any with the
+	// same start.
+	SaveRollups(ctx context.Context, name string, resolution time.Duration, rollups []Rollup) error
+	// Rollups returns the target's rollups of the resolution starting
+	// between from and to, inclusive, oldest first. a zero bound leaves
+	// that side open.
+	Rollups(ctx context.Context, name string, resolution time.Duration, from, to time.Time) ([]Rollup, error)
+	// PruneRollups removes the rollups of the resolution of every target
+	// starting before before and reports how many were removed.
+	PruneRollups(ctx context.Context, resolution time.Duration, before time.Time) (int, error)
+}
+
+// Rollups configures the compaction of raw history into rollups.
+type Rollups struct {
+	// Interval between compaction runs (default 5m).
+	Interval time.Duration
+	// FiveMinute and Hourly are how long the rollups of each resolution
+	// are kept (default 14 days and 400 days).
+	FiveMinute time.Duration
+	Hourly     time.Duration
+}
+
+// WithRollups periodically compacts each target's stored results into 5m and
+// 1h rollups of uptime and min, average, and max latency, so long ranges can
+// be graphed without reading every raw result. it needs a store that
+// implements HistoryStore and RollupStore. history stored before the first
+// run is compacted back to the hourly retention.
+func WithRollups(r Rollups) Option {
+	return func(o *options) { o.rollups = &r }
+}
+
+func validateRollups(r *Rollups, store Store) error {
+	if r == nil {
+		return nil
+	}
+	if r.Interval < 0 || r.FiveMinute < 0 || r.Hourly < 0 {
+		return fmt.Errorf("kenko: rollup interval and retention must not be negative")
+	}
+	_, history := store.(HistoryStore)
+	_, rollups := store.(RollupStore)
+	if !history || !rollups {
+		return fmt.Errorf("kenko: rollups need a store that keeps history and rollups, got %T", store)
+	}
+	return nil
+}
+
+// Rollups returns the target's rollups of the resolution starting between
+// from and to, oldest first, or ErrNoRollups when the store keeps none.
+func (c *Checker) Rollups(ctx context.Context, name string, resolution time.Duration, from, to time.Time) ([]Rollup, error) {
+	rs, ok := c.store.(RollupStore)
+	if !ok {
+		return nil, ErrNoRollups
+	}
+	return rs.Rollups(ctx, name, resolution, from, to)
+}
+
+func (c *Checker) runRollups(ctx context.Context) {
+	ticker := time.NewTicker(cmp.Or(c.rollups.Interval, defaultRollupInterval))
+	defer ticker.Stop()
+
+	marks := make(map[string]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.life.stop:
+			return
+		case <-ticker.C:
+		}
+		c.compact(ctx, time.Now(), marks)
+	}
+}
+
+// compact rolls up every target's results up to the last complete bucket
+// and prunes expired rollups. marks holds how far each target has been
+// compacted.
+func (c *Checker) compact(ctx context.Context, now time.Time, marks map[string]time.Time) {
+	end := now.Add(-rollupDelay).UTC().Truncate(FiveMinuteRollups)
+	for _, t := range c.targetList() {
+		if err := c.compactTarget(ctx, t.Name, end, marks); err != nil {
+			c.logger.Warn("failed to compact history", "target", t.Name, "error", err)
+		}
+	}
+
+	rs := c.store.(RollupStore)
+	for resolution, keep := range map[time.Duration]time.Duration{
+		FiveMinuteRollups: cmp.Or(c.rollups.FiveMinute, defaultFiveMinuteKeep),
+		HourlyRollups:     cmp.Or(c.rollups.Hourly, defaultHourlyKeep),
+	} {
+		n, err := rs.PruneRollups(ctx, resolution, now.Add(-keep))
+		if err != nil {
+			c.logger.Warn("failed to prune rollups", "resolution", resolution, "error", err)
+		}
+		c.reportPruned("rollups", n)
+	}
+}
+
+// compactTarget rolls up the target's results from its mark to end a chunk
+// at a time, moving the mark past each chunk once its rollups are saved.
+func (c *Checker) compactTarget(ctx context.Context, name string, end time.Time, marks map[string]time.Time) error {
+	mark, ok := marks[name]
+	if !ok {
+		found, err := c.loadState(ctx, rollupStatePrefix+name, &mark)
+		if err != nil {
+			return err
+		}
+		if !found {
+			mark = end.Add(-cmp.Or(c.rollups.Hourly, defaultHourlyKeep)).Truncate(HourlyRollups)
+		}
+	}
+
+	hs := c.store.(HistoryStore)
+	rs := c.store.(RollupStore)
+	for mark.Before(end) {
+		chunkEnd := mark.Add(rollupChunk)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		results, err := hs.History(ctx, name, HistoryQuery{From: mark, To: chunkEnd.Add(-time.Nanosecond)})
+		if err != nil {
+			return err
+		}
+
+		buckets := make(map[time.Time]*Rollup)
+		for _, r := range results {
+			if r.Status == StatusMaintenance || r.Status == StatusPaused {
+				continue
+			}
+			start := r.CheckedAt.UTC().Truncate(FiveMinuteRollups)
+			if buckets[start] == nil {
+				buckets[start] = &Rollup{Start: start}
+			}
+			buckets[start].add(r)
+		}
+
+		if len(buckets) > 0 {
+			fine := make([]Rollup, 0, len(buckets))
+			hours := make(map[time.Time]bool)
+			for _, b := range buckets {
+				fine = append(fine, *b)
+				hours[b.Start.Truncate(HourlyRollups)] = true
+			}
+			slices.SortFunc(fine, func(a, b Rollup) int { return a.Start.Compare(b.Start) })
+			if err := rs.SaveRollups(ctx, name, FiveMinuteRollups, fine); err != nil {
+				return err
+			}
+
+			// hours are merged from their 5m rollups, so an hour split
+			// over two runs is rolled up whole
+			hourly := make([]Rollup, 0, len(hours))
+			for hour := range hours {
+				parts, err := rs.Rollups(ctx, name, FiveMinuteRollups, hour, hour.Add(HourlyRollups-FiveMinuteRollups))
+				if err != nil {
+					return err
+				}
+				h := Rollup{Start: hour}
+				for _, p := range parts {
+					h.merge(p)
+				}
+				hourly = append(hourly, h)
+			}
+			slices.SortFunc(hourly, func(a, b Rollup) int { return a.Start.Compare(b.Start) })
+			if err := rs.SaveRollups(ctx, name, HourlyRollups, hourly); err != nil {
+				return err
+			}
+		}
+
+		mark = chunkEnd
+		marks[name] = mark
+		if err := c.saveState(ctx, rollupStatePrefix+name, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	c, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithStore(store),
+		WithRollups(Rollups{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hour := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for _, r := range []Result{
+		{Status: StatusHealthy, Latency: 100 * time.Millisecond, CheckedAt: hour.Add(time.Minute)},
+		{Status: StatusUnhealthy, Latency: 300 * time.Millisecond, CheckedAt: hour.Add(2 * time.Minute)},
+		{Status: StatusMaintenance, Latency: time.Second, CheckedAt: hour.Add(3 * time.Minute)},
+		{Status: StatusHealthy, Latency: 200 * time.Millisecond, CheckedAt: hour.Add(6 * time.Minute)},
+	} {
+		r.Target = "api"
+		store.Set(ctx, "api", r)
+	}
+
+	marks := make(map[string]time.Time)
+	c.compact(ctx, hour.Add(10*time.Minute+rollupDelay), marks)
+
+	fine, _ := c.Rollups(ctx, "api", FiveMinuteRollups, time.Time{}, time.Time{})
+	if len(fine) != 2 {
+		t.Fatalf("5m rollups = %+v, want 2", fine)
+	}
+	if f := fine[0]; f.Checks != 2 || f.Down != 1 || f.MinLatency != 100*time.Millisecond || f.MaxLatency != 300*time.Millisecond || f.AvgLatency() != 200*time.Millisecond {
+		t.Errorf("first 5m rollup = %+v, want the two checks without maintenance", f)
+	}
+
+	// a later run adds to the same hour
+	store.Set(ctx, "api", Result{Target: "api", Status: StatusHealthy, Latency: 50 * time.Millisecond, CheckedAt: hour.Add(12 * time.Minute)})
+	c.compact(ctx, hour.Add(15*time.Minute+rollupDelay), marks)
+
+	hourly, _ := c.Rollups(ctx, "api", HourlyRollups, time.Time{}, time.Time{})
+	if len(hourly) != 1 {
+		t.Fatalf("1h rollups = %+v, want 1", hourly)
+	}
+	if h := hourly[0]; !h.Start.Equal(hour) || h.Checks != 4 || h.Down != 1 || h.MinLatency != 50*time.Millisecond || h.Uptime() != 0.75 {
+		t.Errorf("hourly rollup = %+v, want the whole hour", h)
+	}
+
+	// the mark survives a restart, so nothing is counted twice
+	var mark time.Time
+	if ok, _ := c.loadState(ctx, rollupStatePrefix+"api", &mark); !ok || !mark.Equal(hour.Add(15*time.Minute)) {
+		t.Errorf("saved mark = %v, %v", mark, ok)
+	}
+	c.compact(ctx, hour.Add(15*time.Minute+rollupDelay), make(map[string]time.Time))
+	if hourly, _ := c.Rollups(ctx, "api", HourlyRollups, time.Time{}, time.Time{}); hourly[0].Checks != 4 {
+		t.Errorf("hourly after restart = %+v, want 4 checks", hourly[0])
+	}
+}
+
+func TestCompact_PrunesRollups(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	c, _ := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithStore(store),
+		WithRollups(Rollups{FiveMinute: time.Hour}),
+	)
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	store.SaveRollups(ctx, "api", FiveMinuteRollups, []Rollup{{Start: now.Add(-2 * time.Hour), Checks: 1}, {Start: now.Add(-time.Minute), Checks: 1}})
+	store.SaveRollups(ctx, "api", HourlyRollups, []Rollup{{Start: now.Add(-2 * time.Hour), Checks: 1}})
+	c.compact(ctx, now, map[string]time.Time{"api": now})
+
+	if fine, _ := store.Rollups(ctx, "api", FiveMinuteRollups, time.Time{}, time.Time{}); len(fine) != 1 {
+		t.Errorf("5m rollups = %+v, want the old one pruned", fine)
+	}
+	if hourly, _ := store.Rollups(ctx, "api", HourlyRollups, time.Time{}, time.Time{}); len(hourly) != 1 {
+		t.Errorf("1h rollups = %+v, want them kept", hourly)
+	}
+}
+
+func TestValidateRollups(t *testing.T) {
+	if _, err := NewChecker(WithStore(NewMemoryStore()), WithRollups(Rollups{Hourly: -time.Hour})); err == nil {
+		t.Error("negative retention accepted")
+	}
+	if _, err := NewChecker(WithStore(resultsOnlyStore{}), WithRollups(Rollups{})); err == nil {
+		t.Error("store without rollups accepted")
+	}
+}
+
+func TestHandleRollups(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	c, _ := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(store))
+	start := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	store.SaveRollups(ctx, "api", HourlyRollups, []Rollup{
+		{Start: start.Add(-100 * 24 * time.Hour), Checks: 1},
+		{Start: start, Checks: 4, Down: 1, MinLatency: 10 * time.Millisecond, MaxLatency: 40 * time.Millisecond, TotalLatency: 100 * time.Millisecond},
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/targets/{name}/history/rollups", HandleRollups(c))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history/rollups", nil))
+	var resp rollupsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution != "1h" || len(resp.Rollups) != 1 {
+		t.Fatalf("response = %+v, want the last 90 days of hourly rollups", resp)
+	}
+	if r := resp.Rollups[0]; r.Uptime != 0.75 || r.AvgLatencyMS != 25 || r.MaxLatencyMS != 40 {
+		t.Errorf("rollup = %+v", r)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/history/rollups?resolution=1d", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
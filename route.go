@@ -0,0 +1,136 @@
+package kenko
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Route sends the status changes it matches to some of the notifiers, e.g.
+// failures of the payments group to a pager and everything else to chat.
+// a change matches when its target is one of Targets, is in one of Groups,
+// has one of Severities, has every one of the Labels selectors ("key=value"
+// or "key"), and has one of Statuses; empty criteria match any change. a
+// failure's later changes, its recovery included, are also sent to the
+// notifiers the failure was routed to, so an unhealthy-only route hears of
+// the target going degraded and then healthy. latency alerts only match
+// routes without Statuses.
+type Route struct {
+	Targets    []string
+	Groups     []string
+	Severities []Severity
+	Labels     []string
+	Statuses   []Status
+
+	// Notifiers names the notifiers, registered with WithNotifier, matching
+	// changes are sent to.
+	Notifiers []string
+	// Continue keeps trying the routes after this one when it matches, so a
+	// change can reach the notifiers of several routes.
+	Continue bool
+	// Escalation sends matching failures to more notifiers the longer they
+	// last. a notifier escalated to is sent the failure's later changes and
+	// its recovery.
+	Escalation []EscalationStep
+}
+
+// WithRoute adds a notification route. routes are tried in the order they
+// were added and a change is sent to the notifiers of the first one it
+// matches, and of any further ones while the matching routes continue. with
+// no routes, every notifier is sent every change; with routes, changes no
+// route matches are not sent, so a route without criteria makes a default.
+func WithRoute(r Route) Option {
+	return func(o *options) { o.routes = append(o.routes, r) }
+}
+
+func validateRoutes(routes []Route, notifiers map[string]Notifier) error {
+	for i, r := range routes {
+		if len(r.Notifiers) == 0 {
+			return fmt.Errorf("kenko: route %d: no notifiers", i)
+		}
+		for _, name := range r.Notifiers {
+			if _, ok := notifiers[name]; !ok {
+				return fmt.Errorf("kenko: route %d: unknown notifier %q", i, name)
+			}
+		}
+		if err := validateEscalation(i, r.Escalation, notifiers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Route) matches(n Notification) bool {
+	if len(r.Targets) > 0 && !slices.Contains(r.Targets, n.Target) {
+		return false
+	}
+	if len(r.Groups) > 0 && !slices.Contains(r.Groups, n.Result.Group) {
+		return false
+	}
+	if len(r.Severities) > 0 && !slices.Contains(r.Severities, n.Result.Severity) {
+		return false
+	}
+	if !matchLabels(n.Result.Labels, r.Labels) {
+		return false
+	}
+	if len(r.Statuses) > 0 {
+		if n.Slow != nil {
+			return false
+		}
+		status := n.Status
+		if status == StatusHealthy {
+			status = n.Previous
+		}
+		return slices.Contains(r.Statuses, status)
+	}
+	return true
+}
+
+// route returns the names of the notifiers n is routed to.
+func route(routes []Route, n Notification) map[string]bool {
+	names := make(map[string]bool)
+	for _, r := range routes {
+		if !r.matches(n) {
+			continue
+		}
+		for _, name := range r.Notifiers {
+			names[name] = true
+		}
+		if !r.Continue {
+			break
+		}
+	}
+	return names
+}
+
+// routedFailures tracks the notifiers each failing target's changes were
+// routed to, so the failure's later changes and its recovery reach them even
+// when no route matches those.
+type routedFailures struct {
+	mu   sync.Mutex
+	open map[string]map[string]bool
+}
+
+// track adds the notifiers n's target's failure was routed to before to
+// names, and records names for the failure, ending it when n is a recovery.
+// latency alerts are left alone as they are not part of the failure.
+func (f *routedFailures) track(n Notification, names map[string]bool) {
+	if n.Slow != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	maps.Copy(names, f.open[n.Target])
+	if n.Status == StatusHealthy {
+		delete(f.open, n.Target)
+		return
+	}
+	if len(names) == 0 {
+		return
+	}
+	if f.open == nil {
+		f.open = make(map[string]map[string]bool)
+	}
+	f.open[n.Target] = maps.Clone(names)
+}
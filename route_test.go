@@ -0,0 +1,118 @@
+package kenko
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoute_Matches(t *testing.T) {
+	failure := Notification{
+		Target:   "checkout",
+		Previous: StatusHealthy,
+		Status:   StatusUnhealthy,
+		Result:   Result{Group: "payments", Severity: SeverityCritical, Labels: map[string]string{"team": "payments", "env": "prod"}},
+	}
+	recovery := failure
+	recovery.Previous, recovery.Status = StatusUnhealthy, StatusHealthy
+
+	tests := map[string]struct {
+		route Route
+		n     Notification
+		want  bool
+	}{
+		"empty":           {Route{}, failure, true},
+		"target":          {Route{Targets: []string{"checkout"}}, failure, true},
+		"other target":    {Route{Targets: []string{"search"}}, failure, false},
+		"group":           {Route{Groups: []string{"payments"}}, failure, true},
+		"severity":        {Route{Severities: []Severity{SeverityCritical}}, failure, true},
+		"other severity":  {Route{Severities: []Severity{SeverityWarning, SeverityInfo}}, failure, false},
+		"labels":          {Route{Labels: []string{"team=Payments", "env"}}, failure, true},
+		"missing label":   {Route{Labels: []string{"team=payments", "tier=1"}}, failure, false},
+		"status":          {Route{Statuses: []Status{StatusUnhealthy}}, failure, true},
+		"other status":    {Route{Statuses: []Status{StatusDegraded}}, failure, false},
+		"recovery":        {Route{Statuses: []Status{StatusUnhealthy}}, recovery, true},
+		"recovery other":  {Route{Statuses: []Status{StatusDegraded}}, recovery, false},
+		"all criteria":    {Route{Targets: []string{"checkout"}, Groups: []string{"payments"}, Statuses: []Status{StatusUnhealthy}}, failure, true},
+		"one of criteria": {Route{Targets: []string{"checkout"}, Groups: []string{"search"}}, failure, false},
+	}
+	for name, tt := range tests {
+		if got := tt.route.matches(tt.n); got != tt.want {
+			t.Errorf("%s: matches = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	notifiers := map[string]Notifier{"pager": &recordingNotifier{}}
+	for _, tt := range []struct {
+		route Route
+		want  string
+	}{
+		{Route{Notifiers: []string{"pager"}}, ""},
+		{Route{Groups: []string{"payments"}}, "no notifiers"},
+		{Route{Notifiers: []string{"pager", "chat"}}, `unknown notifier "chat"`},
+	} {
+		err := validateRoutes([]Route{tt.route}, notifiers)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%+v: err = %v, want %q", tt.route, err, tt.want)
+		}
+	}
+}
+
+func TestDispatcher_Routes(t *testing.T) {
+	pager, chat, audit := &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}
+	d := newDispatcher(map[string]Notifier{"pager": pager, "chat": chat, "audit": audit}, NotifyRetry{}, slog.New(slog.NewTextHandler(io.Discard, nil)), func(Delivery) {})
+	d.routes = []Route{
+		{Labels: []string{"env=prod"}, Notifiers: []string{"audit"}, Continue: true},
+		{Groups: []string{"payments"}, Notifiers: []string{"pager"}},
+		{Notifiers: []string{"chat"}},
+	}
+
+	d.enqueue(Notification{Target: "checkout", Result: Result{Group: "payments", Labels: map[string]string{"env": "prod"}}})
+	d.enqueue(Notification{Target: "wiki"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.run(ctx)
+
+	if len(audit.sent) != 1 || audit.sent[0].Target != "checkout" {
+		t.Errorf("audit sent %+v, want checkout", audit.sent)
+	}
+	if len(pager.sent) != 1 || pager.sent[0].Target != "checkout" {
+		t.Errorf("pager sent %+v, want checkout", pager.sent)
+	}
+	if len(chat.sent) != 1 || chat.sent[0].Target != "wiki" {
+		t.Errorf("chat sent %+v, want only the unmatched wiki", chat.sent)
+	}
+}
+
+func TestDispatcher_RoutesFailureChanges(t *testing.T) {
+	pager, chat := &recordingNotifier{}, &recordingNotifier{}
+	d := newDispatcher(map[string]Notifier{"pager": pager, "chat": chat}, NotifyRetry{}, slog.New(slog.NewTextHandler(io.Discard, nil)), func(Delivery) {})
+	d.routes = []Route{
+		{Statuses: []Status{StatusUnhealthy}, Notifiers: []string{"pager"}},
+		{Statuses: []Status{StatusDegraded}, Notifiers: []string{"chat"}},
+	}
+
+	d.enqueue(Notification{Target: "checkout", Previous: StatusHealthy, Status: StatusUnhealthy})
+	d.enqueue(Notification{Target: "checkout", Previous: StatusUnhealthy, Status: StatusDegraded})
+	d.enqueue(Notification{Target: "checkout", Previous: StatusDegraded, Status: StatusHealthy})
+	d.enqueue(Notification{Target: "checkout", Previous: StatusHealthy, Status: StatusDegraded})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.run(ctx)
+
+	var got []Status
+	for _, n := range pager.sent {
+		got = append(got, n.Status)
+	}
+	if want := []Status{StatusUnhealthy, StatusDegraded, StatusHealthy}; !slices.Equal(got, want) {
+		t.Errorf("pager sent %v, want the failure, its later change and its recovery %v", got, want)
+	}
+	if len(chat.sent) != 3 {
+		t.Errorf("chat sent %d notifications, want 3", len(chat.sent))
+	}
+}
@@ -0,0 +1,93 @@
+// Package s3archive is a kenko Archiver that uploads old results to S3 or an
+// S3-compatible object store as gzip-compressed NDJSON, one object per target
+// and archive run, e.g. kenko/api/2026/03/13/20260313T120000Z-20260313T125930Z.ndjson.gz.
+package s3archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	defaultPrefix = "kenko/"
+	keyTimeFormat = "20060102T150405Z"
+)
+
+// PutObjectAPI is the part of the S3 client the archiver uses. *s3.Client
+// implements it.
+type PutObjectAPI interface {
+	PutObject(ctx context.Context, in *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// Option configures an Archiver.
+type Option func(*Archiver)
+
+// WithPrefix sets the prefix of the object keys (default "kenko/").
+func WithPrefix(prefix string) Option {
+	return func(a *Archiver) { a.prefix = prefix }
+}
+
+// Archiver uploads results to a bucket.
+type Archiver struct {
+	client PutObjectAPI
+	bucket string
+	prefix string
+}
+
+// New creates an Archiver writing to bucket through client. for an
+// S3-compatible store, point the client at it with its BaseEndpoint option
+// or AWS_ENDPOINT_URL.
+func New(client PutObjectAPI, bucket string, opts ...Option) *Archiver {
+	a := &Archiver{client: client, bucket: bucket, prefix: defaultPrefix}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Archive uploads the target's results, oldest first, as one object.
+func (a *Archiver) Archive(ctx context.Context, name string, results []kenko.Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("s3archive: marshal: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("s3archive: compress: %w", err)
+	}
+
+	key := a.key(name, results)
+	_, err := a.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:          aws.String(a.bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(buf.Bytes()),
+		ContentType:     aws.String("application/x-ndjson"),
+		ContentEncoding: aws.String("gzip"),
+	})
+	if err != nil {
+		return fmt.Errorf("s3archive: put %s: %w", key, err)
+	}
+	return nil
+}
+
+// key names the object holding results, by target and the check time of its
+// first and last result, so objects list in time order.
+func (a *Archiver) key(name string, results []kenko.Result) string {
+	first := results[0].CheckedAt.UTC()
+	last := results[len(results)-1].CheckedAt.UTC()
+	return fmt.Sprintf("%s%s/%s/%s-%s.ndjson.gz", a.prefix, name, first.Format("2006/01/02"), first.Format(keyTimeFormat), last.Format(keyTimeFormat))
+}
@@ -0,0 +1,82 @@
+package s3archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type fakeS3 struct {
+	puts []*s3.PutObjectInput
+	body []byte
+	err  error
+}
+
+func (f *fakeS3) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.puts = append(f.puts, in)
+	f.body, _ = io.ReadAll(in.Body)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestArchive(t *testing.T) {
+	fake := &fakeS3{}
+	a := New(fake, "evidence", WithPrefix("prod/"))
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	results := []kenko.Result{
+		{Target: "api", Status: kenko.StatusHealthy, CheckedAt: start},
+		{Target: "api", Status: kenko.StatusUnhealthy, CheckedAt: start.Add(30 * time.Second)},
+	}
+
+	if err := a.Archive(context.Background(), "api", results); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.puts) != 1 {
+		t.Fatalf("puts = %d, want 1", len(fake.puts))
+	}
+	put := fake.puts[0]
+	if *put.Bucket != "evidence" || *put.Key != "prod/api/2026/03/13/20260313T120000Z-20260313T120030Z.ndjson.gz" {
+		t.Errorf("object = %s/%s", *put.Bucket, *put.Key)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(fake.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []kenko.Result
+	sc := bufio.NewScanner(zr)
+	for sc.Scan() {
+		var r kenko.Result
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if len(got) != 2 || got[1].Status != kenko.StatusUnhealthy {
+		t.Errorf("archived = %+v", got)
+	}
+}
+
+func TestArchive_Errors(t *testing.T) {
+	fake := &fakeS3{err: errors.New("access denied")}
+	a := New(fake, "evidence")
+
+	if err := a.Archive(context.Background(), "api", nil); err != nil {
+		t.Errorf("empty archive err = %v", err)
+	}
+	err := a.Archive(context.Background(), "api", []kenko.Result{{Target: "api", CheckedAt: time.Now()}})
+	if err == nil || !errors.Is(err, fake.err) {
+		t.Errorf("err = %v, want the put error", err)
+	}
+}
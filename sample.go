@@ -0,0 +1,45 @@
+package kenko
+
+import "sync"
+
+// sampler decides which results of sampled targets are written to the store.
+// failures, status transitions, and the first result are always kept; of the
+// healthy results in between only every Nth is.
+type sampler struct {
+	mu    sync.Mutex
+	state map[string]*sampleState
+}
+
+type sampleState struct {
+	last    Status
+	skipped int
+}
+
+func (s *sampler) keep(target Target, result Result) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		s.state = make(map[string]*sampleState)
+	}
+	st, seen := s.state[target.Name]
+	if !seen {
+		st = &sampleState{}
+		s.state[target.Name] = st
+	}
+
+	transition := !seen || st.last != result.Status
+	st.last = result.Status
+
+	if target.SampleEvery <= 1 || transition || result.Status != StatusHealthy {
+		st.skipped = 0
+		return true
+	}
+
+	st.skipped++
+	if st.skipped >= target.SampleEvery {
+		st.skipped = 0
+		return true
+	}
+	return false
+}
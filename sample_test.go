@@ -0,0 +1,52 @@
+package kenko
+
+import "testing"
+
+func TestSampler_KeepsEveryNthHealthy(t *testing.T) {
+	var s sampler
+	target := Target{Name: "api", SampleEvery: 3}
+
+	var kept []bool
+	for range 7 {
+		kept = append(kept, s.keep(target, Result{Status: StatusHealthy}))
+	}
+
+	want := []bool{true, false, false, true, false, false, true}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Fatalf("kept = %v, want %v", kept, want)
+		}
+	}
+}
+
+func TestSampler_AlwaysKeepsFailuresAndTransitions(t *testing.T) {
+	var s sampler
+	target := Target{Name: "api", SampleEvery: 100}
+
+	seq := []struct {
+		status Status
+		want   bool
+	}{
+		{StatusHealthy, true},   // first result
+		{StatusHealthy, false},  // sampled out
+		{StatusUnhealthy, true}, // transition
+		{StatusUnhealthy, true}, // failure
+		{StatusHealthy, true},   // recovery
+		{StatusHealthy, false},  // sampled out
+	}
+
+	for i, step := range seq {
+		if got := s.keep(target, Result{Status: step.status}); got != step.want {
+			t.Errorf("step %d (%s): keep = %v, want %v", i, step.status, got, step.want)
+		}
+	}
+}
+
+func TestSampler_DisabledKeepsAll(t *testing.T) {
+	var s sampler
+	for range 5 {
+		if !s.keep(Target{Name: "api"}, Result{Status: StatusHealthy}) {
+			t.Fatal("expected every result to be kept without sampling")
+		}
+	}
+}
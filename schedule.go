@@ -0,0 +1,211 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// schedule tracks when each target is next due, so targets with their own
+// Interval are checked on their own cadence.
+type schedule struct {
+	mu      sync.Mutex
+	next    map[string]time.Time
+	running map[string]bool
+	wg      sync.WaitGroup
+
+	// pending holds the targets still awaiting their first check while the
+	// warm-up wave is in progress.
+	pending map[string]bool
+
+	// wake is signalled when the targets change, so new ones are checked
+	// without waiting for the next due target.
+	wake chan struct{}
+}
+
+func newSchedule() *schedule {
+	return &schedule{
+		next:    make(map[string]time.Time),
+		running: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// checkInterval returns the target's interval, or the checker-wide one.
+func (c *Checker) checkInterval(t Target) time.Duration {
+	return cmp.Or(t.Interval, c.interval)
+}
+
+// nextCheck returns when the target is next due after now: the next time
+// matching its schedule, or one interval later.
+func (c *Checker) nextCheck(t Target, now time.Time) time.Time {
+	if t.cron != nil {
+		return t.cron.next(now)
+	}
+	return now.Add(c.checkInterval(t))
+}
+
+// parseSchedules parses the cron schedules of targets.
+func parseSchedules(targets []Target) error {
+	for i := range targets {
+		t := &targets[i]
+		if t.Schedule == "" {
+			t.cron = nil
+			continue
+		}
+		if t.Interval > 0 {
+			return fmt.Errorf("kenko: target %q cannot have both a schedule and an interval", t.Name)
+		}
+		cron, err := parseCron(t.Schedule)
+		if err != nil {
+			return fmt.Errorf("kenko: target %q: %w", t.Name, err)
+		}
+		t.cron = cron
+	}
+	return nil
+}
+
+// poke wakes the check loop without blocking.
+func (s *schedule) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// due returns the targets whose next check is at or before now and are not
+// still being checked, and moves them on to their next check. targets never
+// seen before are due immediately, unless they have a schedule and wait for
+// its first match.
+func (c *Checker) due(targets []Target, now time.Time) []Target {
+	s := c.schedule
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Target
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.Name] = true
+		next, ok := s.next[t.Name]
+		if ok && next.After(now) {
+			continue
+		}
+		s.next[t.Name] = c.nextCheck(t, now)
+		if !ok && t.cron != nil {
+			continue
+		}
+		if s.running[t.Name] {
+			// the previous check overran its interval; skip this one
+			continue
+		}
+		out = append(out, t)
+	}
+	for name := range s.next {
+		if !seen[name] {
+			delete(s.next, name)
+		}
+	}
+	if s.pending != nil {
+		for name := range s.pending {
+			if !seen[name] {
+				delete(s.pending, name)
+			}
+		}
+		if len(s.pending) == 0 {
+			s.pending = nil
+			c.ready.Store(true)
+		}
+	}
+	return out
+}
+
+// stagger spreads the first check of each target evenly over the warm-up
+// period, so a restart with many targets doesn't send them all at once.
+// scheduled targets wait for their first match instead and don't hold up
+// readiness.
+func (c *Checker) stagger(targets []Target, now time.Time) {
+	s := c.schedule
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var staggered []Target
+	for _, t := range targets {
+		if t.cron != nil {
+			s.next[t.Name] = t.cron.next(now)
+			continue
+		}
+		staggered = append(staggered, t)
+	}
+
+	s.pending = make(map[string]bool, len(staggered))
+	for i, t := range staggered {
+		s.next[t.Name] = now.Add(c.warmup * time.Duration(i) / time.Duration(len(staggered)))
+		s.pending[t.Name] = true
+	}
+}
+
+// checked marks the target's first check done, making the checker ready once
+// the warm-up wave has reached every target.
+func (c *Checker) checked(ctx context.Context, name string) {
+	s := c.schedule
+	s.mu.Lock()
+	if s.pending == nil || !s.pending[name] {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.pending, name)
+	last := len(s.pending) == 0
+	if last {
+		s.pending = nil
+	}
+	s.mu.Unlock()
+
+	if last {
+		if c.writer != nil {
+			c.writer.wait(ctx)
+		}
+		c.ready.Store(true)
+		c.logger.Info("warm-up complete")
+	}
+}
+
+// untilNext returns how long until the earliest target is due.
+func (c *Checker) untilNext(targets []Target, now time.Time) time.Duration {
+	s := c.schedule
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := c.interval
+	for _, t := range targets {
+		next, ok := s.next[t.Name]
+		if !ok {
+			return 0
+		}
+		wait = min(wait, next.Sub(now))
+	}
+	return max(wait, 0)
+}
+
+// start checks each target in the background, tracking it as running until
+// its check completes.
+func (c *Checker) start(ctx context.Context, targets []Target) {
+	s := c.schedule
+	for _, t := range targets {
+		s.mu.Lock()
+		s.running[t.Name] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			c.checkTarget(ctx, t)
+
+			s.mu.Lock()
+			delete(s.running, t.Name)
+			s.mu.Unlock()
+			c.checked(ctx, t.Name)
+		}()
+	}
+}
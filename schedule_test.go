@@ -0,0 +1,262 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_PerTargetInterval(t *testing.T) {
+	var fast, slow atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fast" {
+			fast.Add(1)
+		} else {
+			slow.Add(1)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("fast", ts.URL+"/fast", WithCheckInterval(20*time.Millisecond)),
+		WithTarget("slow", ts.URL+"/slow"),
+		WithInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for fast.Load() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("fast target checked %d times", fast.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if n := slow.Load(); n != 1 {
+		t.Errorf("slow target checked %d times, want 1", n)
+	}
+}
+
+func TestRun_AddedTargetCheckedImmediately(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("api", ts.URL), WithInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	for !c.Ready() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := c.SetTargets(ctx, []Target{NewTarget("api", ts.URL), NewTarget("web", ts.URL)}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if results, _ := c.Results(); results["web"].Target == "web" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("added target was not checked")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDue_SkipsRunningTarget(t *testing.T) {
+	c := testChecker()
+	c.interval = time.Minute
+	targets := []Target{{Name: "api"}}
+	now := time.Now()
+
+	if got := c.due(targets, now); len(got) != 1 {
+		t.Fatalf("first due = %d targets, want 1", len(got))
+	}
+	if got := c.due(targets, now.Add(time.Second)); len(got) != 0 {
+		t.Fatalf("due before interval = %d targets, want 0", len(got))
+	}
+
+	c.schedule.running["api"] = true
+	if got := c.due(targets, now.Add(time.Minute)); len(got) != 0 {
+		t.Errorf("running target was due again")
+	}
+	if wait := c.untilNext(targets, now.Add(time.Minute)); wait != time.Minute {
+		t.Errorf("untilNext = %s, want 1m", wait)
+	}
+}
+
+func TestCheck_TargetTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("api", ts.URL, WithCheckTimeout(20*time.Millisecond)),
+		WithTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+	if result.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want unhealthy after target timeout", result.Status)
+	}
+}
+
+func TestRun_Warmup(t *testing.T) {
+	var mu sync.Mutex
+	var seen []time.Time
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, time.Now())
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("a", ts.URL),
+		WithTarget("b", ts.URL),
+		WithTarget("c", ts.URL),
+		WithTarget("d", ts.URL),
+		WithInterval(time.Hour),
+		WithWarmup(200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatal("checker never became ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 4 {
+		t.Fatalf("got %d checks, want 4", len(seen))
+	}
+	if spread := seen[3].Sub(start); spread < 150*time.Millisecond {
+		t.Errorf("last first check after %s, want it spread over the warm-up", spread)
+	}
+}
+
+func TestStagger(t *testing.T) {
+	c := testChecker()
+	c.interval = time.Hour
+	c.warmup = time.Minute
+	targets := []Target{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	now := time.Now()
+
+	c.stagger(targets, now)
+	for i, want := range []time.Duration{0, 15 * time.Second, 30 * time.Second, 45 * time.Second} {
+		if got := c.schedule.next[targets[i].Name].Sub(now); got != want {
+			t.Errorf("%s first check at +%s, want +%s", targets[i].Name, got, want)
+		}
+	}
+
+	if got := c.due(targets, now.Add(20*time.Second)); len(got) != 2 {
+		t.Errorf("due after 20s = %d targets, want 2", len(got))
+	}
+
+	// removing the unchecked targets ends the warm-up
+	c.due(targets[:2], now.Add(21*time.Second))
+	c.checked(context.Background(), "a")
+	if c.Ready() {
+		t.Fatal("ready before every target was checked")
+	}
+	c.checked(context.Background(), "b")
+	if !c.Ready() {
+		t.Error("not ready after every remaining target was checked")
+	}
+}
+
+func TestDue_Schedule(t *testing.T) {
+	c := testChecker()
+	c.interval = time.Minute
+	targets := []Target{{Name: "batch", Schedule: "CRON_TZ=UTC 0 2 * * *"}, {Name: "api"}}
+	if err := parseSchedules(targets); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 13, 1, 0, 0, 0, time.UTC)
+
+	// a new scheduled target waits for its first match
+	if got := c.due(targets, now); len(got) != 1 || got[0].Name != "api" {
+		t.Errorf("due at 01:00 = %v, want only api", got)
+	}
+	if next := c.schedule.next["batch"]; !next.Equal(time.Date(2026, 3, 13, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("batch next check at %s, want 02:00", next)
+	}
+
+	at := time.Date(2026, 3, 13, 2, 0, 0, 0, time.UTC)
+	if got := c.due(targets, at); len(got) != 2 {
+		t.Errorf("due at 02:00 = %d targets, want 2", len(got))
+	}
+	if next := c.schedule.next["batch"]; !next.Equal(at.AddDate(0, 0, 1)) {
+		t.Errorf("batch next check at %s, want the next day", next)
+	}
+}
+
+func TestStagger_Schedule(t *testing.T) {
+	c := testChecker()
+	c.interval = time.Hour
+	c.warmup = time.Minute
+	targets := []Target{{Name: "a"}, {Name: "batch", Schedule: "CRON_TZ=UTC 0 2 * * *"}, {Name: "b"}}
+	if err := parseSchedules(targets); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 13, 1, 0, 0, 0, time.UTC)
+
+	c.stagger(targets, now)
+	if got := c.schedule.next["b"].Sub(now); got != 30*time.Second {
+		t.Errorf("b first check at +%s, want +30s", got)
+	}
+	if c.schedule.pending["batch"] {
+		t.Error("scheduled target holds up the warm-up")
+	}
+}
+
+func TestNew_ScheduleAndInterval(t *testing.T) {
+	if _, err := NewChecker(WithTarget("api", "https://api.example.com",
+		WithSchedule("*/5 * * * *"), WithCheckInterval(time.Minute))); err == nil {
+		t.Error("schedule with interval accepted")
+	}
+	if _, err := NewChecker(WithTarget("api", "https://api.example.com", WithSchedule("every 5m"))); err == nil {
+		t.Error("bad schedule accepted")
+	}
+}
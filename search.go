@@ -0,0 +1,87 @@
+package kenko
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SearchResult is a target matched by Search with its latest result. Result
+// has StatusUnknown when the target has not been checked yet.
+type SearchResult struct {
+	Target Target
+	Result Result
+}
+
+// Search returns the targets matching query, sorted by name. query is a list
+// of whitespace-separated terms that must all match: "tag:x" matches a tag
+// exactly, "group:x" the target's group, "severity:x" its severity,
+// "label:k=v" a label value (or "label:k" any value), and any other term is a case-insensitive substring of
+// the target's name, URL, tags, or status page name. when statuses are given
+// only targets whose latest result has one of them are returned.
+func (c *Checker) Search(query string, statuses ...Status) ([]SearchResult, error) {
+	results, err := c.Results()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	display := make(map[string]string, len(c.statusPage.Components))
+	for _, comp := range c.statusPage.Components {
+		display[comp.Target] = comp.Name
+	}
+
+	var out []SearchResult
+	for _, t := range c.targetList() {
+		if !matchTarget(t, display[t.Name], terms) {
+			continue
+		}
+
+		r, ok := results[t.Name]
+		if !ok {
+			r = Result{Target: t.Name, URL: t.URL, Status: StatusUnknown}
+		}
+		if len(statuses) > 0 && !slices.Contains(statuses, r.Status) {
+			continue
+		}
+		out = append(out, SearchResult{Target: t, Result: r})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Target.Name < out[j].Target.Name })
+	return out, nil
+}
+
+func matchTarget(t Target, display string, terms []string) bool {
+	for _, term := range terms {
+		if tag, ok := strings.CutPrefix(term, "tag:"); ok {
+			if !slices.ContainsFunc(t.Tags, func(s string) bool { return strings.EqualFold(s, tag) }) {
+				return false
+			}
+			continue
+		}
+		if sel, ok := strings.CutPrefix(term, "label:"); ok {
+			if !matchLabel(t.Labels, sel) {
+				return false
+			}
+			continue
+		}
+		if group, ok := strings.CutPrefix(term, "group:"); ok {
+			if !strings.EqualFold(t.Group, group) {
+				return false
+			}
+			continue
+		}
+		if severity, ok := strings.CutPrefix(term, "severity:"); ok {
+			if !strings.EqualFold(string(t.Severity), severity) {
+				return false
+			}
+			continue
+		}
+
+		fields := append([]string{t.Name, t.URL, display}, t.Tags...)
+		if !slices.ContainsFunc(fields, func(f string) bool { return strings.Contains(strings.ToLower(f), term) }) {
+			return false
+		}
+	}
+	return true
+}
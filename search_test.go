@@ -0,0 +1,78 @@
+package kenko
+
+import (
+	"context"
+	"testing"
+)
+
+func searchChecker(t *testing.T) *Checker {
+	t.Helper()
+	store := NewMemoryStore()
+	c, err := NewChecker(
+		WithStore(store),
+		WithTarget("payments-api", "https://pay.example.com/health", WithTags("payments", "tier-1"), WithLabels(map[string]string{"team": "Payments", "env": "prod"})),
+		WithTarget("checkout", "https://shop.example.com/checkout", WithTags("payments"), WithGroup("shop"), WithSeverity(SeverityCritical), WithLabels(map[string]string{"team": "shop"})),
+		WithTarget("blog", "https://blog.example.com"),
+		WithStatusPage(StatusPage{Components: []Component{{Target: "blog", Name: "Company Blog"}}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	_ = store.Set(ctx, "payments-api", Result{Target: "payments-api", Status: StatusUnhealthy})
+	_ = store.Set(ctx, "checkout", Result{Target: "checkout", Status: StatusHealthy})
+	return c
+}
+
+func names(found []SearchResult) []string {
+	var out []string
+	for _, f := range found {
+		out = append(out, f.Target.Name)
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	c := searchChecker(t)
+
+	tests := []struct {
+		query    string
+		statuses []Status
+		want     []string
+	}{
+		{"", nil, []string{"blog", "checkout", "payments-api"}},
+		{"payments", nil, []string{"checkout", "payments-api"}},
+		{"PAY", nil, []string{"checkout", "payments-api"}},
+		{"tag:tier-1", nil, []string{"payments-api"}},
+		{"tag:pay", nil, nil},
+		{"group:shop", nil, []string{"checkout"}},
+		{"severity:Critical", nil, []string{"checkout"}},
+		{"severity:info", nil, nil},
+		{"label:team=payments", nil, []string{"payments-api"}},
+		{"label:team", nil, []string{"checkout", "payments-api"}},
+		{"label:env=prod label:team=shop", nil, nil},
+		{"shop.example", nil, []string{"checkout"}},
+		{"company", nil, []string{"blog"}},
+		{"payments checkout", nil, []string{"checkout"}},
+		{"payments", []Status{StatusUnhealthy}, []string{"payments-api"}},
+		{"", []Status{StatusUnknown}, []string{"blog"}},
+	}
+	for _, tt := range tests {
+		found, err := c.Search(tt.query, tt.statuses...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := names(found)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q, %v) = %v, want %v", tt.query, tt.statuses, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q, %v) = %v, want %v", tt.query, tt.statuses, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package kenko
+
+// Severity is how urgent a target's failures are, used to route its
+// notifications and filter its results.
+type Severity string
+
+const (
+	// SeverityCritical marks targets whose failures need someone now.
+	SeverityCritical Severity = "critical"
+	// SeverityWarning marks targets whose failures can wait for working
+	// hours.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo marks targets whose failures are only worth knowing
+	// about.
+	SeverityInfo Severity = "info"
+)
+
+// Severities lists every Severity, from most to least urgent.
+var Severities = []Severity{SeverityCritical, SeverityWarning, SeverityInfo}
+
+// WithSeverity sets how urgent the target's failures are. it is copied into
+// every Result, so routes, API filters, reporters and notifiers can use it;
+// targets without one have an empty severity.
+func WithSeverity(s Severity) TargetOption {
+	return func(t *Target) { t.Severity = s }
+}
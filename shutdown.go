@@ -0,0 +1,65 @@
+package kenko
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle coordinates a graceful Shutdown with a running Run loop.
+type lifecycle struct {
+	stopOnce sync.Once
+	doneOnce sync.Once
+	stop     chan struct{} // closed by Shutdown
+	done     chan struct{} // closed when Run returns
+
+	mu    sync.Mutex
+	abort context.CancelFunc
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (l *lifecycle) setAbort(abort context.CancelFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.abort = abort
+}
+
+// cancel aborts the checks in flight, reporting false if Run never started.
+func (l *lifecycle) cancel() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.abort == nil {
+		return false
+	}
+	l.abort()
+	return true
+}
+
+func (l *lifecycle) finish() {
+	l.doneOnce.Do(func() { close(l.done) })
+}
+
+// Shutdown stops Run from scheduling new check cycles and waits for the cycle
+// in flight, and any queued result writes, to complete. if ctx expires first
+// the in-flight checks are cancelled and ctx's error is returned. unlike
+// cancelling Run's context, it lets the final results be recorded.
+func (c *Checker) Shutdown(ctx context.Context) error {
+	c.life.stopOnce.Do(func() { close(c.life.stop) })
+
+	c.life.mu.Lock()
+	started := c.life.abort != nil
+	c.life.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-c.life.done:
+		return nil
+	case <-ctx.Done():
+		c.life.cancel()
+		return ctx.Err()
+	}
+}
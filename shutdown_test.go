@@ -0,0 +1,112 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// blockingServer holds each request until release is closed or the request is cancelled.
+func blockingServer(t *testing.T) (srv *httptest.Server, hit <-chan struct{}, release chan struct{}) {
+	t.Helper()
+	hits := make(chan struct{}, 10)
+	release = make(chan struct{})
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- struct{}{}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hits, release
+}
+
+func TestShutdown_WaitsForInFlightChecks(t *testing.T) {
+	srv, hit, release := blockingServer(t)
+
+	c, err := NewChecker(WithTarget("api", srv.URL), WithInterval(time.Hour), WithWriteBehind(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runDone := make(chan struct{})
+	go func() {
+		c.Run(context.Background())
+		close(runDone)
+	}()
+	<-hit
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErr <- c.Shutdown(ctx)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	<-runDone
+
+	results, _ := c.Results()
+	if results["api"].Status != StatusHealthy {
+		t.Errorf("result = %+v, want the in-flight check stored as healthy", results["api"])
+	}
+}
+
+func TestShutdown_DeadlineAbortsChecks(t *testing.T) {
+	srv, hit, _ := blockingServer(t)
+
+	c, err := NewChecker(WithTarget("api", srv.URL), WithInterval(time.Hour), WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runDone := make(chan struct{})
+	go func() {
+		c.Run(context.Background())
+		close(runDone)
+	}()
+	<-hit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+
+	select {
+	case <-runDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the shutdown deadline")
+	}
+}
+
+func TestShutdown_BeforeRun(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "http://localhost:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run after Shutdown did not return")
+	}
+}
@@ -0,0 +1,156 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// silencesStateKey is the StateStore key holding silences.
+const silencesStateKey = "silences"
+
+// ErrNoSilence is returned when a silence ID does not exist.
+var ErrNoSilence = errors.New("kenko: no such silence")
+
+// Silence mutes the notifications of matching targets until it ends, e.g.
+// while on-call works an incident they already know about. a target matches
+// when it is one of Targets, is in one of Groups, and has every one of the
+// Labels selectors ("key=value" or "key"); empty criteria match any target,
+// but a silence needs at least one.
+type Silence struct {
+	ID       string    `json:"id"`
+	Targets  []string  `json:"targets,omitempty"`
+	Groups   []string  `json:"groups,omitempty"`
+	Labels   []string  `json:"labels,omitempty"`
+	Comment  string    `json:"comment"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+func (s Silence) matches(r Result) bool {
+	if len(s.Targets) > 0 && !slices.Contains(s.Targets, r.Target) {
+		return false
+	}
+	if len(s.Groups) > 0 && !slices.Contains(s.Groups, r.Group) {
+		return false
+	}
+	return matchLabels(r.Labels, s.Labels)
+}
+
+// silences holds the silences that have not ended, oldest first.
+type silences struct {
+	mu   sync.Mutex
+	list []Silence
+}
+
+// active drops ended silences and returns the rest. the caller holds mu.
+func (ss *silences) active(now time.Time) []Silence {
+	ss.list = slices.DeleteFunc(ss.list, func(s Silence) bool { return !now.Before(s.EndsAt) })
+	return ss.list
+}
+
+// Silences returns the silences that have not ended, newest first.
+func (c *Checker) Silences() []Silence {
+	c.silences.mu.Lock()
+	defer c.silences.mu.Unlock()
+
+	list := c.silences.active(time.Now())
+	out := make([]Silence, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		s := list[i]
+		s.Targets = slices.Clone(s.Targets)
+		s.Groups = slices.Clone(s.Groups)
+		s.Labels = slices.Clone(s.Labels)
+		out = append(out, s)
+	}
+	return out
+}
+
+// AddSilence mutes the targets matching s for d from now. its ID and times
+// are set from the call; targets must name configured ones. it is persisted
+// when the store implements StateStore, and only takes effect once it is.
+func (c *Checker) AddSilence(ctx context.Context, s Silence, d time.Duration) (Silence, error) {
+	if d <= 0 {
+		return Silence{}, fmt.Errorf("kenko: silence duration must be positive, got %s", d)
+	}
+	if len(s.Targets) == 0 && len(s.Groups) == 0 && len(s.Labels) == 0 {
+		return Silence{}, fmt.Errorf("kenko: silence needs targets, groups, or labels to match")
+	}
+	names := make([]string, 0, len(s.Targets))
+	for _, target := range s.Targets {
+		name, err := c.normalizeName(target)
+		if err != nil {
+			return Silence{}, fmt.Errorf("kenko: target %q: %w", target, err)
+		}
+		if !c.hasTarget(name) {
+			return Silence{}, fmt.Errorf("kenko: unknown target %q", target)
+		}
+		names = append(names, name)
+	}
+
+	now := time.Now()
+	s.ID = strconv.FormatInt(now.UnixNano(), 36)
+	s.Targets = names
+	s.StartsAt = now
+	s.EndsAt = now.Add(d)
+
+	c.silences.mu.Lock()
+	defer c.silences.mu.Unlock()
+	list := append(slices.Clone(c.silences.active(now)), s)
+	if err := c.saveState(ctx, silencesStateKey, list); err != nil {
+		return Silence{}, &saveError{err: err}
+	}
+	c.silences.list = list
+	return s, nil
+}
+
+// DeleteSilence ends a silence early, once the change is persisted.
+func (c *Checker) DeleteSilence(ctx context.Context, id string) error {
+	c.silences.mu.Lock()
+	defer c.silences.mu.Unlock()
+
+	list := slices.Clone(c.silences.active(time.Now()))
+	i := slices.IndexFunc(list, func(s Silence) bool { return s.ID == id })
+	if i < 0 {
+		return ErrNoSilence
+	}
+	list = slices.Delete(list, i, i+1)
+	if err := c.saveState(ctx, silencesStateKey, list); err != nil {
+		return err
+	}
+	c.silences.list = list
+	return nil
+}
+
+// silenced reports whether a silence active at now matches r.
+func (c *Checker) silenced(r Result, now time.Time) bool {
+	c.silences.mu.Lock()
+	defer c.silences.mu.Unlock()
+	for _, s := range c.silences.list {
+		if !now.Before(s.StartsAt) && now.Before(s.EndsAt) && s.matches(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSilences restores persisted silences unless some were added in memory
+// already.
+func (c *Checker) loadSilences(ctx context.Context) error {
+	var stored []Silence
+	if ok, err := c.loadState(ctx, silencesStateKey, &stored); !ok {
+		return err
+	}
+
+	c.silences.mu.Lock()
+	defer c.silences.mu.Unlock()
+	if len(c.silences.list) == 0 {
+		c.silences.list = stored
+		c.silences.active(time.Now())
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSilence_Matches(t *testing.T) {
+	r := Result{Target: "api", Group: "core", Labels: map[string]string{"team": "payments"}}
+	for _, tt := range []struct {
+		name string
+		s    Silence
+		want bool
+	}{
+		{"target", Silence{Targets: []string{"web", "api"}}, true},
+		{"other target", Silence{Targets: []string{"web"}}, false},
+		{"group", Silence{Groups: []string{"core"}}, true},
+		{"label", Silence{Labels: []string{"team=payments"}}, true},
+		{"other label", Silence{Labels: []string{"team=search"}}, false},
+		{"every criterion", Silence{Groups: []string{"core"}, Labels: []string{"team=search"}}, false},
+	} {
+		if got := tt.s.matches(r); got != tt.want {
+			t.Errorf("%s: matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSilences_Lifecycle(t *testing.T) {
+	c := announcementChecker(t, NewMemoryStore())
+	ctx := context.Background()
+
+	s, err := c.AddSilence(ctx, Silence{Targets: []string{"API"}, Comment: "db failover"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID == "" || s.Targets[0] != "api" || s.EndsAt.Sub(s.StartsAt) != time.Hour {
+		t.Errorf("silence = %+v, want an hour on api", s)
+	}
+	now := time.Now()
+	if !c.silenced(Result{Target: "api"}, now) {
+		t.Error("api not silenced")
+	}
+	if c.silenced(Result{Target: "api"}, now.Add(2*time.Hour)) {
+		t.Error("api silenced after the silence ended")
+	}
+	if got := c.Silences(); len(got) != 1 || got[0].Comment != "db failover" {
+		t.Errorf("silences = %+v", got)
+	}
+
+	if err := c.DeleteSilence(ctx, s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteSilence(ctx, s.ID); !errors.Is(err, ErrNoSilence) {
+		t.Errorf("second delete = %v, want ErrNoSilence", err)
+	}
+	if c.silenced(Result{Target: "api"}, now) {
+		t.Error("api silenced after the silence was deleted")
+	}
+}
+
+func TestAddSilence_Invalid(t *testing.T) {
+	c := announcementChecker(t, NewMemoryStore())
+	ctx := context.Background()
+
+	if _, err := c.AddSilence(ctx, Silence{}, time.Hour); err == nil {
+		t.Error("expected an error for a silence matching everything")
+	}
+	if _, err := c.AddSilence(ctx, Silence{Targets: []string{"api"}}, 0); err == nil {
+		t.Error("expected an error for a zero duration")
+	}
+	if _, err := c.AddSilence(ctx, Silence{Targets: []string{"db"}}, time.Hour); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+}
+
+func TestSilences_Persisted(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	s, err := announcementChecker(t, store).AddSilence(ctx, Silence{Labels: []string{"env=prod"}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := announcementChecker(t, store)
+	if err := c.loadSilences(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Silences(); len(got) != 1 || got[0].ID != s.ID {
+		t.Errorf("silences = %+v, want the stored silence", got)
+	}
+}
+
+func TestSilences_SaveFails(t *testing.T) {
+	store := NewMemoryStore()
+	c := announcementChecker(t, store)
+	ctx := context.Background()
+	s, err := c.AddSilence(ctx, Silence{Targets: []string{"api"}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.store = failingStateStore{store}
+	if _, err := c.AddSilence(ctx, Silence{Groups: []string{"payments"}}, time.Hour); !errors.As(err, new(*saveError)) {
+		t.Errorf("add err = %v, want a save error", err)
+	}
+	if err := c.DeleteSilence(ctx, s.ID); err == nil {
+		t.Error("expected an error when the state cannot be saved")
+	}
+	if got := c.Silences(); len(got) != 1 || got[0].ID != s.ID {
+		t.Errorf("silences = %+v, want only the saved one", got)
+	}
+
+	rec := httptest.NewRecorder()
+	HandlePostSilence(c)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/silences", strings.NewReader(`{"targets":["api"],"duration":"1h"}`)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("post status = %d, want 500", rec.Code)
+	}
+}
+
+func TestNotify_Silenced(t *testing.T) {
+	rec := &recordingNotifier{}
+	c, err := NewChecker(WithTarget("api", "https://example.com"), WithNotifier("ops", rec), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s, err := c.AddSilence(ctx, Silence{Targets: []string{"api"}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.notify(Segment{Status: StatusHealthy}, Result{Target: "api", Status: StatusUnhealthy}, nil)
+	if err := c.DeleteSilence(ctx, s.ID); err != nil {
+		t.Fatal(err)
+	}
+	c.notify(Segment{Status: StatusUnhealthy}, Result{Target: "api", Status: StatusHealthy}, nil)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	c.dispatcher.run(runCtx)
+
+	if len(rec.sent) != 1 || rec.sent[0].Status != StatusHealthy {
+		t.Errorf("sent = %+v, want only the recovery after the silence", rec.sent)
+	}
+	deliveries, _ := c.Deliveries(ctx, DeliveryQuery{Notifier: "ops"})
+	if len(deliveries) != 2 || deliveries[1].Outcome != NotificationSilenced || deliveries[1].Status != StatusUnhealthy || deliveries[1].Attempts != 0 {
+		t.Errorf("deliveries = %+v, want the silenced failure recorded", deliveries)
+	}
+}
+
+func TestHandleSilences(t *testing.T) {
+	c := announcementChecker(t, NewMemoryStore())
+	_ = c.store.Set(context.Background(), "api", Result{Target: "api", Status: StatusUnhealthy, CheckedAt: time.Now()})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/silences",
+		strings.NewReader(`{"targets":["api"],"duration":"2h","comment":"known incident"}`))
+	rec := httptest.NewRecorder()
+	HandlePostSilence(c)(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("post status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var s Silence
+	_ = json.NewDecoder(rec.Body).Decode(&s)
+
+	rec = httptest.NewRecorder()
+	HandleStatus(c)(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var resp statusResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Silences) != 1 || resp.Silences[0].ID != s.ID || len(resp.Targets) != 1 || !resp.Targets[0].Silenced {
+		t.Errorf("status = %+v, want the silence listed and api silenced", resp)
+	}
+
+	for _, body := range []string{`{"targets":["api"],"duration":"soon"}`, `{"duration":"1h"}`} {
+		rec = httptest.NewRecorder()
+		HandlePostSilence(c)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/silences", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, rec.Code)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/silences/"+s.ID, nil)
+	req.SetPathValue("id", s.ID)
+	rec = httptest.NewRecorder()
+	HandleDeleteSilence(c)(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	HandleDeleteSilence(c)(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,152 @@
+package kenko
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"time"
+)
+
+// maxReportSamples caps how many stored results an UptimeReport takes its
+// latency percentiles from, newest first.
+const maxReportSamples = 50000
+
+// ReportWindows are the windows UptimeReport is usually asked for, by name.
+var ReportWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+// UptimeReport summarizes a target's availability and latency over a window
+// ending now, e.g. for an SLA.
+type UptimeReport struct {
+	Target   string
+	From, To time.Time
+	// Uptime is the fraction of the Checks in the window that were not
+	// unhealthy, counted in the uptime aggregates: hourly ones for windows
+	// up to a week, daily ones beyond, so the window is widened to the start
+	// of its first hour or day. the checks of excluded incidents are left
+	// out of Checks and Down, attributed to them by their share of each
+	// bucket's unhealthy time in the timeline.
+	Uptime float64
+	Checks int
+	Down   int
+	// Downtime is how long the target was unhealthy in the window and
+	// Incidents how many of its incidents overlapped it, both with excluded
+	// incidents left out, as far back as the timeline reaches.
+	Downtime  time.Duration
+	Incidents int
+	// AvgLatency is the mean latency of the checks.
+	AvgLatency time.Duration
+	// P50, P95 and P99 are the latency percentiles of the Samples stored
+	// results checked between SamplesFrom and SamplesTo, zero when the store
+	// keeps no history. the samples cover less than the window when the
+	// store keeps less history or the window holds more than 50000 results.
+	P50, P95, P99 time.Duration
+	Samples       int
+	SamplesFrom   time.Time
+	SamplesTo     time.Time
+}
+
+// UptimeReport summarizes the named target over the window ending now.
+func (c *Checker) UptimeReport(ctx context.Context, name string, window time.Duration) (UptimeReport, error) {
+	now := time.Now()
+	rep := UptimeReport{Target: name, From: now.Add(-window), To: now}
+
+	period, bucket := PeriodHour, time.Hour
+	if window > 7*24*time.Hour {
+		period, bucket = PeriodDay, 24*time.Hour
+	}
+	// the unhealthy segments in the window, to leave out the downtime and
+	// the checks of excluded incidents
+	type span struct {
+		start, end time.Time
+		excluded   bool
+	}
+	var down []span
+	for _, s := range c.timeline.get(name) {
+		if s.Status != StatusUnhealthy {
+			continue
+		}
+		end := s.End
+		if end.IsZero() || end.After(now) {
+			end = now
+		}
+		_, excluded := c.exclusions.get(name, incidentID(s.Start))
+		down = append(down, span{s.Start, end, excluded})
+
+		start := s.Start
+		if start.Before(rep.From) {
+			start = rep.From
+		}
+		if !excluded && end.After(start) {
+			rep.Downtime += end.Sub(start)
+			rep.Incidents++
+		}
+	}
+
+	var latency time.Duration
+	for _, a := range c.UptimeAggregates(name, period, rep.From.UTC().Truncate(bucket), now) {
+		var all, excluded time.Duration
+		for _, sp := range down {
+			d := overlap(sp.start, sp.end, a.Start, a.Start.Add(bucket))
+			all += d
+			if sp.excluded {
+				excluded += d
+			}
+		}
+		if excluded > 0 {
+			n := int(math.Round(float64(a.Down) * float64(excluded) / float64(all)))
+			if a.Checks > 0 {
+				latency -= a.Latency / time.Duration(a.Checks) * time.Duration(n)
+			}
+			a.Checks -= n
+			a.Down -= n
+		}
+		rep.Checks += a.Checks
+		rep.Down += a.Down
+		latency += a.Latency
+	}
+	rep.Uptime = UptimeAggregate{Checks: rep.Checks, Down: rep.Down}.Uptime()
+	rep.AvgLatency = UptimeAggregate{Checks: rep.Checks, Latency: latency}.AvgLatency()
+
+	results, err := c.History(ctx, name, HistoryQuery{From: rep.From, To: now, Limit: maxReportSamples})
+	if err != nil && !errors.Is(err, ErrNoHistory) {
+		return UptimeReport{}, err
+	}
+	latencies := make([]time.Duration, 0, len(results))
+	for _, r := range results {
+		if r.Status != StatusMaintenance && r.Status != StatusPaused {
+			latencies = append(latencies, r.Latency)
+		}
+	}
+	if len(results) > 0 {
+		// newest first
+		rep.SamplesFrom, rep.SamplesTo = results[len(results)-1].CheckedAt, results[0].CheckedAt
+	}
+	rep.Samples = len(latencies)
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		rep.P50 = percentile(latencies, 0.50)
+		rep.P95 = percentile(latencies, 0.95)
+		rep.P99 = percentile(latencies, 0.99)
+	}
+	return rep, nil
+}
+
+// overlap returns how long [start, end) and [from, to) overlap.
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
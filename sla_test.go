@@ -0,0 +1,90 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUptimeReport(t *testing.T) {
+	store := NewMemoryStore()
+	c, incStart := incidentChecker(t, store)
+	ctx := context.Background()
+	for i, latency := range []time.Duration{100, 200, 300, 400} {
+		r := Result{Target: "api", Status: StatusHealthy, Latency: latency * time.Millisecond, CheckedAt: incStart.Add(time.Duration(i) * time.Minute)}
+		if i == 1 {
+			r.Status = StatusUnhealthy
+		}
+		c.aggregates.observe(r)
+		store.Set(ctx, "api", r)
+	}
+	// an incident that started before the window only counts from its start
+	c.timeline.observe(Result{Target: "web", Status: StatusUnhealthy, CheckedAt: incStart.Add(-48 * time.Hour)})
+	c.timeline.observe(Result{Target: "web", Status: StatusHealthy, CheckedAt: incStart.Add(-23 * time.Hour)})
+
+	rep, err := c.UptimeReport(ctx, "api", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Checks != 4 || rep.Down != 1 || rep.Uptime != 0.75 || rep.AvgLatency != 250*time.Millisecond {
+		t.Errorf("report = %+v, want 4 checks, 1 down", rep)
+	}
+	if rep.Incidents != 1 || rep.Downtime < 10*time.Minute-time.Second || rep.Downtime > 10*time.Minute+time.Second {
+		t.Errorf("incidents %d, downtime %s, want one of 10m", rep.Incidents, rep.Downtime)
+	}
+	if rep.P50 != 200*time.Millisecond || rep.P99 != 400*time.Millisecond {
+		t.Errorf("p50 %s, p99 %s", rep.P50, rep.P99)
+	}
+	if rep.Samples != 4 || !rep.SamplesFrom.Equal(incStart) || !rep.SamplesTo.Equal(incStart.Add(3*time.Minute)) {
+		t.Errorf("samples = %d from %s to %s, want the 4 stored results", rep.Samples, rep.SamplesFrom, rep.SamplesTo)
+	}
+
+	web, _ := c.UptimeReport(ctx, "web", 24*time.Hour)
+	if web.Incidents != 1 || web.Downtime > 30*time.Minute+time.Second || web.Downtime < 30*time.Minute-time.Second {
+		t.Errorf("web downtime = %s, want the 30m inside the window", web.Downtime)
+	}
+
+	if _, err := c.ExcludeIncident(ctx, "api", incidentID(incStart), "provider outage", ""); err != nil {
+		t.Fatal(err)
+	}
+	rep, err = c.UptimeReport(ctx, "api", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Incidents != 0 || rep.Downtime != 0 {
+		t.Errorf("with the incident excluded: %d incidents, %s down", rep.Incidents, rep.Downtime)
+	}
+	if rep.Checks != 3 || rep.Down != 0 || rep.Uptime != 1 {
+		t.Errorf("with the incident excluded: %d checks, %d down, uptime %v, want its check left out", rep.Checks, rep.Down, rep.Uptime)
+	}
+}
+
+func TestHandleUptime(t *testing.T) {
+	c, _ := incidentChecker(t, NewMemoryStore())
+
+	uptime := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/targets/api/uptime"+query, nil)
+		req.SetPathValue("name", "api")
+		rec := httptest.NewRecorder()
+		HandleUptime(c)(rec, req)
+		return rec
+	}
+
+	rec := uptime("")
+	var resp uptimeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || resp.Window != "30d" || resp.Incidents != 1 || resp.DowntimeSeconds < 599 || resp.UptimePercent != 100 {
+		t.Errorf("uptime = %d %+v, want the default 30d window", rec.Code, resp)
+	}
+	if rec := uptime("?window=7d"); rec.Code != http.StatusOK {
+		t.Errorf("7d: status = %d", rec.Code)
+	}
+	if rec := uptime("?window=1y"); rec.Code != http.StatusBadRequest {
+		t.Errorf("1y: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
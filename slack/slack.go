@@ -0,0 +1,336 @@
+// Package slack is a kenko Notifier that posts status changes to Slack,
+// through an incoming webhook or as a bot with a token and a channel.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultAPIURL  = "https://slack.com/api"
+	defaultTimeout = 10 * time.Second
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithStatusPageURL links every message to the status page at url.
+func WithStatusPageURL(url string) Option {
+	return func(n *Notifier) { n.statusPage = url }
+}
+
+// WithText sets the template the headline of each message is rendered from,
+// executed with the kenko.Notification, e.g. to mention a runbook from the
+// target's labels. it is Slack mrkdwn, so escape &, <, and > in values that
+// are not links. the details stay as fields below it.
+func WithText(t *template.Template) Option {
+	return func(n *Notifier) { n.text = t }
+}
+
+// WithHTTPClient sets the client messages are sent with (default a client
+// with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// WithAPIURL sets the base url of the Slack web api a bot posts to (default
+// "https://slack.com/api").
+func WithAPIURL(url string) Option {
+	return func(n *Notifier) { n.apiURL = strings.TrimSuffix(url, "/") }
+}
+
+// Notifier posts notifications to a Slack channel.
+type Notifier struct {
+	webhookURL string
+	token      string
+	channel    string
+	apiURL     string
+	statusPage string
+	text       *template.Template
+	client     *http.Client
+}
+
+// New creates a Notifier posting to an incoming webhook url, which picks the
+// channel itself.
+func New(webhookURL string, opts ...Option) *Notifier {
+	return newNotifier(&Notifier{webhookURL: webhookURL}, opts)
+}
+
+// NewBot creates a Notifier posting to channel, a name such as #ops or an id,
+// with chat.postMessage as the bot whose token is given.
+func NewBot(token, channel string, opts ...Option) *Notifier {
+	return newNotifier(&Notifier{token: token, channel: channel}, opts)
+}
+
+func newNotifier(n *Notifier, opts []Option) *Notifier {
+	n.apiURL = defaultAPIURL
+	n.client = &http.Client{Timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Notify posts the notification's message.
+func (s *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	msg, err := s.message(n)
+	if err != nil {
+		return kenko.Permanent(err)
+	}
+	return s.send(ctx, msg)
+}
+
+// NotifyDigest posts one message for several notifications, with a line for
+// each.
+func (s *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	msg, err := s.digest(ns)
+	if err != nil {
+		return kenko.Permanent(err)
+	}
+	return s.send(ctx, msg)
+}
+
+func (s *Notifier) send(ctx context.Context, msg message) error {
+	if s.token != "" {
+		msg.Channel = s.channel
+		return s.postMessage(ctx, msg)
+	}
+	resp, err := s.post(ctx, s.webhookURL, msg)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	// incoming webhooks answer bad payloads and removed channels with a 4xx
+	return statusError(resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// Preview renders the text of the message Notify would post for n.
+func (s *Notifier) Preview(n kenko.Notification) (string, error) {
+	msg, err := s.message(n)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{msg.Text}
+	for _, f := range msg.Attachments[0].Fields {
+		lines = append(lines, f.Title+": "+f.Value)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// postMessage sends msg with chat.postMessage, which reports failures in an
+// ok field of a 200 response.
+func (s *Notifier) postMessage(ctx context.Context, msg message) error {
+	resp, err := s.post(ctx, s.apiURL+"/chat.postMessage", msg)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return statusError(resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("slack: decode response: %w", err)
+	}
+	if result.OK {
+		return nil
+	}
+	err = fmt.Errorf("slack: %s", result.Error)
+	switch result.Error {
+	case "ratelimited", "internal_error", "fatal_error", "service_unavailable", "request_timeout":
+		return err
+	}
+	// e.g. invalid_auth or channel_not_found, which retrying cannot fix
+	return kenko.Permanent(err)
+}
+
+func (s *Notifier) post(ctx context.Context, url string, msg message) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, kenko.Permanent(fmt.Errorf("slack: marshal: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, kenko.Permanent(fmt.Errorf("slack: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack: %w", err)
+	}
+	return resp, nil
+}
+
+// statusError reports a failed request, marking it permanent unless the
+// status is one a later attempt may get past.
+func statusError(code int, body string) error {
+	err := fmt.Errorf("slack: status %d: %s", code, body)
+	if !kenko.RetryableStatus(code) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// message is the body of an incoming webhook or chat.postMessage request.
+type message struct {
+	Channel     string       `json:"channel,omitempty"`
+	Text        string       `json:"text"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	Color  string  `json:"color"`
+	Text   string  `json:"text,omitempty"`
+	Fields []field `json:"fields,omitempty"`
+	Footer string  `json:"footer,omitempty"`
+	TS     int64   `json:"ts,omitempty"`
+}
+
+type field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// colors of the attachment bar by status.
+var colors = map[kenko.Status]string{
+	kenko.StatusHealthy:   "#2eb886",
+	kenko.StatusDegraded:  "#daa038",
+	kenko.StatusUnhealthy: "#a30200",
+}
+
+// message builds the message for n: a headline naming the target and its
+// new status, with the details as fields.
+func (s *Notifier) message(n kenko.Notification) (message, error) {
+	text, err := s.headline(n)
+	if err != nil {
+		return message{}, err
+	}
+	if n.Test {
+		text = "[test] " + text
+	}
+
+	r := n.Result
+	fields := []field{
+		{Title: "Status", Value: string(n.Status), Short: true},
+		{Title: "Latency", Value: r.Latency.Round(time.Millisecond).String(), Short: true},
+	}
+	if r.StatusCode != 0 {
+		fields = append(fields, field{Title: "Status code", Value: fmt.Sprint(r.StatusCode), Short: true})
+	}
+	if r.Region != "" {
+		fields = append(fields, field{Title: "Region", Value: escape(r.Region), Short: true})
+	}
+	if r.Error != "" {
+		fields = append(fields, field{Title: "Error", Value: escape(r.Error)})
+	}
+	if o := n.Outage; o != nil {
+		fields = append(fields, field{Title: "Down since", Value: o.Start.UTC().Format(time.RFC3339)})
+		if o.FirstError != "" {
+			fields = append(fields, field{Title: "First error", Value: escape(o.FirstError)})
+		}
+		if o.LastError != "" && o.LastError != o.FirstError {
+			fields = append(fields, field{Title: "Last error", Value: escape(o.LastError)})
+		}
+	}
+
+	color, ok := colors[n.Status]
+	if !ok {
+		color = "#808080"
+	}
+	if n.Slow != nil && !n.Slow.Resolved {
+		color = colors[kenko.StatusDegraded]
+	}
+	a := attachment{Color: color, Fields: fields, Footer: "kenko"}
+	if !n.At.IsZero() {
+		a.TS = n.At.Unix()
+	}
+	return message{Text: text, Attachments: []attachment{a}}, nil
+}
+
+// digest builds the message for several notifications: a headline counting
+// them, and an attachment for each with its headline and error, colored by
+// its status.
+func (s *Notifier) digest(ns []kenko.Notification) (message, error) {
+	msg := message{Text: fmt.Sprintf("*kenko: %d notifications*", len(ns))}
+	for _, n := range ns {
+		m, err := s.message(n)
+		if err != nil {
+			return message{}, err
+		}
+		a := m.Attachments[0]
+		a.Text, a.Fields, a.Footer = m.Text, nil, ""
+		if n.Result.Error != "" && n.Status != kenko.StatusHealthy {
+			a.Text += ": " + escape(n.Result.Error)
+		}
+		msg.Attachments = append(msg.Attachments, a)
+	}
+	return msg, nil
+}
+
+// headline renders the text of the message for n.
+func (s *Notifier) headline(n kenko.Notification) (string, error) {
+	if s.text != nil {
+		var b strings.Builder
+		if err := s.text.Execute(&b, n); err != nil {
+			return "", fmt.Errorf("slack: text: %w", err)
+		}
+		return b.String(), nil
+	}
+	name := escape(n.Target)
+	if s.statusPage != "" {
+		name = fmt.Sprintf("<%s|%s>", s.statusPage, name)
+	}
+	switch {
+	case n.Slow != nil && n.Slow.Resolved:
+		return fmt.Sprintf("*%s* is fast again after %s", name, n.At.Sub(n.Slow.Since).Round(time.Second)), nil
+	case n.Slow != nil:
+		return fmt.Sprintf("*%s* is slow: over %s for %d checks", name, n.Slow.Threshold, n.Slow.Checks), nil
+	case n.Outage != nil:
+		return fmt.Sprintf("*%s* recovered after %s (was %s)", name, n.Outage.Duration.Round(time.Second), n.Previous), nil
+	case n.Repeat > 0:
+		return fmt.Sprintf("*%s* is still %s", name, n.Status), nil
+	}
+	return fmt.Sprintf("*%s* is %s (was %s)", name, n.Status, n.Previous), nil
+}
+
+// escape replaces the characters Slack reserves for links and mentions.
+func escape(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '&':
+			out = append(out, "&amp;"...)
+		case '<':
+			out = append(out, "&lt;"...)
+		case '>':
+			out = append(out, "&gt;"...)
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,178 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification {
+	n := notifytest.Notification(kenko.StatusUnhealthy)
+	n.Result.Error = "status 503 <html>"
+	n.Result.Latency = 1234567 * time.Microsecond
+	return n
+}
+
+func TestNotify_Webhook(t *testing.T) {
+	var got message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL, WithStatusPageURL("https://status.example.com")).Notify(context.Background(), notification())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "*<https://status.example.com|api>* is unhealthy (was healthy)" || got.Channel != "" {
+		t.Errorf("message = %+v", got)
+	}
+	a := got.Attachments[0]
+	if a.Color != colors[kenko.StatusUnhealthy] || a.TS != notification().At.Unix() {
+		t.Errorf("attachment = %+v", a)
+	}
+	values := map[string]string{}
+	for _, f := range a.Fields {
+		values[f.Title] = f.Value
+	}
+	if values["Latency"] != "1.235s" || values["Error"] != "status 503 &lt;html&gt;" || values["Status code"] != "503" {
+		t.Errorf("fields = %v", values)
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+	var got message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	web := notification()
+	web.Target, web.Result.Target = "web", "web"
+	recovery := notification()
+	recovery.Target, recovery.Previous, recovery.Status = "db", kenko.StatusUnhealthy, kenko.StatusHealthy
+	recovery.Outage = &kenko.Outage{Duration: time.Minute}
+	if err := New(srv.URL).NotifyDigest(context.Background(), []kenko.Notification{notification(), web, recovery}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "*kenko: 3 notifications*" || len(got.Attachments) != 3 {
+		t.Fatalf("message = %+v", got)
+	}
+	if a := got.Attachments[0]; a.Text != "*api* is unhealthy (was healthy): status 503 &lt;html&gt;" || a.Color != colors[kenko.StatusUnhealthy] || a.Fields != nil {
+		t.Errorf("first attachment = %+v", a)
+	}
+	if a := got.Attachments[2]; a.Text != "*db* recovered after 1m0s (was unhealthy)" || a.Color != colors[kenko.StatusHealthy] {
+		t.Errorf("last attachment = %+v", a)
+	}
+}
+
+func TestNotify_Bot(t *testing.T) {
+	for reply, permanent := range map[string]bool{
+		`{"ok":false,"error":"channel_not_found"}`: true,
+		`{"ok":false,"error":"ratelimited"}`:       false,
+		`{"ok":true}`:                              false,
+	} {
+		var got message
+		var auth string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/chat.postMessage" {
+				http.NotFound(w, r)
+				return
+			}
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+			io.WriteString(w, reply)
+		}))
+		err := NewBot("xoxb-secret", "#ops", WithAPIURL(srv.URL+"/")).Notify(context.Background(), notification())
+		srv.Close()
+
+		if auth != "Bearer xoxb-secret" || got.Channel != "#ops" {
+			t.Errorf("%s: auth %q, channel %q", reply, auth, got.Channel)
+		}
+		if strings.Contains(reply, `"ok":true`) {
+			if err != nil {
+				t.Errorf("%s: err = %v", reply, err)
+			}
+			continue
+		}
+		if err == nil || kenko.IsPermanent(err) != permanent {
+			t.Errorf("%s: err = %v, want permanent %v", reply, err, permanent)
+		}
+	}
+}
+
+func TestNotify_WebhookErrors(t *testing.T) {
+	notifytest.StatusErrors(t, "no_service", "no_service", func(url string) error {
+		return New(url).Notify(context.Background(), notification())
+	})
+}
+
+func TestMessage_Recovery(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Result.Error = ""
+	n.Outage = &kenko.Outage{Start: n.At.Add(-90 * time.Second), Duration: 90 * time.Second, FirstError: "timeout", LastError: "status 503"}
+
+	text, err := New("http://localhost").Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"*api* recovered after 1m30s (was unhealthy)", "Down since: 2026-03-13T11:58:30Z", "First error: timeout", "Last error: status 503"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("preview %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestMessage_Repeat(t *testing.T) {
+	n := notification()
+	n.Repeat = 2
+	if text, _ := New("http://localhost").headline(n); text != "*api* is still unhealthy" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestMessage_Text(t *testing.T) {
+	tmpl := template.Must(template.New("text").Parse(`*{{.Target}}* is {{.Status}}, see <{{index .Result.Labels "runbook"}}|the runbook>`))
+	n := notification()
+	n.Result.Labels = map[string]string{"runbook": "https://wiki/api"}
+	n.Test = true
+	msg, err := New("http://localhost", WithText(tmpl)).message(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[test] *api* is unhealthy, see <https://wiki/api|the runbook>"; msg.Text != want {
+		t.Errorf("text = %q, want %q", msg.Text, want)
+	}
+	if len(msg.Attachments[0].Fields) == 0 {
+		t.Error("fields dropped")
+	}
+
+	broken := template.Must(template.New("text").Parse(`{{.Missing}}`))
+	if err := New("http://localhost", WithText(broken)).Notify(context.Background(), n); !kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent template error", err)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	n := notification()
+	n.Test = true
+	text, err := New("http://localhost").Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, "[test] *api* is unhealthy (was healthy)\n") || !strings.Contains(text, "Latency: 1.235s") {
+		t.Errorf("preview = %s", text)
+	}
+}
@@ -0,0 +1,118 @@
+package kenko
+
+import (
+	"sync"
+	"time"
+)
+
+// WithSlowThreshold sends a latency alert, apart from the target's status
+// changes, once checks consecutive checks of the target took longer than
+// latency while it was up, and its recovery once as many checks in a row
+// were faster. a checks of 0 or 1 alerts on the first slow check.
+func WithSlowThreshold(latency time.Duration, checks int) TargetOption {
+	return func(t *Target) {
+		t.SlowLatency = latency
+		t.SlowChecks = checks
+	}
+}
+
+// SlowAlert describes a latency alert, sent in Notification.Slow.
+type SlowAlert struct {
+	Threshold time.Duration `json:"threshold"`
+	Checks    int           `json:"checks"`
+	// Since is when the first of the slow checks ran.
+	Since time.Time `json:"since"`
+	// Resolved is set when the target is fast again.
+	Resolved bool `json:"resolved,omitempty"`
+}
+
+// slowness tracks each target's latency against its slow threshold.
+type slowness struct {
+	mu    sync.Mutex
+	state map[string]*slowState
+}
+
+type slowState struct {
+	// slow is whether an alert is open, and streak the number of consecutive
+	// checks that disagreed with it, starting at start. threshold and checks
+	// are those the open alert was sent with.
+	slow      bool
+	since     time.Time
+	streak    int
+	start     time.Time
+	threshold time.Duration
+	checks    int
+}
+
+// observe tracks r and returns the alert it opened or resolved, if any.
+// only checks of a target that is up count, so an outage neither opens nor
+// resolves a latency alert. maintenance, a pause, or removing the threshold
+// stops tracking the target and resolves an open alert, so it is not left
+// open with nothing to close it.
+func (s *slowness) observe(t Target, r Result) *SlowAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case t.SlowLatency <= 0, r.Status == StatusMaintenance, r.Status == StatusPaused:
+		st := s.state[t.Name]
+		delete(s.state, t.Name)
+		if st == nil || !st.slow {
+			return nil
+		}
+		return &SlowAlert{Threshold: st.threshold, Checks: st.checks, Since: st.since, Resolved: true}
+	case r.Status != StatusHealthy && r.Status != StatusDegraded:
+		return nil
+	}
+
+	st := s.state[t.Name]
+	if st == nil {
+		if s.state == nil {
+			s.state = make(map[string]*slowState)
+		}
+		st = &slowState{}
+		s.state[t.Name] = st
+	}
+	if slow := r.Latency > t.SlowLatency; slow == st.slow {
+		st.streak = 0
+		return nil
+	}
+	if st.streak == 0 {
+		st.start = r.CheckedAt
+	}
+	st.streak++
+	checks := max(t.SlowChecks, 1)
+	if st.streak < checks {
+		return nil
+	}
+
+	st.slow, st.streak = !st.slow, 0
+	alert := &SlowAlert{Threshold: t.SlowLatency, Checks: checks, Since: st.since, Resolved: !st.slow}
+	if st.slow {
+		st.since, st.threshold, st.checks = st.start, t.SlowLatency, checks
+		alert.Since = st.since
+	}
+	return alert
+}
+
+// notifySlow queues the latency alert for r, unless a silence matches it.
+func (c *Checker) notifySlow(r Result, alert *SlowAlert) {
+	if c.dispatcher == nil {
+		return
+	}
+	n := Notification{
+		Target:   r.Target,
+		Previous: r.Status,
+		Status:   r.Status,
+		Result:   r,
+		At:       r.CheckedAt,
+		Uptime:   c.Uptime(r.Target),
+		Slow:     alert,
+	}
+	if c.silenced(r, time.Now()) {
+		c.logger.Debug("latency alert silenced", "target", r.Target)
+		c.dispatcher.silence(n)
+		return
+	}
+	c.dispatcher.enqueue(n)
+}
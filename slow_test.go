@@ -0,0 +1,104 @@
+package kenko
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlowness(t *testing.T) {
+	var s slowness
+	target := NewTarget("api", "https://example.com", WithSlowThreshold(time.Second, 3))
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	var alerts []*SlowAlert
+	for i, tt := range []struct {
+		latency time.Duration
+		status  Status
+	}{
+		{2 * time.Second, StatusHealthy},
+		{100 * time.Millisecond, StatusHealthy}, // a lone fast check resets the streak
+		{2 * time.Second, StatusHealthy},
+		{2 * time.Second, StatusHealthy},
+		{0, StatusUnhealthy}, // outages are not counted
+		{2 * time.Second, StatusDegraded},
+		{2 * time.Second, StatusHealthy},
+		{100 * time.Millisecond, StatusHealthy},
+		{100 * time.Millisecond, StatusHealthy},
+		{100 * time.Millisecond, StatusHealthy},
+	} {
+		r := Result{Target: "api", Status: tt.status, Latency: tt.latency, CheckedAt: start.Add(time.Duration(i) * time.Minute)}
+		if a := s.observe(target, r); a != nil {
+			alerts = append(alerts, a)
+			if len(alerts) == 1 && i != 5 || len(alerts) == 2 && i != 9 {
+				t.Errorf("alert %+v at check %d", a, i)
+			}
+		}
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("alerts = %+v, want the alert and its recovery", alerts)
+	}
+	want := SlowAlert{Threshold: time.Second, Checks: 3, Since: start.Add(2 * time.Minute)}
+	if *alerts[0] != want {
+		t.Errorf("alert = %+v, want %+v", *alerts[0], want)
+	}
+	want.Resolved = true
+	if *alerts[1] != want {
+		t.Errorf("recovery = %+v, want %+v", *alerts[1], want)
+	}
+}
+
+func TestSlowness_Maintenance(t *testing.T) {
+	var s slowness
+	target := NewTarget("api", "https://example.com", WithSlowThreshold(time.Second, 0))
+	now := time.Now()
+	if a := s.observe(target, Result{Status: StatusHealthy, Latency: 2 * time.Second, CheckedAt: now}); a == nil {
+		t.Fatal("no alert on the first slow check")
+	}
+	a := s.observe(target, Result{Status: StatusMaintenance, CheckedAt: now})
+	if a == nil || !a.Resolved || a.Threshold != time.Second || !a.Since.Equal(now) {
+		t.Fatalf("alert = %+v, want maintenance to resolve the open alert", a)
+	}
+	if a := s.observe(target, Result{Status: StatusHealthy, Latency: time.Millisecond, CheckedAt: now}); a != nil {
+		t.Errorf("alert = %+v, want none once the alert is resolved", a)
+	}
+	if a := s.observe(target, Result{Status: StatusMaintenance, CheckedAt: now}); a != nil {
+		t.Errorf("alert = %+v, want none for maintenance without an open alert", a)
+	}
+}
+
+func TestSlowness_ThresholdRemoved(t *testing.T) {
+	var s slowness
+	target := NewTarget("api", "https://example.com", WithSlowThreshold(time.Second, 0))
+	s.observe(target, Result{Status: StatusHealthy, Latency: 2 * time.Second, CheckedAt: time.Now()})
+	target.SlowLatency = 0
+	a := s.observe(target, Result{Status: StatusHealthy, Latency: 2 * time.Second, CheckedAt: time.Now()})
+	if a == nil || !a.Resolved || a.Threshold != time.Second {
+		t.Errorf("alert = %+v, want removing the threshold to resolve the open alert", a)
+	}
+}
+
+func TestNotifySlow_Routes(t *testing.T) {
+	pager, chat := &recordingNotifier{}, &recordingNotifier{}
+	c, err := NewChecker(
+		WithTarget("api", "https://example.com"),
+		WithNotifier("pager", pager), WithNotifier("chat", chat),
+		WithRoute(Route{Statuses: []Status{StatusUnhealthy}, Notifiers: []string{"pager"}}),
+		WithRoute(Route{Notifiers: []string{"chat"}}),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.notifySlow(Result{Target: "api", Status: StatusHealthy, Latency: 2 * time.Second}, &SlowAlert{Threshold: time.Second, Checks: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.dispatcher.run(ctx)
+
+	if len(pager.sent) != 0 || len(chat.sent) != 1 || chat.sent[0].Slow == nil {
+		t.Errorf("pager sent %+v, chat sent %+v, want the alert only in chat", pager.sent, chat.sent)
+	}
+}
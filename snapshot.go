@@ -0,0 +1,140 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// snapshotVersion is bumped when the snapshot format changes incompatibly.
+const snapshotVersion = 1
+
+// snapshotFile is the file WithSnapshot writes on shutdown.
+type snapshotFile struct {
+	Version   int                  `json:"version"`
+	TakenAt   time.Time            `json:"taken_at"`
+	Results   map[string]Result    `json:"results"`
+	Timelines map[string][]Segment `json:"timelines"`
+	// State and Events hold the operator state and event log of a
+	// MemoryStore, which would otherwise be lost with the process.
+	State  map[string][]byte `json:"state,omitempty"`
+	Events []Event           `json:"events,omitempty"`
+}
+
+// WithSnapshot writes the latest results, status timelines, and in-memory
+// operator state to a file at path when Run returns, and restores them when
+// Run starts. a restart then neither shows every target as never checked nor
+// treats the first check of each as a status change. what is already in the
+// store is kept over the snapshot.
+func WithSnapshot(path string) Option {
+	return func(o *options) { o.snapshotPath = path }
+}
+
+// takeSnapshot writes the snapshot file, replacing the previous one only
+// once the new one is complete.
+func (c *Checker) takeSnapshot(ctx context.Context) error {
+	results, err := c.resultStore().GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("kenko: snapshot: %w", err)
+	}
+
+	snap := snapshotFile{
+		Version:   snapshotVersion,
+		TakenAt:   time.Now(),
+		Results:   results,
+		Timelines: make(map[string][]Segment),
+	}
+	for _, t := range c.targetList() {
+		if segs := c.timeline.get(t.Name); len(segs) > 0 {
+			snap.Timelines[t.Name] = segs
+		}
+	}
+	if m, ok := c.store.(*MemoryStore); ok {
+		m.mu.RLock()
+		snap.State = make(map[string][]byte, len(m.state))
+		for k, v := range m.state {
+			snap.State[k] = v
+		}
+		snap.Events = slices.Clone(m.events)
+		m.mu.RUnlock()
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("kenko: snapshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.snapshot), 0o755); err != nil {
+		return fmt.Errorf("kenko: snapshot: %w", err)
+	}
+	tmp := c.snapshot + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("kenko: snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, c.snapshot); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("kenko: snapshot: %w", err)
+	}
+	return nil
+}
+
+// restoreSnapshot loads the snapshot file, if there is one, for the
+// configured targets. a target's result and timeline are only restored when
+// the store and checker have none yet.
+func (c *Checker) restoreSnapshot(ctx context.Context) error {
+	data, err := os.ReadFile(c.snapshot)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("kenko: restore snapshot: %w", err)
+	}
+
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("kenko: restore snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("kenko: restore snapshot: unsupported version %d", snap.Version)
+	}
+
+	stored, err := c.resultStore().GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("kenko: restore snapshot: %w", err)
+	}
+	for _, t := range c.targetList() {
+		if r, ok := snap.Results[t.Name]; ok {
+			if _, exists := stored[t.Name]; !exists {
+				if err := c.resultStore().Set(ctx, t.Name, r); err != nil {
+					return fmt.Errorf("kenko: restore snapshot: %w", err)
+				}
+			}
+		}
+		if segs, ok := snap.Timelines[t.Name]; ok {
+			c.timeline.restore(t.Name, segs)
+		}
+	}
+
+	if m, ok := c.store.(*MemoryStore); ok {
+		m.mu.Lock()
+		for k, v := range snap.State {
+			if _, exists := m.state[k]; !exists {
+				m.state[k] = v
+			}
+		}
+		if len(m.events) == 0 {
+			m.events = snap.Events
+		}
+		m.mu.Unlock()
+	}
+
+	if c.cache != nil {
+		c.cache.invalidate()
+	}
+	c.logger.Info("restored snapshot", "path", c.snapshot, "taken_at", snap.TakenAt)
+	return nil
+}
@@ -0,0 +1,145 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kenko", "snapshot.json")
+	ctx := context.Background()
+	at := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithTarget("web", "https://web.example.com"), WithSnapshot(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []Result{
+		{Target: "api", Status: StatusUnhealthy, CheckedAt: at},
+		{Target: "web", Status: StatusHealthy, CheckedAt: at},
+	} {
+		c.store.Set(ctx, r.Target, r)
+		c.timeline.observe(r)
+	}
+	if _, err := c.ExcludeIncident(ctx, "api", incidentID(at), "planned maintenance", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.takeSnapshot(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// a result already in the store wins over the snapshot
+	store := NewMemoryStore()
+	store.Set(ctx, "web", Result{Target: "web", Status: StatusDegraded})
+	restarted, err := NewChecker(WithTarget("api", "https://api.example.com"), WithTarget("web", "https://web.example.com"), WithStore(store), WithSnapshot(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restarted.restoreSnapshot(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := restarted.loadExclusions(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	results, _ := restarted.Results()
+	if results["api"].Status != StatusUnhealthy || results["web"].Status != StatusDegraded {
+		t.Errorf("results = %+v, want api from the snapshot and web from the store", results)
+	}
+	if status, ok := restarted.timeline.current("api"); !ok || status != StatusUnhealthy {
+		t.Errorf("api timeline status = %q, %v, want unhealthy", status, ok)
+	}
+	if incs := restarted.Incidents("api"); len(incs) != 1 || incs[0].Exclusion == nil {
+		t.Errorf("incidents = %+v, want the excluded one", incs)
+	}
+}
+
+func TestSnapshot_Missing(t *testing.T) {
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithSnapshot(filepath.Join(t.TempDir(), "none.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.restoreSnapshot(context.Background()); err != nil {
+		t.Errorf("missing snapshot: %v", err)
+	}
+}
+
+func TestRun_WritesSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	c, err := NewChecker(WithTarget("api", "http://127.0.0.1:1"), WithInterval(time.Hour), WithSnapshot(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	for !c.Ready() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("snapshot not written: %v", err)
+	}
+}
+
+// gatedStore is a MemoryStore whose batch writes wait for release.
+type gatedStore struct {
+	*MemoryStore
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (s *gatedStore) SetBatch(ctx context.Context, results []Result) error {
+	select {
+	case s.writing <- struct{}{}:
+	default:
+	}
+	<-s.release
+	for _, r := range results {
+		s.MemoryStore.Set(ctx, r.Target, r)
+	}
+	return nil
+}
+
+func TestRun_SnapshotAfterWriteBehind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	store := &gatedStore{MemoryStore: NewMemoryStore(), writing: make(chan struct{}, 1), release: make(chan struct{})}
+	c, err := NewChecker(WithTarget("api", "http://127.0.0.1:1"), WithInterval(time.Hour), WithStore(store), WithWriteBehind(10), WithSnapshot(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	// the first check's result is being written when the checker stops
+	<-store.writing
+	cancel()
+	time.AfterFunc(50*time.Millisecond, func() { close(store.release) })
+	<-done
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := snap.Results["api"]; !ok {
+		t.Errorf("snapshot results = %+v, want the result the writer drained", snap.Results)
+	}
+}
@@ -0,0 +1,40 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// saveState persists v as JSON under key when the store implements
+// StateStore. without one, operator state only lives in memory.
+func (c *Checker) saveState(ctx context.Context, key string, v any) error {
+	ss, ok := c.store.(StateStore)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("kenko: marshal %s: %w", key, err)
+	}
+	return ss.SaveState(ctx, key, data)
+}
+
+// loadState decodes the JSON stored under key into v. it reports false, and
+// leaves v untouched, when nothing is stored or the store has no state.
+func (c *Checker) loadState(ctx context.Context, key string, v any) (bool, error) {
+	ss, ok := c.store.(StateStore)
+	if !ok {
+		return false, nil
+	}
+
+	data, err := ss.LoadState(ctx, key)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("kenko: decode %s: %w", key, err)
+	}
+	return true, nil
+}
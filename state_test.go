@@ -0,0 +1,45 @@
+package kenko
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSaveLoadState(t *testing.T) {
+	c := newCheckerFromFields(NewMemoryStore(), nil)
+
+	want := map[string]int{"a": 1}
+	if err := c.saveState(context.Background(), "k", want); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]int
+	ok, err := c.loadState(context.Background(), "k", &got)
+	if err != nil || !ok || got["a"] != 1 {
+		t.Errorf("loadState = %v, %v, %v, want the saved value", got, ok, err)
+	}
+
+	if ok, err := c.loadState(context.Background(), "missing", &got); ok || err != nil {
+		t.Errorf("missing key: ok = %v, err = %v, want false, nil", ok, err)
+	}
+}
+
+func TestLoadState_WithoutStateStore(t *testing.T) {
+	c := newCheckerFromFields(resultsOnlyStore{}, nil)
+
+	if err := c.saveState(context.Background(), "k", 1); err != nil {
+		t.Errorf("saveState without a StateStore = %v, want nil", err)
+	}
+	var v int
+	if ok, err := c.loadState(context.Background(), "k", &v); ok || err != nil {
+		t.Errorf("loadState = %v, %v, want false, nil", ok, err)
+	}
+}
+
+// resultsOnlyStore implements Store but not StateStore.
+type resultsOnlyStore struct{}
+
+func (resultsOnlyStore) Set(context.Context, string, Result) error { return nil }
+func (resultsOnlyStore) GetAll(context.Context) (map[string]Result, error) {
+	return nil, nil
+}
@@ -0,0 +1,192 @@
+package kenko
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StatusPage configures the customer-facing status page: which targets are
+// shown, under what names, and the wording used for their statuses. Locales
+// translate any of it per language.
+type StatusPage struct {
+	Title       string
+	Description string
+
+	// Components lists the targets shown on the page, in order. when empty
+	// every target is shown under its own name.
+	Components []Component
+
+	// Statuses overrides the label shown for a status, e.g. "All good" for
+	// healthy. see DefaultStatusLabels.
+	Statuses map[Status]string
+
+	// Locales holds translations keyed by language tag, e.g. "fr" or "pt-BR".
+	// fields a locale leaves empty fall back to the defaults above.
+	Locales map[string]StatusPageLocale
+}
+
+// Component is a target as presented on the status page.
+type Component struct {
+	Target      string
+	Name        string
+	Description string
+}
+
+// ComponentText is the translated name and description of a component.
+type ComponentText struct {
+	Name        string
+	Description string
+}
+
+// StatusPageLocale translates the status page into one language.
+type StatusPageLocale struct {
+	Title       string
+	Description string
+	// Components is keyed by target name.
+	Components map[string]ComponentText
+	Statuses   map[Status]string
+}
+
+// DefaultStatusLabels are the status page labels used unless overridden.
+var DefaultStatusLabels = map[Status]string{
+	StatusHealthy:     "operational",
+	StatusDegraded:    "degraded performance",
+	StatusUnhealthy:   "outage",
+	StatusUnknown:     "unknown",
+	StatusPaused:      "paused",
+	StatusMaintenance: "under maintenance",
+}
+
+// statusSeverity orders statuses from best to worst for the page's overall status.
+var statusSeverity = map[Status]int{
+	StatusHealthy:     0,
+	StatusPaused:      0,
+	StatusMaintenance: 1,
+	StatusUnknown:     2,
+	StatusDegraded:    3,
+	StatusUnhealthy:   4,
+}
+
+// normalizeStatusPage rewrites component targets through the name normalizer
+// and checks that they name configured targets.
+func normalizeStatusPage(sp *StatusPage, targets []Target, fn NameNormalizer) error {
+	known := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		known[t.Name] = true
+	}
+
+	normalize := func(name string) (string, error) {
+		if fn == nil {
+			return name, nil
+		}
+		return fn(name)
+	}
+
+	sp.Components = slices.Clone(sp.Components)
+	sp.Locales = maps.Clone(sp.Locales)
+
+	seen := make(map[string]bool, len(sp.Components))
+	for i, comp := range sp.Components {
+		name, err := normalize(comp.Target)
+		if err != nil {
+			return fmt.Errorf("kenko: status page component %q: %w", comp.Target, err)
+		}
+		if !known[name] {
+			return fmt.Errorf("kenko: status page component %q is not a target", comp.Target)
+		}
+		if seen[name] {
+			return fmt.Errorf("kenko: status page lists target %q twice", name)
+		}
+		seen[name] = true
+		sp.Components[i].Target = name
+	}
+
+	for tag, loc := range sp.Locales {
+		if len(loc.Components) == 0 {
+			continue
+		}
+		comps := make(map[string]ComponentText, len(loc.Components))
+		for target, text := range loc.Components {
+			name, err := normalize(target)
+			if err != nil {
+				return fmt.Errorf("kenko: status page locale %q component %q: %w", tag, target, err)
+			}
+			comps[name] = text
+		}
+		loc.Components = comps
+		sp.Locales[tag] = loc
+	}
+	return nil
+}
+
+// locale picks the best translation for the requested language tags, which
+// are tried in order first exactly and then by their primary subtag (so
+// "fr-CA" falls back to "fr"). it returns the empty tag for the defaults.
+func (sp *StatusPage) locale(tags []string) (string, StatusPageLocale) {
+	for _, tag := range tags {
+		for name, loc := range sp.Locales {
+			if strings.EqualFold(name, tag) {
+				return name, loc
+			}
+		}
+		primary, _, _ := strings.Cut(tag, "-")
+		for name, loc := range sp.Locales {
+			if strings.EqualFold(name, primary) {
+				return name, loc
+			}
+		}
+	}
+	return "", StatusPageLocale{}
+}
+
+// statusLabel returns the label for s, preferring the locale, then the
+// page's overrides, then DefaultStatusLabels.
+func (sp *StatusPage) statusLabel(loc StatusPageLocale, s Status) string {
+	if l := loc.Statuses[s]; l != "" {
+		return l
+	}
+	if l := sp.Statuses[s]; l != "" {
+		return l
+	}
+	if l := DefaultStatusLabels[s]; l != "" {
+		return l
+	}
+	return string(s)
+}
+
+// acceptLanguages returns the language tags of an Accept-Language header in
+// the order given, skipping the wildcard.
+func acceptLanguages(header string) []string {
+	var tags []string
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag != "" && tag != "*" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// components returns the targets shown on the status page, dropping any that
+// are no longer configured.
+func (c *Checker) components() []Component {
+	targets := c.targetList()
+	if len(c.statusPage.Components) == 0 {
+		out := make([]Component, 0, len(targets))
+		for _, t := range targets {
+			out = append(out, Component{Target: t.Name, Name: t.Name})
+		}
+		return out
+	}
+
+	out := make([]Component, 0, len(c.statusPage.Components))
+	for _, comp := range c.statusPage.Components {
+		if c.hasTarget(comp.Target) {
+			out = append(out, comp)
+		}
+	}
+	return out
+}
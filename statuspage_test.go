@@ -0,0 +1,85 @@
+package kenko
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatusPage_Locale(t *testing.T) {
+	sp := StatusPage{
+		Locales: map[string]StatusPageLocale{
+			"fr":    {Title: "État"},
+			"pt-BR": {Title: "Situação"},
+		},
+	}
+
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{[]string{"fr"}, "fr"},
+		{[]string{"FR-ca"}, "fr"},
+		{[]string{"pt-br"}, "pt-BR"},
+		{[]string{"de", "fr"}, "fr"},
+		{[]string{"de"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got, _ := sp.locale(tt.tags); got != tt.want {
+			t.Errorf("locale(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestStatusPage_StatusLabel(t *testing.T) {
+	sp := StatusPage{Statuses: map[Status]string{StatusHealthy: "All good"}}
+	fr := StatusPageLocale{Statuses: map[Status]string{StatusHealthy: "Opérationnel"}}
+
+	if got := sp.statusLabel(fr, StatusHealthy); got != "Opérationnel" {
+		t.Errorf("locale label = %q", got)
+	}
+	if got := sp.statusLabel(StatusPageLocale{}, StatusHealthy); got != "All good" {
+		t.Errorf("override label = %q", got)
+	}
+	if got := sp.statusLabel(fr, StatusUnhealthy); got != "outage" {
+		t.Errorf("default label = %q", got)
+	}
+}
+
+func TestAcceptLanguages(t *testing.T) {
+	got := acceptLanguages("fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5")
+	if !slices.Equal(got, []string{"fr-CH", "fr", "en"}) {
+		t.Errorf("acceptLanguages = %v", got)
+	}
+}
+
+func TestNewChecker_StatusPageComponents(t *testing.T) {
+	c, err := NewChecker(
+		WithNameNormalizer(SlugName),
+		WithTarget("Public API", "https://api.example.com"),
+		WithStatusPage(StatusPage{
+			Components: []Component{{Target: "Public API", Name: "API"}},
+			Locales: map[string]StatusPageLocale{
+				"fr": {Components: map[string]ComponentText{"Public API": {Name: "L'API"}}},
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.statusPage.Components[0].Target; got != "public-api" {
+		t.Errorf("component target = %q, want it normalized", got)
+	}
+	if _, ok := c.statusPage.Locales["fr"].Components["public-api"]; !ok {
+		t.Error("expected locale component keys to be normalized")
+	}
+
+	for name, sp := range map[string]StatusPage{
+		"unknown":   {Components: []Component{{Target: "db"}}},
+		"duplicate": {Components: []Component{{Target: "public-api"}, {Target: "Public API"}}},
+	} {
+		if _, err := NewChecker(WithNameNormalizer(SlugName), WithTarget("Public API", "https://api.example.com"), WithStatusPage(sp)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
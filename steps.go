@@ -0,0 +1,227 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Step is one request in a multi-step synthetic check. the URL, header values,
+// and body are Go templates evaluated against the target's variables and the
+// values extracted by earlier steps, e.g. "Bearer {{.token}}".
+type Step struct {
+	Name   string
+	Method string // default GET
+	URL    string
+	Header http.Header
+	Body   string
+
+	// ExpectStatus is the required response status code. when zero any
+	// status below 400 passes.
+	ExpectStatus     int
+	HeaderAssertions []HeaderAssertion
+	Extract          []Extraction
+}
+
+// Extraction captures a value from a step's response for use by later steps.
+// exactly one of Header or JSON should be set.
+type Extraction struct {
+	Name string
+	// Header is the response header to read.
+	Header string
+	// JSON is a dot-separated path into the JSON body, e.g. "data.items.0.id".
+	JSON string
+}
+
+// StepResult holds the outcome of a single step of a synthetic check.
+type StepResult struct {
+	Name       string        `json:"name"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Latency    time.Duration `json:"latency"`
+	Error      string        `json:"error,omitempty"`
+	// Body holds the start of the response body when the step failed.
+	Body string `json:"body,omitempty"`
+}
+
+func (s Step) label(i int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// checkSteps runs the target's steps in order, stopping at the first failure.
+func (c *Checker) checkSteps(ctx context.Context, target Target) Result {
+	start := time.Now()
+	vars := c.templateVars(target)
+	client := c.clientFor(target)
+
+	result := Result{
+		Target: target.Name,
+		URL:    target.URL,
+		Status: StatusHealthy,
+	}
+
+	for i, step := range target.Steps {
+		sr := c.runStep(ctx, client, target, step, vars)
+		sr.Name = step.label(i)
+		result.Steps = append(result.Steps, sr)
+		result.StatusCode = sr.StatusCode
+
+		if sr.Error != "" {
+			result.Status = StatusUnhealthy
+			result.Error = fmt.Sprintf("step %s: %s", sr.Name, sr.Error)
+			break
+		}
+	}
+
+	result.Latency = time.Since(start)
+	result.CheckedAt = time.Now()
+	return result
+}
+
+func (c *Checker) runStep(ctx context.Context, client *http.Client, target Target, step Step, vars map[string]string) StepResult {
+	start := time.Now()
+	fail := func(format string, args ...any) StepResult {
+		return StepResult{Latency: time.Since(start), Error: fmt.Sprintf(format, args...)}
+	}
+
+	url, err := render(step.URL, vars)
+	if err != nil {
+		return fail("url: %v", err)
+	}
+	body, err := render(step.Body, vars)
+	if err != nil {
+		return fail("body: %v", err)
+	}
+
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return fail("bad request: %v", err)
+	}
+	c.applyHeaders(req, target)
+	for k, values := range step.Header {
+		for i, v := range values {
+			rendered, err := render(v, vars)
+			if err != nil {
+				return fail("header %s: %v", k, err)
+			}
+			if i == 0 {
+				req.Header.Set(k, rendered)
+			} else {
+				req.Header.Add(k, rendered)
+			}
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fail("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	sr := StepResult{StatusCode: resp.StatusCode, Latency: time.Since(start)}
+	if err != nil {
+		sr.Error = fmt.Sprintf("reading body: %v", err)
+		return sr
+	}
+
+	failed := func(format string, args ...any) StepResult {
+		sr.Error = fmt.Sprintf(format, args...)
+		if n := failureBodyLimit(target); n > 0 {
+			sr.Body = snippet(respBody, n)
+		}
+		return sr
+	}
+
+	switch {
+	case step.ExpectStatus != 0 && resp.StatusCode != step.ExpectStatus:
+		return failed("status %d, want %d", resp.StatusCode, step.ExpectStatus)
+	case step.ExpectStatus == 0 && resp.StatusCode >= 400:
+		return failed("status %d", resp.StatusCode)
+	}
+
+	for _, a := range step.HeaderAssertions {
+		if err := a.check(resp.Header); err != nil {
+			return failed("assertion failed: %v", err)
+		}
+	}
+
+	for _, ex := range step.Extract {
+		v, err := ex.extract(resp.Header, respBody)
+		if err != nil {
+			return failed("extract %s: %v", ex.Name, err)
+		}
+		vars[ex.Name] = v
+	}
+
+	return sr
+}
+
+func (ex Extraction) extract(h http.Header, body []byte) (string, error) {
+	if ex.Header != "" {
+		v := h.Get(ex.Header)
+		if v == "" {
+			return "", fmt.Errorf("header %s missing", ex.Header)
+		}
+		return v, nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("decoding json: %w", err)
+	}
+	return lookupJSON(doc, ex.JSON)
+}
+
+// lookupJSON walks a dot-separated path through decoded JSON, treating numeric
+// segments as array indices, and returns the leaf formatted as a string.
+func lookupJSON(doc any, path string) (string, error) {
+	cur := doc
+	for _, seg := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[seg]
+			if !ok {
+				return "", fmt.Errorf("json path %q: key %q not found", path, seg)
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", fmt.Errorf("json path %q: bad index %q", path, seg)
+			}
+			cur = v[i]
+		default:
+			return "", fmt.Errorf("json path %q: cannot descend into %q", path, seg)
+		}
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("json path %q: value is null", path)
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,129 @@
+package kenko
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckSteps_ExtractAndReuse(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Session", "sess-1")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"token": "abc"}})
+	})
+	mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" || r.URL.Query().Get("s") != "sess-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	steps := []Step{
+		{
+			Name:    "login",
+			Method:  http.MethodPost,
+			URL:     ts.URL + "/login",
+			Body:    `{"user":"probe"}`,
+			Extract: []Extraction{{Name: "token", JSON: "data.token"}, {Name: "session", Header: "X-Session"}},
+		},
+		{
+			Name:         "me",
+			URL:          ts.URL + "/me?s={{.session}}",
+			Header:       http.Header{"Authorization": {"Bearer {{.token}}"}},
+			ExpectStatus: http.StatusOK,
+		},
+	}
+
+	c, err := NewChecker(WithTarget("flow", ts.URL, WithSteps(steps...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+
+	if result.Status != StatusHealthy {
+		t.Fatalf("status = %q, want %q (error: %s)", result.Status, StatusHealthy, result.Error)
+	}
+	if len(result.Steps) != 2 {
+		t.Fatalf("steps = %d, want 2", len(result.Steps))
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("status_code = %d, want 200", result.StatusCode)
+	}
+}
+
+func TestCheckSteps_StopsAtFirstFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("access denied"))
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("flow", ts.URL, WithSteps(
+		Step{Name: "login", URL: ts.URL},
+		Step{Name: "fetch", URL: ts.URL},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+
+	if result.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q", result.Status, StatusUnhealthy)
+	}
+	if len(result.Steps) != 1 {
+		t.Errorf("steps run = %d, want 1", len(result.Steps))
+	}
+	if !strings.Contains(result.Error, "login") {
+		t.Errorf("error = %q, want step name", result.Error)
+	}
+	if len(result.Steps) > 0 && result.Steps[0].Body != "access denied" {
+		t.Errorf("step body = %q, want %q", result.Steps[0].Body, "access denied")
+	}
+}
+
+func TestCheckSteps_MissingVariable(t *testing.T) {
+	c, err := NewChecker(WithTarget("flow", "http://example.com", WithSteps(
+		Step{URL: "http://example.com/{{.missing}}"},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+	if result.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q", result.Status, StatusUnhealthy)
+	}
+}
+
+func TestLookupJSON(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"a":{"b":[{"c":1},{"c":"two"}]},"n":null}`), &doc)
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"a.b.0.c", "1", false},
+		{"a.b.1.c", "two", false},
+		{"a.b.2.c", "", true},
+		{"a.x", "", true},
+		{"n", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := lookupJSON(doc, tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("lookupJSON(%q) = %q, %v; want %q, wantErr %v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package kenko
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"time"
 )
 
 // Store persists and retrieves health check results.
@@ -16,27 +18,98 @@ type HealthChecker interface {
 	Ping(ctx context.Context) error
 }
 
+// StateStore is implemented by stores that can persist operator state such as
+// silences, pauses, and maintenance windows, so it survives restarts and is
+// shared between replicas using the same backend.
+type StateStore interface {
+	// SaveState replaces the blob stored under key.
+	SaveState(ctx context.Context, key string, data []byte) error
+	// LoadState returns the blob stored under key, or nil if there is none.
+	LoadState(ctx context.Context, key string) ([]byte, error)
+}
+
+// Deleter is implemented by stores that can drop a target's result, so targets
+// removed at runtime stop showing up in /status.
+type Deleter interface {
+	Delete(ctx context.Context, name string) error
+}
+
+// defaultMemoryHistory is how many results a MemoryStore keeps per target.
+const defaultMemoryHistory = 500
+
+// maxMemoryEvents is how many events a MemoryStore keeps across targets.
+const maxMemoryEvents = 1000
+
+// maxMemoryDeliveries is how many deliveries a MemoryStore keeps across
+// notifiers.
+const maxMemoryDeliveries = 1000
+
 // MemoryStore is an in-memory Store implementation safe for concurrent use.
+// besides the latest result it keeps the most recent results of each target
+// in a ring buffer, so history works without an external store.
 type MemoryStore struct {
-	mu      sync.RWMutex
-	results map[string]Result
+	mu          sync.RWMutex
+	results     map[string]Result
+	state       map[string][]byte
+	history     map[string]*ring
+	historySize int
+	events      []Event
+	deliveries  []Delivery
+	rollups     map[rollupKey][]Rollup
+}
+
+type rollupKey struct {
+	name       string
+	resolution time.Duration
+}
+
+// MemoryStoreOption configures a MemoryStore.
+type MemoryStoreOption func(*MemoryStore)
+
+// WithHistorySize sets how many results the store keeps per target (default
+// 500). 0 keeps only the latest result.
+func WithHistorySize(n int) MemoryStoreOption {
+	return func(m *MemoryStore) { m.historySize = n }
 }
 
 // NewMemoryStore returns an initialized MemoryStore.
-func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{
-		results: make(map[string]Result),
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	m := &MemoryStore{
+		results:     make(map[string]Result),
+		state:       make(map[string][]byte),
+		history:     make(map[string]*ring),
+		historySize: defaultMemoryHistory,
+		rollups:     make(map[rollupKey][]Rollup),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // Set stores a result keyed by target name.
 func (m *MemoryStore) Set(_ context.Context, name string, result Result) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.results[name] = result
+	m.set(name, result)
 	return nil
 }
 
+// set stores the result and appends it to the target's history. the caller
+// holds m.mu.
+func (m *MemoryStore) set(name string, result Result) {
+	m.results[name] = result
+	if m.historySize <= 0 {
+		return
+	}
+	r, ok := m.history[name]
+	if !ok {
+		r = &ring{buf: make([]Result, 0, min(m.historySize, 16))}
+		m.history[name] = r
+	}
+	r.push(result, m.historySize)
+}
+
 // GetAll returns a copy of all stored results.
 func (m *MemoryStore) GetAll(_ context.Context) (map[string]Result, error) {
 	m.mu.RLock()
@@ -48,3 +121,248 @@ func (m *MemoryStore) GetAll(_ context.Context) (map[string]Result, error) {
 	}
 	return out, nil
 }
+
+// Delete removes the result stored for name.
+func (m *MemoryStore) Delete(_ context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.results, name)
+	delete(m.history, name)
+	return nil
+}
+
+// SetBatch stores several results at once, keyed by their target name.
+func (m *MemoryStore) SetBatch(_ context.Context, results []Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range results {
+		m.set(r.Target, r)
+	}
+	return nil
+}
+
+// History returns the target's kept results matching q, newest first, or
+// ErrNoHistory when the store keeps only the latest result.
+func (m *MemoryStore) History(_ context.Context, name string, q HistoryQuery) ([]Result, error) {
+	if m.historySize <= 0 {
+		return nil, ErrNoHistory
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []Result
+	r := m.history[name]
+	if r == nil {
+		return out, nil
+	}
+	for i := r.len() - 1; i >= 0; i-- {
+		res := r.at(i)
+		if !q.To.IsZero() && res.CheckedAt.After(q.To) {
+			continue
+		}
+		if !q.From.IsZero() && res.CheckedAt.Before(q.From) {
+			continue
+		}
+		out = append(out, res)
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// PruneHistory drops the target's kept results checked before before.
+func (m *MemoryStore) PruneHistory(_ context.Context, name string, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.history[name]
+	if r == nil {
+		return 0, nil
+	}
+	kept := make([]Result, 0, r.len())
+	for i := range r.len() {
+		if res := r.at(i); !res.CheckedAt.Before(before) {
+			kept = append(kept, res)
+		}
+	}
+	pruned := r.len() - len(kept)
+	r.buf, r.start = kept, 0
+	return pruned, nil
+}
+
+// AddEvent appends an event to the log, dropping the oldest beyond 1000.
+func (m *MemoryStore) AddEvent(_ context.Context, e Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	if len(m.events) > maxMemoryEvents {
+		m.events = slices.Delete(m.events, 0, len(m.events)-maxMemoryEvents)
+	}
+	return nil
+}
+
+// Events returns the kept events matching q, newest first.
+func (m *MemoryStore) Events(_ context.Context, q EventQuery) ([]Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []Event
+	for _, e := range slices.Backward(m.events) {
+		if !q.matches(e) {
+			continue
+		}
+		out = append(out, e)
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// AddDelivery appends a delivery to the log, dropping the oldest beyond 1000.
+func (m *MemoryStore) AddDelivery(_ context.Context, d Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, d)
+	if len(m.deliveries) > maxMemoryDeliveries {
+		m.deliveries = slices.Delete(m.deliveries, 0, len(m.deliveries)-maxMemoryDeliveries)
+	}
+	return nil
+}
+
+// Deliveries returns the kept deliveries matching q, newest first.
+func (m *MemoryStore) Deliveries(_ context.Context, q DeliveryQuery) ([]Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []Delivery
+	for _, d := range slices.Backward(m.deliveries) {
+		if !q.matches(d) {
+			continue
+		}
+		out = append(out, d)
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// PruneDeliveries drops deliveries from before before.
+func (m *MemoryStore) PruneDeliveries(_ context.Context, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := slices.DeleteFunc(m.deliveries, func(d Delivery) bool { return d.At.Before(before) })
+	pruned := len(m.deliveries) - len(kept)
+	m.deliveries = kept
+	return pruned, nil
+}
+
+// PruneTransitions drops events from before before.
+func (m *MemoryStore) PruneTransitions(_ context.Context, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := slices.DeleteFunc(m.events, func(e Event) bool { return e.At.Before(before) })
+	pruned := len(m.events) - len(kept)
+	m.events = kept
+	return pruned, nil
+}
+
+// SaveRollups stores the rollups, replacing any with the same start.
+func (m *MemoryStore) SaveRollups(_ context.Context, name string, resolution time.Duration, rollups []Rollup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := rollupKey{name, resolution}
+	kept := m.rollups[key]
+	for _, r := range rollups {
+		i, found := slices.BinarySearchFunc(kept, r.Start, func(k Rollup, t time.Time) int { return k.Start.Compare(t) })
+		if found {
+			kept[i] = r
+		} else {
+			kept = slices.Insert(kept, i, r)
+		}
+	}
+	m.rollups[key] = kept
+	return nil
+}
+
+// Rollups returns the target's rollups starting between from and to, oldest
+// first.
+func (m *MemoryStore) Rollups(_ context.Context, name string, resolution time.Duration, from, to time.Time) ([]Rollup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []Rollup
+	for _, r := range m.rollups[rollupKey{name, resolution}] {
+		if !from.IsZero() && r.Start.Before(from) || !to.IsZero() && r.Start.After(to) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// PruneRollups drops rollups of the resolution starting before before.
+func (m *MemoryStore) PruneRollups(_ context.Context, resolution time.Duration, before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pruned := 0
+	for key, rollups := range m.rollups {
+		if key.resolution != resolution {
+			continue
+		}
+		kept := slices.DeleteFunc(rollups, func(r Rollup) bool { return r.Start.Before(before) })
+		pruned += len(rollups) - len(kept)
+		m.rollups[key] = kept
+	}
+	return pruned, nil
+}
+
+// ring is a bounded buffer of results, oldest first.
+type ring struct {
+	buf   []Result
+	start int
+}
+
+// push appends r, overwriting the oldest result once size are held.
+func (rg *ring) push(r Result, size int) {
+	if len(rg.buf) < size {
+		rg.buf = append(rg.buf, r)
+		return
+	}
+	rg.buf[rg.start] = r
+	rg.start = (rg.start + 1) % len(rg.buf)
+}
+
+func (rg *ring) len() int { return len(rg.buf) }
+
+// at returns the i-th oldest result.
+func (rg *ring) at(i int) Result {
+	return rg.buf[(rg.start+i)%len(rg.buf)]
+}
+
+// SaveState stores a copy of data under key.
+func (m *MemoryStore) SaveState(_ context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// LoadState returns a copy of the data stored under key, or nil.
+func (m *MemoryStore) LoadState(_ context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.state[key]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
@@ -2,6 +2,7 @@ package kenko
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 )
@@ -72,3 +73,85 @@ func TestMemoryStore_GetAllReturnsCopy(t *testing.T) {
 		t.Error("GetAll should return a copy, not a reference to internal state")
 	}
 }
+
+func TestMemoryStore_State(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	data, err := s.LoadState(ctx, "silences")
+	if err != nil || data != nil {
+		t.Fatalf("LoadState on empty store = %q, %v; want nil, nil", data, err)
+	}
+
+	buf := []byte(`[{"id":"1"}]`)
+	if err := s.SaveState(ctx, "silences", buf); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+	buf[0] = 'x' // the store must keep its own copy
+
+	data, _ = s.LoadState(ctx, "silences")
+	if string(data) != `[{"id":"1"}]` {
+		t.Errorf("LoadState = %q, want %q", data, `[{"id":"1"}]`)
+	}
+}
+
+func TestMemoryStore_Delete(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	_ = s.Set(ctx, "api", Result{Target: "api"})
+	_ = s.Set(ctx, "web", Result{Target: "web"})
+
+	if err := s.Delete(ctx, "api"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	results, _ := s.GetAll(ctx)
+	if _, ok := results["api"]; ok || len(results) != 1 {
+		t.Errorf("results = %v, want only web", results)
+	}
+}
+
+func TestMemoryStore_History(t *testing.T) {
+	s := NewMemoryStore(WithHistorySize(3))
+	ctx := context.Background()
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		s.Set(ctx, "api", Result{Target: "api", StatusCode: i, CheckedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	s.SetBatch(ctx, []Result{{Target: "db", StatusCode: 9, CheckedAt: start}})
+
+	codes := func(q HistoryQuery) []int {
+		results, err := s.History(ctx, "api", q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []int
+		for _, r := range results {
+			out = append(out, r.StatusCode)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		q    HistoryQuery
+		want []int
+	}{
+		{HistoryQuery{}, []int{4, 3, 2}},
+		{HistoryQuery{Limit: 2}, []int{4, 3}},
+		{HistoryQuery{To: start.Add(3 * time.Minute)}, []int{3, 2}},
+		{HistoryQuery{From: start.Add(3 * time.Minute)}, []int{4, 3}},
+	} {
+		if got := codes(tc.q); !slices.Equal(got, tc.want) {
+			t.Errorf("History(%+v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+
+	if db, _ := s.History(ctx, "db", HistoryQuery{}); len(db) != 1 {
+		t.Errorf("db history = %+v, want the batched result", db)
+	}
+	s.Delete(ctx, "api")
+	if got := codes(HistoryQuery{}); len(got) != 0 {
+		t.Errorf("history after delete = %v", got)
+	}
+}
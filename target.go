@@ -0,0 +1,318 @@
+package kenko
+
+import (
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent sent with checks unless overridden.
+const DefaultUserAgent = "kenko"
+
+// Target represents an endpoint to be health-checked.
+type Target struct {
+	Name string
+	// URL may be a Go template over the target's variables, e.g.
+	// "https://{{.Host}}/health?ts={{now.Unix}}". the env function reads
+	// environment variables.
+	URL string
+
+	// Method is the HTTP method used for checks (default GET). HEAD avoids
+	// transferring the body at all.
+	Method string
+
+	// ExpectStatus lists the response status codes that count as healthy.
+	// when empty any status below 400 does.
+	ExpectStatus []int
+
+	// MaxBodyBytes stops reading the response body after this many bytes.
+	// when zero the body is drained up to an internal 10 MiB cap.
+	MaxBodyBytes int64
+
+	// Header holds request headers sent with every check of this target.
+	// values here take precedence over the checker-wide defaults.
+	Header http.Header
+
+	// Vars are template variables for URL and step templates, layered over
+	// the checker-wide defaults.
+	Vars map[string]string
+
+	// HeaderAssertions must all hold for the target to be considered healthy.
+	HeaderAssertions []HeaderAssertion
+
+	// ContentHash enables body change detection when non-nil.
+	ContentHash *ContentHash
+
+	// Steps, when set, turn the target into a multi-step synthetic check and
+	// URL is used only for display.
+	Steps []Step
+
+	// KeepCookies persists cookies between checks of the target. multi-step
+	// checks always share cookies between their own steps.
+	KeepCookies bool
+
+	// IPVersion restricts dialing to IPv4 or IPv6. the default IPAny lets Go
+	// pick, which can hide a broken family behind happy eyeballs.
+	IPVersion IPVersion
+
+	// SourceAddr binds outbound connections to a local IP and SourceInterface
+	// to the address of a network interface. when both are empty the
+	// checker-wide default applies.
+	SourceAddr      string
+	SourceInterface string
+
+	// SampleEvery, when above 1, stores only every Nth consecutive healthy
+	// result. failures and status transitions are always stored.
+	SampleEvery int
+
+	// Revocation enables certificate revocation checking for HTTPS targets.
+	// it requires a RevocationChecker on the checker.
+	Revocation RevocationMode
+
+	// Timeouts bounds the DNS, connect, TLS and response header phases of
+	// each check separately.
+	Timeouts PhaseTimeouts
+
+	// FailureBodyBytes is how much of the response body is kept on a failed
+	// check (default 512). negative disables failure snippets.
+	FailureBodyBytes int
+
+	// ExpectEncoding lists acceptable Content-Encodings, e.g. br and gzip.
+	// they are sent as Accept-Encoding and a response served otherwise marks
+	// the target degraded.
+	ExpectEncoding []string
+
+	// Credential refreshes the target's authentication header when a check is
+	// rejected with 401 or 403.
+	Credential *Credential
+
+	// Interval and Timeout override the checker-wide check interval and
+	// request timeout for this target when non-zero.
+	Interval time.Duration
+	Timeout  time.Duration
+
+	// Schedule, when set, checks the target at the times matching a cron
+	// expression instead of every Interval. see WithSchedule.
+	Schedule string
+	cron     *cronSchedule
+
+	// Endpoints splits the target into separately checked endpoints, such as
+	// each address family or resolved IP, with an aggregate status.
+	Endpoints EndpointMode
+
+	// dialIP pins connections to dialHost to one resolved address while its
+	// endpoint is checked.
+	dialIP, dialHost string
+
+	// HeartbeatTimeout, when set, makes the target passive: it is healthy
+	// while a heartbeat was received within the timeout instead of being
+	// requested. see WithHeartbeatTimeout.
+	HeartbeatTimeout time.Duration
+
+	// Group names a set of targets, such as the endpoints of one service,
+	// rolled up into a group status. see WithGroupQuorum.
+	Group string
+
+	// Severity is how urgent the target's failures are. see WithSeverity.
+	Severity Severity
+
+	// Tags are free-form keywords used to find the target with Search, e.g.
+	// "payments" or "customer-facing".
+	Tags []string
+
+	// Paused keeps the target listed without checking it. see WithPaused.
+	Paused bool
+
+	// Labels are free-form key/value pairs such as team=payments or env=prod.
+	// they are copied into every Result, so they reach reporters, stores and
+	// notifiers, and can be matched with "label:team=payments" in Search.
+	Labels map[string]string
+
+	// Retries is how many times a failed check is repeated before its result
+	// is recorded, waiting RetryBackoff before the first retry and twice as
+	// long before each next one. only failures matching RetryOn are retried,
+	// or every retryable failure when it is empty. see WithRetries.
+	Retries      int
+	RetryBackoff time.Duration
+	RetryOn      []RetryCondition
+
+	// FailureThreshold and SuccessThreshold are how many consecutive failed
+	// or healthy checks it takes to change the target's status. see
+	// WithThresholds.
+	FailureThreshold int
+	SuccessThreshold int
+
+	// SlowLatency and SlowChecks send a latency alert once SlowChecks
+	// consecutive checks of the target, while it is up, took longer than
+	// SlowLatency, and its recovery once as many were faster again. see
+	// WithSlowThreshold.
+	SlowLatency time.Duration
+	SlowChecks  int
+
+	// Maintenance lists recurring windows during which the target is
+	// reported with StatusMaintenance. see WithMaintenance.
+	Maintenance []MaintenanceWindow
+}
+
+// TargetOption configures a single Target.
+type TargetOption func(*Target)
+
+// NewTarget builds a Target from a name, URL and options, as WithTarget does.
+// it is used to hand targets to SetTargets.
+func NewTarget(name, url string, opts ...TargetOption) Target {
+	t := Target{Name: name, URL: url}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return t
+}
+
+// WithHeader sets a request header for the target, overriding any default of the same name.
+func WithHeader(key, value string) TargetOption {
+	return func(t *Target) {
+		if t.Header == nil {
+			t.Header = make(http.Header)
+		}
+		t.Header.Set(key, value)
+	}
+}
+
+// WithHeaderAssertion adds a response header assertion to the target.
+func WithHeaderAssertion(a HeaderAssertion) TargetOption {
+	return func(t *Target) {
+		t.HeaderAssertions = append(t.HeaderAssertions, a)
+	}
+}
+
+// WithContentHash enables body change detection for the target. when baseline
+// is a hex sha256 the body is compared against it, otherwise against the body
+// seen on the previous check.
+func WithContentHash(baseline string) TargetOption {
+	return func(t *Target) {
+		t.ContentHash = &ContentHash{Baseline: baseline}
+	}
+}
+
+// WithSteps turns the target into a multi-step synthetic check.
+func WithSteps(steps ...Step) TargetOption {
+	return func(t *Target) {
+		t.Steps = append(t.Steps, steps...)
+	}
+}
+
+// WithVar sets a template variable for the target, overriding any default of the same name.
+func WithVar(key, value string) TargetOption {
+	return func(t *Target) {
+		if t.Vars == nil {
+			t.Vars = make(map[string]string)
+		}
+		t.Vars[key] = value
+	}
+}
+
+// WithKeepCookies persists cookies set by the target between checks.
+func WithKeepCookies() TargetOption {
+	return func(t *Target) { t.KeepCookies = true }
+}
+
+// WithIPVersion restricts the target to IPv4 or IPv6.
+func WithIPVersion(v IPVersion) TargetOption {
+	return func(t *Target) { t.IPVersion = v }
+}
+
+// WithSourceAddr binds the target's outbound connections to a local IP address.
+func WithSourceAddr(ip string) TargetOption {
+	return func(t *Target) { t.SourceAddr = ip }
+}
+
+// WithSourceInterface binds the target's outbound connections to the address
+// of the named network interface.
+func WithSourceInterface(name string) TargetOption {
+	return func(t *Target) { t.SourceInterface = name }
+}
+
+// WithSampling stores only every nth consecutive healthy result of the target,
+// while still storing every failure and status transition.
+func WithSampling(n int) TargetOption {
+	return func(t *Target) { t.SampleEvery = n }
+}
+
+// WithMethod sets the HTTP method used to check the target, e.g. HEAD.
+func WithMethod(method string) TargetOption {
+	return func(t *Target) { t.Method = method }
+}
+
+// WithExpectStatus marks the target healthy only for the given status codes,
+// e.g. 204, or 401 for an endpoint that must reject anonymous requests.
+func WithExpectStatus(codes ...int) TargetOption {
+	return func(t *Target) { t.ExpectStatus = append(t.ExpectStatus, codes...) }
+}
+
+// WithMaxBodyBytes stops reading the target's response body after n bytes.
+func WithMaxBodyBytes(n int64) TargetOption {
+	return func(t *Target) { t.MaxBodyBytes = n }
+}
+
+// WithRevocation enables certificate revocation checking for the target.
+func WithRevocation(mode RevocationMode) TargetOption {
+	return func(t *Target) { t.Revocation = mode }
+}
+
+// WithPhaseTimeouts sets separate DNS, connect, TLS handshake and response
+// header timeouts for the target.
+func WithPhaseTimeouts(pt PhaseTimeouts) TargetOption {
+	return func(t *Target) { t.Timeouts = pt }
+}
+
+// WithFailureBody keeps the first n bytes of the response body on failed
+// checks of the target. a negative n disables it.
+func WithFailureBody(n int) TargetOption {
+	return func(t *Target) { t.FailureBodyBytes = n }
+}
+
+// WithExpectEncoding requires the target to serve responses compressed with
+// one of the given encodings, e.g. WithExpectEncoding("br", "gzip").
+func WithExpectEncoding(encodings ...string) TargetOption {
+	return func(t *Target) {
+		for _, enc := range encodings {
+			t.ExpectEncoding = append(t.ExpectEncoding, strings.ToLower(enc))
+		}
+	}
+}
+
+// WithTags adds search tags to the target.
+func WithTags(tags ...string) TargetOption {
+	return func(t *Target) { t.Tags = append(t.Tags, tags...) }
+}
+
+// WithLabels adds labels to the target, replacing existing values of the same
+// keys.
+func WithLabels(labels map[string]string) TargetOption {
+	return func(t *Target) {
+		if t.Labels == nil {
+			t.Labels = make(map[string]string, len(labels))
+		}
+		maps.Copy(t.Labels, labels)
+	}
+}
+
+// WithCheckInterval checks the target every d instead of the checker-wide interval.
+func WithCheckInterval(d time.Duration) TargetOption {
+	return func(t *Target) { t.Interval = d }
+}
+
+// WithSchedule checks the target only at the times matching a cron
+// expression, e.g. "*/5 9-17 * * mon-fri" for every five minutes during
+// business hours or "CRON_TZ=Europe/Berlin 15 2 * * *" for 02:15 Berlin time.
+// it replaces the check interval, and the target is not checked on start-up
+// but at its first scheduled time.
+func WithSchedule(expr string) TargetOption {
+	return func(t *Target) { t.Schedule = expr }
+}
+
+// WithCheckTimeout bounds each check of the target by d instead of the
+// checker-wide timeout.
+func WithCheckTimeout(d time.Duration) TargetOption {
+	return func(t *Target) { t.Timeout = d }
+}
@@ -0,0 +1,299 @@
+// Package telegram is a kenko Notifier that sends status changes as a
+// Telegram bot, to a default chat or to chats picked by routes.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultAPIURL  = "https://api.telegram.org"
+	defaultTimeout = 10 * time.Second
+
+	// maxMessage is the length limit of a message's text.
+	maxMessage = 4096
+)
+
+// Route sends the notifications of matching targets to ChatID instead of the
+// notifier's default chat. a target matches when it is one of Targets or in
+// one of Groups, and has every one of Labels; empty criteria match any
+// target.
+type Route struct {
+	ChatID  string
+	Targets []string
+	Groups  []string
+	Labels  map[string]string
+}
+
+func (r Route) matches(n kenko.Notification) bool {
+	if len(r.Targets) > 0 && !slices.Contains(r.Targets, n.Target) {
+		return false
+	}
+	if len(r.Groups) > 0 && !slices.Contains(r.Groups, n.Result.Group) {
+		return false
+	}
+	for k, v := range r.Labels {
+		if got, ok := n.Result.Labels[k]; !ok || !strings.EqualFold(got, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithRoute adds a route. routes are tried in the order they were added and
+// the first match picks the chat.
+func WithRoute(r Route) Option {
+	return func(n *Notifier) { n.routes = append(n.routes, r) }
+}
+
+// WithText sets the template each message is rendered from, executed with
+// the kenko.Notification, e.g. to link a runbook from the target's labels.
+// Telegram takes a subset of HTML, which html/template escapes values for.
+func WithText(t *template.Template) Option {
+	return func(n *Notifier) { n.text = t }
+}
+
+// WithHTTPClient sets the client messages are sent with (default a client
+// with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// WithAPIURL sets the base url of the bot api (default
+// "https://api.telegram.org"), e.g. for a local bot api server.
+func WithAPIURL(base string) Option {
+	return func(n *Notifier) { n.apiURL = strings.TrimSuffix(base, "/") }
+}
+
+// Notifier sends notifications as a Telegram bot.
+type Notifier struct {
+	token  string
+	chatID string
+	routes []Route
+	text   *template.Template
+	apiURL string
+	client *http.Client
+}
+
+// New creates a Notifier sending as the bot whose token is given, to chatID
+// unless a route picks another chat. with an empty chatID, notifications no
+// route matches are not sent.
+func New(token, chatID string, opts ...Option) *Notifier {
+	n := &Notifier{
+		token:  token,
+		chatID: chatID,
+		apiURL: defaultAPIURL,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// chat returns the chat n is sent to.
+func (t *Notifier) chat(n kenko.Notification) string {
+	for _, r := range t.routes {
+		if r.matches(n) {
+			return r.ChatID
+		}
+	}
+	return t.chatID
+}
+
+// Notify sends the notification's message to its chat.
+func (t *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	chat := t.chat(n)
+	if chat == "" {
+		return nil
+	}
+	text, err := t.message(n)
+	if err != nil {
+		return kenko.Permanent(err)
+	}
+	return t.send(ctx, chat, text)
+}
+
+// NotifyDigest sends one message for several notifications to each of their
+// chats, listing the messages of the notifications for it.
+func (t *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	var chats []string
+	texts := make(map[string][]string)
+	for _, n := range ns {
+		chat := t.chat(n)
+		if chat == "" {
+			continue
+		}
+		text, err := t.message(n)
+		if err != nil {
+			return kenko.Permanent(err)
+		}
+		if _, ok := texts[chat]; !ok {
+			chats = append(chats, chat)
+		}
+		texts[chat] = append(texts[chat], text)
+	}
+	var errs []error
+	permanent := true
+	for _, chat := range chats {
+		if err := t.send(ctx, chat, digest(texts[chat])); err != nil {
+			errs = append(errs, err)
+			permanent = permanent && kenko.IsPermanent(err)
+		}
+	}
+	err := errors.Join(errs...)
+	if err == nil || permanent {
+		return err
+	}
+	// keep a permanent error for one chat from stopping the retries
+	return errors.New(err.Error())
+}
+
+// digest joins the messages of a digest, leaving out those past the
+// message length limit, as cutting one could break its html.
+func digest(texts []string) string {
+	if len(texts) == 1 {
+		return texts[0]
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>kenko: %d notifications</b>", len(texts))
+	for i, text := range texts {
+		if b.Len()+len(text)+2 > maxMessage-32 {
+			fmt.Fprintf(&b, "\n\n…and %d more", len(texts)-i)
+			break
+		}
+		b.WriteString("\n\n" + text)
+	}
+	return b.String()
+}
+
+func (t *Notifier) send(ctx context.Context, chat, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  chat,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("telegram: marshal: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.apiURL+"/bot"+t.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("telegram: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram: send to %s: %w", chat, stripURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	// failures carry a description such as "Bad Request: chat not found"
+	var result struct {
+		Description string `json:"description"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&result)
+	code := resp.StatusCode
+	err = fmt.Errorf("telegram: send to %s: status %d: %s", chat, code, result.Description)
+	if !kenko.RetryableStatus(code) {
+		// e.g. a revoked token or a chat the bot was removed from
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the message Notify would send for n.
+func (t *Notifier) Preview(n kenko.Notification) (string, error) {
+	return t.message(n)
+}
+
+// message renders the text sent for n, from the template set by WithText if
+// any.
+func (t *Notifier) message(n kenko.Notification) (string, error) {
+	if t.text == nil {
+		return message(n), nil
+	}
+	var b strings.Builder
+	if n.Test {
+		b.WriteString("[test] ")
+	}
+	if err := t.text.Execute(&b, n); err != nil {
+		return "", fmt.Errorf("telegram: text: %w", err)
+	}
+	return b.String(), nil
+}
+
+// message formats n as Telegram html: a headline naming the target and its
+// new status, then the details of the result.
+func message(n kenko.Notification) string {
+	var b strings.Builder
+	if n.Test {
+		b.WriteString("[test] ")
+	}
+	if a := n.Slow; a != nil && a.Resolved {
+		fmt.Fprintf(&b, "<b>%s</b> is fast again after %s", html.EscapeString(n.Target), n.At.Sub(a.Since).Round(time.Second))
+		fmt.Fprintf(&b, "\nLatency: %s", n.Result.Latency.Round(time.Millisecond))
+		return b.String()
+	}
+	if o := n.Outage; o != nil {
+		fmt.Fprintf(&b, "<b>%s</b> recovered after %s (was %s)", html.EscapeString(n.Target), o.Duration.Round(time.Second), n.Previous)
+		if o.FirstError != "" {
+			fmt.Fprintf(&b, "\nFirst error: <code>%s</code>", html.EscapeString(o.FirstError))
+		}
+		if o.LastError != "" && o.LastError != o.FirstError {
+			fmt.Fprintf(&b, "\nLast error: <code>%s</code>", html.EscapeString(o.LastError))
+		}
+		return b.String()
+	}
+	switch {
+	case n.Slow != nil:
+		fmt.Fprintf(&b, "<b>%s</b> is slow: over %s for %d checks", html.EscapeString(n.Target), n.Slow.Threshold, n.Slow.Checks)
+	case n.Repeat > 0:
+		fmt.Fprintf(&b, "<b>%s</b> is still %s", html.EscapeString(n.Target), n.Status)
+	default:
+		fmt.Fprintf(&b, "<b>%s</b> is %s (was %s)", html.EscapeString(n.Target), n.Status, n.Previous)
+	}
+	r := n.Result
+	if r.Error != "" {
+		fmt.Fprintf(&b, "\nError: <code>%s</code>", html.EscapeString(r.Error))
+	}
+	if r.StatusCode != 0 {
+		fmt.Fprintf(&b, "\nStatus code: %d", r.StatusCode)
+	}
+	fmt.Fprintf(&b, "\nLatency: %s", r.Latency.Round(time.Millisecond))
+	if r.Region != "" {
+		fmt.Fprintf(&b, "\nRegion: %s", html.EscapeString(r.Region))
+	}
+	return b.String()
+}
+
+// stripURL drops the request url, which holds the token, from a client
+// error.
+func stripURL(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return uerr.Err
+	}
+	return err
+}
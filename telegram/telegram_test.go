@@ -0,0 +1,155 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification {
+	n := notifytest.Notification(kenko.StatusUnhealthy)
+	n.Result.Error = "status 503 <html>"
+	return n
+}
+
+func TestNotify(t *testing.T) {
+	var got map[string]any
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	if err := New("123:secret", "-100", WithAPIURL(srv.URL)).Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bot123:secret/sendMessage" || got["chat_id"] != "-100" || got["parse_mode"] != "HTML" {
+		t.Errorf("path %s, body %v", path, got)
+	}
+	want := "<b>api</b> is unhealthy (was healthy)\nError: <code>status 503 &lt;html&gt;</code>\nStatus code: 503\nLatency: 250ms"
+	if got["text"] != want {
+		t.Errorf("text = %q, want %q", got["text"], want)
+	}
+}
+
+func TestMessage_Recovery(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Outage = &kenko.Outage{Duration: 90 * time.Second, FirstError: "timeout", LastError: "timeout"}
+	want := "<b>api</b> recovered after 1m30s (was unhealthy)\nFirst error: <code>timeout</code>"
+	if got := message(n); got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestMessage_Text(t *testing.T) {
+	tmpl := template.Must(template.New("text").Parse(`<b>{{.Target}}</b>: {{.Result.Error}} ({{.Result.Labels.team}})`))
+	n := notification()
+	n.Test = true
+	got, err := New("token", "1", WithText(tmpl)).Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[test] <b>api</b>: status 503 &lt;html&gt; (payments)"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	broken := template.Must(template.New("text").Parse(`{{.Missing}}`))
+	if err := New("token", "1", WithText(broken)).Notify(context.Background(), n); !kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent template error", err)
+	}
+}
+
+func TestNotify_Routes(t *testing.T) {
+	var chats []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ChatID string `json:"chat_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		chats = append(chats, body.ChatID)
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	n := New("token", "", WithAPIURL(srv.URL),
+		WithRoute(Route{ChatID: "search", Targets: []string{"search"}}),
+		WithRoute(Route{ChatID: "payments", Groups: []string{"payments"}, Labels: map[string]string{"team": "Payments"}}),
+	)
+	other := notification()
+	other.Target, other.Result.Group, other.Result.Labels = "docs", "", nil
+	for _, notif := range []kenko.Notification{notification(), other} {
+		if err := n.Notify(context.Background(), notif); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// docs matches no route and there is no default chat
+	if len(chats) != 1 || chats[0] != "payments" {
+		t.Errorf("sent to %v, want only payments", chats)
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+	texts := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ChatID string `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		texts[body.ChatID] = body.Text
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	n := New("token", "ops", WithAPIURL(srv.URL), WithRoute(Route{ChatID: "payments", Groups: []string{"payments"}}))
+	web := notification()
+	web.Target, web.Result.Group = "web", ""
+	db := notification()
+	db.Target = "db"
+	if err := n.NotifyDigest(context.Background(), []kenko.Notification{notification(), web, db}); err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 2 {
+		t.Fatalf("texts = %v, want one message per chat", texts)
+	}
+	if got := texts["payments"]; !strings.HasPrefix(got, "<b>kenko: 2 notifications</b>\n\n<b>api</b> is unhealthy") || !strings.Contains(got, "\n\n<b>db</b> is unhealthy") {
+		t.Errorf("payments text = %q", got)
+	}
+	if got := texts["ops"]; !strings.HasPrefix(got, "<b>web</b> is unhealthy") {
+		t.Errorf("ops text = %q", got)
+	}
+}
+
+func TestDigest_Limit(t *testing.T) {
+	texts := make([]string, 100)
+	for i := range texts {
+		texts[i] = strings.Repeat("x", 100)
+	}
+	got := digest(texts)
+	if len(got) > maxMessage || !strings.HasSuffix(got, "more") {
+		t.Errorf("digest of %d bytes ends %q", len(got), got[len(got)-20:])
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, `{"ok":false,"description":"Bad Request: chat not found"}`, "chat not found", func(url string) error {
+		return New("123:secret", "-100", WithAPIURL(url)).Notify(context.Background(), notification())
+	})
+
+	err := New("123:secret", "-100", WithAPIURL("http://127.0.0.1:1")).Notify(context.Background(), notification())
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("err = %v, want an error without the token", err)
+	}
+}
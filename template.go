@@ -0,0 +1,53 @@
+package kenko
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are available to every URL, header, and body template.
+var templateFuncs = template.FuncMap{
+	"now": time.Now,
+	"env": os.Getenv,
+}
+
+// render evaluates s as a template against vars. strings without template
+// actions are returned unchanged.
+func render(s string, vars map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("").Funcs(templateFuncs).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// templateVars merges the checker-wide variables with the target's own into a
+// fresh map that callers may extend.
+func (c *Checker) templateVars(target Target) map[string]string {
+	vars := make(map[string]string, len(c.vars)+len(target.Vars))
+	for k, v := range c.vars {
+		vars[k] = v
+	}
+	for k, v := range target.Vars {
+		vars[k] = v
+	}
+	return vars
+}
+
+// ValidateTemplate reports whether s parses as a URL, header, or body template.
+func ValidateTemplate(s string) error {
+	_, err := template.New("").Funcs(templateFuncs).Parse(s)
+	return err
+}
@@ -0,0 +1,68 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	t.Setenv("KENKO_TEST_REGION", "eu")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"https://example.com", "https://example.com", false},
+		{"https://{{.Host}}/health", "https://api.internal/health", false},
+		{`https://{{env "KENKO_TEST_REGION"}}.example.com`, "https://eu.example.com", false},
+		{"https://{{.Missing}}", "", true},
+		{"https://{{.Host", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := render(tt.in, map[string]string{"Host": "api.internal"})
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("render(%q) = %q, %v; want %q, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRender_Now(t *testing.T) {
+	got, err := render("ts={{now.Unix}}", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == "ts=" || strings.Contains(got, "{{") {
+		t.Errorf("render = %q, want timestamp", got)
+	}
+}
+
+func TestCheck_TemplatedURL(t *testing.T) {
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("test", "{{.Base}}/{{.Env}}/health", WithVar("Env", "prod")),
+		WithDefaultVar("Base", ts.URL),
+		WithDefaultVar("Env", "staging"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+
+	if result.Status != StatusHealthy {
+		t.Fatalf("status = %q, want %q (error: %s)", result.Status, StatusHealthy, result.Error)
+	}
+	if gotPath != "/prod/health" {
+		t.Errorf("path = %q, want %q", gotPath, "/prod/health")
+	}
+}
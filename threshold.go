@@ -0,0 +1,79 @@
+package kenko
+
+import "sync"
+
+// WithThresholds makes the target's status change only after failures
+// consecutive failed checks, and recover only after successes consecutive
+// healthy ones, so a single blip neither opens nor clears an incident. until
+// a threshold is reached the previous status is reported, with the status the
+// check found in Result.Observed. values of 0 and 1 change on the first
+// check.
+func WithThresholds(failures, successes int) TargetOption {
+	return func(t *Target) {
+		t.FailureThreshold = failures
+		t.SuccessThreshold = successes
+	}
+}
+
+// thresholds holds each target's reported status back until its failure or
+// success threshold is reached.
+type thresholds struct {
+	mu    sync.Mutex
+	state map[string]*thresholdState
+}
+
+type thresholdState struct {
+	// status is the reported status, and streak the number of consecutive
+	// checks that found otherwise, all failed or, when failing is false, all
+	// healthy.
+	status  Status
+	streak  int
+	failing bool
+}
+
+// apply replaces the result's status with the reported one while the
+// target's threshold for the change is not reached.
+func (th *thresholds) apply(t Target, r *Result) {
+	if t.FailureThreshold <= 1 && t.SuccessThreshold <= 1 {
+		return
+	}
+	// maintenance and pauses are reported as they are and hold nothing back
+	if r.Status == StatusMaintenance || r.Status == StatusPaused {
+		return
+	}
+
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	if th.state == nil {
+		th.state = make(map[string]*thresholdState)
+	}
+	st, ok := th.state[t.Name]
+	if !ok {
+		th.state[t.Name] = &thresholdState{status: r.Status}
+		return
+	}
+	if r.Status == st.status {
+		st.streak = 0
+		return
+	}
+
+	// a check in the other direction starts the streak over, e.g. a degraded
+	// check between healthy ones while the target is reported unhealthy
+	failing := r.Status != StatusHealthy
+	if failing != st.failing {
+		st.streak = 0
+		st.failing = failing
+	}
+	st.streak++
+	need := t.FailureThreshold
+	if r.Status == StatusHealthy {
+		need = t.SuccessThreshold
+	}
+	if st.streak >= need {
+		st.status = r.Status
+		st.streak = 0
+		return
+	}
+	r.Observed = r.Status
+	r.Status = st.status
+}
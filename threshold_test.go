@@ -0,0 +1,48 @@
+package kenko
+
+import "testing"
+
+func TestThresholds(t *testing.T) {
+	target := Target{Name: "api", FailureThreshold: 3, SuccessThreshold: 2}
+	var th thresholds
+
+	steps := []struct {
+		found    Status
+		want     Status
+		observed Status
+	}{
+		{StatusHealthy, StatusHealthy, ""},
+		{StatusUnhealthy, StatusHealthy, StatusUnhealthy},
+		{StatusUnhealthy, StatusHealthy, StatusUnhealthy},
+		// a healthy check starts the failures over
+		{StatusHealthy, StatusHealthy, ""},
+		{StatusUnhealthy, StatusHealthy, StatusUnhealthy},
+		{StatusDegraded, StatusHealthy, StatusDegraded},
+		{StatusUnhealthy, StatusUnhealthy, ""},
+		{StatusHealthy, StatusUnhealthy, StatusHealthy},
+		// a failure of another kind starts the recovery over
+		{StatusDegraded, StatusUnhealthy, StatusDegraded},
+		{StatusHealthy, StatusUnhealthy, StatusHealthy},
+		{StatusHealthy, StatusHealthy, ""},
+		{StatusMaintenance, StatusMaintenance, ""},
+		{StatusHealthy, StatusHealthy, ""},
+	}
+	for i, step := range steps {
+		r := Result{Target: "api", Status: step.found}
+		th.apply(target, &r)
+		if r.Status != step.want || r.Observed != step.observed {
+			t.Errorf("check %d found %s: status %s, observed %q, want %s, %q", i, step.found, r.Status, r.Observed, step.want, step.observed)
+		}
+	}
+}
+
+func TestThresholds_Disabled(t *testing.T) {
+	var th thresholds
+	for _, status := range []Status{StatusHealthy, StatusUnhealthy} {
+		r := Result{Target: "api", Status: status}
+		th.apply(Target{Name: "api", FailureThreshold: 1}, &r)
+		if r.Status != status || r.Observed != "" {
+			t.Errorf("result = %+v, want %s unchanged", r, status)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package kenko
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NotifyThrottle caps how many messages each notifier is sent, so a flapping
+// fleet cannot flood a channel.
+type NotifyThrottle struct {
+	// Limit is how many messages a notifier is sent within Per, a digest
+	// counting as one. the notifications of the rest are dropped and
+	// reported as NotificationThrottled, except recoveries and resolved
+	// latency alerts, which are always sent.
+	Limit int
+	Per   time.Duration
+}
+
+// WithNotifyThrottle caps how many messages each notifier is sent.
+func WithNotifyThrottle(t NotifyThrottle) Option {
+	return func(o *options) { o.throttle = &t }
+}
+
+// WithNotifyRepeat notifies a target that stays down again every interval,
+// with Notification.Repeat counting the reminders, rather than only when its
+// status changes.
+func WithNotifyRepeat(interval time.Duration) Option {
+	return func(o *options) { o.repeat = interval }
+}
+
+func validateThrottle(t *NotifyThrottle, repeat time.Duration) error {
+	if t != nil && (t.Limit < 0 || t.Per < 0) {
+		return fmt.Errorf("kenko: notification throttle must not be negative")
+	}
+	if repeat < 0 {
+		return fmt.Errorf("kenko: notification repeat interval must not be negative, got %s", repeat)
+	}
+	return nil
+}
+
+// rateLimiter allows limit events within any window of per.
+type rateLimiter struct {
+	limit int
+	per   time.Duration
+
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+func (l *rateLimiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := 0
+	for i < len(l.sent) && now.Sub(l.sent[i]) >= l.per {
+		i++
+	}
+	l.sent = l.sent[i:]
+	if len(l.sent) >= l.limit {
+		return false
+	}
+	l.sent = append(l.sent, now)
+	return true
+}
+
+// resolves reports whether n ends a failure or a latency alert.
+func resolves(n Notification) bool {
+	if n.Slow != nil {
+		return n.Slow.Resolved
+	}
+	return n.Status == StatusHealthy
+}
+
+// reminders tracks when each failing target was last notified, so it can be
+// notified again every interval while it stays down.
+type reminders struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]*reminder
+}
+
+type reminder struct {
+	previous Status
+	at       time.Time
+	count    int
+}
+
+// sent records the notification of a change, starting or ending the
+// target's reminders.
+func (r *reminders) sent(n Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n.Status == StatusHealthy {
+		delete(r.last, n.Target)
+		return
+	}
+	if r.last == nil {
+		r.last = make(map[string]*reminder)
+	}
+	r.last[n.Target] = &reminder{previous: n.Previous, at: n.At}
+}
+
+// due returns the reminder for a result of unchanged status once the
+// interval passed since the target was last notified.
+func (r *reminders) due(res Result) (Notification, bool) {
+	switch res.Status {
+	case StatusHealthy, StatusMaintenance, StatusPaused:
+		return Notification{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rem, ok := r.last[res.Target]
+	if !ok || res.CheckedAt.Sub(rem.at) < r.interval {
+		return Notification{}, false
+	}
+	rem.at = res.CheckedAt
+	rem.count++
+	return Notification{
+		Target:   res.Target,
+		Previous: rem.previous,
+		Status:   res.Status,
+		Result:   res,
+		At:       res.CheckedAt,
+		Repeat:   rem.count,
+	}, true
+}
+
+// remind queues a reminder for a target that is still down, if one is due.
+func (c *Checker) remind(r Result) {
+	if c.dispatcher == nil || c.dispatcher.remind == nil || c.silenced(r, time.Now()) {
+		return
+	}
+	if n, ok := c.dispatcher.remind.due(r); ok {
+		n.Uptime = c.Uptime(r.Target)
+		c.dispatcher.enqueue(n)
+	}
+}
@@ -0,0 +1,113 @@
+package kenko
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := &rateLimiter{limit: 2, per: time.Minute}
+	now := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	for i, tt := range []struct {
+		after time.Duration
+		want  bool
+	}{
+		{0, true},
+		{10 * time.Second, true},
+		{20 * time.Second, false},
+		{time.Minute, true},
+		{65 * time.Second, false},
+		{70 * time.Second, true},
+	} {
+		if got := l.allow(now.Add(tt.after)); got != tt.want {
+			t.Errorf("event %d at +%s: allow = %v, want %v", i, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcher_Throttle(t *testing.T) {
+	rec := &recordingNotifier{}
+	outcomes := &outcomeRecorder{}
+	d := newDispatcher(map[string]Notifier{"chat": rec}, NotifyRetry{}, slog.New(slog.NewTextHandler(io.Discard, nil)), outcomes.report)
+	d.queues[0].limiter = &rateLimiter{limit: 2, per: time.Hour}
+	for range 3 {
+		d.enqueue(Notification{Target: "api"})
+	}
+	d.enqueue(Notification{Target: "api", Previous: StatusUnhealthy, Status: StatusHealthy})
+	d.enqueue(Notification{Target: "db", Slow: &SlowAlert{Resolved: true}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.run(ctx)
+
+	if len(rec.sent) != 4 || outcomes.outcomes["chat/throttled"] != 1 {
+		t.Errorf("sent %d, outcomes %v, want 4 sent and 1 throttled", len(rec.sent), outcomes.outcomes)
+	}
+}
+
+func TestDispatcher_ThrottleDigest(t *testing.T) {
+	digest := &digestNotifier{}
+	outcomes := &outcomeRecorder{}
+	d := newDispatcher(map[string]Notifier{"chat": digest}, NotifyRetry{}, slog.New(slog.NewTextHandler(io.Discard, nil)), outcomes.report)
+	d.window = 50 * time.Millisecond
+	d.queues[0].limiter = &rateLimiter{limit: 1, per: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx)
+		close(done)
+	}()
+	for _, target := range []string{"api", "web", "db"} {
+		d.enqueue(Notification{Target: target, Status: StatusUnhealthy})
+	}
+	time.Sleep(150 * time.Millisecond)
+	d.enqueue(Notification{Target: "cdn", Status: StatusUnhealthy})
+	d.enqueue(Notification{Target: "api", Previous: StatusUnhealthy, Status: StatusHealthy})
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	if len(digest.digests) != 2 || len(digest.digests[0]) != 3 {
+		t.Fatalf("digests = %+v, want the three failures counted as one message", digest.digests)
+	}
+	if second := digest.digests[1]; len(second) != 1 || second[0].Status != StatusHealthy {
+		t.Errorf("second digest = %+v, want only the recovery", second)
+	}
+	if outcomes.outcomes["chat/throttled"] != 1 || outcomes.outcomes["chat/delivered"] != 4 {
+		t.Errorf("outcomes = %v, want 4 delivered and 1 throttled", outcomes.outcomes)
+	}
+}
+
+func TestReminders(t *testing.T) {
+	r := &reminders{interval: time.Hour}
+	start := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	down := Result{Target: "api", Status: StatusUnhealthy}
+	at := func(d time.Duration) Result {
+		res := down
+		res.CheckedAt = start.Add(d)
+		return res
+	}
+
+	if _, ok := r.due(at(2 * time.Hour)); ok {
+		t.Error("reminder due for a target never notified")
+	}
+	r.sent(Notification{Target: "api", Previous: StatusHealthy, Status: StatusUnhealthy, At: start})
+	if _, ok := r.due(at(59 * time.Minute)); ok {
+		t.Error("reminder due before the interval")
+	}
+	n, ok := r.due(at(time.Hour))
+	if !ok || n.Repeat != 1 || n.Previous != StatusHealthy || n.Status != StatusUnhealthy {
+		t.Errorf("first reminder = %+v, %v", n, ok)
+	}
+	if n, ok := r.due(at(2 * time.Hour)); !ok || n.Repeat != 2 {
+		t.Errorf("second reminder = %+v, %v", n, ok)
+	}
+
+	r.sent(Notification{Target: "api", Previous: StatusUnhealthy, Status: StatusHealthy, At: start.Add(3 * time.Hour)})
+	if _, ok := r.due(at(5 * time.Hour)); ok {
+		t.Error("reminder due after the recovery")
+	}
+}
@@ -0,0 +1,158 @@
+package kenko
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTimelineSegments bounds how many state segments are kept per target.
+const maxTimelineSegments = 500
+
+// Segment is a contiguous period during which a target held one status.
+type Segment struct {
+	Status Status    `json:"status"`
+	Start  time.Time `json:"start"`
+	// End is zero for the current, still open segment.
+	End time.Time `json:"end"`
+}
+
+// Duration returns how long the segment lasted, measuring an open segment up to now.
+func (s Segment) Duration(now time.Time) time.Duration {
+	if s.End.IsZero() {
+		return now.Sub(s.Start)
+	}
+	return s.End.Sub(s.Start)
+}
+
+// timeline records status segments per target in memory.
+type timeline struct {
+	mu       sync.Mutex
+	segments map[string][]Segment
+}
+
+// observe extends the target's current segment or starts a new one when the
+// status changed. it returns the segment the change closed, if any.
+func (tl *timeline) observe(r Result) (Segment, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.segments == nil {
+		tl.segments = make(map[string][]Segment)
+	}
+
+	var closed Segment
+	changed := false
+	segs := tl.segments[r.Target]
+	if n := len(segs); n > 0 && segs[n-1].Status == r.Status {
+		return Segment{}, false
+	} else if n > 0 {
+		segs[n-1].End = r.CheckedAt
+		closed, changed = segs[n-1], true
+	}
+
+	segs = append(segs, Segment{Status: r.Status, Start: r.CheckedAt})
+	if len(segs) > maxTimelineSegments {
+		segs = segs[len(segs)-maxTimelineSegments:]
+	}
+	tl.segments[r.Target] = segs
+	return closed, changed
+}
+
+// prune drops segments that ended before before and reports how many were
+// dropped. the current segment is always kept.
+func (tl *timeline) prune(before time.Time) int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	pruned := 0
+	for name, segs := range tl.segments {
+		i := 0
+		for i < len(segs)-1 && segs[i].End.Before(before) {
+			i++
+		}
+		if i > 0 {
+			tl.segments[name] = append([]Segment(nil), segs[i:]...)
+			pruned += i
+		}
+	}
+	return pruned
+}
+
+// restore sets the target's segments unless some were observed already.
+func (tl *timeline) restore(name string, segs []Segment) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.segments == nil {
+		tl.segments = make(map[string][]Segment)
+	}
+	if len(tl.segments[name]) == 0 {
+		tl.segments[name] = append([]Segment(nil), segs...)
+	}
+}
+
+// current returns the target's latest observed status.
+func (tl *timeline) current(name string) (Status, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	segs := tl.segments[name]
+	if len(segs) == 0 {
+		return "", false
+	}
+	return segs[len(segs)-1].Status, true
+}
+
+// failingSince returns when the target's current failure began, across the
+// changes between degraded, unhealthy, and unknown, and the status it failed
+// from. ok is false when the target is not failing.
+func (tl *timeline) failingSince(name string) (since time.Time, prev Status, ok bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	segs := tl.segments[name]
+	prev = StatusHealthy
+	for i := len(segs) - 1; i >= 0; i-- {
+		switch segs[i].Status {
+		case StatusHealthy, StatusMaintenance, StatusPaused:
+			prev = segs[i].Status
+		default:
+			since, ok = segs[i].Start, true
+			continue
+		}
+		break
+	}
+	return since, prev, ok
+}
+
+// beforeQuiet returns the last segment before the current one whose status
+// is not maintenance or paused, or false when the target never had one.
+func (tl *timeline) beforeQuiet(name string) (Segment, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	segs := tl.segments[name]
+	for i := len(segs) - 2; i >= 0; i-- {
+		if !quiet(segs[i].Status) {
+			return segs[i], true
+		}
+	}
+	return Segment{}, false
+}
+
+// quiet reports whether s is maintenance or paused, in which a target's
+// notifications wait.
+func quiet(s Status) bool {
+	return s == StatusMaintenance || s == StatusPaused
+}
+
+func (tl *timeline) get(name string) []Segment {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return append([]Segment(nil), tl.segments[name]...)
+}
+
+// Timeline returns the status segments observed for a target, oldest first.
+// the last segment is the current one and has a zero End.
+func (c *Checker) Timeline(name string) []Segment {
+	return c.timeline.get(name)
+}
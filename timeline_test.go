@@ -0,0 +1,62 @@
+package kenko
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeline_Segments(t *testing.T) {
+	var tl timeline
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, st := range []Status{StatusHealthy, StatusHealthy, StatusUnhealthy, StatusHealthy} {
+		tl.observe(Result{Target: "api", Status: st, CheckedAt: t0.Add(time.Duration(i) * time.Minute)})
+	}
+
+	segs := tl.get("api")
+	if len(segs) != 3 {
+		t.Fatalf("segments = %d, want 3", len(segs))
+	}
+	if segs[0].Status != StatusHealthy || segs[0].Duration(t0) != 2*time.Minute {
+		t.Errorf("first segment = %+v, want healthy for 2m", segs[0])
+	}
+	if segs[1].Status != StatusUnhealthy || segs[1].Duration(t0) != time.Minute {
+		t.Errorf("second segment = %+v, want unhealthy for 1m", segs[1])
+	}
+	if !segs[2].End.IsZero() {
+		t.Errorf("current segment end = %v, want zero", segs[2].End)
+	}
+}
+
+func TestTimeline_Bounded(t *testing.T) {
+	var tl timeline
+	now := time.Now()
+	for i := range maxTimelineSegments + 10 {
+		st := StatusHealthy
+		if i%2 == 1 {
+			st = StatusUnhealthy
+		}
+		tl.observe(Result{Target: "api", Status: st, CheckedAt: now.Add(time.Duration(i) * time.Second)})
+	}
+
+	if n := len(tl.get("api")); n != maxTimelineSegments {
+		t.Errorf("segments = %d, want %d", n, maxTimelineSegments)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{76 * time.Hour, "3d 4h"},
+		{90 * time.Minute, "1h 30m"},
+		{12 * time.Minute, "12m"},
+		{42 * time.Second, "42s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package kenko
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// TLSInfo describes the negotiated TLS connection of an HTTPS check.
+type TLSInfo struct {
+	Version     string     `json:"version"`
+	CipherSuite string     `json:"cipher_suite"`
+	Chain       []CertInfo `json:"chain,omitempty"`
+
+	// Revocation is the leaf certificate's revocation status ("good",
+	// "revoked", "no_staple" or "unknown") when revocation checking is enabled.
+	Revocation string `json:"revocation,omitempty"`
+}
+
+// CertInfo summarizes one certificate of the peer chain, leaf first.
+type CertInfo struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+func tlsInfo(cs *tls.ConnectionState) *TLSInfo {
+	if cs == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		Version:     tls.VersionName(cs.Version),
+		CipherSuite: tls.CipherSuiteName(cs.CipherSuite),
+	}
+	for _, cert := range cs.PeerCertificates {
+		info.Chain = append(info.Chain, CertInfo{
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			NotAfter: cert.NotAfter,
+		})
+	}
+	return info
+}
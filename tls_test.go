@@ -0,0 +1,47 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheck_TLSInfo(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("test", ts.URL),
+		WithHTTPClient(ts.Client()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := c.check(context.Background(), c.targets[0])
+
+	if result.TLS == nil {
+		t.Fatal("expected tls info for https target")
+	}
+	if result.TLS.Version == "" || result.TLS.CipherSuite == "" {
+		t.Errorf("tls = %+v, want version and cipher", result.TLS)
+	}
+	if len(result.TLS.Chain) == 0 || result.TLS.Chain[0].Issuer == "" {
+		t.Errorf("chain = %+v, want leaf certificate details", result.TLS.Chain)
+	}
+}
+
+func TestCheck_NoTLSInfoForHTTP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c, err := NewChecker(WithTarget("test", ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := c.check(context.Background(), c.targets[0]); result.TLS != nil {
+		t.Errorf("tls = %+v, want nil", result.TLS)
+	}
+}
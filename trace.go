@@ -0,0 +1,102 @@
+package kenko
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Phases breaks a check's duration down by connection phase. DNS, Connect and
+// TLS are zero when an idle connection was reused.
+type Phases struct {
+	DNS     time.Duration `json:"dns"`
+	Connect time.Duration `json:"connect"`
+	TLS     time.Duration `json:"tls"`
+	// TTFB is the time from the request being written to the first response
+	// byte, i.e. how long the server took to answer.
+	TTFB time.Duration `json:"ttfb"`
+	// Transfer is the time spent reading the response body.
+	Transfer time.Duration `json:"transfer"`
+}
+
+// phaseTrace records httptrace timestamps for one request. dialing can run on
+// another goroutine, so fields are guarded by mu.
+type phaseTrace struct {
+	mu sync.Mutex
+
+	dnsStart, dnsDone   time.Time
+	connStart, connDone time.Time
+	tlsStart, tlsDone   time.Time
+	wrote, firstByte    time.Time
+
+	remoteAddr net.Addr
+}
+
+func (p *phaseTrace) set(t *time.Time, first bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// happy eyeballs may start several dials; keep the first start and the
+	// last completion
+	if first && !t.IsZero() {
+		return
+	}
+	*t = time.Now()
+}
+
+func (p *phaseTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { p.set(&p.dnsStart, true) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { p.set(&p.dnsDone, false) },
+		ConnectStart:      func(string, string) { p.set(&p.connStart, true) },
+		ConnectDone:       func(string, string, error) { p.set(&p.connDone, false) },
+		TLSHandshakeStart: func() { p.set(&p.tlsStart, true) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { p.set(&p.tlsDone, false) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			p.mu.Lock()
+			p.remoteAddr = info.Conn.RemoteAddr()
+			p.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { p.set(&p.wrote, false) },
+		GotFirstResponseByte: func() { p.set(&p.firstByte, true) },
+	}
+}
+
+// phases returns the recorded durations, with the body finishing at end.
+func (p *phaseTrace) phases(end time.Time) *Phases {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return &Phases{
+		DNS:      since(p.dnsStart, p.dnsDone),
+		Connect:  since(p.connStart, p.connDone),
+		TLS:      since(p.tlsStart, p.tlsDone),
+		TTFB:     since(p.wrote, p.firstByte),
+		Transfer: since(p.firstByte, end),
+	}
+}
+
+// remoteIP returns the address of the connection the request was sent on,
+// or "" if none was obtained.
+func (p *phaseTrace) remoteIP() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.remoteAddr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.remoteAddr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// since returns end-start, or zero when either phase boundary was never seen.
+func since(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
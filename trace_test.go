@@ -0,0 +1,46 @@
+package kenko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheck_Phases(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	c := &Checker{client: ts.Client()}
+	target := Target{Name: "api", URL: ts.URL}
+
+	r := c.check(context.Background(), target)
+	if r.Phases == nil {
+		t.Fatal("expected phases on a single-request check")
+	}
+	if r.Phases.Connect <= 0 || r.Phases.TLS <= 0 {
+		t.Errorf("phases = %+v, want connect and tls on a new connection", r.Phases)
+	}
+	if r.Phases.TTFB < 20*time.Millisecond {
+		t.Errorf("ttfb = %v, want at least the server delay", r.Phases.TTFB)
+	}
+
+	r = c.check(context.Background(), target)
+	if r.Phases.Connect != 0 || r.Phases.TLS != 0 {
+		t.Errorf("phases = %+v, want no connect or tls on a reused connection", r.Phases)
+	}
+}
+
+func TestSince(t *testing.T) {
+	now := time.Now()
+	if d := since(now, now.Add(time.Second)); d != time.Second {
+		t.Errorf("since = %v, want 1s", d)
+	}
+	if d := since(time.Time{}, now); d != 0 {
+		t.Errorf("since with missing start = %v, want 0", d)
+	}
+}
@@ -0,0 +1,360 @@
+package kenko
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+)
+
+// IPVersion restricts which address family a target is dialed over.
+type IPVersion int
+
+// Possible IPVersion values.
+const (
+	IPAny IPVersion = 0
+	IPv4  IPVersion = 4
+	IPv6  IPVersion = 6
+)
+
+func (v IPVersion) network() string {
+	switch v {
+	case IPv4:
+		return "tcp4"
+	case IPv6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// PhaseTimeouts bounds individual phases of a check. zero fields fall back to
+// the transport defaults; the checker's overall timeout still caps the whole
+// check.
+type PhaseTimeouts struct {
+	// DNS bounds the hostname lookup.
+	DNS time.Duration
+	// Connect bounds each TCP connection attempt.
+	Connect time.Duration
+	// TLSHandshake bounds the TLS handshake of HTTPS targets.
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the wait for response headers once the request
+	// has been written, i.e. time to first byte.
+	ResponseHeader time.Duration
+}
+
+// TransportSettings tunes connection pooling of the transport shared by all
+// checks. zero fields keep the transport's defaults. with hundreds of targets
+// the default of two idle connections per host makes checks of a shared host
+// re-dial, which shows up as latency noise.
+type TransportSettings struct {
+	// MaxIdleConns caps idle connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections kept for each host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps all connections to each host, including ones in
+	// use. checks beyond it wait for a connection.
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for longer.
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout bounds TLS handshakes of targets without their own
+	// handshake timeout.
+	TLSHandshakeTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every check, so each one
+	// measures DNS, connect and TLS.
+	DisableKeepAlives bool
+}
+
+// WithTransport tunes the connection pooling of the HTTP transport used for
+// checks. it requires the HTTP client's transport, if set, to be an
+// *http.Transport.
+func WithTransport(ts TransportSettings) Option {
+	return func(o *options) { o.transport = &ts }
+}
+
+func validateTransport(ts *TransportSettings) error {
+	if ts == nil {
+		return nil
+	}
+	if ts.MaxIdleConns < 0 || ts.MaxIdleConnsPerHost < 0 || ts.MaxConnsPerHost < 0 {
+		return fmt.Errorf("kenko: transport connection limits must not be negative")
+	}
+	if ts.IdleConnTimeout < 0 || ts.TLSHandshakeTimeout < 0 {
+		return fmt.Errorf("kenko: transport timeouts must not be negative")
+	}
+	return nil
+}
+
+// tuned returns a copy of base, or of http.DefaultTransport when base is nil,
+// with the settings applied.
+func (ts TransportSettings) tuned(base http.RoundTripper) (http.RoundTripper, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	bt, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("kenko: transport settings need an *http.Transport, got %T", base)
+	}
+
+	t := bt.Clone()
+	if ts.MaxIdleConns > 0 {
+		t.MaxIdleConns = ts.MaxIdleConns
+	}
+	if ts.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = ts.MaxIdleConnsPerHost
+	}
+	if ts.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = ts.MaxConnsPerHost
+	}
+	if ts.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = ts.IdleConnTimeout
+	}
+	if ts.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = ts.TLSHandshakeTimeout
+	}
+	if ts.DisableKeepAlives {
+		t.DisableKeepAlives = true
+	}
+	return t, nil
+}
+
+// transportKey captures the connection settings that require a target to use
+// a transport other than the checker's shared one.
+type transportKey struct {
+	ipVersion  IPVersion
+	sourceAddr string
+	sourceIf   string
+	timeouts   PhaseTimeouts
+	// pinned marks the transport of endpoints pinned to one resolved
+	// address. the address travels with each request, so rotating DNS does
+	// not grow a transport per address, and connections are not kept alive
+	// as the pool cannot tell the addresses apart.
+	pinned bool
+}
+
+// dialIPKey is the context key of the address a pinned request dials.
+type dialIPKey struct{}
+
+// pinIP is the round tripper of an endpoint whose host is pinned to ip.
+// requests to other hosts, such as redirects, are dialed as usual.
+type pinIP struct {
+	rt   http.RoundTripper
+	ip   string
+	host string
+}
+
+func (p pinIP) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Hostname() != p.host {
+		return p.rt.RoundTrip(req)
+	}
+	return p.rt.RoundTrip(req.WithContext(context.WithValue(req.Context(), dialIPKey{}, p.ip)))
+}
+
+// transportKey resolves the target's effective connection settings, falling
+// back to the checker-wide defaults.
+func (c *Checker) transportKey(t Target) transportKey {
+	key := transportKey{
+		ipVersion:  t.IPVersion,
+		sourceAddr: t.SourceAddr,
+		sourceIf:   t.SourceInterface,
+		timeouts:   t.Timeouts,
+		pinned:     t.dialIP != "",
+	}
+	if key.sourceAddr == "" && key.sourceIf == "" {
+		key.sourceAddr, key.sourceIf = c.sourceAddr, c.sourceIf
+	}
+	return key
+}
+
+// transports lazily derives one transport per distinct transportKey from the
+// checker's base transport, so targets with the same settings share pooled
+// connections.
+type transports struct {
+	mu    sync.Mutex
+	byKey map[transportKey]http.RoundTripper
+}
+
+// get returns the transport for a non-zero key, or base itself when base cannot be
+// customized (i.e. it is not an *http.Transport).
+func (ts *transports) get(base http.RoundTripper, key transportKey) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	bt, ok := base.(*http.Transport)
+	if !ok {
+		return base
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.byKey == nil {
+		ts.byKey = make(map[transportKey]http.RoundTripper)
+	}
+	if rt, ok := ts.byKey[key]; ok {
+		return rt
+	}
+
+	t := bt.Clone()
+	network := key.ipVersion.network()
+	t.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		if ip, _ := ctx.Value(dialIPKey{}).(string); ip != "" {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			addr = net.JoinHostPort(ip, port)
+		}
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if key.timeouts.Connect > 0 {
+			dialer.Timeout = key.timeouts.Connect
+		}
+		local, err := key.localAddr()
+		if err != nil {
+			return nil, err
+		}
+		if local != nil {
+			dialer.LocalAddr = local
+		}
+		if key.timeouts.DNS <= 0 {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := key.resolve(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		var conn net.Conn
+		for _, a := range addrs {
+			if conn, err = dialer.DialContext(ctx, network, a); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+	if key.timeouts.TLSHandshake > 0 {
+		t.TLSHandshakeTimeout = key.timeouts.TLSHandshake
+	}
+	if key.timeouts.ResponseHeader > 0 {
+		t.ResponseHeaderTimeout = key.timeouts.ResponseHeader
+	}
+	if key.pinned {
+		t.DisableKeepAlives = true
+	}
+
+	ts.byKey[key] = t
+	return t
+}
+
+// resolve looks up the host of addr within the key's DNS timeout, so the
+// lookup is bounded separately from connecting. it returns the addresses to
+// dial in order.
+func (k transportKey) resolve(ctx context.Context, addr string) ([]string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return []string{addr}, nil
+	}
+
+	network := "ip"
+	switch k.ipVersion {
+	case IPv4:
+		network = "ip4"
+	case IPv6:
+		network = "ip6"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, k.timeouts.DNS)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, fmt.Errorf("dns lookup %s: %w", host, err)
+	}
+
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.JoinHostPort(ip.String(), port))
+	}
+	return addrs, nil
+}
+
+// localAddr resolves the address outbound connections are bound to. an
+// interface is looked up on every dial so address changes (e.g. a VPN
+// reconnecting) are picked up; its IPv4 address is preferred unless the key
+// is restricted to IPv6.
+func (k transportKey) localAddr() (*net.TCPAddr, error) {
+	if k.sourceAddr != "" {
+		ip := net.ParseIP(k.sourceAddr)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid source address %q", k.sourceAddr)
+		}
+		return &net.TCPAddr{IP: ip}, nil
+	}
+	if k.sourceIf == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(k.sourceIf)
+	if err != nil {
+		return nil, fmt.Errorf("source interface: %w", err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("source interface %s: %w", k.sourceIf, err)
+	}
+
+	want6 := k.ipVersion == IPv6
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if (ipnet.IP.To4() == nil) == want6 {
+			return &net.TCPAddr{IP: ipnet.IP}, nil
+		}
+	}
+	return nil, fmt.Errorf("source interface %s has no usable address", k.sourceIf)
+}
+
+// clientFor returns the HTTP client to use for one check of the target. it
+// swaps in a dedicated transport when the target needs custom dialing, and a
+// cookie jar for multi-step checks so cookies set by one step reach the next;
+// the jar is fresh per run unless the target keeps cookies.
+func (c *Checker) clientFor(target Target) *http.Client {
+	rt := c.client.Transport
+	if key := c.transportKey(target); key != (transportKey{}) {
+		rt = c.transports.get(rt, key)
+	}
+	if target.dialIP != "" {
+		rt = pinIP{rt: rt, ip: target.dialIP, host: target.dialHost}
+	}
+
+	var jar http.CookieJar
+	switch {
+	case target.KeepCookies:
+		jar = c.cookies.get(target.Name)
+	case len(target.Steps) > 0:
+		jar, _ = cookiejar.New(nil)
+	}
+
+	timeout := cmp.Or(target.Timeout, c.client.Timeout)
+	if jar == nil && rt == c.client.Transport && timeout == c.client.Timeout {
+		return c.client
+	}
+
+	client := *c.client
+	client.Transport = rt
+	client.Timeout = timeout
+	if jar != nil {
+		client.Jar = jar
+	}
+	return &client
+}
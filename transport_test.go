@@ -0,0 +1,239 @@
+package kenko
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheck_IPVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// httptest listens on 127.0.0.1, so forcing the v6 family must fail
+	// while v4 succeeds
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	url := "http://localhost:" + port
+
+	c, err := NewChecker(
+		WithTarget("v4", url, WithIPVersion(IPv4)),
+		WithTarget("v6", url, WithIPVersion(IPv6)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Status != StatusHealthy {
+		t.Errorf("v4 status = %q, want %q (error: %s)", r.Status, StatusHealthy, r.Error)
+	}
+	if r := c.check(context.Background(), c.targets[1]); r.Status != StatusUnhealthy {
+		t.Errorf("v6 status = %q, want %q", r.Status, StatusUnhealthy)
+	}
+}
+
+func TestTransports_SharedPerKey(t *testing.T) {
+	var ts transports
+	key := transportKey{ipVersion: IPv6}
+
+	a := ts.get(nil, key)
+	b := ts.get(nil, key)
+	if a != b {
+		t.Error("expected targets with the same settings to share a transport")
+	}
+	if a == http.DefaultTransport {
+		t.Error("expected a dedicated transport for a non-default key")
+	}
+}
+
+func TestClientFor_DefaultTargetUsesSharedClient(t *testing.T) {
+	c, err := NewChecker(WithTarget("test", "http://example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.clientFor(c.targets[0]) != c.client {
+		t.Error("expected plain targets to use the shared client")
+	}
+}
+
+func TestCheck_SourceAddr(t *testing.T) {
+	var remote string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("bound", ts.URL),
+		WithTarget("bad", ts.URL, WithSourceAddr("not-an-ip")),
+		WithDefaultSourceAddr("127.0.0.1"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Status != StatusHealthy {
+		t.Fatalf("status = %q, want %q (error: %s)", r.Status, StatusHealthy, r.Error)
+	}
+	if remote != "127.0.0.1" {
+		t.Errorf("remote addr = %q, want 127.0.0.1", remote)
+	}
+
+	if r := c.check(context.Background(), c.targets[1]); r.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want %q for invalid source addr", r.Status, StatusUnhealthy)
+	}
+}
+
+func TestTransportKey_LocalAddrFromInterface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		addr, err := transportKey{sourceIf: iface.Name}.localAddr()
+		if err != nil {
+			t.Skipf("loopback %s: %v", iface.Name, err)
+		}
+		if !addr.IP.IsLoopback() {
+			t.Errorf("local addr = %v, want loopback", addr.IP)
+		}
+		return
+	}
+	t.Skip("no loopback interface")
+}
+
+func TestTransportKey_UnknownInterface(t *testing.T) {
+	if _, err := (transportKey{sourceIf: "kenko-does-not-exist0"}).localAddr(); err == nil {
+		t.Error("expected error for unknown interface")
+	}
+}
+
+func TestTransports_PhaseTimeouts(t *testing.T) {
+	var ts transports
+	rt := ts.get(nil, transportKey{timeouts: PhaseTimeouts{TLSHandshake: time.Second, ResponseHeader: 2 * time.Second}})
+
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport = %T, want *http.Transport", rt)
+	}
+	if tr.TLSHandshakeTimeout != time.Second {
+		t.Errorf("TLSHandshakeTimeout = %v, want 1s", tr.TLSHandshakeTimeout)
+	}
+	if tr.ResponseHeaderTimeout != 2*time.Second {
+		t.Errorf("ResponseHeaderTimeout = %v, want 2s", tr.ResponseHeaderTimeout)
+	}
+}
+
+func TestCheck_ResponseHeaderTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("slow", ts.URL, WithPhaseTimeouts(PhaseTimeouts{ResponseHeader: 20 * time.Millisecond})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := c.check(context.Background(), c.targets[0])
+	if r.Status != StatusUnhealthy || !strings.Contains(r.Error, "timeout") {
+		t.Errorf("status = %q, error = %q, want unhealthy with a timeout", r.Status, r.Error)
+	}
+}
+
+func TestCheck_DNSTimeoutResolvesHost(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	c, err := NewChecker(
+		WithTarget("local", "http://localhost:"+port,
+			WithIPVersion(IPv4),
+			WithPhaseTimeouts(PhaseTimeouts{DNS: time.Second, Connect: time.Second}),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := c.check(context.Background(), c.targets[0]); r.Status != StatusHealthy {
+		t.Errorf("status = %q, want %q (error: %s)", r.Status, StatusHealthy, r.Error)
+	}
+}
+
+func TestTransportKey_ResolveLiteralIP(t *testing.T) {
+	key := transportKey{timeouts: PhaseTimeouts{DNS: time.Second}}
+	addrs, err := key.resolve(context.Background(), "127.0.0.1:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || addrs[0] != "127.0.0.1:80" {
+		t.Errorf("addrs = %v, want [127.0.0.1:80]", addrs)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var conns atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	c, err := NewChecker(
+		WithTarget("api", ts.URL),
+		WithTransport(TransportSettings{MaxIdleConnsPerHost: 50, DisableKeepAlives: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := c.client.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 50 || !tr.DisableKeepAlives {
+		t.Errorf("transport = %d idle per host, keep-alives disabled %v", tr.MaxIdleConnsPerHost, tr.DisableKeepAlives)
+	}
+	if tr == http.DefaultTransport {
+		t.Error("default transport was modified in place")
+	}
+
+	for range 3 {
+		c.check(context.Background(), c.targets[0])
+	}
+	if n := conns.Load(); n != 3 {
+		t.Errorf("%d connections for 3 checks, want one each", n)
+	}
+}
+
+func TestWithTransport_Invalid(t *testing.T) {
+	if _, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithTransport(TransportSettings{MaxConnsPerHost: -1}),
+	); err == nil {
+		t.Error("negative limit accepted")
+	}
+
+	custom := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, nil })}
+	if _, err := NewChecker(
+		WithTarget("api", "https://api.example.com"),
+		WithHTTPClient(custom),
+		WithTransport(TransportSettings{MaxIdleConns: 10}),
+	); err == nil {
+		t.Error("custom round tripper accepted")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
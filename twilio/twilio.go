@@ -0,0 +1,306 @@
+// Package twilio is a kenko Notifier that texts or calls phone numbers
+// through Twilio, for the failures a push notification is too easy to miss
+// for. pair it with a route on unhealthy statuses.
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const (
+	defaultAPIURL  = "https://api.twilio.com/2010-04-01"
+	defaultTimeout = 10 * time.Second
+
+	// maxBody is the longest message Twilio accepts.
+	maxBody = 1600
+
+	// pendingTTL is how long the numbers a message was sent to are kept for
+	// its retries.
+	pendingTTL = time.Hour
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithVoice calls the numbers and reads the failure out instead of texting
+// them. recoveries and latency alerts are not called about.
+func WithVoice() Option {
+	return func(n *Notifier) { n.voice = true }
+}
+
+// WithAPIURL sets the base url of the Twilio api (default
+// "https://api.twilio.com/2010-04-01").
+func WithAPIURL(url string) Option {
+	return func(n *Notifier) { n.apiURL = strings.TrimSuffix(url, "/") }
+}
+
+// WithHTTPClient sets the client requests are sent with (default a client
+// with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// Notifier texts or calls phone numbers through Twilio.
+type Notifier struct {
+	accountSID string
+	authToken  string
+	from       string
+	to         []string
+	voice      bool
+	apiURL     string
+	client     *http.Client
+
+	mu      sync.Mutex
+	pending map[string]*pending
+}
+
+// pending is a message that failed for some numbers, with the numbers that
+// are done with it: those sent it, and those whose failure retrying cannot
+// fix.
+type pending struct {
+	at   time.Time
+	done map[string]bool
+	errs []error
+}
+
+// New creates a Notifier sending from the Twilio number from to the numbers
+// to, in E.164 format such as "+15551234567", as the account whose sid and
+// auth token are given.
+func New(accountSID, authToken, from string, to []string, opts ...Option) *Notifier {
+	n := &Notifier{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		to:         to,
+		apiURL:     defaultAPIURL,
+		client:     &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Notify texts or calls every number. a failure for some numbers is
+// retried for only those numbers, unless none of the failures can be fixed
+// by retrying.
+func (t *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	if t.voice && (n.Status == kenko.StatusHealthy || n.Slow != nil) {
+		return nil
+	}
+	if t.voice {
+		return t.sendAll(ctx, n.At, speech(n))
+	}
+	return t.sendAll(ctx, n.At, text(n))
+}
+
+// NotifyDigest sends one text, or call, for several notifications to each
+// of the numbers. a call names only the failing targets.
+func (t *Notifier) NotifyDigest(ctx context.Context, ns []kenko.Notification) error {
+	if !t.voice {
+		return t.sendAll(ctx, ns[0].At, digestText(ns))
+	}
+	var failing []kenko.Notification
+	for _, n := range ns {
+		if n.Status != kenko.StatusHealthy && n.Slow == nil {
+			failing = append(failing, n)
+		}
+	}
+	switch len(failing) {
+	case 0:
+		return nil
+	case 1:
+		return t.sendAll(ctx, failing[0].At, speech(failing[0]))
+	}
+	return t.sendAll(ctx, failing[0].At, digestSpeech(failing))
+}
+
+// sendAll sends message, for a change at at, to every number it has not
+// been sent to by an earlier attempt.
+func (t *Notifier) sendAll(ctx context.Context, at time.Time, message string) error {
+	key := at.Format(time.RFC3339Nano) + "\n" + message
+	t.mu.Lock()
+	now := time.Now()
+	for k, p := range t.pending {
+		if now.Sub(p.at) > pendingTTL {
+			delete(t.pending, k)
+		}
+	}
+	p := t.pending[key]
+	if p == nil {
+		p = &pending{at: now, done: make(map[string]bool)}
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, to := range t.to {
+		if p.done[to] {
+			continue
+		}
+		err := t.send(ctx, to, message)
+		switch {
+		case err == nil:
+			p.done[to] = true
+		case kenko.IsPermanent(err):
+			p.done[to] = true
+			p.errs = append(p.errs, err)
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(errs) == 0 {
+		delete(t.pending, key)
+		if len(p.errs) == 0 {
+			return nil
+		}
+		return errors.Join(p.errs...)
+	}
+	if t.pending == nil {
+		t.pending = make(map[string]*pending)
+	}
+	t.pending[key] = p
+	// keep a permanent error for one number from stopping the retries
+	return errors.New(errors.Join(append(p.errs, errs...)...).Error())
+}
+
+// Preview renders the text Notify would send, or read out in a call, for n.
+func (t *Notifier) Preview(n kenko.Notification) (string, error) {
+	if t.voice {
+		return speech(n), nil
+	}
+	return text(n), nil
+}
+
+// send texts message to a number, or calls it to read message out.
+func (t *Notifier) send(ctx context.Context, to, message string) error {
+	form := url.Values{"To": {to}, "From": {t.from}}
+	resource := "Messages.json"
+	if t.voice {
+		var say strings.Builder
+		xml.EscapeText(&say, []byte(message))
+		form.Set("Twiml", `<Response><Say loop="2">`+say.String()+`</Say></Response>`)
+		resource = "Calls.json"
+	} else {
+		form.Set("Body", message)
+	}
+
+	u := fmt.Sprintf("%s/Accounts/%s/%s", t.apiURL, url.PathEscape(t.accountSID), resource)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("twilio: %w", err))
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio: %s: %w", to, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	// errors carry a code and message, e.g. 21211 for an invalid number
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&result)
+	code := resp.StatusCode
+	err = fmt.Errorf("twilio: %s: status %d: %d %s", to, code, result.Code, result.Message)
+	if !kenko.RetryableStatus(code) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// text is the message texted for n, kept to one line.
+func text(n kenko.Notification) string {
+	var s string
+	switch {
+	case n.Slow != nil && n.Slow.Resolved:
+		s = fmt.Sprintf("kenko: %s is fast again after %s", n.Target, n.At.Sub(n.Slow.Since).Round(time.Second))
+	case n.Slow != nil:
+		s = fmt.Sprintf("kenko: %s is slow: over %s for %d checks", n.Target, n.Slow.Threshold, n.Slow.Checks)
+	case n.Outage != nil:
+		s = fmt.Sprintf("kenko: %s recovered after %s", n.Target, n.Outage.Duration.Round(time.Second))
+	case n.Repeat > 0:
+		s = fmt.Sprintf("kenko: %s is still %s", n.Target, n.Status)
+	default:
+		s = fmt.Sprintf("kenko: %s is %s (was %s)", n.Target, n.Status, n.Previous)
+	}
+	if n.Status != kenko.StatusHealthy && n.Slow == nil {
+		if msg := n.Result.Error; msg != "" {
+			s += ": " + msg
+		} else if n.Result.StatusCode != 0 {
+			s += fmt.Sprintf(": status %d", n.Result.StatusCode)
+		}
+	}
+	if n.Test {
+		s = "[test] " + s
+	}
+	if r := []rune(s); len(r) > maxBody {
+		s = string(r[:maxBody-3]) + "..."
+	}
+	return s
+}
+
+// digestText is the message texted for several notifications, a line for
+// each.
+func digestText(ns []kenko.Notification) string {
+	lines := []string{fmt.Sprintf("kenko: %d notifications", len(ns))}
+	for _, n := range ns {
+		lines = append(lines, strings.TrimPrefix(text(n), "kenko: "))
+	}
+	s := strings.Join(lines, "\n")
+	if r := []rune(s); len(r) > maxBody {
+		s = string(r[:maxBody-3]) + "..."
+	}
+	return s
+}
+
+// digestSpeech is the text read out in a call about several failing
+// targets.
+func digestSpeech(failing []kenko.Notification) string {
+	names := make([]string, len(failing))
+	for i, n := range failing {
+		names[i] = n.Target
+	}
+	s := fmt.Sprintf("Kenko alert. %d targets are failing: %s.", len(failing), strings.Join(names, ", "))
+	if slices.ContainsFunc(failing, func(n kenko.Notification) bool { return n.Test }) {
+		s = "This is a test. " + s
+	}
+	return s
+}
+
+// speech is the text read out in a call about n. errors are left out, they
+// are rarely intelligible spoken.
+func speech(n kenko.Notification) string {
+	s := fmt.Sprintf("Kenko alert. %s is %s.", n.Target, n.Status)
+	if n.Repeat > 0 {
+		s = fmt.Sprintf("Kenko alert. %s is still %s.", n.Target, n.Status)
+	}
+	if n.Test {
+		s = "This is a test. " + s
+	}
+	return s
+}
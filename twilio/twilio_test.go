@@ -0,0 +1,200 @@
+package twilio
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification { return notifytest.Notification(kenko.StatusUnhealthy) }
+
+type request struct {
+	path string
+	user string
+	form url.Values
+}
+
+func server(t *testing.T, status int, body string) (*httptest.Server, *[]request) {
+	var mu sync.Mutex
+	var reqs []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		user, _, _ := r.BasicAuth()
+		mu.Lock()
+		reqs = append(reqs, request{path: r.URL.Path, user: user, form: r.PostForm})
+		mu.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs
+}
+
+func TestNotify_SMS(t *testing.T) {
+	srv, reqs := server(t, http.StatusCreated, `{"sid":"SM1"}`)
+	n := New("AC123", "secret", "+15550000000", []string{"+15551111111", "+15552222222"}, WithAPIURL(srv.URL))
+	if err := n.Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reqs) != 2 {
+		t.Fatalf("requests = %+v, want one per number", *reqs)
+	}
+	r := (*reqs)[0]
+	if r.path != "/Accounts/AC123/Messages.json" || r.user != "AC123" || r.form.Get("To") != "+15551111111" || r.form.Get("From") != "+15550000000" {
+		t.Errorf("request = %+v", r)
+	}
+	if want := "kenko: api is unhealthy (was healthy): status 503"; r.form.Get("Body") != want {
+		t.Errorf("body = %q, want %q", r.form.Get("Body"), want)
+	}
+}
+
+func TestNotify_Voice(t *testing.T) {
+	srv, reqs := server(t, http.StatusCreated, `{"sid":"CA1"}`)
+	n := New("AC123", "secret", "+15550000000", []string{"+15551111111"}, WithAPIURL(srv.URL), WithVoice())
+	failure := notification()
+	failure.Target = "api<eu>"
+	if err := n.Notify(context.Background(), failure); err != nil {
+		t.Fatal(err)
+	}
+	recovery := notification()
+	recovery.Status = kenko.StatusHealthy
+	if err := n.Notify(context.Background(), recovery); err != nil {
+		t.Fatal(err)
+	}
+	slow := notification()
+	slow.Status = kenko.StatusHealthy
+	slow.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3}
+	if err := n.Notify(context.Background(), slow); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reqs) != 1 {
+		t.Fatalf("requests = %+v, want only the failure called about", *reqs)
+	}
+	r := (*reqs)[0]
+	want := `<Response><Say loop="2">Kenko alert. api&lt;eu&gt; is unhealthy.</Say></Response>`
+	if r.path != "/Accounts/AC123/Calls.json" || r.form.Get("Twiml") != want {
+		t.Errorf("request = %+v", r)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Outage = &kenko.Outage{Duration: 90 * time.Second}
+	n.Test = true
+	text, err := New("AC123", "secret", "+1", nil).Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "[test] kenko: api recovered after 1m30s" {
+		t.Errorf("preview = %q", text)
+	}
+
+	slow := notification()
+	slow.Status = kenko.StatusHealthy
+	slow.Slow = &kenko.SlowAlert{Threshold: time.Second, Checks: 3}
+	if text, _ := New("AC123", "secret", "+1", nil).Preview(slow); text != "kenko: api is slow: over 1s for 3 checks" {
+		t.Errorf("slow preview = %q", text)
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+	web := notification()
+	web.Target = "web"
+	recovery := notification()
+	recovery.Target, recovery.Status = "db", kenko.StatusHealthy
+	recovery.Outage = &kenko.Outage{Duration: time.Minute}
+	digest := []kenko.Notification{notification(), web, recovery}
+
+	srv, reqs := server(t, http.StatusCreated, `{"sid":"SM1"}`)
+	if err := New("AC123", "secret", "+15550000000", []string{"+15551111111"}, WithAPIURL(srv.URL)).NotifyDigest(context.Background(), digest); err != nil {
+		t.Fatal(err)
+	}
+	want := "kenko: 3 notifications\napi is unhealthy (was healthy): status 503\nweb is unhealthy (was healthy): status 503\ndb recovered after 1m0s"
+	if len(*reqs) != 1 || (*reqs)[0].form.Get("Body") != want {
+		t.Errorf("requests = %+v, want one text %q", *reqs, want)
+	}
+
+	srv, reqs = server(t, http.StatusCreated, `{"sid":"CA1"}`)
+	if err := New("AC123", "secret", "+15550000000", []string{"+15551111111"}, WithAPIURL(srv.URL), WithVoice()).NotifyDigest(context.Background(), digest); err != nil {
+		t.Fatal(err)
+	}
+	want = `<Response><Say loop="2">Kenko alert. 2 targets are failing: api, web.</Say></Response>`
+	if len(*reqs) != 1 || (*reqs)[0].form.Get("Twiml") != want {
+		t.Errorf("requests = %+v, want one call", *reqs)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, `{"code":21211,"message":"Invalid 'To' Phone Number","status":400}`, "21211 Invalid 'To' Phone Number", func(url string) error {
+		return New("AC123", "secret", "+15550000000", []string{"+1555"}, WithAPIURL(url)).Notify(context.Background(), notification())
+	})
+}
+
+func TestNotify_PartialFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.PostForm.Get("To") {
+		case "+1bad":
+			w.WriteHeader(http.StatusBadRequest)
+		case "+1busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	err := New("AC123", "secret", "+1", []string{"+1bad", "+1busy", "+1ok"}, WithAPIURL(srv.URL)).Notify(context.Background(), notification())
+	if err == nil || kenko.IsPermanent(err) || !strings.Contains(err.Error(), "+1bad") || !strings.Contains(err.Error(), "+1busy") {
+		t.Errorf("err = %v, want both failures, retryable", err)
+	}
+}
+
+func TestNotify_RetriesFailedNumbers(t *testing.T) {
+	var mu sync.Mutex
+	sent := make(map[string]int)
+	busy := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		to := r.PostForm.Get("To")
+		mu.Lock()
+		defer mu.Unlock()
+		sent[to]++
+		switch {
+		case to == "+1bad":
+			w.WriteHeader(http.StatusBadRequest)
+		case to == "+1busy" && busy:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	n := New("AC123", "secret", "+1", []string{"+1bad", "+1busy", "+1ok"}, WithAPIURL(srv.URL))
+	if err := n.Notify(context.Background(), notification()); err == nil || kenko.IsPermanent(err) {
+		t.Fatalf("first attempt: err = %v, want a retryable error", err)
+	}
+	busy = false
+	err := n.Notify(context.Background(), notification())
+	if err == nil || !kenko.IsPermanent(err) || !strings.Contains(err.Error(), "+1bad") {
+		t.Errorf("retry: err = %v, want the permanent failure for +1bad", err)
+	}
+	if want := map[string]int{"+1bad": 1, "+1busy": 2, "+1ok": 1}; !maps.Equal(sent, want) {
+		t.Errorf("sent %v, want only the busy number retried: %v", sent, want)
+	}
+
+	later := notification()
+	later.At = later.At.Add(time.Minute)
+	n.Notify(context.Background(), later)
+	if sent["+1ok"] != 2 {
+		t.Errorf("sent %v, want a later change sent to every number again", sent)
+	}
+}
@@ -0,0 +1,248 @@
+package kenko
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// uptimeStatePrefix prefixes the state key holding a target's aggregates.
+const uptimeStatePrefix = "uptime:"
+
+const (
+	// hourlyAggregates and dailyAggregates bound how many buckets are kept
+	// per target: a week and a day of hours, and a year and a month of days.
+	hourlyAggregates = 8 * 24
+	dailyAggregates  = 400
+
+	// aggregateSaveInterval is how often changed aggregates are persisted.
+	aggregateSaveInterval = 5 * time.Minute
+)
+
+// Period is the length of the buckets UptimeAggregates returns.
+type Period string
+
+const (
+	PeriodHour Period = "hour"
+	PeriodDay  Period = "day"
+)
+
+// UptimeAggregate summarizes a target's checks in one hour or UTC day.
+// checks during maintenance are left out, and excluded incidents still count.
+type UptimeAggregate struct {
+	Start  time.Time `json:"start"`
+	Checks int       `json:"checks"`
+	// Down is how many of the checks were unhealthy.
+	Down int `json:"down"`
+	// Latency is the total latency of the checks.
+	Latency time.Duration `json:"latency"`
+}
+
+// Uptime returns the fraction of the checks that were not unhealthy, or 1
+// without checks.
+func (a UptimeAggregate) Uptime() float64 {
+	if a.Checks == 0 {
+		return 1
+	}
+	return 1 - float64(a.Down)/float64(a.Checks)
+}
+
+// AvgLatency returns the mean latency of the checks.
+func (a UptimeAggregate) AvgLatency() time.Duration {
+	if a.Checks == 0 {
+		return 0
+	}
+	return a.Latency / time.Duration(a.Checks)
+}
+
+// targetAggregates holds a target's buckets, oldest first.
+type targetAggregates struct {
+	Hourly []UptimeAggregate `json:"hourly"`
+	Daily  []UptimeAggregate `json:"daily"`
+
+	dirty bool
+}
+
+type aggregates struct {
+	mu       sync.Mutex
+	byTarget map[string]*targetAggregates
+}
+
+func (a *aggregates) observe(r Result) {
+	if r.Status == StatusMaintenance {
+		return
+	}
+	at := r.CheckedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.byTarget == nil {
+		a.byTarget = make(map[string]*targetAggregates)
+	}
+	ta := a.byTarget[r.Target]
+	if ta == nil {
+		ta = &targetAggregates{}
+		a.byTarget[r.Target] = ta
+	}
+	ta.Hourly = addToBucket(ta.Hourly, at.UTC().Truncate(time.Hour), r, hourlyAggregates)
+	ta.Daily = addToBucket(ta.Daily, at.UTC().Truncate(24*time.Hour), r, dailyAggregates)
+	ta.dirty = true
+}
+
+// addToBucket counts r in the bucket starting at start, opening it when it is
+// newer than the last one, and drops buckets beyond max.
+func addToBucket(buckets []UptimeAggregate, start time.Time, r Result, max int) []UptimeAggregate {
+	i := len(buckets) - 1
+	for i >= 0 && buckets[i].Start.After(start) {
+		i--
+	}
+	if i < 0 || !buckets[i].Start.Equal(start) {
+		i++
+		buckets = slices.Insert(buckets, i, UptimeAggregate{Start: start})
+	}
+
+	b := &buckets[i]
+	b.Checks++
+	if r.Status == StatusUnhealthy {
+		b.Down++
+	}
+	b.Latency += r.Latency
+
+	if len(buckets) > max {
+		buckets = slices.Delete(buckets, 0, len(buckets)-max)
+	}
+	return buckets
+}
+
+func (a *aggregates) get(name string, p Period, from, to time.Time) []UptimeAggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ta := a.byTarget[name]
+	if ta == nil {
+		return nil
+	}
+
+	buckets := ta.Hourly
+	if p == PeriodDay {
+		buckets = ta.Daily
+	}
+	var out []UptimeAggregate
+	for _, b := range buckets {
+		if !from.IsZero() && b.Start.Before(from) || !to.IsZero() && b.Start.After(to) {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// UptimeAggregates returns the target's hourly or daily aggregates starting
+// between from and to, oldest first. a zero bound leaves that side open. the
+// aggregates are kept in memory and persisted through a StateStore, so they
+// survive restarts without replaying history.
+func (c *Checker) UptimeAggregates(name string, p Period, from, to time.Time) []UptimeAggregate {
+	return c.aggregates.get(name, p, from, to)
+}
+
+func (c *Checker) aggregateKey(name string) string {
+	if c.region != "" {
+		return uptimeStatePrefix + c.region + ":" + name
+	}
+	return uptimeStatePrefix + name
+}
+
+// loadAggregates restores the persisted aggregates of every target, merging
+// in any counted since start-up.
+func (c *Checker) loadAggregates(ctx context.Context) error {
+	for _, t := range c.targetList() {
+		var stored targetAggregates
+		ok, err := c.loadState(ctx, c.aggregateKey(t.Name), &stored)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		c.aggregates.mu.Lock()
+		if c.aggregates.byTarget == nil {
+			c.aggregates.byTarget = make(map[string]*targetAggregates)
+		}
+		if ta := c.aggregates.byTarget[t.Name]; ta != nil {
+			stored.Hourly = mergeBuckets(stored.Hourly, ta.Hourly, hourlyAggregates)
+			stored.Daily = mergeBuckets(stored.Daily, ta.Daily, dailyAggregates)
+			stored.dirty = true
+		}
+		c.aggregates.byTarget[t.Name] = &stored
+		c.aggregates.mu.Unlock()
+	}
+	return nil
+}
+
+// mergeBuckets adds the counts of extra into buckets.
+func mergeBuckets(buckets, extra []UptimeAggregate, max int) []UptimeAggregate {
+	for _, e := range extra {
+		i, found := slices.BinarySearchFunc(buckets, e.Start, func(b UptimeAggregate, t time.Time) int {
+			return b.Start.Compare(t)
+		})
+		if !found {
+			buckets = slices.Insert(buckets, i, UptimeAggregate{Start: e.Start})
+		}
+		buckets[i].Checks += e.Checks
+		buckets[i].Down += e.Down
+		buckets[i].Latency += e.Latency
+	}
+	if len(buckets) > max {
+		buckets = slices.Delete(buckets, 0, len(buckets)-max)
+	}
+	return buckets
+}
+
+// saveAggregates persists the aggregates that changed since they were last
+// saved.
+func (c *Checker) saveAggregates(ctx context.Context) {
+	if _, ok := c.store.(StateStore); !ok {
+		return
+	}
+
+	c.aggregates.mu.Lock()
+	changed := make(map[string]targetAggregates)
+	for name, ta := range c.aggregates.byTarget {
+		if ta.dirty {
+			changed[name] = targetAggregates{Hourly: slices.Clone(ta.Hourly), Daily: slices.Clone(ta.Daily)}
+			ta.dirty = false
+		}
+	}
+	c.aggregates.mu.Unlock()
+
+	for name, ta := range changed {
+		if err := c.saveState(ctx, c.aggregateKey(name), ta); err != nil {
+			c.logger.Warn("failed to save uptime aggregates", "target", name, "error", err)
+			c.aggregates.mu.Lock()
+			if cur := c.aggregates.byTarget[name]; cur != nil {
+				cur.dirty = true
+			}
+			c.aggregates.mu.Unlock()
+		}
+	}
+}
+
+func (c *Checker) runAggregates(ctx context.Context) {
+	ticker := time.NewTicker(aggregateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.life.stop:
+			return
+		case <-ticker.C:
+			c.saveAggregates(ctx)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package kenko
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAggregates_Observe(t *testing.T) {
+	var a aggregates
+	t0 := time.Date(2026, 3, 13, 23, 0, 0, 0, time.UTC)
+
+	for i, r := range []Result{
+		{Status: StatusHealthy, Latency: 100 * time.Millisecond},
+		{Status: StatusUnhealthy, Latency: 300 * time.Millisecond},
+		{Status: StatusMaintenance, Latency: time.Second},
+		{Status: StatusHealthy, Latency: 200 * time.Millisecond},
+	} {
+		r.Target = "api"
+		r.CheckedAt = t0.Add(time.Duration(i) * 25 * time.Minute)
+		a.observe(r)
+	}
+
+	hourly := a.get("api", PeriodHour, time.Time{}, time.Time{})
+	if len(hourly) != 2 || hourly[0].Checks != 2 || hourly[1].Checks != 1 {
+		t.Fatalf("hourly = %+v, want 2 checks then 1", hourly)
+	}
+	if up := hourly[0].Uptime(); up != 0.5 {
+		t.Errorf("uptime = %v, want 0.5", up)
+	}
+	if avg := hourly[0].AvgLatency(); avg != 200*time.Millisecond {
+		t.Errorf("average latency = %s, want 200ms", avg)
+	}
+
+	// 23:00 and 00:15 fall on different utc days
+	daily := a.get("api", PeriodDay, t0.Add(time.Hour), time.Time{})
+	if len(daily) != 1 || daily[0].Checks != 1 || !daily[0].Start.Equal(t0.Add(time.Hour)) {
+		t.Errorf("daily from midnight = %+v, want the one check after it", daily)
+	}
+}
+
+func TestAddToBucket_Trims(t *testing.T) {
+	var buckets []UptimeAggregate
+	t0 := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		buckets = addToBucket(buckets, t0.Add(time.Duration(i)*time.Hour), Result{Status: StatusHealthy}, 3)
+	}
+	// a late result lands in its own, older bucket
+	buckets = addToBucket(buckets, t0.Add(3*time.Hour), Result{Status: StatusUnhealthy}, 3)
+
+	if len(buckets) != 3 || !buckets[0].Start.Equal(t0.Add(2*time.Hour)) {
+		t.Fatalf("buckets = %+v, want the last 3 hours", buckets)
+	}
+	if buckets[1].Checks != 2 || buckets[1].Down != 1 {
+		t.Errorf("late bucket = %+v, want 2 checks, 1 down", buckets[1])
+	}
+}
+
+func TestAggregates_Persisted(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().Truncate(time.Hour)
+	c.aggregates.observe(Result{Target: "api", Status: StatusUnhealthy, CheckedAt: at})
+	c.saveAggregates(context.Background())
+
+	restarted, err := NewChecker(WithTarget("api", "https://api.example.com"), WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a check counted before the load is merged into the stored bucket
+	restarted.aggregates.observe(Result{Target: "api", Status: StatusHealthy, CheckedAt: at.Add(time.Minute)})
+	if err := restarted.loadAggregates(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	hourly := restarted.UptimeAggregates("api", PeriodHour, time.Time{}, time.Time{})
+	if len(hourly) != 1 || hourly[0].Checks != 2 || hourly[0].Down != 1 {
+		t.Errorf("hourly after restart = %+v, want 2 checks, 1 down", hourly)
+	}
+}
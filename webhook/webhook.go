@@ -0,0 +1,221 @@
+// Package webhook is a kenko Notifier that POSTs a JSON payload describing
+// each status change to a url, for alerters and automation that have no
+// native integration.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// headers of a signed request. see WithSecret.
+const (
+	SignatureHeader = "X-Kenko-Signature"
+	TimestampHeader = "X-Kenko-Timestamp"
+)
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithHeader adds a header to every request, e.g. an Authorization header the
+// receiver expects.
+func WithHeader(key, value string) Option {
+	return func(n *Notifier) { n.header.Add(key, value) }
+}
+
+// WithHTTPClient sets the client requests are sent with (default a client
+// with a 10s timeout).
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) { n.client = c }
+}
+
+// WithBody sets the template the request body is rendered from instead of
+// the JSON Payload, executed with the kenko.Notification, e.g. to match the
+// format a receiver expects. the Content-Type stays application/json unless
+// WithHeader sets another.
+func WithBody(t *template.Template) Option {
+	return func(n *Notifier) { n.body = t }
+}
+
+// WithSecret signs every request with secret, so the receiver can check it
+// came from this kenko. TimestampHeader carries the unix time the request was
+// signed at and SignatureHeader "sha256=" followed by the hex HMAC-SHA256,
+// keyed with secret, of the timestamp, a dot, and the body. receivers reject
+// old timestamps to stop replays; see Verify.
+func WithSecret(secret string) Option {
+	return func(n *Notifier) { n.secret = secret }
+}
+
+// Payload is the JSON body posted for a notification.
+type Payload struct {
+	Target   string       `json:"target"`
+	Previous kenko.Status `json:"previous"`
+	Status   kenko.Status `json:"status"`
+	At       time.Time    `json:"at"`
+	// Outage is set when the target recovered.
+	Outage *kenko.Outage `json:"outage,omitempty"`
+	// Repeat counts the reminders sent while the target stays down.
+	Repeat int `json:"repeat,omitempty"`
+	// Slow is set for a latency alert rather than a status change.
+	Slow *kenko.SlowAlert `json:"slow,omitempty"`
+	// Test is set for notifications sent through the notifier test API.
+	Test bool `json:"test,omitempty"`
+	// Uptime is the fraction of the target's observed timeline it was up.
+	Uptime float64      `json:"uptime"`
+	Result kenko.Result `json:"result"`
+}
+
+// Notifier posts notifications to a webhook url.
+type Notifier struct {
+	url    string
+	header http.Header
+	body   *template.Template
+	secret string
+	client *http.Client
+}
+
+// New creates a Notifier posting to url.
+func New(url string, opts ...Option) *Notifier {
+	n := &Notifier{
+		url:    url,
+		header: make(http.Header),
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+func payload(n kenko.Notification) Payload {
+	return Payload{
+		Target:   n.Target,
+		Previous: n.Previous,
+		Status:   n.Status,
+		At:       n.At,
+		Outage:   n.Outage,
+		Repeat:   n.Repeat,
+		Slow:     n.Slow,
+		Test:     n.Test,
+		Uptime:   n.Uptime,
+		Result:   n.Result,
+	}
+}
+
+// render returns the request body posted for n.
+func (w *Notifier) render(n kenko.Notification) ([]byte, error) {
+	if w.body == nil {
+		body, err := json.Marshal(payload(n))
+		if err != nil {
+			return nil, fmt.Errorf("webhook: marshal: %w", err)
+		}
+		return body, nil
+	}
+	var b bytes.Buffer
+	if err := w.body.Execute(&b, n); err != nil {
+		return nil, fmt.Errorf("webhook: body: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// Notify posts the notification's payload. a 2xx response is a delivery;
+// other 4xx responses than 408 and 429 are not retried.
+func (w *Notifier) Notify(ctx context.Context, n kenko.Notification) error {
+	body, err := w.render(n)
+	if err != nil {
+		return kenko.Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return kenko.Permanent(fmt.Errorf("webhook: %w", err))
+	}
+	for k, vs := range w.header {
+		req.Header[k] = vs
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", kenko.DefaultUserAgent)
+	if w.secret != "" {
+		// signed per attempt, so a retry is not rejected as a replay
+		ts := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+		req.Header.Set(SignatureHeader, sign(w.secret, ts, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("webhook: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	if !kenko.RetryableStatus(resp.StatusCode) {
+		return kenko.Permanent(err)
+	}
+	return err
+}
+
+// Preview renders the payload Notify would post for n.
+func (w *Notifier) Preview(n kenko.Notification) (string, error) {
+	if w.body != nil {
+		body, err := w.render(n)
+		return string(body), err
+	}
+	body, err := json.MarshalIndent(payload(n), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("webhook: marshal: %w", err)
+	}
+	return string(body), nil
+}
+
+// sign returns the SignatureHeader value of body signed at ts.
+func sign(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that a request with header h and body was signed with secret
+// by a Notifier, and no longer than tolerance ago, for receivers written in
+// Go. tolerance also bounds how far ahead the timestamp may be, allowing for
+// clock skew.
+func Verify(h http.Header, body []byte, secret string, tolerance time.Duration) error {
+	raw, sig := h.Get(TimestampHeader), h.Get(SignatureHeader)
+	if raw == "" || sig == "" {
+		return fmt.Errorf("webhook: request is not signed")
+	}
+	ts, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("webhook: bad timestamp %q", raw)
+	}
+	if !hmac.Equal([]byte(sig), []byte(sign(secret, ts, body))) {
+		return fmt.Errorf("webhook: signature mismatch")
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("webhook: signed %s ago, outside the %s tolerance", age.Truncate(time.Second), tolerance)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/aidantrabs/kenko"
+	"github.com/aidantrabs/kenko/notifytest"
+)
+
+func notification() kenko.Notification { return notifytest.Notification(kenko.StatusUnhealthy) }
+
+func TestNotify(t *testing.T) {
+	var got Payload
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	if err := New(srv.URL, WithHeader("Authorization", "Bearer secret")).Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Target != "api" || got.Previous != kenko.StatusHealthy || got.Status != kenko.StatusUnhealthy || got.Result.StatusCode != 503 || got.Test {
+		t.Errorf("payload = %+v", got)
+	}
+	if header.Get("Authorization") != "Bearer secret" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", header)
+	}
+}
+
+func TestNotify_Errors(t *testing.T) {
+	notifytest.StatusErrors(t, "no thanks\n", "no thanks", func(url string) error {
+		return New(url).Notify(context.Background(), notification())
+	})
+}
+
+func TestPreview(t *testing.T) {
+	n := notification()
+	n.Test = true
+	text, err := New("http://localhost").Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `"status": "unhealthy"`) || !strings.Contains(text, `"test": true`) {
+		t.Errorf("preview = %s", text)
+	}
+}
+
+func TestPreview_Recovery(t *testing.T) {
+	n := notification()
+	n.Previous, n.Status = kenko.StatusUnhealthy, kenko.StatusHealthy
+	n.Outage = &kenko.Outage{Start: n.At.Add(-time.Minute), Duration: time.Minute, FirstError: "timeout"}
+	text, err := New("http://localhost").Preview(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `"duration": 60000000000`) || !strings.Contains(text, `"first_error": "timeout"`) {
+		t.Errorf("preview = %s", text)
+	}
+}
+
+func TestNotify_Body(t *testing.T) {
+	var body, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body, contentType = string(b), r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	tmpl := template.Must(template.New("body").Parse(`{{.Target}} is {{.Status}}, runbook {{index .Result.Labels "runbook"}}, uptime {{printf "%.1f" .Uptime}}`))
+	n := notification()
+	n.Result.Labels = map[string]string{"runbook": "https://wiki/api"}
+	n.Uptime = 0.995
+	w := New(srv.URL, WithBody(tmpl), WithHeader("Content-Type", "text/plain"))
+	if err := w.Notify(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	want := "api is unhealthy, runbook https://wiki/api, uptime 1.0"
+	if body != want || contentType != "text/plain" {
+		t.Errorf("body = %q (%s), want %q as text/plain", body, contentType, want)
+	}
+	if text, err := w.Preview(n); err != nil || text != want {
+		t.Errorf("preview = %q, %v", text, err)
+	}
+
+	broken := template.Must(template.New("body").Parse(`{{.Missing}}`))
+	if err := New(srv.URL, WithBody(broken)).Notify(context.Background(), n); !kenko.IsPermanent(err) {
+		t.Errorf("err = %v, want a permanent template error", err)
+	}
+}
+
+func TestNotify_Signed(t *testing.T) {
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	if err := New(srv.URL, WithSecret("s3cret")).Notify(context.Background(), notification()); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header %q: %v", header.Get(TimestampHeader), err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(strconv.FormatInt(ts, 10) + "."))
+	mac.Write(body)
+	if got, want := header.Get(SignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("signature header = %q, want %q", got, want)
+	}
+	if err := Verify(header, body, "s3cret", time.Minute); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+	if err := Verify(header, body, "other", time.Minute); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("wrong secret: err = %v, want a mismatch", err)
+	}
+	if err := Verify(header, append(body, ' '), "s3cret", time.Minute); err == nil {
+		t.Error("tampered body verified")
+	}
+	if err := Verify(http.Header{}, body, "s3cret", time.Minute); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("unsigned: err = %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"target":"api"}`)
+	signed := func(at time.Time) http.Header {
+		h := http.Header{}
+		h.Set(TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+		h.Set(SignatureHeader, sign("s3cret", at.Unix(), body))
+		return h
+	}
+	now := time.Now()
+	bad := signed(now)
+	bad.Set(TimestampHeader, "yesterday")
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		errs   string
+	}{
+		{"good", signed(now), body, ""},
+		{"inside tolerance", signed(now.Add(-4 * time.Minute)), body, ""},
+		{"skewed ahead", signed(now.Add(4 * time.Minute)), body, ""},
+		{"tampered body", signed(now), []byte(`{"target":"db"}`), "mismatch"},
+		{"replayed", signed(now.Add(-10 * time.Minute)), body, "tolerance"},
+		{"far ahead", signed(now.Add(10 * time.Minute)), body, "tolerance"},
+		{"bad timestamp", bad, body, "bad timestamp"},
+		{"unsigned", http.Header{}, body, "not signed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.header, tt.body, "s3cret", 5*time.Minute)
+			if tt.errs == "" && err != nil {
+				t.Errorf("err = %v, want it verified", err)
+			}
+			if tt.errs != "" && (err == nil || !strings.Contains(err.Error(), tt.errs)) {
+				t.Errorf("err = %v, want %q", err, tt.errs)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac key
+	want := "sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"
+	if got := sign("key", 1700000000, []byte("{}")); got != want {
+		t.Errorf("sign = %q, want %q", got, want)
+	}
+	if got := sign("key", 1700000001, []byte("{}")); got == want {
+		t.Error("signature does not cover the timestamp")
+	}
+}
@@ -0,0 +1,151 @@
+package kenko
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// defaultWriteBatch caps how many queued results are handed to the store in
+// one call.
+const defaultWriteBatch = 100
+
+// BatchStore is implemented by stores that can persist many results in one round trip.
+type BatchStore interface {
+	SetBatch(ctx context.Context, results []Result) error
+}
+
+// resultWriter drains a bounded queue of results into the store from a single
+// goroutine so that slow store writes never block the check loop.
+type resultWriter struct {
+	store  Store
+	logger *slog.Logger
+	queue  chan Result
+	flush  chan chan struct{}
+
+	// batchSize caps each store write, and delay is how long a write waits
+	// for the batch to fill.
+	batchSize int
+	delay     time.Duration
+
+	// afterWrite, if set, is called after every batch is handed to the store.
+	afterWrite func()
+}
+
+func newResultWriter(store Store, logger *slog.Logger, size int) *resultWriter {
+	return &resultWriter{
+		store:  store,
+		logger: logger,
+		queue:  make(chan Result, size),
+		flush:  make(chan chan struct{}),
+
+		batchSize: defaultWriteBatch,
+	}
+}
+
+// enqueue adds a result without blocking, dropping it if the queue is full.
+func (w *resultWriter) enqueue(r Result) {
+	select {
+	case w.queue <- r:
+	default:
+		w.logger.Warn("write queue full, dropping result", "target", r.Target)
+	}
+}
+
+// wait blocks until everything enqueued before the call has been written.
+func (w *resultWriter) wait(ctx context.Context) {
+	done := make(chan struct{})
+	select {
+	case w.flush <- done:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+func (w *resultWriter) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			// write whatever is still queued so the final results are not lost
+			drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			w.drain(drainCtx)
+			cancel()
+			return
+		case done := <-w.flush:
+			w.drain(ctx)
+			close(done)
+		case r := <-w.queue:
+			w.write(ctx, w.collect(ctx, []Result{r}))
+		}
+	}
+}
+
+// drain writes batches until the queue is empty.
+func (w *resultWriter) drain(ctx context.Context) {
+	for {
+		batch := w.fill(nil)
+		if len(batch) == 0 {
+			return
+		}
+		w.write(ctx, batch)
+	}
+}
+
+// collect fills batch, waiting up to the delay for more results while it is
+// not full.
+func (w *resultWriter) collect(ctx context.Context, batch []Result) []Result {
+	batch = w.fill(batch)
+	if w.delay <= 0 || len(batch) >= w.batchSize {
+		return batch
+	}
+
+	timer := time.NewTimer(w.delay)
+	defer timer.Stop()
+	for len(batch) < w.batchSize {
+		select {
+		case r := <-w.queue:
+			batch = append(batch, r)
+		case <-timer.C:
+			return batch
+		case <-ctx.Done():
+			return batch
+		}
+	}
+	return batch
+}
+
+// fill appends queued results to batch without blocking, up to the batch size.
+func (w *resultWriter) fill(batch []Result) []Result {
+	for len(batch) < w.batchSize {
+		select {
+		case r := <-w.queue:
+			batch = append(batch, r)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (w *resultWriter) write(ctx context.Context, batch []Result) {
+	if w.afterWrite != nil {
+		defer w.afterWrite()
+	}
+
+	if bs, ok := w.store.(BatchStore); ok {
+		if err := bs.SetBatch(ctx, batch); err != nil {
+			w.logger.Warn("failed to store results", "count", len(batch), "error", err)
+		}
+		return
+	}
+
+	for _, r := range batch {
+		if err := w.store.Set(ctx, r.Target, r); err != nil {
+			w.logger.Warn("failed to store result", "target", r.Target, "error", err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package kenko
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slowStore struct {
+	*MemoryStore
+	delay time.Duration
+}
+
+func (s *slowStore) Set(ctx context.Context, name string, r Result) error {
+	time.Sleep(s.delay)
+	return s.MemoryStore.Set(ctx, name, r)
+}
+
+type batchRecorder struct {
+	*MemoryStore
+	mu      sync.Mutex
+	batches []int
+}
+
+func (b *batchRecorder) SetBatch(ctx context.Context, results []Result) error {
+	b.mu.Lock()
+	b.batches = append(b.batches, len(results))
+	b.mu.Unlock()
+	return b.MemoryStore.SetBatch(ctx, results)
+}
+
+func TestResultWriter_EnqueueDoesNotBlock(t *testing.T) {
+	store := &slowStore{MemoryStore: NewMemoryStore(), delay: time.Second}
+	w := newResultWriter(store, slog.New(slog.NewJSONHandler(os.Stdout, nil)), 1)
+
+	start := time.Now()
+	w.enqueue(Result{Target: "a"})
+	w.enqueue(Result{Target: "b"}) // queue full, dropped
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("enqueue blocked for %s", elapsed)
+	}
+}
+
+func TestResultWriter_BatchesAndFlushes(t *testing.T) {
+	store := &batchRecorder{MemoryStore: NewMemoryStore()}
+	w := newResultWriter(store, slog.New(slog.NewJSONHandler(os.Stdout, nil)), 10)
+
+	for _, name := range []string{"a", "b", "c"} {
+		w.enqueue(Result{Target: name, Status: StatusHealthy})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.run(ctx)
+		close(done)
+	}()
+
+	w.wait(ctx)
+
+	all, _ := store.GetAll(ctx)
+	if len(all) != 3 {
+		t.Fatalf("stored = %d, want 3", len(all))
+	}
+
+	store.mu.Lock()
+	if len(store.batches) != 1 || store.batches[0] != 3 {
+		t.Errorf("batches = %v, want [3]", store.batches)
+	}
+	store.mu.Unlock()
+
+	cancel()
+	<-done
+}
+
+func TestResultWriter_WaitsForBatch(t *testing.T) {
+	store := &batchRecorder{MemoryStore: NewMemoryStore()}
+	w := newResultWriter(store, slog.New(slog.NewJSONHandler(os.Stdout, nil)), 10)
+	w.batchSize = 3
+	w.delay = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.run(ctx)
+		close(done)
+	}()
+
+	// results trickling in within the delay share a write, the batch size
+	// ends it early
+	for _, name := range []string{"a", "b", "c", "d"} {
+		w.enqueue(Result{Target: name, Status: StatusHealthy})
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.batches) != 2 || store.batches[0] != 3 || store.batches[1] != 1 {
+		t.Errorf("batches = %v, want [3 1]", store.batches)
+	}
+}
+
+func TestResultWriter_DrainsOnShutdown(t *testing.T) {
+	store := NewMemoryStore()
+	w := newResultWriter(store, slog.New(slog.NewJSONHandler(os.Stdout, nil)), 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w.enqueue(Result{Target: "a"})
+	w.run(ctx)
+
+	all, _ := store.GetAll(context.Background())
+	if len(all) != 1 {
+		t.Errorf("stored = %d, want 1", len(all))
+	}
+}
+
+func TestRun_WriteBehindReadyAfterStore(t *testing.T) {
+	c, err := NewChecker(
+		WithTarget("test", "http://localhost:1"),
+		WithInterval(time.Hour),
+		WithWriteBehind(10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for !c.Ready() {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for ready")
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	results, _ := c.Results()
+	if len(results) != 1 {
+		t.Errorf("results = %d, want 1", len(results))
+	}
+
+	cancel()
+	<-done
+}